@@ -16,6 +16,7 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -35,8 +36,10 @@ import (
 	"github.com/sensu/sensu-go/process"
 	"github.com/sensu/sensu-go/system"
 	"github.com/sensu/sensu-go/transport"
+	"github.com/sensu/sensu-go/transport/schema"
 	"github.com/sensu/sensu-go/util/retry"
 	utilstrings "github.com/sensu/sensu-go/util/strings"
+	"github.com/sensu/sensu-go/version"
 	"github.com/sirupsen/logrus"
 )
 
@@ -60,8 +63,39 @@ const (
 
 	// JSONSerializationHeader is the Content-Type header which indicates JSON serialization.
 	JSONSerializationHeader = "application/json"
+
+	// DefaultSerialization is the serialization requested by the agent when
+	// Config.Serialization is empty.
+	DefaultSerialization = "protobuf"
 )
 
+// serializationHeaders are the Content-Type headers of the serializations of
+// the messages of the sessions, by name.
+var serializationHeaders = map[string]string{
+	"protobuf": ProtobufSerializationHeader,
+	"msgpack":  MsgpackSerializationHeader,
+	"json":     JSONSerializationHeader,
+}
+
+// ValidateSerialization returns an error if name is not the name of a
+// serialization of the messages of the sessions.
+func ValidateSerialization(name string) error {
+	if _, ok := serializationHeaders[name]; !ok {
+		return fmt.Errorf("unknown serialization %q, expected protobuf, msgpack or json", name)
+	}
+	return nil
+}
+
+// negotiatedSerialization returns the Content-Type of the messages of a
+// session, from the handshake response of the backend: the requested one if
+// the backend accepts it, or JSON, which the backend falls back to.
+func negotiatedSerialization(requested string, respHeader http.Header) string {
+	if utilstrings.InArray(requested, respHeader["Accept"]) {
+		return requested
+	}
+	return JSONSerializationHeader
+}
+
 // MarshalFunc is the function signature for protobuf/JSON marshaling.
 type MarshalFunc = func(pb proto.Message) ([]byte, error)
 
@@ -140,10 +174,15 @@ type Agent struct {
 	api                *http.Server
 	assetGetter        asset.Getter
 	backendSelector    BackendSelector
+	backoffMu          sync.Mutex
+	backoffUntil       time.Time
 	config             *Config
 	connected          bool
 	connectedMu        sync.RWMutex
 	contentType        string
+	eventAcks          bool
+	protocolVersion    int
+	pendingEvents      *pendingEvents
 	entityConfig       *corev3.EntityConfig
 	entityConfigCh     chan struct{}
 	entityMu           sync.Mutex
@@ -153,10 +192,14 @@ type Agent struct {
 	inProgress         map[string]*corev2.CheckConfig
 	inProgressMu       *sync.Mutex
 	localEntityConfig  *corev3.EntityConfig
+	logTails           []*logTail
 	statsdServer       StatsdServer
 	sendq              chan *transport.Message
 	systemInfo         *corev2.System
 	systemInfoMu       sync.RWMutex
+	throttle           *rate.Limiter
+	throttleMu         sync.Mutex
+	throttleUntil      time.Time
 	wg                 sync.WaitGroup
 	apiQueue           queue
 	marshal            MarshalFunc
@@ -185,6 +228,15 @@ func NewAgentContext(ctx context.Context, config *Config) (*Agent, error) {
 	if to := config.KeepaliveWarningTimeout; to > 0 && to <= config.KeepaliveInterval {
 		return nil, errors.New("keepalive warning timeout must be greater than keepalive interval")
 	}
+	if config.EventBatchSize > transport.MaxEventBatchSize {
+		return nil, fmt.Errorf("event batch size must be lower than or equal to %d", transport.MaxEventBatchSize)
+	}
+	// The send queue keeps its default size, and only grows to hold the
+	// events of a full event batch when the batches are larger
+	sendQueueSize := DefaultEventBatchSize
+	if config.EventBatchSize > sendQueueSize {
+		sendQueueSize = config.EventBatchSize
+	}
 	agent := &Agent{
 		backendSelector:  &RandomBackendSelector{Backends: config.BackendURLs},
 		connected:        false,
@@ -194,17 +246,23 @@ func NewAgentContext(ctx context.Context, config *Config) (*Agent, error) {
 		entityConfigCh:   make(chan struct{}),
 		inProgress:       make(map[string]*corev2.CheckConfig),
 		inProgressMu:     &sync.Mutex{},
-		sendq:            make(chan *transport.Message, 10),
+		sendq:            make(chan *transport.Message, sendQueueSize),
+		protocolVersion:  transport.ProtocolVersionLegacy,
 		systemInfo:       &corev2.System{},
 		unmarshal:        UnmarshalJSON,
 		marshal:          MarshalJSON,
 		ProcessGetter:    &process.NoopProcessGetter{},
 		sequences:        make(map[string]int64),
 		maxSessionLength: config.MaxSessionLength,
+		pendingEvents:    newPendingEvents(maxPendingEvents),
 	}
 
 	agent.statsdServer = NewStatsdServer(agent)
 	agent.handler.AddHandler(transport.MessageTypeEntityConfig, agent.handleEntityConfig)
+	agent.handler.AddHandler(transport.MessageTypeBackoff, agent.handleBackoff)
+	agent.handler.AddHandler(transport.MessageTypeEventAck, agent.handleEventAck)
+	agent.handler.AddHandler(transport.MessageTypeThrottle, agent.handleThrottle)
+	agent.handler.AddHandler(transport.MessageTypeEventRejected, agent.handleEventRejected)
 
 	// We don't check for errors here and let the agent get created regardless
 	// of system info status.
@@ -226,6 +284,12 @@ func NewAgentContext(ctx context.Context, config *Config) (*Agent, error) {
 	}
 	agent.allowList = allowList
 
+	logTails, err := readLogTails(config.LogTail, ioutil.ReadFile)
+	if err != nil {
+		return nil, err
+	}
+	agent.logTails = logTails
+
 	if config.PrometheusBinding != "" {
 		go func() {
 			logger.WithError(http.ListenAndServe(config.PrometheusBinding, promhttp.Handler())).Error("couldn't serve prometheus metrics")
@@ -295,6 +359,8 @@ func (a *Agent) buildTransportHeaderMap() http.Header {
 	header := http.Header{}
 	header.Set(transport.HeaderKeyNamespace, a.config.Namespace)
 	header.Set(transport.HeaderKeyAgentName, a.config.AgentName)
+	header.Set(transport.HeaderKeyAgentVersion, version.Semver())
+	header.Set(transport.HeaderKeyWireSchema, schema.Fingerprint())
 	if tls := a.config.TLS; tls == nil || len(tls.CertFile) == 0 && len(tls.KeyFile) == 0 {
 		logger.Info("using password auth")
 		header.Set(transport.HeaderKeyUser, a.config.User)
@@ -305,6 +371,13 @@ func (a *Agent) buildTransportHeaderMap() http.Header {
 		logger.Info("using tls client auth")
 	}
 	header.Set(transport.HeaderKeySubscriptions, strings.Join(a.config.Subscriptions, ","))
+	header.Set(transport.HeaderKeyProtocolVersion, strconv.Itoa(transport.ProtocolVersion))
+	header.Set(transport.HeaderKeyEventAcks, "true")
+	header.Set(transport.HeaderKeyThrottle, "true")
+	header.Set(transport.HeaderKeyEventRejections, "true")
+	if len(a.config.Compression) > 0 {
+		header.Set(transport.HeaderKeyCompression, strings.Join(a.config.Compression, ","))
+	}
 
 	return header
 }
@@ -355,8 +428,8 @@ func (a *Agent) Run(ctx context.Context) error {
 		if u, err := url.Parse(burl); err != nil {
 			return fmt.Errorf("bad backend URL (%s): %s", burl, err)
 		} else {
-			if u.Scheme != "ws" && u.Scheme != "wss" {
-				return fmt.Errorf("backend URL (%s) must have ws:// or wss:// scheme", burl)
+			if u.Scheme != "ws" && u.Scheme != "wss" && !transport.IsGRPCURL(u) {
+				return fmt.Errorf("backend URL (%s) must have ws://, wss://, grpc:// or grpcs:// scheme", burl)
 			}
 		}
 	}
@@ -398,6 +471,9 @@ func (a *Agent) Run(ctx context.Context) error {
 		a.StartAPI(ctx)
 	}
 
+	a.StartLogTails(ctx)
+	a.StartContainers(ctx)
+
 	// Increment the waitgroup counter here too in case none of the components
 	// above were started, and rely on the system info collector to decrement it
 	// once it exits
@@ -574,6 +650,33 @@ func logEvent(e *corev2.Event) {
 	logger.WithFields(fields).Info("sending event to backend")
 }
 
+// prepareMessage returns false if the message msg taken from the send queue
+// must be dropped. The events are tracked and throttled before they are sent.
+func (a *Agent) prepareMessage(ctx context.Context, msg *transport.Message) bool {
+	// Backends that speak an older protocol version can't handle the
+	// messages introduced by later versions
+	if !transport.SupportsMessageType(a.protocolVersion, msg.Type) {
+		messagesDropped.WithLabelValues().Inc()
+		err := fmt.Errorf("message type %q is not supported by protocol version %d", msg.Type, a.protocolVersion)
+		logger.WithError(err).Warning("dropping message unsupported by the backend")
+		if msg.SendCallback != nil {
+			msg.SendCallback(err)
+		}
+		return false
+	}
+	// The event is tracked before it is sent, so that it is sent again if
+	// the connection is lost
+	a.trackEvent(msg)
+	// Events are sent at the rate asked by the backend while it is
+	// saturated
+	if err := a.waitThrottle(ctx, msg); err != nil {
+		// The connection is closing
+		messagesDropped.WithLabelValues().Inc()
+		return false
+	}
+	return true
+}
+
 func (a *Agent) sendLoop(ctx context.Context, cancel context.CancelFunc, conn transport.Transport) error {
 	defer cancel()
 	keepalive := time.NewTicker(time.Duration(a.config.KeepaliveInterval) * time.Second)
@@ -582,6 +685,10 @@ func (a *Agent) sendLoop(ctx context.Context, cancel context.CancelFunc, conn tr
 		logger.WithError(err).Error("error sending message over websocket")
 		return err
 	}
+	if err := a.redeliverEvents(conn); err != nil {
+		logger.WithError(err).Error("error sending message over websocket")
+		return err
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -591,12 +698,23 @@ func (a *Agent) sendLoop(ctx context.Context, cancel context.CancelFunc, conn tr
 			}
 			return nil
 		case msg := <-a.sendq:
-			if err := conn.Send(msg); err != nil {
-				messagesDropped.WithLabelValues().Inc()
-				logger.WithError(err).Error("error sending message over websocket")
-				return err
+			// The small events queued behind msg are sent with it in an
+			// event batch, and the message that could not be added to the
+			// batch is sent next
+			for msg != nil {
+				if !a.prepareMessage(ctx, msg) {
+					break
+				}
+				var next *transport.Message
+				msg, next = a.batchEvents(ctx, msg)
+				if err := conn.Send(msg); err != nil {
+					messagesDropped.WithLabelValues().Inc()
+					logger.WithError(err).Error("error sending message over websocket")
+					return err
+				}
+				messagesSent.WithLabelValues().Inc()
+				msg = next
 			}
-			messagesSent.WithLabelValues().Inc()
 		case <-keepalive.C:
 			if err := conn.Send(a.newKeepalive()); err != nil {
 				messagesDropped.WithLabelValues().Inc()
@@ -736,16 +854,31 @@ func (a *Agent) connectWithBackoff(ctx context.Context) (transport.Transport, er
 		backendURL := a.backendSelector.Select()
 
 		logger.Infof("connecting to backend URL %q", backendURL)
-		a.header.Set("Accept", ProtobufSerializationHeader)
-		logger.WithField("header", fmt.Sprintf("Accept: %s", ProtobufSerializationHeader)).Debug("setting header")
+		serialization := a.config.Serialization
+		if serialization == "" {
+			serialization = DefaultSerialization
+		}
+		accept := serializationHeaders[serialization]
+		a.header.Set("Accept", accept)
+		logger.WithField("header", fmt.Sprintf("Accept: %s", accept)).Debug("setting header")
 		c, respHeader, err := transport.Connect(backendURL, a.config.TLS, a.header, a.config.BackendHandshakeTimeout)
 		if err != nil {
-			if err == transport.ErrTooManyRequests {
+			if errors.Is(err, transport.ErrTooManyRequests) {
 				// Give the backend extra breathing room
 				logger.WithError(err).Error("backend overloaded, increasing retry delay")
 				backoff.InitialDelayInterval = a.config.RetryMin * 2
 				backoff.MaxDelayInterval = a.config.RetryMax * 2
 				backoff.Multiplier = a.config.RetryMultiplier * 2
+				// The backend asks when to connect again when it refuses
+				// the agent session
+				var tooManyRequests transport.TooManyRequestsError
+				if errors.As(err, &tooManyRequests) && tooManyRequests.RetryAfter > 0 {
+					select {
+					case <-time.After(tooManyRequests.RetryAfter):
+					case <-ctx.Done():
+						return false, ctx.Err()
+					}
+				}
 			} else {
 				backoff.InitialDelayInterval = a.config.RetryMin
 				backoff.MaxDelayInterval = a.config.RetryMax
@@ -761,18 +894,28 @@ func (a *Agent) connectWithBackoff(ctx context.Context) (transport.Transport, er
 		conn = c
 
 		logger.WithField("header", fmt.Sprintf("Accept: %s", respHeader["Accept"])).Debug("received header")
-		if utilstrings.InArray(ProtobufSerializationHeader, respHeader["Accept"]) {
-			a.contentType = ProtobufSerializationHeader
+		a.contentType = negotiatedSerialization(accept, respHeader)
+		switch a.contentType {
+		case ProtobufSerializationHeader:
 			a.unmarshal = proto.Unmarshal
 			a.marshal = proto.Marshal
 			logger.WithField("format", "protobuf").Debug("setting serialization/deserialization")
-		} else {
-			a.contentType = JSONSerializationHeader
+		case MsgpackSerializationHeader:
+			a.unmarshal = UnmarshalMsgpack
+			a.marshal = MarshalMsgpack
+			logger.WithField("format", "msgpack").Debug("setting serialization/deserialization")
+		default:
 			a.unmarshal = UnmarshalJSON
 			a.marshal = MarshalJSON
 			logger.WithField("format", "JSON").Debug("setting serialization/deserialization")
 		}
 		a.header.Set("Content-Type", a.contentType)
+		a.eventAcks = respHeader.Get(transport.HeaderKeyEventAcks) == "true"
+		a.protocolVersion = negotiatedProtocolVersion(respHeader)
+		logger.WithField("protocol_version", a.protocolVersion).Info("negotiated the protocol version of the session")
+		if compression := respHeader.Get(transport.HeaderKeyCompression); compression != "" {
+			logger.WithField("compression", compression).Info("compressing the messages of the session")
+		}
 		logger.WithField("header", fmt.Sprintf("Content-Type: %s", a.contentType)).Debug("setting header")
 
 		return true, nil
@@ -781,6 +924,18 @@ func (a *Agent) connectWithBackoff(ctx context.Context) (transport.Transport, er
 	return conn, err
 }
 
+// negotiatedProtocolVersion returns the protocol version of the session from
+// the response header of the backend. Backends that don't send it, or send a
+// version this agent doesn't speak, are spoken to with the legacy protocol.
+func negotiatedProtocolVersion(respHeader http.Header) int {
+	version, err := transport.ParseProtocolVersion(respHeader.Get(transport.HeaderKeyProtocolVersion))
+	if err != nil || version > transport.ProtocolVersion {
+		logger.WithField("protocol_version", respHeader.Get(transport.HeaderKeyProtocolVersion)).Warning("backend responded with an unsupported protocol version, using the legacy protocol")
+		return transport.ProtocolVersionLegacy
+	}
+	return version
+}
+
 // GracefulShutdown listens for the SIGINT & SIGTERM signals and cancel the
 // contexts once a signal is received.
 func GracefulShutdown(cancel context.CancelFunc) {
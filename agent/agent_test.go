@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -349,3 +350,44 @@ func TestConnectionManager(t *testing.T) {
 	// Give time for a potential reconnect by the connection manager
 	time.Sleep(3 * time.Second)
 }
+
+func TestNegotiatedSerialization(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		accept    []string
+		want      string
+	}{
+		{name: "protobuf", requested: ProtobufSerializationHeader, accept: []string{ProtobufSerializationHeader, JSONSerializationHeader, MsgpackSerializationHeader}, want: ProtobufSerializationHeader},
+		{name: "msgpack", requested: MsgpackSerializationHeader, accept: []string{ProtobufSerializationHeader, JSONSerializationHeader, MsgpackSerializationHeader}, want: MsgpackSerializationHeader},
+		{name: "backend without msgpack", requested: MsgpackSerializationHeader, accept: []string{ProtobufSerializationHeader, JSONSerializationHeader}, want: JSONSerializationHeader},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{"Accept": tt.accept}
+			assert.Equal(t, tt.want, negotiatedSerialization(tt.requested, header))
+		})
+	}
+}
+
+func TestNegotiatedProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{name: "legacy backend", header: "", want: transport.ProtocolVersionLegacy},
+		{name: "negotiated version", header: strconv.Itoa(transport.ProtocolVersion), want: transport.ProtocolVersion},
+		{name: "invalid version", header: "latest", want: transport.ProtocolVersionLegacy},
+		{name: "version newer than the agent", header: strconv.Itoa(transport.ProtocolVersion + 1), want: transport.ProtocolVersionLegacy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := make(http.Header)
+			if tt.header != "" {
+				header.Set(transport.HeaderKeyProtocolVersion, tt.header)
+			}
+			assert.Equal(t, tt.want, negotiatedProtocolVersion(header))
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	time "github.com/echlebek/timeproxy"
+)
+
+// handleBackoff handles the backoff messages sent by the backend when it
+// rejects the events of the agent because of its event rate limits. The
+// payload is the number of seconds for which the agent skips its check
+// executions.
+func (a *Agent) handleBackoff(ctx context.Context, payload []byte) error {
+	seconds, err := strconv.Atoi(strings.TrimSpace(string(payload)))
+	if err != nil || seconds <= 0 {
+		return fmt.Errorf("invalid backoff payload: %q", payload)
+	}
+	until := time.Now().Add(time.Duration(seconds) * time.Second)
+
+	a.backoffMu.Lock()
+	defer a.backoffMu.Unlock()
+	if until.After(a.backoffUntil) {
+		a.backoffUntil = until
+	}
+	logger.WithField("seconds", seconds).Warn("events rate limited by the backend, backing off")
+
+	return nil
+}
+
+// backingOff returns true if the agent was asked to back off by the backend.
+func (a *Agent) backingOff() bool {
+	a.backoffMu.Lock()
+	defer a.backoffMu.Unlock()
+	return time.Now().Before(a.backoffUntil)
+}
@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHandleBackoff(t *testing.T) {
+	cfg, cleanup := FixtureConfig()
+	defer cleanup()
+	a, err := NewAgent(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.backingOff() {
+		t.Fatal("agent should not be backing off")
+	}
+	if err := a.handleBackoff(context.Background(), []byte("foo")); err == nil {
+		t.Fatal("expected an error for an invalid payload")
+	}
+	if err := a.handleBackoff(context.Background(), []byte("0")); err == nil {
+		t.Fatal("expected an error for a zero backoff")
+	}
+	if err := a.handleBackoff(context.Background(), []byte("30")); err != nil {
+		t.Fatal(err)
+	}
+	if !a.backingOff() {
+		t.Fatal("agent should be backing off")
+	}
+	// a shorter backoff does not shorten the current one
+	until := a.backoffUntil
+	if err := a.handleBackoff(context.Background(), []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if !a.backoffUntil.Equal(until) {
+		t.Errorf("backoff shortened: got %v, want %v", a.backoffUntil, until)
+	}
+}
@@ -38,6 +38,13 @@ func (a *Agent) handleCheck(ctx context.Context, payload []byte) error {
 	}
 
 	checkConfig := request.Config
+
+	// skip the check executions while the backend rate limits our events
+	if a.backingOff() {
+		logger.WithField("check", checkConfig.Name).Debug("backing off, skipping check execution")
+		return nil
+	}
+
 	sendFailure := func(err error) {
 		check := corev2.NewCheck(checkConfig)
 		check.Executed = time.Now().Unix()
@@ -157,12 +164,18 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 		"assets":    check.RuntimeAssets,
 	}
 
-	// Match check against allow list
+	// Match check against allow list. Prometheus scrape checks don't execute
+	// their command, and are matched by their scrape URL instead.
+	allowCommand := checkConfig.Command
+	spec, scrape := check.Annotations[prometheusScrapeAnnotation]
+	if scrape {
+		allowCommand = prometheusScrapeCommand(spec)
+	}
 	var matchedEntry allowList
 	var match bool
 	if len(a.allowList) != 0 {
 		logger.WithFields(fields).Debug("matching check against agent allow list")
-		matchedEntry, match = a.matchAllowList(checkConfig.Command)
+		matchedEntry, match = a.matchAllowList(allowCommand)
 		if !match {
 			logger.WithFields(fields).Debug("check does not match agent allow list")
 			a.sendFailure(event, fmt.Errorf(allowListOnDenyOutput))
@@ -195,7 +208,7 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 	}
 
 	// Verify sha against the allow list
-	if matchedEntry.Sha512 != "" {
+	if matchedEntry.Sha512 != "" && !scrape {
 		logger.WithFields(fields).Debug("matching check sha against agent allow list")
 		path, err := lookPath(strings.Split(checkConfig.Command, " ")[0], env)
 		if err != nil {
@@ -238,7 +251,16 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 		ex.Input = string(input)
 	}
 
-	checkExec, err := a.executor.Execute(context.Background(), ex)
+	var scraped []*corev2.MetricPoint
+	var checkExec *command.ExecutionResponse
+	var err error
+	if scrape {
+		// Prometheus scrape checks scrape metrics instead of executing the
+		// check command.
+		scraped, checkExec, err = scrapePrometheus(ctx, check, spec)
+	} else {
+		checkExec, err = a.executor.Execute(context.Background(), ex)
+	}
 	if err != nil {
 		event.Check.Output = err.Error()
 		checkExec.Status = 3
@@ -256,13 +278,27 @@ func (a *Agent) executeCheck(ctx context.Context, request *corev2.CheckRequest,
 		event.ID = id[:]
 	}
 
+	// Parse structured check output. The metrics of prometheus scrape checks
+	// and of checks with an output format are not extracted with the output
+	// metric format.
+	var points []*corev2.MetricPoint
+	_, structured := check.Annotations[outputFormatAnnotation]
+	switch {
+	case scrape:
+		points = scraped
+	case structured:
+		points = parseCheckOutput(event)
+	case check.OutputMetricFormat != "":
+		points = extractMetrics(event)
+	}
+
 	// Instantiate metrics in the event if the check is attempting to extract metrics
-	if check.OutputMetricFormat != "" || len(check.OutputMetricHandlers) != 0 {
+	if check.OutputMetricFormat != "" || len(check.OutputMetricHandlers) != 0 || len(points) > 0 {
 		event.Metrics = &corev2.Metrics{}
 	}
 
-	if check.OutputMetricFormat != "" {
-		event.Metrics.Points = extractMetrics(event)
+	if event.Metrics != nil {
+		event.Metrics.Points = points
 
 		if event.Check.Status == 0 && len(event.Metrics.Points) > 0 && len(check.OutputMetricThresholds) > 0 {
 			event.Check.Status = evaluateOutputMetricThresholds(event)
@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/agent/transformers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// outputFormatAnnotation is the check annotation that declares the format
+	// of the check output. When it is set, the agent parses the output into
+	// the status, the human readable output and the metrics of the check.
+	outputFormatAnnotation = "sensu.io/output-format"
+
+	// OutputFormatNagiosPerfdata is the output format of nagios plugins. The
+	// human readable output is separated from the performance data by a pipe,
+	// and the status is the exit code of the plugin.
+	OutputFormatNagiosPerfdata = "nagios_perfdata"
+
+	// OutputFormatJSON is the output format of checks that print a JSON
+	// object, with optional status, output and metrics fields.
+	OutputFormatJSON = "json"
+
+	// checkStatusUnknown is the status of checks whose output cannot be parsed.
+	checkStatusUnknown = 3
+)
+
+// jsonCheckOutput is the JSON object printed by checks with the json output
+// format.
+type jsonCheckOutput struct {
+	// Status is the status of the check. The exit code of the check is used
+	// if it is not set.
+	Status *uint32 `json:"status"`
+
+	// Output is the human readable output of the check.
+	Output string `json:"output"`
+
+	// Metrics are the metrics measured by the check.
+	Metrics []jsonCheckMetric `json:"metrics"`
+}
+
+// jsonCheckMetric is a metric printed by checks with the json output format.
+type jsonCheckMetric struct {
+	Name      string            `json:"name"`
+	Value     float64           `json:"value"`
+	Timestamp int64             `json:"timestamp"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// parseCheckOutput parses the output of the check of event according to the
+// output format of the check. It replaces the status and output of the check
+// with the parsed ones, and returns the parsed metrics. The output is left as
+// is if the check does not declare an output format.
+func parseCheckOutput(event *corev2.Event) []*corev2.MetricPoint {
+	if !event.HasCheck() {
+		return nil
+	}
+	format := event.Check.Annotations[outputFormatAnnotation]
+	switch format {
+	case "":
+		return nil
+	case OutputFormatNagiosPerfdata:
+		return parseNagiosOutput(event.Check)
+	case OutputFormatJSON:
+		return parseJSONOutput(event.Check)
+	}
+	logger.WithFields(logrus.Fields{
+		"namespace": event.Check.Namespace,
+		"check":     event.Check.Name,
+		"format":    format,
+	}).Error("check output format is not supported")
+	return nil
+}
+
+// parseNagiosOutput parses the output of nagios plugins. The first line of the
+// output and the following lines up to the first pipe are the human readable
+// output, and everything after a pipe is performance data. The status of the
+// check is raised to the status of the warning and critical thresholds of the
+// performance data, if it is lower.
+func parseNagiosOutput(check *corev2.Check) []*corev2.MetricPoint {
+	var text, perfdata []string
+	inPerfdata := false
+	for i, line := range strings.Split(strings.TrimRight(check.Output, "\n"), "\n") {
+		if inPerfdata {
+			perfdata = append(perfdata, line)
+			continue
+		}
+		before, after, found := strings.Cut(line, "|")
+		text = append(text, strings.TrimRight(before, " "))
+		if found {
+			perfdata = append(perfdata, after)
+			// the perfdata of the first line is followed by the long
+			// output, and everything after the next pipe is perfdata.
+			inPerfdata = i > 0
+		}
+	}
+	check.Output = strings.Join(text, "\n")
+	if len(perfdata) == 0 {
+		return nil
+	}
+
+	// reuse the nagios metric transformer on the joined performance data
+	perfcheck := *check
+	perfcheck.Output = "|" + strings.Join(perfdata, " ")
+	perfevent := &corev2.Event{Check: &perfcheck}
+	if status := transformers.NagiosStatus(perfevent); status > check.Status {
+		check.Status = status
+	}
+	return transformers.ParseNagios(perfevent).Transform()
+}
+
+// parseJSONOutput parses the output of checks that print a JSON object. If
+// the output is not a valid JSON object, the status of the check is unknown.
+func parseJSONOutput(check *corev2.Check) []*corev2.MetricPoint {
+	var result jsonCheckOutput
+	if err := json.Unmarshal([]byte(check.Output), &result); err != nil {
+		check.Status = checkStatusUnknown
+		check.Output = fmt.Sprintf("error parsing json check output: %s\n%s", err, check.Output)
+		return nil
+	}
+	if result.Status != nil {
+		check.Status = *result.Status
+	}
+	check.Output = result.Output
+
+	var points []*corev2.MetricPoint
+	for _, metric := range result.Metrics {
+		if metric.Name == "" {
+			continue
+		}
+		timestamp := metric.Timestamp
+		if timestamp == 0 {
+			timestamp = check.Executed
+		}
+		tags := make([]*corev2.MetricTag, 0, len(check.OutputMetricTags)+len(metric.Tags))
+		tags = append(tags, check.OutputMetricTags...)
+		names := make([]string, 0, len(metric.Tags))
+		for name := range metric.Tags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			tags = append(tags, &corev2.MetricTag{Name: name, Value: metric.Tags[name]})
+		}
+		points = append(points, &corev2.MetricPoint{
+			Name:      metric.Name,
+			Value:     metric.Value,
+			Timestamp: timestamp,
+			Tags:      tags,
+		})
+	}
+	return points
+}
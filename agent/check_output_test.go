@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCheckOutput(t *testing.T) {
+	testCases := []struct {
+		name           string
+		format         string
+		output         string
+		status         uint32
+		expectedOutput string
+		expectedStatus uint32
+		expectedPoints []*corev2.MetricPoint
+	}{
+		{
+			name:           "no output format",
+			output:         "OK | load=1",
+			expectedOutput: "OK | load=1",
+		},
+		{
+			name:           "unsupported output format",
+			format:         "xml",
+			output:         "<ok/>",
+			expectedOutput: "<ok/>",
+		},
+		{
+			name:           "nagios without perfdata",
+			format:         OutputFormatNagiosPerfdata,
+			output:         "DISK OK\n",
+			expectedOutput: "DISK OK",
+		},
+		{
+			name:           "nagios perfdata",
+			format:         OutputFormatNagiosPerfdata,
+			output:         "DISK WARNING - free space: / 3326 MB | /=2643MB;5948;5958;0;5968\n/ 15272 MB\n/boot 68 MB | /boot=68MB;88;93;0;98\n/home=69357MB;253404;253409;0;253414\n",
+			status:         1,
+			expectedOutput: "DISK WARNING - free space: / 3326 MB\n/ 15272 MB\n/boot 68 MB",
+			expectedStatus: 1,
+			expectedPoints: []*corev2.MetricPoint{
+				{Name: "/", Value: 2643, Timestamp: 123456789, Tags: []*corev2.MetricTag{}},
+				{Name: "/boot", Value: 68, Timestamp: 123456789, Tags: []*corev2.MetricTag{}},
+				{Name: "/home", Value: 69357, Timestamp: 123456789, Tags: []*corev2.MetricTag{}},
+			},
+		},
+		{
+			name:           "nagios perfdata thresholds",
+			format:         OutputFormatNagiosPerfdata,
+			output:         "PING OK | loss=0%;20;60 rta=600ms;200;500\n",
+			expectedOutput: "PING OK",
+			expectedStatus: 2,
+			expectedPoints: []*corev2.MetricPoint{
+				{Name: "loss", Value: 0, Timestamp: 123456789, Tags: []*corev2.MetricTag{}},
+				{Name: "rta", Value: 600, Timestamp: 123456789, Tags: []*corev2.MetricTag{}},
+			},
+		},
+		{
+			name:           "json",
+			format:         OutputFormatJSON,
+			output:         `{"status": 2, "output": "queue is full", "metrics": [{"name": "queue.size", "value": 100, "tags": {"queue": "jobs"}}, {"value": 1}]}`,
+			expectedOutput: "queue is full",
+			expectedStatus: 2,
+			expectedPoints: []*corev2.MetricPoint{
+				{Name: "queue.size", Value: 100, Timestamp: 123456789, Tags: []*corev2.MetricTag{{Name: "queue", Value: "jobs"}}},
+			},
+		},
+		{
+			name:           "json without status",
+			format:         OutputFormatJSON,
+			output:         `{"output": "queue is empty"}`,
+			status:         1,
+			expectedOutput: "queue is empty",
+			expectedStatus: 1,
+		},
+		{
+			name:           "invalid json",
+			format:         OutputFormatJSON,
+			output:         "queue is full",
+			expectedOutput: "error parsing json check output: invalid character 'q' looking for beginning of value\nqueue is full",
+			expectedStatus: checkStatusUnknown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := corev2.FixtureCheck("check")
+			check.Executed = 123456789
+			check.Output = tc.output
+			check.Status = tc.status
+			if tc.format != "" {
+				check.Annotations = map[string]string{outputFormatAnnotation: tc.format}
+			}
+			points := parseCheckOutput(&corev2.Event{Check: check})
+			assert.Equal(t, tc.expectedPoints, points)
+			assert.Equal(t, tc.expectedOutput, check.Output)
+			assert.Equal(t, tc.expectedStatus, check.Status)
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/agent"
 	"github.com/sensu/sensu-go/asset"
+	"github.com/sensu/sensu-go/transport"
 	"github.com/sensu/sensu-go/util/path"
 	"github.com/sensu/sensu-go/util/url"
 	"github.com/sirupsen/logrus"
@@ -72,6 +73,15 @@ const (
 	flagLabels                    = "labels"
 	flagAnnotations               = "annotations"
 	flagAllowList                 = "allow-list"
+	flagLogTail                   = "log-tail"
+	flagContainerSocket           = "container-socket"
+	flagContainerRuntime          = "container-runtime"
+	flagContainerNamespace        = "container-namespace"
+	flagContainerInterval         = "container-interval"
+	flagContainerLabels           = "container-labels"
+	flagContainerNames            = "container-names"
+	flagContainerHandlers         = "container-handlers"
+	flagContainerMetrics          = "container-metrics"
 	flagBackendHandshakeTimeout   = "backend-handshake-timeout"
 	flagBackendHeartbeatInterval  = "backend-heartbeat-interval"
 	flagBackendHeartbeatTimeout   = "backend-heartbeat-timeout"
@@ -81,6 +91,9 @@ const (
 	flagRetryMultiplier           = "retry-multiplier"
 	flagMaxSessionLength          = "max-session-length"
 	flagStripNetworks             = "strip-networks"
+	flagCompression               = "compression"
+	flagSerialization             = "serialization"
+	flagEventBatchSize            = "event-batch-size"
 
 	// TLS flags
 	flagTrustedCAFile         = "trusted-ca-file"
@@ -137,6 +150,15 @@ func NewAgentConfig(cmd *cobra.Command) (*agent.Config, error) {
 	cfg.StatsdServer.Handlers = viper.GetStringSlice(flagStatsdEventHandlers)
 	cfg.User = viper.GetString(flagUser)
 	cfg.AllowList = viper.GetString(flagAllowList)
+	cfg.LogTail = viper.GetString(flagLogTail)
+	cfg.Containers.Socket = viper.GetString(flagContainerSocket)
+	cfg.Containers.Runtime = viper.GetString(flagContainerRuntime)
+	cfg.Containers.Namespace = viper.GetString(flagContainerNamespace)
+	cfg.Containers.Interval = viper.GetInt(flagContainerInterval)
+	cfg.Containers.Labels = viper.GetStringSlice(flagContainerLabels)
+	cfg.Containers.Names = viper.GetStringSlice(flagContainerNames)
+	cfg.Containers.Handlers = viper.GetStringSlice(flagContainerHandlers)
+	cfg.Containers.Metrics = viper.GetBool(flagContainerMetrics)
 	cfg.BackendHandshakeTimeout = viper.GetInt(flagBackendHandshakeTimeout)
 	cfg.BackendHeartbeatInterval = viper.GetInt(flagBackendHeartbeatInterval)
 	cfg.BackendHeartbeatTimeout = viper.GetInt(flagBackendHeartbeatTimeout)
@@ -145,6 +167,9 @@ func NewAgentConfig(cmd *cobra.Command) (*agent.Config, error) {
 	cfg.RetryMultiplier = viper.GetFloat64(flagRetryMultiplier)
 	cfg.MaxSessionLength = viper.GetDuration(flagMaxSessionLength)
 	cfg.StripNetworks = viper.GetBool(flagStripNetworks)
+	cfg.Compression = viper.GetStringSlice(flagCompression)
+	cfg.Serialization = viper.GetString(flagSerialization)
+	cfg.EventBatchSize = viper.GetInt(flagEventBatchSize)
 
 	// Set the labels & annotations using values defined configuration files
 	// and/or environment variables for now
@@ -163,6 +188,18 @@ func NewAgentConfig(cmd *cobra.Command) (*agent.Config, error) {
 			flagKeepaliveCriticalTimeout, flagKeepaliveWarningTimeout)
 	}
 
+	if err := transport.ValidateCompressions(cfg.Compression); err != nil {
+		return nil, fmt.Errorf("invalid --%s: %s", flagCompression, err)
+	}
+
+	if err := agent.ValidateSerialization(cfg.Serialization); err != nil {
+		return nil, fmt.Errorf("invalid --%s: %s", flagSerialization, err)
+	}
+
+	if cfg.EventBatchSize > transport.MaxEventBatchSize {
+		return nil, fmt.Errorf("--%s must be lower than or equal to %d", flagEventBatchSize, transport.MaxEventBatchSize)
+	}
+
 	agentName := viper.GetString(flagAgentName)
 	if agentName != "" {
 		cfg.AgentName = agentName
@@ -312,6 +349,12 @@ func handleConfig(cmd *cobra.Command, arguments []string) error {
 	viper.SetDefault(flagStatsdMetricsHost, agent.DefaultStatsdMetricsHost)
 	viper.SetDefault(flagStatsdMetricsPort, agent.DefaultStatsdMetricsPort)
 	viper.SetDefault(flagStatsdEventHandlers, []string{})
+	viper.SetDefault(flagContainerRuntime, agent.ContainerRuntimeDocker)
+	viper.SetDefault(flagContainerNamespace, agent.DefaultContainerdNamespace)
+	viper.SetDefault(flagContainerInterval, agent.DefaultContainerInterval)
+	viper.SetDefault(flagContainerLabels, []string{})
+	viper.SetDefault(flagContainerNames, []string{})
+	viper.SetDefault(flagContainerHandlers, []string{})
 	viper.SetDefault(flagSubscriptions, []string{})
 	viper.SetDefault(flagUser, agent.DefaultUser)
 	viper.SetDefault(flagTrustedCAFile, "")
@@ -325,6 +368,9 @@ func handleConfig(cmd *cobra.Command, arguments []string) error {
 	viper.SetDefault(flagRetryMultiplier, 2.0)
 	viper.SetDefault(flagMaxSessionLength, 0*time.Second)
 	viper.SetDefault(flagStripNetworks, false)
+	viper.SetDefault(flagCompression, []string{})
+	viper.SetDefault(flagSerialization, agent.DefaultSerialization)
+	viper.SetDefault(flagEventBatchSize, agent.DefaultEventBatchSize)
 
 	// Merge in flag set so that it appears in command usage
 	flags := flagSet()
@@ -421,7 +467,7 @@ func flagSet() *pflag.FlagSet {
 	flagSet.Int(flagStatsdMetricsPort, viper.GetInt(flagStatsdMetricsPort), "port used for the statsd metrics server")
 	flagSet.StringSlice(flagSubscriptions, viper.GetStringSlice(flagSubscriptions), "comma-delimited list of agent subscriptions. This flag can also be invoked multiple times")
 	flagSet.String(flagUser, viper.GetString(flagUser), "agent user")
-	flagSet.StringSlice(flagBackendURL, viper.GetStringSlice(flagBackendURL), "comma-delimited list of ws/wss URLs of Sensu backend servers. This flag can also be invoked multiple times")
+	flagSet.StringSlice(flagBackendURL, viper.GetStringSlice(flagBackendURL), "comma-delimited list of ws/wss URLs of Sensu backend servers, or grpc/grpcs URLs of their gRPC listener. This flag can also be invoked multiple times")
 	flagSet.StringSlice(flagKeepaliveHandlers, viper.GetStringSlice(flagKeepaliveHandlers), "comma-delimited list of keepalive handlers for this entity. This flag can also be invoked multiple times")
 	flagSet.Int(flagKeepaliveInterval, viper.GetInt(flagKeepaliveInterval), "number of seconds to send between keepalive events")
 	flagSet.Uint32(flagKeepaliveWarningTimeout, uint32(viper.GetInt(flagKeepaliveWarningTimeout)), "number of seconds until agent is considered dead by backend to create a warning event")
@@ -439,6 +485,15 @@ func flagSet() *pflag.FlagSet {
 	flagSet.StringToStringVar(&labels, flagLabels, nil, "entity labels map")
 	flagSet.StringToStringVar(&annotations, flagAnnotations, nil, "entity annotations map")
 	flagSet.String(flagAllowList, viper.GetString(flagAllowList), "path to agent execution allow list configuration file")
+	flagSet.String(flagLogTail, viper.GetString(flagLogTail), "path to agent log tail configuration file")
+	flagSet.String(flagContainerSocket, viper.GetString(flagContainerSocket), "path to the API socket of the container runtime, enables container checks (e.g. /var/run/docker.sock)")
+	flagSet.String(flagContainerRuntime, viper.GetString(flagContainerRuntime), "API of the container runtime [docker, containerd]")
+	flagSet.String(flagContainerNamespace, viper.GetString(flagContainerNamespace), "containerd namespace of the checked containers (e.g. k8s.io)")
+	flagSet.Int(flagContainerInterval, viper.GetInt(flagContainerInterval), "number of seconds between container checks")
+	flagSet.StringSlice(flagContainerLabels, viper.GetStringSlice(flagContainerLabels), "comma-delimited list of key=value labels that checked containers must have")
+	flagSet.StringSlice(flagContainerNames, viper.GetStringSlice(flagContainerNames), "comma-delimited list of name patterns of the checked containers")
+	flagSet.StringSlice(flagContainerHandlers, viper.GetStringSlice(flagContainerHandlers), "comma-delimited list of handlers for container events")
+	flagSet.Bool(flagContainerMetrics, viper.GetBool(flagContainerMetrics), "enable container resource usage metrics")
 	flagSet.Int(flagBackendHandshakeTimeout, viper.GetInt(flagBackendHandshakeTimeout), "number of seconds the agent should wait when negotiating a new WebSocket connection")
 	flagSet.Int(flagBackendHeartbeatInterval, viper.GetInt(flagBackendHeartbeatInterval), "interval at which the agent should send heartbeats to the backend")
 	flagSet.Int(flagBackendHeartbeatTimeout, viper.GetInt(flagBackendHeartbeatTimeout), "number of seconds the agent should wait for a response to a hearbeat")
@@ -448,6 +503,9 @@ func flagSet() *pflag.FlagSet {
 	flagSet.Float64(flagRetryMultiplier, viper.GetFloat64(flagRetryMultiplier), "value multiplied with the current retry delay to produce a longer retry delay (bounded by --retry-max)")
 	flagSet.Duration(flagMaxSessionLength, viper.GetDuration(flagMaxSessionLength), "maximum amount of time after which the agent will reconnect to one of the configured backends (no maximum by default)")
 	flagSet.Bool(flagStripNetworks, viper.GetBool(flagStripNetworks), "do not include Network info in agent entity state")
+	flagSet.StringSlice(flagCompression, viper.GetStringSlice(flagCompression), "comma-delimited list of the compression algorithms offered to the backend, in order of preference [zstd, deflate]")
+	flagSet.String(flagSerialization, viper.GetString(flagSerialization), "serialization of the messages requested from the backend [protobuf, msgpack, json]")
+	flagSet.Int(flagEventBatchSize, viper.GetInt(flagEventBatchSize), "maximum number of small events sent to the backend in one message, 1 to send them one by one")
 
 	flagSet.SetOutput(ioutil.Discard)
 
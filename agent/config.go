@@ -33,6 +33,10 @@ const (
 	// effect.
 	DefaultEventsAPIBurstLimit int = 10
 
+	// DefaultEventBatchSize specifies the default maximum number of events of
+	// the event batches, which is the default size of the send queue.
+	DefaultEventBatchSize = 10
+
 	// DefaultKeepaliveInterval specifies the default keepalive interval
 	DefaultKeepaliveInterval = 20
 
@@ -79,13 +83,17 @@ type Config struct {
 	// AssetsBurstLimit is the maximum amount of burst allowed in a rate interval.
 	AssetsBurstLimit int
 
-	// BackendURLs is a list of URLs for the Sensu Backend. Default:
-	// ws://127.0.0.1:8081
+	// BackendURLs is a list of URLs for the Sensu Backend. The agent connects
+	// over gRPC to the URLs with the grpc:// or grpcs:// scheme, and over
+	// WebSocket to the others. Default: ws://127.0.0.1:8081
 	BackendURLs []string
 
 	// CacheDir path where cached data is stored
 	CacheDir string
 
+	// Containers contains the container runtime provider configuration
+	Containers *ContainerConfig
+
 	// Deregister indicates whether the entity is ephemeral
 	Deregister bool
 
@@ -144,6 +152,10 @@ type Config struct {
 	// Annotations are key-value pairs that users can provide to agent entities
 	Annotations map[string]string
 
+	// LogTail is the path to the agent log tail configuration file, which
+	// configures the log files tailed by the agent.
+	LogTail string
+
 	// Namespace sets the Agent's RBAC namespace identifier
 	Namespace string
 
@@ -211,6 +223,22 @@ type Config struct {
 	// StripNetworks is a boolean to specify if we need to strip network
 	// information from the agent entity state
 	StripNetworks bool
+
+	// Compression is the list of the compression algorithms offered to the
+	// backend for the messages of the sessions, in order of preference. The
+	// messages are not compressed if it is empty.
+	Compression []string
+
+	// Serialization is the serialization of the messages of the sessions
+	// requested from the backend: protobuf, msgpack or json. The messages
+	// are serialized with JSON if the backend doesn't accept it.
+	Serialization string
+
+	// EventBatchSize is the maximum number of small events packed into one
+	// event batch message, up to transport.MaxEventBatchSize. The events are
+	// sent one by one if it is lower than 2, or if the backend doesn't
+	// handle event batches.
+	EventBatchSize int
 }
 
 // StatsdServerConfig contains the statsd server configuration
@@ -267,6 +295,7 @@ func NewConfig() *Config {
 	c := &Config{
 		API:          &APIConfig{},
 		StatsdServer: &StatsdServerConfig{},
+		Containers:   &ContainerConfig{},
 	}
 	return c
 }
@@ -0,0 +1,255 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// DefaultContainerdNamespace is the default containerd namespace of the
+	// checked containers.
+	DefaultContainerdNamespace = "default"
+
+	// containerdNamespaceHeader is the gRPC header of the containerd
+	// namespace of a request.
+	containerdNamespaceHeader = "containerd-namespace"
+
+	containerdListContainers = "/containerd.services.containers.v1.Containers/List"
+	containerdListTasks      = "/containerd.services.tasks.v1.Tasks/List"
+)
+
+// The statuses of containerd tasks, from containerd.v1.types.Status.
+const (
+	containerdTaskCreated = 1
+	containerdTaskRunning = 2
+	containerdTaskStopped = 3
+	containerdTaskPaused  = 4
+	containerdTaskPausing = 5
+)
+
+// containerdNameLabels are the labels in which nerdctl and Kubernetes store
+// the names of the containers, since containerd only knows their ids.
+var containerdNameLabels = []string{"nerdctl/name", "io.kubernetes.container.name"}
+
+// rawCodec passes the protobuf messages of the containerd API as they are
+// encoded, so that the agent doesn't depend on the containerd API packages.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("can't marshal %T", v)
+	}
+	return b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("can't unmarshal into %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// containerdClient queries the gRPC API of containerd.
+type containerdClient struct {
+	conn      *grpc.ClientConn
+	namespace string
+}
+
+func newContainerdClient(socket, namespace string) (*containerdClient, error) {
+	if namespace == "" {
+		namespace = DefaultContainerdNamespace
+	}
+	conn, err := grpc.Dial(socket,
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", addr)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &containerdClient{conn: conn, namespace: namespace}, nil
+}
+
+func (c *containerdClient) invoke(ctx context.Context, method string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, containerAPITimeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, containerdNamespaceHeader, c.namespace)
+	var resp []byte
+	if err := c.conn.Invoke(ctx, method, []byte{}, &resp, grpc.ForceCodec(rawCodec{})); err != nil {
+		return nil, fmt.Errorf("container runtime API error: %s: %s", method, err)
+	}
+	return resp, nil
+}
+
+// list returns every container of the namespace, with the state of its task.
+func (c *containerdClient) list(ctx context.Context) ([]dockerContainer, error) {
+	b, err := c.invoke(ctx, containerdListContainers)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := decodeContainerdContainers(b)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = c.invoke(ctx, containerdListTasks); err != nil {
+		return nil, err
+	}
+	tasks, err := decodeContainerdTasks(b)
+	if err != nil {
+		return nil, err
+	}
+	for i := range containers {
+		task, ok := tasks[containers[i].ID]
+		containers[i].State, containers[i].Status = containerdTaskState(task, ok)
+	}
+	return containers, nil
+}
+
+func (c *containerdClient) close() error {
+	return c.conn.Close()
+}
+
+// containerdTask is the init process of a containerd container.
+type containerdTask struct {
+	status     uint64
+	exitStatus uint64
+}
+
+// containerdTaskState returns the state and status of a container, in the
+// format of the Docker Engine API, from its task. A container without a task
+// has never been started, or its task was deleted, and is created.
+func containerdTaskState(task containerdTask, ok bool) (string, string) {
+	if !ok {
+		return "created", "Created"
+	}
+	switch task.status {
+	case containerdTaskCreated:
+		return "created", "Created"
+	case containerdTaskRunning:
+		return "running", "Up"
+	case containerdTaskStopped:
+		return "exited", fmt.Sprintf("Exited (%d)", task.exitStatus)
+	case containerdTaskPaused, containerdTaskPausing:
+		return "paused", "Paused"
+	default:
+		return "unknown", "Unknown"
+	}
+}
+
+// protoFields calls fn with each field of the protobuf message b. The value
+// of varint fields is in v, and the value of length-delimited fields in s.
+// The other fields are skipped.
+func protoFields(b []byte, fn func(num protowire.Number, v uint64, s []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		var v uint64
+		var s []byte
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			s, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ != protowire.VarintType && typ != protowire.BytesType {
+			continue
+		}
+		if err := fn(num, v, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeContainerdContainers decodes the containers of a
+// containerd.services.containers.v1.ListContainersResponse.
+func decodeContainerdContainers(b []byte) ([]dockerContainer, error) {
+	var containers []dockerContainer
+	err := protoFields(b, func(num protowire.Number, _ uint64, s []byte) error {
+		if num != 1 {
+			return nil
+		}
+		container := dockerContainer{Labels: map[string]string{}}
+		err := protoFields(s, func(num protowire.Number, _ uint64, s []byte) error {
+			switch num {
+			case 1:
+				container.ID = string(s)
+			case 2:
+				var key, value string
+				err := protoFields(s, func(num protowire.Number, _ uint64, s []byte) error {
+					switch num {
+					case 1:
+						key = string(s)
+					case 2:
+						value = string(s)
+					}
+					return nil
+				})
+				container.Labels[key] = value
+				return err
+			case 3:
+				container.Image = string(s)
+			}
+			return nil
+		})
+		for _, label := range containerdNameLabels {
+			if name := container.Labels[label]; name != "" {
+				container.Names = []string{name}
+				break
+			}
+		}
+		containers = append(containers, container)
+		return err
+	})
+	return containers, err
+}
+
+// decodeContainerdTasks decodes the tasks of a
+// containerd.services.tasks.v1.ListTasksResponse, by container id.
+func decodeContainerdTasks(b []byte) (map[string]containerdTask, error) {
+	tasks := make(map[string]containerdTask)
+	err := protoFields(b, func(num protowire.Number, _ uint64, s []byte) error {
+		if num != 1 {
+			return nil
+		}
+		var id string
+		var task containerdTask
+		err := protoFields(s, func(num protowire.Number, v uint64, s []byte) error {
+			switch num {
+			case 1:
+				id = string(s)
+			case 4:
+				task.status = v
+			case 9:
+				task.exitStatus = v
+			}
+			return nil
+		})
+		tasks[id] = task
+		return err
+	})
+	return tasks, err
+}
@@ -0,0 +1,175 @@
+//go:build !windows
+// +build !windows
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendProtoString(b []byte, num protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendProtoMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendProtoVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func testContainerdContainer(id, image string, labels map[string]string) []byte {
+	b := appendProtoString(nil, 1, id)
+	for key, value := range labels {
+		entry := appendProtoString(nil, 1, key)
+		entry = appendProtoString(entry, 2, value)
+		b = appendProtoMessage(b, 2, entry)
+	}
+	b = appendProtoString(b, 3, image)
+	// the spec is skipped
+	return appendProtoMessage(b, 5, []byte("spec"))
+}
+
+func testContainerdTask(id string, status, exitStatus uint64) []byte {
+	b := appendProtoString(nil, 1, id)
+	b = appendProtoString(b, 2, id)
+	b = appendProtoVarint(b, 3, 42)
+	b = appendProtoVarint(b, 4, status)
+	return appendProtoVarint(b, 9, exitStatus)
+}
+
+func newTestContainerd(t *testing.T, namespace string) string {
+	t.Helper()
+	var containers, tasks []byte
+	containers = appendProtoMessage(containers, 1, testContainerdContainer("1a2b3c4d5e6f7a8b", "nginx", map[string]string{"app": "web", "nerdctl/name": "web-1"}))
+	containers = appendProtoMessage(containers, 1, testContainerdContainer("2b3c4d5e6f7a8b9c", "busybox", map[string]string{"app": "web", "io.kubernetes.container.name": "job"}))
+	containers = appendProtoMessage(containers, 1, testContainerdContainer("3c4d5e6f7a8b9c0d", "nginx", map[string]string{"app": "web"}))
+	containers = appendProtoMessage(containers, 1, testContainerdContainer("4d5e6f7a8b9c0d1e", "postgres", map[string]string{"app": "db"}))
+	tasks = appendProtoMessage(tasks, 1, testContainerdTask("1a2b3c4d5e6f7a8b", containerdTaskRunning, 0))
+	tasks = appendProtoMessage(tasks, 1, testContainerdTask("2b3c4d5e6f7a8b9c", containerdTaskStopped, 0))
+	tasks = appendProtoMessage(tasks, 1, testContainerdTask("4d5e6f7a8b9c0d1e", containerdTaskStopped, 137))
+
+	socket := filepath.Join(t.TempDir(), "containerd.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+			md, _ := metadata.FromIncomingContext(stream.Context())
+			if ns := md.Get(containerdNamespaceHeader); len(ns) != 1 || ns[0] != namespace {
+				return status.Error(codes.FailedPrecondition, "namespace is required")
+			}
+			var req []byte
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			method, _ := grpc.MethodFromServerStream(stream)
+			switch method {
+			case containerdListContainers:
+				return stream.SendMsg(containers)
+			case containerdListTasks:
+				return stream.SendMsg(tasks)
+			}
+			return status.Error(codes.Unimplemented, method)
+		}),
+	)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return socket
+}
+
+func TestContainerdList(t *testing.T) {
+	client, err := newContainerdClient(newTestContainerd(t, "k8s.io"), "k8s.io")
+	require.NoError(t, err)
+	defer client.close()
+
+	containers, err := client.list(context.Background())
+	require.NoError(t, err)
+	require.Len(t, containers, 4)
+
+	assert.Equal(t, "1a2b3c4d5e6f7a8b", containers[0].ID)
+	assert.Equal(t, "web-1", containers[0].name())
+	assert.Equal(t, "nginx", containers[0].Image)
+	assert.Equal(t, "running", containers[0].State)
+	assert.Equal(t, "web", containers[0].Labels["app"])
+	assert.Equal(t, "job", containers[1].name())
+	assert.Equal(t, "Exited (0)", containers[1].Status)
+	// the container without a task nor a name label
+	assert.Equal(t, "3c4d5e6f7a8b", containers[2].name())
+	assert.Equal(t, "created", containers[2].State)
+	assert.Equal(t, "Exited (137)", containers[3].Status)
+}
+
+func TestContainerdListNamespace(t *testing.T) {
+	client, err := newContainerdClient(newTestContainerd(t, "k8s.io"), "")
+	require.NoError(t, err)
+	defer client.close()
+
+	_, err = client.list(context.Background())
+	assert.Error(t, err)
+}
+
+func TestCheckContainersContainerd(t *testing.T) {
+	socket := newTestContainerd(t, DefaultContainerdNamespace)
+	cfg, cleanup := FixtureConfig()
+	defer cleanup()
+	cfg.Containers = &ContainerConfig{
+		Socket:   socket,
+		Runtime:  ContainerRuntimeContainerd,
+		Interval: DefaultContainerInterval,
+		Labels:   []string{"app=web"},
+		Metrics:  true,
+	}
+	agent, err := NewAgent(cfg)
+	require.NoError(t, err)
+	ch := make(chan *transport.Message, 10)
+	agent.sendq = ch
+
+	runtime, err := newContainerRuntime(cfg.Containers)
+	require.NoError(t, err)
+	defer runtime.close()
+	require.NoError(t, agent.checkContainers(context.Background(), runtime))
+	close(ch)
+
+	events := map[string]*corev2.Event{}
+	for msg := range ch {
+		var event corev2.Event
+		require.NoError(t, json.Unmarshal(msg.Payload, &event))
+		events[event.Check.ProxyEntityName] = &event
+	}
+	require.Len(t, events, 3)
+	assert.Equal(t, uint32(0), events["web-1"].Check.Status)
+	// metrics are not supported by containerd
+	assert.Nil(t, events["web-1"].Metrics)
+	assert.Equal(t, uint32(1), events["job"].Check.Status)
+	assert.Equal(t, uint32(1), events["3c4d5e6f7a8b"].Check.Status)
+}
+
+func TestNewContainerRuntime(t *testing.T) {
+	runtime, err := newContainerRuntime(&ContainerConfig{Socket: "/var/run/docker.sock"})
+	require.NoError(t, err)
+	assert.IsType(t, &containerClient{}, runtime)
+
+	_, err = newContainerRuntime(&ContainerConfig{Socket: "/var/run/cri-o.sock", Runtime: "cri-o"})
+	assert.Error(t, err)
+}
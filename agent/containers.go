@@ -0,0 +1,363 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/transport"
+)
+
+const (
+	// DefaultContainerInterval is the default interval, in seconds, at which
+	// containers are checked.
+	DefaultContainerInterval = 30
+
+	// DefaultContainerCheckName is the name of the check of container events.
+	DefaultContainerCheckName = "container-state"
+
+	// ContainerRuntimeDocker is the container runtime that serves the Docker
+	// Engine API.
+	ContainerRuntimeDocker = "docker"
+
+	// ContainerRuntimeContainerd is the containerd container runtime.
+	ContainerRuntimeContainerd = "containerd"
+
+	// containerAPITimeout is the timeout of requests to the container runtime.
+	containerAPITimeout = 10 * time.Second
+)
+
+// ContainerConfig contains the container runtime provider configuration. The
+// provider queries the Docker Engine API or the containerd API of the local
+// container runtime, and emits an event for each selected container, with a
+// proxy entity named after the container.
+type ContainerConfig struct {
+	// Socket is the path of the unix socket of the container runtime API. The
+	// provider is disabled if it is empty.
+	Socket string
+
+	// Runtime is the API of the container runtime, ContainerRuntimeDocker or
+	// ContainerRuntimeContainerd. It defaults to ContainerRuntimeDocker.
+	Runtime string
+
+	// Namespace is the containerd namespace of the containers, e.g. k8s.io
+	// for the containers of Kubernetes. It defaults to
+	// DefaultContainerdNamespace.
+	Namespace string
+
+	// Interval is the interval, in seconds, at which containers are checked.
+	Interval int
+
+	// Labels selects the containers that have all of these labels, in the
+	// key=value format.
+	Labels []string
+
+	// Names selects the containers whose name matches one of these glob
+	// patterns. Every container is selected if it is empty.
+	Names []string
+
+	// Handlers are the handlers of container events.
+	Handlers []string
+
+	// Metrics enables the container resource usage metrics. They are only
+	// supported by the Docker Engine API.
+	Metrics bool
+}
+
+// containerRuntime lists the containers of a container runtime.
+type containerRuntime interface {
+	list(ctx context.Context) ([]dockerContainer, error)
+	close() error
+}
+
+// containerStatsGetter is the container runtime that reports the resource
+// usage of containers.
+type containerStatsGetter interface {
+	stats(ctx context.Context, id string) (dockerStats, error)
+}
+
+// dockerContainer is a container listed by the Docker Engine API. The
+// containers of other runtimes are converted to it.
+type dockerContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	State  string            `json:"State"`
+	Status string            `json:"Status"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// name returns the name of the container, without its leading slash.
+func (c dockerContainer) name() string {
+	if len(c.Names) == 0 {
+		if len(c.ID) > 12 {
+			return c.ID[:12]
+		}
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// dockerStats are the resource usage statistics of a container.
+type dockerStats struct {
+	CPUStats    dockerCPUStats `json:"cpu_stats"`
+	PreCPUStats dockerCPUStats `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64 `json:"usage"`
+		Limit uint64 `json:"limit"`
+	} `json:"memory_stats"`
+}
+
+type dockerCPUStats struct {
+	CPUUsage struct {
+		TotalUsage uint64 `json:"total_usage"`
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  uint32 `json:"online_cpus"`
+}
+
+// cpuPercent returns the CPU usage of the container, in percents of a CPU, as
+// computed by the docker stats command.
+func (s dockerStats) cpuPercent() float64 {
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+	cpus := float64(s.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = 1
+	}
+	return cpuDelta / systemDelta * cpus * 100
+}
+
+// containerClient queries the Docker Engine API.
+type containerClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+func newContainerClient(socket string) *containerClient {
+	tr := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &containerClient{
+		client:  &http.Client{Transport: tr, Timeout: containerAPITimeout},
+		baseURL: "http://docker",
+	}
+}
+
+func (c *containerClient) get(ctx context.Context, endpoint string, query url.Values, v interface{}) error {
+	u := c.baseURL + endpoint
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("container runtime API error: GET %s: %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// list returns every container, including stopped containers.
+func (c *containerClient) list(ctx context.Context) ([]dockerContainer, error) {
+	var containers []dockerContainer
+	err := c.get(ctx, "/containers/json", url.Values{"all": {"true"}}, &containers)
+	return containers, err
+}
+
+// stats returns the resource usage statistics of a running container.
+func (c *containerClient) stats(ctx context.Context, id string) (dockerStats, error) {
+	var stats dockerStats
+	err := c.get(ctx, "/containers/"+url.PathEscape(id)+"/stats", url.Values{"stream": {"false"}}, &stats)
+	return stats, err
+}
+
+func (c *containerClient) close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// newContainerRuntime returns the client of the container runtime of the
+// configuration.
+func newContainerRuntime(config *ContainerConfig) (containerRuntime, error) {
+	switch config.Runtime {
+	case "", ContainerRuntimeDocker:
+		return newContainerClient(config.Socket), nil
+	case ContainerRuntimeContainerd:
+		return newContainerdClient(config.Socket, config.Namespace)
+	default:
+		return nil, fmt.Errorf("unknown container runtime %q", config.Runtime)
+	}
+}
+
+// selects returns true if the container is selected by the configuration.
+func (c *ContainerConfig) selects(container dockerContainer) bool {
+	for _, label := range c.Labels {
+		key, value, _ := strings.Cut(label, "=")
+		if v, ok := container.Labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	if len(c.Names) == 0 {
+		return true
+	}
+	for _, pattern := range c.Names {
+		if ok, _ := path.Match(pattern, container.name()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containerStatus returns the check status and output of a container. Running
+// containers are OK, and unhealthy containers are critical. Containers that
+// are not running are a warning if they can be expected to run again, that is
+// if they are created, paused, restarting or exited successfully, since a
+// container that exited with 0 is usually a completed job or a stopped
+// service rather than a failure, and critical otherwise.
+func containerStatus(container dockerContainer) (uint32, string) {
+	output := fmt.Sprintf("container %s (%s) is %s: %s\n", container.name(), container.Image, container.State, container.Status)
+	switch {
+	case strings.Contains(container.Status, "(unhealthy)"):
+		return 2, output
+	case container.State == "running":
+		return 0, output
+	case container.State == "exited" && strings.HasPrefix(container.Status, "Exited (0)"):
+		return 1, output
+	case container.State == "created", container.State == "paused", container.State == "restarting":
+		return 1, output
+	default:
+		return 2, output
+	}
+}
+
+// containerEvent returns the event of a container. Its check has a proxy
+// entity named after the container.
+func (a *Agent) containerEvent(container dockerContainer, stats *dockerStats) *corev2.Event {
+	config := a.config.Containers
+	now := time.Now().Unix()
+	entity := a.getAgentEntity()
+	check := &corev2.Check{
+		ObjectMeta:      corev2.NewObjectMeta(DefaultContainerCheckName, entity.Namespace),
+		Interval:        uint32(config.Interval),
+		Handlers:        config.Handlers,
+		Executed:        now,
+		ProcessedBy:     a.config.AgentName,
+		ProxyEntityName: container.name(),
+	}
+	check.Status, check.Output = containerStatus(container)
+	event := &corev2.Event{
+		ObjectMeta: corev2.NewObjectMeta("", entity.Namespace),
+		Entity:     entity,
+		Check:      check,
+		Timestamp:  now,
+	}
+	if id, err := uuid.NewRandom(); err == nil {
+		event.ID = id[:]
+	}
+	if stats != nil {
+		tags := []*corev2.MetricTag{
+			{Name: "container", Value: container.name()},
+			{Name: "image", Value: container.Image},
+		}
+		event.Metrics = &corev2.Metrics{
+			Handlers: config.Handlers,
+			Points: []*corev2.MetricPoint{
+				{Name: "container.cpu.percent", Value: stats.cpuPercent(), Timestamp: now, Tags: tags},
+				{Name: "container.memory.usage", Value: float64(stats.MemoryStats.Usage), Timestamp: now, Tags: tags},
+				{Name: "container.memory.limit", Value: float64(stats.MemoryStats.Limit), Timestamp: now, Tags: tags},
+			},
+		}
+	}
+	return event
+}
+
+// checkContainers emits the events of the selected containers.
+func (a *Agent) checkContainers(ctx context.Context, runtime containerRuntime) error {
+	containers, err := runtime.list(ctx)
+	if err != nil {
+		return err
+	}
+	statsGetter, _ := runtime.(containerStatsGetter)
+	for _, container := range containers {
+		if !a.config.Containers.selects(container) {
+			continue
+		}
+		var stats *dockerStats
+		if a.config.Containers.Metrics && statsGetter != nil && container.State == "running" {
+			s, err := statsGetter.stats(ctx, container.ID)
+			if err != nil {
+				logger.WithError(err).WithField("container", container.name()).Error("error getting container stats")
+			} else {
+				stats = &s
+			}
+		}
+		msg, err := a.marshal(a.containerEvent(container, stats))
+		if err != nil {
+			logger.WithError(err).Error("error marshaling container event")
+			continue
+		}
+		a.sendMessage(&transport.Message{
+			Type:    transport.MessageTypeEvent,
+			Payload: msg,
+		})
+	}
+	return nil
+}
+
+// StartContainers starts the container runtime provider, if it is configured.
+func (a *Agent) StartContainers(ctx context.Context) {
+	config := a.config.Containers
+	if config == nil || config.Socket == "" {
+		return
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultContainerInterval
+	}
+	runtime, err := newContainerRuntime(config)
+	if err != nil {
+		logger.WithError(err).Error("error starting container checks")
+		return
+	}
+	if _, ok := runtime.(containerStatsGetter); config.Metrics && !ok {
+		logger.Warnf("container metrics are not supported by the %s runtime", config.Runtime)
+	}
+	logger.Info("checking containers of the container runtime: ", config.Socket)
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		defer runtime.close()
+		ticker := time.NewTicker(time.Duration(config.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := a.checkContainers(ctx, runtime); err != nil {
+				logger.WithError(err).Error("error checking containers")
+			}
+		}
+	}()
+}
@@ -0,0 +1,118 @@
+//go:build !windows
+// +build !windows
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testContainers = `[
+	{"Id": "1a2b3c4d5e6f7a8b", "Names": ["/web-1"], "Image": "nginx", "State": "running", "Status": "Up 2 hours (healthy)", "Labels": {"app": "web"}},
+	{"Id": "2b3c4d5e6f7a8b9c", "Names": ["/web-2"], "Image": "nginx", "State": "running", "Status": "Up 2 hours (unhealthy)", "Labels": {"app": "web"}},
+	{"Id": "3c4d5e6f7a8b9c0d", "Names": ["/web-3"], "Image": "nginx", "State": "exited", "Status": "Exited (137) 5 minutes ago", "Labels": {"app": "web"}},
+	{"Id": "4d5e6f7a8b9c0d1e", "Names": ["/db"], "Image": "postgres", "State": "running", "Status": "Up 2 hours", "Labels": {"app": "db"}}
+]`
+
+const testContainerStats = `{
+	"cpu_stats": {"cpu_usage": {"total_usage": 400}, "system_cpu_usage": 2000, "online_cpus": 2},
+	"precpu_stats": {"cpu_usage": {"total_usage": 200}, "system_cpu_usage": 1000, "online_cpus": 2},
+	"memory_stats": {"usage": 1048576, "limit": 4194304}
+}`
+
+func newTestContainerRuntime(t *testing.T) string {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/json":
+			fmt.Fprint(w, testContainers)
+		case "/containers/1a2b3c4d5e6f7a8b/stats":
+			fmt.Fprint(w, testContainerStats)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+	return socket
+}
+
+func TestContainerConfigSelects(t *testing.T) {
+	container := dockerContainer{Names: []string{"/web-1"}, Labels: map[string]string{"app": "web", "tier": "frontend"}}
+	testCases := []struct {
+		name   string
+		config ContainerConfig
+		want   bool
+	}{
+		{name: "no selectors", want: true},
+		{name: "matching labels", config: ContainerConfig{Labels: []string{"app=web", "tier=frontend"}}, want: true},
+		{name: "missing label", config: ContainerConfig{Labels: []string{"app=web", "env=prod"}}, want: false},
+		{name: "matching name", config: ContainerConfig{Names: []string{"db", "web-*"}}, want: true},
+		{name: "other name", config: ContainerConfig{Names: []string{"db"}}, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.config.selects(container))
+		})
+	}
+}
+
+func TestCheckContainers(t *testing.T) {
+	socket := newTestContainerRuntime(t)
+	cfg, cleanup := FixtureConfig()
+	defer cleanup()
+	cfg.Containers = &ContainerConfig{
+		Socket:   socket,
+		Interval: DefaultContainerInterval,
+		Labels:   []string{"app=web"},
+		Handlers: []string{"slack"},
+		Metrics:  true,
+	}
+	agent, err := NewAgent(cfg)
+	require.NoError(t, err)
+	ch := make(chan *transport.Message, 10)
+	agent.sendq = ch
+
+	require.NoError(t, agent.checkContainers(context.Background(), newContainerClient(socket)))
+	close(ch)
+
+	events := map[string]*corev2.Event{}
+	for msg := range ch {
+		var event corev2.Event
+		require.NoError(t, json.Unmarshal(msg.Payload, &event))
+		events[event.Check.ProxyEntityName] = &event
+	}
+	require.Len(t, events, 3)
+
+	web1 := events["web-1"]
+	require.NotNil(t, web1)
+	assert.Equal(t, DefaultContainerCheckName, web1.Check.Name)
+	assert.Equal(t, uint32(0), web1.Check.Status)
+	assert.Equal(t, []string{"slack"}, web1.Check.Handlers)
+	require.NotNil(t, web1.Metrics)
+	require.Len(t, web1.Metrics.Points, 3)
+	assert.Equal(t, float64(40), web1.Metrics.Points[0].Value)
+	assert.Equal(t, float64(1048576), web1.Metrics.Points[1].Value)
+
+	assert.Equal(t, uint32(2), events["web-2"].Check.Status)
+	// stats of other containers are missing, and not reported
+	assert.Nil(t, events["web-2"].Metrics)
+	assert.Equal(t, uint32(2), events["web-3"].Check.Status)
+	assert.Nil(t, events["web-3"].Metrics)
+}
@@ -1,12 +1,14 @@
 package agent
 
 import (
+	"context"
 	"fmt"
 
 	time "github.com/echlebek/timeproxy"
 	"github.com/google/uuid"
 
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/transport"
 )
 
 // prepareEvent accepts a partial or complete event and tries to add any missing
@@ -66,3 +68,26 @@ func prepareEvent(a *Agent, event *corev2.Event) error {
 	// The entity should pass validation at this point
 	return event.Entity.Validate()
 }
+
+// SendEvent prepares event like the events of the agent API, and sends it to
+// the backend. It blocks until the event is queued for sending or ctx is
+// done.
+func (a *Agent) SendEvent(ctx context.Context, event *corev2.Event) error {
+	if err := prepareEvent(a, event); err != nil {
+		return err
+	}
+	payload, err := a.marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshaling event: %s", err)
+	}
+	msg := &transport.Message{
+		Type:    transport.MessageTypeEvent,
+		Payload: payload,
+	}
+	select {
+	case a.sendq <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/transport"
+)
+
+const (
+	// maxPendingEvents is the number of sent events that wait for their
+	// acknowledgement by the backend. The oldest events are dropped when
+	// more events are sent.
+	maxPendingEvents = 1000
+
+	// maxEventDeliveries is the number of times an event is sent to the
+	// backends before it is dropped.
+	maxEventDeliveries = 3
+)
+
+// pendingEvent is an event sent to the backend that was not acknowledged.
+type pendingEvent struct {
+	id          string
+	msg         *transport.Message
+	contentType string
+	deliveries  int
+}
+
+// pendingEvents are the events sent to the backend that were not acknowledged,
+// in the order in which they were sent.
+type pendingEvents struct {
+	mu     sync.Mutex
+	limit  int
+	order  *list.List
+	events map[string]*list.Element
+}
+
+func newPendingEvents(limit int) *pendingEvents {
+	return &pendingEvents{
+		limit:  limit,
+		order:  list.New(),
+		events: make(map[string]*list.Element),
+	}
+}
+
+// add adds the event message msg, whose event has the UUID id and was
+// serialized with contentType. It returns the number of older events dropped
+// to make room for it.
+func (p *pendingEvents) add(id string, msg *transport.Message, contentType string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.events[id]; ok {
+		return 0
+	}
+	p.events[id] = p.order.PushBack(&pendingEvent{
+		id:          id,
+		msg:         msg,
+		contentType: contentType,
+		deliveries:  1,
+	})
+	var dropped int
+	for p.order.Len() > p.limit {
+		p.remove(p.order.Front())
+		dropped++
+	}
+	return dropped
+}
+
+// ack removes the event with the UUID id.
+func (p *pendingEvents) ack(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e, ok := p.events[id]; ok {
+		p.remove(e)
+	}
+}
+
+// dropLarger removes the events whose payload is larger than size bytes, and
+// returns their number.
+func (p *pendingEvents) dropLarger(size int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var dropped int
+	for e := p.order.Front(); e != nil; {
+		next := e.Next()
+		if len(e.Value.(*pendingEvent).msg.Payload) > size {
+			p.remove(e)
+			dropped++
+		}
+		e = next
+	}
+	return dropped
+}
+
+// redeliver returns the messages of the events to send again to a backend
+// that accepts contentType, in the order in which they were first sent. The
+// events that were already sent maxEventDeliveries times, or that were
+// serialized with another content type, are dropped, and their number is
+// returned.
+func (p *pendingEvents) redeliver(contentType string) ([]*transport.Message, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	msgs := make([]*transport.Message, 0, p.order.Len())
+	var dropped int
+	for e := p.order.Front(); e != nil; {
+		next := e.Next()
+		event := e.Value.(*pendingEvent)
+		if event.deliveries >= maxEventDeliveries || event.contentType != contentType {
+			p.remove(e)
+			dropped++
+		} else {
+			event.deliveries++
+			msgs = append(msgs, event.msg)
+		}
+		e = next
+	}
+	return msgs, dropped
+}
+
+func (p *pendingEvents) remove(e *list.Element) {
+	p.order.Remove(e)
+	delete(p.events, e.Value.(*pendingEvent).id)
+}
+
+// trackEvent adds the event message msg to the events waiting for their
+// acknowledgement, if the backend acknowledges the events.
+func (a *Agent) trackEvent(msg *transport.Message) {
+	if !a.eventAcks || msg.Type != transport.MessageTypeEvent {
+		return
+	}
+	event := &corev2.Event{}
+	if err := a.unmarshal(msg.Payload, event); err != nil || len(event.ID) == 0 {
+		return
+	}
+	if dropped := a.pendingEvents.add(event.GetUUID().String(), msg, a.contentType); dropped > 0 {
+		messagesDropped.WithLabelValues().Add(float64(dropped))
+		logger.WithField("dropped", dropped).Warn("too many events waiting for their acknowledgement, dropping the oldest")
+	}
+}
+
+// redeliverEvents sends again the events that were not acknowledged by the
+// backend, after a reconnection.
+func (a *Agent) redeliverEvents(conn transport.Transport) error {
+	if !a.eventAcks {
+		return nil
+	}
+	msgs, dropped := a.pendingEvents.redeliver(a.contentType)
+	if dropped > 0 {
+		messagesDropped.WithLabelValues().Add(float64(dropped))
+		logger.WithField("dropped", dropped).Warn("dropping events that were never acknowledged")
+	}
+	if len(msgs) > 0 {
+		logger.WithField("events", len(msgs)).Info("sending the events that were not acknowledged")
+	}
+	for _, msg := range msgs {
+		if err := conn.Send(msg); err != nil {
+			// The events are sent again after the next reconnection
+			return err
+		}
+		messagesSent.WithLabelValues().Inc()
+	}
+	return nil
+}
+
+// handleEventAck handles the acknowledgements of the events of the agent,
+// whose payload is the UUID of the event.
+func (a *Agent) handleEventAck(ctx context.Context, payload []byte) error {
+	a.pendingEvents.ack(strings.TrimSpace(string(payload)))
+	return nil
+}
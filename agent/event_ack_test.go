@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/transport"
+)
+
+func TestPendingEvents(t *testing.T) {
+	p := newPendingEvents(2)
+	a := transport.NewMessage(transport.MessageTypeEvent, []byte("a"))
+	b := transport.NewMessage(transport.MessageTypeEvent, []byte("b"))
+	c := transport.NewMessage(transport.MessageTypeEvent, []byte("c"))
+	if dropped := p.add("a", a, JSONSerializationHeader); dropped != 0 {
+		t.Fatalf("dropped %d events", dropped)
+	}
+	p.add("b", b, JSONSerializationHeader)
+	if dropped := p.add("c", c, JSONSerializationHeader); dropped != 1 {
+		t.Fatalf("dropped %d events, want 1", dropped)
+	}
+	p.ack("c")
+
+	msgs, dropped := p.redeliver(JSONSerializationHeader)
+	if dropped != 0 {
+		t.Fatalf("dropped %d events", dropped)
+	}
+	if len(msgs) != 1 || msgs[0] != b {
+		t.Fatalf("bad redelivered events: %v", msgs)
+	}
+
+	// The event was sent maxEventDeliveries times
+	if _, dropped := p.redeliver(JSONSerializationHeader); dropped != 0 {
+		t.Fatalf("dropped %d events", dropped)
+	}
+	msgs, dropped = p.redeliver(JSONSerializationHeader)
+	if len(msgs) != 0 || dropped != 1 {
+		t.Fatalf("got %d events and %d dropped, want 0 and 1", len(msgs), dropped)
+	}
+}
+
+func TestPendingEventsContentType(t *testing.T) {
+	p := newPendingEvents(10)
+	p.add("a", transport.NewMessage(transport.MessageTypeEvent, []byte("a")), JSONSerializationHeader)
+	msgs, dropped := p.redeliver(ProtobufSerializationHeader)
+	if len(msgs) != 0 || dropped != 1 {
+		t.Fatalf("got %d events and %d dropped, want 0 and 1", len(msgs), dropped)
+	}
+}
+
+func TestPendingEventsDropLarger(t *testing.T) {
+	p := newPendingEvents(10)
+	p.add("a", transport.NewMessage(transport.MessageTypeEvent, []byte("a")), JSONSerializationHeader)
+	p.add("b", transport.NewMessage(transport.MessageTypeEvent, []byte("bbbb")), JSONSerializationHeader)
+	if dropped := p.dropLarger(2); dropped != 1 {
+		t.Fatalf("dropped %d events, want 1", dropped)
+	}
+	msgs, _ := p.redeliver(JSONSerializationHeader)
+	if len(msgs) != 1 || string(msgs[0].Payload) != "a" {
+		t.Fatalf("bad redelivered events: %v", msgs)
+	}
+}
+
+func TestTrackEvent(t *testing.T) {
+	cfg, cleanup := FixtureConfig()
+	defer cleanup()
+	a, err := NewAgent(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := corev2.FixtureEvent("entity", "check")
+	id := uuid.New()
+	event.ID = id[:]
+	payload, err := a.marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := transport.NewMessage(transport.MessageTypeEvent, payload)
+
+	// Events are not tracked if the backend doesn't acknowledge them
+	a.trackEvent(msg)
+	if msgs, _ := a.pendingEvents.redeliver(a.contentType); len(msgs) != 0 {
+		t.Fatal("expected no pending event")
+	}
+
+	a.eventAcks = true
+	a.trackEvent(msg)
+	a.trackEvent(transport.NewMessage(transport.MessageTypeKeepalive, payload))
+	if err := a.handleEventAck(context.Background(), []byte(id.String())); err != nil {
+		t.Fatal(err)
+	}
+	if msgs, _ := a.pendingEvents.redeliver(a.contentType); len(msgs) != 0 {
+		t.Fatal("expected the acknowledged event to be removed")
+	}
+
+	a.trackEvent(msg)
+	if msgs, _ := a.pendingEvents.redeliver(a.contentType); len(msgs) != 1 {
+		t.Fatal("expected a pending event")
+	}
+}
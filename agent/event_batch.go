@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/transport"
+)
+
+// EventBatchSize is the name of the prometheus histogram of the number of
+// events of the event batches sent by the agent.
+const EventBatchSize = "sensu_go_agent_event_batch_size"
+
+var eventBatchSize = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    EventBatchSize,
+		Help:    "The number of events of the event batches sent to sensu-backend",
+		Buckets: []float64{2, 5, 10, 25, 50, 100},
+	},
+	[]string{},
+)
+
+func init() {
+	_ = prometheus.Register(eventBatchSize)
+}
+
+// batchable returns true if the message msg can be packed into an event
+// batch: it is a small event, and the backend handles event batches.
+func (a *Agent) batchable(msg *transport.Message) bool {
+	return msg.Type == transport.MessageTypeEvent &&
+		len(msg.Payload) <= transport.MaxBatchedEventSize &&
+		transport.SupportsMessageType(a.protocolVersion, transport.MessageTypeEventBatch)
+}
+
+// batchEvents packs msg and the events waiting behind it in the send queue
+// into one event batch message, up to the event batch size of the agent. It
+// doesn't wait for more events, so that the events are only batched while
+// they are queued faster than they are sent. It returns the message to send,
+// and the message taken from the send queue that could not be added to the
+// batch, if any. The batched events are tracked and throttled like the events
+// sent one by one.
+func (a *Agent) batchEvents(ctx context.Context, msg *transport.Message) (*transport.Message, *transport.Message) {
+	if a.config.EventBatchSize < 2 || !a.batchable(msg) {
+		return msg, nil
+	}
+	msgs := []*transport.Message{msg}
+	size := len(msg.Payload)
+	var next *transport.Message
+batch:
+	for len(msgs) < a.config.EventBatchSize {
+		select {
+		case m := <-a.sendq:
+			if !a.batchable(m) || size+len(m.Payload) > transport.MaxEventBatchBytes {
+				next = m
+				break batch
+			}
+			if !a.prepareMessage(ctx, m) {
+				break batch
+			}
+			msgs = append(msgs, m)
+			size += len(m.Payload)
+		default:
+			break batch
+		}
+	}
+	if len(msgs) == 1 {
+		return msg, next
+	}
+
+	payloads := make([][]byte, 0, len(msgs))
+	var callbacks []func(error)
+	for _, m := range msgs {
+		payloads = append(payloads, m.Payload)
+		if m.SendCallback != nil {
+			callbacks = append(callbacks, m.SendCallback)
+		}
+	}
+	batch := transport.NewMessage(transport.MessageTypeEventBatch, transport.EncodeEventBatch(payloads))
+	if len(callbacks) > 0 {
+		batch.SendCallback = func(err error) {
+			for _, callback := range callbacks {
+				callback(err)
+			}
+		}
+	}
+	eventBatchSize.WithLabelValues().Observe(float64(len(msgs)))
+	return batch, next
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sensu/sensu-go/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchEvents(t *testing.T) {
+	cfg, cleanup := FixtureConfig()
+	defer cleanup()
+	cfg.EventBatchSize = 3
+	a, err := NewAgent(cfg)
+	require.NoError(t, err)
+	a.protocolVersion = transport.ProtocolVersion3
+
+	event := func(payload string) *transport.Message {
+		return transport.NewMessage(transport.MessageTypeEvent, []byte(payload))
+	}
+	large := transport.NewMessage(transport.MessageTypeEvent, bytes.Repeat([]byte("x"), transport.MaxBatchedEventSize+1))
+
+	// The queued events are batched up to the event batch size
+	var callbacks int
+	first := event("event1")
+	first.SendCallback = func(error) { callbacks++ }
+	a.sendq <- event("event2")
+	a.sendq <- event("event3")
+	a.sendq <- event("event4")
+	msg, next := a.batchEvents(context.Background(), first)
+	assert.Nil(t, next)
+	require.Equal(t, transport.MessageTypeEventBatch, msg.Type)
+	payloads, err := transport.DecodeEventBatch(msg.Payload)
+	require.NoError(t, err)
+	require.Len(t, payloads, 3)
+	assert.Equal(t, "event1", string(payloads[0]))
+	assert.Equal(t, "event3", string(payloads[2]))
+	require.NotNil(t, msg.SendCallback)
+	msg.SendCallback(nil)
+	assert.Equal(t, 1, callbacks)
+
+	// The batch stops at the first message that can't be batched
+	a.sendq <- large
+	msg, next = a.batchEvents(context.Background(), <-a.sendq)
+	assert.Equal(t, transport.MessageTypeEvent, msg.Type)
+	assert.Equal(t, "event4", string(msg.Payload))
+	assert.Equal(t, large, next)
+
+	// Large events are sent one by one
+	msg, next = a.batchEvents(context.Background(), large)
+	assert.Equal(t, large, msg)
+	assert.Nil(t, next)
+
+	// Backends that speak older protocol versions don't get batches
+	a.protocolVersion = transport.ProtocolVersion2
+	a.sendq <- event("event2")
+	msg, next = a.batchEvents(context.Background(), event("event1"))
+	assert.Equal(t, "event1", string(msg.Payload))
+	assert.Nil(t, next)
+	assert.Len(t, a.sendq, 1)
+}
+
+func TestNewAgentEventBatchSize(t *testing.T) {
+	cfg, cleanup := FixtureConfig()
+	defer cleanup()
+	cfg.EventBatchSize = transport.MaxEventBatchSize + 1
+	_, err := NewAgent(cfg)
+	assert.Error(t, err)
+}
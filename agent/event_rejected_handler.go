@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// EventsRejected is the name of the prometheus counter of the events of the
+// agent rejected by the backend.
+const EventsRejected = "sensu_go_agent_events_rejected"
+
+var eventsRejected = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: EventsRejected,
+		Help: "The total number of events rejected by sensu-backend",
+	},
+	[]string{"reason"},
+)
+
+func init() {
+	_ = prometheus.Register(eventsRejected)
+}
+
+// handleEventRejected handles the event rejection messages sent by the
+// backend when it rejects one of the events of the agent, such as an event
+// larger than its maximum event size. The events rejected because they are
+// too large are not acknowledged, so the events waiting for their
+// acknowledgement that are larger than the maximum event size are dropped
+// instead of being sent again.
+func (a *Agent) handleEventRejected(ctx context.Context, payload []byte) error {
+	var rejection transport.EventRejection
+	if err := json.Unmarshal(payload, &rejection); err != nil || rejection.Reason == "" {
+		return fmt.Errorf("invalid event rejection payload: %q", payload)
+	}
+	eventsRejected.WithLabelValues(rejection.Reason).Inc()
+	logger.WithFields(logrus.Fields{
+		"reason":   rejection.Reason,
+		"size":     rejection.Size,
+		"max_size": rejection.MaxSize,
+	}).Warn("event rejected by the backend")
+	if rejection.Reason == transport.EventRejectionTooLarge && rejection.MaxSize > 0 {
+		if dropped := a.pendingEvents.dropLarger(rejection.MaxSize); dropped > 0 {
+			messagesDropped.WithLabelValues().Add(float64(dropped))
+		}
+	}
+	return nil
+}
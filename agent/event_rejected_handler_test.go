@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sensu/sensu-go/transport"
+)
+
+func TestHandleEventRejected(t *testing.T) {
+	cfg, cleanup := FixtureConfig()
+	defer cleanup()
+	a, err := NewAgent(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, payload := range []string{"foo", `{"size":10}`} {
+		if err := a.handleEventRejected(context.Background(), []byte(payload)); err == nil {
+			t.Fatalf("expected an error for the payload %s", payload)
+		}
+	}
+	a.pendingEvents.add("large", transport.NewMessage(transport.MessageTypeEvent, make([]byte, 2048)), a.contentType)
+	a.pendingEvents.add("small", transport.NewMessage(transport.MessageTypeEvent, make([]byte, 512)), a.contentType)
+
+	counter := eventsRejected.WithLabelValues(transport.EventRejectionTooLarge)
+	before := testutil.ToFloat64(counter)
+	payload := []byte(`{"reason":"too_large","size":2048,"max_size":1024}`)
+	if err := a.handleEventRejected(context.Background(), payload); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := testutil.ToFloat64(counter)-before, 1.0; got != want {
+		t.Errorf("got %v rejected events, want %v", got, want)
+	}
+
+	// The rejected event is not sent again
+	msgs, _ := a.pendingEvents.redeliver(a.contentType)
+	if len(msgs) != 1 || len(msgs[0].Payload) != 512 {
+		t.Errorf("bad redelivered events: %v", msgs)
+	}
+}
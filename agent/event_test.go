@@ -1,11 +1,13 @@
 package agent
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/transport"
 )
 
 func Test_prepareEvent(t *testing.T) {
@@ -130,3 +132,38 @@ func Test_prepareEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestSendEvent(t *testing.T) {
+	cfg, cleanup := FixtureConfig()
+	defer cleanup()
+	a, err := NewAgent(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := &corev2.Event{Check: corev2.FixtureCheck("check1")}
+	event.Check.Output = "synthetic"
+	if err := a.SendEvent(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	msg := <-a.sendq
+	if got, want := msg.Type, transport.MessageTypeEvent; got != want {
+		t.Errorf("got message type %q, want %q", got, want)
+	}
+	var sent corev2.Event
+	if err := UnmarshalJSON(msg.Payload, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sent.Entity.Name, cfg.AgentName; got != want {
+		t.Errorf("got entity %q, want %q", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < cap(a.sendq); i++ {
+		a.sendq <- msg
+	}
+	if err := a.SendEvent(ctx, &corev2.Event{Check: corev2.FixtureCheck("check1")}); err == nil {
+		t.Error("expected an error when the send queue is full and ctx is done")
+	}
+}
@@ -138,6 +138,13 @@ func (a *Agent) executeHook(ctx context.Context, hookConfig *corev2.HookConfig,
 		Env:          env,
 	}
 
+	// Keep the end of the large outputs, where the errors usually are
+	maxOutputSize, err := command.MaxOutputSize(hookConfig.ObjectMeta)
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Warn("not limiting the hook output")
+	}
+	ex.MaxOutputSize = maxOutputSize
+
 	// If stdin is true, add JSON event data to command execution.
 	if hookConfig.Stdin {
 		input, err := json.Marshal(event)
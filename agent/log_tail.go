@@ -0,0 +1,392 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/transport"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// DefaultLogTailInterval is the default interval, in seconds, at which
+	// log files are read.
+	DefaultLogTailInterval = 10
+
+	// defaultLogTailStatus is the default status of events with matches.
+	defaultLogTailStatus = 2
+
+	// maxLogTailRead is the maximum number of bytes read from a log file at
+	// each interval. The rest is read at the next intervals.
+	maxLogTailRead = 10 << 20
+
+	// maxLogTailOutput is the maximum number of matched lines in the output
+	// of log tail events.
+	maxLogTailOutput = 100
+
+	// logTailFingerprintSize is the number of bytes at the start of a log file
+	// that identify it, so that rotations are detected across restarts.
+	logTailFingerprintSize = 1024
+)
+
+// logTail configures the tailing of a log file.
+type logTail struct {
+	// Name is the name of the check of the events of the log file.
+	Name string `yaml:"name" json:"name"`
+
+	// Path is the path of the log file.
+	Path string `yaml:"path" json:"path"`
+
+	// Match is the regular expression of the lines that match.
+	Match string `yaml:"match" json:"match"`
+
+	// Ignore is the regular expression of the matching lines to ignore.
+	Ignore string `yaml:"ignore" json:"ignore"`
+
+	// Status is the status of events with matches, 2 by default.
+	Status *uint32 `yaml:"status" json:"status"`
+
+	// Interval is the interval, in seconds, at which the log file is read.
+	Interval uint32 `yaml:"interval" json:"interval"`
+
+	// FromBeginning reads log files from the beginning the first time that
+	// they are tailed, rather than from the end.
+	FromBeginning bool `yaml:"from_beginning" json:"from_beginning"`
+
+	// Handlers are the handlers of the events of the log file.
+	Handlers []string `yaml:"handlers" json:"handlers"`
+
+	// MetricHandlers are the handlers of the matches metric. The metric is
+	// only emitted if it has handlers.
+	MetricHandlers []string `yaml:"metric_handlers" json:"metric_handlers"`
+
+	match  *regexp.Regexp
+	ignore *regexp.Regexp
+}
+
+func readLogTails(path string, readBytes func(string) ([]byte, error)) ([]*logTail, error) {
+	var tails []*logTail
+	if path == "" {
+		return tails, nil
+	}
+	var unmarshal func(in []byte, out interface{}) error
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	case ".json":
+		unmarshal = json.Unmarshal
+	default:
+		return nil, fmt.Errorf("invalid file extension")
+	}
+	b, err := readBytes(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := unmarshal(b, &tails); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(tails))
+	for _, tail := range tails {
+		if err := tail.validate(); err != nil {
+			return nil, fmt.Errorf("invalid log tail %q: %s", tail.Name, err)
+		}
+		if names[tail.Name] {
+			return nil, fmt.Errorf("duplicate log tail name: %q", tail.Name)
+		}
+		names[tail.Name] = true
+	}
+	return tails, nil
+}
+
+// validate returns an error if the logTail contains invalid values.
+func (t *logTail) validate() error {
+	if err := corev2.ValidateName(t.Name); err != nil {
+		return err
+	}
+	if t.Path == "" {
+		return errors.New("path cannot be empty")
+	}
+	if t.Match == "" {
+		return errors.New("match cannot be empty")
+	}
+	var err error
+	if t.match, err = regexp.Compile(t.Match); err != nil {
+		return err
+	}
+	if t.Ignore != "" {
+		if t.ignore, err = regexp.Compile(t.Ignore); err != nil {
+			return err
+		}
+	}
+	if t.Interval == 0 {
+		t.Interval = DefaultLogTailInterval
+	}
+	return nil
+}
+
+// matches returns true if line matches the log tail.
+func (t *logTail) matches(line string) bool {
+	if !t.match.MatchString(line) {
+		return false
+	}
+	return t.ignore == nil || !t.ignore.MatchString(line)
+}
+
+// logTailState is the position of a log tail in its log file. It is persisted
+// in the agent cache directory, so that log files are not read twice.
+type logTailState struct {
+	// Offset is the offset of the first unread byte of the log file.
+	Offset int64 `json:"offset"`
+
+	// Fingerprint is the hash of the first FingerprintSize bytes of the log
+	// file. The log file was rotated if they changed.
+	Fingerprint     string `json:"fingerprint"`
+	FingerprintSize int64  `json:"fingerprint_size"`
+}
+
+// logTailer reads the new lines of a log file.
+type logTailer struct {
+	tail      *logTail
+	statePath string
+	state     *logTailState
+}
+
+func newLogTailer(tail *logTail, cacheDir string) *logTailer {
+	return &logTailer{
+		tail:      tail,
+		statePath: filepath.Join(cacheDir, "log_tail", tail.Name+".json"),
+	}
+}
+
+func (t *logTailer) loadState() *logTailState {
+	b, err := os.ReadFile(t.statePath)
+	if err != nil {
+		return nil
+	}
+	var state logTailState
+	if err := json.Unmarshal(b, &state); err != nil {
+		logger.WithError(err).WithField("log_tail", t.tail.Name).Warn("ignoring invalid log tail state")
+		return nil
+	}
+	return &state
+}
+
+func (t *logTailer) saveState() error {
+	b, err := json.Marshal(t.state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.statePath), 0700); err != nil {
+		return err
+	}
+	tmp := t.statePath + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.statePath)
+}
+
+// fingerprint returns the fingerprint of the first size bytes of f, or false
+// if f is smaller than size.
+func fingerprint(f *os.File, size int64) (string, bool) {
+	buf := make([]byte, size)
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// read returns the lines added to the log file since the last read. Lines
+// are only returned once they are complete. The log file is read from the
+// beginning when it was rotated or truncated.
+func (t *logTailer) read() ([]string, error) {
+	if t.state == nil {
+		t.state = t.loadState()
+	}
+	f, err := os.Open(t.tail.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// the log file may not have been created yet, or may be rotated
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+
+	if t.state == nil {
+		// the log file is tailed for the first time
+		t.state = &logTailState{}
+		if !t.tail.FromBeginning {
+			t.state.Offset = size
+		}
+	} else if size < t.state.Offset {
+		// the log file was truncated
+		t.state = &logTailState{}
+	} else if t.state.FingerprintSize > 0 {
+		if fp, ok := fingerprint(f, t.state.FingerprintSize); !ok || fp != t.state.Fingerprint {
+			// the log file was rotated
+			t.state = &logTailState{}
+		}
+	}
+
+	var lines []string
+	if size > t.state.Offset {
+		n := size - t.state.Offset
+		if n > maxLogTailRead {
+			n = maxLogTailRead
+		}
+		buf := make([]byte, n)
+		if _, err := f.ReadAt(buf, t.state.Offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		// only read complete lines
+		end := bytes.LastIndexByte(buf, '\n')
+		if end < 0 && n == maxLogTailRead {
+			// a line longer than the maximum read is read in parts
+			end = len(buf) - 1
+		}
+		if end >= 0 {
+			lines = strings.Split(strings.TrimSuffix(string(buf[:end+1]), "\n"), "\n")
+			t.state.Offset += int64(end + 1)
+		}
+	}
+
+	// fingerprint the log file as soon as it is large enough, so that it is
+	// identified once it grows
+	if t.state.FingerprintSize < logTailFingerprintSize && size > t.state.FingerprintSize {
+		fpSize := size
+		if fpSize > logTailFingerprintSize {
+			fpSize = logTailFingerprintSize
+		}
+		if fp, ok := fingerprint(f, fpSize); ok {
+			t.state.Fingerprint = fp
+			t.state.FingerprintSize = fpSize
+		}
+	}
+
+	return lines, t.saveState()
+}
+
+// poll reads the new lines of the log file, and returns the matching lines.
+func (t *logTailer) poll() ([]string, error) {
+	lines, err := t.read()
+	var matches []string
+	for _, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if t.tail.matches(line) {
+			matches = append(matches, line)
+		}
+	}
+	return matches, err
+}
+
+// event returns the event of the lines matched by a poll.
+func (t *logTailer) event(entity *corev2.Entity, agentName string, matches []string, pollErr error) *corev2.Event {
+	now := time.Now().Unix()
+	check := &corev2.Check{
+		ObjectMeta:  corev2.NewObjectMeta(t.tail.Name, entity.Namespace),
+		Interval:    t.tail.Interval,
+		Handlers:    t.tail.Handlers,
+		Executed:    now,
+		ProcessedBy: agentName,
+	}
+	switch {
+	case pollErr != nil:
+		check.Status = 3
+		check.Output = fmt.Sprintf("error reading %s: %s\n", t.tail.Path, pollErr)
+	case len(matches) > 0:
+		check.Status = defaultLogTailStatus
+		if t.tail.Status != nil {
+			check.Status = *t.tail.Status
+		}
+		lines := matches
+		if len(lines) > maxLogTailOutput {
+			lines = lines[len(lines)-maxLogTailOutput:]
+		}
+		check.Output = fmt.Sprintf("%d lines of %s match %q\n%s\n", len(matches), t.tail.Path, t.tail.Match, strings.Join(lines, "\n"))
+	default:
+		check.Output = fmt.Sprintf("no lines of %s match %q\n", t.tail.Path, t.tail.Match)
+	}
+	event := &corev2.Event{
+		ObjectMeta: corev2.NewObjectMeta("", entity.Namespace),
+		Entity:     entity,
+		Check:      check,
+		Timestamp:  now,
+	}
+	if id, err := uuid.NewRandom(); err == nil {
+		event.ID = id[:]
+	}
+	if len(t.tail.MetricHandlers) > 0 && pollErr == nil {
+		event.Metrics = &corev2.Metrics{
+			Handlers: t.tail.MetricHandlers,
+			Points: []*corev2.MetricPoint{
+				{
+					Name:      t.tail.Name + ".matches",
+					Value:     float64(len(matches)),
+					Timestamp: now,
+					Tags:      []*corev2.MetricTag{},
+				},
+			},
+		}
+	}
+	return event
+}
+
+// StartLogTails starts tailing the log files configured by the agent log tail
+// configuration file.
+func (a *Agent) StartLogTails(ctx context.Context) {
+	for _, tail := range a.logTails {
+		tailer := newLogTailer(tail, a.config.CacheDir)
+		logger.WithField("log_tail", tail.Name).Info("tailing log file: ", tail.Path)
+		a.wg.Add(1)
+		go func() {
+			defer a.wg.Done()
+			a.runLogTailer(ctx, tailer)
+		}()
+	}
+}
+
+func (a *Agent) runLogTailer(ctx context.Context, tailer *logTailer) {
+	ticker := time.NewTicker(time.Duration(tailer.tail.Interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		matches, err := tailer.poll()
+		if err != nil {
+			logger.WithError(err).WithField("log_tail", tailer.tail.Name).Error("error tailing log file")
+		}
+		event := tailer.event(a.getAgentEntity(), a.config.AgentName, matches, err)
+		msg, err := a.marshal(event)
+		if err != nil {
+			logger.WithError(err).Error("error marshaling log tail event")
+			continue
+		}
+		a.sendMessage(&transport.Message{
+			Type:    transport.MessageTypeEvent,
+			Payload: msg,
+		})
+	}
+}
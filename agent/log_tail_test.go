@@ -0,0 +1,170 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLogTails(t *testing.T) {
+	testCases := []struct {
+		name    string
+		path    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "no configuration file",
+			path: "",
+		},
+		{
+			name:    "yaml",
+			path:    "log_tail.yml",
+			content: "- name: syslog\n  path: /var/log/syslog\n  match: error\n",
+			want:    1,
+		},
+		{
+			name:    "json",
+			path:    "log_tail.json",
+			content: `[{"name": "syslog", "path": "/var/log/syslog", "match": "error"}, {"name": "auth", "path": "/var/log/auth.log", "match": "failed", "ignore": "sudo"}]`,
+			want:    2,
+		},
+		{
+			name:    "invalid extension",
+			path:    "log_tail.txt",
+			wantErr: true,
+		},
+		{
+			name:    "missing match",
+			path:    "log_tail.json",
+			content: `[{"name": "syslog", "path": "/var/log/syslog"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid regex",
+			path:    "log_tail.json",
+			content: `[{"name": "syslog", "path": "/var/log/syslog", "match": "("}]`,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate names",
+			path:    "log_tail.json",
+			content: `[{"name": "syslog", "path": "/var/log/syslog", "match": "a"}, {"name": "syslog", "path": "/var/log/messages", "match": "b"}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			readBytes := func(string) ([]byte, error) {
+				return []byte(tc.content), nil
+			}
+			tails, err := readLogTails(tc.path, readBytes)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("readLogTails() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			assert.Len(t, tails, tc.want)
+			for _, tail := range tails {
+				assert.Equal(t, uint32(DefaultLogTailInterval), tail.Interval)
+			}
+		})
+	}
+}
+
+func appendLog(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+}
+
+func TestLogTailerPoll(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	cacheDir := filepath.Join(dir, "cache")
+	tail := &logTail{Name: "app", Path: path, Match: "ERROR", Ignore: "ignored"}
+	require.NoError(t, tail.validate())
+
+	// the log file doesn't exist yet
+	tailer := newLogTailer(tail, cacheDir)
+	matches, err := tailer.poll()
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// existing lines are skipped
+	appendLog(t, path, "ERROR before the agent started\n")
+	matches, err = tailer.poll()
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// incomplete lines are read once complete
+	appendLog(t, path, "INFO started\nERROR one\nERROR ignored\nERROR tw")
+	matches, err = tailer.poll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ERROR one"}, matches)
+	appendLog(t, path, "o\r\n")
+	matches, err = tailer.poll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ERROR two"}, matches)
+
+	// the position is persisted across restarts
+	appendLog(t, path, "ERROR three\n")
+	tailer = newLogTailer(tail, cacheDir)
+	matches, err = tailer.poll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ERROR three"}, matches)
+
+	// rotated log files are read from the beginning
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendLog(t, path, strings.Repeat("INFO rotated\n", 200)+"ERROR four\n")
+	matches, err = tailer.poll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ERROR four"}, matches)
+
+	// truncated log files are read from the beginning
+	require.NoError(t, os.Truncate(path, 0))
+	appendLog(t, path, "ERROR five\n")
+	matches, err = tailer.poll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ERROR five"}, matches)
+}
+
+func TestLogTailerEvent(t *testing.T) {
+	status := uint32(1)
+	tail := &logTail{
+		Name:           "app",
+		Path:           "/var/log/app.log",
+		Match:          "ERROR",
+		Status:         &status,
+		Handlers:       []string{"slack"},
+		MetricHandlers: []string{"influxdb"},
+	}
+	require.NoError(t, tail.validate())
+	tailer := newLogTailer(tail, t.TempDir())
+	entity := corev2.FixtureEntity("agent")
+
+	event := tailer.event(entity, "agent", []string{"ERROR one", "ERROR two"}, nil)
+	require.NoError(t, event.Validate())
+	assert.Equal(t, "app", event.Check.Name)
+	assert.Equal(t, uint32(1), event.Check.Status)
+	assert.Equal(t, []string{"slack"}, event.Check.Handlers)
+	assert.Contains(t, event.Check.Output, "ERROR one\nERROR two")
+	require.NotNil(t, event.Metrics)
+	assert.Equal(t, float64(2), event.Metrics.Points[0].Value)
+
+	event = tailer.event(entity, "agent", nil, nil)
+	assert.Equal(t, uint32(0), event.Check.Status)
+	assert.Equal(t, float64(0), event.Metrics.Points[0].Value)
+
+	event = tailer.event(entity, "agent", nil, os.ErrPermission)
+	assert.Equal(t, uint32(3), event.Check.Status)
+	assert.Nil(t, event.Metrics)
+}
@@ -0,0 +1,632 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// MsgpackSerializationHeader is the Content-Type header which indicates
+// MessagePack serialization.
+const MsgpackSerializationHeader = "application/msgpack"
+
+// maxMsgpackDepth is the maximum nesting of the arrays and maps of the
+// MessagePack messages.
+const maxMsgpackDepth = 100
+
+var errMsgpackTruncated = errors.New("msgpack: unexpected end of message")
+
+// MarshalMsgpack is a wrapper to serialize proto messages with MessagePack.
+// The fields of the messages are encoded as they are, like protobuf does, but
+// are named as in their JSON serialization. The empty fields are omitted.
+func MarshalMsgpack(msg proto.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeMsgpack(&buf, reflect.ValueOf(msg)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMsgpack is a wrapper to deserialize proto messages with
+// MessagePack.
+func UnmarshalMsgpack(b []byte, msg proto.Message) error {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("msgpack: can't decode into %T", msg)
+	}
+	d := msgpackDecoder{buf: b}
+	if err := d.decodeValue(v.Elem(), 0); err != nil {
+		return err
+	}
+	if d.pos != len(d.buf) {
+		return errors.New("msgpack: trailing data after message")
+	}
+	return nil
+}
+
+// msgpackField is a field of a struct encoded in a MessagePack map.
+type msgpackField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// msgpackFieldCache caches the fields of the encoded struct types.
+var msgpackFieldCache sync.Map
+
+// msgpackFields returns the fields of the struct type t, named by their json
+// tag. The fields of the embedded structs without a name are flattened, as in
+// JSON.
+func msgpackFields(t reflect.Type) []msgpackField {
+	if fields, ok := msgpackFieldCache.Load(t); ok {
+		return fields.([]msgpackField)
+	}
+	fields := appendMsgpackFields(nil, t, nil)
+	msgpackFieldCache.Store(t, fields)
+	return fields
+}
+
+func appendMsgpackFields(fields []msgpackField, t reflect.Type, index []int) []msgpackField {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fieldIndex := append(append([]int(nil), index...), i)
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			fields = appendMsgpackFields(fields, f.Type, fieldIndex)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, msgpackField{
+			name:      name,
+			index:     fieldIndex,
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+	return fields
+}
+
+// isEmptyMsgpackValue returns true if v is omitted by the omitempty option,
+// as in JSON.
+func isEmptyMsgpackValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// encodeMsgpack encodes v to buf.
+func encodeMsgpack(buf *bytes.Buffer, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Invalid:
+		buf.WriteByte(0xc0)
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteByte(0xc0)
+			return nil
+		}
+		return encodeMsgpack(buf, v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encodeMsgpackInt(buf, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		encodeMsgpackUint(buf, v.Uint())
+	case reflect.Float32:
+		buf.WriteByte(0xca)
+		_ = binary.Write(buf, binary.BigEndian, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		buf.WriteByte(0xcb)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(v.Float()))
+	case reflect.String:
+		encodeMsgpackHeader(buf, v.Len(), 0xa0, 31, 0xd9, 0xda, 0xdb)
+		buf.WriteString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			buf.WriteByte(0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// Bytes have no fix format
+			encodeMsgpackHeader(buf, v.Len(), 0, -1, 0xc4, 0xc5, 0xc6)
+			buf.Write(v.Bytes())
+			return nil
+		}
+		return encodeMsgpackArray(buf, v)
+	case reflect.Array:
+		return encodeMsgpackArray(buf, v)
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteByte(0xc0)
+			return nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("msgpack: unsupported map key of type %s", v.Type().Key())
+		}
+		// The keys are sorted so that the messages are deterministic
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		encodeMsgpackHeader(buf, len(keys), 0x80, 15, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			if err := encodeMsgpack(buf, key); err != nil {
+				return err
+			}
+			if err := encodeMsgpack(buf, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields := msgpackFields(v.Type())
+		values := make([]reflect.Value, len(fields))
+		n := 0
+		for i, field := range fields {
+			values[i] = v.FieldByIndex(field.index)
+			if !field.omitEmpty || !isEmptyMsgpackValue(values[i]) {
+				n++
+			}
+		}
+		encodeMsgpackHeader(buf, n, 0x80, 15, 0, 0xde, 0xdf)
+		for i, field := range fields {
+			if field.omitEmpty && isEmptyMsgpackValue(values[i]) {
+				continue
+			}
+			encodeMsgpackHeader(buf, len(field.name), 0xa0, 31, 0xd9, 0xda, 0xdb)
+			buf.WriteString(field.name)
+			if err := encodeMsgpack(buf, values[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func encodeMsgpackArray(buf *bytes.Buffer, v reflect.Value) error {
+	encodeMsgpackHeader(buf, v.Len(), 0x90, 15, 0, 0xdc, 0xdd)
+	for i := 0; i < v.Len(); i++ {
+		if err := encodeMsgpack(buf, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeMsgpackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		_ = binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		_ = binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		_ = binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+func encodeMsgpackUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u <= math.MaxInt8:
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		_ = binary.Write(buf, binary.BigEndian, uint16(u))
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		_ = binary.Write(buf, binary.BigEndian, uint32(u))
+	default:
+		buf.WriteByte(0xcf)
+		_ = binary.Write(buf, binary.BigEndian, u)
+	}
+}
+
+// encodeMsgpackHeader encodes the header of a string, bytes, array or map of
+// n elements: its fix format up to fixMax elements, or its 8, 16 or 32 bits
+// format. Arrays and maps have no 8 bits format.
+func encodeMsgpackHeader(buf *bytes.Buffer, n int, fix byte, fixMax int, f8, f16, f32 byte) {
+	switch {
+	case n <= fixMax:
+		buf.WriteByte(fix | byte(n))
+	case f8 != 0 && n <= math.MaxUint8:
+		buf.WriteByte(f8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(f16)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(f32)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// msgpackDecoder decodes MessagePack messages into Go values, like
+// encodeMsgpack encodes them, or into the values that JSON is decoded to for
+// the empty interfaces.
+type msgpackDecoder struct {
+	buf []byte
+	pos int
+}
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.buf)-d.pos < n {
+		return nil, errMsgpackTruncated
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func (d *msgpackDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxMsgpackDepth {
+		return nil, errors.New("msgpack: message is nested too deeply")
+	}
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(int(c&0x0f), depth)
+	case c&0xf0 == 0x90:
+		return d.decodeArray(int(c&0x0f), depth)
+	case c&0xe0 == 0xa0:
+		return d.decodeString(int(c & 0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		return d.decodeSized(1, d.decodeString)
+	case 0xc5, 0xda:
+		return d.decodeSized(2, d.decodeString)
+	case 0xc6, 0xdb:
+		return d.decodeSized(4, d.decodeString)
+	case 0xca:
+		v, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		v, err := d.uint(8)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(1 << (c - 0xcc))
+	case 0xd0:
+		v, err := d.uint(1)
+		return int64(int8(v)), err
+	case 0xd1:
+		v, err := d.uint(2)
+		return int64(int16(v)), err
+	case 0xd2:
+		v, err := d.uint(4)
+		return int64(int32(v)), err
+	case 0xd3:
+		v, err := d.uint(8)
+		return int64(v), err
+	case 0xdc:
+		return d.decodeSized(2, func(n int) (interface{}, error) { return d.decodeArray(n, depth) })
+	case 0xdd:
+		return d.decodeSized(4, func(n int) (interface{}, error) { return d.decodeArray(n, depth) })
+	case 0xde:
+		return d.decodeSized(2, func(n int) (interface{}, error) { return d.decodeMap(n, depth) })
+	case 0xdf:
+		return d.decodeSized(4, func(n int) (interface{}, error) { return d.decodeMap(n, depth) })
+	}
+	return nil, fmt.Errorf("msgpack: unsupported format 0x%02x", c)
+}
+
+// decodeValue decodes the next value of the message into v. The unknown
+// fields of the structs are skipped.
+func (d *msgpackDecoder) decodeValue(v reflect.Value, depth int) error {
+	if depth > maxMsgpackDepth {
+		return errors.New("msgpack: message is nested too deeply")
+	}
+	if d.pos >= len(d.buf) {
+		return errMsgpackTruncated
+	}
+	c := d.buf[d.pos]
+	if c == 0xc0 {
+		d.pos++
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decodeValue(v.Elem(), depth)
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return fmt.Errorf("msgpack: can't decode into %s", v.Type())
+		}
+		value, err := d.decode(depth)
+		if err == nil {
+			v.Set(reflect.ValueOf(value))
+		}
+		return err
+	}
+
+	var n uint64
+	var err error
+	switch {
+	case c&0xf0 == 0x80:
+		d.pos++
+		return d.decodeMapValue(v, int(c&0x0f), depth)
+	case c == 0xde, c == 0xdf:
+		d.pos++
+		if n, err = d.uint(2 << (c - 0xde)); err != nil {
+			return err
+		}
+		return d.decodeMapValue(v, int(n), depth)
+	case c&0xf0 == 0x90:
+		d.pos++
+		return d.decodeArrayValue(v, int(c&0x0f), depth)
+	case c == 0xdc, c == 0xdd:
+		d.pos++
+		if n, err = d.uint(2 << (c - 0xdc)); err != nil {
+			return err
+		}
+		return d.decodeArrayValue(v, int(n), depth)
+	}
+	value, err := d.decode(depth)
+	if err != nil {
+		return err
+	}
+	return setMsgpackScalar(v, value)
+}
+
+// decodeMapValue decodes a map of n entries into v, a struct or a map with
+// string keys.
+func (d *msgpackDecoder) decodeMapValue(v reflect.Value, n int, depth int) error {
+	if n > len(d.buf)-d.pos {
+		return errMsgpackTruncated
+	}
+	t := v.Type()
+	switch {
+	case v.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(t, n))
+		}
+	case v.Kind() != reflect.Struct:
+		return fmt.Errorf("msgpack: can't decode a map into %s", t)
+	}
+	for i := 0; i < n; i++ {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return fmt.Errorf("msgpack: unsupported map key of type %T", key)
+		}
+		if v.Kind() == reflect.Map {
+			elem := reflect.New(t.Elem()).Elem()
+			if err := d.decodeValue(elem, depth+1); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), elem)
+			continue
+		}
+		field, ok := msgpackFieldNamed(t, name)
+		if !ok {
+			if _, err := d.decode(depth + 1); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decodeValue(v.FieldByIndex(field.index), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// msgpackFieldNamed returns the field of the struct type t named name.
+func msgpackFieldNamed(t reflect.Type, name string) (msgpackField, bool) {
+	for _, field := range msgpackFields(t) {
+		if field.name == name {
+			return field, true
+		}
+	}
+	return msgpackField{}, false
+}
+
+// decodeArrayValue decodes an array of n elements into v, a slice or an
+// array of n elements.
+func (d *msgpackDecoder) decodeArrayValue(v reflect.Value, n int, depth int) error {
+	if n > len(d.buf)-d.pos {
+		return errMsgpackTruncated
+	}
+	switch {
+	case v.Kind() == reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+	case v.Kind() != reflect.Array || v.Len() != n:
+		return fmt.Errorf("msgpack: can't decode an array of %d elements into %s", n, v.Type())
+	}
+	for i := 0; i < n; i++ {
+		if err := d.decodeValue(v.Index(i), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setMsgpackScalar sets v to value, a decoded bool, number or string.
+func setMsgpackScalar(v reflect.Value, value interface{}) error {
+	switch value := value.(type) {
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(value)
+			return nil
+		}
+	case int64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if !v.OverflowInt(value) {
+				v.SetInt(value)
+				return nil
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if value >= 0 && !v.OverflowUint(uint64(value)) {
+				v.SetUint(uint64(value))
+				return nil
+			}
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(value))
+			return nil
+		}
+	case uint64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if value <= math.MaxInt64 && !v.OverflowInt(int64(value)) {
+				v.SetInt(int64(value))
+				return nil
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if !v.OverflowUint(value) {
+				v.SetUint(value)
+				return nil
+			}
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(value))
+			return nil
+		}
+	case float64:
+		if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+			v.SetFloat(value)
+			return nil
+		}
+	case string:
+		if v.Kind() == reflect.String {
+			v.SetString(value)
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(value))
+			return nil
+		}
+	}
+	return fmt.Errorf("msgpack: can't decode a %T into %s", value, v.Type())
+}
+
+// decodeSized decodes the size of a string, array or map, on size bytes,
+// then its elements with decode.
+func (d *msgpackDecoder) decodeSized(size int, decode func(n int) (interface{}, error)) (interface{}, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.buf)) {
+		return nil, errMsgpackTruncated
+	}
+	return decode(int(n))
+}
+
+func (d *msgpackDecoder) decodeString(n int) (interface{}, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int, depth int) (interface{}, error) {
+	if n > len(d.buf)-d.pos {
+		return nil, errMsgpackTruncated
+	}
+	array := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		e, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, e)
+	}
+	return array, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int, depth int) (interface{}, error) {
+	if n > len(d.buf)-d.pos {
+		return nil, errMsgpackTruncated
+	}
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: unsupported map key of type %T", key)
+		}
+		if m[s], err = d.decode(depth + 1); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	event := corev2.FixtureEvent("entity", "check")
+	event.Check.Output = string(make([]byte, 70000))
+	event.Check.Status = 2
+	event.Check.Duration = 1.5
+	event.Timestamp = -1
+	event.Entity.Labels = map[string]string{"region": "us-west-2"}
+
+	b, err := MarshalMsgpack(event)
+	require.NoError(t, err)
+
+	got := &corev2.Event{}
+	require.NoError(t, UnmarshalMsgpack(b, got))
+	assert.True(t, event.Equal(got))
+	assert.Equal(t, event.ID, got.ID)
+	assert.Equal(t, event.Check.Output, got.Check.Output)
+	assert.Equal(t, event.Entity.Labels, got.Entity.Labels)
+}
+
+func TestMsgpackFieldNames(t *testing.T) {
+	event := corev2.FixtureEvent("entity", "check")
+	b, err := MarshalMsgpack(event)
+	require.NoError(t, err)
+
+	// The fields are named as in JSON
+	d := msgpackDecoder{buf: b}
+	value, err := d.decode(0)
+	require.NoError(t, err)
+	fields, ok := value.(map[string]interface{})
+	require.True(t, ok)
+	assert.Contains(t, fields, "metadata")
+	assert.Contains(t, fields, "entity")
+	assert.Contains(t, fields, "check")
+	assert.NotContains(t, fields, "XXX_unrecognized")
+}
+
+func TestUnmarshalMsgpackUnknownField(t *testing.T) {
+	msg := []byte{0x82, 0xa7}
+	msg = append(msg, "unknown"...)
+	msg = append(msg, 0x91, 0x01, 0xa9)
+	msg = append(msg, "timestamp"...)
+	msg = append(msg, 0x2a)
+
+	var event corev2.Event
+	require.NoError(t, UnmarshalMsgpack(msg, &event))
+	assert.Equal(t, int64(42), event.Timestamp)
+}
+
+func TestMsgpackSmallerThanJSON(t *testing.T) {
+	event := corev2.FixtureEvent("entity", "check")
+
+	b, err := MarshalMsgpack(event)
+	require.NoError(t, err)
+	j, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.Less(t, len(b), len(j))
+}
+
+func TestUnmarshalMsgpackInvalid(t *testing.T) {
+	event := corev2.FixtureEvent("entity", "check")
+	b, err := MarshalMsgpack(event)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		msg  []byte
+	}{
+		{name: "empty", msg: nil},
+		{name: "truncated", msg: b[:len(b)-1]},
+		{name: "trailing data", msg: append(b, 0xc0)},
+		{name: "unsupported format", msg: []byte{0xc1}},
+		{name: "non string key", msg: []byte{0x81, 0x01, 0x01}},
+		{name: "oversized array", msg: []byte{0xdd, 0xff, 0xff, 0xff, 0xff}},
+		{name: "mismatched type", msg: append([]byte{0x81, 0xa9}, "timestamp\xa1x"...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, UnmarshalMsgpack(tt.msg, &corev2.Event{}))
+		})
+	}
+}
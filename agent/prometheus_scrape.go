@@ -0,0 +1,284 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/agent/transformers"
+	"github.com/sensu/sensu-go/command"
+)
+
+const (
+	// prometheusScrapeAnnotation is the check annotation that makes a check a
+	// prometheus scrape check. Its value is a JSON PrometheusScrapeConfig.
+	// The agent scrapes the configured endpoint instead of executing the
+	// check command.
+	prometheusScrapeAnnotation = "sensu.io/prometheus-scrape"
+
+	// prometheusScrapeExec is the command name that prometheus scrape checks
+	// are matched against in the agent allow list, followed by the scrape
+	// URL, as in "prometheus-scrape http://localhost:9100/metrics".
+	prometheusScrapeExec = "prometheus-scrape"
+
+	// defaultScrapeTimeout is the timeout of scrapes for checks without a
+	// timeout.
+	defaultScrapeTimeout = 10 * time.Second
+
+	// maxScrapeSize is the maximum size of a scraped exposition.
+	maxScrapeSize = 10 << 20
+)
+
+// Relabel rule actions.
+const (
+	RelabelReplace   = "replace"
+	RelabelKeep      = "keep"
+	RelabelDrop      = "drop"
+	RelabelLabelDrop = "labeldrop"
+	RelabelLabelKeep = "labelkeep"
+)
+
+// PrometheusScrapeConfig configures a prometheus scrape check.
+type PrometheusScrapeConfig struct {
+	// URL is the URL of the prometheus exposition endpoint.
+	URL string `json:"url"`
+
+	// Metrics is the list of the names of the metrics to keep. Names may be
+	// glob patterns. Every metric is kept if it is empty.
+	Metrics []string `json:"metrics,omitempty"`
+
+	// Relabel is the list of relabel rules applied to the kept metrics, in
+	// order.
+	Relabel []*PrometheusRelabelRule `json:"relabel,omitempty"`
+}
+
+// PrometheusRelabelRule is a relabel rule, with the semantics of prometheus
+// relabel_configs.
+type PrometheusRelabelRule struct {
+	// Action is the action of the rule: replace (the default), keep, drop,
+	// labeldrop or labelkeep.
+	Action string `json:"action,omitempty"`
+
+	// SourceLabels are the labels whose values are joined with Separator and
+	// matched against Regex.
+	SourceLabels []string `json:"source_labels,omitempty"`
+
+	// Separator separates the values of the source labels, ";" by default.
+	Separator string `json:"separator,omitempty"`
+
+	// Regex is the regular expression that the joined values are matched
+	// against, "(.*)" by default. It is anchored at both ends.
+	Regex string `json:"regex,omitempty"`
+
+	// TargetLabel is the label set by the replace action.
+	TargetLabel string `json:"target_label,omitempty"`
+
+	// Replacement is the value of the target label of the replace action,
+	// "$1" by default.
+	Replacement string `json:"replacement,omitempty"`
+
+	regexp *regexp.Regexp
+}
+
+// parsePrometheusScrapeConfig parses and validates a JSON scrape config.
+func parsePrometheusScrapeConfig(s string) (*PrometheusScrapeConfig, error) {
+	var config PrometheusScrapeConfig
+	if err := json.Unmarshal([]byte(s), &config); err != nil {
+		return nil, fmt.Errorf("invalid prometheus scrape config: %s", err)
+	}
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prometheus scrape url: %s", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid prometheus scrape url %q: scheme must be http or https", config.URL)
+	}
+	for _, pattern := range config.Metrics {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid prometheus scrape metric pattern %q: %s", pattern, err)
+		}
+	}
+	for _, rule := range config.Relabel {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &config, nil
+}
+
+func (r *PrometheusRelabelRule) validate() error {
+	if r.Action == "" {
+		r.Action = RelabelReplace
+	}
+	if r.Separator == "" {
+		r.Separator = ";"
+	}
+	if r.Regex == "" {
+		r.Regex = "(.*)"
+	}
+	if r.Replacement == "" {
+		r.Replacement = "$1"
+	}
+	re, err := regexp.Compile("^(?:" + r.Regex + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid relabel regex %q: %s", r.Regex, err)
+	}
+	r.regexp = re
+	switch r.Action {
+	case RelabelReplace:
+		if r.TargetLabel == "" {
+			return errors.New("relabel rules with the replace action require a target label")
+		}
+	case RelabelKeep, RelabelDrop:
+		if len(r.SourceLabels) == 0 {
+			return fmt.Errorf("relabel rules with the %s action require source labels", r.Action)
+		}
+	case RelabelLabelDrop, RelabelLabelKeep:
+	default:
+		return fmt.Errorf("invalid relabel action: %q", r.Action)
+	}
+	return nil
+}
+
+// apply applies the rule to the labels of metric. It returns false if the
+// metric is dropped.
+func (r *PrometheusRelabelRule) apply(metric model.Metric) bool {
+	values := make([]string, 0, len(r.SourceLabels))
+	for _, name := range r.SourceLabels {
+		values = append(values, string(metric[model.LabelName(name)]))
+	}
+	value := strings.Join(values, r.Separator)
+
+	switch r.Action {
+	case RelabelReplace:
+		match := r.regexp.FindStringSubmatchIndex(value)
+		if match == nil {
+			return true
+		}
+		target := model.LabelName(r.TargetLabel)
+		result := r.regexp.ExpandString(nil, r.Replacement, value, match)
+		if len(result) == 0 {
+			delete(metric, target)
+		} else {
+			metric[target] = model.LabelValue(result)
+		}
+	case RelabelKeep:
+		return r.regexp.MatchString(value)
+	case RelabelDrop:
+		return !r.regexp.MatchString(value)
+	case RelabelLabelDrop, RelabelLabelKeep:
+		keep := r.Action == RelabelLabelKeep
+		for name := range metric {
+			if name == model.MetricNameLabel {
+				continue
+			}
+			if r.regexp.MatchString(string(name)) != keep {
+				delete(metric, name)
+			}
+		}
+	}
+	return true
+}
+
+// keeps returns true if the metric named name is in the metrics allowlist.
+func (c *PrometheusScrapeConfig) keeps(name string) bool {
+	if len(c.Metrics) == 0 {
+		return true
+	}
+	for _, pattern := range c.Metrics {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filter applies the metrics allowlist and the relabel rules to samples.
+func (c *PrometheusScrapeConfig) filter(samples transformers.PromList) transformers.PromList {
+	kept := make(transformers.PromList, 0, len(samples))
+samples:
+	for _, sample := range samples {
+		if !c.keeps(string(sample.Metric[model.MetricNameLabel])) {
+			continue
+		}
+		for _, rule := range c.Relabel {
+			if !rule.apply(sample.Metric) {
+				continue samples
+			}
+		}
+		kept = append(kept, sample)
+	}
+	return kept
+}
+
+// prometheusScrapeCommand returns the command that the prometheus scrape check
+// configured by spec is matched against in the agent allow list. It returns an
+// empty command, which is never allowed, if spec is invalid.
+func prometheusScrapeCommand(spec string) string {
+	config, err := parsePrometheusScrapeConfig(spec)
+	if err != nil {
+		return ""
+	}
+	return prometheusScrapeExec + " " + config.URL
+}
+
+// scrapePrometheus scrapes the prometheus exposition endpoint configured by
+// spec for check, and returns the scraped metrics. The response describes
+// the scrape as if it was the execution of the check command.
+func scrapePrometheus(ctx context.Context, check *corev2.Check, spec string) ([]*corev2.MetricPoint, *command.ExecutionResponse, error) {
+	resp := &command.ExecutionResponse{}
+	config, err := parsePrometheusScrapeConfig(spec)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	timeout := defaultScrapeTimeout
+	if check.Timeout > 0 {
+		timeout = time.Duration(check.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, config.URL, nil)
+	if err != nil {
+		return nil, resp, err
+	}
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+
+	started := time.Now()
+	defer func() {
+		resp.Duration = time.Since(started).Seconds()
+	}()
+	client := &http.Client{Timeout: timeout}
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, resp, fmt.Errorf("error scraping %s: %s", config.URL, err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, resp, fmt.Errorf("error scraping %s: %s", config.URL, httpResp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, maxScrapeSize))
+	if err != nil {
+		return nil, resp, fmt.Errorf("error scraping %s: %s", config.URL, err)
+	}
+
+	// reuse the prometheus metric transformer on the scraped exposition
+	scraped := *check
+	scraped.Output = string(body)
+	samples := config.filter(transformers.ParseProm(&corev2.Event{Check: &scraped}))
+	points := samples.Transform()
+
+	resp.Output = fmt.Sprintf("scraped %d metrics from %s\n", len(points), config.URL)
+	return points, resp, nil
+}
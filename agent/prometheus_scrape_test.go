@@ -0,0 +1,178 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testExposition = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027
+http_requests_total{method="post",code="400"} 3
+# HELP go_goroutines Number of goroutines that currently exist.
+# TYPE go_goroutines gauge
+go_goroutines 42
+# TYPE process_open_fds gauge
+process_open_fds 12
+`
+
+func TestParsePrometheusScrapeConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{
+			name: "url only",
+			spec: `{"url": "http://localhost:9100/metrics"}`,
+		},
+		{
+			name: "metrics and relabel rules",
+			spec: `{"url": "https://localhost/metrics", "metrics": ["go_*"], "relabel": [{"source_labels": ["code"], "target_label": "status"}, {"action": "labeldrop", "regex": "prom_help"}]}`,
+		},
+		{
+			name:    "invalid json",
+			spec:    `http://localhost:9100/metrics`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid scheme",
+			spec:    `{"url": "file:///etc/passwd"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid metric pattern",
+			spec:    `{"url": "http://localhost/metrics", "metrics": ["go_["]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid relabel action",
+			spec:    `{"url": "http://localhost/metrics", "relabel": [{"action": "hashmod"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "replace without target label",
+			spec:    `{"url": "http://localhost/metrics", "relabel": [{"source_labels": ["code"]}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid relabel regex",
+			spec:    `{"url": "http://localhost/metrics", "relabel": [{"action": "labeldrop", "regex": "("}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parsePrometheusScrapeConfig(tc.spec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parsePrometheusScrapeConfig() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrometheusScrapeCommand(t *testing.T) {
+	assert.Equal(t, "prometheus-scrape http://localhost:9100/metrics", prometheusScrapeCommand(`{"url": "http://localhost:9100/metrics"}`))
+	assert.Equal(t, "", prometheusScrapeCommand(`{"url": "file:///etc/passwd"}`))
+
+	agent := &Agent{allowList: []allowList{{Exec: prometheusScrapeExec, Args: []string{"http://localhost:9100/metrics"}}}}
+	_, match := agent.matchAllowList(prometheusScrapeCommand(`{"url": "http://localhost:9100/metrics"}`))
+	assert.True(t, match)
+	_, match = agent.matchAllowList(prometheusScrapeCommand(`{"url": "http://10.0.0.1:9100/metrics"}`))
+	assert.False(t, match)
+}
+
+func TestScrapePrometheusTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	check := corev2.FixtureCheck("check")
+	check.Timeout = 1
+	spec := fmt.Sprintf(`{"url": %q}`, server.URL+"/metrics")
+	started := time.Now()
+	_, _, err := scrapePrometheus(context.Background(), check, spec)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(started), 4*time.Second)
+}
+
+func TestScrapePrometheus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, testExposition)
+	}))
+	defer server.Close()
+
+	tags := func(points []*corev2.MetricPoint, name string) map[string]string {
+		for _, point := range points {
+			if point.Name != name {
+				continue
+			}
+			tags := map[string]string{}
+			for _, tag := range point.Tags {
+				tags[tag.Name] = tag.Value
+			}
+			return tags
+		}
+		return nil
+	}
+
+	t.Run("all metrics", func(t *testing.T) {
+		spec := fmt.Sprintf(`{"url": %q}`, server.URL+"/metrics")
+		points, resp, err := scrapePrometheus(context.Background(), corev2.FixtureCheck("check"), spec)
+		require.NoError(t, err)
+		assert.Len(t, points, 4)
+		assert.Equal(t, fmt.Sprintf("scraped 4 metrics from %s/metrics\n", server.URL), resp.Output)
+		assert.Equal(t, 0, resp.Status)
+	})
+
+	t.Run("allowlist and relabel rules", func(t *testing.T) {
+		spec := fmt.Sprintf(`{
+			"url": %q,
+			"metrics": ["http_*", "go_goroutines"],
+			"relabel": [
+				{"action": "drop", "source_labels": ["code"], "regex": "4.."},
+				{"source_labels": ["method", "code"], "separator": "/", "target_label": "route"},
+				{"action": "labeldrop", "regex": "prom_help|method"}
+			]
+		}`, server.URL+"/metrics")
+		points, _, err := scrapePrometheus(context.Background(), corev2.FixtureCheck("check"), spec)
+		require.NoError(t, err)
+		require.Len(t, points, 2)
+		assert.Equal(t, map[string]string{
+			"code":      "200",
+			"route":     "post/200",
+			"prom_type": "counter",
+		}, tags(points, "http_requests_total"))
+		assert.Equal(t, map[string]string{
+			"route":     "/",
+			"prom_type": "gauge",
+		}, tags(points, "go_goroutines"))
+	})
+
+	t.Run("scrape error", func(t *testing.T) {
+		spec := fmt.Sprintf(`{"url": %q}`, server.URL+"/missing")
+		points, resp, err := scrapePrometheus(context.Background(), corev2.FixtureCheck("check"), spec)
+		assert.Error(t, err)
+		assert.Nil(t, points)
+		assert.NotNil(t, resp)
+	})
+}
@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	time "github.com/echlebek/timeproxy"
+	"github.com/sensu/sensu-go/transport"
+	"golang.org/x/time/rate"
+)
+
+// handleThrottle handles the throttle messages sent by the backend while it
+// is saturated. The agent sends its events at the rate of the message, for
+// the seconds of the message. Keepalives are not throttled.
+func (a *Agent) handleThrottle(ctx context.Context, payload []byte) error {
+	var throttle transport.Throttle
+	if err := json.Unmarshal(payload, &throttle); err != nil || throttle.Rate <= 0 || throttle.Seconds <= 0 {
+		return fmt.Errorf("invalid throttle payload: %q", payload)
+	}
+
+	a.throttleMu.Lock()
+	defer a.throttleMu.Unlock()
+	a.throttleUntil = time.Now().Add(time.Duration(throttle.Seconds) * time.Second)
+	if a.throttle == nil || a.throttle.Limit() != rate.Limit(throttle.Rate) {
+		a.throttle = rate.NewLimiter(rate.Limit(throttle.Rate), 1)
+		logger.WithField("rate", throttle.Rate).Warn("backend saturated, throttling events")
+	}
+
+	return nil
+}
+
+// eventThrottle returns the limiter of the events of the agent while it is
+// throttled by the backend, or nil.
+func (a *Agent) eventThrottle() *rate.Limiter {
+	a.throttleMu.Lock()
+	defer a.throttleMu.Unlock()
+	if a.throttle != nil && !time.Now().Before(a.throttleUntil) {
+		a.throttle = nil
+		logger.Info("backend no longer saturated, events no longer throttled")
+	}
+	return a.throttle
+}
+
+// waitThrottle waits until the event message msg can be sent, if the agent is
+// throttled by the backend.
+func (a *Agent) waitThrottle(ctx context.Context, msg *transport.Message) error {
+	if msg.Type != transport.MessageTypeEvent {
+		return nil
+	}
+	limiter := a.eventThrottle()
+	if limiter == nil {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
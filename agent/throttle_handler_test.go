@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/transport"
+)
+
+func TestHandleThrottle(t *testing.T) {
+	cfg, cleanup := FixtureConfig()
+	defer cleanup()
+	a, err := NewAgent(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.eventThrottle() != nil {
+		t.Fatal("agent should not be throttled")
+	}
+	for _, payload := range []string{"foo", `{"rate":0,"seconds":5}`, `{"rate":1,"seconds":0}`} {
+		if err := a.handleThrottle(context.Background(), []byte(payload)); err == nil {
+			t.Fatalf("expected an error for the payload %s", payload)
+		}
+	}
+	if err := a.handleThrottle(context.Background(), []byte(`{"rate":2.5,"seconds":30}`)); err != nil {
+		t.Fatal(err)
+	}
+	limiter := a.eventThrottle()
+	if limiter == nil {
+		t.Fatal("agent should be throttled")
+	}
+	if got, want := float64(limiter.Limit()), 2.5; got != want {
+		t.Errorf("got rate %v, want %v", got, want)
+	}
+
+	// the throttle is renewed without resetting its limiter
+	if err := a.handleThrottle(context.Background(), []byte(`{"rate":2.5,"seconds":30}`)); err != nil {
+		t.Fatal(err)
+	}
+	if a.eventThrottle() != limiter {
+		t.Error("throttle limiter was reset")
+	}
+
+	// keepalives are never throttled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := a.waitThrottle(ctx, transport.NewMessage(transport.MessageTypeKeepalive, nil)); err != nil {
+		t.Errorf("keepalive throttled: %v", err)
+	}
+
+	// the throttle expires
+	a.throttleMu.Lock()
+	a.throttleUntil = a.throttleUntil.Add(-30 * time.Second)
+	a.throttleMu.Unlock()
+	if a.eventThrottle() != nil {
+		t.Error("throttle should have expired")
+	}
+}
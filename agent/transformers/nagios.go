@@ -1,8 +1,6 @@
 package transformers
 
 import (
-	"regexp"
-	"strconv"
 	"strings"
 
 	v2 "github.com/sensu/core/v2"
@@ -58,8 +56,12 @@ func ParseNagios(event *v2.Event) NagiosList {
 	// Fetch the perfdata and remove leading & trailing whitespaces
 	perfdata := strings.TrimSpace(output[1])
 
+	// Values in time and size units are only normalized on demand, as it
+	// changes the values of existing metrics
+	normalize := event.Check.Annotations[NormalizeUnitsAnnotation] == "true"
+
 	// Split the perfdata into a slice of metrics
-	metrics := strings.Split(perfdata, " ")
+	metrics := splitNagiosPerfdata(perfdata)
 
 	// Create a Nagios metric for each perfdata metrics
 	for m, metric := range metrics {
@@ -68,32 +70,20 @@ func ParseNagios(event *v2.Event) NagiosList {
 			// the token was just whitespace, ignore it
 			continue
 		}
-		// Clear everything after ';' and split the label and the value
-		parts := strings.Split(metric, ";")
-		parts = strings.Split(parts[0], "=")
-		if len(parts) != 2 {
-			logger.WithFields(fields).WithError(ErrMetricExtraction).Errorf("invalid nagios perfdata metric: %q", metric)
-			continue
-		}
-
-		// Make sure we don't have any whitespace in our label
-		label := strings.Replace(parts[0], " ", "_", -1)
-
-		// Remove all non-numeric characters from the value
-		re := regexp.MustCompile(`[^-\d\.]`)
-		strValue := re.ReplaceAllString(parts[1], "")
-
-		// Parse the value as a float64
-		value, err := strconv.ParseFloat(strValue, 64)
+		// Parse the label, the value and its unit of measurement
+		perf, err := ParseNagiosPerfdata(metric)
 		if err != nil {
-			logger.WithFields(fields).WithError(ErrMetricExtraction).Errorf("invalid nagios perfdata metric value: %q", parts[1])
+			logger.WithFields(fields).WithError(ErrMetricExtraction).Error(err)
 			continue
 		}
+		if normalize {
+			perf = perf.Normalize()
+		}
 
 		// Add this metric to our list
 		n := Nagios{
-			Label:		label,
-			Value:		value,
+			Label:		perf.Label,
+			Value:		perf.Value,
 			Timestamp:	event.Check.Executed,
 			Tags:		event.Check.OutputMetricTags,
 		}
@@ -102,3 +92,24 @@ func ParseNagios(event *v2.Event) NagiosList {
 
 	return nagiosList
 }
+
+// NagiosStatus returns the highest status of the perfdata metrics of the
+// event for their warning and critical thresholds, as described by
+// NagiosPerfdata.Status. Invalid metrics are ignored.
+func NagiosStatus(event *v2.Event) uint32 {
+	var status uint32
+	output := strings.Split(event.Check.Output, "|")
+	if len(output) != 2 {
+		return status
+	}
+	for _, metric := range splitNagiosPerfdata(strings.TrimSpace(output[1])) {
+		perf, err := ParseNagiosPerfdata(strings.TrimSpace(metric))
+		if err != nil {
+			continue
+		}
+		if s := perf.Status(); s > status {
+			status = s
+		}
+	}
+	return status
+}
@@ -0,0 +1,227 @@
+package transformers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// nagiosValueRegexp splits a perfdata value into its number and its unit of
+// measurement.
+var nagiosValueRegexp = regexp.MustCompile(`^([-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?)(.*)$`)
+
+// nagiosUnit is the normalization of a nagios unit of measurement.
+type nagiosUnit struct {
+	base   string
+	factor float64
+}
+
+// NormalizeUnitsAnnotation is the check annotation that normalizes the
+// values of nagios perfdata metrics in time and size units to seconds and
+// bytes, when set to "true".
+const NormalizeUnitsAnnotation = "sensu.io/nagios-normalize-units"
+
+// nagiosUnits normalize the nagios units of measurement to seconds and bytes.
+var nagiosUnits = map[string]nagiosUnit{
+	"s":  {base: "s", factor: 1},
+	"ms": {base: "s", factor: 1e-3},
+	"us": {base: "s", factor: 1e-6},
+	"B":  {base: "B", factor: 1},
+	"KB": {base: "B", factor: 1 << 10},
+	"kB": {base: "B", factor: 1 << 10},
+	"MB": {base: "B", factor: 1 << 20},
+	"GB": {base: "B", factor: 1 << 30},
+	"TB": {base: "B", factor: 1 << 40},
+}
+
+// NagiosRange is a nagios plugin threshold range, as described in the nagios
+// plugin development guidelines.
+type NagiosRange struct {
+	// Start is the start of the range, or -Inf.
+	Start float64
+
+	// End is the end of the range, or +Inf.
+	End float64
+
+	// Inside is true if values inside the range raise an alert, rather than
+	// values outside of it.
+	Inside bool
+}
+
+// ParseNagiosRange parses a nagios threshold range, such as "10", "10:",
+// "~:10", "10:20" or "@10:20".
+func ParseNagiosRange(s string) (NagiosRange, error) {
+	r := NagiosRange{Start: 0, End: math.Inf(1)}
+	spec := s
+	if strings.HasPrefix(spec, "@") {
+		r.Inside = true
+		spec = spec[1:]
+	}
+	if spec == "" {
+		return r, fmt.Errorf("invalid nagios range: %q", s)
+	}
+	start, end, hasStart := strings.Cut(spec, ":")
+	if !hasStart {
+		start, end = "", spec
+	}
+	var err error
+	switch start {
+	case "":
+	case "~":
+		r.Start = math.Inf(-1)
+	default:
+		if r.Start, err = strconv.ParseFloat(start, 64); err != nil {
+			return r, fmt.Errorf("invalid nagios range: %q", s)
+		}
+	}
+	if end != "" {
+		if r.End, err = strconv.ParseFloat(end, 64); err != nil {
+			return r, fmt.Errorf("invalid nagios range: %q", s)
+		}
+	}
+	if r.Start > r.End {
+		return r, fmt.Errorf("invalid nagios range: %q: start is greater than end", s)
+	}
+	return r, nil
+}
+
+// Alert returns true if value raises an alert for the range.
+func (r NagiosRange) Alert(value float64) bool {
+	inside := value >= r.Start && value <= r.End
+	return inside == r.Inside
+}
+
+// NagiosPerfdata is a nagios performance data metric, in the
+// 'label'=value[UOM];[warn];[crit];[min];[max] format.
+type NagiosPerfdata struct {
+	Label string
+	Value float64
+
+	// Unit is the unit of measurement of the metric.
+	Unit string
+
+	Warning  *NagiosRange
+	Critical *NagiosRange
+	Min      *float64
+	Max      *float64
+}
+
+// Status returns the nagios status of the metric for its warning and critical
+// thresholds: 2 if it raises a critical alert, 1 for a warning alert, and 0
+// otherwise.
+func (p NagiosPerfdata) Status() uint32 {
+	if p.Critical != nil && p.Critical.Alert(p.Value) {
+		return 2
+	}
+	if p.Warning != nil && p.Warning.Alert(p.Value) {
+		return 1
+	}
+	return 0
+}
+
+// Normalize returns the metric with its value, thresholds, min and max
+// converted to seconds or bytes when its unit of measurement is a time or a
+// size.
+func (p NagiosPerfdata) Normalize() NagiosPerfdata {
+	unit, ok := nagiosUnits[p.Unit]
+	if !ok {
+		return p
+	}
+	p.Unit = unit.base
+	p.Value *= unit.factor
+	scaleRange := func(r *NagiosRange) *NagiosRange {
+		if r == nil {
+			return nil
+		}
+		scaled := *r
+		scaled.Start *= unit.factor
+		scaled.End *= unit.factor
+		return &scaled
+	}
+	scale := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		scaled := *v * unit.factor
+		return &scaled
+	}
+	p.Warning = scaleRange(p.Warning)
+	p.Critical = scaleRange(p.Critical)
+	p.Min = scale(p.Min)
+	p.Max = scale(p.Max)
+	return p
+}
+
+// ParseNagiosPerfdata parses a single nagios performance data metric. The
+// value, thresholds, min and max are in the unit of measurement of the
+// metric; see Normalize.
+func ParseNagiosPerfdata(metric string) (NagiosPerfdata, error) {
+	var p NagiosPerfdata
+	label, data, ok := strings.Cut(metric, "=")
+	if !ok || label == "" {
+		return p, fmt.Errorf("invalid nagios perfdata metric: %q", metric)
+	}
+	p.Label = strings.Replace(strings.Trim(label, "'"), " ", "_", -1)
+
+	fields := strings.Split(data, ";")
+	match := nagiosValueRegexp.FindStringSubmatch(fields[0])
+	if match == nil {
+		return p, fmt.Errorf("invalid nagios perfdata metric value: %q", fields[0])
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return p, fmt.Errorf("invalid nagios perfdata metric value: %q", fields[0])
+	}
+	p.Value = value
+	p.Unit = match[2]
+
+	// the thresholds, min and max are optional, and ignored if invalid
+	for i, field := range fields[1:] {
+		if field == "" {
+			continue
+		}
+		switch i {
+		case 0, 1:
+			r, err := ParseNagiosRange(field)
+			if err != nil {
+				continue
+			}
+			if i == 0 {
+				p.Warning = &r
+			} else {
+				p.Critical = &r
+			}
+		case 2, 3:
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				continue
+			}
+			if i == 2 {
+				p.Min = &v
+			} else {
+				p.Max = &v
+			}
+		}
+	}
+	return p, nil
+}
+
+// splitNagiosPerfdata splits performance data into metrics. Metrics are
+// separated by spaces, but quoted labels may contain spaces.
+func splitNagiosPerfdata(perfdata string) []string {
+	var metrics []string
+	quoted := false
+	start := 0
+	for i, c := range perfdata {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == ' ' && !quoted:
+			metrics = append(metrics, perfdata[start:i])
+			start = i + 1
+		}
+	}
+	return append(metrics, perfdata[start:])
+}
@@ -0,0 +1,237 @@
+package transformers
+
+import (
+	"math"
+	"testing"
+
+	v2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNagiosRange(t *testing.T) {
+	inf := math.Inf(1)
+	testCases := []struct {
+		name    string
+		in      string
+		want    NagiosRange
+		wantErr bool
+		alert   []float64
+		ok      []float64
+	}{
+		{
+			name:  "end",
+			in:    "10",
+			want:  NagiosRange{Start: 0, End: 10},
+			alert: []float64{-1, 11},
+			ok:    []float64{0, 5, 10},
+		},
+		{
+			name:  "start",
+			in:    "10:",
+			want:  NagiosRange{Start: 10, End: inf},
+			alert: []float64{9.9},
+			ok:    []float64{10, 1e9},
+		},
+		{
+			name:  "negative infinity",
+			in:    "~:10",
+			want:  NagiosRange{Start: math.Inf(-1), End: 10},
+			alert: []float64{11},
+			ok:    []float64{-1e9, 10},
+		},
+		{
+			name:  "outside",
+			in:    "10:20",
+			want:  NagiosRange{Start: 10, End: 20},
+			alert: []float64{9, 21},
+			ok:    []float64{10, 15, 20},
+		},
+		{
+			name:  "inside",
+			in:    "@10:20",
+			want:  NagiosRange{Start: 10, End: 20, Inside: true},
+			alert: []float64{10, 15, 20},
+			ok:    []float64{9, 21},
+		},
+		{
+			name:    "empty",
+			in:      "@",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			in:      "10:foo",
+			wantErr: true,
+		},
+		{
+			name:    "start greater than end",
+			in:      "20:10",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseNagiosRange(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseNagiosRange() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			assert.Equal(t, tc.want, got)
+			for _, v := range tc.alert {
+				assert.True(t, got.Alert(v), "%v should alert", v)
+			}
+			for _, v := range tc.ok {
+				assert.False(t, got.Alert(v), "%v should not alert", v)
+			}
+		})
+	}
+}
+
+func TestParseNagiosPerfdata(t *testing.T) {
+	float := func(f float64) *float64 { return &f }
+
+	testCases := []struct {
+		name    string
+		in      string
+		want    NagiosPerfdata
+		wantErr bool
+		status  uint32
+
+		// normalized is the normalized metric, if it differs from want
+		normalized *NagiosPerfdata
+	}{
+		{
+			name: "value only",
+			in:   "load1=0.5",
+			want: NagiosPerfdata{Label: "load1", Value: 0.5},
+		},
+		{
+			name: "quoted label",
+			in:   "'disk usage'=80%;90;95;0;100",
+			want: NagiosPerfdata{
+				Label:    "disk_usage",
+				Value:    80,
+				Unit:     "%",
+				Warning:  &NagiosRange{Start: 0, End: 90},
+				Critical: &NagiosRange{Start: 0, End: 95},
+				Min:      float(0),
+				Max:      float(100),
+			},
+		},
+		{
+			name: "milliseconds",
+			in:   "rta=250ms;200;500",
+			want: NagiosPerfdata{
+				Label:    "rta",
+				Value:    250,
+				Unit:     "ms",
+				Warning:  &NagiosRange{Start: 0, End: 200},
+				Critical: &NagiosRange{Start: 0, End: 500},
+			},
+			normalized: &NagiosPerfdata{
+				Label:    "rta",
+				Value:    0.25,
+				Unit:     "s",
+				Warning:  &NagiosRange{Start: 0, End: 0.2},
+				Critical: &NagiosRange{Start: 0, End: 0.5},
+			},
+			status: 1,
+		},
+		{
+			name: "megabytes",
+			in:   "/=2MB;;@1:3",
+			want: NagiosPerfdata{
+				Label:    "/",
+				Value:    2,
+				Unit:     "MB",
+				Critical: &NagiosRange{Start: 1, End: 3, Inside: true},
+			},
+			normalized: &NagiosPerfdata{
+				Label:    "/",
+				Value:    2 << 20,
+				Unit:     "B",
+				Critical: &NagiosRange{Start: 1 << 20, End: 3 << 20, Inside: true},
+			},
+			status: 2,
+		},
+		{
+			name: "counter",
+			in:   "requests=1234c",
+			want: NagiosPerfdata{Label: "requests", Value: 1234, Unit: "c"},
+		},
+		{
+			name: "invalid thresholds are ignored",
+			in:   "load1=0.5;foo;bar",
+			want: NagiosPerfdata{Label: "load1", Value: 0.5},
+		},
+		{
+			name:    "no value",
+			in:      "load1",
+			wantErr: true,
+		},
+		{
+			name:    "unknown value",
+			in:      "load1=U",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseNagiosPerfdata(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseNagiosPerfdata() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.status, got.Status())
+
+			normalized := got.Normalize()
+			if tc.normalized == nil {
+				tc.normalized = &tc.want
+			}
+			assert.Equal(t, *tc.normalized, normalized)
+			assert.Equal(t, tc.status, normalized.Status())
+		})
+	}
+}
+
+func TestSplitNagiosPerfdata(t *testing.T) {
+	got := splitNagiosPerfdata("load1=0.5 'disk usage'=80%;90;95 rta=1ms")
+	want := []string{"load1=0.5", "'disk usage'=80%;90;95", "rta=1ms"}
+	assert.Equal(t, want, got)
+}
+
+func TestParseNagiosNormalizeUnits(t *testing.T) {
+	event := v2.FixtureEvent("entity", "check")
+	event.Check.Output = "PING ok | rta=250ms;200;500 size=2MB"
+
+	values := func(list NagiosList) []float64 {
+		var values []float64
+		for _, n := range list {
+			values = append(values, n.Value)
+		}
+		return values
+	}
+	assert.Equal(t, []float64{250, 2}, values(ParseNagios(event)))
+
+	event.Check.Annotations = map[string]string{NormalizeUnitsAnnotation: "true"}
+	assert.Equal(t, []float64{0.25, 2 << 20}, values(ParseNagios(event)))
+}
+
+func TestNagiosStatus(t *testing.T) {
+	event := v2.FixtureEvent("entity", "check")
+	event.Check.Output = "PING ok | loss=0%;20;60 rta=250ms;200;500 invalid"
+	assert.Equal(t, uint32(1), NagiosStatus(event))
+
+	event.Check.Output = "PING ok | loss=0%;20;60"
+	assert.Equal(t, uint32(0), NagiosStatus(event))
+
+	event.Check.Output = "PING ok"
+	assert.Equal(t, uint32(0), NagiosStatus(event))
+}
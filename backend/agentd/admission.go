@@ -0,0 +1,196 @@
+package agentd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/transport"
+)
+
+const (
+	// DefaultAdmissionTimeout is the default timeout of the calls to the
+	// admission webhook.
+	DefaultAdmissionTimeout = 5 * time.Second
+
+	// maxAdmissionResponseSize is the maximum size of the responses of the
+	// admission webhook.
+	maxAdmissionResponseSize = 1 << 20
+
+	admissionReviewsName = "sensu_go_agentd_admission_reviews_total"
+
+	admissionAllowed  = "allowed"
+	admissionMutated  = "mutated"
+	admissionRejected = "rejected"
+	admissionError    = "error"
+
+	// AdmissionTransportWebSocket and AdmissionTransportGRPC are the
+	// transports of the agent connections reviewed by the admission webhook.
+	AdmissionTransportWebSocket = "websocket"
+	AdmissionTransportGRPC      = "grpc"
+)
+
+var admissionReviews = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: admissionReviewsName,
+		Help: "The total number of agent connections reviewed by the admission webhook, by result",
+	},
+	[]string{"result"},
+)
+
+// AdmissionRequest is the agent connection sent to the admission webhook for
+// review.
+type AdmissionRequest struct {
+	AgentName     string   `json:"agent_name"`
+	Namespace     string   `json:"namespace"`
+	User          string   `json:"user"`
+	Subscriptions []string `json:"subscriptions"`
+	AgentVersion  string   `json:"agent_version"`
+	RemoteAddr    string   `json:"remote_addr"`
+
+	// Transport is the transport of the agent connection, either
+	// AdmissionTransportWebSocket or AdmissionTransportGRPC.
+	Transport string `json:"transport"`
+
+	// TLS is the identity of the client certificate of the agent, if it
+	// authenticated with one.
+	TLS *AdmissionTLSIdentity `json:"tls,omitempty"`
+}
+
+// AdmissionTLSIdentity is the identity of the client certificate of an
+// agent.
+type AdmissionTLSIdentity struct {
+	CommonName   string   `json:"common_name"`
+	DNSNames     []string `json:"dns_names,omitempty"`
+	SerialNumber string   `json:"serial_number"`
+	Issuer       string   `json:"issuer"`
+
+	// Fingerprint is the hex-encoded SHA-256 fingerprint of the certificate.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// AdmissionResponse is the review of an agent connection by the admission
+// webhook.
+type AdmissionResponse struct {
+	// Allowed is true if the agent connection is accepted.
+	Allowed bool `json:"allowed"`
+
+	// Reason tells the agent why its connection is rejected.
+	Reason string `json:"reason,omitempty"`
+
+	// Subscriptions replace the subscriptions of the agent, such as to strip
+	// the disallowed subscriptions. The subscriptions of the agent are kept
+	// if it is null.
+	Subscriptions []string `json:"subscriptions,omitempty"`
+}
+
+// AdmissionWebhook is an external HTTP service that reviews the agent
+// connections when their session is established, over WebSocket or gRPC, to
+// reject them or to change their subscriptions. The changed subscriptions
+// also bound the subscriptions that the entity config of the agent can set
+// while its session lasts. It is given the connection as a JSON
+// AdmissionRequest in a POST request, and must answer with a JSON
+// AdmissionResponse.
+type AdmissionWebhook struct {
+	url      string
+	failOpen bool
+	client   *http.Client
+}
+
+// NewAdmissionWebhook returns an admission webhook that calls url with
+// timeout. The agent connections are rejected when the webhook fails, or
+// accepted unchanged if failOpen is set. It returns nil if url is empty.
+func NewAdmissionWebhook(url string, timeout time.Duration, failOpen bool) *AdmissionWebhook {
+	if url == "" {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = DefaultAdmissionTimeout
+	}
+	return &AdmissionWebhook{
+		url:      url,
+		failOpen: failOpen,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Review returns the review of the agent connection req by the webhook. A
+// nil AdmissionWebhook allows every connection.
+func (w *AdmissionWebhook) Review(ctx context.Context, req AdmissionRequest) AdmissionResponse {
+	if w == nil {
+		return AdmissionResponse{Allowed: true}
+	}
+	resp, err := w.review(ctx, req)
+	if err != nil {
+		admissionReviews.WithLabelValues(admissionError).Inc()
+		lager := logger.WithError(err).WithField("agent", req.AgentName)
+		if w.failOpen {
+			lager.Warn("admission webhook failed, accepting the agent connection")
+			return AdmissionResponse{Allowed: true}
+		}
+		lager.Error("admission webhook failed, rejecting the agent connection")
+		return AdmissionResponse{Reason: "the agent connection could not be reviewed"}
+	}
+	switch {
+	case !resp.Allowed:
+		admissionReviews.WithLabelValues(admissionRejected).Inc()
+	case resp.Subscriptions != nil:
+		admissionReviews.WithLabelValues(admissionMutated).Inc()
+	default:
+		admissionReviews.WithLabelValues(admissionAllowed).Inc()
+	}
+	return resp
+}
+
+func (w *AdmissionWebhook) review(ctx context.Context, req AdmissionRequest) (AdmissionResponse, error) {
+	var resp AdmissionResponse
+	body, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := w.client.Do(httpReq)
+	if err != nil {
+		return resp, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return resp, fmt.Errorf("admission webhook returned status %d", httpResp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(httpResp.Body, maxAdmissionResponseSize)).Decode(&resp); err != nil {
+		return resp, fmt.Errorf("invalid admission webhook response: %s", err)
+	}
+	return resp, nil
+}
+
+// admissionTransport returns the transport of the agent connection r.
+func admissionTransport(r *http.Request) string {
+	if transport.IsGRPCRequest(r) {
+		return AdmissionTransportGRPC
+	}
+	return AdmissionTransportWebSocket
+}
+
+// admissionTLSIdentity returns the identity of the client certificate cert.
+func admissionTLSIdentity(cert *x509.Certificate) *AdmissionTLSIdentity {
+	fingerprint := sha256.Sum256(cert.Raw)
+	return &AdmissionTLSIdentity{
+		CommonName:   cert.Subject.CommonName,
+		DNSNames:     cert.DNSNames,
+		SerialNumber: cert.SerialNumber.String(),
+		Issuer:       cert.Issuer.CommonName,
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+	}
+}
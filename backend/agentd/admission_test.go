@@ -0,0 +1,122 @@
+package agentd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/agent"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/testing/mockbus"
+	"github.com/sensu/sensu-go/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmissionWebhookReview(t *testing.T) {
+	req := AdmissionRequest{
+		AgentName:     "agent",
+		Namespace:     "default",
+		Subscriptions: []string{"linux", "production"},
+		RemoteAddr:    "192.0.2.1:56324",
+	}
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		failOpen bool
+		want     AdmissionResponse
+	}{
+		{name: "allowed", status: http.StatusOK, body: `{"allowed":true}`, want: AdmissionResponse{Allowed: true}},
+		{name: "rejected", status: http.StatusOK, body: `{"allowed":false,"reason":"unknown host"}`, want: AdmissionResponse{Reason: "unknown host"}},
+		{name: "mutated", status: http.StatusOK, body: `{"allowed":true,"subscriptions":["linux"]}`, want: AdmissionResponse{Allowed: true, Subscriptions: []string{"linux"}}},
+		{name: "error", status: http.StatusInternalServerError, want: AdmissionResponse{Reason: "the agent connection could not be reviewed"}},
+		{name: "invalid", status: http.StatusOK, body: `{`, want: AdmissionResponse{Reason: "the agent connection could not be reviewed"}},
+		{name: "fail open", status: http.StatusInternalServerError, failOpen: true, want: AdmissionResponse{Allowed: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var got AdmissionRequest
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				assert.Equal(t, req, got)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			webhook := NewAdmissionWebhook(server.URL, time.Second, tt.failOpen)
+			assert.Equal(t, tt.want, webhook.Review(context.Background(), req))
+		})
+	}
+}
+
+func TestAdmissionWebhookTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	webhook := NewAdmissionWebhook(server.URL, 10*time.Millisecond, false)
+	resp := webhook.Review(context.Background(), AdmissionRequest{AgentName: "agent"})
+	assert.False(t, resp.Allowed)
+}
+
+func TestNilAdmissionWebhook(t *testing.T) {
+	webhook := NewAdmissionWebhook("", time.Second, false)
+	require.Nil(t, webhook)
+	assert.Equal(t, AdmissionResponse{Allowed: true}, webhook.Review(context.Background(), AdmissionRequest{}))
+}
+
+func TestSessionAdmittedSubscriptions(t *testing.T) {
+	keepalive := corev2.FixtureEvent("entity", corev2.KeepaliveCheckName)
+	keepalive.Entity.Subscriptions = []string{"linux", "production"}
+	payload, err := json.Marshal(keepalive)
+	require.NoError(t, err)
+
+	var published *corev2.Event
+	bus := &mockbus.MockBus{}
+	bus.On("Publish", messaging.TopicKeepalive, mock.Anything).Run(func(args mock.Arguments) {
+		published = args.Get(1).(*corev2.Event)
+	}).Return(nil)
+	s := &Session{
+		cfg: SessionConfig{
+			Namespace:             "default",
+			Subscriptions:         []string{"linux", "entity:entity"},
+			admittedSubscriptions: []string{"linux", "entity:entity"},
+		},
+		bus:       bus,
+		unmarshal: agent.UnmarshalJSON,
+	}
+	require.NoError(t, s.handleKeepalive(context.Background(), payload))
+	require.NotNil(t, published)
+	assert.Equal(t, []string{"linux", "entity:entity"}, published.Entity.Subscriptions)
+}
+
+func TestSessionAdmitSubscriptions(t *testing.T) {
+	s := &Session{}
+	assert.Equal(t, []string{"linux", "windows"}, s.admitSubscriptions([]string{"linux", "windows"}))
+
+	// The entity config can't add subscriptions beyond the admitted ones
+	s.cfg.admittedSubscriptions = []string{"linux", "entity:entity"}
+	assert.Equal(t, []string{"linux", "entity:entity"}, s.admitSubscriptions([]string{"linux", "windows", "entity:entity"}))
+	assert.Equal(t, []string{}, s.admitSubscriptions([]string{"windows"}))
+}
+
+func TestAdmissionTransport(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, AdmissionTransportWebSocket, admissionTransport(r))
+
+	r = httptest.NewRequest(http.MethodPost, transport.GRPCMethod, nil)
+	r.ProtoMajor = 2
+	r.Header.Set("Content-Type", "application/grpc")
+	assert.Equal(t, AdmissionTransportGRPC, admissionTransport(r))
+}
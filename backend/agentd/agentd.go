@@ -6,6 +6,7 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -23,14 +24,20 @@ import (
 	"github.com/sensu/sensu-go/backend/authentication/jwt"
 	"github.com/sensu/sensu-go/backend/authorization"
 	"github.com/sensu/sensu-go/backend/authorization/rbac"
+	"github.com/sensu/sensu-go/backend/certmonitor"
 	"github.com/sensu/sensu-go/backend/messaging"
 	"github.com/sensu/sensu-go/backend/metrics"
 	"github.com/sensu/sensu-go/backend/ringv2"
+	"github.com/sensu/sensu-go/backend/selfmonitor"
 	"github.com/sensu/sensu-go/backend/store"
 	cachev2 "github.com/sensu/sensu-go/backend/store/cache/v2"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 	"github.com/sensu/sensu-go/transport"
+	"github.com/sensu/sensu-go/transport/schema"
+	"github.com/sensu/sensu-go/version"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 var (
@@ -70,6 +77,60 @@ func init() {
 	if err := prometheus.Register(eventBytesSummary); err != nil {
 		metrics.LogError(logger, EventBytesSummaryName, err)
 	}
+	if err := prometheus.Register(agentVersionSessions); err != nil {
+		metrics.LogError(logger, agentVersionSessionsName, err)
+	}
+	if err := prometheus.Register(entityConfigUpdatesCoalesced); err != nil {
+		metrics.LogError(logger, entityConfigUpdatesCoalescedName, err)
+	}
+	if err := prometheus.Register(entityConfigUpdatesPending); err != nil {
+		metrics.LogError(logger, entityConfigUpdatesPendingName, err)
+	}
+	if err := prometheus.Register(eventsRateLimited); err != nil {
+		metrics.LogError(logger, eventsRateLimitedName, err)
+	}
+	if err := prometheus.Register(rejectedEvents); err != nil {
+		metrics.LogError(logger, rejectedEventsName, err)
+	}
+	if err := prometheus.Register(sessionRTT); err != nil {
+		metrics.LogError(logger, sessionRTTName, err)
+	}
+	if err := prometheus.Register(sessionMissedPongs); err != nil {
+		metrics.LogError(logger, sessionMissedPongsName, err)
+	}
+	if err := prometheus.Register(throttleMessages); err != nil {
+		metrics.LogError(logger, throttleMessagesName, err)
+	}
+	if err := prometheus.Register(checkChannelCollector{}); err != nil {
+		metrics.LogError(logger, checkChannelDepthName, err)
+	}
+	if err := prometheus.Register(checkRequestsBlocked); err != nil {
+		metrics.LogError(logger, checkRequestsBlockedName, err)
+	}
+	if err := prometheus.Register(checkRequestsDropped); err != nil {
+		metrics.LogError(logger, checkRequestsDroppedName, err)
+	}
+	if err := prometheus.Register(deadLettersGauge); err != nil {
+		metrics.LogError(logger, deadLettersName, err)
+	}
+	if err := prometheus.Register(deadLetterEvents); err != nil {
+		metrics.LogError(logger, deadLetterEventsName, err)
+	}
+	if err := prometheus.Register(proxyProtocolErrors); err != nil {
+		metrics.LogError(logger, proxyProtocolErrorsName, err)
+	}
+	if err := prometheus.Register(admissionReviews); err != nil {
+		metrics.LogError(logger, admissionReviewsName, err)
+	}
+	if err := prometheus.Register(sessionsRefused); err != nil {
+		metrics.LogError(logger, sessionsRefusedName, err)
+	}
+	if err := prometheus.Register(sessionBytes); err != nil {
+		metrics.LogError(logger, sessionBytesName, err)
+	}
+	if err := prometheus.Register(tlsReloads); err != nil {
+		metrics.LogError(logger, tlsReloadsName, err)
+	}
 }
 
 type NamespaceCache = *cachev2.Resource[*corev3.Namespace, corev3.Namespace]
@@ -87,17 +148,43 @@ type Agentd struct {
 	wg             *sync.WaitGroup
 	errChan        chan error
 	httpServer     *http.Server
+	grpcServer     *http.Server
+	grpc           *transport.GRPCServer
 	store          storev2.Interface
 	bus            messaging.MessageBus
 	tls            *corev2.TLSOptions
+	certs          *certReloader
 	ringPool       *ringv2.RingPool
 	ctx            context.Context
 	cancel         context.CancelFunc
 	writeTimeout   int
 	namespaceCache NamespaceCache
 	watcher        <-chan []storev2.WatchEvent
+	pacer          *configPacer
+	eventLimits    EventRateLimits
+	nsLimiters     *namespaceLimiters
 	healthRouter   routers.Router
 	authenticator  Authenticator
+	drainWindow    time.Duration
+	throttle       EventThrottle
+	checkChannel   int
+	dropChecks     bool
+	clockSkew      time.Duration
+	compression    []string
+	maxEventSize   int
+	pingInterval   time.Duration
+	minProtocol    int
+	deadLetters    *DeadLetterQueue
+	configAudit    *ConfigAuditLog
+	proxyProtocol  bool
+	admission      *AdmissionWebhook
+	sessions       *sessionCounts
+	certMonitor    *certmonitor.Monitor
+	selfMonitor    *selfmonitor.Monitor
+
+	// backendVersion is the version that agent versions are checked against
+	backendVersion  string
+	versionPolicies *versionPolicyCache
 }
 
 // Config configures an Agentd.
@@ -112,6 +199,98 @@ type Config struct {
 	Watcher       <-chan []storev2.WatchEvent
 	HealthRouter  routers.Router
 	Authenticator Authenticator
+
+	// EntityConfigRate is the maximum number of entity config updates pushed
+	// to agents per second. Updates are not rate limited if it is zero.
+	EntityConfigRate float64
+
+	// EventRateLimits limit the events received from agents.
+	EventRateLimits EventRateLimits
+
+	// DrainWindow is the duration over which the sessions are closed when
+	// agentd stops, up to 20 seconds. The sessions are all closed at once
+	// when it is zero.
+	DrainWindow time.Duration
+
+	// EventThrottle throttles the events of the agents while the backend is
+	// saturated.
+	EventThrottle EventThrottle
+
+	// CheckChannelSize is the number of check requests buffered for each
+	// session, DefaultCheckChannelSize if it is zero. The check requests sent
+	// to a full session block the message bus, or are dropped if
+	// DropCheckRequests is set.
+	CheckChannelSize  int
+	DropCheckRequests bool
+
+	// ClockSkewThreshold is the clock skew of the agents from which a warning
+	// event is published for their entity. The clock skew of the agents is
+	// not checked if it is zero.
+	ClockSkewThreshold time.Duration
+
+	// Compression is the list of the compression algorithms accepted for the
+	// messages of the sessions. The messages are not compressed if it is
+	// empty.
+	Compression []string
+
+	// MaxEventSize is the maximum size of the events received from the
+	// agents, in bytes. Larger events are rejected before they are decoded.
+	// The size of the events is not limited if it is zero.
+	MaxEventSize int
+
+	// PingInterval is the interval at which the sessions ping their agent,
+	// to measure the round-trip time of their connection. The agents are not
+	// pinged if it is zero.
+	PingInterval time.Duration
+
+	// MinProtocolVersion is the oldest protocol version served to the
+	// agents. Agents that only speak older versions are rejected. All the
+	// protocol versions are served if it is zero.
+	MinProtocolVersion int
+
+	// DeadLetterQueue holds the agent events that could not be published,
+	// to publish them again. The events that could not be published are
+	// lost if it is nil.
+	DeadLetterQueue *DeadLetterQueue
+
+	// ConfigAuditLog records the entity config updates sent to the agents.
+	// The updates are not recorded if it is nil.
+	ConfigAuditLog *ConfigAuditLog
+
+	// ProxyProtocol is true if agentd is behind a load balancer that sends
+	// a PROXY protocol header, v1 or v2, at the start of the connections.
+	// The agent addresses are read from the headers, and the connections
+	// without a header are closed.
+	ProxyProtocol bool
+
+	// AdmissionWebhook reviews the agent connections when their session is
+	// established, to reject them or change their subscriptions. Every
+	// agent connection is accepted unchanged if it is nil.
+	AdmissionWebhook *AdmissionWebhook
+
+	// SessionLimits limit the number of concurrent agent sessions of the
+	// backend, overall and per namespace.
+	SessionLimits SessionLimits
+
+	// TLSReloadInterval is the interval at which the certificate, key and
+	// CA files of TLS are checked for changes, to reload them without
+	// closing the established sessions. They are only reloaded on SIGHUP if
+	// it is zero.
+	TLSReloadInterval time.Duration
+
+	// GRPCPort is the port on which the agents connect over gRPC streams,
+	// for the networks that don't allow WebSocket. The agents only connect
+	// over WebSocket if it is zero.
+	GRPCPort int
+
+	// CertMonitor monitors the expiry of the client certificates presented
+	// by the agents, while their session lasts. They are not monitored if it
+	// is nil.
+	CertMonitor *certmonitor.Monitor
+
+	// SelfMonitor reports the internal errors of the agent sessions with the
+	// events of the backend entity. They are not reported if it is nil.
+	SelfMonitor *selfmonitor.Monitor
 }
 
 // Option is a functional option.
@@ -135,14 +314,50 @@ func New(c Config, opts ...Option) (*Agentd, error) {
 		writeTimeout:  c.WriteTimeout,
 		store:         c.Store,
 		watcher:       c.Watcher,
+		pacer:         newConfigPacer(c.EntityConfigRate),
+		eventLimits:   c.EventRateLimits,
+		nsLimiters:    newNamespaceLimiters(c.EventRateLimits.Namespace),
 		authenticator: c.Authenticator,
+		drainWindow:   c.DrainWindow,
+		throttle:      c.EventThrottle,
+		checkChannel:  c.CheckChannelSize,
+		dropChecks:    c.DropCheckRequests,
+		clockSkew:     c.ClockSkewThreshold,
+		compression:   c.Compression,
+		maxEventSize:  c.MaxEventSize,
+		pingInterval:  c.PingInterval,
+		minProtocol:   c.MinProtocolVersion,
+		deadLetters:   c.DeadLetterQueue,
+		configAudit:   c.ConfigAuditLog,
+		proxyProtocol: c.ProxyProtocol,
+		admission:     c.AdmissionWebhook,
+		sessions:      newSessionCounts(c.SessionLimits),
+		certMonitor:   c.CertMonitor,
+		selfMonitor:   c.SelfMonitor,
+
+		backendVersion:  version.Semver(),
+		versionPolicies: &versionPolicyCache{store: c.Store},
 	}
 
-	// prepare server TLS config
+	if err := transport.ValidateCompressions(c.Compression); err != nil {
+		return nil, err
+	}
+	if a.minProtocol > transport.ProtocolVersion {
+		return nil, fmt.Errorf("minimum protocol version %d is newer than the latest protocol version %d", a.minProtocol, transport.ProtocolVersion)
+	}
+
+	// prepare server TLS config, reloaded when its files change
 	tlsServerConfig, err := c.TLS.ToServerTLSConfig()
 	if err != nil {
 		return nil, err
 	}
+	if c.TLS != nil {
+		a.certs, err = newCertReloader(c.TLS, c.TLSReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+		tlsServerConfig = a.certs.serverConfig()
+	}
 
 	// Configure the middlewares used by agentd's HTTP server by assigning them to
 	// public variables so they can be overriden from the enterprise codebase
@@ -162,6 +377,7 @@ func New(c Config, opts ...Option) (*Agentd, error) {
 
 	route := router.NewRoute().Subrouter()
 	route.HandleFunc("/", a.webSocketHandler)
+	route.HandleFunc(transport.GRPCMethod, a.webSocketHandler)
 	route.Use(agentLimit, authenticate, authorize)
 
 	a.httpServer = &http.Server{
@@ -181,6 +397,23 @@ func New(c Config, opts ...Option) (*Agentd, error) {
 			}
 		},
 	}
+
+	// The gRPC streams have their own HTTP/2 server, without the timeouts
+	// of the WebSocket server that would end them
+	if c.GRPCPort > 0 {
+		a.grpc = transport.NewGRPCServer()
+		a.grpcServer = &http.Server{
+			Addr:     fmt.Sprintf("%s:%d", a.Host, c.GRPCPort),
+			Handler:  transport.GRPCErrors(router),
+			ErrorLog: log.New(&logrusIOWriter{entry: logger}, "", 0),
+		}
+		if a.certs != nil {
+			a.grpcServer.TLSConfig = a.certs.serverConfig("h2")
+		} else {
+			a.grpcServer.Handler = h2c.NewHandler(a.grpcServer.Handler, &http2.Server{})
+		}
+	}
+
 	for _, o := range opts {
 		if err := o(a); err != nil {
 			return nil, err
@@ -202,7 +435,46 @@ func (a *Agentd) Start() error {
 	if err != nil {
 		return fmt.Errorf("failed to start agentd: %s", err)
 	}
+	if a.proxyProtocol {
+		ln = newProxyProtocolListener(ln)
+	}
+	var grpcLn net.Listener
+	if a.grpcServer != nil {
+		logger.Warn("starting agentd gRPC listener on address: ", a.grpcServer.Addr)
+		grpcLn, err = net.Listen("tcp", a.grpcServer.Addr)
+		if err != nil {
+			_ = ln.Close()
+			return fmt.Errorf("failed to start agentd: %s", err)
+		}
+		if a.proxyProtocol {
+			grpcLn = newProxyProtocolListener(grpcLn)
+		}
+	}
 
+	a.serve(a.httpServer, ln)
+	if a.grpcServer != nil {
+		a.serve(a.grpcServer, grpcLn)
+	}
+
+	go a.runWatcher()
+	go a.pacer.Run(a.ctx, a.handleEvent)
+	go a.runThrottle()
+	go a.deadLetters.run(a.ctx)
+	go a.certs.run(a.ctx, a.bus)
+
+	sessionCounterOnce.Do(func() {
+		if err := prometheus.Register(sessionCounter); err != nil {
+			logger.WithError(err).Error("error registering session counter")
+			a.errChan <- err
+		}
+	})
+
+	return nil
+}
+
+// serve serves the connections of ln with server, with TLS if agentd has a
+// TLS configuration.
+func (a *Agentd) serve(server *http.Server, ln net.Listener) {
 	a.wg.Add(1)
 
 	go func() {
@@ -210,25 +482,14 @@ func (a *Agentd) Start() error {
 		var err error
 		if a.tls != nil {
 			// TLS configuration comes from ToServerTLSConfig
-			err = a.httpServer.ServeTLS(ln, "", "")
+			err = server.ServeTLS(ln, "", "")
 		} else {
-			err = a.httpServer.Serve(ln)
+			err = server.Serve(ln)
 		}
 		if err != nil && err != http.ErrServerClosed {
 			a.errChan <- fmt.Errorf("agentd failed while serving: %s", err)
 		}
 	}()
-
-	go a.runWatcher()
-
-	sessionCounterOnce.Do(func() {
-		if err := prometheus.Register(sessionCounter); err != nil {
-			logger.WithError(err).Error("error registering session counter")
-			a.errChan <- err
-		}
-	})
-
-	return nil
 }
 
 func (a *Agentd) runWatcher() {
@@ -244,9 +505,7 @@ func (a *Agentd) runWatcher() {
 				return
 			}
 			for _, event := range events {
-				if err := a.handleEvent(event); err != nil {
-					logger.WithError(err).Error("error handling entity config watch event")
-				}
+				a.pacer.Push(event)
 			}
 		}
 	}
@@ -265,14 +524,26 @@ func (a *Agentd) handleEvent(event storev2.WatchEvent) error {
 	return nil
 }
 
-// Stop Agentd.
+// Stop Agentd. Its sessions are drained over its drain window first.
 func (a *Agentd) Stop() error {
+	if a.drainWindow > 0 {
+		window := a.drainWindow
+		if window > maxStopDrainWindow {
+			window = maxStopDrainWindow
+		}
+		drain(a.ctx, window)
+	}
 	a.cancel()
-	if err := a.httpServer.Shutdown(context.TODO()); err != nil {
-		// failure/timeout shutting down the server gracefully
-		logger.Error("failed to shutdown http server gracefully - forcing shutdown")
-		if closeErr := a.httpServer.Close(); closeErr != nil {
-			logger.Error("failed to shutdown http server forcefully")
+	for _, server := range []*http.Server{a.httpServer, a.grpcServer} {
+		if server == nil {
+			continue
+		}
+		if err := server.Shutdown(context.TODO()); err != nil {
+			// failure/timeout shutting down the server gracefully
+			logger.Error("failed to shutdown http server gracefully - forcing shutdown")
+			if closeErr := server.Close(); closeErr != nil {
+				logger.Error("failed to shutdown http server forcefully")
+			}
 		}
 	}
 	a.running.Store(false)
@@ -309,17 +580,32 @@ func (a *Agentd) webSocketHandler(w http.ResponseWriter, r *http.Request) {
 		"namespace": r.Header.Get(transport.HeaderKeyNamespace),
 	})
 
+	// Agents must connect to another backend while this one drains
+	if isDraining() {
+		lager.Warn("rejecting agent connection, the agent sessions of this backend are draining")
+		http.Error(w, "the agent sessions of this backend are draining", http.StatusServiceUnavailable)
+		return
+	}
+
 	responseHeader := make(http.Header)
 	responseHeader.Add("Accept", agent.ProtobufSerializationHeader)
 	lager.WithField("header", fmt.Sprintf("Accept: %s", agent.ProtobufSerializationHeader)).Debug("setting header")
 	responseHeader.Add("Accept", agent.JSONSerializationHeader)
 	lager.WithField("header", fmt.Sprintf("Accept: %s", agent.JSONSerializationHeader)).Debug("setting header")
-	if r.Header.Get("Accept") == agent.ProtobufSerializationHeader {
+	responseHeader.Add("Accept", agent.MsgpackSerializationHeader)
+	lager.WithField("header", fmt.Sprintf("Accept: %s", agent.MsgpackSerializationHeader)).Debug("setting header")
+	switch r.Header.Get("Accept") {
+	case agent.ProtobufSerializationHeader:
 		marshal = proto.Marshal
 		unmarshal = proto.Unmarshal
 		contentType = agent.ProtobufSerializationHeader
 		lager.WithField("format", "protobuf").Debug("setting serialization/deserialization")
-	} else {
+	case agent.MsgpackSerializationHeader:
+		marshal = agent.MarshalMsgpack
+		unmarshal = agent.UnmarshalMsgpack
+		contentType = agent.MsgpackSerializationHeader
+		lager.WithField("format", "msgpack").Debug("setting serialization/deserialization")
+	default:
 		marshal = agent.MarshalJSON
 		unmarshal = agent.UnmarshalJSON
 		contentType = agent.JSONSerializationHeader
@@ -330,50 +616,219 @@ func (a *Agentd) webSocketHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Validate the agent namespace
 	namespace := r.Header.Get(transport.HeaderKeyNamespace)
-	var found bool
+	var found *corev3.Namespace
 	values := a.namespaceCache.Get("")
 	for _, value := range values {
 		objectMeta := value.Resource.GetMetadata()
 		if objectMeta != nil && objectMeta.Name == namespace {
-			found = true
+			found = value.Resource
 			break
 		}
 	}
-	if namespace == "" || !found {
+	if namespace == "" || found == nil {
 		lager.Warningf("namespace %q not found", namespace)
 		http.Error(w, fmt.Sprintf("namespace %q not found", namespace), http.StatusNotFound)
 		return
 	}
 
-	conn, err := upgrader.Upgrade(w, r, responseHeader)
+	// Validate the agent version
+	agentVersion := r.Header.Get(transport.HeaderKeyAgentVersion)
+	if err := a.checkAgentVersion(r.Context(), found, agentVersion); err != nil {
+		lager.WithError(err).Warning("agent version is not supported")
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	// The admission webhook can reject the agent connection, or change its
+	// subscriptions
+	subscriptions := strings.Split(r.Header.Get(transport.HeaderKeySubscriptions), ",")
+	admissionReq := AdmissionRequest{
+		AgentName:     r.Header.Get(transport.HeaderKeyAgentName),
+		Namespace:     namespace,
+		User:          r.Header.Get(transport.HeaderKeyUser),
+		Subscriptions: subscriptions,
+		AgentVersion:  agentVersion,
+		RemoteAddr:    r.RemoteAddr,
+		Transport:     admissionTransport(r),
+	}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		admissionReq.TLS = admissionTLSIdentity(r.TLS.PeerCertificates[0])
+	}
+	admission := a.admission.Review(r.Context(), admissionReq)
+	if !admission.Allowed {
+		lager.WithField("reason", admission.Reason).Warning("agent connection rejected by the admission webhook")
+		http.Error(w, fmt.Sprintf("agent connection rejected: %s", admission.Reason), http.StatusForbidden)
+		return
+	}
+	if admission.Subscriptions != nil {
+		lager.WithField("subscriptions", admission.Subscriptions).Info("agent subscriptions changed by the admission webhook")
+		subscriptions = admission.Subscriptions
+	}
+
+	// Agents built with another wire schema can only exchange the fields
+	// that are compatible, which the schema tests guarantee for the fields
+	// of the older versions.
+	if wireSchema := r.Header.Get(transport.HeaderKeyWireSchema); wireSchema != "" && wireSchema != schema.Fingerprint() {
+		lager.WithFields(logrus.Fields{
+			"agent_version":       agentVersion,
+			"agent_wire_schema":   wireSchema,
+			"backend_wire_schema": schema.Fingerprint(),
+		}).Info("agent was built with a different wire schema")
+	}
+
+	// Agents speak the latest protocol version of both sides. Agents that
+	// don't send their protocol version speak the legacy protocol, and are
+	// never sent the messages introduced by later versions.
+	offeredProtocol, err := transport.ParseProtocolVersion(r.Header.Get(transport.HeaderKeyProtocolVersion))
 	if err != nil {
-		lager.WithError(err).Error("transport error on websocket upgrade")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		lager.WithError(err).Warning("invalid agent protocol version")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	protocolVersion, err := transport.NegotiateProtocolVersion(offeredProtocol, a.minProtocol)
+	if err != nil {
+		lager.WithError(err).Warning("agent protocol version is not supported")
+		http.Error(w, err.Error(), http.StatusUpgradeRequired)
+		return
+	}
+	responseHeader.Set(transport.HeaderKeyProtocolVersion, strconv.Itoa(protocolVersion))
+
+	// Agents that ask for event acknowledgements retry the events that
+	// are not acknowledged
+	eventAcks := r.Header.Get(transport.HeaderKeyEventAcks) == "true"
+	if eventAcks {
+		responseHeader.Set(transport.HeaderKeyEventAcks, "true")
+	}
+
+	// Agents that handle throttle messages slow down their events while the
+	// backend is saturated
+	throttle := a.throttle.enabled() && r.Header.Get(transport.HeaderKeyThrottle) == "true"
+	if throttle {
+		responseHeader.Set(transport.HeaderKeyThrottle, "true")
+	}
+
+	// Agents that handle event rejection messages are told why their events
+	// are rejected
+	eventRejections := a.maxEventSize > 0 && r.Header.Get(transport.HeaderKeyEventRejections) == "true"
+	if eventRejections {
+		responseHeader.Set(transport.HeaderKeyEventRejections, "true")
+	}
+
+	// Agents that offer compression algorithms get their messages compressed
+	// with the first one that is accepted
+	compression := transport.NegotiateCompression(r.Header.Get(transport.HeaderKeyCompression), a.compression)
+	if compression != "" {
+		responseHeader.Set(transport.HeaderKeyCompression, compression)
+	}
+
+	// The session limits keep the agents of a namespace from starving the
+	// agents of the other namespaces. The refused agents are asked to
+	// connect again later.
+	release, limit := a.sessions.acquire(found)
+	if limit != "" {
+		lager.WithField("limit", limit).Warn("rejecting agent connection, the agent session limit is reached")
+		w.Header().Set("Retry-After", strconv.Itoa(sessionRetryAfter()))
+		http.Error(w, fmt.Sprintf("the %s agent session limit is reached", limit), http.StatusTooManyRequests)
 		return
 	}
 
+	// The client certificate of the agent is monitored until its session
+	// ends
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		forget := a.certMonitor.WatchAgent(namespace, r.Header.Get(transport.HeaderKeyAgentName), r.TLS.PeerCertificates[0])
+		releaseSession := release
+		release = func() {
+			forget()
+			releaseSession()
+		}
+	}
+
 	cfg := SessionConfig{
 		AgentAddr:     r.RemoteAddr,
 		AgentName:     r.Header.Get(transport.HeaderKeyAgentName),
 		Namespace:     r.Header.Get(transport.HeaderKeyNamespace),
 		User:          r.Header.Get(transport.HeaderKeyUser),
-		Subscriptions: strings.Split(r.Header.Get(transport.HeaderKeySubscriptions), ","),
+		Subscriptions: subscriptions,
+		AgentVersion:  agentVersion,
 		RingPool:      a.ringPool,
 		ContentType:   contentType,
 		WriteTimeout:  a.writeTimeout,
 		Bus:           a.bus,
-		Conn:          transport.NewTransport(conn),
 		Storev2:       a.store,
 		Marshal:       marshal,
 		Unmarshal:     unmarshal,
+
+		EventRateLimits:    a.eventLimits,
+		EventAcks:          eventAcks,
+		Throttle:           throttle,
+		CheckChannelSize:   a.checkChannel,
+		DropCheckRequests:  a.dropChecks,
+		ClockSkewThreshold: a.clockSkew,
+		MaxEventSize:       a.maxEventSize,
+		EventRejections:    eventRejections,
+		PingInterval:       a.pingInterval,
+		ProtocolVersion:    protocolVersion,
+		namespaceLimiters:  a.nsLimiters,
+		deadLetters:        a.deadLetters,
+		configAudit:        a.configAudit,
+		selfMonitor:        a.selfMonitor,
+		release:            release,
 	}
 
 	cfg.Subscriptions = corev2.AddEntitySubscription(cfg.AgentName, cfg.Subscriptions)
+	if admission.Subscriptions != nil {
+		// The keepalives of the agent must not bring the subscriptions
+		// back
+		cfg.admittedSubscriptions = cfg.Subscriptions
+	}
 
+	// The agents that connect over gRPC have their session in the stream of
+	// their request, which is served until the session ends
+	if transport.IsGRPCRequest(r) && a.grpc != nil {
+		err := a.grpc.Serve(w, r, responseHeader, compression, func(conn transport.Transport) {
+			if t, ok := conn.(*transport.GRPCTransport); ok {
+				t.SetMaxMessageSize(a.maxEventSize)
+			}
+			cfg.Conn = conn
+			a.startSession(lager, cfg)
+		})
+		if err != nil {
+			release()
+			lager.WithError(err).Error("transport error on gRPC stream")
+		}
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, responseHeader)
+	if err != nil {
+		release()
+		lager.WithError(err).Error("transport error on websocket upgrade")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	cfg.Conn, err = transport.NewCompressedTransport(conn, compression)
+	if err != nil {
+		release()
+		lager.WithError(err).Error("could not create the session transport")
+		_ = conn.Close()
+		return
+	}
+	// Drop the messages larger than the maximum event size, and don't
+	// decompress them beyond it
+	if t, ok := cfg.Conn.(*transport.WebSocketTransport); ok {
+		t.SetMaxMessageSize(a.maxEventSize)
+	}
+	a.startSession(lager, cfg)
+}
+
+// startSession starts the agent session of cfg, whose connection is
+// established. The connection is closed if the session can't be created.
+func (a *Agentd) startSession(lager *logrus.Entry, cfg SessionConfig) {
 	session, err := NewSession(a.ctx, cfg)
 	if err != nil {
+		cfg.release()
 		lager.WithError(err).Error("failed to create session")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		_ = cfg.Conn.Close()
 		// There was an error retrieving the namespace from
 		// etcd, indicating that this backend has a potentially
 		// unrecoverable issue.
@@ -398,6 +853,37 @@ func (a *Agentd) webSocketHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// checkAgentVersion returns an error if the agent must be rejected because
+// its version is outside the supported version skew. Agents outside of it are
+// only logged about, unless the cluster rejects them. Agents of namespaces
+// with the AllowAgentVersionSkewAnnotation are never checked.
+func (a *Agentd) checkAgentVersion(ctx context.Context, namespace *corev3.Namespace, agentVersion string) error {
+	if namespace.Metadata != nil && namespace.Metadata.Annotations[AllowAgentVersionSkewAnnotation] == "true" {
+		return nil
+	}
+	if agentVersion == "" {
+		// Agents older than the version check don't send their version
+		return nil
+	}
+	policy := a.versionPolicies.Get(ctx)
+	if policy.action == AgentVersionPolicyOff {
+		return nil
+	}
+	err := checkAgentVersion(a.backendVersion, agentVersion, policy.skew)
+	if err == nil {
+		return nil
+	}
+	if policy.action == AgentVersionPolicyReject {
+		return err
+	}
+	logger.WithFields(logrus.Fields{
+		"namespace":       namespace.Metadata.Name,
+		"agent_version":   agentVersion,
+		"backend_version": a.backendVersion,
+	}).WithError(err).Warning("agent version is outside the supported version skew")
+	return nil
+}
+
 // AuthenticationMiddleware represents the core authentication middleware for
 // agentd, which consists of basic authentication.
 func (a *Agentd) AuthenticationMiddleware(next http.Handler) http.Handler {
@@ -0,0 +1,194 @@
+package agentd
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultTLSReloadInterval is the default interval at which the TLS
+	// files of agentd are checked for changes.
+	DefaultTLSReloadInterval = 30 * time.Second
+
+	tlsReloadsName = "sensu_go_agentd_tls_reloads_total"
+
+	tlsReloadSucceeded = "succeeded"
+	tlsReloadFailed    = "failed"
+)
+
+var tlsReloads = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: tlsReloadsName,
+		Help: "The total number of reloads of the TLS certificate of agentd, by outcome",
+	},
+	[]string{"outcome"},
+)
+
+// fileVersion identifies the content of a file, by its modification time
+// and size.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// certReloader serves the TLS configuration of agentd, reloaded from the
+// certificate, key and CA files of its TLS options when they change or when
+// the backend receives a SIGHUP. The new configuration only applies to the
+// new connections: the established sessions are kept.
+type certReloader struct {
+	opts     *corev2.TLSOptions
+	interval time.Duration
+	config   atomic.Value
+
+	mu       sync.Mutex
+	versions map[string]fileVersion
+	sighup   chan interface{}
+}
+
+// newCertReloader returns a certReloader of opts, which checks its files for
+// changes every interval once it runs, or only reloads them on SIGHUP if
+// interval is zero. It fails if the files can't be loaded.
+func newCertReloader(opts *corev2.TLSOptions, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{
+		opts:     opts,
+		interval: interval,
+		sighup:   make(chan interface{}, 1),
+	}
+	r.versions = r.fileVersions()
+	config, err := opts.ToServerTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	r.config.Store(config)
+	return r, nil
+}
+
+// Receiver implements messaging.Subscriber, to receive the SIGHUP signals.
+func (r *certReloader) Receiver() chan<- interface{} {
+	return r.sighup
+}
+
+// serverConfig returns the TLS configuration of an agentd server, which
+// serves the last configuration loaded by r, with the application protocols
+// of nextProtos if any.
+func (r *certReloader) serverConfig(nextProtos ...string) *tls.Config {
+	current := func() *tls.Config {
+		config := r.config.Load().(*tls.Config)
+		if len(nextProtos) > 0 {
+			config = config.Clone()
+			config.NextProtos = nextProtos
+		}
+		return config
+	}
+	// GetCertificate is only called if GetConfigForClient returns no
+	// configuration, but tells the HTTP server the configuration has a
+	// certificate
+	return &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if config := current(); len(config.Certificates) > 0 {
+				return &config.Certificates[0], nil
+			}
+			return nil, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return current(), nil
+		},
+	}
+}
+
+// fileVersions returns the versions of the TLS files. The files that can't
+// be read have no version.
+func (r *certReloader) fileVersions() map[string]fileVersion {
+	versions := map[string]fileVersion{}
+	for _, path := range []string{r.opts.GetCertFile(), r.opts.GetKeyFile(), r.opts.GetTrustedCAFile()} {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			versions[path] = fileVersion{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return versions
+}
+
+// changed returns true if a TLS file changed since it was last checked.
+func (r *certReloader) changed() bool {
+	versions := r.fileVersions()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed := len(versions) != len(r.versions)
+	for path, version := range versions {
+		if r.versions[path] != version {
+			changed = true
+		}
+	}
+	r.versions = versions
+	return changed
+}
+
+// reload loads the TLS files again. The previous configuration is kept if
+// they can't be loaded, such as while they are being rotated.
+func (r *certReloader) reload() error {
+	config, err := r.opts.ToServerTLSConfig()
+	if err != nil {
+		tlsReloads.WithLabelValues(tlsReloadFailed).Inc()
+		return err
+	}
+	r.config.Store(config)
+	tlsReloads.WithLabelValues(tlsReloadSucceeded).Inc()
+	return nil
+}
+
+// run reloads the TLS files when they change or on SIGHUP, until ctx is
+// done. A nil certReloader does nothing.
+func (r *certReloader) run(ctx context.Context, bus messaging.MessageBus) {
+	if r == nil {
+		return
+	}
+	subscription, err := messaging.Supervise(ctx, bus, messaging.SignalTopic(syscall.SIGHUP), "agentd-tls", r)
+	if err != nil {
+		logger.WithError(err).Error("could not subscribe to SIGHUP, the agentd TLS certificate is only reloaded when its files change")
+	} else {
+		defer func() {
+			_ = subscription.Cancel()
+		}()
+	}
+	var tick <-chan time.Time
+	if r.interval > 0 {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	lager := logger.WithFields(logrus.Fields{
+		"cert_file":       r.opts.GetCertFile(),
+		"key_file":        r.opts.GetKeyFile(),
+		"trusted_ca_file": r.opts.GetTrustedCAFile(),
+	})
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			if !r.changed() {
+				continue
+			}
+		case <-r.sighup:
+			r.changed()
+		}
+		if err := r.reload(); err != nil {
+			lager.WithError(err).Error("could not reload the agentd TLS certificate, keeping the current one")
+			continue
+		}
+		lager.Info("reloaded the agentd TLS certificate")
+	}
+}
@@ -0,0 +1,84 @@
+package agentd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes a self-signed certificate of commonName, and its key, to
+// certFile and keyFile.
+func writeCert(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+}
+
+func servedCommonName(t *testing.T, r *certReloader) string {
+	t.Helper()
+	config, err := r.serverConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Len(t, config.Certificates, 1)
+	cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	return cert.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, "before")
+
+	r, err := newCertReloader(&corev2.TLSOptions{CertFile: certFile, KeyFile: keyFile}, time.Second)
+	require.NoError(t, err)
+	assert.False(t, r.changed())
+	assert.Equal(t, "before", servedCommonName(t, r))
+
+	// The certificate is rotated
+	writeCert(t, certFile, keyFile, "after")
+	require.NoError(t, os.Chtimes(certFile, time.Now(), time.Now().Add(time.Minute)))
+	assert.True(t, r.changed())
+	assert.False(t, r.changed())
+	require.NoError(t, r.reload())
+	assert.Equal(t, "after", servedCommonName(t, r))
+
+	// An invalid certificate keeps the current one
+	require.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0600))
+	assert.Error(t, r.reload())
+	assert.Equal(t, "after", servedCommonName(t, r))
+}
+
+func TestNewCertReloaderInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	_, err := newCertReloader(&corev2.TLSOptions{
+		CertFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:  filepath.Join(dir, "key.pem"),
+	}, time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package agentd
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultCheckChannelSize is the default number of check requests
+	// buffered for each agent session.
+	DefaultCheckChannelSize = 100
+
+	checkChannelDepthName    = "sensu_go_agentd_check_channel_depth"
+	checkRequestsBlockedName = "sensu_go_agentd_check_requests_blocked_total"
+	checkRequestsDroppedName = "sensu_go_agentd_check_requests_dropped_total"
+)
+
+var (
+	checkChannelDepthDesc = prometheus.NewDesc(
+		checkChannelDepthName,
+		"Number of check requests waiting to be sent to the agents of a namespace connected to the backend",
+		[]string{"namespace"}, nil,
+	)
+
+	checkRequestsBlocked = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: checkRequestsBlockedName,
+			Help: "The total number of check requests that blocked the message bus because the check channel of their agent session was full",
+		},
+		[]string{"namespace"},
+	)
+
+	checkRequestsDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: checkRequestsDroppedName,
+			Help: "The total number of check requests dropped because the check channel of their agent session was full",
+		},
+		[]string{"namespace"},
+	)
+)
+
+// checkChannelCollector collects the depths of the check channels of the
+// sessions of this backend, per namespace.
+type checkChannelCollector struct{}
+
+// Describe implements prometheus.Collector.
+func (checkChannelCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- checkChannelDepthDesc
+}
+
+// Collect implements prometheus.Collector.
+func (checkChannelCollector) Collect(ch chan<- prometheus.Metric) {
+	for namespace, depth := range checkChannelDepths() {
+		ch <- prometheus.MustNewConstMetric(checkChannelDepthDesc, prometheus.GaugeValue, float64(depth), namespace)
+	}
+}
+
+// checkChannelDepths returns the number of check requests waiting in the
+// check channels of the sessions of this backend, per namespace.
+func checkChannelDepths() map[string]int {
+	depths := make(map[string]int)
+	sessionQueues.Range(func(key, _ interface{}) bool {
+		s := key.(*Session)
+		depths[s.cfg.Namespace] += len(s.checkChannel)
+		return true
+	})
+	return depths
+}
+
+// Overflow handles the check requests sent to the session while its check
+// channel is full: they block the message bus until the channel has room, or
+// they are dropped if the session drops check requests.
+func (s *Session) Overflow(message interface{}) bool {
+	if s.cfg.DropCheckRequests {
+		checkRequestsDropped.WithLabelValues(s.cfg.Namespace).Inc()
+		logger.WithField("agent", s.cfg.AgentName).WithField("namespace", s.cfg.Namespace).Warn("check channel full, dropping check request")
+		return false
+	}
+	checkRequestsBlocked.WithLabelValues(s.cfg.Namespace).Inc()
+	return true
+}
@@ -0,0 +1,48 @@
+package agentd
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSessionOverflow(t *testing.T) {
+	blocking := &Session{cfg: SessionConfig{Namespace: "overflow-block"}}
+	if !blocking.Overflow(nil) {
+		t.Error("expected the check request to block")
+	}
+	if got := testutil.ToFloat64(checkRequestsBlocked.WithLabelValues("overflow-block")); got != 1 {
+		t.Errorf("got %v blocked check requests, want 1", got)
+	}
+
+	dropping := &Session{cfg: SessionConfig{Namespace: "overflow-drop", DropCheckRequests: true}}
+	if dropping.Overflow(nil) {
+		t.Error("expected the check request to be dropped")
+	}
+	if got := testutil.ToFloat64(checkRequestsDropped.WithLabelValues("overflow-drop")); got != 1 {
+		t.Errorf("got %v dropped check requests, want 1", got)
+	}
+}
+
+func TestCheckChannelDepths(t *testing.T) {
+	sessions := []*Session{
+		{cfg: SessionConfig{Namespace: "a"}, checkChannel: make(chan interface{}, 10)},
+		{cfg: SessionConfig{Namespace: "a"}, checkChannel: make(chan interface{}, 10)},
+		{cfg: SessionConfig{Namespace: "b"}, checkChannel: make(chan interface{}, 10)},
+	}
+	sessions[0].checkChannel <- nil
+	sessions[1].checkChannel <- nil
+	sessions[1].checkChannel <- nil
+	for _, s := range sessions {
+		sessionQueues.Store(s, struct{}{})
+		defer sessionQueues.Delete(s)
+	}
+
+	depths := checkChannelDepths()
+	if got, want := depths["a"], 3; got != want {
+		t.Errorf("got depth %d for namespace a, want %d", got, want)
+	}
+	if got, want := depths["b"], 0; got != want {
+		t.Errorf("got depth %d for namespace b, want %d", got, want)
+	}
+}
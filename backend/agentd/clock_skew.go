@@ -0,0 +1,112 @@
+package agentd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/agent"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ClockSkewCheckName is the name of the check of the events that report
+	// the entities whose clock is skewed from the clock of the backend.
+	ClockSkewCheckName = "clock-skew"
+
+	// DefaultClockSkewThreshold is the default clock skew from which the
+	// entities are reported.
+	DefaultClockSkewThreshold = 30 * time.Second
+)
+
+// clockSkewState is what a session knows of the clock skew of its entity.
+type clockSkewState struct {
+	checked bool
+	skewed  bool
+}
+
+// checkClockSkew compares the timestamp of keepalive with the clock of the
+// backend, and publishes a warning event for the entity while its clock is
+// skewed by more than the clock skew threshold, since the skew breaks the
+// expirations of its check TTLs and silences. The event is resolved once the
+// clock is no longer skewed.
+func (s *Session) checkClockSkew(keepalive *corev2.Event) {
+	threshold := s.cfg.ClockSkewThreshold
+	if threshold <= 0 || keepalive.Timestamp == 0 {
+		return
+	}
+	now := time.Now()
+	skew := now.Sub(time.Unix(keepalive.Timestamp, 0)).Truncate(time.Second)
+	skewed := skew > threshold || skew < -threshold
+
+	previous := s.clockSkew
+	s.clockSkew = clockSkewState{checked: true, skewed: skewed}
+	if !skewed {
+		if previous.skewed || !previous.checked && s.clockSkewReported(keepalive.Entity) {
+			s.publishClockSkew(keepalive, now, 0, "the clock of the entity is in sync with the backend")
+		}
+		return
+	}
+
+	var output string
+	if skew > 0 {
+		output = fmt.Sprintf("the clock of the entity is %s behind the backend", skew)
+	} else {
+		output = fmt.Sprintf("the clock of the entity is %s ahead of the backend", -skew)
+	}
+	if !previous.skewed {
+		logger.WithFields(logrus.Fields{
+			"agent":     s.cfg.AgentName,
+			"namespace": s.cfg.Namespace,
+			"skew":      skew,
+		}).Warn("agent clock is skewed")
+	}
+	s.publishClockSkew(keepalive, now, 1, output)
+}
+
+// clockSkewReported returns true if the clock skew of entity is reported by a
+// warning event, from a previous session.
+func (s *Session) clockSkewReported(entity *corev2.Entity) bool {
+	ctx := context.WithValue(s.ctx, corev2.NamespaceKey, entity.Namespace)
+	event, err := s.storev2.GetEventStore().GetEventByEntityCheck(ctx, entity.Name, ClockSkewCheckName)
+	if err != nil {
+		logger.WithError(err).Warn("could not get the clock skew event of the entity")
+		return false
+	}
+	return event != nil && event.Check != nil && event.Check.Status != 0
+}
+
+// publishClockSkew publishes the clock skew event of the entity of keepalive.
+func (s *Session) publishClockSkew(keepalive *corev2.Event, now time.Time, status uint32, output string) {
+	interval := uint32(agent.DefaultKeepaliveInterval)
+	if keepalive.Check != nil && keepalive.Check.Interval > 0 {
+		interval = keepalive.Check.Interval
+	}
+	event := &corev2.Event{
+		ObjectMeta: corev2.ObjectMeta{
+			Namespace: keepalive.Entity.Namespace,
+		},
+		Timestamp: now.Unix(),
+		Entity:    keepalive.Entity,
+		Check: &corev2.Check{
+			ObjectMeta: corev2.ObjectMeta{
+				Name:      ClockSkewCheckName,
+				Namespace: keepalive.Entity.Namespace,
+			},
+			Interval:  interval,
+			Status:    status,
+			Output:    output,
+			Executed:  now.Unix(),
+			Issued:    now.Unix(),
+			Scheduler: corev2.EtcdScheduler,
+		},
+	}
+	uid, _ := uuid.NewRandom()
+	event.ID = uid[:]
+	if err := s.bus.Publish(messaging.TopicEventRaw, event); err != nil {
+		logger.WithError(err).Error("could not publish the clock skew event of the entity")
+	}
+}
@@ -0,0 +1,86 @@
+package agentd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/testing/mockbus"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSessionCheckClockSkew(t *testing.T) {
+	bus := &mockbus.MockBus{}
+	var published []*corev2.Event
+	bus.On("Publish", messaging.TopicEventRaw, mock.Anything).Run(func(args mock.Arguments) {
+		published = append(published, args.Get(1).(*corev2.Event))
+	}).Return(nil)
+	events := &mockstore.MockStore{}
+	events.On("GetEventByEntityCheck", mock.Anything, "entity", ClockSkewCheckName).Return((*corev2.Event)(nil), nil)
+	st := &mockstore.V2MockStore{}
+	st.On("GetEventStore").Return(events)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &Session{
+		cfg:     SessionConfig{Namespace: "default", ClockSkewThreshold: 30 * time.Second},
+		bus:     bus,
+		storev2: st,
+		ctx:     ctx,
+	}
+	keepalive := func(skew time.Duration) *corev2.Event {
+		event := corev2.FixtureEvent("entity", corev2.KeepaliveCheckName)
+		event.Timestamp = time.Now().Add(-skew).Unix()
+		return event
+	}
+
+	// the clock of the entity is in sync, and was not reported before
+	s.checkClockSkew(keepalive(time.Second))
+	if len(published) != 0 {
+		t.Fatalf("unexpected clock skew event: %v", published)
+	}
+
+	s.checkClockSkew(keepalive(-time.Minute))
+	if len(published) != 1 || published[0].Check.Status != 1 || published[0].Check.Name != ClockSkewCheckName {
+		t.Fatalf("expected a clock skew warning, got %v", published)
+	}
+
+	// the warning is resolved once the clock is back in sync
+	s.checkClockSkew(keepalive(0))
+	if len(published) != 2 || published[1].Check.Status != 0 {
+		t.Fatalf("expected the clock skew warning to be resolved, got %v", published)
+	}
+	s.checkClockSkew(keepalive(0))
+	if len(published) != 2 {
+		t.Fatalf("unexpected clock skew event: %v", published[2:])
+	}
+}
+
+func TestSessionCheckClockSkewResolvesPreviousSession(t *testing.T) {
+	bus := &mockbus.MockBus{}
+	bus.On("Publish", messaging.TopicEventRaw, mock.MatchedBy(func(event *corev2.Event) bool {
+		return event.Check.Name == ClockSkewCheckName && event.Check.Status == 0
+	})).Return(nil)
+	previous := corev2.FixtureEvent("entity", ClockSkewCheckName)
+	previous.Check.Status = 1
+	events := &mockstore.MockStore{}
+	events.On("GetEventByEntityCheck", mock.Anything, "entity", ClockSkewCheckName).Return(previous, nil)
+	st := &mockstore.V2MockStore{}
+	st.On("GetEventStore").Return(events)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &Session{
+		cfg:     SessionConfig{Namespace: "default", ClockSkewThreshold: 30 * time.Second},
+		bus:     bus,
+		storev2: st,
+		ctx:     ctx,
+	}
+	keepalive := corev2.FixtureEvent("entity", corev2.KeepaliveCheckName)
+	keepalive.Timestamp = time.Now().Unix()
+	s.checkClockSkew(keepalive)
+	bus.AssertNumberOfCalls(t, "Publish", 1)
+}
@@ -0,0 +1,80 @@
+package agentd
+
+import (
+	"context"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// configAuditTimeout is the timeout of the writes of the config audit log.
+const configAuditTimeout = 5 * time.Second
+
+// ConfigAuditLog records the entity config updates the sessions send to
+// their agent, so that the changes of the subscriptions of an agent can be
+// traced back to the update that made them. The records of all backends are
+// kept in the store, and are bounded: the oldest records are dropped when
+// the log is full. A nil ConfigAuditLog records nothing.
+type ConfigAuditLog struct {
+	store store.ConfigAuditStore
+	size  int
+}
+
+// NewConfigAuditLog returns a config audit log of size records, kept in s.
+// It returns nil if size is zero or lower.
+func NewConfigAuditLog(s store.ConfigAuditStore, size int) *ConfigAuditLog {
+	if size <= 0 {
+		return nil
+	}
+	return &ConfigAuditLog{
+		store: s,
+		size:  size,
+	}
+}
+
+// record records the entity config update sent to agent, with the
+// subscriptions it added and removed. The update is described by the action
+// of its watch event. The update is sent even if it can't be recorded.
+func (l *ConfigAuditLog) record(ctx context.Context, agent string, action storev2.WatchActionType, entity *corev3.EntityConfig, added, removed []string) {
+	if l == nil {
+		return
+	}
+	push := &store.AgentConfigPush{
+		Namespace:            entity.Metadata.Namespace,
+		AgentName:            agent,
+		Action:               action.String(),
+		UpdatedBy:            entity.Metadata.CreatedBy,
+		ManagedBy:            entity.Metadata.Labels[corev2.ManagedByLabel],
+		SubscriptionsAdded:   added,
+		SubscriptionsRemoved: removed,
+		SentAt:               time.Now(),
+	}
+	ctx, cancel := context.WithTimeout(ctx, configAuditTimeout)
+	defer cancel()
+	if err := l.store.RecordConfigPush(ctx, push, l.size); err != nil {
+		logger.WithError(err).WithFields(logrus.Fields{
+			"agent":     agent,
+			"namespace": push.Namespace,
+		}).Error("could not record entity config update")
+	}
+}
+
+// List returns the records of the entity config updates sent to the agents
+// of namespace, oldest first. Only the records of agent are returned unless
+// it is empty.
+func (l *ConfigAuditLog) List(ctx context.Context, namespace, agent string) ([]*store.AgentConfigPush, error) {
+	if l == nil {
+		return []*store.AgentConfigPush{}, nil
+	}
+	return l.store.ListConfigPushes(ctx, namespace, agent)
+}
+
+// ConfigPushes returns the records of the entity config updates sent to the
+// agents of namespace by all backends, or to agent only unless it is empty.
+func (s Sessions) ConfigPushes(ctx context.Context, namespace, agent string) ([]*store.AgentConfigPush, error) {
+	return s.ConfigAuditLog.List(ctx, namespace, agent)
+}
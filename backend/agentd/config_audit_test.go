@@ -0,0 +1,86 @@
+package agentd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+type testConfigAuditStore struct {
+	pushes []*store.AgentConfigPush
+}
+
+func (s *testConfigAuditStore) RecordConfigPush(ctx context.Context, push *store.AgentConfigPush, limit int) error {
+	s.pushes = append(s.pushes, push)
+	if len(s.pushes) > limit {
+		s.pushes = s.pushes[len(s.pushes)-limit:]
+	}
+	return nil
+}
+
+func (s *testConfigAuditStore) ListConfigPushes(ctx context.Context, namespace, agent string) ([]*store.AgentConfigPush, error) {
+	pushes := []*store.AgentConfigPush{}
+	for _, push := range s.pushes {
+		if push.Namespace == namespace && (agent == "" || push.AgentName == agent) {
+			pushes = append(pushes, push)
+		}
+	}
+	return pushes, nil
+}
+
+func TestConfigAuditLog(t *testing.T) {
+	ctx := context.Background()
+	log := NewConfigAuditLog(&testConfigAuditStore{}, 2)
+	entity := corev3.FixtureEntityConfig("agent1")
+	entity.Metadata.CreatedBy = "admin"
+	log.record(ctx, "agent1", storev2.WatchCreate, entity, []string{"linux"}, nil)
+	log.record(ctx, "agent1", storev2.WatchUpdate, entity, []string{"web"}, []string{"linux"})
+
+	entity = corev3.FixtureEntityConfig("agent2")
+	entity.Metadata.Labels = map[string]string{corev2.ManagedByLabel: "sensu-agent"}
+	log.record(ctx, "agent2", storev2.WatchUpdate, entity, nil, []string{"web"})
+
+	// The oldest record was dropped
+	pushes, err := log.List(ctx, "default", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pushes) != 2 {
+		t.Fatalf("got %d records, want 2", len(pushes))
+	}
+	if got := pushes[0]; got.AgentName != "agent1" || got.Action != "Update" || got.UpdatedBy != "admin" ||
+		!reflect.DeepEqual(got.SubscriptionsAdded, []string{"web"}) || !reflect.DeepEqual(got.SubscriptionsRemoved, []string{"linux"}) {
+		t.Errorf("bad record: %+v", got)
+	}
+	if got := pushes[1]; got.AgentName != "agent2" || got.ManagedBy != "sensu-agent" {
+		t.Errorf("bad record: %+v", got)
+	}
+
+	if pushes, _ := log.List(ctx, "default", "agent2"); len(pushes) != 1 || pushes[0].AgentName != "agent2" {
+		t.Errorf("records not filtered by agent: %+v", pushes)
+	}
+	if pushes, _ := log.List(ctx, "acme", ""); len(pushes) != 0 {
+		t.Errorf("records not filtered by namespace: %+v", pushes)
+	}
+}
+
+func TestConfigAuditLogDisabled(t *testing.T) {
+	ctx := context.Background()
+	s := &testConfigAuditStore{}
+	log := NewConfigAuditLog(s, 0)
+	if log != nil {
+		t.Fatal("expected a nil config audit log")
+	}
+	log.record(ctx, "agent1", storev2.WatchUpdate, corev3.FixtureEntityConfig("agent1"), []string{"linux"}, nil)
+	if pushes, err := log.List(ctx, "default", ""); err != nil || len(pushes) != 0 {
+		t.Errorf("got %d records, want none", len(pushes))
+	}
+	if len(s.pushes) != 0 {
+		t.Errorf("got %d stored records, want none", len(s.pushes))
+	}
+}
@@ -0,0 +1,248 @@
+package agentd
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/routers"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultDeadLetterQueueSize is the default number of agent events held
+	// by the dead letter queue.
+	DefaultDeadLetterQueueSize = 1000
+
+	// DefaultDeadLetterRetryInterval is the default interval at which the
+	// events of the dead letter queue are published again.
+	DefaultDeadLetterRetryInterval = 10 * time.Second
+
+	deadLettersName      = "sensu_go_agentd_dead_letters"
+	deadLetterEventsName = "sensu_go_agentd_dead_letter_events_total"
+
+	deadLetterQueued      = "queued"
+	deadLetterRepublished = "republished"
+	deadLetterDropped     = "dropped"
+	deadLetterDiscarded   = "discarded"
+)
+
+var (
+	deadLettersGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: deadLettersName,
+			Help: "The number of agent events held by the dead letter queue",
+		},
+	)
+
+	deadLetterEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: deadLetterEventsName,
+			Help: "The total number of agent events of the dead letter queue, by outcome",
+		},
+		[]string{"outcome"},
+	)
+)
+
+// deadLetter is an agent event that could not be published.
+type deadLetter struct {
+	topic    string
+	event    *corev2.Event
+	agent    string
+	err      string
+	attempts int
+	failedAt time.Time
+
+	// ack acknowledges the event to the agent once it is published
+	ack func()
+}
+
+// DeadLetterQueue holds the agent events that could not be published to the
+// message bus, so that they are published again once the bus recovers,
+// instead of being lost. The events are only acknowledged to the agents once
+// they are published, so the agents send the events the queue drops again.
+// The queue is bounded: its oldest events are dropped when it is full. A nil
+// DeadLetterQueue holds no event.
+type DeadLetterQueue struct {
+	bus      messaging.MessageBus
+	size     int
+	interval time.Duration
+
+	// retryMu serializes the retries, so that an event isn't published twice
+	retryMu sync.Mutex
+
+	mu      sync.Mutex
+	letters *list.List
+}
+
+// NewDeadLetterQueue returns a dead letter queue of size events, that
+// publishes its events to bus again every interval, once it runs. It returns
+// nil if size is zero or lower.
+func NewDeadLetterQueue(bus messaging.MessageBus, size int, interval time.Duration) *DeadLetterQueue {
+	if size <= 0 {
+		return nil
+	}
+	return &DeadLetterQueue{
+		bus:      bus,
+		size:     size,
+		interval: interval,
+		letters:  list.New(),
+	}
+}
+
+// add queues event, which could not be published to topic because of err.
+// ack is called once the event is published, it may be nil. add returns false
+// if the queue is nil.
+func (q *DeadLetterQueue) add(topic string, event *corev2.Event, agent string, err error, ack func()) bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.letters.Len() >= q.size {
+		q.letters.Remove(q.letters.Front())
+		deadLetterEvents.WithLabelValues(deadLetterDropped).Inc()
+		logger.Warn("dead letter queue is full, dropping its oldest event")
+	}
+	q.letters.PushBack(&deadLetter{
+		topic:    topic,
+		event:    event,
+		agent:    agent,
+		err:      err.Error(),
+		attempts: 1,
+		failedAt: time.Now(),
+		ack:      ack,
+	})
+	deadLetterEvents.WithLabelValues(deadLetterQueued).Inc()
+	deadLettersGauge.Set(float64(q.letters.Len()))
+	return true
+}
+
+// Retry publishes the events of the queue again, oldest first. It stops at
+// the first event that can't be published, since the bus is most likely
+// still failing. The events are published without holding the lock of the
+// queue, so that the sessions can queue events meanwhile. It returns the
+// number of events published, and the number of events left in the queue.
+func (q *DeadLetterQueue) Retry() (republished, remaining int) {
+	if q == nil {
+		return 0, 0
+	}
+	q.retryMu.Lock()
+	defer q.retryMu.Unlock()
+
+	q.mu.Lock()
+	elements := make([]*list.Element, 0, q.letters.Len())
+	for e := q.letters.Front(); e != nil; e = e.Next() {
+		elements = append(elements, e)
+	}
+	q.mu.Unlock()
+
+	for _, e := range elements {
+		letter := e.Value.(*deadLetter)
+		err := q.bus.Publish(letter.topic, letter.event)
+		q.mu.Lock()
+		if err != nil {
+			letter.attempts++
+			letter.err = err.Error()
+			q.mu.Unlock()
+			break
+		}
+		// The event may have been dropped meanwhile, Remove is a no-op then
+		q.letters.Remove(e)
+		q.mu.Unlock()
+		if letter.ack != nil {
+			letter.ack()
+		}
+		republished++
+		deadLetterEvents.WithLabelValues(deadLetterRepublished).Inc()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	deadLettersGauge.Set(float64(q.letters.Len()))
+	return republished, q.letters.Len()
+}
+
+// Discard removes all the events of the queue, and returns their number.
+func (q *DeadLetterQueue) Discard() int {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	discarded := q.letters.Len()
+	q.letters.Init()
+	deadLetterEvents.WithLabelValues(deadLetterDiscarded).Add(float64(discarded))
+	deadLettersGauge.Set(0)
+	return discarded
+}
+
+// List returns the description of the events of the queue, oldest first.
+func (q *DeadLetterQueue) List() []routers.AgentDeadLetter {
+	letters := []routers.AgentDeadLetter{}
+	if q == nil {
+		return letters
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for e := q.letters.Front(); e != nil; e = e.Next() {
+		letter := e.Value.(*deadLetter)
+		letters = append(letters, routers.AgentDeadLetter{
+			Namespace: letter.event.Entity.Namespace,
+			AgentName: letter.agent,
+			Topic:     letter.topic,
+			Error:     letter.err,
+			Attempts:  letter.attempts,
+			FailedAt:  letter.failedAt,
+			Event:     letter.event,
+		})
+	}
+	return letters
+}
+
+// run publishes the events of the queue again every interval of the queue,
+// until ctx is canceled. The events are only published again through the API
+// if the interval is zero.
+func (q *DeadLetterQueue) run(ctx context.Context) {
+	if q == nil || q.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			republished, remaining := q.Retry()
+			if republished > 0 || remaining > 0 {
+				logger.WithFields(logrus.Fields{
+					"republished": republished,
+					"remaining":   remaining,
+				}).Info("published the events of the dead letter queue again")
+			}
+		}
+	}
+}
+
+// DeadLetters returns the events of the dead letter queue of this backend.
+func (s Sessions) DeadLetters() []routers.AgentDeadLetter {
+	return s.DeadLetterQueue.List()
+}
+
+// RetryDeadLetters publishes the events of the dead letter queue of this
+// backend again.
+func (s Sessions) RetryDeadLetters() routers.AgentDeadLettersRetry {
+	republished, remaining := s.DeadLetterQueue.Retry()
+	return routers.AgentDeadLettersRetry{Republished: republished, Remaining: remaining}
+}
+
+// DiscardDeadLetters discards the events of the dead letter queue of this
+// backend.
+func (s Sessions) DiscardDeadLetters() int {
+	return s.DeadLetterQueue.Discard()
+}
@@ -0,0 +1,125 @@
+package agentd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/testing/mockbus"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeadLetterQueue(t *testing.T) {
+	bus := &mockbus.MockBus{}
+	bus.On("Publish", messaging.TopicEventRaw, mock.MatchedBy(func(event *corev2.Event) bool {
+		return event.Check.Name == "check2"
+	})).Return(errors.New("bus is down")).Once()
+	bus.On("Publish", messaging.TopicEventRaw, mock.Anything).Return(nil)
+
+	q := NewDeadLetterQueue(bus, 3, 0)
+	dropped := testutil.ToFloat64(deadLetterEvents.WithLabelValues(deadLetterDropped))
+	for _, check := range []string{"check0", "check1", "check2", "check3"} {
+		if !q.add(messaging.TopicEventRaw, corev2.FixtureEvent("entity", check), "agent", errors.New("bus is down"), nil) {
+			t.Fatal("event not queued")
+		}
+	}
+	if got, want := testutil.ToFloat64(deadLetterEvents.WithLabelValues(deadLetterDropped))-dropped, 1.0; got != want {
+		t.Errorf("got %v dropped events, want %v", got, want)
+	}
+	letters := q.List()
+	if len(letters) != 3 || letters[0].Event.Check.Name != "check1" {
+		t.Fatalf("the oldest event was not dropped: %v", letters)
+	}
+	if letters[0].AgentName != "agent" || letters[0].Namespace != "default" || letters[0].Attempts != 1 {
+		t.Errorf("bad dead letter: %+v", letters[0])
+	}
+
+	// The retry stops at the first event that can't be published
+	republished, remaining := q.Retry()
+	if republished != 1 || remaining != 2 {
+		t.Fatalf("got %d republished and %d remaining events, want 1 and 2", republished, remaining)
+	}
+	letters = q.List()
+	if letters[0].Event.Check.Name != "check2" || letters[0].Attempts != 2 {
+		t.Errorf("bad dead letter after the retry: %+v", letters[0])
+	}
+
+	republished, remaining = q.Retry()
+	if republished != 2 || remaining != 0 {
+		t.Fatalf("got %d republished and %d remaining events, want 2 and 0", republished, remaining)
+	}
+	if got := testutil.ToFloat64(deadLettersGauge); got != 0 {
+		t.Errorf("got %v dead letters, want 0", got)
+	}
+}
+
+func TestDeadLetterQueueDiscard(t *testing.T) {
+	q := NewDeadLetterQueue(&mockbus.MockBus{}, 10, 0)
+	for _, check := range []string{"check1", "check2"} {
+		q.add(messaging.TopicEventRaw, corev2.FixtureEvent("entity", check), "agent", errors.New("bus is down"))
+	}
+	if got, want := q.Discard(), 2; got != want {
+		t.Errorf("got %d discarded events, want %d", got, want)
+	}
+	if letters := q.List(); len(letters) != 0 {
+		t.Errorf("events not discarded: %v", letters)
+	}
+}
+
+func TestDeadLetterQueueDisabled(t *testing.T) {
+	q := NewDeadLetterQueue(&mockbus.MockBus{}, 0, 0)
+	if q != nil {
+		t.Fatal("expected no dead letter queue")
+	}
+	if q.add(messaging.TopicEventRaw, corev2.FixtureEvent("entity", "check"), "agent", errors.New("bus is down")) {
+		t.Error("event queued without a dead letter queue")
+	}
+	if letters := q.List(); letters == nil || len(letters) != 0 {
+		t.Errorf("bad dead letters: %v", letters)
+	}
+}
+
+func TestSessionPublishEventDeadLetter(t *testing.T) {
+	bus := &mockbus.MockBus{}
+	bus.On("Publish", messaging.TopicEventRaw, mock.Anything).Return(errors.New("bus is down"))
+	event := corev2.FixtureEvent("entity", "check")
+	id := uuid.New()
+	event.ID = id[:]
+	s := &Session{
+		cfg: SessionConfig{
+			AgentName: "entity",
+			Namespace: "default",
+			EventAcks: true,
+		},
+		bus:  bus,
+		acks: make(chan []byte, 1),
+	}
+	if err := s.publishEvent(messaging.TopicEventRaw, event); err == nil {
+		t.Fatal("expected an error without a dead letter queue")
+	}
+	if len(s.acks) != 0 {
+		t.Fatal("event acknowledged without a dead letter queue")
+	}
+
+	recovered := &mockbus.MockBus{}
+	recovered.On("Publish", messaging.TopicEventRaw, mock.Anything).Return(nil)
+	s.cfg.deadLetters = NewDeadLetterQueue(recovered, 10, 0)
+	if err := s.publishEvent(messaging.TopicEventRaw, event); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.acks) != 0 {
+		t.Fatal("dead letter acknowledged before it was published")
+	}
+	if letters := s.cfg.deadLetters.List(); len(letters) != 1 {
+		t.Fatalf("got %d dead letters, want 1", len(letters))
+	}
+	if republished, _ := s.cfg.deadLetters.Retry(); republished != 1 {
+		t.Fatalf("got %d republished events, want 1", republished)
+	}
+	if len(s.acks) != 1 {
+		t.Error("dead letter not acknowledged once published")
+	}
+}
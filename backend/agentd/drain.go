@@ -0,0 +1,100 @@
+package agentd
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultDrainWindow is the default duration over which the sessions are
+	// closed when agentd drains.
+	DefaultDrainWindow = 10 * time.Second
+
+	// maxStopDrainWindow is the maximum duration over which the sessions are
+	// closed when agentd stops, so that it stops before the backend gives up
+	// on it.
+	maxStopDrainWindow = 20 * time.Second
+
+	// flushTimeout is the maximum duration given to a session to send its
+	// pending messages before its agent is asked to close it.
+	flushTimeout = time.Second
+)
+
+// draining is set once the sessions of this backend are drained. No new
+// session is accepted while draining.
+var draining int32
+
+// isDraining returns true if the sessions of this backend are drained.
+func isDraining() bool {
+	return atomic.LoadInt32(&draining) == 1
+}
+
+// drain drains the sessions of this backend: the sessions stop receiving
+// check requests, and their agents are asked to close them once their pending
+// messages are sent, one after the other over window, so that they don't
+// all reconnect to the other backends at once. It returns false if the
+// sessions are already drained.
+func drain(ctx context.Context, window time.Duration) bool {
+	if !atomic.CompareAndSwapInt32(&draining, 0, 1) {
+		return false
+	}
+	var sessions []*Session
+	sessionQueues.Range(func(key, _ interface{}) bool {
+		sessions = append(sessions, key.(*Session))
+		return true
+	})
+	logger.WithField("sessions", len(sessions)).WithField("window", window).Warn("draining agent sessions")
+	for _, s := range sessions {
+		s.stopCheckRequests()
+	}
+	var interval time.Duration
+	if len(sessions) > 0 {
+		interval = window / time.Duration(len(sessions))
+	}
+	for i, s := range sessions {
+		if i > 0 && interval > 0 {
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return true
+			}
+		}
+		s.flush(flushTimeout)
+		s.disconnect()
+	}
+	return true
+}
+
+// Drain drains the sessions of this backend in the background, over
+// DrainWindow. The backend accepts no new session until it restarts.
+func (s Sessions) Drain() error {
+	go drain(context.Background(), s.DrainWindow)
+	return nil
+}
+
+// stopCheckRequests unsubscribes the session from its check subscriptions.
+func (s *Session) stopCheckRequests() {
+	atomic.StoreInt32(&s.draining, 1)
+	s.mu.Lock()
+	subscriptions := append([]string(nil), s.cfg.Subscriptions...)
+	s.mu.Unlock()
+	s.unsubscribe(subscriptions)
+}
+
+// flush waits for the sender to send the pending messages of the session, for
+// at most timeout.
+func (s *Session) flush(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for s.pendingMessages() > 0 && time.Now().Before(deadline) {
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Session) pendingMessages() int {
+	return len(s.checkChannel) + len(s.backoffs) + len(s.acks) + len(s.throttles) + len(s.rejections)
+}
@@ -0,0 +1,54 @@
+package agentd
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/testing/mocktransport"
+)
+
+func TestDrain(t *testing.T) {
+	defer atomic.StoreInt32(&draining, 0)
+
+	var sessions []*Session
+	for _, name := range []string{"a", "b", "c"} {
+		conn := new(mocktransport.MockTransport)
+		conn.On("SendCloseMessage").Return(nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		s := &Session{
+			cfg:    SessionConfig{AgentName: name, Namespace: "default"},
+			conn:   conn,
+			ctx:    ctx,
+			cancel: cancel,
+		}
+		sessions = append(sessions, s)
+		sessionQueues.Store(s, struct{}{})
+		defer sessionQueues.Delete(s)
+	}
+
+	start := time.Now()
+	if !drain(context.Background(), 30*time.Millisecond) {
+		t.Fatal("expected the sessions to be drained")
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("the sessions were not closed over the drain window: %s", elapsed)
+	}
+	for _, s := range sessions {
+		if atomic.LoadInt32(&s.draining) != 1 {
+			t.Errorf("session %s still receives check requests", s.cfg.AgentName)
+		}
+		if s.ctx.Err() == nil {
+			t.Errorf("session %s was not stopped", s.cfg.AgentName)
+		}
+		s.conn.(*mocktransport.MockTransport).AssertCalled(t, "SendCloseMessage")
+	}
+	if !isDraining() {
+		t.Error("expected new sessions to be refused")
+	}
+	if drain(context.Background(), 0) {
+		t.Error("expected the sessions to be drained only once")
+	}
+}
@@ -0,0 +1,34 @@
+package agentd
+
+import (
+	"context"
+
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// handleEventBatch unpacks the events of an event batch message and handles
+// them one by one, like the events sent in their own message. The events
+// that can't be handled are logged and skipped, unless the error is internal
+// to the backend.
+func (s *Session) handleEventBatch(ctx context.Context, payload []byte) error {
+	payloads, err := transport.DecodeEventBatch(payload)
+	if err != nil {
+		return err
+	}
+	for i, payload := range payloads {
+		if err := s.handleEvent(ctx, payload); err != nil {
+			if _, ok := err.(*store.ErrInternal); ok {
+				return err
+			}
+			logger.WithError(err).WithFields(logrus.Fields{
+				"agent":     s.cfg.AgentName,
+				"namespace": s.cfg.Namespace,
+				"index":     i,
+				"events":    len(payloads),
+			}).Error("error handling event of event batch")
+		}
+	}
+	return nil
+}
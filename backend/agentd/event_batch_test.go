@@ -0,0 +1,48 @@
+package agentd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/agent"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/testing/mockbus"
+	"github.com/sensu/sensu-go/transport"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSession_handleEventBatch(t *testing.T) {
+	var payloads [][]byte
+	for _, check := range []string{"check1", "check2", "check3"} {
+		payload, err := json.Marshal(corev2.FixtureEvent("entity", check))
+		if err != nil {
+			t.Fatal(err)
+		}
+		payloads = append(payloads, payload)
+	}
+	// Invalid events are skipped
+	payloads = append(payloads[:1], append([][]byte{[]byte("invalid")}, payloads[1:]...)...)
+
+	var published []string
+	bus := &mockbus.MockBus{}
+	bus.On("Publish", messaging.TopicEventRaw, mock.Anything).Run(func(args mock.Arguments) {
+		published = append(published, args.Get(1).(*corev2.Event).Check.Name)
+	}).Return(nil)
+	s := &Session{
+		cfg:       SessionConfig{AgentName: "entity", Namespace: "default"},
+		bus:       bus,
+		unmarshal: agent.UnmarshalJSON,
+	}
+	if err := s.handleEventBatch(context.Background(), transport.EncodeEventBatch(payloads)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := published, []string{"check1", "check2", "check3"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("bad published events: got %v, want %v", got, want)
+	}
+
+	if err := s.handleEventBatch(context.Background(), []byte{0x05, 'x'}); err == nil {
+		t.Error("expected an error for a truncated event batch")
+	}
+}
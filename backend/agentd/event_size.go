@@ -0,0 +1,82 @@
+package agentd
+
+import (
+	"encoding/json"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/transport"
+	"github.com/sirupsen/logrus"
+)
+
+const rejectedEventsName = "sensu_go_rejected_events"
+
+var rejectedEvents = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: rejectedEventsName,
+		Help: "The total number of agent events rejected by agentd",
+	},
+	[]string{"namespace", "reason"},
+)
+
+// allowEventSize returns false if the event payload is larger than the
+// maximum event size of the session. The rejected event is counted, and the
+// agent is told why it was rejected if it handles event rejection messages.
+// The event is rejected before it is decoded, so that oversized events don't
+// use up the memory of the backend. The websocket transports already drop the
+// events that are larger than the maximum event size on the wire, before
+// they are decompressed.
+func (s *Session) allowEventSize(payload []byte) bool {
+	if s.cfg.MaxEventSize <= 0 || len(payload) <= s.cfg.MaxEventSize {
+		return true
+	}
+	s.rejectEvent(len(payload), s.cfg.MaxEventSize)
+	return false
+}
+
+// rejectTooLarge rejects the message that the transport dropped because it
+// was too large on the wire or once decompressed. Only the events and event
+// batches are rejected, the other messages are just dropped.
+func (s *Session) rejectTooLarge(err transport.MessageTooLargeError) {
+	if err.Type != transport.MessageTypeEvent && err.Type != transport.MessageTypeEventBatch {
+		logger.WithFields(logrus.Fields{
+			"agent":    s.cfg.AgentName,
+			"type":     err.Type,
+			"max_size": err.MaxSize,
+		}).Warn("message dropped because it is too large")
+		return
+	}
+	s.rejectEvent(err.Size, err.MaxSize)
+}
+
+// rejectEvent counts the event rejected because it is too large, and tells
+// the agent why it was rejected if it handles event rejection messages. The
+// rejection message is the negative acknowledgement of the event, which can't
+// be acknowledged by its UUID since it is not decoded: the agent stops
+// redelivering its events that are larger than maxSize.
+func (s *Session) rejectEvent(size, maxSize int) {
+	rejectedEvents.WithLabelValues(s.cfg.Namespace, transport.EventRejectionTooLarge).Inc()
+	logger.WithFields(logrus.Fields{
+		"agent":     s.cfg.AgentName,
+		"namespace": s.cfg.Namespace,
+		"size":      size,
+		"max_size":  maxSize,
+	}).Warn("event rejected because it is too large")
+
+	if !s.cfg.EventRejections {
+		return
+	}
+	rejection, err := json.Marshal(transport.EventRejection{
+		Reason:  transport.EventRejectionTooLarge,
+		Size:    size,
+		MaxSize: maxSize,
+	})
+	if err != nil {
+		logger.WithError(err).Error("could not serialize event rejection message")
+		return
+	}
+	// The rejection messages are dropped if the session can't keep up
+	select {
+	case s.rejections <- rejection:
+	default:
+	}
+}
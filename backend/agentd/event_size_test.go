@@ -0,0 +1,96 @@
+package agentd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sensu/sensu-go/transport"
+)
+
+func TestSession_handleEventMaxSize(t *testing.T) {
+	tests := []struct {
+		name            string
+		namespace       string
+		maxEventSize    int
+		eventRejections bool
+		rejected        bool
+	}{
+		{
+			name:         "no limit",
+			namespace:    "size-no-limit",
+			maxEventSize: 0,
+		},
+		{
+			name:         "rejected",
+			namespace:    "size-rejected",
+			maxEventSize: 1024,
+			rejected:     true,
+		},
+		{
+			name:            "rejected with a rejection message",
+			namespace:       "size-rejection-message",
+			maxEventSize:    1024,
+			eventRejections: true,
+			rejected:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded bool
+			s := &Session{
+				cfg: SessionConfig{
+					AgentName:       "agent",
+					Namespace:       tt.namespace,
+					MaxEventSize:    tt.maxEventSize,
+					EventRejections: tt.eventRejections,
+				},
+				unmarshal: func([]byte, proto.Message) error {
+					decoded = true
+					return errors.New("decoded")
+				},
+				rejections: make(chan []byte, 1),
+			}
+			payload := bytes.Repeat([]byte("x"), 2048)
+			_ = s.handleEvent(context.Background(), payload)
+
+			if decoded == tt.rejected {
+				t.Errorf("decoded: got %v, want %v", decoded, !tt.rejected)
+			}
+			counter := rejectedEvents.WithLabelValues(tt.namespace, transport.EventRejectionTooLarge)
+			want := 0.0
+			if tt.rejected {
+				want = 1
+			}
+			if got := testutil.ToFloat64(counter); got != want {
+				t.Errorf("rejected events: got %v, want %v", got, want)
+			}
+			select {
+			case msg := <-s.rejections:
+				if !tt.eventRejections {
+					t.Fatalf("unexpected rejection message %q", msg)
+				}
+				var rejection transport.EventRejection
+				if err := json.Unmarshal(msg, &rejection); err != nil {
+					t.Fatal(err)
+				}
+				wantRejection := transport.EventRejection{
+					Reason:  transport.EventRejectionTooLarge,
+					Size:    2048,
+					MaxSize: 1024,
+				}
+				if rejection != wantRejection {
+					t.Errorf("bad rejection message: got %+v, want %+v", rejection, wantRejection)
+				}
+			default:
+				if tt.eventRejections {
+					t.Error("expected a rejection message")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package agentd
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"golang.org/x/time/rate"
+)
+
+const (
+	entityConfigUpdatesCoalescedName = "sensu_go_agentd_entity_config_updates_coalesced"
+	entityConfigUpdatesPendingName   = "sensu_go_agentd_entity_config_updates_pending"
+)
+
+var (
+	entityConfigUpdatesCoalesced = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: entityConfigUpdatesCoalescedName,
+			Help: "The total number of entity config updates replaced by a later update before being pushed to agents",
+		},
+	)
+	entityConfigUpdatesPending = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: entityConfigUpdatesPendingName,
+			Help: "The number of entity config updates waiting to be pushed to agents",
+		},
+	)
+)
+
+type entityConfigKey struct {
+	namespace string
+	name      string
+}
+
+// configPacer paces the entity config updates pushed to agents, so that bulk
+// changes to entity configs don't flood every session at once. Only the
+// latest pending update of an entity is pushed.
+type configPacer struct {
+	mu      sync.Mutex
+	pending map[entityConfigKey]*storev2.WatchEvent
+	order   []entityConfigKey
+	notify  chan struct{}
+	limiter *rate.Limiter
+}
+
+// newConfigPacer returns a configPacer that pushes at most limit updates per
+// second. Updates are not rate limited if limit is zero.
+func newConfigPacer(limit float64) *configPacer {
+	l := rate.Inf
+	if limit > 0 {
+		l = rate.Limit(limit)
+	}
+	return &configPacer{
+		pending: make(map[entityConfigKey]*storev2.WatchEvent),
+		notify:  make(chan struct{}, 1),
+		limiter: rate.NewLimiter(l, 1),
+	}
+}
+
+// Push queues event, replacing the pending update of the same entity.
+func (p *configPacer) Push(event storev2.WatchEvent) {
+	key := entityConfigKey{namespace: event.Key.Namespace, name: event.Key.Name}
+	p.mu.Lock()
+	if _, ok := p.pending[key]; ok {
+		entityConfigUpdatesCoalesced.Inc()
+	} else {
+		p.order = append(p.order, key)
+		entityConfigUpdatesPending.Inc()
+	}
+	p.pending[key] = &event
+	p.mu.Unlock()
+
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next pops the oldest pending update, or returns nil if there is none.
+func (p *configPacer) next() *storev2.WatchEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.order) == 0 {
+		return nil
+	}
+	key := p.order[0]
+	p.order = p.order[1:]
+	event := p.pending[key]
+	delete(p.pending, key)
+	entityConfigUpdatesPending.Dec()
+	return event
+}
+
+// Run pushes the pending updates with publish, in the order of their first
+// pending update, until ctx is done.
+func (p *configPacer) Run(ctx context.Context, publish func(storev2.WatchEvent) error) {
+	for {
+		if err := p.limiter.Wait(ctx); err != nil {
+			return
+		}
+		event := p.next()
+		for event == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.notify:
+			}
+			event = p.next()
+		}
+		if err := publish(*event); err != nil {
+			logger.WithError(err).Error("error handling entity config watch event")
+		}
+	}
+}
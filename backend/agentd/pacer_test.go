@@ -0,0 +1,68 @@
+package agentd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+func newWatchEvent(name string, revision int64) storev2.WatchEvent {
+	return storev2.WatchEvent{
+		Type:     storev2.WatchUpdate,
+		Key:      storev2.ResourceRequest{Namespace: "default", Name: name},
+		Revision: revision,
+	}
+}
+
+func TestConfigPacerCoalesces(t *testing.T) {
+	pacer := newConfigPacer(0)
+	pacer.Push(newWatchEvent("a", 1))
+	pacer.Push(newWatchEvent("b", 2))
+	pacer.Push(newWatchEvent("a", 3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	published := make(chan storev2.WatchEvent, 3)
+	go pacer.Run(ctx, func(event storev2.WatchEvent) error {
+		published <- event
+		return nil
+	})
+
+	first := <-published
+	assert.Equal(t, "a", first.Key.Name)
+	assert.Equal(t, int64(3), first.Revision)
+	second := <-published
+	assert.Equal(t, "b", second.Key.Name)
+	select {
+	case event := <-published:
+		t.Fatalf("unexpected event: %v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestConfigPacerRateLimits(t *testing.T) {
+	pacer := newConfigPacer(20)
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		pacer.Push(newWatchEvent(name, int64(i)))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	published := make(chan storev2.WatchEvent, 5)
+	begin := time.Now()
+	go pacer.Run(ctx, func(event storev2.WatchEvent) error {
+		published <- event
+		return nil
+	})
+	for i := 0; i < 5; i++ {
+		<-published
+	}
+	// the first update is pushed at once, the next ones every 50ms
+	if elapsed := time.Since(begin); elapsed < 150*time.Millisecond {
+		t.Errorf("updates were pushed too fast: %s", elapsed)
+	}
+}
@@ -0,0 +1,86 @@
+package agentd
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/transport"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sessionRTTName         = "sensu_go_agentd_session_rtt_seconds"
+	sessionMissedPongsName = "sensu_go_agentd_session_missed_pongs_total"
+
+	// DefaultPingInterval is the default interval at which the sessions ping
+	// their agent.
+	DefaultPingInterval = 30 * time.Second
+)
+
+var (
+	sessionRTT = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    sessionRTTName,
+			Help:    "The round-trip time of the pings of the agent sessions",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"namespace"},
+	)
+
+	sessionMissedPongs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: sessionMissedPongsName,
+			Help: "The total number of pings of the agent sessions that were not answered in time",
+		},
+		[]string{"namespace"},
+	)
+)
+
+// pinger pings the agent at the ping interval of the session, for as long as
+// the session runs, to measure the round-trip time of its connection. A ping
+// whose pong is not received before the next ping is missed. The agents
+// answer the pings while they receive messages, so missed pongs reveal a
+// degraded network before the keepalives of the agent fail.
+func (s *Session) pinger() {
+	interval := s.cfg.PingInterval
+	if interval <= 0 {
+		return
+	}
+	p, ok := s.conn.(transport.Pinger)
+	if !ok {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		rtt, err := p.Ping(s.ctx, interval)
+		if s.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			atomic.AddInt64(&s.missedPongs, 1)
+			sessionMissedPongs.WithLabelValues(s.cfg.Namespace).Inc()
+			logger.WithFields(logrus.Fields{
+				"agent":     s.cfg.AgentName,
+				"namespace": s.cfg.Namespace,
+			}).WithError(err).Warn("agent did not answer the ping of its session")
+			continue
+		}
+		atomic.StoreInt64(&s.rtt, int64(rtt))
+		sessionRTT.WithLabelValues(s.cfg.Namespace).Observe(rtt.Seconds())
+	}
+}
+
+// lastRTT returns the round-trip time of the last ping of the session
+// answered by the agent, in seconds, rounded to the microsecond, or zero.
+func (s *Session) lastRTT() float64 {
+	rtt := time.Duration(atomic.LoadInt64(&s.rtt))
+	return math.Round(rtt.Seconds()*1e6) / 1e6
+}
@@ -0,0 +1,74 @@
+package agentd
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sensu/sensu-go/testing/mocktransport"
+	"github.com/sensu/sensu-go/transport"
+)
+
+// pingTransport answers the pings with its results, in order, then stops
+// the session.
+type pingTransport struct {
+	mocktransport.MockTransport
+	mu      sync.Mutex
+	results []error
+	cancel  context.CancelFunc
+}
+
+func (p *pingTransport) Ping(ctx context.Context, timeout time.Duration) (time.Duration, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.results) == 0 {
+		p.cancel()
+		return 0, ctx.Err()
+	}
+	err := p.results[0]
+	p.results = p.results[1:]
+	if err != nil {
+		return 0, err
+	}
+	return 5 * time.Millisecond, nil
+}
+
+func TestSession_pinger(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &Session{
+		cfg: SessionConfig{
+			AgentName:    "agent",
+			Namespace:    "pinger",
+			PingInterval: time.Millisecond,
+		},
+		conn: &pingTransport{
+			results: []error{nil, transport.ErrPongTimeout, nil, transport.ErrPongTimeout},
+			cancel:  cancel,
+		},
+		ctx: ctx,
+	}
+	s.pinger()
+
+	if got, want := s.info().MissedPongs, int64(2); got != want {
+		t.Errorf("missed pongs: got %d, want %d", got, want)
+	}
+	if got, want := s.info().RTT, 0.005; got != want {
+		t.Errorf("rtt: got %v, want %v", got, want)
+	}
+	if got, want := testutil.ToFloat64(sessionMissedPongs.WithLabelValues("pinger")), 2.0; got != want {
+		t.Errorf("missed pongs counter: got %v, want %v", got, want)
+	}
+}
+
+func TestSession_pingerDisabled(t *testing.T) {
+	s := &Session{
+		cfg:  SessionConfig{Namespace: "pinger-disabled"},
+		conn: &pingTransport{},
+		ctx:  context.Background(),
+	}
+	// The pinger returns right away
+	s.pinger()
+}
@@ -0,0 +1,248 @@
+package agentd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// proxyHeaderTimeout is the time given to the connections to send their
+	// PROXY protocol header.
+	proxyHeaderTimeout = 5 * time.Second
+
+	// maxProxyHeaderV1Size is the maximum size of a PROXY protocol v1
+	// header, including its CRLF.
+	maxProxyHeaderV1Size = 107
+
+	proxyProtocolErrorsName = "sensu_go_agentd_proxy_protocol_errors_total"
+)
+
+// proxyHeaderV2Signature is the signature of the PROXY protocol v2 headers.
+var proxyHeaderV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+var proxyProtocolErrors = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: proxyProtocolErrorsName,
+		Help: "The total number of agentd connections closed because of an invalid PROXY protocol header",
+	},
+)
+
+// proxyProtocolListener is a listener whose connections start with a PROXY
+// protocol header, sent by the load balancer in front of agentd, that gives
+// the address of the agents. The addresses of the headers are the remote
+// addresses of the accepted connections, so that the agent addresses show up
+// in the logs and the session listing instead of the load balancer address.
+// Connections without a valid header are closed.
+//
+// The headers are read concurrently with a timeout, so that slow or idle
+// connections can't block the other connections from being accepted.
+type proxyProtocolListener struct {
+	net.Listener
+
+	conns chan net.Conn
+	errs  chan error
+	done  chan struct{}
+	once  sync.Once
+}
+
+// newProxyProtocolListener returns a listener that accepts the connections of
+// ln and reads their PROXY protocol header.
+func newProxyProtocolListener(ln net.Listener) *proxyProtocolListener {
+	l := &proxyProtocolListener{
+		Listener: ln,
+		conns:    make(chan net.Conn),
+		errs:     make(chan error),
+		done:     make(chan struct{}),
+	}
+	go l.acceptLoop()
+	return l
+}
+
+func (l *proxyProtocolListener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			select {
+			case l.errs <- err:
+			case <-l.done:
+				return
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		go l.readHeader(conn)
+	}
+}
+
+func (l *proxyProtocolListener) readHeader(conn net.Conn) {
+	_ = conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+	r := bufio.NewReader(conn)
+	addr, err := readProxyHeader(r)
+	if err != nil {
+		proxyProtocolErrors.Inc()
+		logger.WithField("source", conn.RemoteAddr().String()).WithError(err).Warn("closing agentd connection with an invalid PROXY protocol header")
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	if addr == nil {
+		// The load balancer connects on its own behalf, such as for health
+		// checks
+		addr = conn.RemoteAddr()
+	}
+	select {
+	case l.conns <- &proxyProtocolConn{Conn: conn, r: r, remoteAddr: addr}:
+	case <-l.done:
+		_ = conn.Close()
+	}
+}
+
+// Accept returns the next connection whose PROXY protocol header was read.
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case err := <-l.errs:
+		return nil, err
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the listener.
+func (l *proxyProtocolListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+// proxyProtocolConn is a connection whose remote address is given by its
+// PROXY protocol header.
+type proxyProtocolConn struct {
+	net.Conn
+
+	// r holds the data read past the header
+	r          *bufio.Reader
+	remoteAddr net.Addr
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+// readProxyHeader reads a PROXY protocol v1 or v2 header from r, and returns
+// the source address it gives. It returns a nil address if the header gives
+// no address, such as the headers of the health checks of the load balancer.
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	// The shortest v1 header, "PROXY UNKNOWN\r\n", is longer than the v2
+	// signature
+	b, err := r.Peek(len(proxyHeaderV2Signature))
+	if err != nil {
+		return nil, fmt.Errorf("error reading PROXY protocol header: %s", err)
+	}
+	if bytes.Equal(b, proxyHeaderV2Signature) {
+		return readProxyHeaderV2(r)
+	}
+	if bytes.HasPrefix(b, []byte("PROXY ")) {
+		return readProxyHeaderV1(r)
+	}
+	return nil, errors.New("missing PROXY protocol header")
+}
+
+// readProxyHeaderV1 reads a text header such as
+// "PROXY TCP4 192.0.2.1 192.0.2.2 56324 8081\r\n".
+func readProxyHeaderV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < maxProxyHeaderV1Size {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("error reading PROXY protocol header: %s", err)
+		}
+		line = append(line, c)
+		if c == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("invalid PROXY protocol header: missing CRLF")
+	}
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("invalid PROXY protocol header: %q", line)
+	}
+	ip := net.ParseIP(fields[2])
+	if ip == nil || (fields[1] == "TCP4") != (ip.To4() != nil) {
+		return nil, fmt.Errorf("invalid PROXY protocol source address: %q", fields[2])
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY protocol source port: %q", fields[4])
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyHeaderV2 reads a binary header: the signature, the version and
+// command, the address family and protocol, the length of the addresses and
+// their TLVs, then the addresses and their TLVs.
+func readProxyHeaderV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, len(proxyHeaderV2Signature)+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("error reading PROXY protocol header: %s", err)
+	}
+	versionCommand, family := header[12], header[13]
+	body := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("error reading PROXY protocol header: %s", err)
+	}
+	if versionCommand>>4 != 2 {
+		return nil, fmt.Errorf("unsupported PROXY protocol version: %d", versionCommand>>4)
+	}
+	switch versionCommand & 0x0f {
+	case 0x0:
+		// LOCAL command
+		return nil, nil
+	case 0x1:
+		// PROXY command
+	default:
+		return nil, fmt.Errorf("unsupported PROXY protocol command: %d", versionCommand&0x0f)
+	}
+	var ipLen int
+	switch family {
+	case 0x11:
+		// TCP over IPv4
+		ipLen = net.IPv4len
+	case 0x21:
+		// TCP over IPv6
+		ipLen = net.IPv6len
+	default:
+		// Unspecified, UDP or UNIX addresses, that agents don't connect from
+		return nil, nil
+	}
+	// The source and destination addresses, then their ports
+	if len(body) < 2*ipLen+4 {
+		return nil, errors.New("invalid PROXY protocol header: truncated addresses")
+	}
+	ip := make(net.IP, ipLen)
+	copy(ip, body[:ipLen])
+	port := binary.BigEndian.Uint16(body[2*ipLen:])
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
@@ -0,0 +1,110 @@
+package agentd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func proxyHeaderV2(command, family byte, addrs []byte) []byte {
+	header := append([]byte{}, proxyHeaderV2Signature...)
+	header = append(header, 0x20|command, family, 0, 0)
+	binary.BigEndian.PutUint16(header[14:], uint16(len(addrs)))
+	return append(header, addrs...)
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	ipv4 := []byte{192, 0, 2, 1, 192, 0, 2, 2, 0xdc, 0x04, 0x1f, 0x91}
+	ipv6 := make([]byte, 36)
+	copy(ipv6, net.ParseIP("2001:db8::1"))
+	binary.BigEndian.PutUint16(ipv6[32:], 56324)
+	// TLVs follow the addresses
+	tlvs := append(append([]byte{}, ipv4...), 0x04, 0x00, 0x01, 0xff)
+
+	tests := []struct {
+		name    string
+		header  []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "v1 tcp4", header: []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 8081\r\n"), want: "192.0.2.1:56324"},
+		{name: "v1 tcp6", header: []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 8081\r\n"), want: "[2001:db8::1]:56324"},
+		{name: "v1 unknown", header: []byte("PROXY UNKNOWN\r\n")},
+		{name: "v1 bad family", header: []byte("PROXY TCP4 2001:db8::1 2001:db8::2 56324 8081\r\n"), wantErr: true},
+		{name: "v1 bad port", header: []byte("PROXY TCP4 192.0.2.1 192.0.2.2 port 8081\r\n"), wantErr: true},
+		{name: "v1 missing crlf", header: []byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 8081\n"), wantErr: true},
+		{name: "v1 too long", header: []byte("PROXY " + strings.Repeat("A", 200) + "\r\n"), wantErr: true},
+		{name: "v2 tcp4", header: proxyHeaderV2(0x1, 0x11, ipv4), want: "192.0.2.1:56324"},
+		{name: "v2 tcp6", header: proxyHeaderV2(0x1, 0x21, ipv6), want: "[2001:db8::1]:56324"},
+		{name: "v2 tlvs", header: proxyHeaderV2(0x1, 0x11, tlvs), want: "192.0.2.1:56324"},
+		{name: "v2 local", header: proxyHeaderV2(0x0, 0x00, nil)},
+		{name: "v2 truncated", header: proxyHeaderV2(0x1, 0x11, ipv4)[:20], wantErr: true},
+		{name: "v2 short addresses", header: proxyHeaderV2(0x1, 0x21, ipv4), wantErr: true},
+		{name: "v2 bad command", header: proxyHeaderV2(0x2, 0x11, ipv4), wantErr: true},
+		{name: "missing", header: []byte("GET / HTTP/1.1\r\nHost: agentd\r\n\r\n"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The data past the header is left to be read
+			r := bufio.NewReader(io.MultiReader(bytes.NewReader(tt.header), strings.NewReader("GET /")))
+			addr, err := readProxyHeader(r)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Nil(t, addr)
+			} else {
+				require.NotNil(t, addr)
+				assert.Equal(t, tt.want, addr.String())
+			}
+			rest, _ := io.ReadAll(r)
+			assert.Equal(t, "GET /", string(rest))
+		})
+	}
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ln := newProxyProtocolListener(tcp)
+	defer ln.Close()
+
+	// A connection without a header doesn't block the next one
+	invalid, err := net.Dial("tcp", tcp.Addr().String())
+	require.NoError(t, err)
+	defer invalid.Close()
+	_, err = invalid.Write([]byte("GET / HTTP/1.1\r\n"))
+	require.NoError(t, err)
+
+	client, err := net.Dial("tcp", tcp.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.Write([]byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 8081\r\nhello"))
+	require.NoError(t, err)
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "192.0.2.1:56324", conn.RemoteAddr().String())
+	b := make([]byte, 5)
+	_, err = io.ReadFull(conn, b)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	// The connection without a header is closed
+	_, err = invalid.Read(b)
+	assert.Error(t, err)
+
+	require.NoError(t, ln.Close())
+	_, err = ln.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+}
@@ -0,0 +1,132 @@
+package agentd
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	eventsRateLimitedName = "sensu_go_agentd_events_rate_limited"
+
+	// rateLimitNamespace and rateLimitAgent are the values of the limit label
+	// of the rate limited events counter.
+	rateLimitNamespace = "namespace"
+	rateLimitAgent     = "agent"
+
+	// backoffInterval is the minimum interval between two backoff messages
+	// sent to an agent.
+	backoffInterval = time.Second
+)
+
+// backoffAgentVersion is the first agent version that handles the backoff
+// messages of agentd.
+var backoffAgentVersion = semver.MustParse("7.0.0")
+
+var eventsRateLimited = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: eventsRateLimitedName,
+		Help: "The total number of agent events rejected by the event rate limits of agentd",
+	},
+	[]string{"namespace", "limit"},
+)
+
+// EventRateLimits are the limits of the events received by agentd from
+// agents, in events per second. Keepalives are not limited. A zero limit
+// disables the limit.
+type EventRateLimits struct {
+	// Namespace is the limit of the events of the agents of a namespace that
+	// are connected to the backend.
+	Namespace float64
+
+	// Agent is the limit of the events of an agent.
+	Agent float64
+
+	// Backoff sends a backoff message to the agents whose events are
+	// rejected, so that they pause their check executions.
+	Backoff bool
+}
+
+// newEventLimiter returns a limiter of limit events per second, which allows
+// bursts of a second of events, or nil if limit is zero.
+func newEventLimiter(limit float64) *rate.Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(limit), int(math.Ceil(limit)))
+}
+
+// namespaceLimiters are the event limiters of the namespaces, shared by the
+// sessions of agentd.
+type namespaceLimiters struct {
+	mu       sync.Mutex
+	limit    float64
+	limiters map[string]*rate.Limiter
+}
+
+func newNamespaceLimiters(limit float64) *namespaceLimiters {
+	return &namespaceLimiters{
+		limit:    limit,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// Get returns the limiter of namespace, or nil if the namespaces are not
+// limited. A nil *namespaceLimiters limits nothing.
+func (n *namespaceLimiters) Get(namespace string) *rate.Limiter {
+	if n == nil || n.limit <= 0 {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	limiter, ok := n.limiters[namespace]
+	if !ok {
+		limiter = newEventLimiter(n.limit)
+		n.limiters[namespace] = limiter
+	}
+	return limiter
+}
+
+// backoffPayload is the payload of the backoff message sent to an agent whose
+// events are limited to limit events per second: the number of seconds for
+// which the agent should back off.
+func backoffPayload(limit rate.Limit) []byte {
+	seconds := 1
+	if limit > 0 && limit < 1 {
+		seconds = int(math.Ceil(float64(1 / limit)))
+	}
+	return []byte(strconv.Itoa(seconds))
+}
+
+// reserveEvent reserves a token of limiter for an event at now. It returns
+// false, and the reservation to cancel, if the event exceeds the limit. A nil
+// limiter limits nothing, and returns a nil reservation.
+func reserveEvent(limiter *rate.Limiter, now time.Time) (*rate.Reservation, bool) {
+	if limiter == nil {
+		return nil, true
+	}
+	r := limiter.ReserveN(now, 1)
+	return r, r.OK() && r.DelayFrom(now) == 0
+}
+
+// supportsBackoff returns true if an agent of agentVersion handles the backoff
+// messages of agentd. Agents older than the version check don't send their
+// version, and versions that can't be parsed, such as the versions of
+// development builds, are assumed to be recent.
+func supportsBackoff(agentVersion string) bool {
+	if agentVersion == "" {
+		return false
+	}
+	v, err := semver.ParseTolerant(agentVersion)
+	if err != nil {
+		return true
+	}
+	v.Pre = nil
+	v.Build = nil
+	return v.GTE(backoffAgentVersion)
+}
@@ -0,0 +1,180 @@
+package agentd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNewEventLimiter(t *testing.T) {
+	if l := newEventLimiter(0); l != nil {
+		t.Error("expected no limiter for a zero limit")
+	}
+	l := newEventLimiter(2.5)
+	if got, want := l.Burst(), 3; got != want {
+		t.Errorf("bad burst: got %d, want %d", got, want)
+	}
+}
+
+func TestNamespaceLimiters(t *testing.T) {
+	var nilLimiters *namespaceLimiters
+	if l := nilLimiters.Get("default"); l != nil {
+		t.Error("expected no limiter")
+	}
+	limiters := newNamespaceLimiters(1)
+	if limiters.Get("default") != limiters.Get("default") {
+		t.Error("expected the limiter of a namespace to be shared")
+	}
+	if limiters.Get("default") == limiters.Get("dev") {
+		t.Error("expected a limiter per namespace")
+	}
+}
+
+func TestBackoffPayload(t *testing.T) {
+	tests := []struct {
+		limit float64
+		want  string
+	}{
+		{limit: 10, want: "1"},
+		{limit: 1, want: "1"},
+		{limit: 0.5, want: "2"},
+		{limit: 0.1, want: "10"},
+	}
+	for _, tt := range tests {
+		l := newEventLimiter(tt.limit)
+		if got := string(backoffPayload(l.Limit())); got != tt.want {
+			t.Errorf("backoffPayload(%v) = %q, want %q", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSession_allowEvent(t *testing.T) {
+	tests := []struct {
+		name         string
+		limits       EventRateLimits
+		namespace    string
+		agentVersion string
+		allowed      int
+		limit        string
+		backoff      bool
+	}{
+		{
+			name:      "no limits",
+			namespace: "allow-none",
+			allowed:   10,
+		},
+		{
+			name:      "agent limit",
+			limits:    EventRateLimits{Agent: 2},
+			namespace: "allow-agent",
+			allowed:   2,
+			limit:     rateLimitAgent,
+		},
+		{
+			name:         "namespace limit with backoff",
+			limits:       EventRateLimits{Namespace: 3, Backoff: true},
+			namespace:    "allow-namespace",
+			agentVersion: "7.0.0",
+			allowed:      3,
+			limit:        rateLimitNamespace,
+			backoff:      true,
+		},
+		{
+			name:         "no backoff for old agents",
+			limits:       EventRateLimits{Namespace: 3, Backoff: true},
+			namespace:    "allow-old-agent",
+			agentVersion: "6.9.1",
+			allowed:      3,
+			limit:        rateLimitNamespace,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Session{
+				cfg: SessionConfig{
+					AgentName:         "agent",
+					AgentVersion:      tt.agentVersion,
+					Namespace:         tt.namespace,
+					EventRateLimits:   tt.limits,
+					namespaceLimiters: newNamespaceLimiters(tt.limits.Namespace),
+				},
+				agentLimiter: newEventLimiter(tt.limits.Agent),
+				backoffs:     make(chan []byte, 1),
+			}
+			var allowed int
+			for i := 0; i < 10; i++ {
+				if s.allowEvent() {
+					allowed++
+				}
+			}
+			if allowed != tt.allowed {
+				t.Errorf("allowed %d events, want %d", allowed, tt.allowed)
+			}
+			if tt.limit != "" {
+				counter := eventsRateLimited.WithLabelValues(tt.namespace, tt.limit)
+				if got, want := testutil.ToFloat64(counter), float64(10-tt.allowed); got != want {
+					t.Errorf("rate limited events: got %v, want %v", got, want)
+				}
+			}
+			select {
+			case payload := <-s.backoffs:
+				if !tt.backoff {
+					t.Errorf("unexpected backoff %q", payload)
+				} else if string(payload) != "1" {
+					t.Errorf("bad backoff payload: %q", payload)
+				}
+			default:
+				if tt.backoff {
+					t.Error("expected a backoff")
+				}
+			}
+		})
+	}
+}
+
+func TestSession_allowEventReservesBothLimits(t *testing.T) {
+	s := &Session{
+		cfg: SessionConfig{
+			AgentName:         "agent",
+			Namespace:         "allow-both",
+			EventRateLimits:   EventRateLimits{Agent: 5, Namespace: 3},
+			namespaceLimiters: newNamespaceLimiters(3),
+		},
+		agentLimiter: newEventLimiter(5),
+		backoffs:     make(chan []byte, 1),
+	}
+	var allowed int
+	for i := 0; i < 10; i++ {
+		if s.allowEvent() {
+			allowed++
+		}
+	}
+	if got, want := allowed, 3; got != want {
+		t.Errorf("allowed %d events, want %d", got, want)
+	}
+	// The events rejected by the namespace limit don't consume the tokens of
+	// the agent limit
+	if !s.agentLimiter.AllowN(time.Now(), 2) {
+		t.Error("expected the agent limiter to keep the tokens of the rejected events")
+	}
+}
+
+func TestSupportsBackoff(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "", want: false},
+		{version: "6.9.1", want: false},
+		{version: "7.0.0", want: true},
+		{version: "7.0.0-beta.1", want: true},
+		{version: "7.1.2", want: true},
+		{version: "(devel)", want: true},
+	}
+	for _, tt := range tests {
+		if got := supportsBackoff(tt.version); got != tt.want {
+			t.Errorf("supportsBackoff(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
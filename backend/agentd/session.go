@@ -8,22 +8,27 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/agent"
 	"github.com/sensu/sensu-go/backend/messaging"
 	"github.com/sensu/sensu-go/backend/metrics"
+	"github.com/sensu/sensu-go/backend/pipeline"
 	"github.com/sensu/sensu-go/backend/ringv2"
+	"github.com/sensu/sensu-go/backend/selfmonitor"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 	"github.com/sensu/sensu-go/backend/store/v2/wrap"
 	"github.com/sensu/sensu-go/handler"
 	"github.com/sensu/sensu-go/transport"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -41,6 +46,10 @@ const (
 	// Name of the websocket errors metric
 	websocketErrorCounterName = "sensu_go_websocket_errors"
 
+	// Number of consecutive messages on which the message handler of a
+	// session fails before it is reported as a crash loop
+	handlerCrashLoopFailures = 10
+
 	// EventBytesSummaryName is the name of the prometheus summary vec used to
 	// track event sizes (in bytes).
 	EventBytesSummaryName = "sensu_go_agentd_event_bytes"
@@ -53,6 +62,10 @@ const (
 var (
 	eventBytesSummary = metrics.NewEventBytesSummaryVec(EventBytesSummaryName, EventBytesSummaryHelp)
 
+	// sessionQueues holds the active sessions of this backend, to measure
+	// their queues of check requests and to list them.
+	sessionQueues sync.Map
+
 	sessionCounter = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: sessionCounterName,
@@ -98,6 +111,38 @@ type Session struct {
 	entityConfig     *entityConfig
 	mu               sync.Mutex
 	subscriptionsMap map[string]subscription
+	agentLimiter     *rate.Limiter
+	backoffs         chan []byte
+	lastBackoff      time.Time
+	acks             chan []byte
+	throttles        chan []byte
+	rejections       chan []byte
+	connectedAt      time.Time
+	messagesReceived int64
+	messagesSent     int64
+
+	// bytesSentCounted and bytesReceivedCounted are the bytes sent and
+	// received over the connection already added to the bytes counters,
+	// by the sender and by the receiver.
+	bytesSentCounted     int64
+	bytesReceivedCounted int64
+
+	// handlerFailures is the number of consecutive messages on which the
+	// message handler failed, only used by the receiver.
+	handlerFailures int
+
+	// rtt is the round-trip time of the last ping answered by the agent, and
+	// missedPongs the number of pings it did not answer in time.
+	rtt         int64
+	missedPongs int64
+
+	// draining is set once the session stops receiving check requests,
+	// before it is closed.
+	draining int32
+
+	// clockSkew is the clock skew of the entity of the session, as last
+	// measured by its keepalives.
+	clockSkew clockSkewState
 }
 
 // subscription is used to abstract a message.Subscription and therefore allow
@@ -122,6 +167,7 @@ func newSessionHandler(s *Session) *handler.MessageHandler {
 	handler := handler.NewMessageHandler()
 	handler.AddHandler(transport.MessageTypeKeepalive, s.handleKeepalive)
 	handler.AddHandler(transport.MessageTypeEvent, s.handleEvent)
+	handler.AddHandler(transport.MessageTypeEventBatch, s.handleEventBatch)
 
 	return handler
 }
@@ -135,6 +181,7 @@ type SessionConfig struct {
 	AgentName     string
 	User          string
 	Subscriptions []string
+	AgentVersion  string
 	WriteTimeout  int
 
 	Bus      messaging.MessageBus
@@ -144,6 +191,71 @@ type SessionConfig struct {
 
 	Marshal   agent.MarshalFunc
 	Unmarshal agent.UnmarshalFunc
+
+	// EventRateLimits limit the events received from the agent.
+	EventRateLimits EventRateLimits
+
+	// EventAcks sends an acknowledgement to the agent for each of its events
+	// once it is published, so that the agent retries the others.
+	EventAcks bool
+
+	// Throttle sends throttle messages to the agent while the backend is
+	// saturated.
+	Throttle bool
+
+	// CheckChannelSize is the number of check requests buffered for the
+	// agent, DefaultCheckChannelSize if it is zero. DropCheckRequests drops
+	// the check requests sent while the buffer is full, instead of blocking
+	// the message bus.
+	CheckChannelSize  int
+	DropCheckRequests bool
+
+	// ClockSkewThreshold is the clock skew of the agent from which a warning
+	// event is published for its entity. The clock skew is not checked if it
+	// is zero.
+	ClockSkewThreshold time.Duration
+
+	// MaxEventSize is the maximum size of the events received from the
+	// agent, in bytes, or zero for no limit. EventRejections sends an event
+	// rejection message to the agent for each of its rejected events.
+	MaxEventSize    int
+	EventRejections bool
+
+	// PingInterval is the interval at which the agent is pinged, to measure
+	// the round-trip time of its connection. The agent is not pinged if it
+	// is zero.
+	PingInterval time.Duration
+
+	// ProtocolVersion is the protocol version negotiated with the agent.
+	// The messages whose type is not supported by the protocol version are
+	// not sent to the agent. The agent speaks the legacy protocol if it is
+	// zero.
+	ProtocolVersion int
+
+	// namespaceLimiters are the event limiters of the namespaces, shared by
+	// the sessions of agentd.
+	namespaceLimiters *namespaceLimiters
+
+	// deadLetters holds the events that could not be published, shared by
+	// the sessions of agentd.
+	deadLetters *DeadLetterQueue
+
+	// configAudit records the entity config updates sent to the agent,
+	// shared by the sessions of agentd.
+	configAudit *ConfigAuditLog
+
+	// admittedSubscriptions are the subscriptions set by the admission
+	// webhook, that replace the subscriptions of the keepalives of the agent.
+	// The subscriptions of the keepalives are kept if it is nil.
+	admittedSubscriptions []string
+
+	// release stops counting the session against the session limits of
+	// agentd, once it ends.
+	release func()
+
+	// selfMonitor reports the internal errors of the session, shared by the
+	// sessions of agentd.
+	selfMonitor *selfmonitor.Monitor
 }
 
 // NewSession creates a new Session object given the triple of a transport
@@ -160,11 +272,19 @@ func NewSession(ctx context.Context, cfg SessionConfig) (*Session, error) {
 
 	ctx, cancel := context.WithCancel(ctx)
 
+	checkChannelSize := cfg.CheckChannelSize
+	if checkChannelSize <= 0 {
+		checkChannelSize = DefaultCheckChannelSize
+	}
+	if cfg.ProtocolVersion == 0 {
+		cfg.ProtocolVersion = transport.ProtocolVersionLegacy
+	}
+
 	s := &Session{
 		conn:             cfg.Conn,
 		cfg:              cfg,
 		wg:               &sync.WaitGroup{},
-		checkChannel:     make(chan interface{}, 100),
+		checkChannel:     make(chan interface{}, checkChannelSize),
 		storev2:          cfg.Storev2,
 		bus:              cfg.Bus,
 		subscriptionsMap: map[string]subscription{},
@@ -177,6 +297,12 @@ func NewSession(ctx context.Context, cfg SessionConfig) (*Session, error) {
 			subscriptions:  make(chan messaging.Subscription, 1),
 			updatesChannel: make(chan interface{}, 10),
 		},
+		agentLimiter: newEventLimiter(cfg.EventRateLimits.Agent),
+		backoffs:     make(chan []byte, 1),
+		acks:         make(chan []byte, 100),
+		throttles:    make(chan []byte, 1),
+		rejections:   make(chan []byte, 10),
+		connectedAt:  time.Now(),
 	}
 
 	s.handler = newSessionHandler(s)
@@ -201,6 +327,12 @@ func (s *Session) receiver() {
 			return
 		}
 		msg, err := s.conn.Receive()
+		if tooLarge, ok := err.(transport.MessageTooLargeError); ok {
+			// The message was dropped by the transport, which can still
+			// be used
+			s.rejectTooLarge(tooLarge)
+			continue
+		}
 		if err != nil {
 			switch err := err.(type) {
 			case transport.ConnectionError:
@@ -221,17 +353,28 @@ func (s *Session) receiver() {
 			}
 			return
 		}
+		atomic.AddInt64(&s.messagesReceived, 1)
+		s.countBytesReceived()
 		ctx, cancel := context.WithTimeout(s.ctx, time.Duration(s.cfg.WriteTimeout)*time.Second)
 		if err := s.handler.Handle(ctx, msg.Type, msg.Payload); err != nil {
 			logger.WithError(err).WithFields(logrus.Fields{
 				"type":    msg.Type,
 				"payload": string(msg.Payload)}).Error("error handling message")
+			s.handlerFailures++
+			if s.handlerFailures == handlerCrashLoopFailures {
+				sessionErrorCounter.WithLabelValues("HandlerCrashLoop").Inc()
+				s.cfg.selfMonitor.Record(selfmonitor.ConditionHandlerCrashLoop)
+				s.handlerFailures = 0
+			}
 			if _, ok := err.(*store.ErrInternal); ok {
 				// Fatal error - boot the agent out of the session
 				sessionErrorCounter.WithLabelValues("store.ErrInternal").Inc()
+				s.cfg.selfMonitor.Record(selfmonitor.ConditionStoreInternal)
 				logger.Error("internal error - stopping session")
 				go s.Stop()
 			}
+		} else {
+			s.handlerFailures = 0
 		}
 		cancel()
 	}
@@ -303,6 +446,10 @@ func (s *Session) sender() {
 				continue
 			}
 
+			// The entity config can't subscribe the agent beyond the
+			// subscriptions set by the admission webhook
+			entity.Subscriptions = s.admitSubscriptions(entity.Subscriptions)
+
 			bytes, err := s.marshal(entity)
 			if err != nil {
 				lager.WithError(err).Error("session failed to serialize entity config")
@@ -332,6 +479,7 @@ func (s *Session) sender() {
 				lager.Debug("not sending entity update because entity is managed by its agent")
 			}
 
+			s.cfg.configAudit.record(s.ctx, s.cfg.AgentName, watchEvent.Type, entity, added, removed)
 			msg = transport.NewMessage(transport.MessageTypeEntityConfig, bytes)
 		case c := <-s.checkChannel:
 			request, ok := c.(*corev2.CheckRequest)
@@ -340,6 +488,15 @@ func (s *Session) sender() {
 				continue
 			}
 
+			if s.excludesCheck(request) {
+				logger.WithFields(logrus.Fields{
+					"agent":     s.cfg.AgentName,
+					"namespace": s.cfg.Namespace,
+					"check":     request.Config.Name,
+				}).Debug("not sending check request excluded by a negated subscription")
+				continue
+			}
+
 			configBytes, err := s.marshal(request)
 			if err != nil {
 				logger.WithError(err).Error("session failed to serialize check request")
@@ -347,9 +504,26 @@ func (s *Session) sender() {
 			}
 
 			msg = transport.NewMessage(corev2.CheckRequestType, configBytes)
+		case payload := <-s.backoffs:
+			msg = transport.NewMessage(transport.MessageTypeBackoff, payload)
+		case payload := <-s.acks:
+			msg = transport.NewMessage(transport.MessageTypeEventAck, payload)
+		case payload := <-s.throttles:
+			msg = transport.NewMessage(transport.MessageTypeThrottle, payload)
+		case payload := <-s.rejections:
+			msg = transport.NewMessage(transport.MessageTypeEventRejected, payload)
 		case <-s.ctx.Done():
 			return
 		}
+		if !transport.SupportsMessageType(s.cfg.ProtocolVersion, msg.Type) {
+			logger.WithFields(logrus.Fields{
+				"agent":            s.cfg.AgentName,
+				"namespace":        s.cfg.Namespace,
+				"type":             msg.Type,
+				"protocol_version": s.cfg.ProtocolVersion,
+			}).Debug("not sending message unsupported by the protocol version of the agent")
+			continue
+		}
 		logger.WithFields(logrus.Fields{
 			"type":         msg.Type,
 			"payload_size": len(msg.Payload),
@@ -366,9 +540,45 @@ func (s *Session) sender() {
 			}
 			return
 		}
+		atomic.AddInt64(&s.messagesSent, 1)
+		s.countBytesSent()
 	}
 }
 
+// ActiveSessions returns the number of active agent sessions on this backend,
+// across the namespaces of the sensu_go_agent_sessions gauge.
+func ActiveSessions() int64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		sessionCounter.Collect(ch)
+		close(ch)
+	}()
+	var sessions float64
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			continue
+		}
+		sessions += pb.GetGauge().GetValue()
+	}
+	return int64(sessions)
+}
+
+// SessionQueueDepth returns the number of check requests waiting to be sent
+// by the most saturated agent session of this backend, and the capacity of
+// its queue.
+func SessionQueueDepth() (depth, capacity int) {
+	sessionQueues.Range(func(key, _ interface{}) bool {
+		s := key.(*Session)
+		l, c := len(s.checkChannel), cap(s.checkChannel)
+		if capacity == 0 || l*capacity > depth*c {
+			depth, capacity = l, c
+		}
+		return true
+	})
+	return depth, capacity
+}
+
 // Start a Session.
 // 1. Start sender
 // 2. Start receiver
@@ -376,11 +586,14 @@ func (s *Session) sender() {
 func (s *Session) Start() (err error) {
 	defer close(s.entityConfig.subscriptions)
 	sessionCounter.WithLabelValues(s.cfg.Namespace).Inc()
+	activeSessionVersions.add(s.cfg.Namespace, s.cfg.AgentVersion, 1)
+	sessionQueues.Store(s, struct{}{})
 	s.wg = &sync.WaitGroup{}
 	s.wg.Add(2)
 	s.stopWG.Add(1)
 	go s.sender()
 	go s.receiver()
+	go s.pinger()
 	go func() {
 		<-s.ctx.Done()
 		s.stop()
@@ -415,7 +628,7 @@ func (s *Session) Start() (err error) {
 	// bus, in order to avoid problems with an agent reconnecting before its
 	// session is ended
 	agentName := agentUUID(s.cfg.Namespace, s.cfg.AgentName)
-	subscription, err := s.bus.Subscribe(topic, agentName, s.entityConfig)
+	subscription, err := messaging.Supervise(s.ctx, s.bus, topic, agentName, s.entityConfig)
 	if err != nil {
 		lager.WithError(err).Error("error starting subscription")
 		return err
@@ -485,9 +698,12 @@ func (s *Session) Start() (err error) {
 			return err
 		}
 
-		// Update the session subscriptions so it uses the stored subscriptions
+		// Update the session subscriptions so it uses the stored subscriptions,
+		// unless they were set by the admission webhook
 		s.mu.Lock()
-		s.cfg.Subscriptions = storedEntityConfig.Subscriptions
+		if s.cfg.admittedSubscriptions == nil {
+			s.cfg.Subscriptions = storedEntityConfig.Subscriptions
+		}
 		s.mu.Unlock()
 	}
 
@@ -524,6 +740,11 @@ func (s *Session) stop() {
 	defer close(s.checkChannel)
 
 	sessionCounter.WithLabelValues(s.cfg.Namespace).Dec()
+	activeSessionVersions.add(s.cfg.Namespace, s.cfg.AgentVersion, -1)
+	sessionQueues.Delete(s)
+	if s.cfg.release != nil {
+		s.cfg.release()
+	}
 
 	topic := messaging.TopicAgentConnectionState
 	err := s.bus.Publish(topic, messaging.AgentNotification{
@@ -555,8 +776,11 @@ func (s *Session) stop() {
 		}
 	}
 
-	// Unsubscribe the session from every configured check subscriptions
-	s.unsubscribe(s.cfg.Subscriptions)
+	// Unsubscribe the session from every configured check subscriptions,
+	// unless it was already unsubscribed by a drain
+	if atomic.LoadInt32(&s.draining) == 0 {
+		s.unsubscribe(s.cfg.Subscriptions)
+	}
 }
 
 // handleKeepalive is the keepalive message handler.
@@ -576,27 +800,58 @@ func (s *Session) handleKeepalive(_ context.Context, payload []byte) error {
 		return errors.New("keepalive contains invalid timestamp")
 	}
 
+	if s.cfg.admittedSubscriptions != nil {
+		// The subscriptions of the session are the admitted subscriptions
+		// that the entity config kept
+		s.mu.Lock()
+		keepalive.Entity.Subscriptions = append([]string{}, s.cfg.Subscriptions...)
+		s.mu.Unlock()
+	}
 	keepalive.Entity.Subscriptions = corev2.AddEntitySubscription(keepalive.Entity.Name, keepalive.Entity.Subscriptions)
+	store.SetEventReceived(keepalive, time.Now())
+	store.SetEventRequestID(keepalive, "")
+	s.checkClockSkew(keepalive)
 
-	return s.bus.Publish(messaging.TopicKeepalive, keepalive)
+	if err := s.bus.Publish(messaging.TopicKeepalive, keepalive); err != nil {
+		sessionErrorCounter.WithLabelValues("bus.Publish").Inc()
+		s.cfg.selfMonitor.Record(selfmonitor.ConditionBusPublish)
+		return err
+	}
+	return nil
 }
 
 // handleEvent is the event message handler.
 func (s *Session) handleEvent(_ context.Context, payload []byte) error {
+	// Reject the oversized events before decoding them
+	if !s.allowEventSize(payload) {
+		return nil
+	}
+
 	// Decode the payload to an event
 	event := &corev2.Event{}
 	if err := s.unmarshal(payload, event); err != nil {
 		return err
 	}
 
-	// Validate the received event
+	// Validate the received event. Invalid events are acknowledged, so that
+	// the agent doesn't send them again
 	if err := event.Validate(); err != nil {
+		s.ackEvent(event)
 		return err
 	}
 
 	// Add the entity subscription to the subscriptions of this entity
 	event.Entity.Subscriptions = corev2.AddEntitySubscription(event.Entity.Name, event.Entity.Subscriptions)
 
+	// Only the replay harness can stub the handlers of the events
+	pipeline.StripReplayAnnotation(event)
+
+	// Record when the event was received, next to when it was created by
+	// the agent, since the agent may have buffered it. The annotation sent by
+	// the agent is overwritten.
+	store.SetEventReceived(event, time.Now())
+	store.SetEventRequestID(event, "")
+
 	if event.HasCheck() {
 		if event.HasMetrics() {
 			eventBytesSummary.WithLabelValues(metrics.EventTypeLabelCheckAndMetrics).Observe(float64(len(payload)))
@@ -604,13 +859,98 @@ func (s *Session) handleEvent(_ context.Context, payload []byte) error {
 			eventBytesSummary.WithLabelValues(metrics.EventTypeLabelCheck).Observe(float64(len(payload)))
 		}
 		if event.Check.Name == corev2.KeepaliveCheckName {
-			return s.bus.Publish(messaging.TopicKeepaliveRaw, event)
+			s.checkClockSkew(event)
+			return s.publishEvent(messaging.TopicKeepaliveRaw, event)
 		}
 	} else if event.HasMetrics() {
 		eventBytesSummary.WithLabelValues(metrics.EventTypeLabelMetrics).Observe(float64(len(payload)))
 	}
 
-	return s.bus.Publish(messaging.TopicEventRaw, event)
+	if !s.allowEvent() {
+		// Rate limited events are acknowledged, so that the agent doesn't
+		// retry them
+		s.ackEvent(event)
+		return nil
+	}
+
+	return s.publishEvent(messaging.TopicEventRaw, event)
+}
+
+// publishEvent publishes event to topic, and acknowledges it once published.
+// The events that can't be published are queued in the dead letter queue, to
+// be published again later, and are only acknowledged once published.
+func (s *Session) publishEvent(topic string, event *corev2.Event) error {
+	if err := s.bus.Publish(topic, event); err != nil {
+		sessionErrorCounter.WithLabelValues("bus.Publish").Inc()
+		s.cfg.selfMonitor.Record(selfmonitor.ConditionBusPublish)
+		ack := func() { s.ackEvent(event) }
+		if !s.cfg.deadLetters.add(topic, event, s.cfg.AgentName, err, ack) {
+			return err
+		}
+		logger.WithError(err).WithFields(logrus.Fields{
+			"agent":     s.cfg.AgentName,
+			"namespace": s.cfg.Namespace,
+			"topic":     topic,
+		}).Warn("could not publish event, queued it in the dead letter queue")
+		return nil
+	}
+	s.ackEvent(event)
+	return nil
+}
+
+// ackEvent sends the acknowledgement of event to the agent, if the agent asked
+// for it. The acknowledgement is dropped if the session can't keep up, and
+// the agent sends the event again.
+func (s *Session) ackEvent(event *corev2.Event) {
+	if !s.cfg.EventAcks || len(event.ID) == 0 {
+		return
+	}
+	select {
+	case s.acks <- []byte(event.GetUUID().String()):
+	default:
+	}
+}
+
+// allowEvent returns false if the event rate limit of the agent or of its
+// namespace rejects an event. Both limits are checked before either of them
+// consumes a token, so that the events rejected by one limit don't count
+// against the other. The rejected event is counted, and the agent is asked to
+// back off if the limits are configured to and the agent supports it.
+func (s *Session) allowEvent() bool {
+	now := time.Now()
+	limit := rateLimitAgent
+	limiter := s.agentLimiter
+	agent, ok := reserveEvent(limiter, now)
+	if ok {
+		limit = rateLimitNamespace
+		limiter = s.cfg.namespaceLimiters.Get(s.cfg.Namespace)
+		var namespace *rate.Reservation
+		namespace, ok = reserveEvent(limiter, now)
+		if ok {
+			return true
+		}
+		if namespace != nil {
+			namespace.CancelAt(now)
+		}
+	}
+	if agent != nil {
+		agent.CancelAt(now)
+	}
+	eventsRateLimited.WithLabelValues(s.cfg.Namespace, limit).Inc()
+	logger.WithFields(logrus.Fields{
+		"agent":     s.cfg.AgentName,
+		"namespace": s.cfg.Namespace,
+		"limit":     limit,
+	}).Debug("event rejected by rate limit")
+
+	if s.cfg.EventRateLimits.Backoff && supportsBackoff(s.cfg.AgentVersion) && time.Since(s.lastBackoff) >= backoffInterval {
+		s.lastBackoff = time.Now()
+		select {
+		case s.backoffs <- backoffPayload(limiter.Limit()):
+		default:
+		}
+	}
+	return false
 }
 
 // subscribe adds a subscription to the session for every check subscriptions
@@ -625,14 +965,20 @@ func (s *Session) subscribe(subscriptions []string) error {
 		"namespace": s.cfg.Namespace,
 	})
 
+	// A draining session doesn't receive check requests anymore
+	if atomic.LoadInt32(&s.draining) == 1 {
+		return nil
+	}
+
 	// Get a unique name for the agent, which will be used as the consumer of the
 	// bus, in order to avoid problems with an reconnecting before its session is
 	// ended
 	agent := agentUUID(s.cfg.Namespace, s.cfg.AgentName)
 
 	for _, sub := range subscriptions {
-		// Ignore empty subscriptions
-		if sub == "" {
+		// Ignore empty subscriptions, and the negated and wildcard
+		// subscriptions that are not topics
+		if !isSubscriptionTopic(sub) {
 			continue
 		}
 
@@ -645,7 +991,7 @@ func (s *Session) subscribe(subscriptions []string) error {
 		}
 
 		lager.Debugf("subscribing to %q", sub)
-		subscription, err := s.bus.Subscribe(topic, agent, s)
+		subscription, err := messaging.Supervise(s.ctx, s.bus, topic, agent, s)
 		if err != nil {
 			lager.WithError(err).Errorf("could not subscribe to %q", sub)
 			return err
@@ -669,6 +1015,7 @@ func (s *Session) unsubscribe(subscriptions []string) {
 		"namespace": s.cfg.Namespace,
 	})
 
+	subscriptions = subscriptionTopics(subscriptions)
 	for _, subscriptionName := range subscriptions {
 		topic := messaging.SubscriptionTopic(s.cfg.Namespace, subscriptionName)
 		if subscription, ok := s.subscriptionsMap[topic]; ok {
@@ -714,6 +1061,35 @@ func (s *Session) unsubscribe(subscriptions []string) {
 	ringWG.Wait()
 }
 
+// excludesCheck returns true if a negated subscription of the entity matches
+// one of the subscriptions of the check of request.
+func (s *Session) excludesCheck(request *corev2.CheckRequest) bool {
+	if request.Config == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return messaging.ExcludedBySubscriptions(s.cfg.Subscriptions, request.Config.Subscriptions)
+}
+
+// isSubscriptionTopic returns true if the session subscribes to the topic of
+// the entity subscription sub. Negated and wildcard subscriptions only
+// select checks and have no topic.
+func isSubscriptionTopic(sub string) bool {
+	return sub != "" && !messaging.IsNegatedSubscription(sub) && !messaging.IsSubscriptionPattern(sub)
+}
+
+// subscriptionTopics returns the subscriptions that have a topic.
+func subscriptionTopics(subscriptions []string) []string {
+	var topics []string
+	for _, sub := range subscriptions {
+		if isSubscriptionTopic(sub) {
+			topics = append(topics, sub)
+		}
+	}
+	return topics
+}
+
 func agentUUID(namespace, name string) string {
 	return fmt.Sprintf("%s:%s-%s", namespace, name, uuid.New().String())
 }
@@ -721,6 +1097,26 @@ func agentUUID(namespace, name string) string {
 // diff compares the two given slices and returns the elements that were both
 // added and removed in the new slice, in comparison to the old slice. It relies
 // on both slices being sorted to properly work.
+// admitSubscriptions returns the subscriptions of subscriptions that are set
+// by the admission webhook, or subscriptions if the webhook did not set the
+// subscriptions of the session.
+func (s *Session) admitSubscriptions(subscriptions []string) []string {
+	if s.cfg.admittedSubscriptions == nil {
+		return subscriptions
+	}
+	admitted := make(map[string]struct{}, len(s.cfg.admittedSubscriptions))
+	for _, sub := range s.cfg.admittedSubscriptions {
+		admitted[sub] = struct{}{}
+	}
+	subs := []string{}
+	for _, sub := range subscriptions {
+		if _, ok := admitted[sub]; ok {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
 func diff(old, new []string) ([]string, []string) {
 	var added, removed []string
 	i, j := 0, 0
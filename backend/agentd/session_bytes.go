@@ -0,0 +1,53 @@
+package agentd
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/transport"
+)
+
+const (
+	sessionBytesName = "sensu_go_agentd_session_bytes_total"
+
+	bytesDirectionSent     = "sent"
+	bytesDirectionReceived = "received"
+)
+
+var sessionBytes = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: sessionBytesName,
+		Help: "The total number of bytes of the messages sent to and received from the agents, as written to and read from their connection, by namespace and direction",
+	},
+	[]string{"namespace", "direction"},
+)
+
+// transportBytes returns the bytes of the messages sent and received over
+// the connection of the session, or zero if its transport doesn't count them.
+func (s *Session) transportBytes() (sent, received int64) {
+	counter, ok := s.conn.(transport.ByteCounter)
+	if !ok {
+		return 0, 0
+	}
+	return counter.BytesSent(), counter.BytesReceived()
+}
+
+// countBytesSent adds the bytes sent over the connection since its previous
+// call to the bytes counter of the namespace. It is only called by the
+// sender of the session.
+func (s *Session) countBytesSent() {
+	sent, _ := s.transportBytes()
+	if sent > s.bytesSentCounted {
+		sessionBytes.WithLabelValues(s.cfg.Namespace, bytesDirectionSent).Add(float64(sent - s.bytesSentCounted))
+		s.bytesSentCounted = sent
+	}
+}
+
+// countBytesReceived adds the bytes received over the connection since its
+// previous call to the bytes counter of the namespace. It is only called by
+// the receiver of the session.
+func (s *Session) countBytesReceived() {
+	_, received := s.transportBytes()
+	if received > s.bytesReceivedCounted {
+		sessionBytes.WithLabelValues(s.cfg.Namespace, bytesDirectionReceived).Add(float64(received - s.bytesReceivedCounted))
+		s.bytesReceivedCounted = received
+	}
+}
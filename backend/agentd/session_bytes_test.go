@@ -0,0 +1,60 @@
+package agentd
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sensu/sensu-go/testing/mocktransport"
+)
+
+// bytesTransport counts the bytes set by the test.
+type bytesTransport struct {
+	mocktransport.MockTransport
+	sent     int64
+	received int64
+}
+
+func (b *bytesTransport) BytesSent() int64 {
+	return b.sent
+}
+
+func (b *bytesTransport) BytesReceived() int64 {
+	return b.received
+}
+
+func TestSession_countBytes(t *testing.T) {
+	conn := &bytesTransport{sent: 100, received: 40}
+	s := &Session{
+		cfg:  SessionConfig{AgentName: "agent", Namespace: "bytes"},
+		conn: conn,
+	}
+	s.countBytesSent()
+	s.countBytesReceived()
+
+	// Only the bytes since the previous count are added
+	conn.sent = 150
+	s.countBytesSent()
+	s.countBytesReceived()
+
+	if got, want := testutil.ToFloat64(sessionBytes.WithLabelValues("bytes", bytesDirectionSent)), 150.0; got != want {
+		t.Errorf("bytes sent counter: got %v, want %v", got, want)
+	}
+	if got, want := testutil.ToFloat64(sessionBytes.WithLabelValues("bytes", bytesDirectionReceived)), 40.0; got != want {
+		t.Errorf("bytes received counter: got %v, want %v", got, want)
+	}
+	if info := s.info(); info.BytesSent != 150 || info.BytesReceived != 40 {
+		t.Errorf("bad byte counts: %+v", info)
+	}
+}
+
+func TestSession_countBytesUnsupported(t *testing.T) {
+	s := &Session{
+		cfg:  SessionConfig{AgentName: "agent", Namespace: "bytes-unsupported"},
+		conn: new(mocktransport.MockTransport),
+	}
+	s.countBytesSent()
+	s.countBytesReceived()
+	if info := s.info(); info.BytesSent != 0 || info.BytesReceived != 0 {
+		t.Errorf("bad byte counts: %+v", info)
+	}
+}
@@ -0,0 +1,124 @@
+package agentd
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev3 "github.com/sensu/core/v3"
+)
+
+const (
+	// MaxAgentSessionsAnnotation is the namespace annotation that sets the
+	// maximum number of sessions of the agents of the namespace on each
+	// backend, instead of the namespace session limit of the backend.
+	MaxAgentSessionsAnnotation = "sensu.io/max_agent_sessions"
+
+	// sessionLimitRetryAfter is the minimum delay after which the agents
+	// refused by a session limit are asked to connect again. A random delay
+	// of up to the same duration is added, so that the refused agents don't
+	// all connect again at once.
+	sessionLimitRetryAfter = 30 * time.Second
+
+	sessionsRefusedName = "sensu_go_agentd_sessions_refused_total"
+
+	// sessionLimitBackend and sessionLimitNamespace are the values of the
+	// limit label of the refused sessions counter.
+	sessionLimitBackend   = "backend"
+	sessionLimitNamespace = "namespace"
+)
+
+var sessionsRefused = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: sessionsRefusedName,
+		Help: "The total number of agent connections refused by the session limits of agentd",
+	},
+	[]string{"namespace", "limit"},
+)
+
+// SessionLimits are the limits of the number of concurrent agent sessions of
+// a backend. The agents that connect past a limit are refused, and asked to
+// connect again later. A zero limit disables the limit.
+type SessionLimits struct {
+	// Backend is the limit of the sessions of the backend.
+	Backend int
+
+	// Namespace is the limit of the sessions of the agents of a namespace on
+	// the backend, unless the namespace sets its own limit with the
+	// MaxAgentSessionsAnnotation.
+	Namespace int
+}
+
+// sessionCounts counts the sessions of agentd, to enforce its session
+// limits. The sessions are counted from before their connection is upgraded,
+// so that the concurrent connections of a namespace can't go past its limit.
+type sessionCounts struct {
+	limits SessionLimits
+
+	mu         sync.Mutex
+	total      int
+	namespaces map[string]int
+}
+
+func newSessionCounts(limits SessionLimits) *sessionCounts {
+	return &sessionCounts{
+		limits:     limits,
+		namespaces: make(map[string]int),
+	}
+}
+
+// namespaceLimit returns the session limit of namespace.
+func (c *sessionCounts) namespaceLimit(namespace *corev3.Namespace) int {
+	value, ok := namespace.Metadata.Annotations[MaxAgentSessionsAnnotation]
+	if !ok {
+		return c.limits.Namespace
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		logger.WithField("namespace", namespace.Metadata.Name).Warnf("invalid %s annotation: %q", MaxAgentSessionsAnnotation, value)
+		return c.limits.Namespace
+	}
+	return limit
+}
+
+// acquire counts a new session of the agents of namespace, and returns the
+// function that stops counting it once it ends. It returns the limit that is
+// reached instead, backend or namespace, if the session must be refused.
+func (c *sessionCounts) acquire(namespace *corev3.Namespace) (release func(), limit string) {
+	name := namespace.Metadata.Name
+	namespaceLimit := c.namespaceLimit(namespace)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.limits.Backend > 0 && c.total >= c.limits.Backend:
+		limit = sessionLimitBackend
+	case namespaceLimit > 0 && c.namespaces[name] >= namespaceLimit:
+		limit = sessionLimitNamespace
+	}
+	if limit != "" {
+		sessionsRefused.WithLabelValues(name, limit).Inc()
+		return nil, limit
+	}
+	c.total++
+	c.namespaces[name]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.total--
+			if c.namespaces[name]--; c.namespaces[name] <= 0 {
+				delete(c.namespaces, name)
+			}
+		})
+	}, ""
+}
+
+// sessionRetryAfter returns the delay, in seconds, after which an agent
+// refused by a session limit is asked to connect again.
+func sessionRetryAfter() int {
+	delay := sessionLimitRetryAfter + time.Duration(rand.Int63n(int64(sessionLimitRetryAfter)))
+	return int(delay / time.Second)
+}
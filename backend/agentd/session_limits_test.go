@@ -0,0 +1,63 @@
+package agentd
+
+import (
+	"testing"
+
+	corev3 "github.com/sensu/core/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionCountsAcquire(t *testing.T) {
+	counts := newSessionCounts(SessionLimits{Backend: 3, Namespace: 2})
+	acme := corev3.FixtureNamespace("acme")
+	other := corev3.FixtureNamespace("other")
+
+	release, limit := counts.acquire(acme)
+	require.Empty(t, limit)
+	_, limit = counts.acquire(acme)
+	require.Empty(t, limit)
+
+	// The namespace limit is reached, but not the backend limit
+	_, limit = counts.acquire(acme)
+	assert.Equal(t, sessionLimitNamespace, limit)
+	_, limit = counts.acquire(other)
+	require.Empty(t, limit)
+
+	// The backend limit is reached
+	_, limit = counts.acquire(other)
+	assert.Equal(t, sessionLimitBackend, limit)
+
+	// Releasing a session twice only releases it once
+	release()
+	release()
+	_, limit = counts.acquire(acme)
+	require.Empty(t, limit)
+	_, limit = counts.acquire(acme)
+	assert.Equal(t, sessionLimitBackend, limit)
+}
+
+func TestSessionCountsNamespaceLimit(t *testing.T) {
+	counts := newSessionCounts(SessionLimits{Namespace: 2})
+
+	tests := []struct {
+		name       string
+		annotation string
+		want       int
+	}{
+		{name: "default", want: 2},
+		{name: "annotation", annotation: "10", want: 10},
+		{name: "unlimited", annotation: "0", want: 0},
+		{name: "invalid", annotation: "ten", want: 2},
+		{name: "negative", annotation: "-1", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace := corev3.FixtureNamespace("acme")
+			if tt.annotation != "" {
+				namespace.Metadata.Annotations = map[string]string{MaxAgentSessionsAnnotation: tt.annotation}
+			}
+			assert.Equal(t, tt.want, counts.namespaceLimit(namespace))
+		})
+	}
+}
@@ -10,14 +10,17 @@ import (
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/google/uuid"
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/agent"
 	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/selfmonitor"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 	"github.com/sensu/sensu-go/handler"
 	"github.com/sensu/sensu-go/testing/mockbus"
+	"github.com/sensu/sensu-go/testing/mockresource"
 	"github.com/sensu/sensu-go/testing/mockstore"
 	"github.com/sensu/sensu-go/testing/mocktransport"
 	"github.com/sensu/sensu-go/transport"
@@ -450,6 +453,12 @@ func TestSession_subscribe(t *testing.T) {
 			name:          "empty subscriptions are ignored",
 			subscriptions: []string{""},
 		},
+		{
+			name:             "negated and wildcard subscriptions are ignored",
+			subscriptions:    []string{"!canary", "linux-*"},
+			subscriptionsMap: map[string]subscription{},
+			want:             map[string]subscription{},
+		},
 		{
 			name:          "already subscribed subscriptions are ignored",
 			subscriptions: []string{"foo"},
@@ -593,6 +602,51 @@ func TestSession_unsubscribe(t *testing.T) {
 	}
 }
 
+func TestSession_excludesCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		subscriptions []string
+		request       *corev2.CheckRequest
+		want          bool
+	}{
+		{
+			name:          "no negated subscriptions",
+			subscriptions: []string{"linux", "canary"},
+			request:       &corev2.CheckRequest{Config: &corev2.CheckConfig{Subscriptions: []string{"canary"}}},
+		},
+		{
+			name:          "negated subscription matches a check subscription",
+			subscriptions: []string{"linux", "!canary"},
+			request:       &corev2.CheckRequest{Config: &corev2.CheckConfig{Subscriptions: []string{"linux", "canary"}}},
+			want:          true,
+		},
+		{
+			name:          "negated wildcard subscription matches a check subscription",
+			subscriptions: []string{"linux", "!canary-*"},
+			request:       &corev2.CheckRequest{Config: &corev2.CheckConfig{Subscriptions: []string{"canary-eu"}}},
+			want:          true,
+		},
+		{
+			name:          "invalid negated subscriptions are ignored",
+			subscriptions: []string{"linux", "!*"},
+			request:       &corev2.CheckRequest{Config: &corev2.CheckConfig{Subscriptions: []string{"linux"}}},
+		},
+		{
+			name:          "check requests without config are not excluded",
+			subscriptions: []string{"!canary"},
+			request:       &corev2.CheckRequest{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Session{cfg: SessionConfig{Subscriptions: tt.subscriptions}}
+			if got := s.excludesCheck(tt.request); got != tt.want {
+				t.Errorf("Session.excludesCheck() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_diff(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -769,3 +823,100 @@ func TestSession_receiver(t *testing.T) {
 		})
 	}
 }
+
+func TestSession_handleEventAck(t *testing.T) {
+	event := corev2.FixtureEvent("entity", "check")
+	id := uuid.New()
+	event.ID = id[:]
+	payload, err := proto.Marshal(event)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		eventAcks  bool
+		publishErr error
+		wantAck    bool
+	}{
+		{
+			name:      "published events are acknowledged",
+			eventAcks: true,
+			wantAck:   true,
+		},
+		{
+			name:       "events that fail to be published are not acknowledged",
+			eventAcks:  true,
+			publishErr: errors.New("error"),
+		},
+		{
+			name: "events are not acknowledged unless the agent asks for it",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &mockbus.MockBus{}
+			bus.On("Publish", messaging.TopicEventRaw, mock.Anything).Return(tt.publishErr)
+			s := &Session{
+				cfg:       SessionConfig{Namespace: "default", EventAcks: tt.eventAcks},
+				bus:       bus,
+				unmarshal: proto.Unmarshal,
+				acks:      make(chan []byte, 1),
+			}
+			err := s.handleEvent(context.Background(), payload)
+			if tt.publishErr != nil {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			select {
+			case ack := <-s.acks:
+				if !tt.wantAck {
+					t.Fatalf("unexpected acknowledgement %q", ack)
+				}
+				assert.Equal(t, id.String(), string(ack))
+			default:
+				if tt.wantAck {
+					t.Fatal("expected an acknowledgement")
+				}
+			}
+		})
+	}
+}
+
+func TestSessionPublishEventSelfMonitor(t *testing.T) {
+	bus := &mockbus.MockBus{}
+	bus.On("Publish", messaging.TopicEventRaw, mock.Anything).Return(errors.New("bus is down"))
+	receiver := &mockresource.EventReceiver{}
+	monitor, err := selfmonitor.New(selfmonitor.Config{EventReceiver: receiver, Interval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	s := &Session{
+		cfg: SessionConfig{
+			AgentName:   "entity",
+			Namespace:   "default",
+			selfMonitor: monitor,
+		},
+		bus: bus,
+	}
+	require.Error(t, s.publishEvent(messaging.TopicEventRaw, corev2.FixtureEvent("entity", "check")))
+
+	require.NoError(t, monitor.Start())
+	defer monitor.Stop()
+	assert.Eventually(t, func() bool {
+		for _, event := range receiver.Events(selfmonitor.ConditionBusPublish) {
+			if event.Status == 1 {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestActiveSessions(t *testing.T) {
+	before := ActiveSessions()
+	sessionCounter.WithLabelValues("acme").Add(2)
+	sessionCounter.WithLabelValues("default").Inc()
+	defer func() {
+		sessionCounter.WithLabelValues("acme").Sub(2)
+		sessionCounter.WithLabelValues("default").Dec()
+	}()
+	assert.Equal(t, before+3, ActiveSessions())
+}
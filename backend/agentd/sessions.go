@@ -0,0 +1,106 @@
+package agentd
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/apid/routers"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sirupsen/logrus"
+)
+
+// Sessions lists, disconnects and drains the agent sessions of this backend,
+// manages the dead letter queue of their events, and lists the entity config
+// updates they sent.
+type Sessions struct {
+	// DrainWindow is the duration over which the sessions are closed when
+	// they are drained.
+	DrainWindow time.Duration
+
+	// DeadLetterQueue is the dead letter queue of the events of the
+	// sessions.
+	DeadLetterQueue *DeadLetterQueue
+
+	// ConfigAuditLog records the entity config updates sent by the
+	// sessions.
+	ConfigAuditLog *ConfigAuditLog
+}
+
+// Sessions returns the agent sessions of namespace connected to this backend,
+// sorted by agent name.
+func (Sessions) Sessions(ctx context.Context, namespace string) ([]routers.AgentSession, error) {
+	sessions := []routers.AgentSession{}
+	sessionQueues.Range(func(key, _ interface{}) bool {
+		s := key.(*Session)
+		if s.cfg.Namespace != namespace {
+			return true
+		}
+		sessions = append(sessions, s.info())
+		return true
+	})
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].AgentName < sessions[j].AgentName
+	})
+	return sessions, nil
+}
+
+// Disconnect closes the sessions of the agent name of namespace connected to
+// this backend. The agent is sent a close message, and its session is given
+// closeGracePeriod to end before its connection is closed. The agent is free
+// to reconnect, to this backend or to another one.
+func (Sessions) Disconnect(ctx context.Context, namespace, name string) error {
+	var found bool
+	sessionQueues.Range(func(key, _ interface{}) bool {
+		s := key.(*Session)
+		if s.cfg.Namespace != namespace || s.cfg.AgentName != name {
+			return true
+		}
+		found = true
+		s.disconnect()
+		return true
+	})
+	if !found {
+		return &store.ErrNotFound{Key: name}
+	}
+	return nil
+}
+
+// disconnect asks the agent to close the session, and stops the session.
+func (s *Session) disconnect() {
+	lager := logger.WithFields(logrus.Fields{
+		"agent":     s.cfg.AgentName,
+		"namespace": s.cfg.Namespace,
+	})
+	lager.Info("disconnecting agent session")
+	if err := s.conn.SendCloseMessage(); err != nil {
+		websocketErrorCounter.WithLabelValues("send", "CloseMessage").Inc()
+		lager.WithError(err).Warn("error sending close message")
+	}
+	s.cancel()
+}
+
+// info returns the description of the session.
+func (s *Session) info() routers.AgentSession {
+	s.mu.Lock()
+	subscriptions := append([]string(nil), s.cfg.Subscriptions...)
+	s.mu.Unlock()
+	bytesSent, bytesReceived := s.transportBytes()
+	return routers.AgentSession{
+		AgentName:        s.cfg.AgentName,
+		Namespace:        s.cfg.Namespace,
+		AgentAddr:        s.cfg.AgentAddr,
+		AgentVersion:     s.cfg.AgentVersion,
+		ProtocolVersion:  s.cfg.ProtocolVersion,
+		Subscriptions:    subscriptions,
+		ContentType:      s.cfg.ContentType,
+		ConnectedAt:      s.connectedAt,
+		MessagesReceived: atomic.LoadInt64(&s.messagesReceived),
+		MessagesSent:     atomic.LoadInt64(&s.messagesSent),
+		BytesReceived:    bytesReceived,
+		BytesSent:        bytesSent,
+		RTT:              s.lastRTT(),
+		MissedPongs:      atomic.LoadInt64(&s.missedPongs),
+	}
+}
@@ -0,0 +1,68 @@
+package agentd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mocktransport"
+)
+
+func TestSessions(t *testing.T) {
+	b := &Session{cfg: SessionConfig{AgentName: "b", Namespace: "default", Subscriptions: []string{"linux"}}}
+	a := &Session{cfg: SessionConfig{AgentName: "a", Namespace: "default"}, messagesReceived: 2, messagesSent: 3}
+	other := &Session{cfg: SessionConfig{AgentName: "c", Namespace: "other"}}
+	for _, s := range []*Session{b, a, other} {
+		sessionQueues.Store(s, struct{}{})
+		defer sessionQueues.Delete(s)
+	}
+
+	sessions, err := Sessions{}.Sessions(context.Background(), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(sessions), 2; got != want {
+		t.Fatalf("got %d sessions, want %d", got, want)
+	}
+	if sessions[0].AgentName != "a" || sessions[1].AgentName != "b" {
+		t.Errorf("sessions not sorted by agent name: %v", sessions)
+	}
+	if sessions[0].MessagesReceived != 2 || sessions[0].MessagesSent != 3 {
+		t.Errorf("bad message counts: %+v", sessions[0])
+	}
+	if got := sessions[1].Subscriptions; len(got) != 1 || got[0] != "linux" {
+		t.Errorf("bad subscriptions: %v", got)
+	}
+}
+
+func TestSessionsDisconnect(t *testing.T) {
+	conn := new(mocktransport.MockTransport)
+	conn.On("SendCloseMessage").Return(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &Session{
+		cfg:    SessionConfig{AgentName: "a", Namespace: "default"},
+		conn:   conn,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	sessionQueues.Store(s, struct{}{})
+	defer sessionQueues.Delete(s)
+
+	err := Sessions{}.Disconnect(context.Background(), "other", "a")
+	if _, ok := err.(*store.ErrNotFound); !ok {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("session of another namespace was stopped")
+	}
+
+	err = Sessions{}.Disconnect(context.Background(), "default", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.AssertCalled(t, "SendCloseMessage")
+	if ctx.Err() == nil {
+		t.Error("session was not stopped")
+	}
+}
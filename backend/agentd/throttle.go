@@ -0,0 +1,106 @@
+package agentd
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/transport"
+)
+
+const (
+	throttleMessagesName = "sensu_go_agentd_throttle_messages_total"
+
+	// throttleInterval is the interval at which the saturation of the backend
+	// is measured.
+	throttleInterval = time.Second
+
+	// throttleSeconds is the duration of the throttle messages, which are
+	// sent again while the backend stays saturated.
+	throttleSeconds = 5
+)
+
+var throttleMessages = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: throttleMessagesName,
+		Help: "The total number of throttle messages sent to agents while the backend is saturated",
+	},
+)
+
+// Saturation measures the saturation of the backend, between 0 and 1.
+type Saturation interface {
+	Score() float64
+}
+
+// EventThrottle throttles the events of the agents while the backend is
+// saturated, so that it sheds load before its queues are full.
+type EventThrottle struct {
+	// Saturation is the saturation of the backend from which the agents are
+	// throttled. The agents are never throttled if it is zero.
+	Saturation float64
+
+	// Rate is the number of events per second sent by each agent while it is
+	// throttled.
+	Rate float64
+
+	// Monitor measures the saturation of the backend.
+	Monitor Saturation
+}
+
+func (t EventThrottle) enabled() bool {
+	return t.Saturation > 0 && t.Rate > 0 && t.Monitor != nil
+}
+
+// runThrottle asks the agents that handle throttle messages to throttle their
+// events, for as long as the backend is saturated.
+func (a *Agentd) runThrottle() {
+	if !a.throttle.enabled() {
+		return
+	}
+	payload, err := json.Marshal(transport.Throttle{Rate: a.throttle.Rate, Seconds: throttleSeconds})
+	if err != nil {
+		logger.WithError(err).Error("could not serialize throttle message")
+		return
+	}
+	ticker := time.NewTicker(throttleInterval)
+	defer ticker.Stop()
+	var saturated bool
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		score := a.throttle.Monitor.Score()
+		if score < a.throttle.Saturation {
+			if saturated {
+				logger.WithField("saturation", score).Info("backend no longer saturated, agent events no longer throttled")
+			}
+			saturated = false
+			continue
+		}
+		if !saturated {
+			logger.WithField("saturation", score).Warn("backend saturated, throttling agent events")
+		}
+		saturated = true
+		throttleSessions(payload)
+	}
+}
+
+// throttleSessions sends the throttle message payload to the sessions whose
+// agent handles them.
+func throttleSessions(payload []byte) {
+	sessionQueues.Range(func(key, _ interface{}) bool {
+		s := key.(*Session)
+		if !s.cfg.Throttle {
+			return true
+		}
+		select {
+		case s.throttles <- payload:
+			throttleMessages.Inc()
+		default:
+			// The previous throttle message is not sent yet
+		}
+		return true
+	})
+}
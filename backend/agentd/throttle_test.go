@@ -0,0 +1,65 @@
+package agentd
+
+import (
+	"testing"
+)
+
+type saturation float64
+
+func (s saturation) Score() float64 {
+	return float64(s)
+}
+
+func TestEventThrottleEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		throttle EventThrottle
+		want     bool
+	}{
+		{name: "disabled", throttle: EventThrottle{Rate: 1, Monitor: saturation(1)}},
+		{name: "no rate", throttle: EventThrottle{Saturation: 0.9, Monitor: saturation(1)}},
+		{name: "no monitor", throttle: EventThrottle{Saturation: 0.9, Rate: 1}},
+		{name: "enabled", throttle: EventThrottle{Saturation: 0.9, Rate: 1, Monitor: saturation(1)}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.throttle.enabled(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThrottleSessions(t *testing.T) {
+	throttled := &Session{
+		cfg:       SessionConfig{AgentName: "throttled", Throttle: true},
+		throttles: make(chan []byte, 1),
+	}
+	legacy := &Session{
+		cfg:       SessionConfig{AgentName: "legacy"},
+		throttles: make(chan []byte, 1),
+	}
+	for _, s := range []*Session{throttled, legacy} {
+		sessionQueues.Store(s, struct{}{})
+		defer sessionQueues.Delete(s)
+	}
+
+	throttleSessions([]byte("a"))
+	// the sessions that were not sent the previous message yet are skipped
+	throttleSessions([]byte("b"))
+
+	select {
+	case payload := <-throttled.throttles:
+		if got, want := string(payload), "a"; got != want {
+			t.Errorf("got payload %q, want %q", got, want)
+		}
+	default:
+		t.Fatal("expected a throttle message")
+	}
+	if len(throttled.throttles) != 0 {
+		t.Error("expected a single throttle message")
+	}
+	if len(legacy.throttles) != 0 {
+		t.Error("agents that don't handle throttle messages should not be sent any")
+	}
+}
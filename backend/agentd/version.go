@@ -0,0 +1,204 @@
+package agentd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	corev3 "github.com/sensu/core/v3"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+const (
+	// AgentVersionPolicyAnnotation is the cluster config annotation that sets
+	// what agentd does with agents outside the supported version skew: warn,
+	// reject or off. Agents are warned about by default.
+	AgentVersionPolicyAnnotation = "sensu.io/agent_version_policy"
+
+	// AgentVersionSkewAnnotation is the cluster config annotation that sets
+	// the number of minor versions an agent can be behind its backend.
+	AgentVersionSkewAnnotation = "sensu.io/agent_version_skew"
+
+	// AllowAgentVersionSkewAnnotation is the namespace annotation that lets
+	// agents of any version connect to the namespace, when it is "true".
+	AllowAgentVersionSkewAnnotation = "sensu.io/allow_agent_version_skew"
+
+	AgentVersionPolicyWarn   = "warn"
+	AgentVersionPolicyReject = "reject"
+	AgentVersionPolicyOff    = "off"
+
+	// DefaultAgentVersionSkew is the number of minor versions an agent can be
+	// behind its backend, unless the cluster config sets it.
+	DefaultAgentVersionSkew = 2
+
+	// unknownAgentVersion is the version label of agents that don't send
+	// their version, or send an invalid one.
+	unknownAgentVersion = "unknown"
+
+	// versionPolicyTTL is how long the version policy is cached for.
+	versionPolicyTTL = 30 * time.Second
+
+	agentVersionSessionsName = "sensu_go_agent_sessions_by_version"
+)
+
+var (
+	agentVersionSessions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: agentVersionSessionsName,
+			Help: "Number of active agent sessions on this backend by agent version",
+		},
+		[]string{"namespace", "version"},
+	)
+
+	// activeSessionVersions counts the active sessions of this backend by
+	// namespace and agent version.
+	activeSessionVersions = sessionVersions{counts: make(map[string]map[string]int)}
+)
+
+type sessionVersions struct {
+	mu     sync.Mutex
+	counts map[string]map[string]int
+}
+
+// add adds delta sessions of agentVersion to namespace.
+func (s *sessionVersions) add(namespace, agentVersion string, delta int) {
+	label := agentVersionLabel(agentVersion)
+	agentVersionSessions.WithLabelValues(namespace, label).Add(float64(delta))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions, ok := s.counts[namespace]
+	if !ok {
+		versions = make(map[string]int)
+		s.counts[namespace] = versions
+	}
+	versions[label] += delta
+	if versions[label] <= 0 {
+		delete(versions, label)
+	}
+	if len(versions) == 0 {
+		delete(s.counts, namespace)
+	}
+}
+
+// SessionVersions reports the agent versions of the active sessions of this
+// backend.
+type SessionVersions struct{}
+
+// SessionVersions returns the number of active sessions of this backend by
+// namespace and agent version.
+func (SessionVersions) SessionVersions() map[string]map[string]int {
+	activeSessionVersions.mu.Lock()
+	defer activeSessionVersions.mu.Unlock()
+	result := make(map[string]map[string]int, len(activeSessionVersions.counts))
+	for namespace, versions := range activeSessionVersions.counts {
+		result[namespace] = make(map[string]int, len(versions))
+		for version, count := range versions {
+			result[namespace][version] = count
+		}
+	}
+	return result
+}
+
+// versionPolicy is the policy of agentd for agents outside the supported
+// version skew.
+type versionPolicy struct {
+	action string
+	skew   uint64
+}
+
+// versionPolicyFromClusterConfig returns the version policy set by the
+// annotations of config, which can be nil.
+func versionPolicyFromClusterConfig(config *corev3.ClusterConfig) (versionPolicy, error) {
+	policy := versionPolicy{action: AgentVersionPolicyWarn, skew: DefaultAgentVersionSkew}
+	if config == nil || config.Metadata == nil {
+		return policy, nil
+	}
+	annotations := config.Metadata.Annotations
+	switch action := annotations[AgentVersionPolicyAnnotation]; action {
+	case "":
+	case AgentVersionPolicyWarn, AgentVersionPolicyReject, AgentVersionPolicyOff:
+		policy.action = action
+	default:
+		return policy, fmt.Errorf("invalid %s annotation: %q", AgentVersionPolicyAnnotation, action)
+	}
+	if value, ok := annotations[AgentVersionSkewAnnotation]; ok {
+		skew, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return policy, fmt.Errorf("invalid %s annotation: %q", AgentVersionSkewAnnotation, value)
+		}
+		policy.skew = skew
+	}
+	return policy, nil
+}
+
+// checkAgentVersion returns an error if the agent version is outside of the
+// supported skew of the backend version: agents must have the major version
+// of the backend, must not be newer than the backend, and can be at most skew
+// minor versions behind it. Versions that can't be parsed, such as the
+// versions of development builds, are not checked.
+func checkAgentVersion(backendVersion, agentVersion string, skew uint64) error {
+	backend, err := semver.ParseTolerant(backendVersion)
+	if err != nil {
+		return nil
+	}
+	agent, err := semver.ParseTolerant(agentVersion)
+	if err != nil {
+		return nil
+	}
+	if agent.Major != backend.Major {
+		return fmt.Errorf("agent version %s has a different major version than backend version %s", agent, backend)
+	}
+	if agent.Minor > backend.Minor {
+		return fmt.Errorf("agent version %s is newer than backend version %s", agent, backend)
+	}
+	if backend.Minor-agent.Minor > skew {
+		return fmt.Errorf("agent version %s is more than %d minor versions behind backend version %s", agent, skew, backend)
+	}
+	return nil
+}
+
+// agentVersionLabel returns the version label of an agent version.
+func agentVersionLabel(agentVersion string) string {
+	v, err := semver.ParseTolerant(agentVersion)
+	if err != nil {
+		return unknownAgentVersion
+	}
+	return v.String()
+}
+
+// versionPolicyCache caches the version policy of the cluster config.
+type versionPolicyCache struct {
+	store   storev2.Interface
+	mu      sync.Mutex
+	policy  versionPolicy
+	expires time.Time
+}
+
+// Get returns the version policy of the cluster. The default policy is used
+// if the cluster config can't be read or is invalid.
+func (c *versionPolicyCache) Get(ctx context.Context) versionPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.expires) {
+		return c.policy
+	}
+	var config *corev3.ClusterConfig
+	configs, err := storev2.Of[*corev3.ClusterConfig](c.store).List(ctx, storev2.ID{}, nil)
+	if err != nil {
+		logger.WithError(err).Error("error reading cluster config, using default agent version policy")
+	} else if len(configs) > 0 {
+		config = configs[0]
+	}
+	policy, err := versionPolicyFromClusterConfig(config)
+	if err != nil {
+		logger.WithError(err).Error("using default agent version policy")
+	}
+	c.policy = policy
+	c.expires = time.Now().Add(versionPolicyTTL)
+	return c.policy
+}
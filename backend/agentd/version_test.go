@@ -0,0 +1,100 @@
+package agentd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/testing/mockstore"
+)
+
+func TestCheckAgentVersion(t *testing.T) {
+	tests := []struct {
+		backend string
+		agent   string
+		wantErr bool
+	}{
+		{backend: "7.2.0", agent: "7.2.0"},
+		{backend: "7.2.0", agent: "7.0.3"},
+		{backend: "7.2.0", agent: "6.9.0", wantErr: true},
+		{backend: "7.3.0", agent: "7.0.0", wantErr: true},
+		{backend: "7.2.0", agent: "7.3.0", wantErr: true},
+		{backend: "7.2.0", agent: "v7.2.1"},
+		{backend: "(devel)", agent: "6.0.0"},
+		{backend: "7.2.0", agent: "dev"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend+"/"+tt.agent, func(t *testing.T) {
+			err := checkAgentVersion(tt.backend, tt.agent, 2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkAgentVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVersionPolicyFromClusterConfig(t *testing.T) {
+	policy, err := versionPolicyFromClusterConfig(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, versionPolicy{action: AgentVersionPolicyWarn, skew: DefaultAgentVersionSkew}, policy)
+
+	config := &corev3.ClusterConfig{Metadata: &corev2.ObjectMeta{
+		Annotations: map[string]string{
+			AgentVersionPolicyAnnotation: AgentVersionPolicyReject,
+			AgentVersionSkewAnnotation:   "1",
+		},
+	}}
+	policy, err = versionPolicyFromClusterConfig(config)
+	assert.NoError(t, err)
+	assert.Equal(t, versionPolicy{action: AgentVersionPolicyReject, skew: 1}, policy)
+
+	config.Metadata.Annotations[AgentVersionPolicyAnnotation] = "ignore"
+	_, err = versionPolicyFromClusterConfig(config)
+	assert.Error(t, err)
+}
+
+func TestAgentdCheckAgentVersion(t *testing.T) {
+	config := &corev3.ClusterConfig{Metadata: &corev2.ObjectMeta{
+		Name: "cluster",
+		Annotations: map[string]string{
+			AgentVersionPolicyAnnotation: AgentVersionPolicyReject,
+		},
+	}}
+	stor := &mockstore.V2MockStore{}
+	cstore := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cstore)
+	cstore.On("List", mock.Anything, mock.Anything, mock.Anything).
+		Return(mockstore.WrapList[*corev3.ClusterConfig]{config}, nil)
+
+	a := &Agentd{
+		backendVersion:  "7.5.0",
+		versionPolicies: &versionPolicyCache{store: stor},
+	}
+	namespace := corev3.FixtureNamespace("default")
+	assert.NoError(t, a.checkAgentVersion(context.Background(), namespace, "7.4.0"))
+	assert.NoError(t, a.checkAgentVersion(context.Background(), namespace, ""))
+	assert.Error(t, a.checkAgentVersion(context.Background(), namespace, "7.1.0"))
+
+	exempt := corev3.FixtureNamespace("legacy")
+	exempt.Metadata.Annotations = map[string]string{AllowAgentVersionSkewAnnotation: "true"}
+	assert.NoError(t, a.checkAgentVersion(context.Background(), exempt, "7.1.0"))
+
+	// the policy is cached
+	cstore.AssertNumberOfCalls(t, "List", 1)
+}
+
+func TestSessionVersions(t *testing.T) {
+	activeSessionVersions.add("versions", "7.1.0", 1)
+	activeSessionVersions.add("versions", "v7.1.0", 1)
+	activeSessionVersions.add("versions", "", 1)
+	assert.Equal(t, map[string]int{"7.1.0": 2, "unknown": 1}, SessionVersions{}.SessionVersions()["versions"])
+
+	activeSessionVersions.add("versions", "7.1.0", -2)
+	activeSessionVersions.add("versions", "", -1)
+	_, ok := SessionVersions{}.SessionVersions()["versions"]
+	assert.False(t, ok)
+}
@@ -138,6 +138,21 @@ func (e *EntityClient) ListEntities(ctx context.Context, pred *store.SelectionPr
 	return slice, nil
 }
 
+// CountEntities counts the entities in a namespace that match the entity
+// selector of ctx, if authorized.
+func (e *EntityClient) CountEntities(ctx context.Context) (int, error) {
+	attrs := entityAuthAttributes(ctx, "list", "")
+	if err := authorize(ctx, e.auth, attrs); err != nil {
+		return 0, err
+	}
+	return e.entityStore.CountEntities(ctx)
+}
+
+// EntityStoreSupportsFiltering proxies to store client
+func (e *EntityClient) EntityStoreSupportsFiltering(ctx context.Context) bool {
+	return e.entityStore.EntityStoreSupportsFiltering(ctx)
+}
+
 func entityAuthAttributes(ctx context.Context, verb, name string) *authorization.Attributes {
 	return &authorization.Attributes{
 		APIGroup:     "core",
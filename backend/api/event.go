@@ -3,8 +3,10 @@ package api
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/request"
 	"github.com/sensu/sensu-go/backend/authorization"
 	"github.com/sensu/sensu-go/backend/messaging"
 	"github.com/sensu/sensu-go/backend/store"
@@ -56,6 +58,8 @@ func (e *EventClient) UpdateEvent(ctx context.Context, event *corev2.Event) erro
 		event.Check.CreatedBy = claims.StandardClaims.Subject
 		event.Entity.CreatedBy = claims.StandardClaims.Subject
 	}
+	store.SetEventReceived(event, time.Now())
+	store.SetEventRequestID(event, request.RequestIDFromContext(ctx))
 	// Update the event through eventd
 	return e.bus.Publish(messaging.TopicEventRaw, event)
 }
@@ -10,8 +10,10 @@ import (
 
 // EntityClient is an interface generated for "github.com/sensu/sensu-go/backend/api.EntityClient".
 type EntityClient interface {
+	CountEntities(context.Context) (int, error)
 	CreateEntity(context.Context, *v2.Entity) error
 	DeleteEntity(context.Context, string) error
+	EntityStoreSupportsFiltering(context.Context) bool
 	FetchEntity(context.Context, string) (*v2.Entity, error)
 	ListEntities(context.Context, *store.SelectionPredicate) ([]*v2.Entity, error)
 	UpdateEntity(context.Context, *v2.Entity) error
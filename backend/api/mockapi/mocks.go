@@ -308,6 +308,21 @@ func (m *MockEntityClient) EXPECT() *MockEntityClientMockRecorder {
 	return m.recorder
 }
 
+// CountEntities mocks base method.
+func (m *MockEntityClient) CountEntities(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountEntities", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountEntities indicates an expected call of CountEntities.
+func (mr *MockEntityClientMockRecorder) CountEntities(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountEntities", reflect.TypeOf((*MockEntityClient)(nil).CountEntities), arg0)
+}
+
 // CreateEntity mocks base method.
 func (m *MockEntityClient) CreateEntity(arg0 context.Context, arg1 *v2.Entity) error {
 	m.ctrl.T.Helper()
@@ -336,6 +351,20 @@ func (mr *MockEntityClientMockRecorder) DeleteEntity(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntity", reflect.TypeOf((*MockEntityClient)(nil).DeleteEntity), arg0, arg1)
 }
 
+// EntityStoreSupportsFiltering mocks base method.
+func (m *MockEntityClient) EntityStoreSupportsFiltering(arg0 context.Context) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EntityStoreSupportsFiltering", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// EntityStoreSupportsFiltering indicates an expected call of EntityStoreSupportsFiltering.
+func (mr *MockEntityClientMockRecorder) EntityStoreSupportsFiltering(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EntityStoreSupportsFiltering", reflect.TypeOf((*MockEntityClient)(nil).EntityStoreSupportsFiltering), arg0)
+}
+
 // FetchEntity mocks base method.
 func (m *MockEntityClient) FetchEntity(arg0 context.Context, arg1 string) (*v2.Entity, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,110 @@
+package actions
+
+import (
+	"context"
+	"sort"
+
+	"github.com/blang/semver/v4"
+	corev3 "github.com/sensu/core/v3"
+
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// unknownAgentVersion is the version of agents that don't report a valid
+// version.
+const unknownAgentVersion = "unknown"
+
+// SessionVersionCounter counts the agent sessions of a backend by namespace
+// and agent version.
+type SessionVersionCounter interface {
+	SessionVersions() map[string]map[string]int
+}
+
+// AgentVersionCount is the number of agents of a namespace that run a version
+// of the agent.
+type AgentVersionCount struct {
+	Namespace string `json:"namespace"`
+	Version   string `json:"version"`
+
+	// Entities is the number of agent entities that last reported the
+	// version.
+	Entities int `json:"entities"`
+
+	// Sessions is the number of agents of the version connected to the
+	// backend that served the request.
+	Sessions int `json:"sessions"`
+}
+
+// AgentVersionsController summarizes the agent versions of the fleet, so that
+// operators can plan upgrades.
+type AgentVersionsController struct {
+	store    storev2.Interface
+	sessions SessionVersionCounter
+}
+
+// NewAgentVersionsController returns a new AgentVersionsController
+func NewAgentVersionsController(store storev2.Interface, sessions SessionVersionCounter) AgentVersionsController {
+	return AgentVersionsController{
+		store:    store,
+		sessions: sessions,
+	}
+}
+
+// List returns the agent versions of every namespace, from the states of
+// the entities and from the sessions of the backend, sorted by namespace and
+// version.
+func (c AgentVersionsController) List(ctx context.Context) ([]AgentVersionCount, error) {
+	type key struct{ namespace, version string }
+	counts := make(map[key]*AgentVersionCount)
+	get := func(namespace, version string) *AgentVersionCount {
+		k := key{namespace: namespace, version: version}
+		count, ok := counts[k]
+		if !ok {
+			count = &AgentVersionCount{Namespace: namespace, Version: version}
+			counts[k] = count
+		}
+		return count
+	}
+
+	states, err := storev2.Of[*corev3.EntityState](c.store).List(ctx, storev2.ID{}, nil)
+	if err != nil {
+		return nil, NewError(InternalErr, err)
+	}
+	for _, state := range states {
+		// Proxy entities don't report an agent version
+		if state.SensuAgentVersion == "" {
+			continue
+		}
+		get(state.Metadata.Namespace, normalizeAgentVersion(state.SensuAgentVersion)).Entities++
+	}
+
+	if c.sessions != nil {
+		for namespace, versions := range c.sessions.SessionVersions() {
+			for version, n := range versions {
+				get(namespace, version).Sessions += n
+			}
+		}
+	}
+
+	result := make([]AgentVersionCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+// normalizeAgentVersion returns the version of agentVersion in the format of
+// the session versions.
+func normalizeAgentVersion(agentVersion string) string {
+	v, err := semver.ParseTolerant(agentVersion)
+	if err != nil {
+		return unknownAgentVersion
+	}
+	return v.String()
+}
@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev3 "github.com/sensu/core/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/sensu/sensu-go/testing/mockstore"
+)
+
+type testSessionVersions map[string]map[string]int
+
+func (s testSessionVersions) SessionVersions() map[string]map[string]int {
+	return s
+}
+
+func TestAgentVersionsList(t *testing.T) {
+	newState := func(name, namespace, version string) *corev3.EntityState {
+		state := corev3.FixtureEntityState(name)
+		state.Metadata.Namespace = namespace
+		state.SensuAgentVersion = version
+		return state
+	}
+	states := []*corev3.EntityState{
+		newState("a", "default", "7.1.0"),
+		newState("b", "default", "v7.1.0"),
+		newState("c", "default", "7.0.2"),
+		newState("d", "prod", "7.1.0"),
+		newState("proxy", "prod", ""),
+	}
+	sessions := testSessionVersions{
+		"default": {"7.1.0": 1},
+		"dev":     {"unknown": 2},
+	}
+
+	sv2 := new(mockstore.V2MockStore)
+	es := new(mockstore.EntityStateStore)
+	sv2.On("GetEntityStateStore").Return(es)
+	es.On("List", mock.Anything, "", mock.Anything).Return(states, nil)
+
+	got, err := NewAgentVersionsController(sv2, sessions).List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []AgentVersionCount{
+		{Namespace: "default", Version: "7.0.2", Entities: 1},
+		{Namespace: "default", Version: "7.1.0", Entities: 2, Sessions: 1},
+		{Namespace: "dev", Version: "unknown", Sessions: 2},
+		{Namespace: "prod", Version: "7.1.0", Entities: 1},
+	}, got)
+}
+
+func TestAgentVersionsListError(t *testing.T) {
+	sv2 := new(mockstore.V2MockStore)
+	es := new(mockstore.EntityStateStore)
+	sv2.On("GetEntityStateStore").Return(es)
+	es.On("List", mock.Anything, mock.Anything, mock.Anything).Return([]*corev3.EntityState(nil), errors.New("oh noes"))
+
+	_, err := NewAgentVersionsController(sv2, nil).List(context.Background())
+	inferErr, ok := err.(Error)
+	assert.True(t, ok)
+	assert.Equal(t, InternalErr, inferErr.Code)
+}
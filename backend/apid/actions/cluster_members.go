@@ -0,0 +1,43 @@
+package actions
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sensu/sensu-go/backend/membership"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// ClusterMembersController exposes actions which a viewer can perform
+type ClusterMembersController struct {
+	registry *membership.Registry
+}
+
+// NewClusterMembersController returns a new ClusterMembersController
+func NewClusterMembersController(registry *membership.Registry) ClusterMembersController {
+	return ClusterMembersController{
+		registry: registry,
+	}
+}
+
+// List returns the backends of the cluster
+func (c ClusterMembersController) List(ctx context.Context) ([]membership.Member, error) {
+	members, err := c.registry.List(ctx)
+	if err != nil {
+		return nil, NewError(InternalErr, err)
+	}
+	return members, nil
+}
+
+// Get returns the backend named name
+func (c ClusterMembersController) Get(ctx context.Context, name string) (membership.Member, error) {
+	member, err := c.registry.Get(ctx, name)
+	if err != nil {
+		var notFound *store.ErrNotFound
+		if errors.As(err, &notFound) {
+			return member, NewErrorf(NotFound)
+		}
+		return member, NewError(InternalErr, err)
+	}
+	return member, nil
+}
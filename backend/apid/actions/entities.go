@@ -3,6 +3,7 @@ package actions
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
@@ -127,3 +128,159 @@ func (c EntityController) CreateOrReplace(ctx context.Context, entity corev2.Ent
 
 	return nil
 }
+
+const (
+	// BulkEntityCreated, BulkEntityUpdated, BulkEntityDeleted and
+	// BulkEntityRejected are the statuses of the entities of a bulk update.
+	BulkEntityCreated  = "created"
+	BulkEntityUpdated  = "updated"
+	BulkEntityDeleted  = "deleted"
+	BulkEntityRejected = "rejected"
+)
+
+// BulkEntityRequest is a request to create or update proxy entities in bulk,
+// such as the entities of a configuration management database.
+type BulkEntityRequest struct {
+	// Entities are the proxy entities to create or update.
+	Entities []*corev2.Entity `json:"entities"`
+
+	// SyncLabel and SyncValue identify the entities synchronized by the
+	// request: the label SyncLabel of the entities is set to SyncValue.
+	SyncLabel string `json:"sync_label,omitempty"`
+	SyncValue string `json:"sync_value,omitempty"`
+
+	// DeleteMissing deletes the proxy entities whose label SyncLabel is
+	// SyncValue, and that are not part of the request.
+	DeleteMissing bool `json:"delete_missing,omitempty"`
+}
+
+// BulkEntityItem is the result of the bulk update of an entity.
+type BulkEntityItem struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkEntityResult is the result of a bulk update of entities, with an item
+// for each entity of the request, in order, followed by an item for each
+// deleted entity.
+type BulkEntityResult struct {
+	Entities []BulkEntityItem `json:"entities"`
+}
+
+// BulkUpdate creates or updates the proxy entities of req in the namespace of
+// ctx, and deletes the missing entities if req asks for it, in a single
+// transaction. The entities that are invalid, or that would replace an agent
+// entity, are rejected and the others are updated.
+func (c EntityController) BulkUpdate(ctx context.Context, req BulkEntityRequest) (BulkEntityResult, error) {
+	result := BulkEntityResult{Entities: []BulkEntityItem{}}
+	if (req.SyncLabel == "") != (req.SyncValue == "") {
+		return result, NewErrorf(InvalidArgument, "sync_label and sync_value must be set together")
+	}
+	if req.DeleteMissing && req.SyncLabel == "" {
+		return result, NewErrorf(InvalidArgument, "delete_missing requires sync_label and sync_value")
+	}
+	bulkStore, ok := c.store.GetEntityStore().(store.BulkEntityStore)
+	if !ok {
+		return result, NewErrorf(InternalErr, "the entity store does not support bulk updates")
+	}
+
+	// The updates are computed from the existing entities of the namespace as
+	// read in the transaction of the updates, so that a concurrent request
+	// can't create an entity that would be missed, or deleted by mistake
+	err := bulkStore.UpdateEntities(ctx, func(existing []*corev2.Entity) ([]*corev2.Entity, []string, error) {
+		var updates []*corev2.Entity
+		var deletes []string
+		result, updates, deletes = bulkUpdates(req, corev2.ContextNamespace(ctx), existing)
+		return updates, deletes, nil
+	})
+	if err != nil {
+		return BulkEntityResult{Entities: []BulkEntityItem{}}, NewError(InternalErr, err)
+	}
+	return result, nil
+}
+
+// bulkUpdates returns the result of the bulk update req of the entities of
+// namespace, whose existing entities are existing, with the entities to create
+// or update and the names of the entities to delete.
+func bulkUpdates(req BulkEntityRequest, namespace string, existing []*corev2.Entity) (BulkEntityResult, []*corev2.Entity, []string) {
+	result := BulkEntityResult{Entities: []BulkEntityItem{}}
+	entities := make(map[string]*corev2.Entity, len(existing))
+	for _, entity := range existing {
+		entities[entity.Name] = entity
+	}
+
+	updates := make([]*corev2.Entity, 0, len(req.Entities))
+	seen := make(map[string]bool, len(req.Entities))
+	for _, entity := range req.Entities {
+		item := BulkEntityItem{Status: BulkEntityRejected}
+		if entity != nil {
+			item.Name = entity.Name
+		}
+		if err := bulkValidateEntity(entity, namespace, entities, seen); err != nil {
+			item.Error = err.Error()
+			result.Entities = append(result.Entities, item)
+			continue
+		}
+		seen[entity.Name] = true
+		if req.SyncLabel != "" {
+			if entity.Labels == nil {
+				entity.Labels = map[string]string{}
+			}
+			entity.Labels[req.SyncLabel] = req.SyncValue
+		}
+		item.Status = BulkEntityCreated
+		if entities[entity.Name] != nil {
+			item.Status = BulkEntityUpdated
+		}
+		result.Entities = append(result.Entities, item)
+		updates = append(updates, entity)
+	}
+
+	var deletes []string
+	if req.DeleteMissing {
+		for _, entity := range existing {
+			if entity.EntityClass != corev2.EntityProxyClass || seen[entity.Name] {
+				continue
+			}
+			if value, ok := entity.Labels[req.SyncLabel]; !ok || value != req.SyncValue {
+				continue
+			}
+			deletes = append(deletes, entity.Name)
+			result.Entities = append(result.Entities, BulkEntityItem{Name: entity.Name, Status: BulkEntityDeleted})
+		}
+	}
+	return result, updates, deletes
+}
+
+// bulkValidateEntity returns an error if entity can't be part of a bulk update
+// of the entities of namespace, whose existing entities are existing. An
+// entity without a namespace or an entity class is a proxy entity of
+// namespace.
+func bulkValidateEntity(entity *corev2.Entity, namespace string, existing map[string]*corev2.Entity, seen map[string]bool) error {
+	if entity == nil {
+		return errors.New("entity is empty")
+	}
+	if entity.Namespace == "" {
+		entity.Namespace = namespace
+	}
+	if entity.Namespace != namespace {
+		return fmt.Errorf("entity is not in the namespace %q", namespace)
+	}
+	if entity.EntityClass == "" {
+		entity.EntityClass = corev2.EntityProxyClass
+	}
+	if entity.EntityClass != corev2.EntityProxyClass {
+		return errors.New("entity is not a proxy entity")
+	}
+	if err := entity.Validate(); err != nil {
+		return err
+	}
+	if seen[entity.Name] {
+		return errors.New("entity is duplicated")
+	}
+	if e := existing[entity.Name]; e != nil && (e.EntityClass != corev2.EntityProxyClass || e.Labels[corev2.ManagedByLabel] == "sensu-agent") {
+		return errors.New("entity is managed by its agent")
+	}
+	return nil
+}
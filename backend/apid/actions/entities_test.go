@@ -359,3 +359,94 @@ func TestEntityCreateOrReplace(t *testing.T) {
 		})
 	}
 }
+
+func TestEntityBulkUpdate(t *testing.T) {
+	ctx := testutil.NewContext(testutil.ContextWithNamespace("default"))
+
+	proxyEntity := func(name string, labels map[string]string) *corev2.Entity {
+		entity := corev2.FixtureEntity(name)
+		entity.EntityClass = corev2.EntityProxyClass
+		entity.Labels = labels
+		return entity
+	}
+	agentEntity := corev2.FixtureEntity("agent")
+	agentEntity.EntityClass = corev2.EntityAgentClass
+
+	existing := []*corev2.Entity{
+		agentEntity,
+		proxyEntity("updated", map[string]string{"cmdb": "nightly"}),
+		proxyEntity("stale", map[string]string{"cmdb": "nightly"}),
+		proxyEntity("other", map[string]string{"cmdb": "hourly"}),
+		proxyEntity("unlabelled", nil),
+	}
+	invalid := proxyEntity("invalid", nil)
+	invalid.Name = "in valid"
+	otherNamespace := proxyEntity("elsewhere", nil)
+	otherNamespace.Namespace = "dev"
+	created := proxyEntity("created", nil)
+	created.EntityClass = ""
+
+	req := BulkEntityRequest{
+		Entities: []*corev2.Entity{
+			created,
+			proxyEntity("updated", nil),
+			proxyEntity("agent", nil),
+			invalid,
+			otherNamespace,
+			proxyEntity("created", nil),
+		},
+		SyncLabel:     "cmdb",
+		SyncValue:     "nightly",
+		DeleteMissing: true,
+	}
+
+	entityStore := &mockstore.MockStore{}
+	st := new(mockstore.V2MockStore)
+	st.On("GetEntityStore").Return(entityStore)
+	entityStore.On("GetEntities", mock.Anything, mock.Anything).Return(existing, nil)
+	entityStore.On("UpdateEntities", mock.Anything, mock.MatchedBy(func(entities []*corev2.Entity) bool {
+		return len(entities) == 2 && entities[0].Name == "created" && entities[1].Name == "updated" &&
+			entities[0].EntityClass == corev2.EntityProxyClass && entities[1].Labels["cmdb"] == "nightly"
+	}), []string{"stale"}).Return(nil)
+
+	result, err := NewEntityController(st).BulkUpdate(ctx, req)
+	assert.NoError(t, err)
+
+	var statuses []string
+	for _, item := range result.Entities {
+		statuses = append(statuses, item.Status)
+	}
+	assert.Equal(t, []string{
+		BulkEntityCreated, BulkEntityUpdated, BulkEntityRejected, BulkEntityRejected,
+		BulkEntityRejected, BulkEntityRejected, BulkEntityDeleted,
+	}, statuses)
+	assert.Equal(t, "stale", result.Entities[6].Name)
+	assert.Equal(t, "entity is duplicated", result.Entities[5].Error)
+	entityStore.AssertExpectations(t)
+}
+
+func TestEntityBulkUpdateInvalidSync(t *testing.T) {
+	ctx := testutil.NewContext(testutil.ContextWithNamespace("default"))
+	st := new(mockstore.V2MockStore)
+	controller := NewEntityController(st)
+
+	_, err := controller.BulkUpdate(ctx, BulkEntityRequest{SyncLabel: "cmdb"})
+	assert.Error(t, err)
+	_, err = controller.BulkUpdate(ctx, BulkEntityRequest{DeleteMissing: true})
+	assert.Error(t, err)
+}
+
+func TestEntityBulkUpdateStoreError(t *testing.T) {
+	ctx := testutil.NewContext(testutil.ContextWithNamespace("default"))
+	entityStore := &mockstore.MockStore{}
+	st := new(mockstore.V2MockStore)
+	st.On("GetEntityStore").Return(entityStore)
+	entityStore.On("GetEntities", mock.Anything, mock.Anything).Return([]*corev2.Entity{}, nil)
+	entityStore.On("UpdateEntities", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("rollback"))
+
+	entity := corev2.FixtureEntity("foo")
+	entity.EntityClass = corev2.EntityProxyClass
+	result, err := NewEntityController(st).BulkUpdate(ctx, BulkEntityRequest{Entities: []*corev2.Entity{entity}})
+	assert.Error(t, err)
+	assert.Empty(t, result.Entities)
+}
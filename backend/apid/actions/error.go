@@ -1,6 +1,14 @@
 package actions
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sensu/sensu-go/backend/apid/request"
+	"github.com/sensu/sensu-go/backend/store"
+)
 
 //
 // Following defines error type w/ error codes. Helpful for
@@ -55,6 +63,14 @@ const (
 
 	// Gone indicates that an API that was once supported but no longer is.
 	Gone
+
+	// Unavailable means that the action can't be performed for now, such as
+	// a change while the API is in read-only mode.
+	Unavailable
+
+	// PayloadTooLarge means that the request body exceeds the size limit of
+	// its endpoint.
+	PayloadTooLarge
 )
 
 // Default error messages if not message is provided.
@@ -69,6 +85,33 @@ var standardErrorMessages = map[ErrCode]string{
 	PreconditionFailed: "precondition failed",
 	DeadlineExceeded:   "deadline exceeded",
 	Gone:               "this action is no longer supported",
+	Unavailable:        "service unavailable",
+	PayloadTooLarge:    "request body too large",
+}
+
+// Names of the error codes, used as the reasons of the errors that have no
+// more specific reason.
+var errCodeNames = map[ErrCode]string{
+	InternalErr:        "internal",
+	InvalidArgument:    "invalid_argument",
+	NotFound:           "not_found",
+	AlreadyExistsErr:   "already_exists",
+	PermissionDenied:   "permission_denied",
+	Unauthenticated:    "unauthenticated",
+	PaymentRequired:    "payment_required",
+	PreconditionFailed: "precondition_failed",
+	DeadlineExceeded:   "deadline_exceeded",
+	Gone:               "gone",
+	Unavailable:        "unavailable",
+	PayloadTooLarge:    "payload_too_large",
+}
+
+// String returns the name of the code.
+func (c ErrCode) String() string {
+	if name, ok := errCodeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("code_%d", uint32(c))
 }
 
 // Error describes an issue that ocurred while performing the action.
@@ -79,6 +122,9 @@ type Error struct {
 	// Message is a developer / operator friendly message briefly describing what
 	// occurred.
 	Message string
+	// Reason is a machine-readable description of the error, more specific
+	// than its code, like the reason of the store error that caused it.
+	Reason string
 }
 
 // Error method implements error interface
@@ -86,9 +132,19 @@ func (err Error) Error() string {
 	return fmt.Sprintf("error: code = %d desc = %s", err.Code, err.Message)
 }
 
-// NewError returns a new Error given existing error and code.
+// NewError returns a new Error given existing error and code. The reason of
+// the error is the reason of err if it is a store error, or the name of code.
+// The errors of request bodies that exceed their size limit are always
+// PayloadTooLarge errors, whatever the code of the caller that decoded them.
 func NewError(code ErrCode, err error) Error {
-	return Error{Code: code, Message: err.Error()}
+	if errors.Is(err, request.ErrBodyTooLarge) {
+		code = PayloadTooLarge
+	}
+	reason := store.Reason(err)
+	if reason == "" {
+		reason = code.String()
+	}
+	return Error{Code: code, Message: err.Error(), Reason: reason}
 }
 
 // NewErrorf returns a new Error given message and code.
@@ -99,7 +155,53 @@ func NewErrorf(code ErrCode, s ...interface{}) Error {
 	} else {
 		f, s = s[0].(string), s[1:]
 	}
-	return Error{Code: code, Message: fmt.Sprintf(f, s...)}
+	return Error{Code: code, Message: fmt.Sprintf(f, s...), Reason: code.String()}
+}
+
+// FromError returns err if it is an Error, or the Error of the code that
+// matches err, with the reason of err if it is a store error.
+func FromError(err error) Error {
+	if actionErr, ok := err.(Error); ok {
+		return actionErr
+	}
+	var (
+		alreadyExists      *store.ErrAlreadyExists
+		namespaceMissing   *store.ErrNamespaceMissing
+		namespaceNotEmpty  *store.ErrNamespaceNotEmpty
+		notFound           *store.ErrNotFound
+		notValid           *store.ErrNotValid
+		preconditionFailed *store.ErrPreconditionFailed
+		unavailable        *store.ErrUnavailable
+	)
+	if isDeadlineExceeded(err) {
+		actionErr := NewError(DeadlineExceeded, err)
+		actionErr.Reason = DeadlineExceeded.String()
+		return actionErr
+	}
+	switch {
+	case errors.As(err, &alreadyExists):
+		return NewError(AlreadyExistsErr, err)
+	case errors.As(err, &namespaceMissing), errors.As(err, &notValid):
+		return NewError(InvalidArgument, err)
+	case errors.As(err, &notFound):
+		return NewError(NotFound, err)
+	case errors.As(err, &namespaceNotEmpty), errors.As(err, &preconditionFailed):
+		return NewError(PreconditionFailed, err)
+	case errors.As(err, &unavailable):
+		return NewError(Unavailable, err)
+	}
+	return NewError(InternalErr, err)
+}
+
+// isDeadlineExceeded returns true if err was caused by the deadline of the
+// request. Some stores only keep the message of the errors of their database
+// driver in their internal errors.
+func isDeadlineExceeded(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var internal *store.ErrInternal
+	return errors.As(err, &internal) && strings.HasSuffix(internal.Message, context.DeadlineExceeded.Error())
 }
 
 // StatusFromError extracts code from the given error.
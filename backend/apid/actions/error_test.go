@@ -0,0 +1,102 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sensu/sensu-go/backend/apid/request"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   ErrCode
+		wantReason string
+	}{
+		{
+			name:       "action error",
+			err:        NewErrorf(PermissionDenied),
+			wantCode:   PermissionDenied,
+			wantReason: "permission_denied",
+		},
+		{
+			name:       "store not found",
+			err:        &store.ErrNotFound{Key: "foo"},
+			wantCode:   NotFound,
+			wantReason: store.ReasonNotFound,
+		},
+		{
+			name:       "wrapped store error",
+			err:        fmt.Errorf("could not get: %w", &store.ErrNamespaceMissing{Namespace: "dev"}),
+			wantCode:   InvalidArgument,
+			wantReason: store.ReasonNamespaceMissing,
+		},
+		{
+			name:       "store internal",
+			err:        &store.ErrInternal{Message: "boom"},
+			wantCode:   InternalErr,
+			wantReason: store.ReasonInternal,
+		},
+		{
+			name:       "store unavailable",
+			err:        &store.ErrUnavailable{Message: "the store circuit breaker is open"},
+			wantCode:   Unavailable,
+			wantReason: store.ReasonUnavailable,
+		},
+		{
+			name:       "deadline",
+			err:        context.DeadlineExceeded,
+			wantCode:   DeadlineExceeded,
+			wantReason: "deadline_exceeded",
+		},
+		{
+			name:       "store deadline",
+			err:        &store.ErrInternal{Message: "couldn't get events: " + context.DeadlineExceeded.Error()},
+			wantCode:   DeadlineExceeded,
+			wantReason: "deadline_exceeded",
+		},
+		{
+			name:       "body too large",
+			err:        fmt.Errorf("could not decode: %w", request.ErrBodyTooLarge),
+			wantCode:   PayloadTooLarge,
+			wantReason: "payload_too_large",
+		},
+		{
+			name:       "other error",
+			err:        errors.New("boom"),
+			wantCode:   InternalErr,
+			wantReason: "internal",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FromError(tt.err)
+			if err.Code != tt.wantCode {
+				t.Errorf("bad code: got %s, want %s", err.Code, tt.wantCode)
+			}
+			if err.Reason != tt.wantReason {
+				t.Errorf("bad reason: got %q, want %q", err.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestNewErrorBodyTooLarge(t *testing.T) {
+	err := NewError(InvalidArgument, fmt.Errorf("%w: the limit is 10 bytes", request.ErrBodyTooLarge))
+	if err.Code != PayloadTooLarge {
+		t.Errorf("bad code: got %s, want %s", err.Code, PayloadTooLarge)
+	}
+}
+
+func TestErrCodeString(t *testing.T) {
+	if got, want := AlreadyExistsErr.String(), "already_exists"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := ErrCode(42).String(), "code_42"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
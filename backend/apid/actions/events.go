@@ -2,11 +2,17 @@ package actions
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sensu/sensu-go/backend/apid/request"
 	"github.com/sensu/sensu-go/backend/authentication/jwt"
+	"github.com/sensu/sensu-go/backend/jobs"
 	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/selector"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 
@@ -16,6 +22,10 @@ import (
 
 const deletedEventSentinel = -1
 
+// ManualResolveAnnotation is the annotation of the events resolved manually
+// through the API. Its value is the user who resolved the event.
+const ManualResolveAnnotation = "sensu.io/manual-resolve"
+
 // EventController expose actions in which a viewer can perform.
 type EventController struct {
 	store store.EventStore
@@ -142,6 +152,10 @@ func (a EventController) CreateOrReplace(ctx context.Context, event *corev2.Even
 		event.Entity.CreatedBy = claims.StandardClaims.Subject
 	}
 
+	// Record when and by which request the event was received
+	store.SetEventReceived(event, time.Now())
+	store.SetEventRequestID(event, request.RequestIDFromContext(ctx))
+
 	// Publish to event pipeline
 	if err := a.bus.Publish(messaging.TopicEventRaw, event); err != nil {
 		return NewError(InternalErr, err)
@@ -155,3 +169,266 @@ func (a EventController) CreateOrReplace(ctx context.Context, event *corev2.Even
 
 	return nil
 }
+
+const (
+	// BulkEventCreated, BulkEventRejected and BulkEventFailed are the
+	// statuses of the events of a bulk creation. The failed events were
+	// valid, but could not be created, and can be sent again.
+	BulkEventCreated  = "created"
+	BulkEventRejected = "rejected"
+	BulkEventFailed   = "failed"
+)
+
+// BulkEventItem is the result of the creation of an event in bulk.
+type BulkEventItem struct {
+	// Event is the event, in the entity/check format.
+	Event  string `json:"event"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkCreateEventsResult is the result of a bulk creation of events, with an
+// item for each event of the request, in order.
+type BulkCreateEventsResult struct {
+	Events []BulkEventItem `json:"events"`
+}
+
+// BulkCreateOrReplace creates or replaces events in the namespace of ctx, as
+// CreateOrReplace does, one at a time. The events that are invalid are
+// rejected, and don't prevent the others from being created.
+func (a EventController) BulkCreateOrReplace(ctx context.Context, events []*corev2.Event) BulkCreateEventsResult {
+	result := BulkCreateEventsResult{Events: make([]BulkEventItem, 0, len(events))}
+	namespace := corev2.ContextNamespace(ctx)
+	for _, event := range events {
+		item := BulkEventItem{Status: BulkEventCreated}
+		if event != nil && event.HasCheck() && event.Entity != nil {
+			item.Event = path.Join(event.Entity.Name, event.Check.Name)
+		}
+		if err := bulkValidateEvent(event, namespace); err != nil {
+			item.Status = BulkEventRejected
+			item.Error = err.Error()
+		} else if err := a.CreateOrReplace(ctx, event); err != nil {
+			item.Status = BulkEventFailed
+			if code, _ := StatusFromError(err); code == InvalidArgument {
+				item.Status = BulkEventRejected
+			}
+			item.Error = bulkErrorMessage(err)
+		}
+		result.Events = append(result.Events, item)
+	}
+	return result
+}
+
+// bulkValidateEvent returns an error if event can't be part of a bulk creation
+// of the events of namespace. The entity and check of an event without a
+// namespace are in namespace.
+func bulkValidateEvent(event *corev2.Event, namespace string) error {
+	if event == nil {
+		return errors.New("event is empty")
+	}
+	if !event.HasCheck() || event.Entity == nil {
+		return errors.New("event must have an entity and a check")
+	}
+	for _, meta := range []*corev2.ObjectMeta{&event.Entity.ObjectMeta, &event.Check.ObjectMeta} {
+		if meta.Namespace == "" {
+			meta.Namespace = namespace
+		}
+		if meta.Namespace != namespace {
+			return fmt.Errorf("namespace %q does not match the namespace of the request (%s)", meta.Namespace, namespace)
+		}
+	}
+	return nil
+}
+
+// BulkEventResult is the result of an operation on the events that match a
+// selector.
+type BulkEventResult struct {
+	// Events are the events that the operation was applied to, in the
+	// entity/check format.
+	Events []string `json:"events"`
+
+	// Errors are the errors that occurred while applying the operation.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// selectEvents returns the events of the namespace of ctx that match the
+// selector of ctx. A selector is required, so that every event of a namespace
+// is not deleted or resolved by mistake.
+func (a EventController) selectEvents(ctx context.Context) ([]*corev2.Event, error) {
+	sel := request.SelectorFromContext(ctx)
+	if sel == nil || len(sel.Operations) == 0 {
+		return nil, NewErrorf(InvalidArgument, "a label or field selector is required")
+	}
+	ctx = storev2.EventContextWithSelector(ctx, sel)
+	events, err := a.store.GetEvents(ctx, &store.SelectionPredicate{})
+	if err != nil {
+		return nil, NewError(InternalErr, err)
+	}
+	return events, nil
+}
+
+// bulkErrorMessage returns the message of an error of a bulk operation.
+func bulkErrorMessage(err error) string {
+	if e, ok := err.(Error); ok {
+		return e.Message
+	}
+	return err.Error()
+}
+
+// BulkDelete deletes the events that match the selector of ctx.
+func (a EventController) BulkDelete(ctx context.Context) (BulkEventResult, error) {
+	result := BulkEventResult{Events: []string{}}
+	events, err := a.selectEvents(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, event := range events {
+		if !event.HasCheck() {
+			continue
+		}
+		id := path.Join(event.Entity.Name, event.Check.Name)
+		if err := a.Delete(ctx, event.Entity.Name, event.Check.Name); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", id, bulkErrorMessage(err)))
+			continue
+		}
+		result.Events = append(result.Events, id)
+	}
+	return result, nil
+}
+
+// Resolve resolves the failing event of the entity and check, and returns
+// the resolved event. The event is processed by its pipelines if notify is
+// true, so that its handlers notify the recovery, otherwise it is only
+// stored. The events that are not failing are returned as they are.
+func (a EventController) Resolve(ctx context.Context, entity, check string, notify bool) (*corev2.Event, error) {
+	if entity == "" || check == "" {
+		return nil, NewErrorf(InvalidArgument, "Resolve() requires both an entity and a check")
+	}
+
+	event, err := a.store.GetEventByEntityCheck(ctx, entity, check)
+	if err != nil {
+		return nil, NewError(InternalErr, err)
+	}
+	if event == nil {
+		return nil, NewErrorf(NotFound)
+	}
+	if !event.HasCheck() || event.Check.Status == 0 {
+		return event, nil
+	}
+
+	if err := a.resolve(ctx, event, time.Now().Unix(), notify); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// resolve resolves event at now, and records the user of ctx in its
+// ManualResolveAnnotation annotation.
+func (a EventController) resolve(ctx context.Context, event *corev2.Event, now int64, notify bool) error {
+	actor := "api"
+	if claims := jwt.GetClaimsFromContext(ctx); claims != nil && claims.StandardClaims.Subject != "" {
+		actor = claims.StandardClaims.Subject
+	}
+	if event.Annotations == nil {
+		event.Annotations = make(map[string]string)
+	}
+	event.Annotations[ManualResolveAnnotation] = actor
+	event.Check.Status = 0
+	event.Check.Output = "Resolved manually by " + actor
+	event.Check.Executed = now
+	event.Timestamp = now
+
+	if notify {
+		return a.CreateOrReplace(ctx, event)
+	}
+	if _, _, err := a.store.UpdateEvent(ctx, event); err != nil {
+		return NewError(InternalErr, err)
+	}
+	return nil
+}
+
+// BulkResolve resolves the failing events that match the selector of ctx.
+// The events are processed by their pipelines if notify is true, otherwise
+// they are only stored.
+func (a EventController) BulkResolve(ctx context.Context, notify bool) (BulkEventResult, error) {
+	result := BulkEventResult{Events: []string{}}
+	events, err := a.selectEvents(ctx)
+	if err != nil {
+		return result, err
+	}
+	now := time.Now().Unix()
+	for _, event := range events {
+		if !event.HasCheck() || event.Check.Status == 0 {
+			continue
+		}
+		id := path.Join(event.Entity.Name, event.Check.Name)
+		if err := a.resolve(ctx, event, now, notify); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", id, bulkErrorMessage(err)))
+			continue
+		}
+		result.Events = append(result.Events, id)
+	}
+	return result, nil
+}
+
+// DeleteEventsJob is the kind of the background jobs that delete the events of
+// an entity, or the events that match a selector.
+const DeleteEventsJob = "delete-events"
+
+// DeleteEvents is the handler of the DeleteEventsJob jobs. It deletes the
+// events of the entity param of job, or else the events that match its
+// labelSelector and fieldSelector params.
+func (a EventController) DeleteEvents(ctx context.Context, job *store.Job, progress jobs.ProgressFunc) error {
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, job.Namespace)
+	events, err := a.jobEvents(ctx, job.Params)
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for i, event := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if event.HasCheck() {
+			err := a.Delete(ctx, event.Entity.Name, event.Check.Name)
+			// Events deleted since they were listed are not errors
+			if e, ok := err.(Error); err != nil && (!ok || e.Code != NotFound) {
+				id := path.Join(event.Entity.Name, event.Check.Name)
+				failed = append(failed, fmt.Sprintf("%s: %s", id, bulkErrorMessage(err)))
+			}
+		}
+		progress(i+1, len(events))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d events could not be deleted: %s", len(failed), len(events), failed[0])
+	}
+	return nil
+}
+
+// jobEvents returns the events selected by the params of a DeleteEventsJob
+// job.
+func (a EventController) jobEvents(ctx context.Context, params map[string]string) ([]*corev2.Event, error) {
+	if entity := params["entity"]; entity != "" {
+		return a.store.GetEventsByEntity(ctx, entity, &store.SelectionPredicate{})
+	}
+	var selectors []*selector.Selector
+	if params["labelSelector"] != "" {
+		sel, err := selector.ParseLabelSelector(params["labelSelector"])
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+	}
+	if params["fieldSelector"] != "" {
+		sel, err := selector.ParseFieldSelector(params["fieldSelector"])
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+	}
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("an entity or a selector is required")
+	}
+	ctx = storev2.EventContextWithSelector(ctx, selector.Merge(selectors...))
+	return a.store.GetEvents(ctx, &store.SelectionPredicate{})
+}
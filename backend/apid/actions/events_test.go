@@ -6,8 +6,10 @@ import (
 	"testing"
 
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/request"
 	"github.com/sensu/sensu-go/backend/authentication/jwt"
 	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/selector"
 	"github.com/sensu/sensu-go/backend/store"
 	"github.com/sensu/sensu-go/testing/mockbus"
 	"github.com/sensu/sensu-go/testing/mockstore"
@@ -375,3 +377,200 @@ func TestEventCreatedBy(t *testing.T) {
 	assert.Equal(t, "admin", event.Check.CreatedBy)
 	assert.Equal(t, "admin", event.Entity.CreatedBy)
 }
+
+func TestEventBulk(t *testing.T) {
+	sel, err := selector.ParseFieldSelector("event.check.name == check1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := request.ContextWithSelector(context.Background(), sel)
+
+	failing := corev2.FixtureEvent("entity1", "check1")
+	failing.Check.Status = 2
+	passing := corev2.FixtureEvent("entity2", "check1")
+	metrics := corev2.FixtureEvent("entity3", "check1")
+	metrics.Check = nil
+
+	newController := func() (EventController, *mockstore.MockStore, *mockbus.MockBus) {
+		s := &mockstore.MockStore{}
+		sv2 := new(mockstore.V2MockStore)
+		sv2.On("GetEventStore").Return(s)
+		bus := &mockbus.MockBus{}
+		s.On("GetEvents", mock.Anything, mock.Anything).
+			Return([]*corev2.Event{failing, passing, metrics}, nil)
+		return NewEventController(sv2, bus), s, bus
+	}
+
+	t.Run("selector required", func(t *testing.T) {
+		controller, _, _ := newController()
+		_, err := controller.BulkDelete(context.Background())
+		inferErr, ok := err.(Error)
+		assert.True(t, ok)
+		assert.Equal(t, InvalidArgument, inferErr.Code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		controller, s, _ := newController()
+		s.On("GetEventByEntityCheck", mock.Anything, "entity1", "check1").Return(failing, nil)
+		s.On("GetEventByEntityCheck", mock.Anything, "entity2", "check1").Return((*corev2.Event)(nil), errors.New("database is down"))
+		s.On("DeleteEventByEntityCheck", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		result, err := controller.BulkDelete(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"entity1/check1"}, result.Events)
+		assert.Len(t, result.Errors, 1)
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		controller, _, bus := newController()
+		bus.On("Publish", messaging.TopicEventRaw, mock.Anything).Return(nil)
+		result, err := controller.BulkResolve(ctx, true)
+		assert.NoError(t, err)
+		// passing events are already resolved
+		assert.Equal(t, []string{"entity1/check1"}, result.Events)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, uint32(0), failing.Check.Status)
+		assert.Equal(t, "api", failing.Annotations[ManualResolveAnnotation])
+		bus.AssertNumberOfCalls(t, "Publish", 1)
+	})
+}
+
+func TestEventBulkCreateOrReplace(t *testing.T) {
+	ctx := context.WithValue(context.Background(), corev2.NamespaceKey, "default")
+	bus := &mockbus.MockBus{}
+	bus.On("Publish", messaging.TopicEventRaw, mock.MatchedBy(func(event *corev2.Event) bool {
+		return event.Entity.Name != "unavailable"
+	})).Return(nil)
+	bus.On("Publish", messaging.TopicEventRaw, mock.Anything).Return(errors.New("where's the wizard"))
+	controller := NewEventController(new(mockstore.V2MockStore), bus)
+
+	created := corev2.FixtureEvent("entity1", "check1")
+	created.Entity.Namespace = ""
+	created.Check.Namespace = ""
+	invalid := corev2.FixtureEvent("entity2", "check1")
+	invalid.Check.Name = "!@#"
+	otherNamespace := corev2.FixtureEvent("entity3", "check1")
+	otherNamespace.Entity.Namespace = "acme"
+	unavailable := corev2.FixtureEvent("unavailable", "check1")
+
+	result := controller.BulkCreateOrReplace(ctx, []*corev2.Event{created, invalid, nil, otherNamespace, unavailable})
+	statuses := make([]string, 0, len(result.Events))
+	for _, item := range result.Events {
+		statuses = append(statuses, item.Status)
+	}
+	assert.Equal(t, []string{BulkEventCreated, BulkEventRejected, BulkEventRejected, BulkEventRejected, BulkEventFailed}, statuses)
+	assert.Equal(t, "entity1/check1", result.Events[0].Event)
+	assert.Equal(t, "default", created.Check.Namespace)
+	assert.Empty(t, result.Events[0].Error)
+	assert.NotEmpty(t, result.Events[4].Error)
+}
+
+func TestEventResolve(t *testing.T) {
+	claims, err := jwt.NewClaims(&corev2.User{Username: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), corev2.ClaimsKey, claims)
+
+	newController := func(event *corev2.Event, bus *mockbus.MockBus) (EventController, *mockstore.MockStore) {
+		s := &mockstore.MockStore{}
+		sv2 := new(mockstore.V2MockStore)
+		sv2.On("GetEventStore").Return(s)
+		s.On("GetEventByEntityCheck", mock.Anything, "entity1", "check1").Return(event, nil)
+		return NewEventController(sv2, bus), s
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		controller, _ := newController(nil, &mockbus.MockBus{})
+		_, err := controller.Resolve(ctx, "entity1", "check1", true)
+		inferErr, ok := err.(Error)
+		assert.True(t, ok)
+		assert.Equal(t, NotFound, inferErr.Code)
+	})
+
+	t.Run("passing event", func(t *testing.T) {
+		event := corev2.FixtureEvent("entity1", "check1")
+		controller, _ := newController(event, &mockbus.MockBus{})
+		resolved, err := controller.Resolve(ctx, "entity1", "check1", true)
+		assert.NoError(t, err)
+		assert.Equal(t, event, resolved)
+		assert.Empty(t, resolved.Annotations[ManualResolveAnnotation])
+	})
+
+	t.Run("notify", func(t *testing.T) {
+		event := corev2.FixtureEvent("entity1", "check1")
+		event.Check.Status = 2
+		bus := &mockbus.MockBus{}
+		bus.On("Publish", messaging.TopicEventRaw, mock.Anything).Return(nil)
+		controller, s := newController(event, bus)
+
+		resolved, err := controller.Resolve(ctx, "entity1", "check1", true)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(0), resolved.Check.Status)
+		assert.Equal(t, "admin", resolved.Annotations[ManualResolveAnnotation])
+		assert.Equal(t, "Resolved manually by admin", resolved.Check.Output)
+		bus.AssertNumberOfCalls(t, "Publish", 1)
+		s.AssertNotCalled(t, "UpdateEvent", mock.Anything)
+	})
+
+	t.Run("store only", func(t *testing.T) {
+		event := corev2.FixtureEvent("entity1", "check1")
+		event.Check.Status = 2
+		controller, s := newController(event, &mockbus.MockBus{})
+		s.On("UpdateEvent", mock.Anything).Return(event, event, nil)
+
+		resolved, err := controller.Resolve(ctx, "entity1", "check1", false)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(0), resolved.Check.Status)
+		assert.Equal(t, "admin", resolved.Annotations[ManualResolveAnnotation])
+		s.AssertNumberOfCalls(t, "UpdateEvent", 1)
+	})
+}
+
+func TestEventDeleteEvents(t *testing.T) {
+	event1 := corev2.FixtureEvent("entity1", "check1")
+	event2 := corev2.FixtureEvent("entity1", "check2")
+	event3 := corev2.FixtureEvent("entity1", "check3")
+
+	newController := func() (EventController, *mockstore.MockStore) {
+		s := &mockstore.MockStore{}
+		sv2 := new(mockstore.V2MockStore)
+		sv2.On("GetEventStore").Return(s)
+		return NewEventController(sv2, &mockbus.MockBus{}), s
+	}
+	noProgress := func(done, total int) {}
+
+	t.Run("entity", func(t *testing.T) {
+		controller, s := newController()
+		s.On("GetEventsByEntity", mock.Anything, "entity1", mock.Anything).
+			Return([]*corev2.Event{event1, event2, event3}, nil)
+		s.On("GetEventByEntityCheck", mock.Anything, "entity1", "check1").Return(event1, nil)
+		// event2 was deleted since it was listed
+		s.On("GetEventByEntityCheck", mock.Anything, "entity1", "check2").Return((*corev2.Event)(nil), nil)
+		s.On("GetEventByEntityCheck", mock.Anything, "entity1", "check3").Return(event3, nil)
+		s.On("DeleteEventByEntityCheck", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		var done, total int
+		job := &store.Job{Namespace: "default", Params: map[string]string{"entity": "entity1"}}
+		err := controller.DeleteEvents(context.Background(), job, func(d, t int) { done, total = d, t })
+		assert.NoError(t, err)
+		assert.Equal(t, 3, done)
+		assert.Equal(t, 3, total)
+		s.AssertNumberOfCalls(t, "DeleteEventByEntityCheck", 2)
+	})
+
+	t.Run("selector", func(t *testing.T) {
+		controller, s := newController()
+		s.On("GetEvents", mock.Anything, mock.Anything).Return([]*corev2.Event{event1}, nil)
+		s.On("GetEventByEntityCheck", mock.Anything, "entity1", "check1").Return((*corev2.Event)(nil), errors.New("database is down"))
+
+		job := &store.Job{Namespace: "default", Params: map[string]string{"fieldSelector": "event.check.name == check1"}}
+		err := controller.DeleteEvents(context.Background(), job, noProgress)
+		assert.EqualError(t, err, "1 of 1 events could not be deleted: entity1/check1: database is down")
+	})
+
+	t.Run("entity or selector required", func(t *testing.T) {
+		controller, _ := newController()
+		err := controller.DeleteEvents(context.Background(), &store.Job{Namespace: "default"}, noProgress)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,251 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+
+	corev2 "github.com/sensu/core/v2"
+	"golang.org/x/time/rate"
+
+	"github.com/sensu/sensu-go/backend/apid/request"
+	"github.com/sensu/sensu-go/backend/jobs"
+	"github.com/sensu/sensu-go/backend/selector"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// DefaultReplayRate is the default number of events replayed per second.
+const DefaultReplayRate = 10
+
+// HandlerReplayer sends events through a handler.
+type HandlerReplayer interface {
+	ReplayHandler(ctx context.Context, name string, event *corev2.Event) error
+}
+
+// HandlerReplayRequest selects the stored events to replay through a handler.
+// Events are also selected by the label and field selectors of the request.
+type HandlerReplayRequest struct {
+	// Start selects the events that occurred at or after this time, in
+	// seconds since the epoch.
+	Start int64 `json:"start,omitempty"`
+
+	// End selects the events that occurred at or before this time, in seconds
+	// since the epoch.
+	End int64 `json:"end,omitempty"`
+
+	// Rate is the maximum number of events replayed per second.
+	// DefaultReplayRate is used when it is zero.
+	Rate float64 `json:"rate,omitempty"`
+}
+
+// HandlerReplayController exposes the replay of stored events through a
+// handler, to recover from a misconfigured handler or downstream service.
+type HandlerReplayController struct {
+	store    store.EventStore
+	replayer HandlerReplayer
+}
+
+// NewHandlerReplayController returns a new HandlerReplayController
+func NewHandlerReplayController(store storev2.Interface, replayer HandlerReplayer) HandlerReplayController {
+	return HandlerReplayController{
+		store:    store.GetEventStore(),
+		replayer: replayer,
+	}
+}
+
+// Replay sends the events of the namespace of ctx that match req through the
+// handler named name, at most req.Rate events per second. A time window or a
+// selector is required, so that every event of a namespace is not replayed
+// by mistake. The replay stops when ctx is done, long replays should be run
+// in the background with ReplayJob instead.
+func (c HandlerReplayController) Replay(ctx context.Context, name string, req HandlerReplayRequest) (BulkEventResult, error) {
+	result := BulkEventResult{Events: []string{}}
+
+	if err := ValidateHandlerReplay(ctx, req); err != nil {
+		return result, err
+	}
+	events, err := c.replayEvents(ctx, req, request.SelectorFromContext(ctx))
+	if err != nil {
+		return result, NewError(InternalErr, err)
+	}
+	err = c.replay(ctx, name, req, events, func(id string, err error) {
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", id, err))
+			return
+		}
+		result.Events = append(result.Events, id)
+	})
+	return result, err
+}
+
+// ValidateHandlerReplay returns an error if req, and the selector of ctx, do
+// not select the events to replay.
+func ValidateHandlerReplay(ctx context.Context, req HandlerReplayRequest) error {
+	if req.Start < 0 || req.End < 0 || req.Rate < 0 {
+		return NewErrorf(InvalidArgument, "start, end and rate must not be negative")
+	}
+	if req.End != 0 && req.End < req.Start {
+		return NewErrorf(InvalidArgument, "end must not be before start")
+	}
+	sel := request.SelectorFromContext(ctx)
+	if req.Start == 0 && req.End == 0 && (sel == nil || len(sel.Operations) == 0) {
+		return NewErrorf(InvalidArgument, "a time window or a label or field selector is required")
+	}
+	return nil
+}
+
+// ReplayHandlerJob is the kind of the background jobs that replay stored
+// events through a handler.
+const ReplayHandlerJob = "replay-handler"
+
+// ReplayJobParams returns the params of a ReplayHandlerJob job that replays
+// the events selected by req, and by the label and field selectors, through
+// the handler named name.
+func ReplayJobParams(name string, req HandlerReplayRequest, labelSelector, fieldSelector string) map[string]string {
+	params := map[string]string{"handler": name}
+	if req.Start != 0 {
+		params["start"] = strconv.FormatInt(req.Start, 10)
+	}
+	if req.End != 0 {
+		params["end"] = strconv.FormatInt(req.End, 10)
+	}
+	if req.Rate != 0 {
+		params["rate"] = strconv.FormatFloat(req.Rate, 'f', -1, 64)
+	}
+	if labelSelector != "" {
+		params["labelSelector"] = labelSelector
+	}
+	if fieldSelector != "" {
+		params["fieldSelector"] = fieldSelector
+	}
+	return params
+}
+
+// ReplayJob is the handler of the ReplayHandlerJob jobs. It replays the events
+// selected by the params of job through their handler, and stops when ctx is
+// done.
+func (c HandlerReplayController) ReplayJob(ctx context.Context, job *store.Job, progress jobs.ProgressFunc) error {
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, job.Namespace)
+	var (
+		req HandlerReplayRequest
+		err error
+	)
+	if v := job.Params["start"]; v != "" {
+		if req.Start, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return fmt.Errorf("invalid start: %s", err)
+		}
+	}
+	if v := job.Params["end"]; v != "" {
+		if req.End, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return fmt.Errorf("invalid end: %s", err)
+		}
+	}
+	if v := job.Params["rate"]; v != "" {
+		if req.Rate, err = strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("invalid rate: %s", err)
+		}
+	}
+	var selectors []*selector.Selector
+	if job.Params["labelSelector"] != "" {
+		sel, err := selector.ParseLabelSelector(job.Params["labelSelector"])
+		if err != nil {
+			return err
+		}
+		selectors = append(selectors, sel)
+	}
+	if job.Params["fieldSelector"] != "" {
+		sel, err := selector.ParseFieldSelector(job.Params["fieldSelector"])
+		if err != nil {
+			return err
+		}
+		selectors = append(selectors, sel)
+	}
+	var sel *selector.Selector
+	if len(selectors) > 0 {
+		sel = selector.Merge(selectors...)
+	}
+	events, err := c.replayEvents(ctx, req, sel)
+	if err != nil {
+		return err
+	}
+
+	var replayed int
+	var failed []string
+	err = c.replay(ctx, job.Params["handler"], req, events, func(id string, err error) {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", id, err))
+		}
+		replayed++
+		progress(replayed, len(events))
+	})
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d events could not be replayed: %s", len(failed), replayed, failed[0])
+	}
+	return nil
+}
+
+// replayEvents returns the events of the namespace of ctx that match sel, in
+// the time window of req.
+func (c HandlerReplayController) replayEvents(ctx context.Context, req HandlerReplayRequest, sel *selector.Selector) ([]*corev2.Event, error) {
+	if sel != nil && len(sel.Operations) > 0 {
+		ctx = storev2.EventContextWithSelector(ctx, sel)
+	}
+	events, err := c.store.GetEvents(ctx, &store.SelectionPredicate{})
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]*corev2.Event, 0, len(events))
+	for _, event := range events {
+		if req.Start != 0 && event.Timestamp < req.Start {
+			continue
+		}
+		if req.End != 0 && event.Timestamp > req.End {
+			continue
+		}
+		selected = append(selected, event)
+	}
+	return selected, nil
+}
+
+// replay sends events through the handler named name, at most req.Rate
+// events per second, and calls done with the result of each of them. It returns an
+// error if the handler does not exist, or if ctx is done.
+func (c HandlerReplayController) replay(ctx context.Context, name string, req HandlerReplayRequest, events []*corev2.Event, done func(id string, err error)) error {
+	limit := req.Rate
+	if limit == 0 {
+		limit = DefaultReplayRate
+	}
+	limiter := rate.NewLimiter(rate.Limit(limit), 1)
+
+	for _, event := range events {
+		id := event.Entity.Name
+		if event.HasCheck() {
+			id = path.Join(id, event.Check.Name)
+		}
+		if err := limiter.Wait(ctx); err != nil {
+			if ctx.Err() != nil {
+				return NewError(DeadlineExceeded, ctx.Err())
+			}
+			return NewError(InternalErr, err)
+		}
+		if err := c.replayer.ReplayHandler(ctx, name, event); err != nil {
+			var notFound *store.ErrNotFound
+			if errors.As(err, &notFound) {
+				return NewErrorf(NotFound)
+			}
+			if ctx.Err() != nil {
+				return NewError(DeadlineExceeded, ctx.Err())
+			}
+			done(id, err)
+			continue
+		}
+		done(id, nil)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mockstore"
+)
+
+type mockHandlerReplayer struct {
+	mock.Mock
+}
+
+func (m *mockHandlerReplayer) ReplayHandler(ctx context.Context, name string, event *corev2.Event) error {
+	return m.Called(ctx, name, event).Error(0)
+}
+
+func TestHandlerReplay(t *testing.T) {
+	early := corev2.FixtureEvent("entity1", "check1")
+	early.Timestamp = 100
+	inWindow := corev2.FixtureEvent("entity2", "check1")
+	inWindow.Timestamp = 200
+	failing := corev2.FixtureEvent("entity3", "check1")
+	failing.Timestamp = 300
+
+	newController := func() (HandlerReplayController, *mockHandlerReplayer) {
+		s := &mockstore.MockStore{}
+		sv2 := new(mockstore.V2MockStore)
+		sv2.On("GetEventStore").Return(s)
+		s.On("GetEvents", mock.Anything, mock.Anything).
+			Return([]*corev2.Event{early, inWindow, failing}, nil)
+		replayer := &mockHandlerReplayer{}
+		return NewHandlerReplayController(sv2, replayer), replayer
+	}
+
+	t.Run("time window or selector required", func(t *testing.T) {
+		controller, _ := newController()
+		_, err := controller.Replay(context.Background(), "slack", HandlerReplayRequest{})
+		inferErr, ok := err.(Error)
+		assert.True(t, ok)
+		assert.Equal(t, InvalidArgument, inferErr.Code)
+	})
+
+	t.Run("invalid time window", func(t *testing.T) {
+		controller, _ := newController()
+		_, err := controller.Replay(context.Background(), "slack", HandlerReplayRequest{Start: 200, End: 100})
+		inferErr, ok := err.(Error)
+		assert.True(t, ok)
+		assert.Equal(t, InvalidArgument, inferErr.Code)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		controller, replayer := newController()
+		replayer.On("ReplayHandler", mock.Anything, "slack", inWindow).Return(nil)
+		replayer.On("ReplayHandler", mock.Anything, "slack", failing).Return(errors.New("webhook unreachable"))
+		result, err := controller.Replay(context.Background(), "slack", HandlerReplayRequest{Start: 150, Rate: 1000})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"entity2/check1"}, result.Events)
+		assert.Equal(t, []string{"entity3/check1: webhook unreachable"}, result.Errors)
+		replayer.AssertNumberOfCalls(t, "ReplayHandler", 2)
+	})
+
+	t.Run("handler not found", func(t *testing.T) {
+		controller, replayer := newController()
+		replayer.On("ReplayHandler", mock.Anything, "missing", mock.Anything).Return(&store.ErrNotFound{})
+		_, err := controller.Replay(context.Background(), "missing", HandlerReplayRequest{Start: 150, Rate: 1000})
+		inferErr, ok := err.(Error)
+		assert.True(t, ok)
+		assert.Equal(t, NotFound, inferErr.Code)
+	})
+}
+
+func TestHandlerReplayCanceled(t *testing.T) {
+	event := corev2.FixtureEvent("entity1", "check1")
+	event.Timestamp = 200
+	s := &mockstore.MockStore{}
+	sv2 := new(mockstore.V2MockStore)
+	sv2.On("GetEventStore").Return(s)
+	s.On("GetEvents", mock.Anything, mock.Anything).Return([]*corev2.Event{event, event}, nil)
+	replayer := &mockHandlerReplayer{}
+	controller := NewHandlerReplayController(sv2, replayer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := controller.Replay(ctx, "slack", HandlerReplayRequest{Start: 100})
+	inferErr, ok := err.(Error)
+	assert.True(t, ok)
+	assert.Equal(t, DeadlineExceeded, inferErr.Code)
+	replayer.AssertNotCalled(t, "ReplayHandler", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandlerReplayJob(t *testing.T) {
+	early := corev2.FixtureEvent("entity1", "check1")
+	early.Timestamp = 100
+	inWindow := corev2.FixtureEvent("entity2", "check1")
+	inWindow.Timestamp = 200
+	s := &mockstore.MockStore{}
+	sv2 := new(mockstore.V2MockStore)
+	sv2.On("GetEventStore").Return(s)
+	s.On("GetEvents", mock.Anything, mock.Anything).Return([]*corev2.Event{early, inWindow}, nil)
+	replayer := &mockHandlerReplayer{}
+	replayer.On("ReplayHandler", mock.Anything, "slack", inWindow).Return(nil)
+	controller := NewHandlerReplayController(sv2, replayer)
+
+	job := &store.Job{
+		ID:        "job1",
+		Namespace: "default",
+		Kind:      ReplayHandlerJob,
+		Params:    ReplayJobParams("slack", HandlerReplayRequest{Start: 150, Rate: 1000}, "", ""),
+	}
+	var done int
+	err := controller.ReplayJob(context.Background(), job, func(n, total int) { done = n })
+	assert.NoError(t, err)
+	assert.Equal(t, 1, done)
+	replayer.AssertNumberOfCalls(t, "ReplayHandler", 1)
+}
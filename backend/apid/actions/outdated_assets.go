@@ -0,0 +1,68 @@
+package actions
+
+import (
+	"context"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/request"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/bonsai"
+)
+
+// OutdatedAssetsController compares the assets installed from Bonsai with
+// their latest version on Bonsai, and upgrades them.
+type OutdatedAssetsController struct {
+	store  storev2.Interface
+	bonsai bonsai.Client
+}
+
+// NewOutdatedAssetsController returns a new OutdatedAssetsController
+func NewOutdatedAssetsController(store storev2.Interface, client bonsai.Client) OutdatedAssetsController {
+	return OutdatedAssetsController{
+		store:  store,
+		bonsai: client,
+	}
+}
+
+// List returns the outdated assets of the namespace of ctx, or of every
+// namespace if it is empty. The assets are filtered by the selector of ctx,
+// if any.
+func (c OutdatedAssetsController) List(ctx context.Context) ([]bonsai.OutdatedAsset, error) {
+	if selector := request.SelectorFromContext(ctx); selector != nil {
+		tm := corev2.TypeMeta{Type: "Asset", APIVersion: "core/v2"}
+		ctx = storev2.ContextWithSelector(ctx, tm, selector)
+	}
+	assets, err := storev2.Of[*corev2.Asset](c.store).List(ctx, storev2.ID{Namespace: corev2.ContextNamespace(ctx)}, nil)
+	if err != nil {
+		return nil, NewError(InternalErr, err)
+	}
+	outdated, err := bonsai.Outdated(assets, c.bonsai)
+	if err != nil {
+		return nil, NewError(Unavailable, err)
+	}
+	return outdated, nil
+}
+
+// Upgrade replaces the definitions of the outdated assets of the namespace
+// of ctx, or of every namespace if it is empty, with the definitions of
+// their latest version on Bonsai. It returns the upgraded assets.
+func (c OutdatedAssetsController) Upgrade(ctx context.Context) ([]bonsai.OutdatedAsset, error) {
+	outdated, err := c.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	astore := storev2.Of[*corev2.Asset](c.store)
+	for _, o := range outdated {
+		asset, err := bonsai.Upgrade(o, c.bonsai)
+		if err != nil {
+			return nil, NewError(Unavailable, err)
+		}
+		if err := asset.Validate(); err != nil {
+			return nil, NewError(InternalErr, err)
+		}
+		if err := astore.CreateOrUpdate(ctx, asset); err != nil {
+			return nil, NewError(InternalErr, err)
+		}
+	}
+	return outdated, nil
+}
@@ -0,0 +1,91 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/bonsai"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testBonsaiClient struct {
+	versions   map[string]string
+	definition string
+	err        error
+}
+
+func (c testBonsaiClient) FetchAsset(namespace, name string) (*bonsai.Asset, error) {
+	asset := &bonsai.Asset{Name: namespace + "/" + name}
+	if version, ok := c.versions[asset.Name]; ok {
+		asset.Versions = []*bonsai.AssetVersionGrouping{{Version: version}}
+	}
+	return asset, c.err
+}
+
+func (c testBonsaiClient) FetchAssetVersion(namespace, name, version string) (string, error) {
+	return c.definition, c.err
+}
+
+func fixtureBonsaiAsset(name, version string) *corev2.Asset {
+	asset := corev2.FixtureAsset(name)
+	asset.Annotations = map[string]string{
+		bonsai.URLAnnotation:       "https://bonsai.sensu.io",
+		bonsai.NamespaceAnnotation: "sensu",
+		bonsai.NameAnnotation:      name,
+		bonsai.VersionAnnotation:   version,
+	}
+	return asset
+}
+
+func TestOutdatedAssetsList(t *testing.T) {
+	store := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	store.On("GetConfigStore").Return(cs)
+	assets := mockstore.WrapList[*corev2.Asset]{
+		fixtureBonsaiAsset("outdated", "0.1.0"),
+		fixtureBonsaiAsset("latest", "1.0.0"),
+		corev2.FixtureAsset("local"),
+	}
+	cs.On("List", mock.Anything, mock.Anything, mock.Anything).Return(assets, nil)
+
+	client := testBonsaiClient{versions: map[string]string{"sensu/outdated": "0.2.0", "sensu/latest": "1.0.0"}}
+	got, err := NewOutdatedAssetsController(store, client).List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []bonsai.OutdatedAsset{
+		{BonsaiName: "outdated", BonsaiNamespace: "sensu", AssetName: "outdated", AssetNamespace: "default", CurrentVersion: "0.1.0", LatestVersion: "0.2.0"},
+	}, got)
+}
+
+func TestOutdatedAssetsListBonsaiError(t *testing.T) {
+	store := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	store.On("GetConfigStore").Return(cs)
+	assets := mockstore.WrapList[*corev2.Asset]{fixtureBonsaiAsset("outdated", "0.1.0")}
+	cs.On("List", mock.Anything, mock.Anything, mock.Anything).Return(assets, nil)
+
+	_, err := NewOutdatedAssetsController(store, testBonsaiClient{err: errors.New("oh noes")}).List(context.Background())
+	code, _ := StatusFromError(err)
+	assert.Equal(t, Unavailable, code)
+}
+
+func TestOutdatedAssetsUpgrade(t *testing.T) {
+	store := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	store.On("GetConfigStore").Return(cs)
+	assets := mockstore.WrapList[*corev2.Asset]{fixtureBonsaiAsset("outdated", "0.1.0")}
+	cs.On("List", mock.Anything, mock.Anything, mock.Anything).Return(assets, nil)
+	cs.On("CreateOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	client := testBonsaiClient{
+		versions:   map[string]string{"sensu/outdated": "0.2.0"},
+		definition: `{"type": "Asset", "api_version": "core/v2", "metadata": {"name": "sensu/outdated", "annotations": {"io.sensu.bonsai.version": "0.2.0"}}, "spec": {"url": "https://example.com/outdated_0.2.0.tar.gz", "sha512": "abcd"}}`,
+	}
+	got, err := NewOutdatedAssetsController(store, client).Upgrade(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	cs.AssertCalled(t, "CreateOrUpdate", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,205 @@
+package actions
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// PruneReport lists the resources of a namespace that are not used by any
+// other resource, and could be pruned.
+type PruneReport struct {
+	// Handlers are the handlers that are not referenced by any check,
+	// entity, handler set or pipeline.
+	Handlers []string `json:"handlers"`
+
+	// Filters are the filters that are not referenced by any handler or
+	// pipeline.
+	Filters []string `json:"filters"`
+
+	// Assets are the assets that are not referenced by any check, hook,
+	// filter, mutator or handler.
+	Assets []string `json:"assets"`
+
+	// SubscriptionsWithoutChecks are the entity subscriptions that no check
+	// is published to.
+	SubscriptionsWithoutChecks []string `json:"subscriptions_without_checks"`
+
+	// SubscriptionsWithoutEntities are the check subscriptions that no entity
+	// is subscribed to.
+	SubscriptionsWithoutEntities []string `json:"subscriptions_without_entities"`
+}
+
+// PruneReportController exposes actions which a viewer can perform.
+type PruneReportController struct {
+	store storev2.Interface
+}
+
+// NewPruneReportController returns a new PruneReportController
+func NewPruneReportController(store storev2.Interface) PruneReportController {
+	return PruneReportController{
+		store: store,
+	}
+}
+
+// PruneResources are the resources of a namespace that a PruneReport is
+// built from.
+type PruneResources struct {
+	Checks    []*corev2.CheckConfig
+	Entities  []*corev3.EntityConfig
+	Filters   []*corev2.EventFilter
+	Handlers  []*corev2.Handler
+	Hooks     []*corev2.HookConfig
+	Mutators  []*corev2.Mutator
+	Pipelines []*corev2.Pipeline
+	Assets    []*corev2.Asset
+}
+
+// Report returns the prune report of the namespace of ctx.
+func (c PruneReportController) Report(ctx context.Context) (PruneReport, error) {
+	namespace := corev2.ContextNamespace(ctx)
+	var resources PruneResources
+	var err error
+	if resources.Checks, err = listPruneResources[*corev2.CheckConfig](ctx, c.store, namespace); err != nil {
+		return PruneReport{}, err
+	}
+	if resources.Entities, err = listPruneResources[*corev3.EntityConfig](ctx, c.store, namespace); err != nil {
+		return PruneReport{}, err
+	}
+	if resources.Filters, err = listPruneResources[*corev2.EventFilter](ctx, c.store, namespace); err != nil {
+		return PruneReport{}, err
+	}
+	if resources.Handlers, err = listPruneResources[*corev2.Handler](ctx, c.store, namespace); err != nil {
+		return PruneReport{}, err
+	}
+	if resources.Hooks, err = listPruneResources[*corev2.HookConfig](ctx, c.store, namespace); err != nil {
+		return PruneReport{}, err
+	}
+	if resources.Mutators, err = listPruneResources[*corev2.Mutator](ctx, c.store, namespace); err != nil {
+		return PruneReport{}, err
+	}
+	if resources.Pipelines, err = listPruneResources[*corev2.Pipeline](ctx, c.store, namespace); err != nil {
+		return PruneReport{}, err
+	}
+	if resources.Assets, err = listPruneResources[*corev2.Asset](ctx, c.store, namespace); err != nil {
+		return PruneReport{}, err
+	}
+	return NewPruneReport(resources), nil
+}
+
+func listPruneResources[R storev2.Resource[T], T any](ctx context.Context, s storev2.Interface, namespace string) ([]R, error) {
+	resources, err := storev2.Of[R](s).List(ctx, storev2.ID{Namespace: namespace}, &store.SelectionPredicate{})
+	if err != nil {
+		return nil, NewError(InternalErr, err)
+	}
+	return resources, nil
+}
+
+// NewPruneReport returns the prune report of resources.
+func NewPruneReport(resources PruneResources) PruneReport {
+	usedHandlers := map[string]bool{}
+	usedFilters := map[string]bool{}
+	usedAssets := map[string]bool{}
+	checkSubscriptions := map[string]bool{}
+	entitySubscriptions := map[string]bool{}
+
+	useAll := func(used map[string]bool, names []string) {
+		for _, name := range names {
+			used[name] = true
+		}
+	}
+
+	for _, check := range resources.Checks {
+		useAll(usedHandlers, check.Handlers)
+		useAll(usedHandlers, check.OutputMetricHandlers)
+		useAll(usedAssets, check.RuntimeAssets)
+		useAll(checkSubscriptions, check.Subscriptions)
+	}
+	for _, entity := range resources.Entities {
+		if len(entity.KeepaliveHandlers) > 0 {
+			useAll(usedHandlers, entity.KeepaliveHandlers)
+		} else {
+			usedHandlers[corev2.KeepaliveHandlerName] = true
+		}
+		if entity.Deregistration.Handler != "" {
+			usedHandlers[entity.Deregistration.Handler] = true
+		}
+		for _, subscription := range entity.Subscriptions {
+			// Every entity is subscribed to its own entity subscription
+			if !strings.HasPrefix(subscription, "entity:") {
+				entitySubscriptions[subscription] = true
+			}
+		}
+	}
+	for _, handler := range resources.Handlers {
+		useAll(usedHandlers, handler.Handlers)
+		useAll(usedFilters, handler.Filters)
+		useAll(usedAssets, handler.RuntimeAssets)
+	}
+	for _, pipeline := range resources.Pipelines {
+		for _, workflow := range pipeline.Workflows {
+			if ref := workflow.Handler; ref != nil && ref.APIVersion == "core/v2" && ref.Type == "Handler" {
+				usedHandlers[ref.Name] = true
+			}
+			for _, ref := range workflow.Filters {
+				if ref != nil && ref.APIVersion == "core/v2" && ref.Type == "EventFilter" {
+					usedFilters[ref.Name] = true
+				}
+			}
+		}
+	}
+	for _, filter := range resources.Filters {
+		useAll(usedAssets, filter.RuntimeAssets)
+	}
+	for _, mutator := range resources.Mutators {
+		useAll(usedAssets, mutator.RuntimeAssets)
+	}
+	for _, hook := range resources.Hooks {
+		useAll(usedAssets, hook.RuntimeAssets)
+	}
+
+	report := PruneReport{
+		Handlers:                     []string{},
+		Filters:                      []string{},
+		Assets:                       []string{},
+		SubscriptionsWithoutChecks:   []string{},
+		SubscriptionsWithoutEntities: []string{},
+	}
+	for _, handler := range resources.Handlers {
+		if !usedHandlers[handler.Name] {
+			report.Handlers = append(report.Handlers, handler.Name)
+		}
+	}
+	for _, filter := range resources.Filters {
+		if !usedFilters[filter.Name] {
+			report.Filters = append(report.Filters, filter.Name)
+		}
+	}
+	for _, asset := range resources.Assets {
+		if !usedAssets[asset.Name] {
+			report.Assets = append(report.Assets, asset.Name)
+		}
+	}
+	for subscription := range entitySubscriptions {
+		if !checkSubscriptions[subscription] {
+			report.SubscriptionsWithoutChecks = append(report.SubscriptionsWithoutChecks, subscription)
+		}
+	}
+	for subscription := range checkSubscriptions {
+		if !entitySubscriptions[subscription] {
+			report.SubscriptionsWithoutEntities = append(report.SubscriptionsWithoutEntities, subscription)
+		}
+	}
+	sort.Strings(report.Handlers)
+	sort.Strings(report.Filters)
+	sort.Strings(report.Assets)
+	sort.Strings(report.SubscriptionsWithoutChecks)
+	sort.Strings(report.SubscriptionsWithoutEntities)
+
+	return report
+}
@@ -0,0 +1,99 @@
+package actions
+
+import (
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPruneReport(t *testing.T) {
+	check := corev2.FixtureCheckConfig("check")
+	check.Handlers = []string{"slack"}
+	check.RuntimeAssets = []string{"check-plugins"}
+	check.Subscriptions = []string{"linux", "windows"}
+	check.Pipelines = []*corev2.ResourceReference{
+		{APIVersion: "core/v2", Type: "Pipeline", Name: "pipeline"},
+	}
+
+	entity := corev3.FixtureEntityConfig("entity")
+	entity.Subscriptions = []string{"linux", "database", "entity:entity"}
+	entity.Deregistration.Handler = "deregister"
+	entity.KeepaliveHandlers = []string{"ops"}
+
+	pipeline := corev2.FixturePipeline("pipeline", "default")
+	pipeline.Workflows = []*corev2.PipelineWorkflow{
+		{
+			Name: "workflow",
+			Filters: []*corev2.ResourceReference{
+				{APIVersion: "core/v2", Type: "EventFilter", Name: "production"},
+			},
+			Handler: &corev2.ResourceReference{APIVersion: "core/v2", Type: "Handler", Name: "pagerduty"},
+		},
+	}
+
+	set := corev2.FixtureSetHandler("set", "email")
+	set.Filters = []string{"business-hours"}
+
+	filter := corev2.FixtureEventFilter("production")
+	filter.RuntimeAssets = []string{"filter-lib"}
+
+	resources := PruneResources{
+		Checks:   []*corev2.CheckConfig{check},
+		Entities: []*corev3.EntityConfig{entity},
+		Filters: []*corev2.EventFilter{
+			filter,
+			corev2.FixtureEventFilter("business-hours"),
+			corev2.FixtureEventFilter("unused-filter"),
+		},
+		Handlers: []*corev2.Handler{
+			corev2.FixtureHandler("slack"),
+			corev2.FixtureHandler("pagerduty"),
+			corev2.FixtureHandler("email"),
+			corev2.FixtureHandler("deregister"),
+			corev2.FixtureHandler("keepalive"),
+			corev2.FixtureHandler("ops"),
+			corev2.FixtureHandler("unused-handler"),
+			set,
+		},
+		Pipelines: []*corev2.Pipeline{pipeline},
+		Assets: []*corev2.Asset{
+			corev2.FixtureAsset("check-plugins"),
+			corev2.FixtureAsset("filter-lib"),
+			corev2.FixtureAsset("unused-asset"),
+		},
+	}
+
+	report := NewPruneReport(resources)
+
+	// The explicit keepalive handlers of the entity replace the default one
+	assert.Equal(t, []string{"keepalive", "set", "unused-handler"}, report.Handlers)
+	assert.Equal(t, []string{"unused-filter"}, report.Filters)
+	assert.Equal(t, []string{"unused-asset"}, report.Assets)
+	assert.Equal(t, []string{"database"}, report.SubscriptionsWithoutChecks)
+	assert.Equal(t, []string{"windows"}, report.SubscriptionsWithoutEntities)
+}
+
+func TestNewPruneReportDefaultKeepaliveHandler(t *testing.T) {
+	entity := corev3.FixtureEntityConfig("entity")
+	entity.KeepaliveHandlers = nil
+
+	resources := PruneResources{
+		Entities: []*corev3.EntityConfig{entity},
+		Handlers: []*corev2.Handler{
+			corev2.FixtureHandler("keepalive"),
+			corev2.FixtureHandler("ops"),
+		},
+	}
+
+	report := NewPruneReport(resources)
+	assert.Equal(t, []string{"ops"}, report.Handlers)
+}
+
+func TestNewPruneReportEmpty(t *testing.T) {
+	report := NewPruneReport(PruneResources{})
+	assert.Empty(t, report.Handlers)
+	assert.NotNil(t, report.Handlers)
+	assert.Empty(t, report.SubscriptionsWithoutEntities)
+}
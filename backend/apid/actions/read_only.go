@@ -0,0 +1,102 @@
+package actions
+
+import (
+	"context"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+const (
+	// ReadOnlyAnnotation is the cluster config annotation that puts the API
+	// in read-only mode when it is "true": the requests that change
+	// resources are rejected, except the event submissions.
+	ReadOnlyAnnotation = "sensu.io/read_only"
+
+	// ReadOnlyReasonAnnotation is the cluster config annotation that tells
+	// why the API is in read-only mode, such as a store migration.
+	ReadOnlyReasonAnnotation = "sensu.io/read_only_reason"
+)
+
+// ReadOnlyMode is the read-only mode of the API.
+type ReadOnlyMode struct {
+	// Enabled is true while the API is in read-only mode.
+	Enabled bool `json:"enabled"`
+
+	// Reason tells why the API is in read-only mode.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReadOnlyModeFromClusterConfig returns the read-only mode set by the
+// annotations of config, which can be nil.
+func ReadOnlyModeFromClusterConfig(config *corev3.ClusterConfig) ReadOnlyMode {
+	if config == nil || config.Metadata == nil {
+		return ReadOnlyMode{}
+	}
+	annotations := config.Metadata.Annotations
+	if annotations[ReadOnlyAnnotation] != "true" {
+		return ReadOnlyMode{}
+	}
+	return ReadOnlyMode{Enabled: true, Reason: annotations[ReadOnlyReasonAnnotation]}
+}
+
+// GetClusterConfig returns the cluster config, or nil if there is none.
+func GetClusterConfig(ctx context.Context, store storev2.Interface) (*corev3.ClusterConfig, error) {
+	configs, err := storev2.Of[*corev3.ClusterConfig](store).List(ctx, storev2.ID{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	return configs[0], nil
+}
+
+// ReadOnlyController reads and sets the read-only mode of the API, stored in
+// the annotations of the cluster config.
+type ReadOnlyController struct {
+	store storev2.Interface
+}
+
+// NewReadOnlyController returns a new ReadOnlyController.
+func NewReadOnlyController(store storev2.Interface) ReadOnlyController {
+	return ReadOnlyController{store: store}
+}
+
+// Get returns the read-only mode of the API.
+func (c ReadOnlyController) Get(ctx context.Context) (ReadOnlyMode, error) {
+	config, err := GetClusterConfig(ctx, c.store)
+	if err != nil {
+		return ReadOnlyMode{}, NewError(InternalErr, err)
+	}
+	return ReadOnlyModeFromClusterConfig(config), nil
+}
+
+// Set puts the API in read-only mode, or back in read-write mode.
+func (c ReadOnlyController) Set(ctx context.Context, mode ReadOnlyMode) error {
+	config, err := GetClusterConfig(ctx, c.store)
+	if err != nil {
+		return NewError(InternalErr, err)
+	}
+	if config == nil {
+		return NewErrorf(NotFound, "cluster config not found")
+	}
+	if config.Metadata == nil {
+		config.Metadata = &corev2.ObjectMeta{}
+	}
+	if config.Metadata.Annotations == nil {
+		config.Metadata.Annotations = map[string]string{}
+	}
+	if mode.Enabled {
+		config.Metadata.Annotations[ReadOnlyAnnotation] = "true"
+		config.Metadata.Annotations[ReadOnlyReasonAnnotation] = mode.Reason
+	} else {
+		delete(config.Metadata.Annotations, ReadOnlyAnnotation)
+		delete(config.Metadata.Annotations, ReadOnlyReasonAnnotation)
+	}
+	if err := storev2.Of[*corev3.ClusterConfig](c.store).CreateOrUpdate(ctx, config); err != nil {
+		return NewError(InternalErr, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package actions
+
+import (
+	"context"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/sensu/sensu-go/testing/mockstore"
+)
+
+func TestReadOnlyModeFromClusterConfig(t *testing.T) {
+	assert.Equal(t, ReadOnlyMode{}, ReadOnlyModeFromClusterConfig(nil))
+
+	config := &corev3.ClusterConfig{Metadata: &corev2.ObjectMeta{
+		Annotations: map[string]string{
+			ReadOnlyAnnotation:       "true",
+			ReadOnlyReasonAnnotation: "backup",
+		},
+	}}
+	assert.Equal(t, ReadOnlyMode{Enabled: true, Reason: "backup"}, ReadOnlyModeFromClusterConfig(config))
+
+	config.Metadata.Annotations[ReadOnlyAnnotation] = "false"
+	assert.Equal(t, ReadOnlyMode{}, ReadOnlyModeFromClusterConfig(config))
+}
+
+func TestReadOnlyControllerSet(t *testing.T) {
+	config := &corev3.ClusterConfig{Metadata: &corev2.ObjectMeta{Name: "cluster"}}
+	stor := new(mockstore.V2MockStore)
+	cstore := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cstore)
+	cstore.On("List", mock.Anything, mock.Anything, mock.Anything).
+		Return(mockstore.WrapList[*corev3.ClusterConfig]{config}, nil)
+	cstore.On("CreateOrUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ctrl := NewReadOnlyController(stor)
+	mode := ReadOnlyMode{Enabled: true, Reason: "store migration"}
+	assert.NoError(t, ctrl.Set(context.Background(), mode))
+	assert.Equal(t, "true", config.Metadata.Annotations[ReadOnlyAnnotation])
+	assert.Equal(t, "store migration", config.Metadata.Annotations[ReadOnlyReasonAnnotation])
+
+	got, err := ctrl.Get(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, mode, got)
+
+	assert.NoError(t, ctrl.Set(context.Background(), ReadOnlyMode{}))
+	assert.Empty(t, config.Metadata.Annotations)
+	cstore.AssertNumberOfCalls(t, "CreateOrUpdate", 2)
+}
+
+func TestReadOnlyControllerSetNoClusterConfig(t *testing.T) {
+	stor := new(mockstore.V2MockStore)
+	cstore := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cstore)
+	cstore.On("List", mock.Anything, mock.Anything, mock.Anything).
+		Return(mockstore.WrapList[*corev3.ClusterConfig]{}, nil)
+
+	err := NewReadOnlyController(stor).Set(context.Background(), ReadOnlyMode{Enabled: true})
+	inferErr, ok := err.(Error)
+	assert.True(t, ok)
+	assert.Equal(t, NotFound, inferErr.Code)
+}
@@ -5,18 +5,21 @@ import (
 
 	corev2 "github.com/sensu/core/v2"
 	apitools "github.com/sensu/sensu-api-tools"
+	"github.com/sensu/sensu-go/backend/featuregate"
 	"github.com/sensu/sensu-go/version"
 )
 
 // VersionController exposes actions which a viewer can perform
 type VersionController struct {
 	clusterVersion string
+	featureGates   *featuregate.Gates
 }
 
 // NewVersionController returns a new VersionController
-func NewVersionController(clusterVersion string) VersionController {
+func NewVersionController(clusterVersion string, featureGates *featuregate.Gates) VersionController {
 	return VersionController{
 		clusterVersion: clusterVersion,
+		featureGates:   featureGates,
 	}
 }
 
@@ -27,3 +30,17 @@ func (v VersionController) GetVersion(ctx context.Context) *corev2.Version {
 		APIGroups:    apitools.APIModuleVersions(),
 	}
 }
+
+// GetFeatureGates returns the status of the feature gates of the backend
+func (v VersionController) GetFeatureGates(ctx context.Context) []featuregate.Status {
+	return v.featureGates.List()
+}
+
+// GetClusterVersion returns the lowest version run by the backends of the
+// cluster, or the configured cluster version if it is not known yet
+func (v VersionController) GetClusterVersion(ctx context.Context) string {
+	if version := v.featureGates.ClusterVersion(); version != nil {
+		return version.String()
+	}
+	return v.clusterVersion
+}
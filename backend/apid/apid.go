@@ -22,11 +22,37 @@ import (
 	"github.com/sensu/sensu-go/backend/apid/handlers"
 	"github.com/sensu/sensu-go/backend/apid/middlewares"
 	"github.com/sensu/sensu-go/backend/apid/routers"
+	"github.com/sensu/sensu-go/backend/apid/usage"
 	"github.com/sensu/sensu-go/backend/authentication"
 	"github.com/sensu/sensu-go/backend/authorization/rbac"
+	"github.com/sensu/sensu-go/backend/featuregate"
+	"github.com/sensu/sensu-go/backend/membership"
 	"github.com/sensu/sensu-go/backend/messaging"
 	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/bonsai"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const (
+	// DefaultIdleTimeout is the default duration for which idle keep-alive
+	// connections are kept open.
+	DefaultIdleTimeout = 2 * time.Minute
+
+	// DefaultMaxConcurrentStreams is the default number of concurrent
+	// HTTP/2 streams allowed per connection.
+	DefaultMaxConcurrentStreams = 250
+
+	// DefaultShutdownTimeout is the default duration given to open
+	// connections to drain when apid stops.
+	DefaultShutdownTimeout = 30 * time.Second
+
+	// DefaultIdempotencyWindow is the default duration during which the
+	// event submissions with the same idempotency key are only processed
+	// once.
+	DefaultIdempotencyWindow = time.Hour
 )
 
 // APId is the backend HTTP API.
@@ -46,6 +72,8 @@ type APId struct {
 	bus      messaging.MessageBus
 	store    storev2.Interface
 	tls      *v2.TLSOptions
+
+	shutdownTimeout time.Duration
 }
 
 // Option is a functional option.
@@ -56,14 +84,79 @@ type Config struct {
 	ListenAddress  string
 	RequestLimit   int64
 	WriteTimeout   time.Duration
+	ListCacheTTL   time.Duration
 	URL            string
 	Bus            messaging.MessageBus
 	Store          storev2.Interface
 	TLS            *v2.TLSOptions
 	Authenticator  *authentication.Authenticator
 	ClusterVersion string
+	FeatureGates   *featuregate.Gates
 	GraphQLService *graphql.Service
 	Queue          queue.Client
+	Membership     *membership.Registry
+	Schedules      routers.SchedulesController
+	EventTraces    store.EventTraceStore
+	Remediation    store.RemediationLockStore
+	Jobs           routers.JobsController
+	Keepalives     routers.KeepalivesController
+	AgentSessions  routers.AgentSessionsController
+	BusTopics      routers.BusController
+	Pipeline       routers.PipelineSimulator
+	Replayer       actions.HandlerReplayer
+	Sessions       actions.SessionVersionCounter
+
+	// Bonsai is the client with which the assets installed from Bonsai are
+	// compared with their latest version. The outdated assets routes are not
+	// mounted when it is nil.
+	Bonsai bonsai.Client
+
+	// ExtensionRouters are the routers of the extensions of the backend,
+	// mounted under /api/{group}/{version}/ after the core API.
+	ExtensionRouters []routers.Router
+
+	// IdleTimeout is the duration for which idle keep-alive connections are
+	// kept open. DefaultIdleTimeout is used when it is zero.
+	IdleTimeout time.Duration
+
+	// MaxConcurrentStreams is the number of concurrent HTTP/2 streams allowed
+	// per connection. DefaultMaxConcurrentStreams is used when it is zero.
+	MaxConcurrentStreams uint32
+
+	// ShutdownTimeout is the duration given to open connections to drain when
+	// apid stops, before they are closed. DefaultShutdownTimeout is used when
+	// it is zero.
+	ShutdownTimeout time.Duration
+
+	// CORS is the Cross-Origin Resource Sharing policy of the API.
+	CORS middlewares.CORS
+
+	// IdempotencyKeys stores the idempotency keys of the event submissions.
+	// The Idempotency-Key header of the event submissions is ignored when it
+	// is nil.
+	IdempotencyKeys store.IdempotencyKeyStore
+
+	// IdempotencyWindow is the duration during which the event submissions
+	// with the same idempotency key are only processed once.
+	IdempotencyWindow time.Duration
+
+	// Deadline is the deadline of the API requests, per class of operation.
+	// The requests of a class whose duration is zero have no deadline.
+	Deadline middlewares.Deadline
+
+	// EventsRequestLimit and BulkRequestLimit are the max sizes of the bodies
+	// of the requests that create or update events and resources in bulk.
+	// They are RequestLimit when zero.
+	EventsRequestLimit int64
+	BulkRequestLimit   int64
+
+	// Usage tracks the API usage of users and API keys. A new tracker is
+	// used when it is nil.
+	Usage *usage.Tracker
+
+	// ReadOnly rejects the changes while the API is in read-only mode. A
+	// middleware reading the cluster config of Store is used when it is nil.
+	ReadOnly *middlewares.ReadOnly
 }
 
 // New creates a new APId.
@@ -78,6 +171,25 @@ func New(c Config, opts ...Option) (*APId, error) {
 		errChan:       make(chan error, 1),
 		Authenticator: c.Authenticator,
 		RequestLimit:  c.RequestLimit,
+
+		shutdownTimeout: c.ShutdownTimeout,
+	}
+	if c.Usage == nil {
+		c.Usage = usage.NewTracker()
+	}
+	if c.ReadOnly == nil {
+		c.ReadOnly = middlewares.NewReadOnly(c.Store)
+	}
+	if a.shutdownTimeout == 0 {
+		a.shutdownTimeout = DefaultShutdownTimeout
+	}
+	idleTimeout := c.IdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = DefaultIdleTimeout
+	}
+	maxConcurrentStreams := c.MaxConcurrentStreams
+	if maxConcurrentStreams == 0 {
+		maxConcurrentStreams = DefaultMaxConcurrentStreams
 	}
 
 	// prepare TLS config
@@ -97,15 +209,40 @@ func New(c Config, opts ...Option) (*APId, error) {
 	a.CoreSubrouter = CoreSubrouter(router, c)
 	a.CoreV3Subrouter = CoreV3Subrouter(router, c)
 	a.EntityLimitedCoreSubrouter = EntityLimitedCoreSubrouter(router, c)
+	if len(c.ExtensionRouters) > 0 {
+		_ = ExtensionSubrouter(router, c)
+	}
+
+	h2s := &http2.Server{
+		MaxConcurrentStreams: maxConcurrentStreams,
+		IdleTimeout:          idleTimeout,
+	}
+
+	// The CORS policy wraps the whole router, so that preflight requests are
+	// answered before any route is matched. Every request, including
+	// preflight requests, is then identified by a request ID.
+	handler := middlewares.RequestID{}.Then(c.CORS.Then(router))
+	if c.TLS == nil {
+		// Without TLS, HTTP/2 is only available to clients with prior
+		// knowledge (h2c).
+		handler = h2c.NewHandler(handler, h2s)
+	}
 
 	a.HTTPServer = &http.Server{
 		Addr:         c.ListenAddress,
-		Handler:      router,
+		Handler:      handler,
 		WriteTimeout: c.WriteTimeout,
 		ReadTimeout:  15 * time.Second,
+		IdleTimeout:  idleTimeout,
 		TLSConfig:    tlsServerConfig,
 	}
 
+	// Negotiate HTTP/2 over TLS with ALPN, and gracefully close HTTP/2
+	// connections on shutdown.
+	if err := http2.ConfigureServer(a.HTTPServer, h2s); err != nil {
+		return nil, fmt.Errorf("failed to configure HTTP/2: %s", err)
+	}
+
 	for _, o := range opts {
 		if err := o(a); err != nil {
 			return nil, err
@@ -121,19 +258,30 @@ func NewRouter() *mux.Router {
 	router := mux.NewRouter().UseEncodedPath()
 
 	// Register a default handler when no routes match
-	router.NotFoundHandler = middlewares.SimpleLogger{}.Then(http.HandlerFunc(notFoundHandler))
+	router.NotFoundHandler = middlewares.AccessLog{}.Then(http.HandlerFunc(notFoundHandler))
 
 	return router
 }
 
+// limitRequest returns the middleware that enforces the request limits of
+// the config.
+func (cfg Config) limitRequest() middlewares.LimitRequest {
+	return middlewares.LimitRequest{
+		Limit:  cfg.RequestLimit,
+		Events: cfg.EventsRequestLimit,
+		Bulk:   cfg.BulkRequestLimit,
+	}
+}
+
 // AuthenticationSubrouter initializes a subrouter that handles all
 // authentication requests
 func AuthenticationSubrouter(router *mux.Router, cfg Config) *mux.Router {
 	subrouter := NewSubrouter(
 		router.NewRoute(),
-		middlewares.SimpleLogger{},
+		middlewares.AccessLog{},
+		cfg.Deadline,
 		middlewares.RefreshToken{},
-		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		cfg.limitRequest(),
 	)
 
 	mountRouters(subrouter,
@@ -149,16 +297,26 @@ func CoreSubrouter(router *mux.Router, cfg Config) *mux.Router {
 	subrouter := NewSubrouter(
 		router.PathPrefix("/api/{group:core}/{version:v2}/"),
 		middlewares.Namespace{},
+		cfg.Deadline,
 		middlewares.Authentication{Store: cfg.Store},
-		middlewares.SimpleLogger{},
+		middlewares.AccessLog{},
+		middlewares.Usage{Tracker: cfg.Usage},
 		middlewares.AuthorizationAttributes{},
 		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
-		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		cfg.ReadOnly,
+		cfg.limitRequest(),
+		middlewares.Deprecation{},
 		middlewares.Pagination{},
 		middlewares.Selectors{},
 	)
+	if cfg.Bonsai != nil {
+		// The outdated assets router is mounted before the assets router,
+		// whose list routes match the same paths.
+		mountRouters(subrouter, routers.NewOutdatedAssetsRouter(actions.NewOutdatedAssetsController(cfg.Store, cfg.Bonsai)))
+	}
 	mountRouters(
 		subrouter,
+		routers.NewAgentVersionsRouter(actions.NewAgentVersionsController(cfg.Store, cfg.Sessions)),
 		routers.NewAssetRouter(cfg.Store),
 		routers.NewAPIKeysRouter(cfg.Store),
 		routers.NewChecksRouter(cfg.Store, cfg.Queue),
@@ -169,12 +327,44 @@ func CoreSubrouter(router *mux.Router, cfg Config) *mux.Router {
 		routers.NewHooksRouter(cfg.Store),
 		routers.NewMutatorsRouter(cfg.Store),
 		routers.NewPipelinesRouter(cfg.Store),
+		routers.NewPruneReportRouter(actions.NewPruneReportController(cfg.Store)),
+		routers.NewReadOnlyRouter(actions.NewReadOnlyController(cfg.Store)),
 		routers.NewRolesRouter(cfg.Store),
 		routers.NewRoleBindingsRouter(cfg.Store),
 		routers.NewSilencedRouter(cfg.Store),
 		routers.NewTessenRouter(actions.NewTessenController(cfg.Store, cfg.Bus)),
 		routers.NewUsersRouter(cfg.Store),
 	)
+	if cfg.Membership != nil {
+		mountRouters(
+			subrouter,
+			routers.NewClusterMembersRouter(actions.NewClusterMembersController(cfg.Membership)),
+		)
+	}
+	if cfg.Schedules != nil {
+		mountRouters(subrouter, routers.NewSchedulesRouter(cfg.Schedules))
+	}
+	if cfg.Keepalives != nil {
+		mountRouters(subrouter, routers.NewKeepalivesRouter(cfg.Keepalives))
+	}
+	if cfg.AgentSessions != nil {
+		mountRouters(subrouter, routers.NewAgentSessionsRouter(cfg.AgentSessions))
+	}
+	if cfg.BusTopics != nil {
+		mountRouters(subrouter, routers.NewBusRouter(cfg.BusTopics))
+	}
+	if cfg.Pipeline != nil {
+		mountRouters(subrouter, routers.NewPipelineSimulationRouter(cfg.Pipeline))
+	}
+	if cfg.Replayer != nil {
+		mountRouters(subrouter, routers.NewHandlerReplayRouter(actions.NewHandlerReplayController(cfg.Store, cfg.Replayer), cfg.Jobs))
+	}
+	if cfg.Remediation != nil {
+		mountRouters(subrouter, routers.NewRemediationLocksRouter(cfg.Remediation))
+	}
+	if cfg.Usage != nil {
+		mountRouters(subrouter, routers.NewUsageRouter(cfg.Usage))
+	}
 
 	return subrouter
 }
@@ -183,11 +373,15 @@ func CoreV3Subrouter(router *mux.Router, cfg Config) *mux.Router {
 	subrouter := NewSubrouter(
 		router.PathPrefix("/api/{group:core}/{version:v3}/"),
 		middlewares.Namespace{},
+		cfg.Deadline,
 		middlewares.Authentication{Store: cfg.Store},
-		middlewares.SimpleLogger{},
+		middlewares.AccessLog{},
+		middlewares.Usage{Tracker: cfg.Usage},
 		middlewares.AuthorizationAttributes{},
 		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
-		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		cfg.ReadOnly,
+		cfg.limitRequest(),
+		middlewares.Deprecation{},
 		middlewares.Pagination{},
 		middlewares.Selectors{},
 	)
@@ -198,25 +392,62 @@ func CoreV3Subrouter(router *mux.Router, cfg Config) *mux.Router {
 	return subrouter
 }
 
+// ExtensionSubrouter initializes a subrouter that handles the requests coming
+// to the API groups of the extensions of the backend.
+func ExtensionSubrouter(router *mux.Router, cfg Config) *mux.Router {
+	subrouter := NewSubrouter(
+		router.PathPrefix("/api/{group}/{version}/"),
+		middlewares.Namespace{},
+		cfg.Deadline,
+		middlewares.Authentication{Store: cfg.Store},
+		middlewares.AccessLog{},
+		middlewares.Usage{Tracker: cfg.Usage},
+		middlewares.AuthorizationAttributes{},
+		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
+		cfg.ReadOnly,
+		cfg.limitRequest(),
+		middlewares.Deprecation{},
+		middlewares.Pagination{},
+		middlewares.Selectors{},
+	)
+	mountRouters(subrouter, cfg.ExtensionRouters...)
+	return subrouter
+}
+
 // EntityLimitedCoreSubrouter initializes a subrouter that handles all requests
 // coming to /api/core/v2 that must be gated by entity limits.
 func EntityLimitedCoreSubrouter(router *mux.Router, cfg Config) *mux.Router {
 	subrouter := NewSubrouter(
 		router.PathPrefix("/api/{group:core}/{version:v2}/"),
 		middlewares.Namespace{},
+		cfg.Deadline,
 		middlewares.Authentication{Store: cfg.Store},
-		middlewares.SimpleLogger{},
+		middlewares.AccessLog{},
+		middlewares.Usage{Tracker: cfg.Usage},
 		middlewares.AuthorizationAttributes{},
 		middlewares.Authorization{Authorizer: &rbac.Authorizer{Store: cfg.Store}},
-		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		cfg.ReadOnly,
+		cfg.limitRequest(),
+		middlewares.Deprecation{},
 		middlewares.Pagination{},
 		middlewares.Selectors{},
+		middlewares.NewListCache(cfg.ListCacheTTL),
 	)
+	if cfg.Jobs != nil {
+		// The asynchronous operations are routed before the synchronous ones
+		mountRouters(subrouter, routers.NewJobsRouter(cfg.Jobs))
+	}
+	eventsRouter := routers.NewEventsRouter(cfg.Store, cfg.Bus)
+	eventsRouter.IdempotencyKeys = cfg.IdempotencyKeys
+	eventsRouter.IdempotencyWindow = cfg.IdempotencyWindow
 	mountRouters(
 		subrouter,
 		routers.NewEntitiesRouter(cfg.Store),
-		routers.NewEventsRouter(cfg.Store, cfg.Bus),
+		eventsRouter,
 	)
+	if cfg.EventTraces != nil {
+		mountRouters(subrouter, routers.NewEventTracesRouter(cfg.EventTraces))
+	}
 
 	return subrouter
 }
@@ -226,7 +457,7 @@ func EntityLimitedCoreSubrouter(router *mux.Router, cfg Config) *mux.Router {
 func GraphQLSubrouter(router *mux.Router, cfg Config) *mux.Router {
 	subrouter := NewSubrouter(
 		router.NewRoute(),
-		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		cfg.limitRequest(),
 		// We permit requests that do not include an access token or API key,
 		// this allows unauthenticated clients to run introspecton queries or
 		// query resources that do not require authorization, such as health
@@ -235,7 +466,8 @@ func GraphQLSubrouter(router *mux.Router, cfg Config) *mux.Router {
 		// https://github.com/graphql/graphiql
 		// https://graphql.org/learn/introspection/
 		middlewares.Authentication{IgnoreUnauthorized: true, Store: cfg.Store},
-		middlewares.SimpleLogger{},
+		middlewares.AccessLog{},
+		middlewares.Usage{Tracker: cfg.Usage},
 	)
 
 	// The write timeout hangs up the request making it more difficult for
@@ -249,8 +481,9 @@ func GraphQLSubrouter(router *mux.Router, cfg Config) *mux.Router {
 	mountRouters(
 		subrouter,
 		&routers.GraphQLRouter{
-			Service: cfg.GraphQLService,
-			Timeout: timeout,
+			Service:  cfg.GraphQLService,
+			Timeout:  timeout,
+			ReadOnly: cfg.ReadOnly,
 		},
 	)
 
@@ -262,12 +495,12 @@ func GraphQLSubrouter(router *mux.Router, cfg Config) *mux.Router {
 func PublicSubrouter(router *mux.Router, cfg Config) *mux.Router {
 	subrouter := NewSubrouter(
 		router.NewRoute(),
-		middlewares.SimpleLogger{},
-		middlewares.LimitRequest{Limit: cfg.RequestLimit},
+		middlewares.AccessLog{},
+		cfg.limitRequest(),
 	)
 
 	mountRouters(subrouter,
-		routers.NewVersionRouter(actions.NewVersionController(cfg.ClusterVersion)),
+		routers.NewVersionRouter(actions.NewVersionController(cfg.ClusterVersion, cfg.FeatureGates)),
 		routers.NewTessenMetricRouter(actions.NewTessenMetricController(cfg.Bus)),
 	)
 
@@ -313,7 +546,10 @@ func (a *APId) Start() error {
 
 // Stop httpApi.
 func (a *APId) Stop() error {
-	if err := a.HTTPServer.Shutdown(context.TODO()); err != nil {
+	// Stop accepting new connections and wait for the open ones to drain
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+	if err := a.HTTPServer.Shutdown(ctx); err != nil {
 		// failure/timeout shutting down the server gracefully
 		logger.Error("failed to shutdown http server gracefully - forcing shutdown")
 		if closeErr := a.HTTPServer.Close(); closeErr != nil {
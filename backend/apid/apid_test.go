@@ -0,0 +1,135 @@
+package apid
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"golang.org/x/net/http2"
+)
+
+// freeAddress returns a local address that apid can listen on.
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func startAPId(t *testing.T, c Config, opts ...Option) *APId {
+	t.Helper()
+	c.ListenAddress = freeAddress(t)
+	a, err := New(c, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestAPIdH2C(t *testing.T) {
+	a := startAPId(t, Config{})
+	defer a.Stop()
+
+	// HTTP/2 with prior knowledge over a cleartext connection
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+	resp, err := client.Get("http://" + a.HTTPServer.Addr + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got, want := resp.ProtoMajor, 2; got != want {
+		t.Errorf("bad protocol version: got %d, want %d", got, want)
+	}
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Errorf("bad status: got %d, want %d", got, want)
+	}
+}
+
+func TestAPIdTLSALPN(t *testing.T) {
+	a := startAPId(t, Config{
+		TLS: &corev2.TLSOptions{
+			CertFile:      "../../util/ssl/etcd1.pem",
+			KeyFile:       "../../util/ssl/etcd1-key.pem",
+			TrustedCAFile: "../../util/ssl/ca.pem",
+		},
+	})
+	defer a.Stop()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: true,
+		},
+	}
+	resp, err := client.Get("https://" + a.HTTPServer.Addr + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got, want := resp.ProtoMajor, 2; got != want {
+		t.Errorf("bad protocol version: got %d, want %d", got, want)
+	}
+	if got, want := resp.TLS.NegotiatedProtocol, "h2"; got != want {
+		t.Errorf("bad negotiated protocol: got %q, want %q", got, want)
+	}
+}
+
+func TestAPIdStopShutdownTimeout(t *testing.T) {
+	// A request that never completes on its own keeps its connection open
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	blocking := func(a *APId) error {
+		a.HTTPServer.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(entered)
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		})
+		return nil
+	}
+	a := startAPId(t, Config{ShutdownTimeout: 100 * time.Millisecond}, blocking)
+
+	go func() {
+		resp, err := http.Get("http://" + a.HTTPServer.Addr + "/")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	select {
+	case <-entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request never reached apid")
+	}
+
+	start := time.Now()
+	if err := a.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+	if elapsed < 100*time.Millisecond {
+		t.Errorf("apid stopped before the shutdown timeout: %s", elapsed)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("apid did not close the connections after the shutdown timeout: %s", elapsed)
+	}
+}
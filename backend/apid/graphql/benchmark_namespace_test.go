@@ -0,0 +1,92 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/apid/graphql/schema"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// benchEntityClient is an entity client of a large dataset. When it supports
+// filtering, it returns the requested page of the selected entities, like the
+// store does; otherwise it returns every entity, chunk by chunk.
+type benchEntityClient struct {
+	MockEntityClient
+	entities  []*corev2.Entity
+	agents    []*corev2.Entity
+	filtering bool
+}
+
+func newBenchEntityClient(size int, filtering bool) *benchEntityClient {
+	client := &benchEntityClient{filtering: filtering}
+	for i := 0; i < size; i++ {
+		entity := corev2.FixtureEntity(fmt.Sprintf("entity-%d", i))
+		if i%2 == 0 {
+			entity.EntityClass = corev2.EntityProxyClass
+		} else {
+			client.agents = append(client.agents, entity)
+		}
+		client.entities = append(client.entities, entity)
+	}
+	return client
+}
+
+func (c *benchEntityClient) EntityStoreSupportsFiltering(context.Context) bool {
+	return c.filtering
+}
+
+func (c *benchEntityClient) CountEntities(context.Context) (int, error) {
+	return len(c.agents), nil
+}
+
+func (c *benchEntityClient) ListEntities(ctx context.Context, pred *store.SelectionPredicate) ([]*corev2.Entity, error) {
+	if c.filtering {
+		l, h := clampSlice(int(pred.Offset), int(pred.Offset+pred.Limit), len(c.agents))
+		return c.agents[l:h], nil
+	}
+	offset, _ := strconv.Atoi(pred.Continue)
+	l, h := clampSlice(offset, offset+int(pred.Limit), len(c.entities))
+	pred.Continue = ""
+	if h < len(c.entities) {
+		pred.Continue = strconv.Itoa(h)
+	}
+	return c.entities[l:h], nil
+}
+
+func benchmarkNamespaceEntities(b *testing.B, size int, filtering bool) {
+	resolver := &namespaceImpl{entityClient: newBenchEntityClient(size, filtering)}
+	params := schema.NamespaceEntitiesFieldResolverParams{}
+	params.Context = context.Background()
+	params.Source = corev3.FixtureNamespace("default")
+	params.Args.Limit = 50
+	params.Args.Offset = 1000
+	params.Args.Filters = []string{"class:agent"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := resolver.Entities(params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNamespaceEntitiesInMemory(b *testing.B) {
+	for _, size := range []int{10000, 100000} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			benchmarkNamespaceEntities(b, size, false)
+		})
+	}
+}
+
+func BenchmarkNamespaceEntitiesPushedDown(b *testing.B) {
+	for _, size := range []int{10000, 100000} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			benchmarkNamespaceEntities(b, size, true)
+		})
+	}
+}
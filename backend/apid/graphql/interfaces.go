@@ -32,6 +32,8 @@ type EntityClient interface {
 	UpdateEntity(context.Context, *corev2.Entity) error
 	FetchEntity(context.Context, string) (*corev2.Entity, error)
 	ListEntities(ctx context.Context, pred *store.SelectionPredicate) ([]*corev2.Entity, error)
+	CountEntities(ctx context.Context) (int, error)
+	EntityStoreSupportsFiltering(context.Context) bool
 }
 
 type EventClient interface {
@@ -89,6 +91,7 @@ type HookClient interface {
 	FetchHookConfig(ctx context.Context, name string) (*corev2.HookConfig, error)
 	CreateHookConfig(ctx context.Context, hook *corev2.HookConfig) error
 	UpdateHookConfig(ctx context.Context, hook *corev2.HookConfig) error
+	DeleteHookConfig(ctx context.Context, name string) error
 }
 
 type UserClient interface {
@@ -145,6 +145,15 @@ func (c *MockEntityClient) ListEntities(ctx context.Context, pred *store.Selecti
 	return args.Get(0).([]*corev2.Entity), args.Error(1)
 }
 
+func (c *MockEntityClient) CountEntities(ctx context.Context) (int, error) {
+	args := c.Called(ctx)
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (c *MockEntityClient) EntityStoreSupportsFiltering(ctx context.Context) bool {
+	return c.Called(ctx).Get(0).(bool)
+}
+
 type MockEventClient struct {
 	mock.Mock
 }
@@ -207,6 +216,32 @@ func (c *MockMutatorClient) DeleteMutator(ctx context.Context, name string) erro
 	return c.Called(ctx, name).Error(0)
 }
 
+type MockHookClient struct {
+	mock.Mock
+}
+
+func (c *MockHookClient) ListHookConfigs(ctx context.Context) ([]*corev2.HookConfig, error) {
+	args := c.Called(ctx)
+	return args.Get(0).([]*corev2.HookConfig), args.Error(1)
+}
+
+func (c *MockHookClient) FetchHookConfig(ctx context.Context, name string) (*corev2.HookConfig, error) {
+	args := c.Called(ctx, name)
+	return args.Get(0).(*corev2.HookConfig), args.Error(1)
+}
+
+func (c *MockHookClient) CreateHookConfig(ctx context.Context, hook *corev2.HookConfig) error {
+	return c.Called(ctx, hook).Error(0)
+}
+
+func (c *MockHookClient) UpdateHookConfig(ctx context.Context, hook *corev2.HookConfig) error {
+	return c.Called(ctx, hook).Error(0)
+}
+
+func (c *MockHookClient) DeleteHookConfig(ctx context.Context, name string) error {
+	return c.Called(ctx, name).Error(0)
+}
+
 type MockGenericClient struct {
 	mock.Mock
 }
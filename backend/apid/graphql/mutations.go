@@ -94,7 +94,7 @@ func (r *mutationsImpl) CreateCheck(p schema.MutationCreateCheckFieldResolverPar
 	check.Namespace = inputs.Namespace
 
 	rawArgs := p.ResolveParams.Args
-	if err := copyCheckInputs(&check, rawArgs["input"]); err != nil {
+	if err := copyInputProps(&check, rawArgs["input"]); err != nil {
 		return nil, err
 	}
 
@@ -123,7 +123,7 @@ func (r *mutationsImpl) UpdateCheck(p schema.MutationUpdateCheckFieldResolverPar
 	}
 
 	rawArgs := p.ResolveParams.Args
-	if err := copyCheckInputs(check, rawArgs["input"]); err != nil {
+	if err := copyInputProps(check, rawArgs["input"]); err != nil {
 		return nil, err
 	}
 
@@ -174,7 +174,9 @@ func (r *mutationsImpl) ExecuteCheck(p schema.MutationExecuteCheckFieldResolverP
 	}, nil
 }
 
-func copyCheckInputs(r *corev2.CheckConfig, args interface{}) error {
+// copyInputProps decodes the props of the given mutation input into r, only
+// overwriting the fields that were given.
+func copyInputProps(r interface{}, args interface{}) error {
 	input, ok := args.(map[string]interface{})
 	if !ok {
 		return errors.New("given unexpected arguments")
@@ -330,6 +332,86 @@ func (r *mutationsImpl) DeleteHandler(p schema.MutationDeleteHandlerFieldResolve
 	}, nil
 }
 
+//
+// Implement hook mutations
+//
+
+// CreateHook implements response to request for the 'createHook' field.
+func (r *mutationsImpl) CreateHook(p schema.MutationCreateHookFieldResolverParams) (interface{}, error) {
+	inputs := p.Args.Input
+
+	var hook corev2.HookConfig
+	hook.Name = inputs.Name
+	hook.Namespace = inputs.Namespace
+
+	rawArgs := p.ResolveParams.Args
+	if err := copyInputProps(&hook, rawArgs["input"]); err != nil {
+		return nil, err
+	}
+
+	ctx := contextWithNamespace(p.Context, inputs.Namespace)
+	client := r.svc.HookClient
+
+	err := client.CreateHookConfig(ctx, &hook)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"clientMutationId": inputs.ClientMutationID,
+		"hook":             &hook,
+	}, nil
+}
+
+// UpdateHook implements response to request for the 'updateHook' field.
+func (r *mutationsImpl) UpdateHook(p schema.MutationUpdateHookFieldResolverParams) (interface{}, error) {
+	components, _ := globalid.Decode(p.Args.Input.ID)
+	if components.Resource() != globalid.HookTranslator.ForResourceNamed() {
+		return nil, errors.New("given ID must be a hook")
+	}
+
+	ctx := setContextFromComponents(p.Context, components)
+
+	client := r.svc.HookClient
+	hook, err := client.FetchHookConfig(ctx, components.UniqueComponent())
+	if err != nil {
+		return nil, err
+	}
+
+	rawArgs := p.ResolveParams.Args
+	if err := copyInputProps(hook, rawArgs["input"]); err != nil {
+		return nil, err
+	}
+
+	err = client.UpdateHookConfig(ctx, hook)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"clientMutationId": p.Args.Input.ClientMutationID,
+		"hook":             hook,
+	}, nil
+}
+
+// DeleteHook implements response to request for the 'deleteHook' field.
+func (r *mutationsImpl) DeleteHook(p schema.MutationDeleteHookFieldResolverParams) (interface{}, error) {
+	components, _ := globalid.Decode(p.Args.Input.ID)
+	if components.Resource() != globalid.HookTranslator.ForResourceNamed() {
+		return nil, errors.New("given ID must be a hook")
+	}
+
+	ctx := setContextFromComponents(p.Context, components)
+	client := r.svc.HookClient
+
+	err := client.DeleteHookConfig(ctx, components.UniqueComponent())
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"clientMutationId": p.Args.Input.ClientMutationID,
+		"deletedId":        components.String(),
+	}, nil
+}
+
 //
 // Implement mutator mutations
 //
@@ -354,6 +436,99 @@ func (r *mutationsImpl) DeleteMutator(p schema.MutationDeleteMutatorFieldResolve
 	}, nil
 }
 
+//
+// Implement pipeline mutations
+//
+
+var pipelineTypeMeta = corev2.TypeMeta{Type: "Pipeline", APIVersion: "core/v2"}
+
+// CreatePipeline implements response to request for the 'createPipeline' field.
+func (r *mutationsImpl) CreatePipeline(p schema.MutationCreatePipelineFieldResolverParams) (interface{}, error) {
+	inputs := p.Args.Input
+
+	var pipeline corev2.Pipeline
+	pipeline.ObjectMeta = corev2.NewObjectMeta(inputs.Name, inputs.Namespace)
+
+	rawArgs := p.ResolveParams.Args
+	if err := copyInputProps(&pipeline, rawArgs["input"]); err != nil {
+		return nil, err
+	}
+
+	ctx := contextWithNamespace(p.Context, inputs.Namespace)
+	client := r.svc.GenericClient
+	if err := client.SetTypeMeta(pipelineTypeMeta); err != nil {
+		return nil, err
+	}
+
+	err := client.Create(ctx, &pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"clientMutationId": inputs.ClientMutationID,
+		"pipeline":         &pipeline,
+	}, nil
+}
+
+// UpdatePipeline implements response to request for the 'updatePipeline' field.
+func (r *mutationsImpl) UpdatePipeline(p schema.MutationUpdatePipelineFieldResolverParams) (interface{}, error) {
+	components, _ := globalid.Decode(p.Args.Input.ID)
+	if components.Resource() != globalid.PipelineTranslator.ForResourceNamed() {
+		return nil, errors.New("given ID must be a pipeline")
+	}
+
+	ctx := setContextFromComponents(p.Context, components)
+	client := r.svc.GenericClient
+	if err := client.SetTypeMeta(pipelineTypeMeta); err != nil {
+		return nil, err
+	}
+
+	var pipeline corev2.Pipeline
+	if err := client.Get(ctx, components.UniqueComponent(), &pipeline); err != nil {
+		return nil, err
+	}
+
+	// Given workflows replace the existing ones rather than being merged
+	// into them.
+	pipeline.Workflows = nil
+	rawArgs := p.ResolveParams.Args
+	if err := copyInputProps(&pipeline, rawArgs["input"]); err != nil {
+		return nil, err
+	}
+
+	err := client.Update(ctx, &pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"clientMutationId": p.Args.Input.ClientMutationID,
+		"pipeline":         &pipeline,
+	}, nil
+}
+
+// DeletePipeline implements response to request for the 'deletePipeline' field.
+func (r *mutationsImpl) DeletePipeline(p schema.MutationDeletePipelineFieldResolverParams) (interface{}, error) {
+	components, _ := globalid.Decode(p.Args.Input.ID)
+	if components.Resource() != globalid.PipelineTranslator.ForResourceNamed() {
+		return nil, errors.New("given ID must be a pipeline")
+	}
+
+	ctx := setContextFromComponents(p.Context, components)
+	client := r.svc.GenericClient
+	if err := client.SetTypeMeta(pipelineTypeMeta); err != nil {
+		return nil, err
+	}
+
+	err := client.Delete(ctx, components.UniqueComponent())
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"clientMutationId": p.Args.Input.ClientMutationID,
+		"deletedId":        components.String(),
+	}, nil
+}
+
 //
 // Implement silenced mutations
 //
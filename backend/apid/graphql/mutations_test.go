@@ -331,3 +331,276 @@ func TestMutationTypeDeleteSilenceField(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, body)
 }
+
+func TestMutationTypeCreateCheckWithSecrets(t *testing.T) {
+	inputs := schema.CreateCheckInput{Name: "a", Namespace: "default"}
+	params := schema.MutationCreateCheckFieldResolverParams{ResolveParams: graphql.ResolveParams{Context: context.Background()}}
+	params.Args.Input = &inputs
+	params.ResolveParams.Args = map[string]interface{}{
+		"input": map[string]interface{}{
+			"props": map[string]interface{}{
+				"command":  "yes",
+				"interval": 10,
+				"secrets": []interface{}{
+					map[string]interface{}{"name": "TOKEN", "secret": "api-token"},
+				},
+			},
+		},
+	}
+
+	client := new(MockCheckClient)
+	client.On("CreateCheck", mock.Anything, mock.Anything).Return(nil).Once()
+	impl := mutationsImpl{svc: ServiceConfig{CheckClient: client}}
+
+	body, err := impl.CreateCheck(params)
+	assert.NoError(t, err)
+	check := body.(map[string]interface{})["check"].(*corev2.CheckConfig)
+	assert.Equal(t, []*corev2.Secret{{Name: "TOKEN", Secret: "api-token"}}, check.Secrets)
+}
+
+func TestMutationTypeCreateHook(t *testing.T) {
+	inputs := schema.CreateHookInput{Name: "a", Namespace: "default"}
+	params := schema.MutationCreateHookFieldResolverParams{ResolveParams: graphql.ResolveParams{Context: context.Background()}}
+	params.Args.Input = &inputs
+	params.ResolveParams.Args = map[string]interface{}{
+		"input": map[string]interface{}{
+			"props": map[string]interface{}{
+				"command":       "ps aux",
+				"timeout":       10,
+				"stdin":         true,
+				"runtimeAssets": []interface{}{"ps"},
+			},
+		},
+	}
+
+	client := new(MockHookClient)
+	cfg := ServiceConfig{HookClient: client}
+	impl := mutationsImpl{svc: cfg}
+
+	// Success
+	client.On("CreateHookConfig", mock.Anything, mock.Anything).Return(nil).Once()
+	body, err := impl.CreateHook(params)
+	assert.NoError(t, err)
+	hook := body.(map[string]interface{})["hook"].(*corev2.HookConfig)
+	assert.Equal(t, "a", hook.Name)
+	assert.Equal(t, "ps aux", hook.Command)
+	assert.Equal(t, uint32(10), hook.Timeout)
+	assert.True(t, hook.Stdin)
+	assert.Equal(t, []string{"ps"}, hook.RuntimeAssets)
+
+	// Failure
+	client.On("CreateHookConfig", mock.Anything, mock.Anything).Return(errors.New("invalid")).Once()
+	body, err = impl.CreateHook(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+}
+
+func TestMutationTypeUpdateHook(t *testing.T) {
+	hook := corev2.FixtureHookConfig("a")
+	gid := globalid.HookTranslator.EncodeToString(context.Background(), hook)
+
+	inputs := schema.UpdateHookInput{ID: gid}
+	params := schema.MutationUpdateHookFieldResolverParams{ResolveParams: graphql.ResolveParams{Context: context.Background()}}
+	params.Args.Input = &inputs
+	params.ResolveParams.Args = map[string]interface{}{
+		"input": map[string]interface{}{
+			"props": map[string]interface{}{
+				"command": "yes",
+			},
+		},
+	}
+
+	client := new(MockHookClient)
+	cfg := ServiceConfig{HookClient: client}
+	impl := mutationsImpl{svc: cfg}
+
+	// Success
+	client.On("FetchHookConfig", mock.Anything, "a").Return(hook, nil).Once()
+	client.On("UpdateHookConfig", mock.Anything, hook).Return(nil).Once()
+	body, err := impl.UpdateHook(params)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, body)
+	assert.Equal(t, "yes", hook.Command)
+
+	// Failure - no hook
+	client.On("FetchHookConfig", mock.Anything, "a").Return(hook, errors.New("404")).Once()
+	body, err = impl.UpdateHook(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+
+	// Failure - update fails
+	client.On("FetchHookConfig", mock.Anything, "a").Return(hook, nil).Once()
+	client.On("UpdateHookConfig", mock.Anything, hook).Return(errors.New("fail")).Once()
+	body, err = impl.UpdateHook(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+
+	// Wrong resource
+	params.Args.Input = &schema.UpdateHookInput{ID: globalid.HandlerTranslator.EncodeToString(context.Background(), corev2.FixtureHandler("a"))}
+	body, err = impl.UpdateHook(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+	client.AssertNumberOfCalls(t, "FetchHookConfig", 3)
+}
+
+func TestMutationTypeDeleteHookField(t *testing.T) {
+	hook := corev2.FixtureHookConfig("a")
+	gid := globalid.HookTranslator.EncodeToString(context.Background(), hook)
+
+	inputs := schema.DeleteRecordInput{ID: gid}
+	params := schema.MutationDeleteHookFieldResolverParams{ResolveParams: graphql.ResolveParams{Context: context.Background()}}
+	params.Args.Input = &inputs
+
+	client := new(MockHookClient)
+	cfg := ServiceConfig{HookClient: client}
+	impl := mutationsImpl{svc: cfg}
+
+	// Success
+	client.On("DeleteHookConfig", mock.Anything, "a").Return(nil).Once()
+	body, err := impl.DeleteHook(params)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, body)
+
+	// Failure
+	client.On("DeleteHookConfig", mock.Anything, mock.Anything).Return(errors.New("err")).Once()
+	body, err = impl.DeleteHook(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+
+	// Wrong resource
+	params.Args.Input = &schema.DeleteRecordInput{ID: globalid.HandlerTranslator.EncodeToString(context.Background(), corev2.FixtureHandler("a"))}
+	body, err = impl.DeleteHook(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+}
+
+func TestMutationTypeCreatePipeline(t *testing.T) {
+	inputs := schema.CreatePipelineInput{Name: "a", Namespace: "default"}
+	params := schema.MutationCreatePipelineFieldResolverParams{ResolveParams: graphql.ResolveParams{Context: context.Background()}}
+	params.Args.Input = &inputs
+	params.ResolveParams.Args = map[string]interface{}{
+		"input": map[string]interface{}{
+			"props": map[string]interface{}{
+				"workflows": []interface{}{
+					map[string]interface{}{
+						"name": "slack",
+						"filters": []interface{}{
+							map[string]interface{}{"name": "is_incident", "type": "EventFilter", "apiVersion": "core/v2"},
+						},
+						"handler": map[string]interface{}{"name": "slack", "type": "Handler", "apiVersion": "core/v2"},
+					},
+				},
+			},
+		},
+	}
+
+	client := new(MockGenericClient)
+	client.On("SetTypeMeta", pipelineTypeMeta).Return(nil)
+	cfg := ServiceConfig{GenericClient: client}
+	impl := mutationsImpl{svc: cfg}
+
+	// Success
+	client.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+	body, err := impl.CreatePipeline(params)
+	assert.NoError(t, err)
+	pipeline := body.(map[string]interface{})["pipeline"].(*corev2.Pipeline)
+	assert.Equal(t, "a", pipeline.Name)
+	if assert.Len(t, pipeline.Workflows, 1) {
+		workflow := pipeline.Workflows[0]
+		assert.Equal(t, "slack", workflow.Name)
+		assert.Equal(t, &corev2.ResourceReference{Name: "slack", Type: "Handler", APIVersion: "core/v2"}, workflow.Handler)
+		assert.Equal(t, []*corev2.ResourceReference{{Name: "is_incident", Type: "EventFilter", APIVersion: "core/v2"}}, workflow.Filters)
+		assert.Nil(t, workflow.Mutator)
+	}
+
+	// Failure
+	client.On("Create", mock.Anything, mock.Anything).Return(errors.New("invalid")).Once()
+	body, err = impl.CreatePipeline(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+}
+
+func TestMutationTypeUpdatePipeline(t *testing.T) {
+	pipeline := corev2.FixturePipeline("a", "default")
+	gid := globalid.PipelineTranslator.EncodeToString(context.Background(), pipeline)
+
+	inputs := schema.UpdatePipelineInput{ID: gid}
+	params := schema.MutationUpdatePipelineFieldResolverParams{ResolveParams: graphql.ResolveParams{Context: context.Background()}}
+	params.Args.Input = &inputs
+	params.ResolveParams.Args = map[string]interface{}{
+		"input": map[string]interface{}{
+			"props": map[string]interface{}{
+				"workflows": []interface{}{
+					map[string]interface{}{
+						"name":    "pagerduty",
+						"handler": map[string]interface{}{"name": "pagerduty", "type": "Handler", "apiVersion": "core/v2"},
+					},
+				},
+			},
+		},
+	}
+
+	client := new(MockGenericClient)
+	client.On("SetTypeMeta", pipelineTypeMeta).Return(nil)
+	cfg := ServiceConfig{GenericClient: client}
+	impl := mutationsImpl{svc: cfg}
+
+	// Success
+	client.On("Get", mock.Anything, "a", mock.Anything).Run(func(args mock.Arguments) {
+		arg := args.Get(2).(*corev2.Pipeline)
+		*arg = *pipeline
+		arg.Workflows = []*corev2.PipelineWorkflow{{Name: "slack"}}
+	}).Return(nil).Once()
+	client.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	body, err := impl.UpdatePipeline(params)
+	assert.NoError(t, err)
+	updated := body.(map[string]interface{})["pipeline"].(*corev2.Pipeline)
+	if assert.Len(t, updated.Workflows, 1) {
+		assert.Equal(t, "pagerduty", updated.Workflows[0].Name)
+	}
+
+	// Failure - no pipeline
+	client.On("Get", mock.Anything, "a", mock.Anything).Return(errors.New("404")).Once()
+	body, err = impl.UpdatePipeline(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+
+	// Failure - update fails
+	client.On("Get", mock.Anything, "a", mock.Anything).Return(nil).Once()
+	client.On("Update", mock.Anything, mock.Anything).Return(errors.New("fail")).Once()
+	body, err = impl.UpdatePipeline(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+}
+
+func TestMutationTypeDeletePipelineField(t *testing.T) {
+	pipeline := corev2.FixturePipeline("a", "default")
+	gid := globalid.PipelineTranslator.EncodeToString(context.Background(), pipeline)
+
+	inputs := schema.DeleteRecordInput{ID: gid}
+	params := schema.MutationDeletePipelineFieldResolverParams{ResolveParams: graphql.ResolveParams{Context: context.Background()}}
+	params.Args.Input = &inputs
+
+	client := new(MockGenericClient)
+	client.On("SetTypeMeta", pipelineTypeMeta).Return(nil)
+	cfg := ServiceConfig{GenericClient: client}
+	impl := mutationsImpl{svc: cfg}
+
+	// Success
+	client.On("Delete", mock.Anything, "a").Return(nil).Once()
+	body, err := impl.DeletePipeline(params)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, body)
+
+	// Failure
+	client.On("Delete", mock.Anything, mock.Anything).Return(errors.New("err")).Once()
+	body, err = impl.DeletePipeline(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+
+	// Wrong resource
+	params.Args.Input = &schema.DeleteRecordInput{ID: globalid.HandlerTranslator.EncodeToString(context.Background(), corev2.FixtureHandler("a"))}
+	body, err = impl.DeletePipeline(params)
+	assert.Error(t, err)
+	assert.Nil(t, body)
+}
@@ -256,6 +256,20 @@ func (r *namespaceImpl) Entities(p schema.NamespaceEntitiesFieldResolverParams)
 	res := newOffsetContainer(p.Args.Offset, p.Args.Limit)
 	ctx := store.NamespaceContext(p.Context, p.Source.(*corev3.Namespace).Metadata.Name)
 
+	filters := p.Args.Filters
+	if r.entityClient.EntityStoreSupportsFiltering(ctx) {
+		plan := planEntityFilters(filters)
+		if plan.pushedDown() {
+			return r.entitiesWithInStoreFiltering(p, plan)
+		}
+		// the store narrows down the entities scanned for the residual
+		// filters
+		if plan.selector != nil {
+			ctx = storev2.EntityContextWithSelector(ctx, plan.selector)
+		}
+		filters = plan.residual
+	}
+
 	chunkSize := p.Args.Limit
 	chunkSize = maxInt(chunkSize, minChunkSizeNamespaceListEntities)
 	chunkSize = minInt(chunkSize, maxChunkSizeNamespaceListEntities)
@@ -277,7 +291,7 @@ CONTINUE:
 	}
 
 	// filter
-	matchFn, err := filter.Compile(p.Args.Filters, EntityFilters(), corev3.EntityFields)
+	matchFn, err := filter.Compile(filters, EntityFilters(), corev3.EntityFields)
 	if err != nil {
 		return res, err
 	}
@@ -335,6 +349,34 @@ CONTINUE:
 	return res, nil
 }
 
+func (r *namespaceImpl) entitiesWithInStoreFiltering(p schema.NamespaceEntitiesFieldResolverParams, plan filterPlan) (interface{}, error) {
+	res := newOffsetContainer(p.Args.Offset, p.Args.Limit)
+	ctx := store.NamespaceContext(p.Context, p.Source.(*corev3.Namespace).Metadata.Name)
+	if plan.selector != nil {
+		ctx = storev2.EntityContextWithSelector(ctx, plan.selector)
+	}
+
+	ordering, desc := listEntitiesOrdering(p.Args.OrderBy)
+	pred := &store.SelectionPredicate{
+		Ordering:   ordering,
+		Descending: desc,
+		Limit:      int64(p.Args.Limit),
+		Offset:     int64(p.Args.Offset),
+	}
+	entities, err := r.entityClient.ListEntities(ctx, pred)
+	if err != nil {
+		return res, err
+	}
+	totalCount, err := r.entityClient.CountEntities(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	res.Nodes = entities
+	res.PageInfo.totalCount = totalCount
+	return res, nil
+}
+
 func listEventsOrdering(order schema.EventsListOrder) (string, bool) {
 	switch order {
 	case schema.EventsListOrders.ENTITY:
@@ -354,15 +396,14 @@ func listEventsOrdering(order schema.EventsListOrder) (string, bool) {
 	}
 }
 
-func (r *namespaceImpl) eventsWithInStoreFiltering(p schema.NamespaceEventsFieldResolverParams) (interface{}, error) {
+func (r *namespaceImpl) eventsWithInStoreFiltering(p schema.NamespaceEventsFieldResolverParams, plan filterPlan) (interface{}, error) {
 	res := newOffsetContainer(p.Args.Offset, p.Args.Limit)
 	nsp := p.Source.(*corev3.Namespace)
 
 	ctx := store.NamespaceContext(p.Context, nsp.Metadata.Name)
 
-	selector := parseEventFilters(p.Args.Filters)
-	if selector != nil {
-		ctx = storev2.EventContextWithSelector(ctx, selector)
+	if plan.selector != nil {
+		ctx = storev2.EventContextWithSelector(ctx, plan.selector)
 	}
 
 	ordering, direction := listEventsOrdering(p.Args.OrderBy)
@@ -389,22 +430,31 @@ func (r *namespaceImpl) eventsWithInStoreFiltering(p schema.NamespaceEventsField
 
 // Events implements response to request for 'events' field.
 func (r *namespaceImpl) Events(p schema.NamespaceEventsFieldResolverParams) (interface{}, error) {
-	if r.eventClient.EventStoreSupportsFiltering(p.Context) {
-		return r.eventsWithInStoreFiltering(p)
-	}
-
 	res := newOffsetContainer(p.Args.Offset, p.Args.Limit)
 	nsp := p.Source.(*corev3.Namespace)
+	ctx := store.NamespaceContext(p.Context, nsp.Metadata.Name)
+
+	filters := p.Args.Filters
+	if r.eventClient.EventStoreSupportsFiltering(p.Context) {
+		plan := planEventFilters(filters)
+		if plan.pushedDown() {
+			return r.eventsWithInStoreFiltering(p, plan)
+		}
+		// the store narrows down the events fetched for the residual filters
+		if plan.selector != nil {
+			ctx = storev2.EventContextWithSelector(ctx, plan.selector)
+		}
+		filters = plan.residual
+	}
 
 	// fetch
-	ctx := store.NamespaceContext(p.Context, nsp.Metadata.Name)
 	results, err := listEvents(ctx, r.eventClient, "", maxSizeNamespaceListEvents)
 	if err != nil {
 		return res, err
 	}
 
 	// filter
-	matches, err := filter.Compile(p.Args.Filters, EventFilters(), corev3.EventFields)
+	matches, err := filter.Compile(filters, EventFilters(), corev3.EventFields)
 	if err != nil {
 		return res, err
 	}
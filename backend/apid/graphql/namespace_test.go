@@ -9,6 +9,7 @@ import (
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/backend/apid/graphql/schema"
 	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 	"github.com/sensu/sensu-go/graphql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -44,6 +45,7 @@ func TestNamespaceTypeCheckConfigsField(t *testing.T) {
 
 func TestNamespaceTypeEntitiesField(t *testing.T) {
 	client := new(MockEntityClient)
+	client.On("EntityStoreSupportsFiltering", mock.Anything).Return(false)
 	client.On("ListEntities", mock.Anything, mock.Anything).Return([]*corev2.Entity{
 		corev2.FixtureEntity("a"),
 		corev2.FixtureEntity("b"),
@@ -156,6 +158,67 @@ func TestNamespaceTypeEntitiesField(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestNamespaceTypeEntitiesFieldWithStoreFiltering(t *testing.T) {
+	params := schema.NamespaceEntitiesFieldResolverParams{}
+	params.Context = context.Background()
+	params.Source = corev3.FixtureNamespace("default")
+	params.Args.Limit = 2
+	params.Args.Offset = 4
+	params.Args.OrderBy = schema.EntityListOrders.ID
+
+	// Every filter is pushed down, with the pagination and the count
+	client := new(MockEntityClient)
+	client.On("EntityStoreSupportsFiltering", mock.Anything).Return(true)
+	client.On("ListEntities", mock.Anything, mock.Anything).Return([]*corev2.Entity{
+		corev2.FixtureEntity("a"),
+		corev2.FixtureEntity("b"),
+	}, nil).Once()
+	client.On("CountEntities", mock.Anything).Return(42, nil).Once()
+
+	params.Args.Filters = []string{"class:agent", "subscription:linux"}
+	resolver := &namespaceImpl{entityClient: client}
+	got, err := resolver.Entities(params)
+	require.NoError(t, err)
+	assert.Len(t, got.(offsetContainer).Nodes, 2)
+	assert.Equal(t, 42, got.(offsetContainer).PageInfo.totalCount)
+	assert.False(t, got.(offsetContainer).PageInfo.partialCount)
+
+	ctx := client.Calls[1].Arguments[0].(context.Context)
+	sel := storev2.EntitySelectorFromContext(ctx)
+	require.NotNil(t, sel)
+	assert.Len(t, sel.Operations, 2)
+	pred := client.Calls[1].Arguments[1].(*store.SelectionPredicate)
+	assert.Equal(t, int64(2), pred.Limit)
+	assert.Equal(t, int64(4), pred.Offset)
+	assert.Equal(t, corev2.EntitySortName, pred.Ordering)
+	assert.False(t, pred.Descending)
+
+	// The residual filters are evaluated in memory, over the entities
+	// selected by the store
+	client = new(MockEntityClient)
+	client.On("EntityStoreSupportsFiltering", mock.Anything).Return(true)
+	proxy := corev2.FixtureEntity("c")
+	proxy.EntityClass = corev2.EntityProxyClass
+	client.On("ListEntities", mock.Anything, mock.Anything).Return([]*corev2.Entity{
+		corev2.FixtureEntity("a"),
+		corev2.FixtureEntity("b"),
+		proxy,
+	}, nil).Once()
+
+	params.Args.Offset = 0
+	params.Args.Filters = []string{"subscription:linux", "fieldSelector:entity.name != b"}
+	resolver = &namespaceImpl{entityClient: client}
+	got, err = resolver.Entities(params)
+	require.NoError(t, err)
+	assert.Len(t, got.(offsetContainer).Nodes, 2)
+	client.AssertNotCalled(t, "CountEntities", mock.Anything)
+
+	ctx = client.Calls[1].Arguments[0].(context.Context)
+	sel = storev2.EntitySelectorFromContext(ctx)
+	require.NotNil(t, sel)
+	assert.Len(t, sel.Operations, 1)
+}
+
 func TestNamespaceTypeEventsField(t *testing.T) {
 	client := new(MockEventClient)
 	client.On("EventStoreSupportsFiltering", mock.Anything).Return(false)
@@ -125,6 +125,39 @@ type MutationDeleteHandlerFieldResolverParams struct {
 	Args MutationDeleteHandlerFieldResolverArgs
 }
 
+// MutationCreateHookFieldResolverArgs contains arguments provided to createHook when selected
+type MutationCreateHookFieldResolverArgs struct {
+	Input *CreateHookInput // Input - self descriptive
+}
+
+// MutationCreateHookFieldResolverParams contains contextual info to resolve createHook field
+type MutationCreateHookFieldResolverParams struct {
+	graphql.ResolveParams
+	Args MutationCreateHookFieldResolverArgs
+}
+
+// MutationUpdateHookFieldResolverArgs contains arguments provided to updateHook when selected
+type MutationUpdateHookFieldResolverArgs struct {
+	Input *UpdateHookInput // Input - self descriptive
+}
+
+// MutationUpdateHookFieldResolverParams contains contextual info to resolve updateHook field
+type MutationUpdateHookFieldResolverParams struct {
+	graphql.ResolveParams
+	Args MutationUpdateHookFieldResolverArgs
+}
+
+// MutationDeleteHookFieldResolverArgs contains arguments provided to deleteHook when selected
+type MutationDeleteHookFieldResolverArgs struct {
+	Input *DeleteRecordInput // Input - self descriptive
+}
+
+// MutationDeleteHookFieldResolverParams contains contextual info to resolve deleteHook field
+type MutationDeleteHookFieldResolverParams struct {
+	graphql.ResolveParams
+	Args MutationDeleteHookFieldResolverArgs
+}
+
 // MutationDeleteMutatorFieldResolverArgs contains arguments provided to deleteMutator when selected
 type MutationDeleteMutatorFieldResolverArgs struct {
 	Input *DeleteRecordInput // Input - self descriptive
@@ -136,6 +169,39 @@ type MutationDeleteMutatorFieldResolverParams struct {
 	Args MutationDeleteMutatorFieldResolverArgs
 }
 
+// MutationCreatePipelineFieldResolverArgs contains arguments provided to createPipeline when selected
+type MutationCreatePipelineFieldResolverArgs struct {
+	Input *CreatePipelineInput // Input - self descriptive
+}
+
+// MutationCreatePipelineFieldResolverParams contains contextual info to resolve createPipeline field
+type MutationCreatePipelineFieldResolverParams struct {
+	graphql.ResolveParams
+	Args MutationCreatePipelineFieldResolverArgs
+}
+
+// MutationUpdatePipelineFieldResolverArgs contains arguments provided to updatePipeline when selected
+type MutationUpdatePipelineFieldResolverArgs struct {
+	Input *UpdatePipelineInput // Input - self descriptive
+}
+
+// MutationUpdatePipelineFieldResolverParams contains contextual info to resolve updatePipeline field
+type MutationUpdatePipelineFieldResolverParams struct {
+	graphql.ResolveParams
+	Args MutationUpdatePipelineFieldResolverArgs
+}
+
+// MutationDeletePipelineFieldResolverArgs contains arguments provided to deletePipeline when selected
+type MutationDeletePipelineFieldResolverArgs struct {
+	Input *DeleteRecordInput // Input - self descriptive
+}
+
+// MutationDeletePipelineFieldResolverParams contains contextual info to resolve deletePipeline field
+type MutationDeletePipelineFieldResolverParams struct {
+	graphql.ResolveParams
+	Args MutationDeletePipelineFieldResolverArgs
+}
+
 // MutationCreateSilenceFieldResolverArgs contains arguments provided to createSilence when selected
 type MutationCreateSilenceFieldResolverArgs struct {
 	Input *CreateSilenceInput // Input - self descriptive
@@ -191,9 +257,27 @@ type MutationFieldResolvers interface {
 	// DeleteHandler implements response to request for 'deleteHandler' field.
 	DeleteHandler(p MutationDeleteHandlerFieldResolverParams) (interface{}, error)
 
+	// CreateHook implements response to request for 'createHook' field.
+	CreateHook(p MutationCreateHookFieldResolverParams) (interface{}, error)
+
+	// UpdateHook implements response to request for 'updateHook' field.
+	UpdateHook(p MutationUpdateHookFieldResolverParams) (interface{}, error)
+
+	// DeleteHook implements response to request for 'deleteHook' field.
+	DeleteHook(p MutationDeleteHookFieldResolverParams) (interface{}, error)
+
 	// DeleteMutator implements response to request for 'deleteMutator' field.
 	DeleteMutator(p MutationDeleteMutatorFieldResolverParams) (interface{}, error)
 
+	// CreatePipeline implements response to request for 'createPipeline' field.
+	CreatePipeline(p MutationCreatePipelineFieldResolverParams) (interface{}, error)
+
+	// UpdatePipeline implements response to request for 'updatePipeline' field.
+	UpdatePipeline(p MutationUpdatePipelineFieldResolverParams) (interface{}, error)
+
+	// DeletePipeline implements response to request for 'deletePipeline' field.
+	DeletePipeline(p MutationDeletePipelineFieldResolverParams) (interface{}, error)
+
 	// CreateSilence implements response to request for 'createSilence' field.
 	CreateSilence(p MutationCreateSilenceFieldResolverParams) (interface{}, error)
 
@@ -267,12 +351,48 @@ func (_ MutationAliases) DeleteHandler(p MutationDeleteHandlerFieldResolverParam
 	return val, err
 }
 
+// CreateHook implements response to request for 'createHook' field.
+func (_ MutationAliases) CreateHook(p MutationCreateHookFieldResolverParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
+// UpdateHook implements response to request for 'updateHook' field.
+func (_ MutationAliases) UpdateHook(p MutationUpdateHookFieldResolverParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
+// DeleteHook implements response to request for 'deleteHook' field.
+func (_ MutationAliases) DeleteHook(p MutationDeleteHookFieldResolverParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
 // DeleteMutator implements response to request for 'deleteMutator' field.
 func (_ MutationAliases) DeleteMutator(p MutationDeleteMutatorFieldResolverParams) (interface{}, error) {
 	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
 	return val, err
 }
 
+// CreatePipeline implements response to request for 'createPipeline' field.
+func (_ MutationAliases) CreatePipeline(p MutationCreatePipelineFieldResolverParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
+// UpdatePipeline implements response to request for 'updatePipeline' field.
+func (_ MutationAliases) UpdatePipeline(p MutationUpdatePipelineFieldResolverParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
+// DeletePipeline implements response to request for 'deletePipeline' field.
+func (_ MutationAliases) DeletePipeline(p MutationDeletePipelineFieldResolverParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
 // CreateSilence implements response to request for 'createSilence' field.
 func (_ MutationAliases) CreateSilence(p MutationCreateSilenceFieldResolverParams) (interface{}, error) {
 	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
@@ -442,6 +562,51 @@ func _ObjTypeMutationDeleteHandlerHandler(impl interface{}) graphql1.FieldResolv
 	}
 }
 
+func _ObjTypeMutationCreateHookHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		CreateHook(p MutationCreateHookFieldResolverParams) (interface{}, error)
+	})
+	return func(p graphql1.ResolveParams) (interface{}, error) {
+		frp := MutationCreateHookFieldResolverParams{ResolveParams: p}
+		err := mapstructure.Decode(p.Args, &frp.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		return resolver.CreateHook(frp)
+	}
+}
+
+func _ObjTypeMutationUpdateHookHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		UpdateHook(p MutationUpdateHookFieldResolverParams) (interface{}, error)
+	})
+	return func(p graphql1.ResolveParams) (interface{}, error) {
+		frp := MutationUpdateHookFieldResolverParams{ResolveParams: p}
+		err := mapstructure.Decode(p.Args, &frp.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		return resolver.UpdateHook(frp)
+	}
+}
+
+func _ObjTypeMutationDeleteHookHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		DeleteHook(p MutationDeleteHookFieldResolverParams) (interface{}, error)
+	})
+	return func(p graphql1.ResolveParams) (interface{}, error) {
+		frp := MutationDeleteHookFieldResolverParams{ResolveParams: p}
+		err := mapstructure.Decode(p.Args, &frp.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		return resolver.DeleteHook(frp)
+	}
+}
+
 func _ObjTypeMutationDeleteMutatorHandler(impl interface{}) graphql1.FieldResolveFn {
 	resolver := impl.(interface {
 		DeleteMutator(p MutationDeleteMutatorFieldResolverParams) (interface{}, error)
@@ -457,6 +622,51 @@ func _ObjTypeMutationDeleteMutatorHandler(impl interface{}) graphql1.FieldResolv
 	}
 }
 
+func _ObjTypeMutationCreatePipelineHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		CreatePipeline(p MutationCreatePipelineFieldResolverParams) (interface{}, error)
+	})
+	return func(p graphql1.ResolveParams) (interface{}, error) {
+		frp := MutationCreatePipelineFieldResolverParams{ResolveParams: p}
+		err := mapstructure.Decode(p.Args, &frp.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		return resolver.CreatePipeline(frp)
+	}
+}
+
+func _ObjTypeMutationUpdatePipelineHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		UpdatePipeline(p MutationUpdatePipelineFieldResolverParams) (interface{}, error)
+	})
+	return func(p graphql1.ResolveParams) (interface{}, error) {
+		frp := MutationUpdatePipelineFieldResolverParams{ResolveParams: p}
+		err := mapstructure.Decode(p.Args, &frp.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		return resolver.UpdatePipeline(frp)
+	}
+}
+
+func _ObjTypeMutationDeletePipelineHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		DeletePipeline(p MutationDeletePipelineFieldResolverParams) (interface{}, error)
+	})
+	return func(p graphql1.ResolveParams) (interface{}, error) {
+		frp := MutationDeletePipelineFieldResolverParams{ResolveParams: p}
+		err := mapstructure.Decode(p.Args, &frp.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		return resolver.DeletePipeline(frp)
+	}
+}
+
 func _ObjTypeMutationCreateSilenceHandler(impl interface{}) graphql1.FieldResolveFn {
 	resolver := impl.(interface {
 		CreateSilence(p MutationCreateSilenceFieldResolverParams) (interface{}, error)
@@ -501,6 +711,26 @@ func _ObjectTypeMutationConfigFn() graphql1.ObjectConfig {
 				Name:              "createCheck",
 				Type:              graphql.OutputType("CreateCheckPayload"),
 			},
+			"createHook": &graphql1.Field{
+				Args: graphql1.FieldConfigArgument{"input": &graphql1.ArgumentConfig{
+					Description: "self descriptive",
+					Type:        graphql1.NewNonNull(graphql.InputType("CreateHookInput")),
+				}},
+				DeprecationReason: "",
+				Description:       "Creates a new hook.",
+				Name:              "createHook",
+				Type:              graphql.OutputType("CreateHookPayload"),
+			},
+			"createPipeline": &graphql1.Field{
+				Args: graphql1.FieldConfigArgument{"input": &graphql1.ArgumentConfig{
+					Description: "self descriptive",
+					Type:        graphql1.NewNonNull(graphql.InputType("CreatePipelineInput")),
+				}},
+				DeprecationReason: "",
+				Description:       "Creates a new pipeline.",
+				Name:              "createPipeline",
+				Type:              graphql.OutputType("CreatePipelinePayload"),
+			},
 			"createSilence": &graphql1.Field{
 				Args: graphql1.FieldConfigArgument{"input": &graphql1.ArgumentConfig{
 					Description: "self descriptive",
@@ -561,6 +791,16 @@ func _ObjectTypeMutationConfigFn() graphql1.ObjectConfig {
 				Name:              "deleteHandler",
 				Type:              graphql.OutputType("DeleteRecordPayload"),
 			},
+			"deleteHook": &graphql1.Field{
+				Args: graphql1.FieldConfigArgument{"input": &graphql1.ArgumentConfig{
+					Description: "self descriptive",
+					Type:        graphql1.NewNonNull(graphql.InputType("DeleteRecordInput")),
+				}},
+				DeprecationReason: "",
+				Description:       "Removes given hook.",
+				Name:              "deleteHook",
+				Type:              graphql.OutputType("DeleteRecordPayload"),
+			},
 			"deleteMutator": &graphql1.Field{
 				Args: graphql1.FieldConfigArgument{"input": &graphql1.ArgumentConfig{
 					Description: "self descriptive",
@@ -571,6 +811,16 @@ func _ObjectTypeMutationConfigFn() graphql1.ObjectConfig {
 				Name:              "deleteMutator",
 				Type:              graphql.OutputType("DeleteRecordPayload"),
 			},
+			"deletePipeline": &graphql1.Field{
+				Args: graphql1.FieldConfigArgument{"input": &graphql1.ArgumentConfig{
+					Description: "self descriptive",
+					Type:        graphql1.NewNonNull(graphql.InputType("DeleteRecordInput")),
+				}},
+				DeprecationReason: "",
+				Description:       "Removes given pipeline.",
+				Name:              "deletePipeline",
+				Type:              graphql.OutputType("DeleteRecordPayload"),
+			},
 			"deleteSilence": &graphql1.Field{
 				Args: graphql1.FieldConfigArgument{"input": &graphql1.ArgumentConfig{
 					Description: "self descriptive",
@@ -628,6 +878,26 @@ func _ObjectTypeMutationConfigFn() graphql1.ObjectConfig {
 				Name:              "updateCheck",
 				Type:              graphql.OutputType("UpdateCheckPayload"),
 			},
+			"updateHook": &graphql1.Field{
+				Args: graphql1.FieldConfigArgument{"input": &graphql1.ArgumentConfig{
+					Description: "self descriptive",
+					Type:        graphql1.NewNonNull(graphql.InputType("UpdateHookInput")),
+				}},
+				DeprecationReason: "",
+				Description:       "Updates given hook.",
+				Name:              "updateHook",
+				Type:              graphql.OutputType("UpdateHookPayload"),
+			},
+			"updatePipeline": &graphql1.Field{
+				Args: graphql1.FieldConfigArgument{"input": &graphql1.ArgumentConfig{
+					Description: "self descriptive",
+					Type:        graphql1.NewNonNull(graphql.InputType("UpdatePipelineInput")),
+				}},
+				DeprecationReason: "",
+				Description:       "Updates given pipeline.",
+				Name:              "updatePipeline",
+				Type:              graphql.OutputType("UpdatePipelinePayload"),
+			},
 		},
 		Interfaces: []*graphql1.Interface{},
 		IsTypeOf: func(_ graphql1.IsTypeOfParams) bool {
@@ -647,18 +917,24 @@ var _ObjectTypeMutationDesc = graphql.ObjectDesc{
 	Config: _ObjectTypeMutationConfigFn,
 	FieldHandlers: map[string]graphql.FieldHandler{
 		"createCheck":       _ObjTypeMutationCreateCheckHandler,
+		"createHook":        _ObjTypeMutationCreateHookHandler,
+		"createPipeline":    _ObjTypeMutationCreatePipelineHandler,
 		"createSilence":     _ObjTypeMutationCreateSilenceHandler,
 		"deleteCheck":       _ObjTypeMutationDeleteCheckHandler,
 		"deleteEntity":      _ObjTypeMutationDeleteEntityHandler,
 		"deleteEvent":       _ObjTypeMutationDeleteEventHandler,
 		"deleteEventFilter": _ObjTypeMutationDeleteEventFilterHandler,
 		"deleteHandler":     _ObjTypeMutationDeleteHandlerHandler,
+		"deleteHook":        _ObjTypeMutationDeleteHookHandler,
 		"deleteMutator":     _ObjTypeMutationDeleteMutatorHandler,
+		"deletePipeline":    _ObjTypeMutationDeletePipelineHandler,
 		"deleteSilence":     _ObjTypeMutationDeleteSilenceHandler,
 		"executeCheck":      _ObjTypeMutationExecuteCheckHandler,
 		"putWrapped":        _ObjTypeMutationPutWrappedHandler,
 		"resolveEvent":      _ObjTypeMutationResolveEventHandler,
 		"updateCheck":       _ObjTypeMutationUpdateCheckHandler,
+		"updateHook":        _ObjTypeMutationUpdateHookHandler,
+		"updatePipeline":    _ObjTypeMutationUpdatePipelineHandler,
 	},
 }
 
@@ -923,6 +1199,8 @@ type CheckConfigInputs struct {
 	Publish bool
 	// Assets - Provide a list of valid assets that are required to execute the check.
 	Assets []string
+	// Secrets - secrets is the list of Sensu secrets to set for the check's execution environment.
+	Secrets []*SecretInput
 }
 
 // CheckConfigInputsType self descriptive
@@ -964,6 +1242,10 @@ func _InputTypeCheckConfigInputsConfigFn() graphql1.InputObjectConfig {
 				Description: "publish indicates if check requests are published for the check",
 				Type:        graphql1.Boolean,
 			},
+			"secrets": &graphql1.InputObjectFieldConfig{
+				Description: "secrets is the list of Sensu secrets to set for the check's execution environment.",
+				Type:        graphql1.NewList(graphql1.NewNonNull(graphql.InputType("SecretInput"))),
+			},
 			"subscriptions": &graphql1.InputObjectFieldConfig{
 				Description: "subscriptions refers to the list of subscribers for the check.",
 				Type:        graphql1.NewList(graphql1.NewNonNull(graphql1.String)),
@@ -1776,3 +2058,784 @@ var _ObjectTypeCreateSilencePayloadDesc = graphql.ObjectDesc{
 		"silence":          _ObjTypeCreateSilencePayloadSilenceHandler,
 	},
 }
+
+// SecretInput SecretInput references a Sensu secret from an execution environment.
+type SecretInput struct {
+	// Name - name is the name of the secret referenced in an executable command.
+	Name string
+	// Secret - secret is the name of the Sensu secret resource.
+	Secret string
+}
+
+// SecretInputType SecretInput references a Sensu secret from an execution environment.
+var SecretInputType = graphql.NewType("SecretInput", graphql.InputKind)
+
+// RegisterSecretInput registers SecretInput object type with given service.
+func RegisterSecretInput(svc *graphql.Service) {
+	svc.RegisterInput(_InputTypeSecretInputDesc)
+}
+func _InputTypeSecretInputConfigFn() graphql1.InputObjectConfig {
+	return graphql1.InputObjectConfig{
+		Description: "SecretInput references a Sensu secret from an execution environment.",
+		Fields: graphql1.InputObjectConfigFieldMap{
+			"name": &graphql1.InputObjectFieldConfig{
+				Description: "name is the name of the secret referenced in an executable command.",
+				Type:        graphql1.NewNonNull(graphql1.String),
+			},
+			"secret": &graphql1.InputObjectFieldConfig{
+				Description: "secret is the name of the Sensu secret resource.",
+				Type:        graphql1.NewNonNull(graphql1.String),
+			},
+		},
+		Name: "SecretInput",
+	}
+}
+
+// describe SecretInput's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _InputTypeSecretInputDesc = graphql.InputDesc{Config: _InputTypeSecretInputConfigFn}
+
+// HookConfigInputs self descriptive
+type HookConfigInputs struct {
+	// Command - command is the command to be executed.
+	Command string
+	// Timeout - timeout is the time in seconds until the hook is terminated.
+	Timeout int
+	// Stdin - stdin indicates if the event should be written to the hook's stdin.
+	Stdin bool
+	// RuntimeAssets - runtimeAssets are a list of assets required to execute the hook.
+	RuntimeAssets []string
+}
+
+// HookConfigInputsType self descriptive
+var HookConfigInputsType = graphql.NewType("HookConfigInputs", graphql.InputKind)
+
+// RegisterHookConfigInputs registers HookConfigInputs object type with given service.
+func RegisterHookConfigInputs(svc *graphql.Service) {
+	svc.RegisterInput(_InputTypeHookConfigInputsDesc)
+}
+func _InputTypeHookConfigInputsConfigFn() graphql1.InputObjectConfig {
+	return graphql1.InputObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.InputObjectConfigFieldMap{
+			"command": &graphql1.InputObjectFieldConfig{
+				Description: "command is the command to be executed.",
+				Type:        graphql1.String,
+			},
+			"runtimeAssets": &graphql1.InputObjectFieldConfig{
+				Description: "runtimeAssets are a list of assets required to execute the hook.",
+				Type:        graphql1.NewList(graphql1.NewNonNull(graphql1.String)),
+			},
+			"stdin": &graphql1.InputObjectFieldConfig{
+				Description: "stdin indicates if the event should be written to the hook's stdin.",
+				Type:        graphql1.Boolean,
+			},
+			"timeout": &graphql1.InputObjectFieldConfig{
+				Description: "timeout is the time in seconds until the hook is terminated.",
+				Type:        graphql1.Int,
+			},
+		},
+		Name: "HookConfigInputs",
+	}
+}
+
+// describe HookConfigInputs's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _InputTypeHookConfigInputsDesc = graphql.InputDesc{Config: _InputTypeHookConfigInputsConfigFn}
+
+// CreateHookInput self descriptive
+type CreateHookInput struct {
+	// ClientMutationID - A unique identifier for the client performing the mutation.
+	ClientMutationID string
+	// Namespace - namespace the resulting resource will belong to.
+	Namespace string
+	// Name - name of the resulting hook.
+	Name string
+	// Props - properties of the hook
+	Props *HookConfigInputs
+}
+
+// CreateHookInputType self descriptive
+var CreateHookInputType = graphql.NewType("CreateHookInput", graphql.InputKind)
+
+// RegisterCreateHookInput registers CreateHookInput object type with given service.
+func RegisterCreateHookInput(svc *graphql.Service) {
+	svc.RegisterInput(_InputTypeCreateHookInputDesc)
+}
+func _InputTypeCreateHookInputConfigFn() graphql1.InputObjectConfig {
+	return graphql1.InputObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.InputObjectConfigFieldMap{
+			"clientMutationId": &graphql1.InputObjectFieldConfig{
+				Description: "A unique identifier for the client performing the mutation.",
+				Type:        graphql1.String,
+			},
+			"name": &graphql1.InputObjectFieldConfig{
+				Description: "name of the resulting hook.",
+				Type:        graphql1.NewNonNull(graphql1.String),
+			},
+			"namespace": &graphql1.InputObjectFieldConfig{
+				DefaultValue: "default",
+				Description:  "namespace the resulting resource will belong to.",
+				Type:         graphql1.String,
+			},
+			"props": &graphql1.InputObjectFieldConfig{
+				Description: "properties of the hook",
+				Type:        graphql1.NewNonNull(graphql.InputType("HookConfigInputs")),
+			},
+		},
+		Name: "CreateHookInput",
+	}
+}
+
+// describe CreateHookInput's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _InputTypeCreateHookInputDesc = graphql.InputDesc{Config: _InputTypeCreateHookInputConfigFn}
+
+// CreateHookPayloadFieldResolvers represents a collection of methods whose products represent the
+// response values of the 'CreateHookPayload' type.
+type CreateHookPayloadFieldResolvers interface {
+	// ClientMutationID implements response to request for 'clientMutationId' field.
+	ClientMutationID(p graphql.ResolveParams) (string, error)
+
+	// Hook implements response to request for 'hook' field.
+	Hook(p graphql.ResolveParams) (interface{}, error)
+}
+
+// CreateHookPayloadAliases implements all methods on CreateHookPayloadFieldResolvers interface by using reflection to
+// match name of field to a field on the given value. Intent is reduce friction
+// of writing new resolvers by removing all the instances where you would simply
+// have the resolvers method return a field.
+type CreateHookPayloadAliases struct{}
+
+// ClientMutationID implements response to request for 'clientMutationId' field.
+func (_ CreateHookPayloadAliases) ClientMutationID(p graphql.ResolveParams) (string, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	ret, ok := val.(string)
+	if err != nil {
+		return ret, err
+	}
+	if !ok {
+		return ret, errors.New("unable to coerce value for field 'clientMutationId'")
+	}
+	return ret, err
+}
+
+// Hook implements response to request for 'hook' field.
+func (_ CreateHookPayloadAliases) Hook(p graphql.ResolveParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
+// CreateHookPayloadType self descriptive
+var CreateHookPayloadType = graphql.NewType("CreateHookPayload", graphql.ObjectKind)
+
+// RegisterCreateHookPayload registers CreateHookPayload object type with given service.
+func RegisterCreateHookPayload(svc *graphql.Service, impl CreateHookPayloadFieldResolvers) {
+	svc.RegisterObject(_ObjectTypeCreateHookPayloadDesc, impl)
+}
+func _ObjTypeCreateHookPayloadClientMutationIDHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		ClientMutationID(p graphql.ResolveParams) (string, error)
+	})
+	return func(frp graphql1.ResolveParams) (interface{}, error) {
+		return resolver.ClientMutationID(frp)
+	}
+}
+
+func _ObjTypeCreateHookPayloadHookHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		Hook(p graphql.ResolveParams) (interface{}, error)
+	})
+	return func(frp graphql1.ResolveParams) (interface{}, error) {
+		return resolver.Hook(frp)
+	}
+}
+
+func _ObjectTypeCreateHookPayloadConfigFn() graphql1.ObjectConfig {
+	return graphql1.ObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.Fields{
+			"clientMutationId": &graphql1.Field{
+				Args:              graphql1.FieldConfigArgument{},
+				DeprecationReason: "",
+				Description:       "A unique identifier for the client performing the mutation.",
+				Name:              "clientMutationId",
+				Type:              graphql1.String,
+			},
+			"hook": &graphql1.Field{
+				Args:              graphql1.FieldConfigArgument{},
+				DeprecationReason: "",
+				Description:       "The newly created hook.",
+				Name:              "hook",
+				Type:              graphql1.NewNonNull(graphql.OutputType("HookConfig")),
+			},
+		},
+		Interfaces: []*graphql1.Interface{},
+		IsTypeOf: func(_ graphql1.IsTypeOfParams) bool {
+			// NOTE:
+			// Panic by default. Intent is that when Service is invoked, values of
+			// these fields are updated with instantiated resolvers. If these
+			// defaults are called it is most certainly programmer err.
+			// If you're see this comment then: 'Whoops! Sorry, my bad.'
+			panic("Unimplemented; see CreateHookPayloadFieldResolvers.")
+		},
+		Name: "CreateHookPayload",
+	}
+}
+
+// describe CreateHookPayload's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _ObjectTypeCreateHookPayloadDesc = graphql.ObjectDesc{
+	Config: _ObjectTypeCreateHookPayloadConfigFn,
+	FieldHandlers: map[string]graphql.FieldHandler{
+		"clientMutationId": _ObjTypeCreateHookPayloadClientMutationIDHandler,
+		"hook":             _ObjTypeCreateHookPayloadHookHandler,
+	},
+}
+
+// UpdateHookInput self descriptive
+type UpdateHookInput struct {
+	// ClientMutationID - A unique identifier for the client performing the mutation.
+	ClientMutationID string
+	// ID - Global ID of the hook to update.
+	ID string
+	// Props - properties of the hook
+	Props *HookConfigInputs
+}
+
+// UpdateHookInputType self descriptive
+var UpdateHookInputType = graphql.NewType("UpdateHookInput", graphql.InputKind)
+
+// RegisterUpdateHookInput registers UpdateHookInput object type with given service.
+func RegisterUpdateHookInput(svc *graphql.Service) {
+	svc.RegisterInput(_InputTypeUpdateHookInputDesc)
+}
+func _InputTypeUpdateHookInputConfigFn() graphql1.InputObjectConfig {
+	return graphql1.InputObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.InputObjectConfigFieldMap{
+			"clientMutationId": &graphql1.InputObjectFieldConfig{
+				Description: "A unique identifier for the client performing the mutation.",
+				Type:        graphql1.String,
+			},
+			"id": &graphql1.InputObjectFieldConfig{
+				Description: "Global ID of the hook to update.",
+				Type:        graphql1.NewNonNull(graphql1.ID),
+			},
+			"props": &graphql1.InputObjectFieldConfig{
+				Description: "properties of the hook",
+				Type:        graphql1.NewNonNull(graphql.InputType("HookConfigInputs")),
+			},
+		},
+		Name: "UpdateHookInput",
+	}
+}
+
+// describe UpdateHookInput's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _InputTypeUpdateHookInputDesc = graphql.InputDesc{Config: _InputTypeUpdateHookInputConfigFn}
+
+// UpdateHookPayloadFieldResolvers represents a collection of methods whose products represent the
+// response values of the 'UpdateHookPayload' type.
+type UpdateHookPayloadFieldResolvers interface {
+	// ClientMutationID implements response to request for 'clientMutationId' field.
+	ClientMutationID(p graphql.ResolveParams) (string, error)
+
+	// Hook implements response to request for 'hook' field.
+	Hook(p graphql.ResolveParams) (interface{}, error)
+}
+
+// UpdateHookPayloadAliases implements all methods on UpdateHookPayloadFieldResolvers interface by using reflection to
+// match name of field to a field on the given value. Intent is reduce friction
+// of writing new resolvers by removing all the instances where you would simply
+// have the resolvers method return a field.
+type UpdateHookPayloadAliases struct{}
+
+// ClientMutationID implements response to request for 'clientMutationId' field.
+func (_ UpdateHookPayloadAliases) ClientMutationID(p graphql.ResolveParams) (string, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	ret, ok := val.(string)
+	if err != nil {
+		return ret, err
+	}
+	if !ok {
+		return ret, errors.New("unable to coerce value for field 'clientMutationId'")
+	}
+	return ret, err
+}
+
+// Hook implements response to request for 'hook' field.
+func (_ UpdateHookPayloadAliases) Hook(p graphql.ResolveParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
+// UpdateHookPayloadType self descriptive
+var UpdateHookPayloadType = graphql.NewType("UpdateHookPayload", graphql.ObjectKind)
+
+// RegisterUpdateHookPayload registers UpdateHookPayload object type with given service.
+func RegisterUpdateHookPayload(svc *graphql.Service, impl UpdateHookPayloadFieldResolvers) {
+	svc.RegisterObject(_ObjectTypeUpdateHookPayloadDesc, impl)
+}
+func _ObjTypeUpdateHookPayloadClientMutationIDHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		ClientMutationID(p graphql.ResolveParams) (string, error)
+	})
+	return func(frp graphql1.ResolveParams) (interface{}, error) {
+		return resolver.ClientMutationID(frp)
+	}
+}
+
+func _ObjTypeUpdateHookPayloadHookHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		Hook(p graphql.ResolveParams) (interface{}, error)
+	})
+	return func(frp graphql1.ResolveParams) (interface{}, error) {
+		return resolver.Hook(frp)
+	}
+}
+
+func _ObjectTypeUpdateHookPayloadConfigFn() graphql1.ObjectConfig {
+	return graphql1.ObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.Fields{
+			"clientMutationId": &graphql1.Field{
+				Args:              graphql1.FieldConfigArgument{},
+				DeprecationReason: "",
+				Description:       "A unique identifier for the client performing the mutation.",
+				Name:              "clientMutationId",
+				Type:              graphql1.String,
+			},
+			"hook": &graphql1.Field{
+				Args:              graphql1.FieldConfigArgument{},
+				DeprecationReason: "",
+				Description:       "The updated hook.",
+				Name:              "hook",
+				Type:              graphql1.NewNonNull(graphql.OutputType("HookConfig")),
+			},
+		},
+		Interfaces: []*graphql1.Interface{},
+		IsTypeOf: func(_ graphql1.IsTypeOfParams) bool {
+			// NOTE:
+			// Panic by default. Intent is that when Service is invoked, values of
+			// these fields are updated with instantiated resolvers. If these
+			// defaults are called it is most certainly programmer err.
+			// If you're see this comment then: 'Whoops! Sorry, my bad.'
+			panic("Unimplemented; see UpdateHookPayloadFieldResolvers.")
+		},
+		Name: "UpdateHookPayload",
+	}
+}
+
+// describe UpdateHookPayload's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _ObjectTypeUpdateHookPayloadDesc = graphql.ObjectDesc{
+	Config: _ObjectTypeUpdateHookPayloadConfigFn,
+	FieldHandlers: map[string]graphql.FieldHandler{
+		"clientMutationId": _ObjTypeUpdateHookPayloadClientMutationIDHandler,
+		"hook":             _ObjTypeUpdateHookPayloadHookHandler,
+	},
+}
+
+// ResourceReferenceInput ResourceReferenceInput references a resource by type, API version and name.
+type ResourceReferenceInput struct {
+	// Name - name is the name of the resource to reference.
+	Name string
+	// Type - type is the name of the data type of the resource to reference.
+	Type string
+	// ApiVersion - apiVersion is the API version of the resource to reference.
+	ApiVersion string
+}
+
+// ResourceReferenceInputType ResourceReferenceInput references a resource by type, API version and name.
+var ResourceReferenceInputType = graphql.NewType("ResourceReferenceInput", graphql.InputKind)
+
+// RegisterResourceReferenceInput registers ResourceReferenceInput object type with given service.
+func RegisterResourceReferenceInput(svc *graphql.Service) {
+	svc.RegisterInput(_InputTypeResourceReferenceInputDesc)
+}
+func _InputTypeResourceReferenceInputConfigFn() graphql1.InputObjectConfig {
+	return graphql1.InputObjectConfig{
+		Description: "ResourceReferenceInput references a resource by type, API version and name.",
+		Fields: graphql1.InputObjectConfigFieldMap{
+			"apiVersion": &graphql1.InputObjectFieldConfig{
+				Description: "apiVersion is the API version of the resource to reference.",
+				Type:        graphql1.NewNonNull(graphql1.String),
+			},
+			"name": &graphql1.InputObjectFieldConfig{
+				Description: "name is the name of the resource to reference.",
+				Type:        graphql1.NewNonNull(graphql1.String),
+			},
+			"type": &graphql1.InputObjectFieldConfig{
+				Description: "type is the name of the data type of the resource to reference.",
+				Type:        graphql1.NewNonNull(graphql1.String),
+			},
+		},
+		Name: "ResourceReferenceInput",
+	}
+}
+
+// describe ResourceReferenceInput's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _InputTypeResourceReferenceInputDesc = graphql.InputDesc{Config: _InputTypeResourceReferenceInputConfigFn}
+
+// PipelineWorkflowInputs self descriptive
+type PipelineWorkflowInputs struct {
+	// Name - name is the name of the workflow.
+	Name string
+	// Filters - filters are the filters the workflow uses to determine if an event is handled.
+	Filters []*ResourceReferenceInput
+	// Mutator - mutator is the mutator the workflow uses to transform an event.
+	Mutator *ResourceReferenceInput
+	// Handler - handler is the handler the workflow uses to process an event.
+	Handler *ResourceReferenceInput
+}
+
+// PipelineWorkflowInputsType self descriptive
+var PipelineWorkflowInputsType = graphql.NewType("PipelineWorkflowInputs", graphql.InputKind)
+
+// RegisterPipelineWorkflowInputs registers PipelineWorkflowInputs object type with given service.
+func RegisterPipelineWorkflowInputs(svc *graphql.Service) {
+	svc.RegisterInput(_InputTypePipelineWorkflowInputsDesc)
+}
+func _InputTypePipelineWorkflowInputsConfigFn() graphql1.InputObjectConfig {
+	return graphql1.InputObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.InputObjectConfigFieldMap{
+			"filters": &graphql1.InputObjectFieldConfig{
+				Description: "filters are the filters the workflow uses to determine if an event is handled.",
+				Type:        graphql1.NewList(graphql1.NewNonNull(graphql.InputType("ResourceReferenceInput"))),
+			},
+			"handler": &graphql1.InputObjectFieldConfig{
+				Description: "handler is the handler the workflow uses to process an event.",
+				Type:        graphql1.NewNonNull(graphql.InputType("ResourceReferenceInput")),
+			},
+			"mutator": &graphql1.InputObjectFieldConfig{
+				Description: "mutator is the mutator the workflow uses to transform an event.",
+				Type:        graphql.InputType("ResourceReferenceInput"),
+			},
+			"name": &graphql1.InputObjectFieldConfig{
+				Description: "name is the name of the workflow.",
+				Type:        graphql1.NewNonNull(graphql1.String),
+			},
+		},
+		Name: "PipelineWorkflowInputs",
+	}
+}
+
+// describe PipelineWorkflowInputs's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _InputTypePipelineWorkflowInputsDesc = graphql.InputDesc{Config: _InputTypePipelineWorkflowInputsConfigFn}
+
+// PipelineInputs self descriptive
+type PipelineInputs struct {
+	// Workflows - workflows are the workflows of the pipeline; replaces any existing workflows.
+	Workflows []*PipelineWorkflowInputs
+}
+
+// PipelineInputsType self descriptive
+var PipelineInputsType = graphql.NewType("PipelineInputs", graphql.InputKind)
+
+// RegisterPipelineInputs registers PipelineInputs object type with given service.
+func RegisterPipelineInputs(svc *graphql.Service) {
+	svc.RegisterInput(_InputTypePipelineInputsDesc)
+}
+func _InputTypePipelineInputsConfigFn() graphql1.InputObjectConfig {
+	return graphql1.InputObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.InputObjectConfigFieldMap{
+			"workflows": &graphql1.InputObjectFieldConfig{
+				Description: "workflows are the workflows of the pipeline; replaces any existing workflows.",
+				Type:        graphql1.NewList(graphql1.NewNonNull(graphql.InputType("PipelineWorkflowInputs"))),
+			},
+		},
+		Name: "PipelineInputs",
+	}
+}
+
+// describe PipelineInputs's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _InputTypePipelineInputsDesc = graphql.InputDesc{Config: _InputTypePipelineInputsConfigFn}
+
+// CreatePipelineInput self descriptive
+type CreatePipelineInput struct {
+	// ClientMutationID - A unique identifier for the client performing the mutation.
+	ClientMutationID string
+	// Namespace - namespace the resulting resource will belong to.
+	Namespace string
+	// Name - name of the resulting pipeline.
+	Name string
+	// Props - properties of the pipeline
+	Props *PipelineInputs
+}
+
+// CreatePipelineInputType self descriptive
+var CreatePipelineInputType = graphql.NewType("CreatePipelineInput", graphql.InputKind)
+
+// RegisterCreatePipelineInput registers CreatePipelineInput object type with given service.
+func RegisterCreatePipelineInput(svc *graphql.Service) {
+	svc.RegisterInput(_InputTypeCreatePipelineInputDesc)
+}
+func _InputTypeCreatePipelineInputConfigFn() graphql1.InputObjectConfig {
+	return graphql1.InputObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.InputObjectConfigFieldMap{
+			"clientMutationId": &graphql1.InputObjectFieldConfig{
+				Description: "A unique identifier for the client performing the mutation.",
+				Type:        graphql1.String,
+			},
+			"name": &graphql1.InputObjectFieldConfig{
+				Description: "name of the resulting pipeline.",
+				Type:        graphql1.NewNonNull(graphql1.String),
+			},
+			"namespace": &graphql1.InputObjectFieldConfig{
+				DefaultValue: "default",
+				Description:  "namespace the resulting resource will belong to.",
+				Type:         graphql1.String,
+			},
+			"props": &graphql1.InputObjectFieldConfig{
+				Description: "properties of the pipeline",
+				Type:        graphql1.NewNonNull(graphql.InputType("PipelineInputs")),
+			},
+		},
+		Name: "CreatePipelineInput",
+	}
+}
+
+// describe CreatePipelineInput's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _InputTypeCreatePipelineInputDesc = graphql.InputDesc{Config: _InputTypeCreatePipelineInputConfigFn}
+
+// CreatePipelinePayloadFieldResolvers represents a collection of methods whose products represent the
+// response values of the 'CreatePipelinePayload' type.
+type CreatePipelinePayloadFieldResolvers interface {
+	// ClientMutationID implements response to request for 'clientMutationId' field.
+	ClientMutationID(p graphql.ResolveParams) (string, error)
+
+	// Pipeline implements response to request for 'pipeline' field.
+	Pipeline(p graphql.ResolveParams) (interface{}, error)
+}
+
+// CreatePipelinePayloadAliases implements all methods on CreatePipelinePayloadFieldResolvers interface by using reflection to
+// match name of field to a field on the given value. Intent is reduce friction
+// of writing new resolvers by removing all the instances where you would simply
+// have the resolvers method return a field.
+type CreatePipelinePayloadAliases struct{}
+
+// ClientMutationID implements response to request for 'clientMutationId' field.
+func (_ CreatePipelinePayloadAliases) ClientMutationID(p graphql.ResolveParams) (string, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	ret, ok := val.(string)
+	if err != nil {
+		return ret, err
+	}
+	if !ok {
+		return ret, errors.New("unable to coerce value for field 'clientMutationId'")
+	}
+	return ret, err
+}
+
+// Pipeline implements response to request for 'pipeline' field.
+func (_ CreatePipelinePayloadAliases) Pipeline(p graphql.ResolveParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
+// CreatePipelinePayloadType self descriptive
+var CreatePipelinePayloadType = graphql.NewType("CreatePipelinePayload", graphql.ObjectKind)
+
+// RegisterCreatePipelinePayload registers CreatePipelinePayload object type with given service.
+func RegisterCreatePipelinePayload(svc *graphql.Service, impl CreatePipelinePayloadFieldResolvers) {
+	svc.RegisterObject(_ObjectTypeCreatePipelinePayloadDesc, impl)
+}
+func _ObjTypeCreatePipelinePayloadClientMutationIDHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		ClientMutationID(p graphql.ResolveParams) (string, error)
+	})
+	return func(frp graphql1.ResolveParams) (interface{}, error) {
+		return resolver.ClientMutationID(frp)
+	}
+}
+
+func _ObjTypeCreatePipelinePayloadPipelineHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		Pipeline(p graphql.ResolveParams) (interface{}, error)
+	})
+	return func(frp graphql1.ResolveParams) (interface{}, error) {
+		return resolver.Pipeline(frp)
+	}
+}
+
+func _ObjectTypeCreatePipelinePayloadConfigFn() graphql1.ObjectConfig {
+	return graphql1.ObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.Fields{
+			"clientMutationId": &graphql1.Field{
+				Args:              graphql1.FieldConfigArgument{},
+				DeprecationReason: "",
+				Description:       "A unique identifier for the client performing the mutation.",
+				Name:              "clientMutationId",
+				Type:              graphql1.String,
+			},
+			"pipeline": &graphql1.Field{
+				Args:              graphql1.FieldConfigArgument{},
+				DeprecationReason: "",
+				Description:       "The newly created pipeline.",
+				Name:              "pipeline",
+				Type:              graphql1.NewNonNull(graphql.OutputType("CoreV2Pipeline")),
+			},
+		},
+		Interfaces: []*graphql1.Interface{},
+		IsTypeOf: func(_ graphql1.IsTypeOfParams) bool {
+			// NOTE:
+			// Panic by default. Intent is that when Service is invoked, values of
+			// these fields are updated with instantiated resolvers. If these
+			// defaults are called it is most certainly programmer err.
+			// If you're see this comment then: 'Whoops! Sorry, my bad.'
+			panic("Unimplemented; see CreatePipelinePayloadFieldResolvers.")
+		},
+		Name: "CreatePipelinePayload",
+	}
+}
+
+// describe CreatePipelinePayload's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _ObjectTypeCreatePipelinePayloadDesc = graphql.ObjectDesc{
+	Config: _ObjectTypeCreatePipelinePayloadConfigFn,
+	FieldHandlers: map[string]graphql.FieldHandler{
+		"clientMutationId": _ObjTypeCreatePipelinePayloadClientMutationIDHandler,
+		"pipeline":         _ObjTypeCreatePipelinePayloadPipelineHandler,
+	},
+}
+
+// UpdatePipelineInput self descriptive
+type UpdatePipelineInput struct {
+	// ClientMutationID - A unique identifier for the client performing the mutation.
+	ClientMutationID string
+	// ID - Global ID of the pipeline to update.
+	ID string
+	// Props - properties of the pipeline
+	Props *PipelineInputs
+}
+
+// UpdatePipelineInputType self descriptive
+var UpdatePipelineInputType = graphql.NewType("UpdatePipelineInput", graphql.InputKind)
+
+// RegisterUpdatePipelineInput registers UpdatePipelineInput object type with given service.
+func RegisterUpdatePipelineInput(svc *graphql.Service) {
+	svc.RegisterInput(_InputTypeUpdatePipelineInputDesc)
+}
+func _InputTypeUpdatePipelineInputConfigFn() graphql1.InputObjectConfig {
+	return graphql1.InputObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.InputObjectConfigFieldMap{
+			"clientMutationId": &graphql1.InputObjectFieldConfig{
+				Description: "A unique identifier for the client performing the mutation.",
+				Type:        graphql1.String,
+			},
+			"id": &graphql1.InputObjectFieldConfig{
+				Description: "Global ID of the pipeline to update.",
+				Type:        graphql1.NewNonNull(graphql1.ID),
+			},
+			"props": &graphql1.InputObjectFieldConfig{
+				Description: "properties of the pipeline",
+				Type:        graphql1.NewNonNull(graphql.InputType("PipelineInputs")),
+			},
+		},
+		Name: "UpdatePipelineInput",
+	}
+}
+
+// describe UpdatePipelineInput's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _InputTypeUpdatePipelineInputDesc = graphql.InputDesc{Config: _InputTypeUpdatePipelineInputConfigFn}
+
+// UpdatePipelinePayloadFieldResolvers represents a collection of methods whose products represent the
+// response values of the 'UpdatePipelinePayload' type.
+type UpdatePipelinePayloadFieldResolvers interface {
+	// ClientMutationID implements response to request for 'clientMutationId' field.
+	ClientMutationID(p graphql.ResolveParams) (string, error)
+
+	// Pipeline implements response to request for 'pipeline' field.
+	Pipeline(p graphql.ResolveParams) (interface{}, error)
+}
+
+// UpdatePipelinePayloadAliases implements all methods on UpdatePipelinePayloadFieldResolvers interface by using reflection to
+// match name of field to a field on the given value. Intent is reduce friction
+// of writing new resolvers by removing all the instances where you would simply
+// have the resolvers method return a field.
+type UpdatePipelinePayloadAliases struct{}
+
+// ClientMutationID implements response to request for 'clientMutationId' field.
+func (_ UpdatePipelinePayloadAliases) ClientMutationID(p graphql.ResolveParams) (string, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	ret, ok := val.(string)
+	if err != nil {
+		return ret, err
+	}
+	if !ok {
+		return ret, errors.New("unable to coerce value for field 'clientMutationId'")
+	}
+	return ret, err
+}
+
+// Pipeline implements response to request for 'pipeline' field.
+func (_ UpdatePipelinePayloadAliases) Pipeline(p graphql.ResolveParams) (interface{}, error) {
+	val, err := graphql.DefaultResolver(p.Source, p.Info.FieldName)
+	return val, err
+}
+
+// UpdatePipelinePayloadType self descriptive
+var UpdatePipelinePayloadType = graphql.NewType("UpdatePipelinePayload", graphql.ObjectKind)
+
+// RegisterUpdatePipelinePayload registers UpdatePipelinePayload object type with given service.
+func RegisterUpdatePipelinePayload(svc *graphql.Service, impl UpdatePipelinePayloadFieldResolvers) {
+	svc.RegisterObject(_ObjectTypeUpdatePipelinePayloadDesc, impl)
+}
+func _ObjTypeUpdatePipelinePayloadClientMutationIDHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		ClientMutationID(p graphql.ResolveParams) (string, error)
+	})
+	return func(frp graphql1.ResolveParams) (interface{}, error) {
+		return resolver.ClientMutationID(frp)
+	}
+}
+
+func _ObjTypeUpdatePipelinePayloadPipelineHandler(impl interface{}) graphql1.FieldResolveFn {
+	resolver := impl.(interface {
+		Pipeline(p graphql.ResolveParams) (interface{}, error)
+	})
+	return func(frp graphql1.ResolveParams) (interface{}, error) {
+		return resolver.Pipeline(frp)
+	}
+}
+
+func _ObjectTypeUpdatePipelinePayloadConfigFn() graphql1.ObjectConfig {
+	return graphql1.ObjectConfig{
+		Description: "self descriptive",
+		Fields: graphql1.Fields{
+			"clientMutationId": &graphql1.Field{
+				Args:              graphql1.FieldConfigArgument{},
+				DeprecationReason: "",
+				Description:       "A unique identifier for the client performing the mutation.",
+				Name:              "clientMutationId",
+				Type:              graphql1.String,
+			},
+			"pipeline": &graphql1.Field{
+				Args:              graphql1.FieldConfigArgument{},
+				DeprecationReason: "",
+				Description:       "The updated pipeline.",
+				Name:              "pipeline",
+				Type:              graphql1.NewNonNull(graphql.OutputType("CoreV2Pipeline")),
+			},
+		},
+		Interfaces: []*graphql1.Interface{},
+		IsTypeOf: func(_ graphql1.IsTypeOfParams) bool {
+			// NOTE:
+			// Panic by default. Intent is that when Service is invoked, values of
+			// these fields are updated with instantiated resolvers. If these
+			// defaults are called it is most certainly programmer err.
+			// If you're see this comment then: 'Whoops! Sorry, my bad.'
+			panic("Unimplemented; see UpdatePipelinePayloadFieldResolvers.")
+		},
+		Name: "UpdatePipelinePayload",
+	}
+}
+
+// describe UpdatePipelinePayload's configuration; kept private to avoid unintentional tampering of configuration at runtime.
+var _ObjectTypeUpdatePipelinePayloadDesc = graphql.ObjectDesc{
+	Config: _ObjectTypeUpdatePipelinePayloadConfigFn,
+	FieldHandlers: map[string]graphql.FieldHandler{
+		"clientMutationId": _ObjTypeUpdatePipelinePayloadClientMutationIDHandler,
+		"pipeline":         _ObjTypeUpdatePipelinePayloadPipelineHandler,
+	},
+}
@@ -6,101 +6,171 @@ import (
 	"github.com/sensu/sensu-go/backend/selector"
 )
 
-func parseEventFilters(statements []string) *selector.Selector {
-	const statementSeparator = ":"
+const (
+	statementSeparator = ":"
+
+	entityClassField         = "entity.entity_class"
+	entitySubscriptionsField = "entity.subscriptions"
+)
+
+// filterPlan is the plan of the evaluation of the filter statements of a
+// connection. The statements that the store can evaluate are pushed down to
+// it as a selector, and the residual statements are evaluated in memory.
+type filterPlan struct {
+	selector *selector.Selector
+	residual []string
+}
+
+// pushedDown returns true if the store evaluates every statement, in which
+// case pagination and counting can be pushed down to the store too.
+func (p filterPlan) pushedDown() bool {
+	return len(p.residual) == 0
+}
+
+func newFilterPlan(selectors []*selector.Selector, residual []string) filterPlan {
+	plan := filterPlan{residual: residual}
+	if len(selectors) > 0 {
+		plan.selector = selector.Merge(selectors...)
+	}
+	return plan
+}
+
+func splitStatement(statement string) (string, string, bool) {
+	ss := strings.SplitN(statement, statementSeparator, 2)
+	if len(ss) != 2 {
+		return "", "", false
+	}
+	return ss[0], ss[1], true
+}
+
+func fieldOperation(lvalue string, operator selector.Operator, rvalue string) *selector.Selector {
+	return &selector.Selector{
+		Operations: []selector.Operation{
+			{
+				LValue:        lvalue,
+				Operator:      operator,
+				RValues:       []string{rvalue},
+				OperationType: selector.OperationTypeFieldSelector,
+			},
+		},
+	}
+}
+
+// planEventFilters plans the filter statements of an event connection. The
+// statements that can't be expressed as a selector, such as status:unknown or
+// invalid selectors, are evaluated in memory.
+func planEventFilters(statements []string) filterPlan {
 	var statusMap = map[string]string{
-		"passing": "0",
-		"warning": "1",
-		"critial": "2",
+		"passing":  "0",
+		"warning":  "1",
+		"critical": "2",
 	}
 	selectors := make([]*selector.Selector, 0, len(statements))
+	residual := []string{}
 	for _, s := range statements {
-		ss := strings.SplitN(s, statementSeparator, 2)
-		if len(ss) != 2 {
-			logger.WithField("selector", ss).Warn("invalid selector")
+		key, value, ok := splitStatement(s)
+		if !ok {
+			residual = append(residual, s)
 			continue
 		}
-		switch ss[0] {
+		switch key {
 		case "fieldSelector":
-			sel, err := selector.ParseFieldSelector(ss[1])
+			sel, err := selector.ParseFieldSelector(value)
 			if err != nil {
-				logger.WithField("selector", ss).WithError(err).Warn("invalid selector")
+				residual = append(residual, s)
 				continue
 			}
 			selectors = append(selectors, sel)
 		case "labelSelector":
-			sel, err := selector.ParseLabelSelector(ss[1])
+			sel, err := selector.ParseLabelSelector(value)
 			if err != nil {
-				logger.WithField("selector", ss).WithError(err).Warn("invalid selector")
+				residual = append(residual, s)
 				continue
 			}
 			selectors = append(selectors, sel)
 		case "silenced":
-			sel := &selector.Selector{
-				Operations: []selector.Operation{
-					{
-						LValue:   "event.is_silenced",
-						Operator: selector.DoubleEqualSignOperator,
-						RValues:  []string{ss[1]},
-					},
-				},
-			}
-			selectors = append(selectors, sel)
+			selectors = append(selectors, fieldOperation("event.is_silenced", selector.DoubleEqualSignOperator, value))
 		case "entity":
-			sel := &selector.Selector{
-				Operations: []selector.Operation{
-					{
-						LValue:   "event.entity.name",
-						Operator: selector.DoubleEqualSignOperator,
-						RValues:  []string{ss[1]},
-					},
-				},
-			}
-			selectors = append(selectors, sel)
+			selectors = append(selectors, fieldOperation("event.entity.name", selector.DoubleEqualSignOperator, value))
 		case "check":
-			sel := &selector.Selector{
-				Operations: []selector.Operation{
-					{
-						LValue:   "event.check.name",
-						Operator: selector.DoubleEqualSignOperator,
-						RValues:  []string{ss[1]},
-					},
-				},
-			}
-			selectors = append(selectors, sel)
+			selectors = append(selectors, fieldOperation("event.check.name", selector.DoubleEqualSignOperator, value))
 		case "status":
-			status, ok := statusMap[ss[1]]
+			if value == "incident" {
+				selectors = append(selectors, fieldOperation("event.check.status", selector.NotEqualOperator, "0"))
+				continue
+			}
+			status, ok := statusMap[value]
 			if !ok {
-				if ss[1] == "incident" {
-					sel := &selector.Selector{
-						Operations: []selector.Operation{
-							{
-								LValue:   "event.check.status",
-								Operator: selector.NotEqualOperator,
-								RValues:  []string{"0"},
-							},
-						},
-					}
-					selectors = append(selectors, sel)
-				}
-				// we can't represent "incident" or "unknown" so avoid selecting
-				// this altogether.
+				// unknown statuses are greater than 2, which a selector can't
+				// express
+				residual = append(residual, s)
+				continue
+			}
+			selectors = append(selectors, fieldOperation("event.check.status", selector.DoubleEqualSignOperator, status))
+		default:
+			residual = append(residual, s)
+		}
+	}
+	return newFilterPlan(selectors, residual)
+}
+
+// planEntityFilters plans the filter statements of an entity connection. The
+// store can select entities by class, subscription and labels; the other
+// statements are evaluated in memory.
+func planEntityFilters(statements []string) filterPlan {
+	selectors := make([]*selector.Selector, 0, len(statements))
+	residual := []string{}
+	for _, s := range statements {
+		key, value, ok := splitStatement(s)
+		if !ok {
+			residual = append(residual, s)
+			continue
+		}
+		switch key {
+		case "class":
+			selectors = append(selectors, fieldOperation(entityClassField, selector.DoubleEqualSignOperator, value))
+		case "subscription":
+			selectors = append(selectors, fieldOperation(value, selector.InOperator, entitySubscriptionsField))
+		case "labelSelector":
+			sel, err := selector.ParseLabelSelector(value)
+			if err != nil {
+				residual = append(residual, s)
 				continue
 			}
-			sel := &selector.Selector{
-				Operations: []selector.Operation{
-					{
-						LValue:   "event.check.status",
-						Operator: selector.DoubleEqualSignOperator,
-						RValues:  []string{status},
-					},
-				},
+			selectors = append(selectors, sel)
+		case "fieldSelector":
+			sel, err := selector.ParseFieldSelector(value)
+			if err != nil || !entitySelectable(sel) {
+				residual = append(residual, s)
+				continue
 			}
 			selectors = append(selectors, sel)
+		default:
+			residual = append(residual, s)
 		}
 	}
-	if len(selectors) == 0 {
-		return nil
+	return newFilterPlan(selectors, residual)
+}
+
+// entitySelectable returns true if the store can evaluate every operation of
+// a field selector of entities.
+func entitySelectable(sel *selector.Selector) bool {
+	for _, op := range sel.Operations {
+		switch {
+		case op.OperationType == selector.OperationTypeLabelSelector:
+		case op.LValue == entityClassField:
+			switch op.Operator {
+			case selector.DoubleEqualSignOperator, selector.NotEqualOperator, selector.InOperator, selector.NotInOperator:
+			default:
+				return false
+			}
+		case len(op.RValues) == 1 && op.RValues[0] == entitySubscriptionsField:
+			if op.Operator != selector.InOperator && op.Operator != selector.NotInOperator {
+				return false
+			}
+		default:
+			return false
+		}
 	}
-	return selector.Merge(selectors...)
+	return true
 }
@@ -0,0 +1,116 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/sensu/sensu-go/backend/selector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanEventFilters(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statements []string
+		operations []selector.Operation
+		residual   []string
+	}{
+		{
+			name: "no filters",
+		},
+		{
+			name:       "pushed down",
+			statements: []string{"check:disk", "entity:server", "status:critical", "silenced:true"},
+			operations: []selector.Operation{
+				{LValue: "event.check.name", Operator: selector.DoubleEqualSignOperator, RValues: []string{"disk"}},
+				{LValue: "event.entity.name", Operator: selector.DoubleEqualSignOperator, RValues: []string{"server"}},
+				{LValue: "event.check.status", Operator: selector.DoubleEqualSignOperator, RValues: []string{"2"}},
+				{LValue: "event.is_silenced", Operator: selector.DoubleEqualSignOperator, RValues: []string{"true"}},
+			},
+		},
+		{
+			name:       "incident",
+			statements: []string{"status:incident"},
+			operations: []selector.Operation{
+				{LValue: "event.check.status", Operator: selector.NotEqualOperator, RValues: []string{"0"}},
+			},
+		},
+		{
+			name:       "unknown status is evaluated in memory",
+			statements: []string{"check:disk", "status:unknown"},
+			operations: []selector.Operation{
+				{LValue: "event.check.name", Operator: selector.DoubleEqualSignOperator, RValues: []string{"disk"}},
+			},
+			residual: []string{"status:unknown"},
+		},
+		{
+			name:       "invalid selectors are evaluated in memory",
+			statements: []string{"labelSelector:region in", "unknown:value", "nonsense"},
+			residual:   []string{"labelSelector:region in", "unknown:value", "nonsense"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := planEventFilters(tc.statements)
+			if len(tc.operations) == 0 {
+				assert.Nil(t, plan.selector)
+			} else {
+				assert.Equal(t, tc.operations, plan.selector.Operations)
+			}
+			assert.ElementsMatch(t, tc.residual, plan.residual)
+			assert.Equal(t, len(tc.residual) == 0, plan.pushedDown())
+		})
+	}
+}
+
+func TestPlanEntityFilters(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statements []string
+		operations int
+		residual   []string
+	}{
+		{
+			name:       "class and subscription",
+			statements: []string{"class:agent", "subscription:linux"},
+			operations: 2,
+		},
+		{
+			name:       "label selector",
+			statements: []string{`labelSelector:region == "us-west-1" && tier in [web, db]`},
+			operations: 2,
+		},
+		{
+			name:       "selectable field selector",
+			statements: []string{"fieldSelector:entity.entity_class != proxy && linux in entity.subscriptions"},
+			operations: 2,
+		},
+		{
+			name:       "field selectors of other fields are evaluated in memory",
+			statements: []string{"class:agent", "fieldSelector:entity.system.os == linux"},
+			operations: 1,
+			residual:   []string{"fieldSelector:entity.system.os == linux"},
+		},
+		{
+			name:       "unknown filters are evaluated in memory",
+			statements: []string{"name:server"},
+			residual:   []string{"name:server"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := planEntityFilters(tc.statements)
+			if tc.operations == 0 {
+				assert.Nil(t, plan.selector)
+			} else {
+				assert.Len(t, plan.selector.Operations, tc.operations)
+			}
+			assert.ElementsMatch(t, tc.residual, plan.residual)
+		})
+	}
+
+	plan := planEntityFilters([]string{"class:agent", "subscription:linux"})
+	assert.Equal(t, []selector.Operation{
+		{LValue: entityClassField, Operator: selector.DoubleEqualSignOperator, RValues: []string{"agent"}},
+		{LValue: "linux", Operator: selector.InOperator, RValues: []string{entitySubscriptionsField}},
+	}, plan.selector.Operations)
+}
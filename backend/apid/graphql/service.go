@@ -196,16 +196,29 @@ func NewService(cfg ServiceConfig) (*Service, error) {
 	schema.RegisterCheckConfigInputs(svc)
 	schema.RegisterCreateCheckInput(svc)
 	schema.RegisterCreateCheckPayload(svc, &checkMutationPayload{})
+	schema.RegisterCreateHookInput(svc)
+	schema.RegisterCreateHookPayload(svc, &schema.CreateHookPayloadAliases{})
+	schema.RegisterCreatePipelineInput(svc)
+	schema.RegisterCreatePipelinePayload(svc, &schema.CreatePipelinePayloadAliases{})
 	schema.RegisterCreateSilenceInput(svc)
 	schema.RegisterCreateSilencePayload(svc, &schema.CreateSilencePayloadAliases{})
 	schema.RegisterDeleteRecordInput(svc)
 	schema.RegisterDeleteRecordPayload(svc, &deleteRecordPayload{})
 	schema.RegisterExecuteCheckInput(svc)
 	schema.RegisterExecuteCheckPayload(svc, &schema.ExecuteCheckPayloadAliases{})
+	schema.RegisterHookConfigInputs(svc)
+	schema.RegisterPipelineInputs(svc)
+	schema.RegisterPipelineWorkflowInputs(svc)
+	schema.RegisterResourceReferenceInput(svc)
 	schema.RegisterResolveEventInput(svc)
+	schema.RegisterSecretInput(svc)
 	schema.RegisterSilenceInputs(svc)
 	schema.RegisterUpdateCheckInput(svc)
 	schema.RegisterUpdateCheckPayload(svc, &checkMutationPayload{})
+	schema.RegisterUpdateHookInput(svc)
+	schema.RegisterUpdateHookPayload(svc, &schema.UpdateHookPayloadAliases{})
+	schema.RegisterUpdatePipelineInput(svc)
+	schema.RegisterUpdatePipelinePayload(svc, &schema.UpdatePipelinePayloadAliases{})
 	schema.RegisterPutWrappedPayload(svc, &schema.PutWrappedPayloadAliases{})
 
 	// Errors
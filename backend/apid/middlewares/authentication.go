@@ -8,6 +8,7 @@ import (
 
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/apid/request"
 	"github.com/sensu/sensu-go/backend/authentication/bcrypt"
 	"github.com/sensu/sensu-go/backend/authentication/jwt"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
@@ -36,7 +37,7 @@ func (a Authentication) Then(next http.Handler) http.Handler {
 				if err != nil {
 					logger.WithError(err).Warn("invalid token")
 					actionErr := actions.NewErrorf(actions.Unauthenticated, "invalid credentials")
-					SimpleLogger{}.Then(errorWriter{err: actionErr}.Then(next)).ServeHTTP(w, r.WithContext(ctx))
+					AccessLog{}.Then(errorWriter{err: actionErr}.Then(next)).ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
 				// Set the claims into the request context
@@ -48,16 +49,18 @@ func (a Authentication) Then(next http.Handler) http.Handler {
 			// if the auth header contains Key, continue with api key auth
 			if strings.HasPrefix(headerString, "Key ") {
 				headerString = strings.TrimPrefix(headerString, "Key ")
-				claims, err := extractAPIKeyClaims(ctx, headerString, a.Store)
+				claims, keyName, err := extractAPIKeyClaims(ctx, headerString, a.Store)
 				if err != nil {
 					logger.WithError(err).Warn("invalid api key")
 					actionErr := actions.NewErrorf(actions.Unauthenticated, "invalid credentials")
-					SimpleLogger{}.Then(errorWriter{err: actionErr}.Then(next)).ServeHTTP(w, r.WithContext(ctx))
+					AccessLog{}.Then(errorWriter{err: actionErr}.Then(next)).ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
 				if claims != nil {
-					// Set the claims into the request context
+					// Set the claims and the API key name into the request
+					// context
 					ctx = jwt.SetClaimsIntoContext(r, claims)
+					ctx = request.ContextWithAPIKey(ctx, keyName)
 					next.ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
@@ -71,16 +74,16 @@ func (a Authentication) Then(next http.Handler) http.Handler {
 		}
 
 		actionErr := actions.NewErrorf(actions.Unauthenticated, "bad credentials")
-		SimpleLogger{}.Then(errorWriter{err: actionErr}.Then(next)).ServeHTTP(w, r.WithContext(ctx))
+		AccessLog{}.Then(errorWriter{err: actionErr}.Then(next)).ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func extractAPIKeyClaims(ctx context.Context, key string, store storev2.Interface) (*corev2.Claims, error) {
+func extractAPIKeyClaims(ctx context.Context, key string, store storev2.Interface) (*corev2.Claims, string, error) {
 	var claims *corev2.Claims
 	keyStore := storev2.Of[*corev2.APIKey](store)
 	apiKeys, err := keyStore.List(ctx, storev2.ID{}, nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	for _, apiKey := range apiKeys {
@@ -88,7 +91,7 @@ func extractAPIKeyClaims(ctx context.Context, key string, store storev2.Interfac
 			userStore := storev2.Of[*corev2.User](store)
 			user, err := userStore.Get(ctx, storev2.ID{Name: apiKey.Username})
 			if err != nil {
-				return nil, err
+				return nil, "", err
 			}
 
 			// inject the username and groups into standard jwt claims
@@ -98,11 +101,11 @@ func extractAPIKeyClaims(ctx context.Context, key string, store storev2.Interfac
 				APIKey:         true,
 			}
 
-			return claims, nil
+			return claims, apiKey.Name, nil
 		}
 	}
 
-	return nil, errors.New("API key rejected")
+	return nil, "", errors.New("API key rejected")
 }
 
 type errorWriter struct {
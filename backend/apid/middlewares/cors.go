@@ -0,0 +1,122 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/request"
+)
+
+var (
+	// DefaultCORSAllowedMethods are the methods allowed in cross-origin
+	// requests by default.
+	DefaultCORSAllowedMethods = []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+	}
+
+	// DefaultCORSAllowedHeaders are the headers allowed in cross-origin
+	// requests by default.
+	DefaultCORSAllowedHeaders = []string{
+		"Authorization",
+		"Content-Type",
+		"If-Match",
+		"If-None-Match",
+		request.RequestIDHeader,
+	}
+
+	// corsExposedHeaders are the response headers that browser-based clients
+	// need to read.
+	corsExposedHeaders = []string{
+		corev2.PaginationContinueHeader,
+		"Etag",
+		request.RequestIDHeader,
+	}
+)
+
+// CORS is an HTTP middleware that enforces a Cross-Origin Resource Sharing
+// policy, allowing browser-based clients served from other origins to call the
+// API. Cross-origin requests are not allowed when AllowedOrigins is empty.
+type CORS struct {
+	// AllowedOrigins are the origins allowed to make cross-origin requests,
+	// "*" allows any origin.
+	AllowedOrigins []string
+
+	// AllowedMethods are the methods allowed in cross-origin requests.
+	// DefaultCORSAllowedMethods are allowed when it is empty.
+	AllowedMethods []string
+
+	// AllowedHeaders are the headers allowed in cross-origin requests.
+	// DefaultCORSAllowedHeaders are allowed when it is empty.
+	AllowedHeaders []string
+
+	// AllowCredentials indicates whether cross-origin requests can include
+	// credentials, like cookies.
+	AllowCredentials bool
+}
+
+// Then middleware
+func (c CORS) Then(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !c.originAllowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		if c.AllowCredentials || !c.anyOriginAllowed() {
+			header.Set("Access-Control-Allow-Origin", origin)
+		} else {
+			header.Set("Access-Control-Allow-Origin", "*")
+		}
+		if c.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		// Answer preflight requests directly, they would not match any route
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			methods := c.AllowedMethods
+			if len(methods) == 0 {
+				methods = DefaultCORSAllowedMethods
+			}
+			headers := c.AllowedHeaders
+			if len(headers) == 0 {
+				headers = DefaultCORSAllowedHeaders
+			}
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		header.Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c CORS) originAllowed(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c CORS) anyOriginAllowed() bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
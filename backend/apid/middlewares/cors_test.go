@@ -0,0 +1,124 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name           string
+		cors           CORS
+		method         string
+		origin         string
+		preflight      bool
+		wantStatus     int
+		wantOrigin     string
+		wantMethods    string
+		wantHeaders    string
+		wantCredential string
+	}{
+		{
+			name:       "disabled",
+			cors:       CORS{},
+			method:     http.MethodGet,
+			origin:     "https://dashboard.example.com",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "same origin request",
+			cors:       CORS{AllowedOrigins: []string{"*"}},
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "origin not allowed",
+			cors:       CORS{AllowedOrigins: []string{"https://other.example.com"}},
+			method:     http.MethodGet,
+			origin:     "https://dashboard.example.com",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "origin allowed",
+			cors:       CORS{AllowedOrigins: []string{"https://dashboard.example.com"}},
+			method:     http.MethodGet,
+			origin:     "https://dashboard.example.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "https://dashboard.example.com",
+		},
+		{
+			name:       "any origin allowed",
+			cors:       CORS{AllowedOrigins: []string{"*"}},
+			method:     http.MethodGet,
+			origin:     "https://dashboard.example.com",
+			wantStatus: http.StatusOK,
+			wantOrigin: "*",
+		},
+		{
+			name:           "any origin allowed with credentials",
+			cors:           CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			method:         http.MethodGet,
+			origin:         "https://dashboard.example.com",
+			wantStatus:     http.StatusOK,
+			wantOrigin:     "https://dashboard.example.com",
+			wantCredential: "true",
+		},
+		{
+			name:        "preflight with defaults",
+			cors:        CORS{AllowedOrigins: []string{"https://dashboard.example.com"}},
+			method:      http.MethodOptions,
+			origin:      "https://dashboard.example.com",
+			preflight:   true,
+			wantStatus:  http.StatusNoContent,
+			wantOrigin:  "https://dashboard.example.com",
+			wantMethods: "GET, HEAD, POST, PUT, PATCH, DELETE",
+			wantHeaders: "Authorization, Content-Type, If-Match, If-None-Match, X-Request-ID",
+		},
+		{
+			name: "preflight with custom methods and headers",
+			cors: CORS{
+				AllowedOrigins: []string{"https://dashboard.example.com"},
+				AllowedMethods: []string{"GET"},
+				AllowedHeaders: []string{"Authorization"},
+			},
+			method:      http.MethodOptions,
+			origin:      "https://dashboard.example.com",
+			preflight:   true,
+			wantStatus:  http.StatusNoContent,
+			wantOrigin:  "https://dashboard.example.com",
+			wantMethods: "GET",
+			wantHeaders: "Authorization",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.cors.Then(testHandler()))
+			defer server.Close()
+
+			req, err := http.NewRequest(tt.method, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			assert.Equal(t, tt.wantOrigin, res.Header.Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, tt.wantMethods, res.Header.Get("Access-Control-Allow-Methods"))
+			assert.Equal(t, tt.wantHeaders, res.Header.Get("Access-Control-Allow-Headers"))
+			assert.Equal(t, tt.wantCredential, res.Header.Get("Access-Control-Allow-Credentials"))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/backend/metrics"
+)
+
+const (
+	// DeadlineExceededCounterName is the name of the prometheus counter of
+	// the API requests whose deadline was exceeded.
+	DeadlineExceededCounterName = "sensu_go_api_deadline_exceeded_total"
+
+	// DefaultReadDeadline is the default duration of the requests that get a
+	// single resource.
+	DefaultReadDeadline = 10 * time.Second
+
+	// DefaultListDeadline is the default duration of the requests that list
+	// resources.
+	DefaultListDeadline = 14 * time.Second
+
+	// DefaultWriteDeadline is the default duration of the requests that
+	// create, update or delete resources.
+	DefaultWriteDeadline = 10 * time.Second
+)
+
+// The classes of operations of the API requests, by which their deadlines are
+// configured.
+const (
+	OperationRead  = "read"
+	OperationList  = "list"
+	OperationWrite = "write"
+)
+
+var deadlineExceededCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: DeadlineExceededCounterName,
+		Help: "The total number of API requests whose deadline was exceeded, per class of operation",
+	},
+	[]string{"operation"},
+)
+
+func init() {
+	if err := prometheus.Register(deadlineExceededCounter); err != nil {
+		metrics.LogError(logger, DeadlineExceededCounterName, err)
+	}
+}
+
+// Deadline is an HTTP middleware that sets the deadline of the context of the
+// requests according to the class of their operation, so that the store
+// operations of the request are abandoned once the client can no longer get
+// their result. A zero duration leaves the requests of its class without
+// deadline.
+type Deadline struct {
+	Read  time.Duration
+	List  time.Duration
+	Write time.Duration
+}
+
+// Then middleware
+func (d Deadline) Then(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation := operationOf(r)
+		timeout := d.timeout(operation)
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+		if ctx.Err() == context.DeadlineExceeded {
+			deadlineExceededCounter.WithLabelValues(operation).Inc()
+			logger.WithField("path", r.URL.Path).WithField("operation", operation).Warn("api request deadline exceeded")
+		}
+	})
+}
+
+func (d Deadline) timeout(operation string) time.Duration {
+	switch operation {
+	case OperationRead:
+		return d.Read
+	case OperationList:
+		return d.List
+	default:
+		return d.Write
+	}
+}
+
+// operationOf returns the class of the operation of r. A GET request that
+// doesn't name a resource lists resources.
+func operationOf(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		vars := mux.Vars(r)
+		if vars["id"] == "" && vars["check"] == "" {
+			return OperationList
+		}
+		return OperationRead
+	default:
+		return OperationWrite
+	}
+}
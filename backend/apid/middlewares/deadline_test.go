@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadline(t *testing.T) {
+	deadline := Deadline{
+		Read:  time.Minute,
+		List:  2 * time.Minute,
+		Write: 0,
+	}
+	var got time.Duration
+	var hasDeadline bool
+	router := mux.NewRouter()
+	router.Use(deadline.Then)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var d time.Time
+		d, hasDeadline = r.Context().Deadline()
+		got = time.Until(d)
+	}
+	router.HandleFunc("/checks", handler)
+	router.HandleFunc("/checks/{id}", handler)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		wantDeadline time.Duration
+	}{
+		{"get", http.MethodGet, "/checks/foo", time.Minute},
+		{"list", http.MethodGet, "/checks", 2 * time.Minute},
+		{"write without deadline", http.MethodPut, "/checks/foo", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+			if tt.wantDeadline == 0 {
+				assert.False(t, hasDeadline)
+				return
+			}
+			assert.True(t, hasDeadline)
+			assert.InDelta(t, tt.wantDeadline, got, float64(time.Second))
+		})
+	}
+}
+
+func TestDeadlineExceeded(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	handler := Deadline{Write: time.Millisecond}.Then(next)
+	before := testutil.ToFloat64(deadlineExceededCounter.WithLabelValues(OperationWrite))
+	req := httptest.NewRequest(http.MethodPost, "/checks", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	after := testutil.ToFloat64(deadlineExceededCounter.WithLabelValues(OperationWrite))
+	assert.Equal(t, before+1, after)
+}
@@ -0,0 +1,109 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/backend/authorization"
+	"github.com/sensu/sensu-go/backend/metrics"
+	"github.com/sensu/sensu-go/util/deprecation"
+)
+
+// DeprecatedRequestsCounterName is the name of the prometheus counter of the
+// API requests that use deprecated endpoints and fields.
+const DeprecatedRequestsCounterName = "sensu_go_api_deprecated_requests_total"
+
+var deprecatedRequestsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: DeprecatedRequestsCounterName,
+		Help: "The total number of API requests that use deprecated endpoints and fields, per deprecation",
+	},
+	[]string{"deprecation"},
+)
+
+func init() {
+	if err := prometheus.Register(deprecatedRequestsCounter); err != nil {
+		metrics.LogError(logger, DeprecatedRequestsCounterName, err)
+	}
+}
+
+// Deprecation is an HTTP middleware that warns the clients that use the
+// deprecated endpoints and fields of the deprecation registry, with a Warning
+// header in the response. The request is handled as usual. It must follow the
+// AuthorizationAttributes and LimitRequest middlewares, since it reads the
+// bodies of the requests that may set deprecated fields.
+type Deprecation struct{}
+
+// Then middleware
+func (Deprecation) Then(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var deprecations []deprecation.Deprecation
+		if d, ok := deprecation.Endpoint(r.Method, r.URL.Path); ok {
+			deprecations = append(deprecations, d)
+		}
+		deprecations = append(deprecations, deprecatedFields(r)...)
+		for _, d := range deprecations {
+			deprecatedRequestsCounter.WithLabelValues(d.Name).Inc()
+			w.Header().Add(deprecation.WarningHeader, deprecation.FormatWarning(d.Warning()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// deprecatedFields returns the deprecated fields set by the resource in the
+// body of r, bare or wrapped.
+func deprecatedFields(r *http.Request) []deprecation.Deprecation {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil
+	}
+	attrs := authorization.GetAttributes(r.Context())
+	if attrs == nil || r.Body == nil {
+		return nil
+	}
+	fields := deprecation.Fields(attrs.Resource)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		// The handler reports the error, such as a body that exceeds the
+		// request limit, when it reads the body again
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err}))
+		return nil
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	var resource map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resource); err != nil {
+		return nil
+	}
+	if spec, ok := resource["spec"]; ok {
+		var wrapped map[string]json.RawMessage
+		if err := json.Unmarshal(spec, &wrapped); err == nil {
+			resource = wrapped
+		}
+	}
+
+	var deprecations []deprecation.Deprecation
+	for _, d := range fields {
+		if value, ok := resource[d.Field()]; ok && !bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			deprecations = append(deprecations, d)
+		}
+	}
+	return deprecations
+}
+
+// errReader returns its error once the body it follows is read.
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
@@ -0,0 +1,107 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sensu/sensu-go/backend/apid/request"
+	"github.com/sensu/sensu-go/backend/authorization"
+	"github.com/sensu/sensu-go/util/deprecation"
+)
+
+func TestDeprecation(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		resource string
+		body     string
+		warnings []string
+	}{
+		{
+			name:     "deprecated endpoint",
+			method:   http.MethodGet,
+			path:     "/api/core/v2/silenced/checks/check-cpu",
+			resource: "silenced",
+			warnings: []string{"endpoint GET /api/core/v2/silenced/checks/{check} is deprecated"},
+		},
+		{
+			name:     "deprecated field",
+			method:   http.MethodPut,
+			path:     "/api/core/v2/namespaces/default/checks/check-cpu",
+			resource: "checks",
+			body:     `{"command": "true", "subdue": {"days": {}}}`,
+			warnings: []string{"field checks.subdue is deprecated"},
+		},
+		{
+			name:     "deprecated field of a wrapped resource",
+			method:   http.MethodPost,
+			path:     "/api/core/v2/namespaces/default/checks",
+			resource: "checks",
+			body:     `{"type": "CheckConfig", "spec": {"command": "true", "subdue": {"days": {}}}}`,
+			warnings: []string{"field checks.subdue is deprecated"},
+		},
+		{
+			name:     "null deprecated field",
+			method:   http.MethodPut,
+			path:     "/api/core/v2/namespaces/default/checks/check-cpu",
+			resource: "checks",
+			body:     `{"command": "true", "subdue": null}`,
+		},
+		{
+			name:     "field of another resource",
+			method:   http.MethodPut,
+			path:     "/api/core/v2/namespaces/default/handlers/slack",
+			resource: "handlers",
+			body:     `{"subdue": {}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				body = string(b)
+			})
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			ctx := authorization.SetAttributes(req.Context(), &authorization.Attributes{Resource: tt.resource})
+			w := httptest.NewRecorder()
+			Deprecation{}.Then(next).ServeHTTP(w, req.WithContext(ctx))
+
+			// The handler reads the whole body
+			assert.Equal(t, tt.body, body)
+
+			headers := w.Header().Values(deprecation.WarningHeader)
+			require.Len(t, headers, len(tt.warnings))
+			for i, header := range headers {
+				warning, ok := deprecation.ParseWarning(header)
+				assert.True(t, ok)
+				assert.Contains(t, warning, tt.warnings[i])
+			}
+		})
+	}
+}
+
+func TestDeprecationBodyLimit(t *testing.T) {
+	var err error
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err = io.ReadAll(r.Body)
+	})
+	req := httptest.NewRequest(http.MethodPut, "/api/core/v2/namespaces/default/checks/check-cpu", strings.NewReader(`{"command": "true"}`))
+	req.Header.Set("Content-Type", "application/json")
+	// A body of unknown length is read until it exceeds the limit
+	req.ContentLength = -1
+	ctx := authorization.SetAttributes(req.Context(), &authorization.Attributes{Resource: "checks"})
+	w := httptest.NewRecorder()
+	LimitRequest{Limit: 4}.Then(Deprecation{}.Then(next)).ServeHTTP(w, req.WithContext(ctx))
+
+	// The handler gets the error of the request limit
+	assert.ErrorIs(t, err, request.ErrBodyTooLarge)
+}
@@ -32,6 +32,10 @@ func writeErr(w http.ResponseWriter, err error) {
 		st = http.StatusForbidden
 	case actions.Unauthenticated:
 		st = http.StatusUnauthorized
+	case actions.Unavailable:
+		st = http.StatusServiceUnavailable
+	case actions.PayloadTooLarge:
+		st = http.StatusRequestEntityTooLarge
 	}
 
 	errJSON, err := json.Marshal(errRes)
@@ -1,30 +1,112 @@
 package middlewares
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"path"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/request"
 )
 
 // MaxBytesLimit is the default max http request size, in bytes (see https://docs.sensu.io/sensu-go/latest/api/#request-size-limit)
 const (
 	MaxBytesLimit = 512000
+
+	// DefaultEventsBytesLimit is the default max size of the requests that
+	// create or update events, in bytes.
+	DefaultEventsBytesLimit = 1024000
+
+	// DefaultBulkBytesLimit is the default max size of the requests that
+	// create or update resources in bulk, in bytes.
+	DefaultBulkBytesLimit = 5120000
 )
 
-// LimitRequest is an HTTP middleware that enforces request limits
+// The classes of endpoints of the API requests, by which their max sizes are
+// configured.
+const (
+	RequestClassDefault = "default"
+	RequestClassEvents  = "events"
+	RequestClassBulk    = "bulk"
+)
+
+// LimitRequest is an HTTP middleware that enforces request limits, according
+// to the class of the endpoint of the requests. A zero Events or Bulk limit
+// falls back to Limit.
 type LimitRequest struct {
-	Limit int64
+	Limit  int64
+	Events int64
+	Bulk   int64
 }
 
 // Then middleware
 func (l LimitRequest) Then(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		r.Body = http.MaxBytesReader(w, r.Body, l.Limit)
+		class := requestClassOf(r)
+		limit := l.limit(class)
+
+		// Reject the requests that announce their oversized body before
+		// reading any of it
+		if r.ContentLength > limit {
+			http.Error(w, fmt.Sprintf("Request exceeded max length of the %s requests", class), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		r.Body = &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, limit), class: class, limit: limit}
 		r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 		err := r.ParseForm()
 		if err != nil && err != io.EOF {
+			if errors.Is(err, request.ErrBodyTooLarge) {
+				http.Error(w, fmt.Sprintf("Request exceeded max length of the %s requests", class), http.StatusRequestEntityTooLarge)
+				return
+			}
 			http.Error(w, "Request exceeded max length", http.StatusInternalServerError)
 			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+func (l LimitRequest) limit(class string) int64 {
+	switch {
+	case class == RequestClassEvents && l.Events > 0:
+		return l.Events
+	case class == RequestClassBulk && l.Bulk > 0:
+		return l.Bulk
+	default:
+		return l.Limit
+	}
+}
+
+// requestClassOf returns the class of the endpoint of r.
+func requestClassOf(r *http.Request) string {
+	if r.Method == http.MethodPost && path.Base(r.URL.Path) == "bulk" {
+		return RequestClassBulk
+	}
+	switch mux.Vars(r)["resource"] {
+	case "events", "replays":
+		return RequestClassEvents
+	}
+	return RequestClassDefault
+}
+
+// limitedBody is a request body that fails with an error wrapping
+// request.ErrBodyTooLarge once it is read beyond its limit, so that the
+// handlers that decode it can tell this error from a malformed body.
+type limitedBody struct {
+	io.ReadCloser
+	class string
+	limit int64
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if err != nil && err != io.EOF && b.read >= b.limit {
+		err = fmt.Errorf("%w: the limit of the %s requests is %d bytes", request.ErrBodyTooLarge, b.class, b.limit)
+	}
+	return n, err
+}
@@ -3,13 +3,18 @@ package middlewares
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gorilla/mux"
 	v2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/request"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMiddlewareLimits(t *testing.T) {
@@ -56,7 +61,7 @@ func TestMiddlewareLimits(t *testing.T) {
 			description:	"Request over threshold",
 			url:		"/checks",
 			body:		badCheck,
-			expectedCode:	http.StatusInternalServerError,
+			expectedCode:	http.StatusRequestEntityTooLarge,
 			limit:		MaxBytesLimit,
 		}, {
 			description:	"Configurable limit within threshold",
@@ -68,7 +73,7 @@ func TestMiddlewareLimits(t *testing.T) {
 			description:	"Configurable limit over threshold",
 			url:		"/checks",
 			body:		goodCheck,
-			expectedCode:	http.StatusInternalServerError,
+			expectedCode:	http.StatusRequestEntityTooLarge,
 		},
 	}
 
@@ -83,3 +88,86 @@ func TestMiddlewareLimits(t *testing.T) {
 		assert.Equal(tc.expectedCode, res.StatusCode, tc.description)
 	}
 }
+
+func TestLimitRequestClasses(t *testing.T) {
+	mware := LimitRequest{Limit: 100, Events: 200, Bulk: 300}
+	router := mux.NewRouter()
+	router.Handle("/namespaces/{namespace}/{resource:events}", mware.Then(testHandler()))
+	router.Handle("/namespaces/{namespace}/{resource:entities}", mware.Then(testHandler()))
+	router.Handle("/namespaces/{namespace}/{resource:entities}/bulk", mware.Then(testHandler()))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	tests := []struct {
+		description  string
+		url          string
+		size         int
+		expectedCode int
+	}{
+		{
+			description:  "default request within its limit",
+			url:          "/namespaces/default/entities",
+			size:         100,
+			expectedCode: http.StatusOK,
+		}, {
+			description:  "default request over its limit",
+			url:          "/namespaces/default/entities",
+			size:         101,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		}, {
+			description:  "events request within its limit",
+			url:          "/namespaces/default/events",
+			size:         200,
+			expectedCode: http.StatusOK,
+		}, {
+			description:  "events request over its limit",
+			url:          "/namespaces/default/events",
+			size:         201,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		}, {
+			description:  "bulk request within its limit",
+			url:          "/namespaces/default/entities/bulk",
+			size:         300,
+			expectedCode: http.StatusOK,
+		}, {
+			description:  "bulk request over its limit",
+			url:          "/namespaces/default/entities/bulk",
+			size:         301,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			body := strings.NewReader(strings.Repeat("a", tc.size))
+			req, err := http.NewRequest(http.MethodPost, server.URL+tc.url, body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			assert.Equal(t, tc.expectedCode, res.StatusCode)
+		})
+	}
+}
+
+func TestLimitRequestUnknownLength(t *testing.T) {
+	mware := LimitRequest{Limit: 100}
+	readErr := make(chan error, 1)
+	server := httptest.NewServer(mware.Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		readErr <- err
+	})))
+	defer server.Close()
+
+	// The body of unknown length is only rejected by the handler that reads
+	// it beyond the limit
+	body := io.MultiReader(strings.NewReader(strings.Repeat("a", 101)))
+	req, err := http.NewRequest(http.MethodPost, server.URL, body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.ErrorIs(t, <-readErr, request.ErrBodyTooLarge)
+}
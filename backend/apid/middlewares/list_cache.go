@@ -0,0 +1,179 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/authorization"
+)
+
+// ListCache is an HTTP middleware that adds ETag support to list responses,
+// and caches them for a short period of time so that clients polling the same
+// list, like dashboards, do not have it fetched and serialized over and over
+// again. Cached responses are keyed by user and request URI, and any write
+// request going through the middleware purges the cache.
+type ListCache struct {
+	// TTL is how long list responses are cached for. Responses are not cached
+	// when TTL is zero, but they are still given an ETag.
+	TTL time.Duration
+
+	mu      *sync.Mutex
+	entries map[string]listCacheEntry
+}
+
+type listCacheEntry struct {
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// NewListCache returns a ListCache that caches list responses for ttl.
+func NewListCache(ttl time.Duration) ListCache {
+	return ListCache{
+		TTL:     ttl,
+		mu:      &sync.Mutex{},
+		entries: make(map[string]listCacheEntry),
+	}
+}
+
+// Then middleware
+func (c ListCache) Then(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attrs := authorization.GetAttributes(r.Context())
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			if r.Method != http.MethodHead {
+				c.purge()
+			}
+			return
+		}
+		if attrs == nil || attrs.Verb != "list" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := attrs.User.Username + " " + r.URL.RequestURI()
+		if entry, ok := c.get(key); ok {
+			writeListCacheEntry(w, r, entry)
+			return
+		}
+
+		rec := &listCacheRecorder{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.status != http.StatusOK {
+			copyHeader(w.Header(), rec.header)
+			w.WriteHeader(rec.status)
+			_, _ = w.Write(rec.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(rec.body.Bytes())
+		entry := listCacheEntry{
+			header:  rec.header,
+			body:    rec.body.Bytes(),
+			etag:    hex.EncodeToString(sum[:]),
+			expires: time.Now().Add(c.TTL),
+		}
+		c.set(key, entry)
+		writeListCacheEntry(w, r, entry)
+	})
+}
+
+func (c ListCache) get(key string) (listCacheEntry, bool) {
+	if c.TTL <= 0 || c.mu == nil {
+		return listCacheEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return listCacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c ListCache) set(key string, entry listCacheEntry) {
+	if c.TTL <= 0 || c.mu == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry
+}
+
+func (c ListCache) purge() {
+	if c.mu == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		delete(c.entries, k)
+	}
+}
+
+func writeListCacheEntry(w http.ResponseWriter, r *http.Request, entry listCacheEntry) {
+	copyHeader(w.Header(), entry.header)
+	w.Header().Set("Etag", fmt.Sprintf("%q", entry.etag))
+	if etagMatches(r.Header.Get("If-None-Match"), entry.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(entry.body)
+}
+
+// etagMatches returns whether the value of an If-None-Match header matches
+// the given etag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		candidate = strings.TrimPrefix(candidate, "W/")
+		if candidate == fmt.Sprintf("%q", etag) {
+			return true
+		}
+	}
+	return false
+}
+
+func copyHeader(dst, src http.Header) {
+	for k, v := range src {
+		dst[k] = append([]string(nil), v...)
+	}
+}
+
+// listCacheRecorder records the response of a list request so it can be
+// cached.
+type listCacheRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *listCacheRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *listCacheRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *listCacheRecorder) WriteHeader(status int) {
+	r.status = status
+}
@@ -0,0 +1,125 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/authorization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listCacheRequest(method, verb, username string) *http.Request {
+	req := httptest.NewRequest(method, "/api/core/v2/namespaces/default/entities", nil)
+	attrs := &authorization.Attributes{Verb: verb, User: corev2.User{Username: username}}
+	return req.WithContext(authorization.SetAttributes(req.Context(), attrs))
+}
+
+func countingHandler(calls *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `[{"call":%d}]`, *calls)
+	})
+}
+
+func TestListCacheCachesLists(t *testing.T) {
+	var calls int
+	server := NewListCache(time.Minute).Then(countingHandler(&calls))
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, listCacheRequest(http.MethodGet, "list", "alice"))
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("Etag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `[{"call":1}]`, w.Body.String())
+
+	// The cached response is served
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, listCacheRequest(http.MethodGet, "list", "alice"))
+	assert.Equal(t, `[{"call":1}]`, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("Etag"))
+	assert.Equal(t, 1, calls)
+
+	// Other users do not share cached responses
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, listCacheRequest(http.MethodGet, "list", "bob"))
+	assert.Equal(t, `[{"call":2}]`, w.Body.String())
+
+	// Writes purge the cache
+	server.ServeHTTP(httptest.NewRecorder(), listCacheRequest(http.MethodPut, "update", "alice"))
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, listCacheRequest(http.MethodGet, "list", "alice"))
+	assert.Equal(t, `[{"call":4}]`, w.Body.String())
+}
+
+func TestListCacheIfNoneMatch(t *testing.T) {
+	var calls int
+	server := NewListCache(0).Then(countingHandler(&calls))
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, listCacheRequest(http.MethodGet, "list", "alice"))
+	etag := w.Header().Get("Etag")
+	require.NotEmpty(t, etag)
+
+	// Responses are not cached without a TTL, but unchanged responses are
+	// not sent again
+	calls = 0
+	req := listCacheRequest(http.MethodGet, "list", "alice")
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, 1, calls)
+
+	// Changed responses are sent
+	req = listCacheRequest(http.MethodGet, "list", "alice")
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("Etag"))
+}
+
+func TestListCacheIgnoresGets(t *testing.T) {
+	var calls int
+	server := NewListCache(time.Minute).Then(countingHandler(&calls))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, listCacheRequest(http.MethodGet, "get", "alice"))
+		assert.Empty(t, w.Header().Get("Etag"))
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestListCacheErrorsNotCached(t *testing.T) {
+	var calls int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	server := NewListCache(time.Minute).Then(handler)
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, listCacheRequest(http.MethodGet, "list", "alice"))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Get("Etag"))
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestEtagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"abc"`, "abc"))
+	assert.True(t, etagMatches(`"xyz", W/"abc"`, "abc"))
+	assert.True(t, etagMatches(`*`, "abc"))
+	assert.False(t, etagMatches(`"xyz"`, "abc"))
+	assert.False(t, etagMatches(``, "abc"))
+}
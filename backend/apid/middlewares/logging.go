@@ -7,15 +7,23 @@ import (
 	"net/http"
 	"time"
 
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/request"
 	"github.com/sensu/sensu-go/backend/authentication/jwt"
 	"github.com/sirupsen/logrus"
 )
 
-// SimpleLogger log request path and duration
-type SimpleLogger struct{}
+// AccessLog is an HTTP middleware that emits one structured log entry per
+// request, with its method, path, user, namespace, status, latency and ID.
+type AccessLog struct{}
+
+// SimpleLogger is the former name of AccessLog.
+//
+// Deprecated: use AccessLog.
+type SimpleLogger = AccessLog
 
 // Then middleware
-func (m SimpleLogger) Then(next http.Handler) http.Handler {
+func (m AccessLog) Then(next http.Handler) http.Handler {
 	if logger.Logger.Level < logrus.InfoLevel {
 		return next
 	}
@@ -25,20 +33,27 @@ func (m SimpleLogger) Then(next http.Handler) http.Handler {
 		writerWithCapture := makeResponseWriterWithCapture(w)
 		next.ServeHTTP(writerWithCapture, r)
 
+		ctx := r.Context()
 		var user string
-		claims := jwt.GetClaimsFromContext(r.Context())
+		claims := jwt.GetClaimsFromContext(ctx)
 		if claims != nil {
 			user = claims.StandardClaims.Subject
 		}
+		namespace, _ := ctx.Value(corev2.NamespaceKey).(string)
 
-		duration := float64(time.Since(start)) / float64(time.Millisecond)
+		latency := fmt.Sprintf("%.3fms", float64(time.Since(start))/float64(time.Millisecond))
 		logEntry := logger.WithFields(logrus.Fields{
-			"duration": fmt.Sprintf("%.3fms", duration),
-			"status":   writerWithCapture.Status(),
-			"size":     writerWithCapture.Size(),
-			"path":     r.URL.Path,
-			"method":   r.Method,
-			"user":     user,
+			"latency": latency,
+			// duration is deprecated in favor of latency, and will be
+			// removed in a future release.
+			"duration":   latency,
+			"status":     writerWithCapture.Status(),
+			"size":       writerWithCapture.Size(),
+			"path":       r.URL.Path,
+			"method":     r.Method,
+			"user":       user,
+			"namespace":  namespace,
+			"request_id": request.RequestIDFromContext(ctx),
 		})
 		logEntry.Info("request completed")
 	})
@@ -0,0 +1,137 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/authorization"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// DefaultReadOnlyTTL is how long the read-only mode of the cluster is cached
+// by the ReadOnly middleware.
+const DefaultReadOnlyTTL = 5 * time.Second
+
+// ReadOnly is an HTTP middleware that rejects the requests that change
+// resources while the API is in read-only mode, such as during store
+// migrations and backups. The event submissions of the agents and the
+// ingestion clients are still allowed, as well as the requests that change
+// the read-only mode itself, but the events can't be deleted, replaced or
+// resolved. It must follow the AuthorizationAttributes middleware. A nil
+// ReadOnly allows every request.
+//
+// The API is also in a degraded read-only mode while the backend starts its
+// other daemons, in which the event submissions are rejected too, since
+// they can't be processed yet.
+type ReadOnly struct {
+	store storev2.Interface
+	ttl   time.Duration
+
+	mu       sync.Mutex
+	mode     actions.ReadOnlyMode
+	expires  time.Time
+	degraded string
+}
+
+// NewReadOnly returns a ReadOnly middleware that reads the read-only mode
+// from the cluster config of store.
+func NewReadOnly(store storev2.Interface) *ReadOnly {
+	return &ReadOnly{store: store, ttl: DefaultReadOnlyTTL}
+}
+
+// Then middleware
+func (m *ReadOnly) Then(next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if reason := m.degradedReason(); reason != "" {
+			writeErr(w, actions.NewErrorf(actions.Unavailable, "the API is in degraded read-only mode: %s", reason))
+			return
+		}
+		if attrs := authorization.GetAttributes(r.Context()); attrs != nil {
+			if attrs.Resource == "read-only" || (attrs.Resource == "events" && isEventSubmission(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		if err := m.Allow(r.Context()); err != nil {
+			writeErr(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isEventSubmission returns true if r submits an event, with a POST request
+// to the events of a namespace or to an event, as opposed to the other changes
+// of the events.
+func isEventSubmission(r *http.Request) bool {
+	return r.Method == http.MethodPost && !strings.HasSuffix(r.URL.Path, "/resolve")
+}
+
+// Allow returns an Unavailable error if the API is in read-only mode, or in
+// degraded read-only mode, and nil if the resources can be changed. It is
+// used by the GraphQL API, whose queries and mutations can't be told apart
+// by their HTTP method.
+func (m *ReadOnly) Allow(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	if reason := m.degradedReason(); reason != "" {
+		return actions.NewErrorf(actions.Unavailable, "the API is in degraded read-only mode: %s", reason)
+	}
+	mode := m.get(ctx)
+	if !mode.Enabled {
+		return nil
+	}
+	if mode.Reason != "" {
+		return actions.NewErrorf(actions.Unavailable, "the API is in read-only mode: %s", mode.Reason)
+	}
+	return actions.NewErrorf(actions.Unavailable, "the API is in read-only mode")
+}
+
+// SetDegraded puts the API in degraded read-only mode for reason, or takes it
+// out of it if reason is empty.
+func (m *ReadOnly) SetDegraded(reason string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.degraded = reason
+}
+
+func (m *ReadOnly) degradedReason() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.degraded
+}
+
+// get returns the read-only mode of the cluster. The API stays in its last
+// known mode if the cluster config can't be read.
+func (m *ReadOnly) get(ctx context.Context) actions.ReadOnlyMode {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if time.Now().Before(m.expires) {
+		return m.mode
+	}
+	config, err := actions.GetClusterConfig(ctx, m.store)
+	if err != nil {
+		logger.WithError(err).Error("error reading cluster config, keeping the read-only mode")
+	} else {
+		m.mode = actions.ReadOnlyModeFromClusterConfig(config)
+	}
+	m.expires = time.Now().Add(m.ttl)
+	return m.mode
+}
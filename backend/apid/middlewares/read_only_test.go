@@ -0,0 +1,111 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/authorization"
+	"github.com/sensu/sensu-go/testing/mockstore"
+)
+
+func TestReadOnly(t *testing.T) {
+	config := &corev3.ClusterConfig{Metadata: &corev2.ObjectMeta{
+		Name: "cluster",
+		Annotations: map[string]string{
+			actions.ReadOnlyAnnotation:       "true",
+			actions.ReadOnlyReasonAnnotation: "backup",
+		},
+	}}
+	stor := &mockstore.V2MockStore{}
+	cstore := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cstore)
+	cstore.On("List", mock.Anything, mock.Anything, mock.Anything).
+		Return(mockstore.WrapList[*corev3.ClusterConfig]{config}, nil)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := NewReadOnly(stor).Then(next)
+
+	tests := []struct {
+		method   string
+		path     string
+		resource string
+		status   int
+	}{
+		{method: http.MethodGet, resource: "checks", status: http.StatusOK},
+		{method: http.MethodPost, resource: "checks", status: http.StatusServiceUnavailable},
+		{method: http.MethodPut, resource: "checks", status: http.StatusServiceUnavailable},
+		{method: http.MethodDelete, resource: "entities", status: http.StatusServiceUnavailable},
+		{method: http.MethodPost, path: "/api/core/v2/namespaces/default/events", resource: "events", status: http.StatusOK},
+		{method: http.MethodPost, path: "/api/core/v2/namespaces/default/events/foo/check-cpu", resource: "events", status: http.StatusOK},
+		{method: http.MethodPut, path: "/api/core/v2/namespaces/default/events/foo/check-cpu", resource: "events", status: http.StatusServiceUnavailable},
+		{method: http.MethodDelete, path: "/api/core/v2/namespaces/default/events/foo/check-cpu", resource: "events", status: http.StatusServiceUnavailable},
+		{method: http.MethodPost, path: "/api/core/v2/namespaces/default/events/foo/check-cpu/resolve", resource: "events", status: http.StatusServiceUnavailable},
+		{method: http.MethodPatch, path: "/api/core/v2/namespaces/default/events", resource: "events", status: http.StatusServiceUnavailable},
+		{method: http.MethodPut, resource: "read-only", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.resource+tt.path, func(t *testing.T) {
+			path := tt.path
+			if path == "" {
+				path = "/"
+			}
+			req, _ := http.NewRequest(tt.method, path, nil)
+			ctx := authorization.SetAttributes(req.Context(), &authorization.Attributes{Resource: tt.resource})
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req.WithContext(ctx))
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusServiceUnavailable {
+				assert.Contains(t, w.Body.String(), "read-only mode: backup")
+			}
+		})
+	}
+
+	// the read-only mode is cached
+	cstore.AssertNumberOfCalls(t, "List", 1)
+}
+
+func TestReadOnlyNil(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	var m *ReadOnly
+	req, _ := http.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	m.Then(next).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, m.Allow(req.Context()))
+}
+
+func TestReadOnlyDegraded(t *testing.T) {
+	// The cluster config is not read in degraded mode, since the store may
+	// be unavailable
+	stor := &mockstore.V2MockStore{}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	m := NewReadOnly(stor)
+	m.SetDegraded("the backend is starting eventd")
+	handler := m.Then(next)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		req, _ := http.NewRequest(method, "/", nil)
+		ctx := authorization.SetAttributes(req.Context(), &authorization.Attributes{Resource: "events"})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req.WithContext(ctx))
+		if method == http.MethodGet {
+			assert.Equal(t, http.StatusOK, w.Code)
+			continue
+		}
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "degraded read-only mode: the backend is starting eventd")
+	}
+
+	// The GraphQL mutations are rejected too
+	err := m.Allow(context.Background())
+	code, _ := actions.StatusFromError(err)
+	assert.Equal(t, actions.Unavailable, code)
+}
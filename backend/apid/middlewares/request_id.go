@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/sensu/sensu-go/backend/apid/request"
+)
+
+// maxRequestIDLength is the maximum length of a request ID provided by a
+// client.
+const maxRequestIDLength = 128
+
+// RequestID is an HTTP middleware that identifies every request, so that its
+// logs and errors can be correlated from end to end. The ID provided by the
+// client in the X-Request-ID header is used when it is valid, otherwise a new
+// one is generated. The ID is stored in the request context and returned in
+// the X-Request-ID header of the response.
+type RequestID struct{}
+
+// Then middleware
+func (RequestID) Then(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(request.RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(request.RequestIDHeader, id)
+		ctx := request.ContextWithRequestID(r.Context(), id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID returns whether the request ID provided by a client is safe
+// to use in logs and response headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		// only accept visible ASCII characters
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
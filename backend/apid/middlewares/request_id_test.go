@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sensu/sensu-go/backend/apid/request"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{
+			name:     "client request ID",
+			header:   "2b0f3c3c-7a4b-4a8e-b1f1-1b0c4e3f0b7a",
+			wantSame: true,
+		},
+		{
+			name:     "missing request ID",
+			header:   "",
+			wantSame: false,
+		},
+		{
+			name:     "request ID with spaces",
+			header:   "foo bar",
+			wantSame: false,
+		},
+		{
+			name:     "request ID too long",
+			header:   strings.Repeat("a", maxRequestIDLength+1),
+			wantSame: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctxID string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = request.RequestIDFromContext(r.Context())
+			})
+
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(request.RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			RequestID{}.Then(next).ServeHTTP(w, req)
+
+			id := w.Header().Get(request.RequestIDHeader)
+			assert.NotEmpty(t, id)
+			assert.Equal(t, id, ctxID)
+			if tt.wantSame {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.NotEqual(t, tt.header, id)
+			}
+		})
+	}
+}
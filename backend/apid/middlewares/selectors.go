@@ -12,7 +12,9 @@ type Selectors struct{}
 
 func (s Selectors) Then(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
+		// Selectors select the resources that are listed, and the events
+		// that are deleted, resolved or replayed in bulk.
+		if r.Method != http.MethodGet && r.Method != http.MethodDelete && r.Method != http.MethodPatch && r.Method != http.MethodPost {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/sensu/sensu-go/backend/apid/request"
+	"github.com/sensu/sensu-go/backend/apid/usage"
+	"github.com/sensu/sensu-go/backend/authentication/jwt"
+)
+
+// Usage is an HTTP middleware that records the requests and bytes of every
+// authenticated user and API key. It must follow the Authentication
+// middleware, unauthenticated requests are not recorded.
+type Usage struct {
+	Tracker *usage.Tracker
+}
+
+// Then middleware
+func (u Usage) Then(next http.Handler) http.Handler {
+	if u.Tracker == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		claims := jwt.GetClaimsFromContext(ctx)
+		if claims == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body *countingReader
+		if r.Body != nil {
+			body = &countingReader{ReadCloser: r.Body}
+			r.Body = body
+		}
+		writerWithCapture := makeResponseWriterWithCapture(w)
+		next.ServeHTTP(writerWithCapture, r)
+
+		var received int64
+		if body != nil {
+			received = body.n
+		}
+		principal := usage.Principal{
+			User:   claims.StandardClaims.Subject,
+			APIKey: request.APIKeyFromContext(ctx),
+		}
+		u.Tracker.Record(principal, received, int64(writerWithCapture.Size()))
+	})
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
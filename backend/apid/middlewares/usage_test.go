@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sensu/sensu-go/backend/apid/request"
+	"github.com/sensu/sensu-go/backend/apid/usage"
+	"github.com/sensu/sensu-go/backend/authentication/jwt"
+)
+
+func TestUsage(t *testing.T) {
+	tracker := usage.NewTracker()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte("Success"))
+	})
+	handler := Usage{Tracker: tracker}.Then(next)
+
+	// unauthenticated requests are not recorded
+	req, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Empty(t, tracker.List())
+
+	req, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	ctx := jwt.SetClaimsIntoContext(req, &corev2.Claims{
+		StandardClaims: corev2.StandardClaims("admin"),
+		APIKey:         true,
+	})
+	ctx = request.ContextWithAPIKey(ctx, "ci-key")
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+	want := []usage.Usage{
+		{
+			Principal:     usage.Principal{User: "admin", APIKey: "ci-key"},
+			Requests:      1,
+			BytesReceived: 2,
+			BytesSent:     7,
+		},
+	}
+	assert.Equal(t, want, tracker.List())
+}
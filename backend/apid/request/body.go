@@ -0,0 +1,29 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrBodyTooLarge is the error of reading a request body beyond the size limit
+// of its class of endpoints.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// Decode decodes the JSON request body into v. The body must hold a single
+// JSON value, and its size is bounded by the LimitRequest middleware, whose
+// limit is reached while the body is read.
+func Decode(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		if err != nil {
+			return err
+		}
+		return errors.New("unexpected data after the JSON value of the request body")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package request
+
+import (
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Body      string
+		ExpectErr bool
+	}{
+		{
+			Name: "single value",
+			Body: `{"name":"foo"}`,
+		}, {
+			Name: "trailing whitespace",
+			Body: "{\"name\":\"foo\"}\n",
+		}, {
+			Name:      "trailing data",
+			Body:      `{"name":"foo"}{"name":"bar"}`,
+			ExpectErr: true,
+		}, {
+			Name:      "invalid value",
+			Body:      `{"name":`,
+			ExpectErr: true,
+		}, {
+			Name:      "empty body",
+			ExpectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var v struct {
+				Name string `json:"name"`
+			}
+			err := Decode(newRequest([]byte(tc.Body)), &v)
+			if tc.ExpectErr {
+				assertError(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v.Name != "foo" {
+				t.Errorf("expected name foo, got %q", v.Name)
+			}
+		})
+	}
+}
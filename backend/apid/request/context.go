@@ -27,3 +27,45 @@ func SelectorFromContext(ctx context.Context) *selector.Selector {
 	}
 	return val.(*selector.Selector)
 }
+
+// RequestIDHeader is the header carrying the ID of an API request, which
+// correlates the logs and errors of the request.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDContextKey struct{}
+
+// RequestIDContextKey is the context key used for passing the request ID
+// through contexts.
+var RequestIDContextKey requestIDContextKey
+
+// ContextWithRequestID returns a new context, with the request ID stored as a
+// value.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIDContextKey, id)
+}
+
+// RequestIDFromContext extracts the request ID stored as a context value, if
+// it exists.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDContextKey).(string)
+	return id
+}
+
+type apiKeyContextKey struct{}
+
+// APIKeyContextKey is the context key used for passing the name of the API key
+// that authenticated a request through contexts.
+var APIKeyContextKey apiKeyContextKey
+
+// ContextWithAPIKey returns a new context, with the API key name stored as a
+// value.
+func ContextWithAPIKey(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, APIKeyContextKey, name)
+}
+
+// APIKeyFromContext extracts the API key name stored as a context value, if
+// it exists.
+func APIKeyFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(APIKeyContextKey).(string)
+	return name
+}
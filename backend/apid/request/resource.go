@@ -3,7 +3,6 @@ package request
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 
 	corev3 "github.com/sensu/core/v3"
@@ -15,8 +14,8 @@ import (
 func Resource[R corev3.Resource](r *http.Request) (R, error) {
 	var payload R
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
+	var body json.RawMessage
+	if err := Decode(r, &body); err != nil {
 		return payload, err
 	}
 
@@ -0,0 +1,201 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// AgentSession describes an agent session connected to a backend.
+type AgentSession struct {
+	AgentName        string    `json:"agent_name"`
+	Namespace        string    `json:"namespace"`
+	AgentAddr        string    `json:"agent_addr"`
+	AgentVersion     string    `json:"agent_version"`
+	ProtocolVersion  int       `json:"protocol_version"`
+	Subscriptions    []string  `json:"subscriptions"`
+	ContentType      string    `json:"content_type"`
+	ConnectedAt      time.Time `json:"connected_at"`
+	MessagesReceived int64     `json:"messages_received"`
+	MessagesSent     int64     `json:"messages_sent"`
+	BytesReceived    int64     `json:"bytes_received"`
+	BytesSent        int64     `json:"bytes_sent"`
+	RTT              float64   `json:"rtt_seconds,omitempty"`
+	MissedPongs      int64     `json:"missed_pongs"`
+}
+
+// AgentDeadLetter describes an agent event that could not be published by
+// the backend, and waits in its dead letter queue to be published again.
+type AgentDeadLetter struct {
+	Namespace string        `json:"namespace"`
+	AgentName string        `json:"agent_name"`
+	Topic     string        `json:"topic"`
+	Error     string        `json:"error"`
+	Attempts  int           `json:"attempts"`
+	FailedAt  time.Time     `json:"failed_at"`
+	Event     *corev2.Event `json:"event"`
+}
+
+// AgentDeadLettersRetry is the result of publishing the events of the dead
+// letter queue again.
+type AgentDeadLettersRetry struct {
+	Republished int `json:"republished"`
+	Remaining   int `json:"remaining"`
+}
+
+// AgentSessionsController represents the controller needs of the
+// AgentSessionsRouter
+type AgentSessionsController interface {
+	Sessions(ctx context.Context, namespace string) ([]AgentSession, error)
+	Disconnect(ctx context.Context, namespace, name string) error
+}
+
+// AgentSessionsDrainer is implemented by the AgentSessionsControllers that
+// can drain the agent sessions of their backend.
+type AgentSessionsDrainer interface {
+	Drain() error
+}
+
+// AgentDeadLetters is implemented by the AgentSessionsControllers that queue
+// the agent events their backend could not publish.
+type AgentDeadLetters interface {
+	DeadLetters() []AgentDeadLetter
+	RetryDeadLetters() AgentDeadLettersRetry
+	DiscardDeadLetters() int
+}
+
+// AgentConfigAudit is implemented by the AgentSessionsControllers that
+// record the entity config updates the backends send to the agents.
+type AgentConfigAudit interface {
+	ConfigPushes(ctx context.Context, namespace, agent string) ([]*store.AgentConfigPush, error)
+}
+
+// AgentSessionsRouter handles requests for /agents. It lists and disconnects
+// the agent sessions connected to the backend that serves the request.
+type AgentSessionsRouter struct {
+	controller AgentSessionsController
+}
+
+// NewAgentSessionsRouter instantiates a new router for agent sessions
+func NewAgentSessionsRouter(ctrl AgentSessionsController) *AgentSessionsRouter {
+	return &AgentSessionsRouter{
+		controller: ctrl,
+	}
+}
+
+// Mount the AgentSessionsRouter to a parent Router
+func (r *AgentSessionsRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/namespaces/{namespace}/{resource:agents}", r.list).Methods(http.MethodGet)
+	parent.HandleFunc("/namespaces/{namespace}/{resource:agents}/{id}", r.disconnect).Methods(http.MethodDelete)
+	if drainer, ok := r.controller.(AgentSessionsDrainer); ok {
+		parent.HandleFunc("/{resource:agents}/drain", drain(drainer)).Methods(http.MethodPost)
+	}
+	if deadLetters, ok := r.controller.(AgentDeadLetters); ok {
+		parent.HandleFunc("/{resource:agents}/dead-letters", listDeadLetters(deadLetters)).Methods(http.MethodGet)
+		parent.HandleFunc("/{resource:agents}/dead-letters", discardDeadLetters(deadLetters)).Methods(http.MethodDelete)
+		parent.HandleFunc("/{resource:agents}/dead-letters/retry", retryDeadLetters(deadLetters)).Methods(http.MethodPost)
+	}
+	if audit, ok := r.controller.(AgentConfigAudit); ok {
+		parent.HandleFunc("/namespaces/{namespace}/{resource:agents}/config-pushes", listConfigPushes(audit)).Methods(http.MethodGet)
+	}
+}
+
+func (r *AgentSessionsRouter) list(w http.ResponseWriter, req *http.Request) {
+	namespace, err := url.PathUnescape(mux.Vars(req)["namespace"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	sessions, err := r.controller.Sessions(req.Context(), namespace)
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sessions)
+}
+
+func (r *AgentSessionsRouter) disconnect(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	namespace, err := url.PathUnescape(vars["namespace"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	name, err := url.PathUnescape(vars["id"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	if err := r.controller.Disconnect(req.Context(), namespace, name); err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// drain starts draining the agent sessions of the backend that serves the
+// request. The backend accepts no new agent session until it restarts.
+func drain(drainer AgentSessionsDrainer) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if err := drainer.Drain(); err != nil {
+			WriteError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// listDeadLetters lists the agent events of the dead letter queue of the
+// backend that serves the request.
+func listDeadLetters(deadLetters AgentDeadLetters) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(deadLetters.DeadLetters())
+	}
+}
+
+// retryDeadLetters publishes the agent events of the dead letter queue of the
+// backend that serves the request again, without waiting for its next retry.
+func retryDeadLetters(deadLetters AgentDeadLetters) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(deadLetters.RetryDeadLetters())
+	}
+}
+
+// discardDeadLetters discards the agent events of the dead letter queue of
+// the backend that serves the request.
+func discardDeadLetters(deadLetters AgentDeadLetters) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		deadLetters.DiscardDeadLetters()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// listConfigPushes lists the entity config updates sent to the agents of the
+// namespace by all backends, or to the agent of the agent query parameter
+// only.
+func listConfigPushes(audit AgentConfigAudit) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		namespace, err := url.PathUnescape(mux.Vars(req)["namespace"])
+		if err != nil {
+			WriteError(w, actions.NewError(actions.InvalidArgument, err))
+			return
+		}
+		pushes, err := audit.ConfigPushes(req.Context(), namespace, req.URL.Query().Get("agent"))
+		if err != nil {
+			WriteError(w, actions.NewError(actions.InternalErr, err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(pushes)
+	}
+}
@@ -0,0 +1,275 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+type testAgentSessionsController []AgentSession
+
+func (c testAgentSessionsController) Sessions(ctx context.Context, namespace string) ([]AgentSession, error) {
+	if namespace == "broken" {
+		return nil, errors.New("agentd is down")
+	}
+	var sessions []AgentSession
+	for _, session := range c {
+		if session.Namespace == namespace {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (c testAgentSessionsController) Disconnect(ctx context.Context, namespace, name string) error {
+	for _, session := range c {
+		if session.Namespace == namespace && session.AgentName == name {
+			return nil
+		}
+	}
+	return &store.ErrNotFound{Key: name}
+}
+
+func TestAgentSessionsRouter(t *testing.T) {
+	controller := testAgentSessionsController{
+		{Namespace: "default", AgentName: "agent1", Subscriptions: []string{"linux"}},
+		{Namespace: "default", AgentName: "agent2"},
+		{Namespace: "other", AgentName: "agent3"},
+	}
+	router := mux.NewRouter().UseEncodedPath()
+	NewAgentSessionsRouter(controller).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		status int
+		want   int
+	}{
+		{path: "/namespaces/default/agents", status: http.StatusOK, want: 2},
+		{path: "/namespaces/other/agents", status: http.StatusOK, want: 1},
+		{path: "/namespaces/broken/agents", status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("bad status: got %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var sessions []AgentSession
+			if err := json.NewDecoder(resp.Body).Decode(&sessions); err != nil {
+				t.Fatal(err)
+			}
+			if len(sessions) != tt.want {
+				t.Errorf("got %d sessions, want %d", len(sessions), tt.want)
+			}
+		})
+	}
+}
+
+type testAgentSessionsDrainer struct {
+	testAgentSessionsController
+	drained bool
+}
+
+func (d *testAgentSessionsDrainer) Drain() error {
+	d.drained = true
+	return nil
+}
+
+func TestAgentSessionsRouterDrain(t *testing.T) {
+	drainer := &testAgentSessionsDrainer{}
+	router := mux.NewRouter().UseEncodedPath()
+	NewAgentSessionsRouter(drainer).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/agents/drain", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got, want := resp.StatusCode, http.StatusAccepted; got != want {
+		t.Fatalf("bad status: got %d, want %d", got, want)
+	}
+	if !drainer.drained {
+		t.Error("sessions not drained")
+	}
+}
+
+func TestAgentSessionsRouterDisconnect(t *testing.T) {
+	controller := testAgentSessionsController{
+		{Namespace: "default", AgentName: "agent1"},
+	}
+	router := mux.NewRouter().UseEncodedPath()
+	NewAgentSessionsRouter(controller).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{path: "/namespaces/default/agents/agent1", status: http.StatusNoContent},
+		{path: "/namespaces/default/agents/agent2", status: http.StatusNotFound},
+		{path: "/namespaces/other/agents/agent1", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodDelete, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("bad status: got %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+type testAgentDeadLetters struct {
+	testAgentSessionsController
+	letters []AgentDeadLetter
+}
+
+func (d *testAgentDeadLetters) DeadLetters() []AgentDeadLetter {
+	return d.letters
+}
+
+func (d *testAgentDeadLetters) RetryDeadLetters() AgentDeadLettersRetry {
+	republished := len(d.letters)
+	d.letters = nil
+	return AgentDeadLettersRetry{Republished: republished}
+}
+
+func (d *testAgentDeadLetters) DiscardDeadLetters() int {
+	discarded := len(d.letters)
+	d.letters = nil
+	return discarded
+}
+
+func TestAgentSessionsRouterDeadLetters(t *testing.T) {
+	deadLetters := &testAgentDeadLetters{
+		letters: []AgentDeadLetter{
+			{Namespace: "default", AgentName: "agent1", Topic: "sensu:event-raw"},
+			{Namespace: "default", AgentName: "agent2", Topic: "sensu:event-raw"},
+		},
+	}
+	router := mux.NewRouter().UseEncodedPath()
+	NewAgentSessionsRouter(deadLetters).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/agents/dead-letters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var letters []AgentDeadLetter
+	if err := json.NewDecoder(resp.Body).Decode(&letters); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(letters), 2; got != want {
+		t.Fatalf("got %d dead letters, want %d", got, want)
+	}
+
+	resp, err = http.Post(server.URL+"/agents/dead-letters/retry", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var retry AgentDeadLettersRetry
+	if err := json.NewDecoder(resp.Body).Decode(&retry); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := retry, (AgentDeadLettersRetry{Republished: 2}); got != want {
+		t.Errorf("bad retry: got %+v, want %+v", got, want)
+	}
+
+	deadLetters.letters = letters
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/agents/dead-letters", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got, want := resp.StatusCode, http.StatusNoContent; got != want {
+		t.Fatalf("bad status: got %d, want %d", got, want)
+	}
+	if len(deadLetters.letters) != 0 {
+		t.Error("dead letters not discarded")
+	}
+}
+
+type testAgentConfigAudit struct {
+	testAgentSessionsController
+	pushes []*store.AgentConfigPush
+}
+
+func (a testAgentConfigAudit) ConfigPushes(ctx context.Context, namespace, agent string) ([]*store.AgentConfigPush, error) {
+	pushes := []*store.AgentConfigPush{}
+	for _, push := range a.pushes {
+		if push.Namespace == namespace && (agent == "" || push.AgentName == agent) {
+			pushes = append(pushes, push)
+		}
+	}
+	return pushes, nil
+}
+
+func TestAgentSessionsRouterConfigPushes(t *testing.T) {
+	audit := testAgentConfigAudit{
+		pushes: []*store.AgentConfigPush{
+			{Namespace: "default", AgentName: "agent1", Action: "Update", SubscriptionsAdded: []string{"linux"}},
+			{Namespace: "default", AgentName: "agent2", Action: "Update", SubscriptionsRemoved: []string{"linux"}},
+			{Namespace: "acme", AgentName: "agent1", Action: "Create"},
+		},
+	}
+	router := mux.NewRouter().UseEncodedPath()
+	NewAgentSessionsRouter(audit).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "", want: 2},
+		{query: "?agent=agent2", want: 1},
+		{query: "?agent=agent3", want: 0},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(server.URL + "/namespaces/default/agents/config-pushes" + tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var pushes []store.AgentConfigPush
+		if err := json.NewDecoder(resp.Body).Decode(&pushes); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(pushes); got != tt.want {
+			t.Errorf("%q: got %d config pushes, want %d", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+)
+
+// AgentVersionsController represents the controller needs of the
+// AgentVersionsRouter
+type AgentVersionsController interface {
+	List(ctx context.Context) ([]actions.AgentVersionCount, error)
+}
+
+// AgentVersionsRouter handles requests for /agent-versions
+type AgentVersionsRouter struct {
+	controller AgentVersionsController
+}
+
+// NewAgentVersionsRouter instantiates a new router for agent versions
+func NewAgentVersionsRouter(ctrl AgentVersionsController) *AgentVersionsRouter {
+	return &AgentVersionsRouter{
+		controller: ctrl,
+	}
+}
+
+// Mount the AgentVersionsRouter to a parent Router
+func (r *AgentVersionsRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/{resource:agent-versions}", r.list).Methods(http.MethodGet)
+}
+
+func (r *AgentVersionsRouter) list(w http.ResponseWriter, req *http.Request) {
+	versions, err := r.controller.List(req.Context())
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(versions)
+}
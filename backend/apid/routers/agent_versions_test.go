@@ -0,0 +1,64 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+)
+
+type testAgentVersionsController struct {
+	versions []actions.AgentVersionCount
+	err      error
+}
+
+func (c testAgentVersionsController) List(ctx context.Context) ([]actions.AgentVersionCount, error) {
+	return c.versions, c.err
+}
+
+func TestAgentVersionsRouter(t *testing.T) {
+	versions := []actions.AgentVersionCount{
+		{Namespace: "default", Version: "7.1.0", Entities: 3, Sessions: 1},
+	}
+	tests := []struct {
+		name       string
+		controller testAgentVersionsController
+		status     int
+	}{
+		{name: "list", controller: testAgentVersionsController{versions: versions}, status: http.StatusOK},
+		{name: "error", controller: testAgentVersionsController{err: actions.NewError(actions.InternalErr, errors.New("oh noes"))}, status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			NewAgentVersionsRouter(tt.controller).Mount(router)
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			resp, err := new(http.Client).Do(newRequest(t, http.MethodGet, server.URL+"/agent-versions", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("bad status: %d", resp.StatusCode)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got []actions.AgentVersionCount
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, versions) {
+				t.Errorf("got %v, want %v", got, versions)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package routers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/messaging"
+)
+
+// BusController represents the controller needs of the BusRouter
+type BusController interface {
+	Topics() []messaging.TopicInfo
+}
+
+// BusRouter handles requests for /bus. It lists the topics of the message bus
+// of the backend that serves the request with their subscribers, such as the
+// agent sessions subscribed to the topics of the check requests, so that the
+// messages that reach no subscriber can be diagnosed.
+type BusRouter struct {
+	controller BusController
+}
+
+// NewBusRouter instantiates a new router for the message bus
+func NewBusRouter(ctrl BusController) *BusRouter {
+	return &BusRouter{
+		controller: ctrl,
+	}
+}
+
+// Mount the BusRouter to a parent Router
+func (r *BusRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/{resource:bus}/topics", r.listTopics).Methods(http.MethodGet)
+}
+
+// listTopics lists the topics of the bus, or the topics that start with the
+// prefix query parameter only.
+func (r *BusRouter) listTopics(w http.ResponseWriter, req *http.Request) {
+	prefix := req.URL.Query().Get("prefix")
+	topics := []messaging.TopicInfo{}
+	for _, topic := range r.controller.Topics() {
+		if strings.HasPrefix(topic.Topic, prefix) {
+			topics = append(topics, topic)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(topics)
+}
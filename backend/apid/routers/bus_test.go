@@ -0,0 +1,67 @@
+package routers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/messaging"
+)
+
+type mockBusController []messaging.TopicInfo
+
+func (m mockBusController) Topics() []messaging.TopicInfo {
+	return m
+}
+
+func TestBusRouter(t *testing.T) {
+	checkTopic := messaging.TopicInfo{
+		Topic:       messaging.SubscriptionTopic("default", "linux"),
+		Subscribers: []messaging.SubscriberInfo{{Consumer: "default:agent1-uuid", QueueCapacity: 100}},
+		Published:   3,
+	}
+	eventTopic := messaging.TopicInfo{
+		Topic:       messaging.TopicEventRaw,
+		Subscribers: []messaging.SubscriberInfo{{Consumer: "eventd", QueueDepth: 2, QueueCapacity: 1000}},
+		Published:   10,
+		Dropped:     1,
+	}
+
+	router := mux.NewRouter()
+	NewBusRouter(mockBusController{checkTopic, eventTopic}).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   []messaging.TopicInfo
+	}{
+		{name: "all topics", want: []messaging.TopicInfo{checkTopic, eventTopic}},
+		{name: "check topics", prefix: messaging.TopicSubscriptions + ":default:", want: []messaging.TopicInfo{checkTopic}},
+		{name: "no topic", prefix: "signal:", want: []messaging.TopicInfo{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := new(http.Client).Do(newRequest(t, http.MethodGet, server.URL+"/bus/topics?prefix="+url.QueryEscape(tt.prefix), nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("bad status: %d", resp.StatusCode)
+			}
+			var got []messaging.TopicInfo
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/membership"
+)
+
+// ClusterMembersController represents the controller needs of the
+// ClusterMembersRouter
+type ClusterMembersController interface {
+	List(ctx context.Context) ([]membership.Member, error)
+	Get(ctx context.Context, name string) (membership.Member, error)
+}
+
+// ClusterMembersRouter handles requests for /cluster/members
+type ClusterMembersRouter struct {
+	controller ClusterMembersController
+}
+
+// NewClusterMembersRouter instantiates a new router for cluster members
+func NewClusterMembersRouter(ctrl ClusterMembersController) *ClusterMembersRouter {
+	return &ClusterMembersRouter{
+		controller: ctrl,
+	}
+}
+
+// Mount the ClusterMembersRouter to a parent Router
+func (r *ClusterMembersRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/{resource:cluster}/members", r.list).Methods(http.MethodGet)
+	parent.HandleFunc("/{resource:cluster}/members/{id}", r.get).Methods(http.MethodGet)
+}
+
+func (r *ClusterMembersRouter) list(w http.ResponseWriter, req *http.Request) {
+	members, err := r.controller.List(req.Context())
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(members)
+}
+
+func (r *ClusterMembersRouter) get(w http.ResponseWriter, req *http.Request) {
+	name, err := url.PathUnescape(mux.Vars(req)["id"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	member, err := r.controller.Get(req.Context(), name)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(member)
+}
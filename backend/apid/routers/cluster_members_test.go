@@ -0,0 +1,86 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/membership"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockClusterMembersController struct {
+	mock.Mock
+}
+
+func (m *mockClusterMembersController) List(ctx context.Context) ([]membership.Member, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]membership.Member), args.Error(1)
+}
+
+func (m *mockClusterMembersController) Get(ctx context.Context, name string) (membership.Member, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(membership.Member), args.Error(1)
+}
+
+func TestClusterMembersRouter(t *testing.T) {
+	controller := &mockClusterMembersController{}
+	router := mux.NewRouter()
+	NewClusterMembersRouter(controller).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	member := membership.Member{
+		Name:    "backend-1",
+		Present: true,
+		Heartbeat: membership.Heartbeat{
+			Version:   "7.0.0",
+			StoreType: membership.StorePostgres,
+		},
+	}
+	controller.On("List", mock.Anything).Return([]membership.Member{member}, nil)
+	controller.On("Get", mock.Anything, "backend-1").Return(member, nil)
+	controller.On("Get", mock.Anything, "missing").Return(membership.Member{}, actions.NewErrorf(actions.NotFound))
+
+	client := new(http.Client)
+
+	resp, err := client.Do(newRequest(t, http.MethodGet, server.URL+"/cluster/members", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var members []membership.Member
+	if err := json.NewDecoder(resp.Body).Decode(&members); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if want := []membership.Member{member}; !reflect.DeepEqual(members, want) {
+		t.Errorf("bad members: got %v, want %v", members, want)
+	}
+
+	resp, err = client.Do(newRequest(t, http.MethodGet, server.URL+"/cluster/members/backend-1", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got membership.Member
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if !reflect.DeepEqual(got, member) {
+		t.Errorf("bad member: got %v, want %v", got, member)
+	}
+
+	resp, err = client.Do(newRequest(t, http.MethodGet, server.URL+"/cluster/members/missing", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("bad status: got %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
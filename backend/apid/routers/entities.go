@@ -2,9 +2,11 @@ package routers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/url"
+	"path"
 
 	"github.com/gorilla/mux"
 	corev2 "github.com/sensu/core/v2"
@@ -32,6 +34,7 @@ type EntityController interface {
 	List(ctx context.Context, pred *store.SelectionPredicate) ([]corev3.Resource, error)
 	Create(ctx context.Context, entity corev2.Entity) error
 	CreateOrReplace(ctx context.Context, entity corev2.Entity) error
+	BulkUpdate(ctx context.Context, req actions.BulkEntityRequest) (actions.BulkEntityResult, error)
 }
 
 // NewEntitiesRouter instantiates new router for controlling entities resources
@@ -59,6 +62,9 @@ func (r *EntitiesRouter) Mount(parent *mux.Router) {
 
 	ecHandlers := handlers.NewHandlers[*corev3.EntityConfig](r.store)
 
+	// Proxy entities can be created or updated in bulk
+	parent.HandleFunc(path.Join(routes.PathPrefix, "bulk"), r.bulkUpdate).Methods(http.MethodPost)
+
 	routes.Del(deleter.Delete)
 	routes.Get(r.find)
 	routes.List(r.controller.List, corev3.EntityFields)
@@ -97,6 +103,21 @@ func (r *EntitiesRouter) create(req *http.Request) (handlers.HandlerResponse, er
 	return responseWrap(entity, err)
 }
 
+func (r *EntitiesRouter) bulkUpdate(w http.ResponseWriter, req *http.Request) {
+	var bulkReq actions.BulkEntityRequest
+	if err := request.Decode(req, &bulkReq); err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	result, err := r.controller.BulkUpdate(req.Context(), bulkReq)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
 func (r *EntitiesRouter) createOrReplace(req *http.Request) (handlers.HandlerResponse, error) {
 	var response handlers.HandlerResponse
 	entity, err := request.Resource[*corev2.Entity](req)
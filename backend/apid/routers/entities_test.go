@@ -2,11 +2,18 @@ package routers
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/apid/middlewares"
 	"github.com/sensu/sensu-go/backend/store"
 	"github.com/sensu/sensu-go/testing/mockstore"
 	"github.com/stretchr/testify/mock"
@@ -36,6 +43,11 @@ func (m *mockEntitiesController) CreateOrReplace(ctx context.Context, entity cor
 	return args.Error(0)
 }
 
+func (m *mockEntitiesController) BulkUpdate(ctx context.Context, req actions.BulkEntityRequest) (actions.BulkEntityResult, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(actions.BulkEntityResult), args.Error(1)
+}
+
 func TestEntitiesRouter(t *testing.T) {
 	// Setup the router
 	controller := new(mockEntitiesController)
@@ -78,3 +90,68 @@ func TestEntitiesRouter(t *testing.T) {
 		run(t, tt, parentRouter, s)
 	}
 }
+
+func TestEntitiesRouterBulkUpdate(t *testing.T) {
+	controller := new(mockEntitiesController)
+	s := new(mockstore.V2MockStore)
+	s.On("GetConfigStore").Return(new(mockstore.ConfigStore))
+	s.On("GetEntityStore").Return(new(mockstore.MockStore))
+	s.On("GetEventStore").Return(new(mockstore.MockStore))
+	router := NewEntitiesRouter(s)
+	router.controller = controller
+	parentRouter := mux.NewRouter().PathPrefix(corev2.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+	server := httptest.NewServer(parentRouter)
+	defer server.Close()
+
+	result := actions.BulkEntityResult{Entities: []actions.BulkEntityItem{
+		{Name: "foo", Status: actions.BulkEntityCreated},
+	}}
+	controller.On("BulkUpdate", mock.Anything, mock.MatchedBy(func(req actions.BulkEntityRequest) bool {
+		return len(req.Entities) == 1 && req.Entities[0].Name == "foo" && req.SyncLabel == "cmdb" && req.DeleteMissing
+	})).Return(result, nil).Once()
+
+	url := server.URL + "/api/core/v2/namespaces/default/entities/bulk"
+	body := `{"entities": [{"metadata": {"name": "foo"}, "entity_class": "proxy"}], "sync_label": "cmdb", "sync_value": "nightly", "delete_missing": true}`
+	res, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("bad status code: %d", res.StatusCode)
+	}
+	var got actions.BulkEntityResult
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Entities) != 1 || got.Entities[0] != result.Entities[0] {
+		t.Errorf("bad result: %+v", got)
+	}
+
+	res, err = http.Post(url, "application/json", strings.NewReader("{"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad status code for an invalid request: %d", res.StatusCode)
+	}
+	// The body of unknown length that exceeds the bulk limit is rejected
+	// while it is decoded
+	limited := httptest.NewServer(middlewares.LimitRequest{Limit: 1024, Bulk: 64}.Then(parentRouter))
+	defer limited.Close()
+	req, err := http.NewRequest(http.MethodPost, limited.URL+"/api/core/v2/namespaces/default/entities/bulk", io.MultiReader(strings.NewReader(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("bad status code for a request over the limit: %d", res.StatusCode)
+	}
+}
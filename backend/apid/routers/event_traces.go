@@ -0,0 +1,54 @@
+package routers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// EventTracesRouter handles requests for /events/{entity}/{check}/trace
+type EventTracesRouter struct {
+	store store.EventTraceStore
+}
+
+// NewEventTracesRouter instantiates a new router for event traces
+func NewEventTracesRouter(store store.EventTraceStore) *EventTracesRouter {
+	return &EventTracesRouter{
+		store: store,
+	}
+}
+
+// Mount the EventTracesRouter to a parent Router
+func (r *EventTracesRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/namespaces/{namespace}/{resource:events}/{entity}/{check}/trace", r.get).Methods(http.MethodGet)
+}
+
+func (r *EventTracesRouter) get(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	var params [3]string
+	for i, key := range []string{"namespace", "entity", "check"} {
+		value, err := url.PathUnescape(vars[key])
+		if err != nil {
+			WriteError(w, actions.NewError(actions.InvalidArgument, err))
+			return
+		}
+		params[i] = value
+	}
+	trace, err := r.store.GetEventTrace(req.Context(), params[0], params[1], params[2])
+	if err != nil {
+		var notFound *store.ErrNotFound
+		if errors.As(err, &notFound) {
+			WriteError(w, actions.NewErrorf(actions.NotFound))
+			return
+		}
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(trace)
+}
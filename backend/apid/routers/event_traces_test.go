@@ -0,0 +1,75 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+type testEventTraceStore map[string]*store.EventTrace
+
+func (s testEventTraceStore) AppendEventTrace(ctx context.Context, trace *store.EventTrace) error {
+	s[trace.Namespace+"/"+trace.Entity+"/"+trace.Check] = trace
+	return nil
+}
+
+func (s testEventTraceStore) GetEventTrace(ctx context.Context, namespace, entity, check string) (*store.EventTrace, error) {
+	trace, ok := s[namespace+"/"+entity+"/"+check]
+	if !ok {
+		return nil, &store.ErrNotFound{Key: check}
+	}
+	return trace, nil
+}
+
+func TestEventTracesRouter(t *testing.T) {
+	traces := testEventTraceStore{}
+	_ = traces.AppendEventTrace(context.Background(), &store.EventTrace{
+		Namespace: "default",
+		Entity:    "entity1",
+		Check:     "check1",
+		EventID:   "abc",
+		Steps: []store.EventTraceStep{
+			{Component: "eventd", Step: "store", Result: "stored"},
+		},
+	})
+	router := mux.NewRouter().UseEncodedPath()
+	NewEventTracesRouter(traces).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{path: "/namespaces/default/events/entity1/check1/trace", status: http.StatusOK},
+		{path: "/namespaces/default/events/entity1/check2/trace", status: http.StatusNotFound},
+		{path: "/namespaces/other/events/entity1/check1/trace", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("bad status: got %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var trace store.EventTrace
+			if err := json.NewDecoder(resp.Body).Decode(&trace); err != nil {
+				t.Fatal(err)
+			}
+			if trace.EventID != "abc" || len(trace.Steps) != 1 {
+				t.Errorf("bad trace: %v", trace)
+			}
+		})
+	}
+}
@@ -2,9 +2,12 @@ package routers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	corev2 "github.com/sensu/core/v2"
@@ -13,6 +16,7 @@ import (
 	"github.com/sensu/sensu-go/backend/apid/handlers"
 	"github.com/sensu/sensu-go/backend/apid/request"
 	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/pipeline"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 )
@@ -20,14 +24,26 @@ import (
 // EventsRouter handles requests for /events
 type EventsRouter struct {
 	controller eventController
+
+	// IdempotencyKeys stores the idempotency keys of the event submissions.
+	// The Idempotency-Key header is ignored when it is nil.
+	IdempotencyKeys store.IdempotencyKeyStore
+
+	// IdempotencyWindow is the duration during which the event submissions
+	// with the same idempotency key are only processed once.
+	IdempotencyWindow time.Duration
 }
 
 // eventController represents the controller needs of the EventsRouter.
 type eventController interface {
 	CreateOrReplace(ctx context.Context, check *corev2.Event) error
+	BulkCreateOrReplace(ctx context.Context, events []*corev2.Event) actions.BulkCreateEventsResult
 	Delete(ctx context.Context, entity, check string) error
 	Get(ctx context.Context, entity, check string) (*corev2.Event, error)
 	List(ctx context.Context, pred *store.SelectionPredicate) ([]corev3.Resource, error)
+	BulkDelete(ctx context.Context) (actions.BulkEventResult, error)
+	BulkResolve(ctx context.Context, notify bool) (actions.BulkEventResult, error)
+	Resolve(ctx context.Context, entity, check string, notify bool) (*corev2.Event, error)
 }
 
 // NewEventsRouter instantiates new events controller
@@ -44,12 +60,26 @@ func (r *EventsRouter) Mount(parent *mux.Router) {
 		PathPrefix: "/namespaces/{namespace}/{resource:events}",
 	}
 
-	routes.Post(r.create)
+	parent.Handle(routes.PathPrefix, r.idempotent(actionHandler(r.create))).Methods(http.MethodPost)
 	routes.List(r.controller.List, corev3.EventFields)
 	routes.ListAllNamespaces(r.controller.List, "/{resource:events}", corev3.EventFields)
 	routes.Path("{entity}/{check}", r.get).Methods(http.MethodGet)
 	routes.Path("{entity}/{check}", r.delete).Methods(http.MethodDelete)
-	routes.Path("{entity}/{check}", r.createOrReplace).Methods(http.MethodPost, http.MethodPut)
+	parent.Handle(path.Join(routes.PathPrefix, "{entity}/{check}"), r.idempotent(actionHandler(r.createOrReplace))).
+		Methods(http.MethodPost, http.MethodPut)
+	routes.Path("{entity}/{check}/resolve", r.resolve).Methods(http.MethodPost)
+
+	// The events replayed by the replay harness are created with their own
+	// resource, so that the permission to replay events, which stubs their
+	// handlers, is distinct from the permission to create events
+	parent.Handle("/namespaces/{namespace}/{resource:replays}/{id}/events", actionHandler(r.replay)).
+		Methods(http.MethodPost)
+
+	// Events can be created in bulk, and events that match a selector can be
+	// deleted or resolved in bulk
+	parent.HandleFunc(path.Join(routes.PathPrefix, "bulk"), r.bulkCreateOrReplace).Methods(http.MethodPost)
+	parent.HandleFunc(routes.PathPrefix, r.bulk(r.bulkDelete)).Methods(http.MethodDelete)
+	parent.HandleFunc(routes.PathPrefix, r.bulk(r.bulkResolve)).Methods(http.MethodPatch)
 
 	// Additionaly allow a subcollection to be specified when listing events,
 	// which correspond to the entity name here
@@ -84,6 +114,29 @@ func (r *EventsRouter) create(req *http.Request) (handlers.HandlerResponse, erro
 	if err := validateEventPayload(event, vars); err != nil {
 		return response, err
 	}
+	pipeline.StripReplayAnnotation(event)
+
+	err = r.controller.CreateOrReplace(req.Context(), event)
+	return response, err
+}
+
+// replay creates an event replayed by the replay harness, annotated with the
+// ID of the replay so that its handlers are stubbed.
+func (r *EventsRouter) replay(req *http.Request) (handlers.HandlerResponse, error) {
+	var response handlers.HandlerResponse
+	event, err := request.Resource[*corev2.Event](req)
+	if err != nil {
+		return response, actions.NewError(actions.InvalidArgument, err)
+	}
+
+	vars := mux.Vars(req)
+	if err := validateEventPayload(event, vars); err != nil {
+		return response, err
+	}
+	if event.Annotations == nil {
+		event.Annotations = make(map[string]string)
+	}
+	event.Annotations[pipeline.ReplayAnnotation] = vars["id"]
 
 	err = r.controller.CreateOrReplace(req.Context(), event)
 	return response, err
@@ -100,11 +153,83 @@ func (r *EventsRouter) createOrReplace(req *http.Request) (handlers.HandlerRespo
 	if err := validateEventPayload(event, vars); err != nil {
 		return response, err
 	}
+	pipeline.StripReplayAnnotation(event)
 
 	err = r.controller.CreateOrReplace(req.Context(), event)
 	return response, err
 }
 
+// resolve resolves an event. The resolved event goes through its pipelines
+// unless the notify query parameter is false.
+func (r *EventsRouter) resolve(req *http.Request) (handlers.HandlerResponse, error) {
+	params := actions.QueryParams(mux.Vars(req))
+	entity := url.PathEscape(params["entity"])
+	check := url.PathEscape(params["check"])
+	notify, err := notifyParam(req)
+	if err != nil {
+		return handlers.HandlerResponse{}, err
+	}
+	event, err := r.controller.Resolve(req.Context(), entity, check, notify)
+	return handlers.HandlerResponse{Resource: event}, err
+}
+
+// bulkCreateOrReplace creates or replaces the events of the request body, a
+// list of events of the namespace of the request. The events that can't be
+// created are reported in the response, with the status of each event.
+func (r *EventsRouter) bulkCreateOrReplace(w http.ResponseWriter, req *http.Request) {
+	var events []*corev2.Event
+	if err := request.Decode(req, &events); err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	for _, event := range events {
+		if event != nil {
+			pipeline.StripReplayAnnotation(event)
+		}
+	}
+	result := r.controller.BulkCreateOrReplace(req.Context(), events)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (r *EventsRouter) bulkDelete(req *http.Request) (actions.BulkEventResult, error) {
+	return r.controller.BulkDelete(req.Context())
+}
+
+func (r *EventsRouter) bulkResolve(req *http.Request) (actions.BulkEventResult, error) {
+	notify, err := notifyParam(req)
+	if err != nil {
+		return actions.BulkEventResult{}, err
+	}
+	return r.controller.BulkResolve(req.Context(), notify)
+}
+
+// notifyParam returns the notify query parameter of the event resolutions,
+// which is true by default.
+func notifyParam(req *http.Request) (bool, error) {
+	value := req.URL.Query().Get("notify")
+	if value == "" {
+		return true, nil
+	}
+	notify, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, actions.NewErrorf(actions.InvalidArgument, "invalid notify parameter: %q", value)
+	}
+	return notify, nil
+}
+
+func (r *EventsRouter) bulk(fn func(*http.Request) (actions.BulkEventResult, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		result, err := fn(req)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
+
 // validateEventPayload validates the event payload against the URL path values
 func validateEventPayload(event *corev2.Event, vars map[string]string) error {
 	if event.Entity != nil {
@@ -0,0 +1,94 @@
+package routers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/apid/handlers"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+const (
+	// IdempotencyKeyHeader is the header of the event submissions that
+	// identifies them across their retries.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on the responses to the event
+	// submissions whose idempotency key was already claimed, and which were
+	// not processed again.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength is the maximum length of the idempotency keys.
+	maxIdempotencyKeyLength = 255
+
+	// idempotencyReleaseTimeout is how long the release of the key of a
+	// failed event submission can take.
+	idempotencyReleaseTimeout = 5 * time.Second
+)
+
+// idempotent processes the event submissions of next at most once per
+// idempotency key during the idempotency window. The key is claimed before
+// the submission is processed, and released if the submission fails, so
+// that it can be retried.
+func (r *EventsRouter) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(IdempotencyKeyHeader)
+		if key == "" || r.IdempotencyKeys == nil || r.IdempotencyWindow <= 0 {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			WriteError(w, actions.NewErrorf(actions.InvalidArgument, "the idempotency key must be at most %d characters long", maxIdempotencyKeyLength))
+			return
+		}
+		namespace, err := url.PathUnescape(mux.Vars(req)["namespace"])
+		if err != nil {
+			WriteError(w, actions.NewError(actions.InvalidArgument, err))
+			return
+		}
+
+		now := time.Now()
+		claimed, err := r.IdempotencyKeys.ClaimIdempotencyKey(req.Context(), &store.IdempotencyKey{
+			Namespace: namespace,
+			Key:       key,
+			ClaimedAt: now,
+			ExpiresAt: now.Add(r.IdempotencyWindow),
+		})
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		if !claimed {
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			RespondWith(w, req, handlers.HandlerResponse{})
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+		if recorder.status >= http.StatusBadRequest {
+			// The key is released even if the submission failed because
+			// the client went away or the request timed out
+			ctx, cancel := context.WithTimeout(context.Background(), idempotencyReleaseTimeout)
+			defer cancel()
+			if err := r.IdempotencyKeys.ReleaseIdempotencyKey(ctx, namespace, key); err != nil {
+				logger.WithError(err).Warn("could not release the idempotency key of a failed event submission")
+			}
+		}
+	})
+}
+
+// statusRecorder records the status of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,124 @@
+package routers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/stretchr/testify/mock"
+)
+
+type testIdempotencyKeys struct {
+	mu   sync.Mutex
+	keys map[string]time.Time
+}
+
+func (k *testIdempotencyKeys) ClaimIdempotencyKey(ctx context.Context, key *store.IdempotencyKey) (bool, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	id := path.Join(key.Namespace, key.Key)
+	if expiresAt, ok := k.keys[id]; ok && expiresAt.After(key.ClaimedAt) {
+		return false, nil
+	}
+	k.keys[id] = key.ExpiresAt
+	return true, nil
+}
+
+func (k *testIdempotencyKeys) ReleaseIdempotencyKey(ctx context.Context, namespace, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	id := path.Join(namespace, key)
+	if _, ok := k.keys[id]; !ok {
+		return &store.ErrNotFound{Key: id}
+	}
+	delete(k.keys, id)
+	return nil
+}
+
+func TestEventsRouterIdempotencyKey(t *testing.T) {
+	controller := &mockEventController{}
+	router := EventsRouter{
+		controller:        controller,
+		IdempotencyKeys:   &testIdempotencyKeys{keys: make(map[string]time.Time)},
+		IdempotencyWindow: time.Minute,
+	}
+	parentRouter := mux.NewRouter().PathPrefix(corev2.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+	server := httptest.NewServer(parentRouter)
+	defer server.Close()
+
+	event := corev2.FixtureEvent("foo", "check-cpu")
+	body, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := func(key string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/api/core/v2/namespaces/default/events", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(IdempotencyKeyHeader, key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	// A failed submission releases its key
+	controller.On("CreateOrReplace", mock.Anything, mock.Anything).Return(errors.New("store is down")).Once()
+	if resp := post("abc"); resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("bad status: got %d, want %d", resp.StatusCode, http.StatusInternalServerError)
+	}
+
+	controller.On("CreateOrReplace", mock.Anything, mock.Anything).Return(nil).Once()
+	if resp := post("abc"); resp.StatusCode != http.StatusCreated || resp.Header.Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("bad response: got %d, replayed %q", resp.StatusCode, resp.Header.Get(IdempotentReplayedHeader))
+	}
+
+	// The retry is not processed again
+	if resp := post("abc"); resp.StatusCode != http.StatusCreated || resp.Header.Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("bad response: got %d, replayed %q", resp.StatusCode, resp.Header.Get(IdempotentReplayedHeader))
+	}
+	controller.AssertNumberOfCalls(t, "CreateOrReplace", 2)
+}
+
+func TestEventsRouterIdempotencyKeyCanceled(t *testing.T) {
+	keys := &testIdempotencyKeys{keys: make(map[string]time.Time)}
+	router := EventsRouter{
+		IdempotencyKeys:   keys,
+		IdempotencyWindow: time.Minute,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := router.idempotent(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// The client goes away while the submission is processed
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/api/core/v2/namespaces/default/events", nil).WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{"namespace": "default"})
+	req.Header.Set(IdempotencyKeyHeader, "abc")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// The key of the failed submission is released nonetheless
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	if _, ok := keys.keys["default/abc"]; ok {
+		t.Error("the idempotency key was not released")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/pipeline"
 	"github.com/sensu/sensu-go/backend/store"
 	"github.com/stretchr/testify/mock"
 )
@@ -38,6 +39,25 @@ func (m *mockEventController) List(ctx context.Context, pred *store.SelectionPre
 	return args.Get(0).([]corev3.Resource), args.Error(1)
 }
 
+func (m *mockEventController) BulkCreateOrReplace(ctx context.Context, events []*corev2.Event) actions.BulkCreateEventsResult {
+	return m.Called(ctx, events).Get(0).(actions.BulkCreateEventsResult)
+}
+
+func (m *mockEventController) BulkDelete(ctx context.Context) (actions.BulkEventResult, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(actions.BulkEventResult), args.Error(1)
+}
+
+func (m *mockEventController) BulkResolve(ctx context.Context, notify bool) (actions.BulkEventResult, error) {
+	args := m.Called(ctx, notify)
+	return args.Get(0).(actions.BulkEventResult), args.Error(1)
+}
+
+func (m *mockEventController) Resolve(ctx context.Context, entity, check string, notify bool) (*corev2.Event, error) {
+	args := m.Called(ctx, entity, check, notify)
+	return args.Get(0).(*corev2.Event), args.Error(1)
+}
+
 func TestEventsRouter(t *testing.T) {
 	type controllerFunc func(*mockEventController)
 
@@ -378,6 +398,114 @@ func TestEventsRouter(t *testing.T) {
 			},
 			wantStatusCode: http.StatusNoContent,
 		},
+		//
+		// BULK
+		//
+		{
+			name:           "it returns 400 if the events created in bulk can't be decoded",
+			method:         http.MethodPost,
+			path:           empty.URIPath() + "/bulk",
+			body:           []byte(`foo`),
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "it returns 200 if events were created in bulk",
+			method: http.MethodPost,
+			path:   empty.URIPath() + "/bulk",
+			body:   []byte(`[{"entity": {"metadata": {"name": "foo"}}, "check": {"metadata": {"name": "check-cpu"}}}]`),
+			controllerFunc: func(c *mockEventController) {
+				c.On("BulkCreateOrReplace", mock.Anything, mock.Anything).
+					Return(actions.BulkCreateEventsResult{Events: []actions.BulkEventItem{{Event: "foo/check-cpu", Status: actions.BulkEventCreated}}}).
+					Once()
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "it returns 400 if events are deleted in bulk without a selector",
+			method: http.MethodDelete,
+			path:   empty.URIPath(),
+			controllerFunc: func(c *mockEventController) {
+				c.On("BulkDelete", mock.Anything).
+					Return(actions.BulkEventResult{}, actions.NewErrorf(actions.InvalidArgument)).
+					Once()
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "it returns 200 if events were deleted in bulk",
+			method: http.MethodDelete,
+			path:   empty.URIPath(),
+			controllerFunc: func(c *mockEventController) {
+				c.On("BulkDelete", mock.Anything).
+					Return(actions.BulkEventResult{Events: []string{"foo/check-cpu"}}, nil).
+					Once()
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "it returns 200 if events were resolved in bulk",
+			method: http.MethodPatch,
+			path:   empty.URIPath(),
+			controllerFunc: func(c *mockEventController) {
+				c.On("BulkResolve", mock.Anything, true).
+					Return(actions.BulkEventResult{Events: []string{"foo/check-cpu"}}, nil).
+					Once()
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "it returns 200 if events were resolved in bulk without notification",
+			method: http.MethodPatch,
+			path:   empty.URIPath() + "?notify=false",
+			controllerFunc: func(c *mockEventController) {
+				c.On("BulkResolve", mock.Anything, false).
+					Return(actions.BulkEventResult{Events: []string{"foo/check-cpu"}}, nil).
+					Once()
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		//
+		// RESOLVE
+		//
+		{
+			name:   "it returns 200 if the event was resolved",
+			method: http.MethodPost,
+			path:   fixture.URIPath() + "/resolve",
+			controllerFunc: func(c *mockEventController) {
+				c.On("Resolve", mock.Anything, "foo", "check-cpu", true).
+					Return(fixture, nil).
+					Once()
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "it returns 200 if the event was resolved without notification",
+			method: http.MethodPost,
+			path:   fixture.URIPath() + "/resolve?notify=false",
+			controllerFunc: func(c *mockEventController) {
+				c.On("Resolve", mock.Anything, "foo", "check-cpu", false).
+					Return(fixture, nil).
+					Once()
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "it returns 400 if the notify parameter is invalid",
+			method:         http.MethodPost,
+			path:           fixture.URIPath() + "/resolve?notify=maybe",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "it returns 404 if the resolved event does not exist",
+			method: http.MethodPost,
+			path:   corev2.FixtureEvent("foo", "missing").URIPath() + "/resolve",
+			controllerFunc: func(c *mockEventController) {
+				c.On("Resolve", mock.Anything, "foo", "missing", true).
+					Return((*corev2.Event)(nil), actions.NewErrorf(actions.NotFound)).
+					Once()
+			},
+			wantStatusCode: http.StatusNotFound,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -413,3 +541,47 @@ func TestEventsRouter(t *testing.T) {
 		})
 	}
 }
+
+func TestEventsRouterReplayAnnotation(t *testing.T) {
+	controller := &mockEventController{}
+	router := EventsRouter{controller: controller}
+	parentRouter := mux.NewRouter().PathPrefix(corev2.URLPrefix).Subrouter()
+	router.Mount(parentRouter)
+
+	event := corev2.FixtureEvent("foo", "check-cpu")
+	event.Annotations = map[string]string{pipeline.ReplayAnnotation: "forged"}
+	body := marshalWrapped(event)
+
+	var replayIDs []string
+	controller.On("CreateOrReplace", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		replayIDs = append(replayIDs, args.Get(1).(*corev2.Event).Annotations[pipeline.ReplayAnnotation])
+	})
+
+	// The events created through the events API can't stub their handlers,
+	// unlike those created through the replays API
+	for _, path := range []string{
+		"/api/core/v2/namespaces/default/events",
+		event.URIPath(),
+		"/api/core/v2/namespaces/default/replays/replay-1/events",
+	} {
+		method := http.MethodPost
+		if path == event.URIPath() {
+			method = http.MethodPut
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		parentRouter.ServeHTTP(rr, req)
+		if rr.Code >= 400 {
+			t.Fatalf("%s %s: unexpected status %d: %s", method, path, rr.Code, rr.Body.String())
+		}
+	}
+	want := []string{"", "", "replay-1"}
+	if len(replayIDs) != len(want) {
+		t.Fatalf("got replay IDs %v, want %v", replayIDs, want)
+	}
+	for i := range want {
+		if replayIDs[i] != want[i] {
+			t.Errorf("got replay IDs %v, want %v", replayIDs, want)
+		}
+	}
+}
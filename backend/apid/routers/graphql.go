@@ -23,10 +23,20 @@ type GraphQLService interface {
 	Do(context.Context, graphql.QueryParams) *graphql.Result
 }
 
+// ReadOnlyGuard tells whether the resources can be changed, or whether the
+// API is in read-only mode.
+type ReadOnlyGuard interface {
+	Allow(context.Context) error
+}
+
 // GraphQLRouter handles requests for /events
 type GraphQLRouter struct {
 	Service GraphQLService
 	Timeout time.Duration
+
+	// ReadOnly rejects the mutations while the API is in read-only mode.
+	// The mutations are always allowed if it is nil.
+	ReadOnly ReadOnlyGuard
 }
 
 // Mount the GraphQLRouter to a parent Router
@@ -69,6 +79,22 @@ func (r *GraphQLRouter) query(w http.ResponseWriter, req *http.Request) {
 		WriteError(w, errors.New("received unexpected response body"))
 	}
 
+	// The requests are all POST requests, so the read-only mode only
+	// applies to the mutations
+	if r.ReadOnly != nil {
+		for _, op := range ops {
+			query, _ := op["query"].(string)
+			if !graphql.IsMutation(query) {
+				continue
+			}
+			if err := r.ReadOnly.Allow(ctx); err != nil {
+				WriteError(w, err)
+				return
+			}
+			break
+		}
+	}
+
 	claims := jwt.GetClaimsFromContext(req.Context())
 
 	// Execute each operation; maybe this could be done in parallel in the future.
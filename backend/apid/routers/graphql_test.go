@@ -2,12 +2,14 @@ package routers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/graphql-go/graphql/testutil"
+	"github.com/sensu/sensu-go/backend/apid/actions"
 	"github.com/sensu/sensu-go/backend/apid/graphql"
 )
 
@@ -79,3 +81,44 @@ func TestHttpGraphQLBatchRequest(t *testing.T) {
 		t.Error("response failed")
 	}
 }
+
+type readOnlyGuard struct{}
+
+func (readOnlyGuard) Allow(context.Context) error {
+	return actions.NewErrorf(actions.Unavailable, "the API is in read-only mode")
+}
+
+func TestHttpGraphQLReadOnly(t *testing.T) {
+	service, err := graphql.NewService(graphql.ServiceConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := &GraphQLRouter{Service: service, ReadOnly: readOnlyGuard{}}
+
+	// The queries are still allowed in read-only mode
+	req, err := setupRequest(http.MethodPost, "/graphql", map[string]interface{}{
+		"query": testutil.IntrospectionQuery,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := httptest.NewRecorder()
+	router.query(writer, req)
+	if got := writer.Result().StatusCode; got != http.StatusOK {
+		t.Errorf("query: got status %d, want %d", got, http.StatusOK)
+	}
+
+	// The mutations are not
+	req, err = setupRequest(http.MethodPost, "/graphql", map[string]interface{}{
+		"query": `mutation { deleteEntity(input: {id: "foo"}) { deletedId } }`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer = httptest.NewRecorder()
+	router.query(writer, req)
+	if got := writer.Result().StatusCode; got != http.StatusServiceUnavailable {
+		t.Errorf("mutation: got status %d, want %d", got, http.StatusServiceUnavailable)
+	}
+}
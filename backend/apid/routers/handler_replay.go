@@ -0,0 +1,109 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/apid/request"
+)
+
+// HandlerReplayController represents the controller needs of the
+// HandlerReplayRouter
+type HandlerReplayController interface {
+	Replay(ctx context.Context, name string, req actions.HandlerReplayRequest) (actions.BulkEventResult, error)
+}
+
+// HandlerReplayRouter handles requests for /handlers/{id}/replay. It sends
+// stored events through a handler again, so that events that a misconfigured
+// handler failed to deliver can be recovered.
+type HandlerReplayRouter struct {
+	controller HandlerReplayController
+	jobs       JobsController
+}
+
+// NewHandlerReplayRouter instantiates a new router for the replay of events
+// through handlers. The replays are run in the background when the async
+// query parameter is true and jobs is not nil.
+func NewHandlerReplayRouter(ctrl HandlerReplayController, jobs JobsController) *HandlerReplayRouter {
+	return &HandlerReplayRouter{
+		controller: ctrl,
+		jobs:       jobs,
+	}
+}
+
+// Mount the HandlerReplayRouter to a parent Router
+func (r *HandlerReplayRouter) Mount(parent *mux.Router) {
+	if r.jobs != nil {
+		// Long replays outlive the deadline of the API requests, they are
+		// run in the background
+		parent.HandleFunc("/namespaces/{namespace}/{resource:handlers}/{id}/replay", r.replayAsync).
+			Methods(http.MethodPost).Queries("async", "true")
+	}
+	parent.HandleFunc("/namespaces/{namespace}/{resource:handlers}/{id}/replay", r.replay).Methods(http.MethodPost)
+}
+
+func (r *HandlerReplayRouter) replay(w http.ResponseWriter, req *http.Request) {
+	name, replayReq, err := decodeReplay(req)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	result, err := r.controller.Replay(req.Context(), name, replayReq)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (r *HandlerReplayRouter) replayAsync(w http.ResponseWriter, req *http.Request) {
+	namespace, err := url.PathUnescape(mux.Vars(req)["namespace"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	name, replayReq, err := decodeReplay(req)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	if err := actions.ValidateHandlerReplay(req.Context(), replayReq); err != nil {
+		WriteError(w, err)
+		return
+	}
+	query := req.URL.Query()
+	params := actions.ReplayJobParams(name, replayReq,
+		strings.Join(query["labelSelector"], " && "),
+		strings.Join(query["fieldSelector"], " && "))
+	job, err := r.jobs.SubmitJob(req.Context(), namespace, actions.ReplayHandlerJob, params)
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+// decodeReplay returns the name of the handler and the replay request of req.
+func decodeReplay(req *http.Request) (string, actions.HandlerReplayRequest, error) {
+	var replayReq actions.HandlerReplayRequest
+	name, err := url.PathUnescape(mux.Vars(req)["id"])
+	if err != nil {
+		return name, replayReq, actions.NewError(actions.InvalidArgument, err)
+	}
+	// The request body is optional, the events can be selected with
+	// selectors only
+	if err := request.Decode(req, &replayReq); err != nil && !errors.Is(err, io.EOF) {
+		return name, replayReq, actions.NewError(actions.InvalidArgument, err)
+	}
+	return name, replayReq, nil
+}
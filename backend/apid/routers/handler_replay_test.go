@@ -0,0 +1,121 @@
+package routers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockHandlerReplayController struct {
+	mock.Mock
+}
+
+func (m *mockHandlerReplayController) Replay(ctx context.Context, name string, req actions.HandlerReplayRequest) (actions.BulkEventResult, error) {
+	args := m.Called(ctx, name, req)
+	return args.Get(0).(actions.BulkEventResult), args.Error(1)
+}
+
+func TestHandlerReplayRouter(t *testing.T) {
+	controller := &mockHandlerReplayController{}
+	router := mux.NewRouter()
+	NewHandlerReplayRouter(controller, nil).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	controller.On("Replay", mock.Anything, "slack", actions.HandlerReplayRequest{Start: 100, End: 200}).
+		Return(actions.BulkEventResult{Events: []string{"entity1/check1"}}, nil)
+	controller.On("Replay", mock.Anything, "slack", actions.HandlerReplayRequest{}).
+		Return(actions.BulkEventResult{}, actions.NewErrorf(actions.InvalidArgument))
+	controller.On("Replay", mock.Anything, "missing", mock.Anything).
+		Return(actions.BulkEventResult{}, actions.NewErrorf(actions.NotFound))
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{
+			name:   "replay",
+			path:   "/namespaces/default/handlers/slack/replay",
+			body:   `{"start": 100, "end": 200}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "no body",
+			path:   "/namespaces/default/handlers/slack/replay",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid body",
+			path:   "/namespaces/default/handlers/slack/replay",
+			body:   `{"start": "yesterday"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing handler",
+			path:   "/namespaces/default/handlers/missing/replay",
+			body:   `{"start": 100}`,
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+tt.path, "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("bad status: got %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestHandlerReplayRouterAsync(t *testing.T) {
+	controller := &mockHandlerReplayController{}
+	jobs := testJobsController{}
+	router := mux.NewRouter()
+	NewHandlerReplayRouter(controller, jobs).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/namespaces/default/handlers/slack/replay?async=true", "application/json", strings.NewReader(`{"start": 100, "rate": 2.5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("bad status: got %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	job, ok := jobs["default/job1"]
+	if !ok {
+		t.Fatal("no job submitted")
+	}
+	if got, want := job.Kind, actions.ReplayHandlerJob; got != want {
+		t.Errorf("bad job kind: got %q, want %q", got, want)
+	}
+	want := map[string]string{"handler": "slack", "start": "100", "rate": "2.5"}
+	if !reflect.DeepEqual(job.Params, want) {
+		t.Errorf("bad job params: got %v, want %v", job.Params, want)
+	}
+	controller.AssertNotCalled(t, "Replay", mock.Anything, mock.Anything, mock.Anything)
+
+	// The replay request is validated before the job is submitted
+	resp, err = http.Post(server.URL+"/namespaces/default/handlers/slack/replay?async=true", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("bad status: got %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -10,7 +10,11 @@ import (
 
 	"github.com/gorilla/mux"
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/backpressure"
+	"github.com/sensu/sensu-go/backend/daemon"
+	"github.com/sensu/sensu-go/backend/featuregate"
 	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/backend/timezone"
 )
 
 const defaultTimeout = 3
@@ -20,10 +24,35 @@ type HealthController interface {
 	GetClusterHealth(ctx context.Context) *corev2.HealthResponse
 }
 
+// BackpressureReporter reports the saturation of the queues of the backend.
+type BackpressureReporter interface {
+	Report() backpressure.Report
+}
+
+// DaemonStatusReporter reports the statuses of the daemons of the backend.
+type DaemonStatusReporter interface {
+	List() []daemon.Status
+}
+
 // HealthRouter handles requests for /health
 type HealthRouter struct {
-	controller HealthController
-	mu         sync.Mutex
+	controller   HealthController
+	backpressure BackpressureReporter
+	featureGates *featuregate.Gates
+	timeZones    func() timezone.Database
+	daemons      DaemonStatusReporter
+	mu           sync.Mutex
+}
+
+// healthResponse is the health of the cluster, along with the saturation of
+// the queues of the backend, its enabled feature gates, its time zone
+// database and the statuses of its daemons.
+type healthResponse struct {
+	*corev2.HealthResponse
+	Backpressure     *backpressure.Report  `json:"Backpressure,omitempty"`
+	FeatureGates     []featuregate.Feature `json:"FeatureGates,omitempty"`
+	TimeZoneDatabase *timezone.Database    `json:"TimeZoneDatabase,omitempty"`
+	Daemons          []daemon.Status       `json:"Daemons,omitempty"`
 }
 
 // NewHealthRouter instantiates new router for controlling health info
@@ -60,8 +89,63 @@ func (r *HealthRouter) health(w http.ResponseWriter, req *http.Request) {
 	}
 	r.mu.Lock()
 	clusterHealth := r.controller.GetClusterHealth(ctx)
+	reporter := r.backpressure
+	gates := r.featureGates
+	timeZones := r.timeZones
+	daemons := r.daemons
+	r.mu.Unlock()
+	if reporter == nil && gates == nil && timeZones == nil && daemons == nil {
+		_ = json.NewEncoder(w).Encode(clusterHealth)
+		return
+	}
+	response := healthResponse{HealthResponse: clusterHealth}
+	if reporter != nil {
+		report := reporter.Report()
+		response.Backpressure = &report
+	}
+	if gates != nil {
+		response.FeatureGates = gates.EnabledFeatures()
+	}
+	if timeZones != nil {
+		db := timeZones()
+		response.TimeZoneDatabase = &db
+	}
+	if daemons != nil {
+		response.Daemons = daemons.List()
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// SetBackpressure sets the reporter of the saturation of the queues of the
+// backend included in health responses.
+func (r *HealthRouter) SetBackpressure(reporter BackpressureReporter) {
+	r.mu.Lock()
+	r.backpressure = reporter
+	r.mu.Unlock()
+}
+
+// SetFeatureGates sets the feature gates whose enabled features are included
+// in health responses.
+func (r *HealthRouter) SetFeatureGates(gates *featuregate.Gates) {
+	r.mu.Lock()
+	r.featureGates = gates
+	r.mu.Unlock()
+}
+
+// SetTimeZoneDatabase sets the lookup of the time zone database included in
+// health responses.
+func (r *HealthRouter) SetTimeZoneDatabase(lookup func() timezone.Database) {
+	r.mu.Lock()
+	r.timeZones = lookup
+	r.mu.Unlock()
+}
+
+// SetDaemonStatuses sets the reporter of the statuses of the daemons of the
+// backend included in health responses.
+func (r *HealthRouter) SetDaemonStatuses(reporter DaemonStatusReporter) {
+	r.mu.Lock()
+	r.daemons = reporter
 	r.mu.Unlock()
-	_ = json.NewEncoder(w).Encode(clusterHealth)
 }
 
 // Swap swaps the health controller of the health router.
@@ -2,13 +2,20 @@ package routers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/gorilla/mux"
 	v2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/backpressure"
+	"github.com/sensu/sensu-go/backend/daemon"
+	"github.com/sensu/sensu-go/backend/featuregate"
+	"github.com/sensu/sensu-go/backend/timezone"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -88,3 +95,163 @@ func TestUnHealthyClusterStatus(t *testing.T) {
 	}
 
 }
+
+type testBackpressureReporter struct{}
+
+func (testBackpressureReporter) Report() backpressure.Report {
+	return backpressure.Report{
+		Score:  0.5,
+		Queues: []backpressure.QueueReport{{Name: "pipelined", Depth: 50, Capacity: 100, Saturation: 0.5}},
+	}
+}
+
+func TestHealthWithoutBackpressure(t *testing.T) {
+	controller, server := newHealthTest(t)
+	defer server.Close()
+	controller.On("GetClusterHealth", mock.Anything).Return(v2.FixtureHealthResponse(true))
+
+	client := new(http.Client)
+	req := newRequest(t, http.MethodGet, server.URL+"/health", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := response["Backpressure"]; ok {
+		t.Fatal("expected no backpressure report without a reporter")
+	}
+}
+
+func TestHealthBackpressureReport(t *testing.T) {
+	controller := &mockHealthController{}
+	healthRouter := NewHealthRouter(controller)
+	healthRouter.SetBackpressure(testBackpressureReporter{})
+	router := mux.NewRouter()
+	healthRouter.Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	controller.On("GetClusterHealth", mock.Anything).Return(v2.FixtureHealthResponse(true))
+
+	client := new(http.Client)
+	req := newRequest(t, http.MethodGet, server.URL+"/health", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		ClusterHealth []*v2.ClusterHealth
+		Backpressure  backpressure.Report
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.ClusterHealth) == 0 {
+		t.Error("expected the cluster health in the response")
+	}
+	if got, want := response.Backpressure, (testBackpressureReporter{}).Report(); !reflect.DeepEqual(got, want) {
+		t.Errorf("bad backpressure report: got %v, want %v", got, want)
+	}
+}
+
+func TestHealthFeatureGates(t *testing.T) {
+	controller := &mockHealthController{}
+	healthRouter := NewHealthRouter(controller)
+	gates := featuregate.NewDefault()
+	if err := gates.Set(string(featuregate.EventPriority) + "=true"); err != nil {
+		t.Fatal(err)
+	}
+	healthRouter.SetFeatureGates(gates)
+	router := mux.NewRouter()
+	healthRouter.Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	controller.On("GetClusterHealth", mock.Anything).Return(v2.FixtureHealthResponse(true))
+
+	client := new(http.Client)
+	req := newRequest(t, http.MethodGet, server.URL+"/health", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		ClusterHealth []*v2.ClusterHealth
+		Backpressure  *backpressure.Report
+		FeatureGates  []featuregate.Feature
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.ClusterHealth) == 0 {
+		t.Error("expected the cluster health in the response")
+	}
+	if response.Backpressure != nil {
+		t.Error("expected no backpressure report without a reporter")
+	}
+	if got, want := response.FeatureGates, []featuregate.Feature{featuregate.EventPriority}; !reflect.DeepEqual(got, want) {
+		t.Errorf("bad feature gates: got %v, want %v", got, want)
+	}
+}
+
+func TestHealthTimeZoneDatabase(t *testing.T) {
+	controller := &mockHealthController{}
+	healthRouter := NewHealthRouter(controller)
+	db := timezone.Database{Source: "/usr/share/zoneinfo/", Version: "2024a", Local: "UTC"}
+	healthRouter.SetTimeZoneDatabase(func() timezone.Database { return db })
+	router := mux.NewRouter()
+	healthRouter.Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	controller.On("GetClusterHealth", mock.Anything).Return(v2.FixtureHealthResponse(true))
+
+	client := new(http.Client)
+	req := newRequest(t, http.MethodGet, server.URL+"/health", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		TimeZoneDatabase *timezone.Database
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.TimeZoneDatabase == nil || *response.TimeZoneDatabase != db {
+		t.Errorf("bad time zone database: got %v, want %v", response.TimeZoneDatabase, db)
+	}
+}
+
+func TestHealthDaemons(t *testing.T) {
+	controller := &mockHealthController{}
+	healthRouter := NewHealthRouter(controller)
+	statuses := daemon.NewStatuses(nil)
+	statuses.Set("apid", daemon.StateRunning, nil)
+	statuses.Set("eventd", daemon.StateFailed, errors.New("store unavailable"))
+	healthRouter.SetDaemonStatuses(statuses)
+	router := mux.NewRouter()
+	healthRouter.Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	controller.On("GetClusterHealth", mock.Anything).Return(v2.FixtureHealthResponse(true))
+
+	client := new(http.Client)
+	req := newRequest(t, http.MethodGet, server.URL+"/health", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Daemons []daemon.Status
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Daemons) != 2 {
+		t.Fatalf("bad daemon statuses: %v", response.Daemons)
+	}
+	if got := response.Daemons[1]; got.Name != "eventd" || got.State != daemon.StateFailed || got.Error != "store unavailable" {
+		t.Errorf("bad eventd status: %v", got)
+	}
+}
@@ -0,0 +1,140 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/apid/request"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// JobsController submits background jobs and reports their progress.
+type JobsController interface {
+	SubmitJob(ctx context.Context, namespace, kind string, params map[string]string) (*store.Job, error)
+	GetJob(ctx context.Context, namespace, id string) (*store.Job, error)
+	ListJobs(ctx context.Context, namespace string, status store.JobStatus) ([]*store.Job, error)
+}
+
+// JobsRouter handles requests for /jobs, and the requests for operations that
+// are run as background jobs when the async query parameter is true.
+type JobsRouter struct {
+	controller JobsController
+}
+
+// NewJobsRouter instantiates a new router for jobs
+func NewJobsRouter(controller JobsController) *JobsRouter {
+	return &JobsRouter{
+		controller: controller,
+	}
+}
+
+// Mount the JobsRouter to a parent Router. It must be mounted before the
+// routers of the synchronous operations, whose routes match the same paths.
+func (r *JobsRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/namespaces/{namespace}/{resource:jobs}", r.list).Methods(http.MethodGet)
+	parent.HandleFunc("/namespaces/{namespace}/{resource:jobs}/{id}", r.get).Methods(http.MethodGet)
+
+	// The events of an entity, or that match a selector, can be deleted in
+	// the background
+	parent.HandleFunc("/namespaces/{namespace}/{resource:events}", r.deleteEvents).
+		Methods(http.MethodDelete).Queries("async", "true")
+	parent.HandleFunc("/namespaces/{namespace}/{resource:events}/{entity}", r.deleteEvents).
+		Methods(http.MethodDelete).Queries("async", "true")
+}
+
+func (r *JobsRouter) list(w http.ResponseWriter, req *http.Request) {
+	namespace, err := url.PathUnescape(mux.Vars(req)["namespace"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	status := store.JobStatus(req.URL.Query().Get("status"))
+	switch status {
+	case "", store.JobPending, store.JobRunning, store.JobSucceeded, store.JobFailed:
+	default:
+		WriteError(w, actions.NewErrorf(actions.InvalidArgument, "invalid job status: %s", status))
+		return
+	}
+	jobs, err := r.controller.ListJobs(req.Context(), namespace, status)
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(jobs)
+}
+
+func (r *JobsRouter) get(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	namespace, err := url.PathUnescape(vars["namespace"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	id, err := url.PathUnescape(vars["id"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	job, err := r.controller.GetJob(req.Context(), namespace, id)
+	if err != nil {
+		var notFound *store.ErrNotFound
+		if errors.As(err, &notFound) {
+			WriteError(w, actions.NewErrorf(actions.NotFound))
+			return
+		}
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(job)
+}
+
+func (r *JobsRouter) deleteEvents(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	namespace, err := url.PathUnescape(vars["namespace"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	params := map[string]string{}
+	if entity, ok := vars["entity"]; ok {
+		entity, err = url.PathUnescape(entity)
+		if err != nil {
+			WriteError(w, actions.NewError(actions.InvalidArgument, err))
+			return
+		}
+		params["entity"] = entity
+	} else {
+		// A selector is required, so that every event of a namespace is not
+		// deleted by mistake
+		if sel := request.SelectorFromContext(req.Context()); sel == nil || len(sel.Operations) == 0 {
+			WriteError(w, actions.NewErrorf(actions.InvalidArgument, "a label or field selector is required"))
+			return
+		}
+		query := req.URL.Query()
+		for _, key := range []string{"labelSelector", "fieldSelector"} {
+			if values := query[key]; len(values) > 0 {
+				params[key] = strings.Join(values, " && ")
+			}
+		}
+	}
+	r.submit(w, req, namespace, actions.DeleteEventsJob, params)
+}
+
+func (r *JobsRouter) submit(w http.ResponseWriter, req *http.Request, namespace, kind string, params map[string]string) {
+	job, err := r.controller.SubmitJob(req.Context(), namespace, kind, params)
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(job)
+}
@@ -0,0 +1,158 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/apid/middlewares"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+type testJobsController map[string]*store.Job
+
+func (c testJobsController) SubmitJob(ctx context.Context, namespace, kind string, params map[string]string) (*store.Job, error) {
+	job := &store.Job{ID: "job1", Namespace: namespace, Kind: kind, Params: params, Status: store.JobPending}
+	c[namespace+"/"+job.ID] = job
+	return job, nil
+}
+
+func (c testJobsController) GetJob(ctx context.Context, namespace, id string) (*store.Job, error) {
+	job, ok := c[namespace+"/"+id]
+	if !ok {
+		return nil, &store.ErrNotFound{Key: id}
+	}
+	return job, nil
+}
+
+func (c testJobsController) ListJobs(ctx context.Context, namespace string, status store.JobStatus) ([]*store.Job, error) {
+	jobs := []*store.Job{}
+	for _, job := range c {
+		if job.Namespace == namespace && (status == "" || job.Status == status) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func TestJobsRouter(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		query  url.Values
+		status int
+		params map[string]string
+	}{
+		{
+			name:   "events of an entity",
+			path:   "/namespaces/default/events/entity1",
+			query:  url.Values{"async": {"true"}},
+			status: http.StatusAccepted,
+			params: map[string]string{"entity": "entity1"},
+		},
+		{
+			name:   "events that match a selector",
+			path:   "/namespaces/default/events",
+			query:  url.Values{"async": {"true"}, "labelSelector": {"region == us"}},
+			status: http.StatusAccepted,
+			params: map[string]string{"labelSelector": "region == us"},
+		},
+		{
+			name:   "selector required",
+			path:   "/namespaces/default/events",
+			query:  url.Values{"async": {"true"}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "synchronous operations are not routed",
+			path:   "/namespaces/default/events/entity1",
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := testJobsController{}
+			router := mux.NewRouter().UseEncodedPath()
+			router.Use(middlewares.Selectors{}.Then)
+			NewJobsRouter(jobs).Mount(router)
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodDelete, server.URL+tt.path+"?"+tt.query.Encode(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if got, want := resp.StatusCode, tt.status; got != want {
+				t.Fatalf("bad status: got %d, want %d", got, want)
+			}
+			if tt.status != http.StatusAccepted {
+				return
+			}
+			var job store.Job
+			if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+				t.Fatal(err)
+			}
+			if job.Kind != actions.DeleteEventsJob || len(job.Params) != len(tt.params) {
+				t.Fatalf("bad job: %v", job)
+			}
+			for key, value := range tt.params {
+				if job.Params[key] != value {
+					t.Errorf("bad param %s: got %q, want %q", key, job.Params[key], value)
+				}
+			}
+
+			resp, err = http.Get(server.URL + "/namespaces/default/jobs/" + job.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("bad status: %d", resp.StatusCode)
+			}
+		})
+	}
+
+	jobs := testJobsController{
+		"default/job1": {ID: "job1", Namespace: "default", Status: store.JobRunning},
+		"default/job2": {ID: "job2", Namespace: "default", Status: store.JobFailed},
+		"ops/job3":     {ID: "job3", Namespace: "ops", Status: store.JobRunning},
+	}
+	router := mux.NewRouter()
+	NewJobsRouter(jobs).Mount(router)
+	for _, tt := range []struct {
+		path   string
+		status int
+		jobs   int
+	}{
+		{path: "/namespaces/default/jobs/missing", status: http.StatusNotFound},
+		{path: "/namespaces/default/jobs", status: http.StatusOK, jobs: 2},
+		{path: "/namespaces/default/jobs?status=running", status: http.StatusOK, jobs: 1},
+		{path: "/namespaces/default/jobs?status=unknown", status: http.StatusBadRequest},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Fatalf("%s: bad status: %d", tt.path, rec.Code)
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var got []*store.Job
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != tt.jobs {
+			t.Errorf("%s: got %d jobs, want %d", tt.path, len(got), tt.jobs)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/keepalived"
+)
+
+// KeepalivesController represents the controller needs of the
+// KeepalivesRouter
+type KeepalivesController interface {
+	Keepalives(ctx context.Context, namespace string) ([]keepalived.KeepaliveStatus, error)
+}
+
+// KeepalivesRouter handles requests for /keepalives. It reports the liveness
+// state of agents, so that keepalive alerts can be debugged.
+type KeepalivesRouter struct {
+	controller KeepalivesController
+}
+
+// NewKeepalivesRouter instantiates a new router for keepalives
+func NewKeepalivesRouter(ctrl KeepalivesController) *KeepalivesRouter {
+	return &KeepalivesRouter{
+		controller: ctrl,
+	}
+}
+
+// Mount the KeepalivesRouter to a parent Router
+func (r *KeepalivesRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/namespaces/{namespace}/{resource:keepalives}", r.list).Methods(http.MethodGet)
+}
+
+func (r *KeepalivesRouter) list(w http.ResponseWriter, req *http.Request) {
+	namespace, err := url.PathUnescape(mux.Vars(req)["namespace"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	statuses, err := r.controller.Keepalives(req.Context(), namespace)
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(statuses)
+}
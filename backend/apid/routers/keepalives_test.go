@@ -0,0 +1,72 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/keepalived"
+)
+
+type testKeepalivesController []keepalived.KeepaliveStatus
+
+func (c testKeepalivesController) Keepalives(ctx context.Context, namespace string) ([]keepalived.KeepaliveStatus, error) {
+	if namespace == "broken" {
+		return nil, errors.New("database is down")
+	}
+	var statuses []keepalived.KeepaliveStatus
+	for _, status := range c {
+		if status.Namespace == namespace {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+func TestKeepalivesRouter(t *testing.T) {
+	controller := testKeepalivesController{
+		{Namespace: "default", Entity: "entity1", State: keepalived.KeepaliveStateAlive},
+		{Namespace: "default", Entity: "entity2", State: keepalived.KeepaliveStateDead},
+		{Namespace: "other", Entity: "entity3", State: keepalived.KeepaliveStateAlive},
+	}
+	router := mux.NewRouter().UseEncodedPath()
+	NewKeepalivesRouter(controller).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		status int
+		want   int
+	}{
+		{path: "/namespaces/default/keepalives", status: http.StatusOK, want: 2},
+		{path: "/namespaces/other/keepalives", status: http.StatusOK, want: 1},
+		{path: "/namespaces/broken/keepalives", status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("bad status: got %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var statuses []keepalived.KeepaliveStatus
+			if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+				t.Fatal(err)
+			}
+			if len(statuses) != tt.want {
+				t.Errorf("got %d keepalives, want %d", len(statuses), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/bonsai"
+)
+
+// OutdatedAssetsController represents the controller needs of the
+// OutdatedAssetsRouter
+type OutdatedAssetsController interface {
+	List(ctx context.Context) ([]bonsai.OutdatedAsset, error)
+	Upgrade(ctx context.Context) ([]bonsai.OutdatedAsset, error)
+}
+
+// OutdatedAssetsRouter handles requests for /assets?outdated=true
+type OutdatedAssetsRouter struct {
+	controller OutdatedAssetsController
+}
+
+// NewOutdatedAssetsRouter instantiates a new router for outdated assets
+func NewOutdatedAssetsRouter(ctrl OutdatedAssetsController) *OutdatedAssetsRouter {
+	return &OutdatedAssetsRouter{
+		controller: ctrl,
+	}
+}
+
+// Mount the OutdatedAssetsRouter to a parent Router. It must be mounted
+// before the AssetsRouter, whose list routes match the same paths.
+func (r *OutdatedAssetsRouter) Mount(parent *mux.Router) {
+	for _, path := range []string{"/namespaces/{namespace}/{resource:assets}", "/{resource:assets}"} {
+		parent.HandleFunc(path, r.list).Methods(http.MethodGet).Queries("outdated", "true")
+		parent.HandleFunc(path, r.upgrade).Methods(http.MethodPut).Queries("outdated", "true")
+	}
+}
+
+func (r *OutdatedAssetsRouter) list(w http.ResponseWriter, req *http.Request) {
+	assets, err := r.controller.List(req.Context())
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(assets)
+}
+
+func (r *OutdatedAssetsRouter) upgrade(w http.ResponseWriter, req *http.Request) {
+	assets, err := r.controller.Upgrade(req.Context())
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(assets)
+}
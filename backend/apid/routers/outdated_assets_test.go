@@ -0,0 +1,81 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/bonsai"
+)
+
+type testOutdatedAssetsController struct {
+	assets   []bonsai.OutdatedAsset
+	err      error
+	upgraded bool
+}
+
+func (c *testOutdatedAssetsController) List(ctx context.Context) ([]bonsai.OutdatedAsset, error) {
+	return c.assets, c.err
+}
+
+func (c *testOutdatedAssetsController) Upgrade(ctx context.Context) ([]bonsai.OutdatedAsset, error) {
+	c.upgraded = true
+	return c.assets, c.err
+}
+
+func TestOutdatedAssetsRouter(t *testing.T) {
+	assets := []bonsai.OutdatedAsset{
+		{BonsaiName: "testasset", BonsaiNamespace: "sensu", AssetName: "foo", AssetNamespace: "default", CurrentVersion: "0.1.0", LatestVersion: "0.2.0"},
+	}
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		err      error
+		status   int
+		upgraded bool
+	}{
+		{name: "list", method: http.MethodGet, path: "/namespaces/default/assets?outdated=true", status: http.StatusOK},
+		{name: "list all namespaces", method: http.MethodGet, path: "/assets?outdated=true", status: http.StatusOK},
+		{name: "upgrade", method: http.MethodPut, path: "/namespaces/default/assets?outdated=true", status: http.StatusOK, upgraded: true},
+		{name: "bonsai error", method: http.MethodGet, path: "/namespaces/default/assets?outdated=true", err: actions.NewError(actions.Unavailable, errors.New("oh noes")), status: http.StatusServiceUnavailable},
+		{name: "not outdated", method: http.MethodGet, path: "/namespaces/default/assets", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &testOutdatedAssetsController{assets: assets, err: tt.err}
+			router := mux.NewRouter()
+			NewOutdatedAssetsRouter(controller).Mount(router)
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			resp, err := new(http.Client).Do(newRequest(t, tt.method, server.URL+tt.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("bad status: %d", resp.StatusCode)
+			}
+			if controller.upgraded != tt.upgraded {
+				t.Errorf("upgraded = %v, want %v", controller.upgraded, tt.upgraded)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got []bonsai.OutdatedAsset
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, assets) {
+				t.Errorf("got %v, want %v", got, assets)
+			}
+		})
+	}
+}
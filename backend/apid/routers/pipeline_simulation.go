@@ -0,0 +1,168 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/apid/request"
+	"github.com/sensu/sensu-go/backend/pipeline"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// PipelineSimulator represents the controller needs of the
+// PipelineSimulationRouter
+type PipelineSimulator interface {
+	SimulateFilter(ctx context.Context, name string, event *corev2.Event) (bool, error)
+	SimulateMutator(ctx context.Context, name string, event *corev2.Event) ([]byte, error)
+}
+
+// FilterTestResult is the result of the evaluation of a filter against a
+// sample event.
+type FilterTestResult struct {
+	// Filtered is true if the filter denied the event.
+	Filtered bool `json:"filtered"`
+
+	// Error is the error that occurred while evaluating the filter.
+	Error string `json:"error,omitempty"`
+}
+
+// MutatorTestResult is the result of the mutation of a sample event.
+type MutatorTestResult struct {
+	// Output is the mutated event.
+	Output string `json:"output"`
+
+	// Error is the error that occurred while mutating the event.
+	Error string `json:"error,omitempty"`
+}
+
+// PipelineSimulationRouter handles requests for /filters/{id}/test and
+// /mutators/{id}/test. It runs stored filters and mutators against sample
+// events, without running handlers, so that pipelines can be tested.
+type PipelineSimulationRouter struct {
+	simulator PipelineSimulator
+}
+
+// NewPipelineSimulationRouter instantiates a new router for the simulation
+// of filters and mutators
+func NewPipelineSimulationRouter(simulator PipelineSimulator) *PipelineSimulationRouter {
+	return &PipelineSimulationRouter{
+		simulator: simulator,
+	}
+}
+
+// Mount the PipelineSimulationRouter to a parent Router
+func (r *PipelineSimulationRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/namespaces/{namespace}/{resource:filters}/{id}/test", r.testFilter).Methods(http.MethodPost)
+	parent.HandleFunc("/namespaces/{namespace}/{resource:mutators}/{id}/test", r.testMutator).Methods(http.MethodPost)
+}
+
+func (r *PipelineSimulationRouter) testFilter(w http.ResponseWriter, req *http.Request) {
+	name, events, err := readSampleEvents(req)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	results := make([]FilterTestResult, 0, len(events))
+	for _, event := range events {
+		filtered, err := r.simulator.SimulateFilter(req.Context(), name, event)
+		if err != nil {
+			if isNotFound(err) {
+				WriteError(w, actions.NewErrorf(actions.NotFound))
+				return
+			}
+			results = append(results, FilterTestResult{Error: err.Error()})
+			continue
+		}
+		results = append(results, FilterTestResult{Filtered: filtered})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(results)
+}
+
+func (r *PipelineSimulationRouter) testMutator(w http.ResponseWriter, req *http.Request) {
+	name, events, err := readSampleEvents(req)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	results := make([]MutatorTestResult, 0, len(events))
+	for _, event := range events {
+		output, err := r.simulator.SimulateMutator(req.Context(), name, event)
+		if err != nil {
+			if isNotFound(err) {
+				WriteError(w, actions.NewErrorf(actions.NotFound))
+				return
+			}
+			var disabled *pipeline.ErrPipeMutatorTestsDisabled
+			if errors.As(err, &disabled) {
+				WriteError(w, actions.NewError(actions.PermissionDenied, err))
+				return
+			}
+			results = append(results, MutatorTestResult{Error: err.Error()})
+			continue
+		}
+		results = append(results, MutatorTestResult{Output: string(output)})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(results)
+}
+
+func isNotFound(err error) bool {
+	var notFound *store.ErrNotFound
+	return errors.As(err, &notFound)
+}
+
+// readSampleEvents reads the name of the resource to test, and the sample
+// events of the request. The events are placed in the namespace of the
+// request, and validated.
+func readSampleEvents(req *http.Request) (string, []*corev2.Event, error) {
+	vars := mux.Vars(req)
+	namespace, err := url.PathUnescape(vars["namespace"])
+	if err != nil {
+		return "", nil, actions.NewError(actions.InvalidArgument, err)
+	}
+	name, err := url.PathUnescape(vars["id"])
+	if err != nil {
+		return "", nil, actions.NewError(actions.InvalidArgument, err)
+	}
+	var events []*corev2.Event
+	if err := request.Decode(req, &events); err != nil {
+		return "", nil, actions.NewError(actions.InvalidArgument, err)
+	}
+	if len(events) == 0 {
+		return "", nil, actions.NewErrorf(actions.InvalidArgument, "no sample events")
+	}
+	for i, event := range events {
+		if event == nil || event.Entity == nil {
+			return "", nil, actions.NewErrorf(actions.InvalidArgument, "sample event %d has no entity", i)
+		}
+		for _, meta := range []*corev2.ObjectMeta{&event.ObjectMeta, &event.Entity.ObjectMeta, eventCheckMeta(event)} {
+			if meta == nil {
+				continue
+			}
+			if meta.Namespace == "" {
+				meta.Namespace = namespace
+			} else if meta.Namespace != namespace {
+				return "", nil, actions.NewErrorf(actions.InvalidArgument, "sample event %d is not in namespace %q", i, namespace)
+			}
+		}
+		if err := event.Validate(); err != nil {
+			return "", nil, actions.NewError(actions.InvalidArgument, fmt.Errorf("invalid sample event %d: %s", i, err))
+		}
+	}
+	return name, events, nil
+}
+
+func eventCheckMeta(event *corev2.Event) *corev2.ObjectMeta {
+	if event.Check == nil {
+		return nil
+	}
+	return &event.Check.ObjectMeta
+}
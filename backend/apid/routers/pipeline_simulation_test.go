@@ -0,0 +1,129 @@
+package routers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// testPipelineSimulator filters failing events with the "failing" filter, and
+// mutates events to their check output with the "output" mutator.
+type testPipelineSimulator struct{}
+
+func (testPipelineSimulator) SimulateFilter(ctx context.Context, name string, event *corev2.Event) (bool, error) {
+	switch name {
+	case "failing":
+		return event.Check.Status != 0, nil
+	case "broken":
+		return false, errors.New("ReferenceError: 'foo' is not defined")
+	}
+	return false, &store.ErrNotFound{Key: name}
+}
+
+func (testPipelineSimulator) SimulateMutator(ctx context.Context, name string, event *corev2.Event) ([]byte, error) {
+	if name != "output" {
+		return nil, &store.ErrNotFound{Key: name}
+	}
+	return []byte(event.Check.Output), nil
+}
+
+func TestPipelineSimulationRouter(t *testing.T) {
+	router := mux.NewRouter().UseEncodedPath()
+	NewPipelineSimulationRouter(testPipelineSimulator{}).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ok := corev2.FixtureEvent("entity1", "check1")
+	ok.Check.Output = "all good"
+	failing := corev2.FixtureEvent("entity1", "check1")
+	failing.Check.Status = 2
+	otherNamespace := corev2.FixtureEvent("entity1", "check1")
+	otherNamespace.Entity.Namespace = "other"
+
+	tests := []struct {
+		name   string
+		path   string
+		events []*corev2.Event
+		status int
+		want   string
+	}{
+		{
+			name:   "filter",
+			path:   "/namespaces/default/filters/failing/test",
+			events: []*corev2.Event{ok, failing},
+			status: http.StatusOK,
+			want:   `[{"filtered":false},{"filtered":true}]`,
+		},
+		{
+			name:   "filter error",
+			path:   "/namespaces/default/filters/broken/test",
+			events: []*corev2.Event{ok},
+			status: http.StatusOK,
+			want:   `[{"filtered":false,"error":"ReferenceError: 'foo' is not defined"}]`,
+		},
+		{
+			name:   "missing filter",
+			path:   "/namespaces/default/filters/missing/test",
+			events: []*corev2.Event{ok},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "mutator",
+			path:   "/namespaces/default/mutators/output/test",
+			events: []*corev2.Event{ok},
+			status: http.StatusOK,
+			want:   `[{"output":"all good"}]`,
+		},
+		{
+			name:   "missing mutator",
+			path:   "/namespaces/default/mutators/missing/test",
+			events: []*corev2.Event{ok},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "no events",
+			path:   "/namespaces/default/filters/failing/test",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "event of another namespace",
+			path:   "/namespaces/default/filters/failing/test",
+			events: []*corev2.Event{otherNamespace},
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.events)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.Post(server.URL+tt.path, "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("bad status: got %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got bytes.Buffer
+			if _, err := got.ReadFrom(resp.Body); err != nil {
+				t.Fatal(err)
+			}
+			if got := bytes.TrimSpace(got.Bytes()); string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
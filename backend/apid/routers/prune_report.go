@@ -0,0 +1,44 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+)
+
+// PruneReportController represents the controller needs of the
+// PruneReportRouter
+type PruneReportController interface {
+	Report(ctx context.Context) (actions.PruneReport, error)
+}
+
+// PruneReportRouter handles requests for /prune-report. It reports the
+// resources of a namespace that are not used by any other resource.
+type PruneReportRouter struct {
+	controller PruneReportController
+}
+
+// NewPruneReportRouter instantiates a new router for prune reports
+func NewPruneReportRouter(ctrl PruneReportController) *PruneReportRouter {
+	return &PruneReportRouter{
+		controller: ctrl,
+	}
+}
+
+// Mount the PruneReportRouter to a parent Router
+func (r *PruneReportRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/namespaces/{namespace}/{resource:prune-report}", r.get).Methods(http.MethodGet)
+}
+
+func (r *PruneReportRouter) get(w http.ResponseWriter, req *http.Request) {
+	report, err := r.controller.Report(req.Context())
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,68 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+)
+
+type testPruneReportController struct {
+	report actions.PruneReport
+	err    error
+}
+
+func (c testPruneReportController) Report(ctx context.Context) (actions.PruneReport, error) {
+	return c.report, c.err
+}
+
+func TestPruneReportRouter(t *testing.T) {
+	tests := []struct {
+		name       string
+		controller testPruneReportController
+		status     int
+	}{
+		{
+			name:       "report",
+			controller: testPruneReportController{report: actions.PruneReport{Handlers: []string{"unused"}}},
+			status:     http.StatusOK,
+		},
+		{
+			name:       "error",
+			controller: testPruneReportController{err: actions.NewError(actions.InternalErr, errors.New("database is down"))},
+			status:     http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mux.NewRouter().UseEncodedPath()
+			NewPruneReportRouter(tt.controller).Mount(router)
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			resp, err := http.Get(server.URL + "/namespaces/default/prune-report")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("bad status: got %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var report actions.PruneReport
+			if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Handlers) != 1 || report.Handlers[0] != "unused" {
+				t.Errorf("bad report: %v", report)
+			}
+		})
+	}
+}
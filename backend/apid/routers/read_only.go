@@ -0,0 +1,58 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+)
+
+// ReadOnlyController represents the controller needs of the ReadOnlyRouter
+type ReadOnlyController interface {
+	Get(ctx context.Context) (actions.ReadOnlyMode, error)
+	Set(ctx context.Context, mode actions.ReadOnlyMode) error
+}
+
+// ReadOnlyRouter handles requests for /read-only
+type ReadOnlyRouter struct {
+	controller ReadOnlyController
+}
+
+// NewReadOnlyRouter instantiates a new router for the read-only mode
+func NewReadOnlyRouter(ctrl ReadOnlyController) *ReadOnlyRouter {
+	return &ReadOnlyRouter{
+		controller: ctrl,
+	}
+}
+
+// Mount the ReadOnlyRouter to a parent Router
+func (r *ReadOnlyRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/{resource:read-only}", r.get).Methods(http.MethodGet)
+	parent.HandleFunc("/{resource:read-only}", r.set).Methods(http.MethodPut)
+}
+
+func (r *ReadOnlyRouter) get(w http.ResponseWriter, req *http.Request) {
+	mode, err := r.controller.Get(req.Context())
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(mode)
+}
+
+func (r *ReadOnlyRouter) set(w http.ResponseWriter, req *http.Request) {
+	var mode actions.ReadOnlyMode
+	if err := json.NewDecoder(req.Body).Decode(&mode); err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	if err := r.controller.Set(req.Context(), mode); err != nil {
+		WriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(mode)
+}
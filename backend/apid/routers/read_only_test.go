@@ -0,0 +1,73 @@
+package routers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+)
+
+type testReadOnlyController struct {
+	mode actions.ReadOnlyMode
+	err  error
+}
+
+func (c *testReadOnlyController) Get(ctx context.Context) (actions.ReadOnlyMode, error) {
+	return c.mode, c.err
+}
+
+func (c *testReadOnlyController) Set(ctx context.Context, mode actions.ReadOnlyMode) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.mode = mode
+	return nil
+}
+
+func TestReadOnlyRouter(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   []byte
+		err    error
+		status int
+	}{
+		{name: "get", method: http.MethodGet, status: http.StatusOK},
+		{name: "set", method: http.MethodPut, body: []byte(`{"enabled":true,"reason":"backup"}`), status: http.StatusOK},
+		{name: "invalid", method: http.MethodPut, body: []byte(`{`), status: http.StatusBadRequest},
+		{name: "not found", method: http.MethodPut, body: []byte(`{}`), err: actions.NewErrorf(actions.NotFound), status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &testReadOnlyController{err: tt.err}
+			router := mux.NewRouter()
+			NewReadOnlyRouter(controller).Mount(router)
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			resp, err := new(http.Client).Do(newRequest(t, tt.method, server.URL+"/read-only", bytes.NewReader(tt.body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("bad status: %d", resp.StatusCode)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got actions.ReadOnlyMode
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got != controller.mode {
+				t.Errorf("got %v, want %v", got, controller.mode)
+			}
+		})
+	}
+}
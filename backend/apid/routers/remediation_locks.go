@@ -0,0 +1,68 @@
+package routers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// RemediationLocksRouter handles requests for /remediation-locks
+type RemediationLocksRouter struct {
+	store store.RemediationLockStore
+}
+
+// NewRemediationLocksRouter instantiates a new router for remediation locks
+func NewRemediationLocksRouter(store store.RemediationLockStore) *RemediationLocksRouter {
+	return &RemediationLocksRouter{
+		store: store,
+	}
+}
+
+// Mount the RemediationLocksRouter to a parent Router
+func (r *RemediationLocksRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/namespaces/{namespace}/{resource:remediation-locks}", r.list).Methods(http.MethodGet)
+	parent.HandleFunc("/namespaces/{namespace}/{resource:remediation-locks}/{handler}/{entity}", r.release).Methods(http.MethodDelete)
+}
+
+func (r *RemediationLocksRouter) list(w http.ResponseWriter, req *http.Request) {
+	namespace, err := url.PathUnescape(mux.Vars(req)["namespace"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	locks, err := r.store.ListRemediationLocks(req.Context(), namespace)
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(locks)
+}
+
+func (r *RemediationLocksRouter) release(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	var params [3]string
+	for i, key := range []string{"namespace", "handler", "entity"} {
+		value, err := url.PathUnescape(vars[key])
+		if err != nil {
+			WriteError(w, actions.NewError(actions.InvalidArgument, err))
+			return
+		}
+		params[i] = value
+	}
+	if err := r.store.ReleaseRemediationLock(req.Context(), params[0], params[1], params[2]); err != nil {
+		var notFound *store.ErrNotFound
+		if errors.As(err, &notFound) {
+			WriteError(w, actions.NewErrorf(actions.NotFound))
+			return
+		}
+		WriteError(w, actions.NewError(actions.InternalErr, err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,92 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+type testRemediationLockStore map[string]*store.RemediationLock
+
+func (s testRemediationLockStore) AcquireRemediationLock(ctx context.Context, lock *store.RemediationLock) (bool, error) {
+	key := lock.Namespace + "/" + lock.Handler + "/" + lock.Entity
+	if _, ok := s[key]; ok {
+		return false, nil
+	}
+	s[key] = lock
+	return true, nil
+}
+
+func (s testRemediationLockStore) ListRemediationLocks(ctx context.Context, namespace string) ([]*store.RemediationLock, error) {
+	locks := []*store.RemediationLock{}
+	for _, lock := range s {
+		if lock.Namespace == namespace {
+			locks = append(locks, lock)
+		}
+	}
+	return locks, nil
+}
+
+func (s testRemediationLockStore) ReleaseRemediationLock(ctx context.Context, namespace, handler, entity string) error {
+	key := namespace + "/" + handler + "/" + entity
+	if _, ok := s[key]; !ok {
+		return &store.ErrNotFound{Key: key}
+	}
+	delete(s, key)
+	return nil
+}
+
+func TestRemediationLocksRouter(t *testing.T) {
+	locks := testRemediationLockStore{}
+	_, _ = locks.AcquireRemediationLock(context.Background(), &store.RemediationLock{
+		Namespace:  "default",
+		Handler:    "restart-service",
+		Entity:     "entity1",
+		Event:      "abc",
+		AcquiredAt: time.Now(),
+		ExpiresAt:  time.Now().Add(30 * time.Minute),
+	})
+	router := mux.NewRouter().UseEncodedPath()
+	NewRemediationLocksRouter(locks).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/namespaces/default/remediation-locks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*store.RemediationLock
+	err = json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Handler != "restart-service" {
+		t.Fatalf("bad locks: %v", got)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{path: "/namespaces/default/remediation-locks/restart-service/entity1", status: http.StatusNoContent},
+		{path: "/namespaces/default/remediation-locks/restart-service/entity1", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodDelete, server.URL+tt.path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("DELETE %s: got status %d, want %d", tt.path, resp.StatusCode, tt.status)
+		}
+	}
+}
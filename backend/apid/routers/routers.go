@@ -12,12 +12,15 @@ import (
 	"github.com/sensu/core/v3/types"
 	"github.com/sensu/sensu-go/backend/apid/actions"
 	"github.com/sensu/sensu-go/backend/apid/handlers"
+	"github.com/sensu/sensu-go/backend/apid/request"
 	"github.com/sensu/sensu-go/backend/store"
 )
 
 type errorBody struct {
-	Message string `json:"message"`
-	Code    uint32 `json:"code"`
+	Message   string `json:"message"`
+	Code      uint32 `json:"code"`
+	Reason    string `json:"reason,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // RespondWith given writer and resource, marshal to JSON and write response.
@@ -83,17 +86,25 @@ func RespondWith(w http.ResponseWriter, r *http.Request, response handlers.Handl
 func WriteError(w http.ResponseWriter, err error) {
 	const fallback = `{"message": "failed to marshal error message"}`
 
-	errBody := errorBody{}
-	st := http.StatusInternalServerError
-
-	// Wrap message in standard errorBody
-	actionErr, ok := err.(actions.Error)
-	if ok {
-		errBody.Message = actionErr.Message
-		errBody.Code = uint32(actionErr.Code)
-		st = HTTPStatusFromCode(actionErr.Code)
-	} else {
-		errBody.Message = err.Error()
+	// Wrap message in standard errorBody. Errors that are not action errors,
+	// like the store errors, are classified by their type.
+	actionErr := actions.FromError(err)
+	errBody := errorBody{
+		Message: actionErr.Message,
+		Code:    uint32(actionErr.Code),
+		Reason:  actionErr.Reason,
+	}
+	if errBody.Reason == "" {
+		errBody.Reason = actionErr.Code.String()
+	}
+	st := HTTPStatusFromCode(actionErr.Code)
+
+	// Correlate server errors, like store failures, with the request that
+	// caused them. The request ID is set in the response headers by the
+	// RequestID middleware.
+	if st >= http.StatusInternalServerError {
+		errBody.RequestID = w.Header().Get(request.RequestIDHeader)
+		logger.WithError(err).WithField("request_id", errBody.RequestID).Error("request failed")
 	}
 
 	// Prevent browser from doing mime-sniffing
@@ -140,6 +151,10 @@ func HTTPStatusFromCode(code actions.ErrCode) int {
 		return http.StatusGatewayTimeout
 	case actions.Gone:
 		return http.StatusGone
+	case actions.Unavailable:
+		return http.StatusServiceUnavailable
+	case actions.PayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
 	}
 
 	logger.WithField("code", code).Error("unknown error code")
@@ -3,6 +3,7 @@ package routers
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +15,7 @@ import (
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/backend/apid/actions"
 	"github.com/sensu/sensu-go/backend/apid/handlers"
+	"github.com/sensu/sensu-go/backend/apid/request"
 	"github.com/sensu/sensu-go/backend/store"
 )
 
@@ -79,19 +81,48 @@ func TestRespondWith(t *testing.T) {
 }
 
 func TestWriteError(t *testing.T) {
-	type args struct {
-		w   http.ResponseWriter
-		err error
-	}
 	tests := []struct {
-		name string
-		args args
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
 	}{
-		// TODO: Add test cases.
+		{
+			name:       "client error",
+			err:        actions.NewErrorf(actions.NotFound),
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"message":"not found","code":2,"reason":"not_found"}`,
+		},
+		{
+			name:       "server error",
+			err:        actions.NewError(actions.InternalErr, &store.ErrInternal{Message: "boom"}),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"message":"internal error: boom","code":0,"reason":"store_internal","request_id":"abc"}`,
+		},
+		{
+			name:       "store error",
+			err:        &store.ErrPreconditionFailed{Key: "foo"},
+			wantStatus: http.StatusPreconditionFailed,
+			wantBody:   `{"message":"at least one condition failed for the key foo","code":7,"reason":"precondition_failed"}`,
+		},
+		{
+			name:       "other error",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"message":"boom","code":0,"reason":"internal","request_id":"abc"}`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			WriteError(tt.args.w, tt.args.err)
+			w := httptest.NewRecorder()
+			w.Header().Set(request.RequestIDHeader, "abc")
+			WriteError(w, tt.err)
+			if got := w.Code; got != tt.wantStatus {
+				t.Errorf("WriteError() status = %d, want %d", got, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("WriteError() body = %s, want %s", got, tt.wantBody)
+			}
 		})
 	}
 }
@@ -0,0 +1,45 @@
+package routers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/backend/schedulerd"
+)
+
+// SchedulesController represents the controller needs of the SchedulesRouter
+type SchedulesController interface {
+	Schedules(namespace string) []schedulerd.ScheduleStatus
+}
+
+// SchedulesRouter handles requests for /schedules. It reports the state of
+// the check schedulers of the backend that serves the request.
+type SchedulesRouter struct {
+	controller SchedulesController
+}
+
+// NewSchedulesRouter instantiates a new router for check schedules
+func NewSchedulesRouter(ctrl SchedulesController) *SchedulesRouter {
+	return &SchedulesRouter{
+		controller: ctrl,
+	}
+}
+
+// Mount the SchedulesRouter to a parent Router
+func (r *SchedulesRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/namespaces/{namespace}/{resource:schedules}", r.list).Methods(http.MethodGet)
+	parent.HandleFunc("/{resource:schedules}", r.list).Methods(http.MethodGet)
+}
+
+func (r *SchedulesRouter) list(w http.ResponseWriter, req *http.Request) {
+	namespace, err := url.PathUnescape(mux.Vars(req)["namespace"])
+	if err != nil {
+		WriteError(w, actions.NewError(actions.InvalidArgument, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(r.controller.Schedules(namespace))
+}
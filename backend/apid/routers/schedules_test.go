@@ -0,0 +1,67 @@
+package routers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/schedulerd"
+)
+
+type testSchedulesController map[string][]schedulerd.ScheduleStatus
+
+func (c testSchedulesController) Schedules(namespace string) []schedulerd.ScheduleStatus {
+	return c[namespace]
+}
+
+func TestSchedulesRouter(t *testing.T) {
+	status := schedulerd.ScheduleStatus{
+		Check:         "check-cpu",
+		Namespace:     "default",
+		SchedulerType: "interval",
+		NextExecution: 1700000000,
+		LastResult:    schedulerd.ResultPublished,
+	}
+	controller := testSchedulesController{
+		"default": {status},
+		"":        {status, status},
+	}
+	router := mux.NewRouter()
+	NewSchedulesRouter(controller).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/namespaces/default/schedules", want: 1},
+		{path: "/namespaces/empty/schedules", want: 0},
+		{path: "/schedules", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("bad status: %d", resp.StatusCode)
+			}
+			var statuses []schedulerd.ScheduleStatus
+			if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+				t.Fatal(err)
+			}
+			if len(statuses) != tt.want {
+				t.Fatalf("got %d schedules, want %d", len(statuses), tt.want)
+			}
+			if tt.want > 0 && !reflect.DeepEqual(statuses[0], status) {
+				t.Errorf("got %v, want %v", statuses[0], status)
+			}
+		})
+	}
+}
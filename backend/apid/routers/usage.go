@@ -0,0 +1,38 @@
+package routers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/usage"
+)
+
+// UsageController represents the controller needs of the UsageRouter
+type UsageController interface {
+	List() []usage.Usage
+}
+
+// UsageRouter handles requests for /usage. It reports the API usage of every
+// user and API key since the backend started, so that the clients overloading
+// the cluster can be identified.
+type UsageRouter struct {
+	controller UsageController
+}
+
+// NewUsageRouter instantiates a new router for API usage
+func NewUsageRouter(ctrl UsageController) *UsageRouter {
+	return &UsageRouter{
+		controller: ctrl,
+	}
+}
+
+// Mount the UsageRouter to a parent Router
+func (r *UsageRouter) Mount(parent *mux.Router) {
+	parent.HandleFunc("/{resource:usage}", r.list).Methods(http.MethodGet)
+}
+
+func (r *UsageRouter) list(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(r.controller.List())
+}
@@ -0,0 +1,38 @@
+package routers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sensu/sensu-go/backend/apid/usage"
+)
+
+func TestUsageRouter(t *testing.T) {
+	tracker := usage.NewTracker()
+	tracker.Record(usage.Principal{User: "admin", APIKey: "ci-key"}, 2, 7)
+
+	router := mux.NewRouter()
+	NewUsageRouter(tracker).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := new(http.Client).Do(newRequest(t, http.MethodGet, server.URL+"/usage", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("bad status: %d", resp.StatusCode)
+	}
+	var got []usage.Usage
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if want := tracker.List(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
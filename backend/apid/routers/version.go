@@ -7,6 +7,7 @@ import (
 
 	"github.com/gorilla/mux"
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/featuregate"
 )
 
 // VersionController represents the controller needs of the VersionRouter
@@ -14,6 +15,22 @@ type VersionController interface {
 	GetVersion(ctx context.Context) *corev2.Version
 }
 
+// FeatureGateController is implemented by version controllers that report
+// the feature gates of the backend, and the cluster version that gates the
+// features that are not compatible with older backends.
+type FeatureGateController interface {
+	GetFeatureGates(ctx context.Context) []featuregate.Status
+	GetClusterVersion(ctx context.Context) string
+}
+
+// versionResponse is the version information of the backend, along with the
+// status of its feature gates.
+type versionResponse struct {
+	*corev2.Version
+	ClusterVersion string               `json:"cluster_version,omitempty"`
+	FeatureGates   []featuregate.Status `json:"feature_gates"`
+}
+
 // VersionRouter handles requests for /version
 type VersionRouter struct {
 	controller VersionController
@@ -31,7 +48,15 @@ func (r *VersionRouter) Mount(parent *mux.Router) {
 	parent.HandleFunc("/version", r.version).Methods(http.MethodGet)
 }
 
-func (r *VersionRouter) version(w http.ResponseWriter, _ *http.Request) {
-	version := r.controller.GetVersion(context.Background())
+func (r *VersionRouter) version(w http.ResponseWriter, req *http.Request) {
+	version := r.controller.GetVersion(req.Context())
+	if ctrl, ok := r.controller.(FeatureGateController); ok {
+		_ = json.NewEncoder(w).Encode(versionResponse{
+			Version:        version,
+			ClusterVersion: ctrl.GetClusterVersion(req.Context()),
+			FeatureGates:   ctrl.GetFeatureGates(req.Context()),
+		})
+		return
+	}
 	_ = json.NewEncoder(w).Encode(version)
 }
@@ -2,13 +2,16 @@ package routers
 
 import (
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/gorilla/mux"
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/featuregate"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -47,3 +50,52 @@ func TestVersion(t *testing.T) {
 		t.Fatalf("bad status: %d (%q)", resp.StatusCode, string(body))
 	}
 }
+
+type mockFeatureGateVersionController struct {
+	mockVersionController
+}
+
+func (m *mockFeatureGateVersionController) GetFeatureGates(ctx context.Context) []featuregate.Status {
+	args := m.Called(ctx)
+	return args.Get(0).([]featuregate.Status)
+}
+
+func (m *mockFeatureGateVersionController) GetClusterVersion(ctx context.Context) string {
+	return m.Called(ctx).String(0)
+}
+
+func TestVersionFeatureGates(t *testing.T) {
+	controller := &mockFeatureGateVersionController{}
+	router := mux.NewRouter()
+	NewVersionRouter(controller).Mount(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	gates := []featuregate.Status{
+		{Name: "NewBus", Enabled: true, Stage: featuregate.Alpha},
+	}
+	controller.On("GetVersion", mock.Anything).Return(corev2.FixtureVersion())
+	controller.On("GetFeatureGates", mock.Anything).Return(gates)
+	controller.On("GetClusterVersion", mock.Anything).Return("7.0.2")
+
+	req := newRequest(t, http.MethodGet, server.URL+"/version", nil)
+	resp, err := new(http.Client).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body versionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := body.SensuBackend, corev2.FixtureVersion().SensuBackend; got != want {
+		t.Errorf("bad sensu_backend: got %q, want %q", got, want)
+	}
+	if got, want := body.ClusterVersion, "7.0.2"; got != want {
+		t.Errorf("bad cluster_version: got %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(body.FeatureGates, gates) {
+		t.Errorf("bad feature_gates: got %v, want %v", body.FeatureGates, gates)
+	}
+}
@@ -0,0 +1,128 @@
+// Package usage tracks the API usage of authenticated principals, so that the
+// users and API keys putting the most load on the cluster can be identified.
+package usage
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/backend/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RequestsCounterName is the name of the prometheus counter of API
+	// requests per principal.
+	RequestsCounterName = "sensu_go_api_principal_requests_total"
+
+	// BytesCounterName is the name of the prometheus counter of API bytes
+	// received and sent per principal.
+	BytesCounterName = "sensu_go_api_principal_bytes_total"
+
+	// DirectionReceived labels the bytes of the request bodies.
+	DirectionReceived = "received"
+
+	// DirectionSent labels the bytes of the response bodies.
+	DirectionSent = "sent"
+)
+
+var (
+	logger = logrus.WithFields(logrus.Fields{
+		"component": "apid",
+	})
+
+	requestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: RequestsCounterName,
+			Help: "The total number of API requests per user and API key",
+		},
+		[]string{"user", "api_key"},
+	)
+
+	bytesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: BytesCounterName,
+			Help: "The total number of API bytes received and sent per user and API key",
+		},
+		[]string{"user", "api_key", "direction"},
+	)
+)
+
+func init() {
+	if err := prometheus.Register(requestsCounter); err != nil {
+		metrics.LogError(logger, RequestsCounterName, err)
+	}
+	if err := prometheus.Register(bytesCounter); err != nil {
+		metrics.LogError(logger, BytesCounterName, err)
+	}
+}
+
+// Principal identifies an authenticated API client. APIKey is the name of the
+// API key used to authenticate, if any.
+type Principal struct {
+	User   string `json:"user"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// Usage is the API usage of a principal since the backend started.
+type Usage struct {
+	Principal
+	Requests      int64 `json:"requests"`
+	BytesReceived int64 `json:"bytes_received"`
+	BytesSent     int64 `json:"bytes_sent"`
+}
+
+// Tracker tracks the API usage of principals. It is safe for concurrent use.
+type Tracker struct {
+	mu    sync.Mutex
+	usage map[Principal]*Usage
+}
+
+// NewTracker creates a new Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		usage: make(map[Principal]*Usage),
+	}
+}
+
+// Record records a request of the principal, with the size of its request
+// and response bodies.
+func (t *Tracker) Record(p Principal, received, sent int64) {
+	requestsCounter.WithLabelValues(p.User, p.APIKey).Inc()
+	bytesCounter.WithLabelValues(p.User, p.APIKey, DirectionReceived).Add(float64(received))
+	bytesCounter.WithLabelValues(p.User, p.APIKey, DirectionSent).Add(float64(sent))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u, ok := t.usage[p]
+	if !ok {
+		u = &Usage{Principal: p}
+		t.usage[p] = u
+	}
+	u.Requests++
+	u.BytesReceived += received
+	u.BytesSent += sent
+}
+
+// List returns the usage of every principal, the principals with the most
+// requests first.
+func (t *Tracker) List() []Usage {
+	t.mu.Lock()
+	result := make([]Usage, 0, len(t.usage))
+	for _, u := range t.usage {
+		result = append(result, *u)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Requests != result[j].Requests {
+			return result[i].Requests > result[j].Requests
+		}
+		if result[i].User != result[j].User {
+			return result[i].User < result[j].User
+		}
+		return result[i].APIKey < result[j].APIKey
+	})
+	return result
+}
@@ -0,0 +1,24 @@
+package usage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	admin := Principal{User: "admin"}
+	ci := Principal{User: "admin", APIKey: "ci-key"}
+
+	tracker.Record(admin, 10, 100)
+	tracker.Record(ci, 0, 50)
+	tracker.Record(ci, 20, 50)
+
+	want := []Usage{
+		{Principal: ci, Requests: 2, BytesReceived: 20, BytesSent: 100},
+		{Principal: admin, Requests: 1, BytesReceived: 10, BytesSent: 100},
+	}
+	if got := tracker.List(); !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+}
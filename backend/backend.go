@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sensu/sensu-go/backend/queue/jetstream"
 	"github.com/sensu/sensu-go/backend/resource"
 	"github.com/spf13/viper"
 	"golang.org/x/time/rate"
@@ -22,31 +24,46 @@ import (
 	"github.com/sensu/sensu-go/backend/apid"
 	"github.com/sensu/sensu-go/backend/apid/actions"
 	"github.com/sensu/sensu-go/backend/apid/graphql"
+	"github.com/sensu/sensu-go/backend/apid/middlewares"
 	"github.com/sensu/sensu-go/backend/apid/routers"
 	"github.com/sensu/sensu-go/backend/authentication"
 	"github.com/sensu/sensu-go/backend/authentication/jwt"
 	"github.com/sensu/sensu-go/backend/authentication/providers/basic"
 	"github.com/sensu/sensu-go/backend/authorization/rbac"
+	"github.com/sensu/sensu-go/backend/backpressure"
+	"github.com/sensu/sensu-go/backend/bridge"
+	"github.com/sensu/sensu-go/backend/certmonitor"
 	"github.com/sensu/sensu-go/backend/daemon"
 	"github.com/sensu/sensu-go/backend/eventd"
+	"github.com/sensu/sensu-go/backend/jobs"
+	"github.com/sensu/sensu-go/backend/kafkabus"
 	"github.com/sensu/sensu-go/backend/keepalived"
 	"github.com/sensu/sensu-go/backend/licensing"
 	"github.com/sensu/sensu-go/backend/logging"
+	"github.com/sensu/sensu-go/backend/membership"
 	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/mirror"
 	"github.com/sensu/sensu-go/backend/pipeline"
 	"github.com/sensu/sensu-go/backend/pipeline/filter"
 	"github.com/sensu/sensu-go/backend/pipeline/handler"
 	"github.com/sensu/sensu-go/backend/pipeline/mutator"
 	"github.com/sensu/sensu-go/backend/pipelined"
+	"github.com/sensu/sensu-go/backend/retention"
 	"github.com/sensu/sensu-go/backend/ringv2"
 	"github.com/sensu/sensu-go/backend/schedulerd"
 	"github.com/sensu/sensu-go/backend/secrets"
+	"github.com/sensu/sensu-go/backend/selfmonitor"
+	"github.com/sensu/sensu-go/backend/store"
 	"github.com/sensu/sensu-go/backend/store/postgres"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 	"github.com/sensu/sensu-go/backend/tessend"
+	"github.com/sensu/sensu-go/backend/timezone"
+	"github.com/sensu/sensu-go/backend/upgrade"
+	"github.com/sensu/sensu-go/bonsai"
 	"github.com/sensu/sensu-go/command"
 	"github.com/sensu/sensu-go/metrics"
 	"github.com/sensu/sensu-go/system"
+	"github.com/sensu/sensu-go/util/retry"
 )
 
 var pgWrapper = postgres.NewResourceWrapper(storev2.WrapResource)
@@ -136,6 +153,8 @@ type Backend struct {
 	PipelineAdapterV1      pipeline.AdapterV1
 	LicenseGetter          licensing.Getter
 	Bus                    messaging.MessageBus
+	Backpressure           *backpressure.Monitor
+	DaemonStatuses         *daemon.Statuses
 
 	Cfg *Config
 }
@@ -155,6 +174,77 @@ func errorReporter(event pq.ListenerEventType, err error) {
 	}
 }
 
+// postgresClientCertFile returns the client certificate file set by the
+// sslcert parameter of the postgres DSN, in the URL or key/value format, if
+// any.
+func postgresClientCertFile(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		converted, err := pq.ParseURL(dsn)
+		if err != nil {
+			return ""
+		}
+		dsn = converted
+	}
+	for _, field := range strings.Fields(dsn) {
+		if value := strings.TrimPrefix(field, "sslcert="); value != field {
+			return strings.Trim(value, "'")
+		}
+	}
+	return ""
+}
+
+// newJetStreamBus returns the bus bridged with the JetStream streams of the
+// NATS server of the bus-nats-url flag. The work messages are shared by the
+// pipeline backends, and every backend receives the broadcast messages with
+// its own consumer, which keeps the messages it missed while it was down.
+func newJetStreamBus(wizardBus *messaging.WizardBus, config *Config) (*bridge.Bus, error) {
+	url := viper.GetString(FlagBusNATSURL)
+	if url == "" {
+		return nil, fmt.Errorf("%s is required by the %s bus driver", FlagBusNATSURL, BusDriverJetStream)
+	}
+	maxAge := viper.GetDuration(FlagBusNATSMaxAge)
+	work, err := jetstream.New(jetstream.Config{
+		URL:       url,
+		Name:      config.Name,
+		Stream:    jetstream.WorkStream,
+		Consumer:  "pipeline",
+		WorkQueue: true,
+		MaxAge:    maxAge,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error initializing the %s bus: %s", BusDriverJetStream, err)
+	}
+	// The consumers of the broadcast stream skip the check requests and
+	// notifications missed while the backend was stopped, and are deleted
+	// once their backend is gone for longer than the messages are kept
+	inactiveThreshold := maxAge
+	if inactiveThreshold <= 0 {
+		inactiveThreshold = jetstream.DefaultMaxAge
+	}
+	broadcast, err := jetstream.New(jetstream.Config{
+		URL:               url,
+		Name:              config.Name,
+		Stream:            jetstream.BroadcastStream,
+		Consumer:          config.Name,
+		MaxAge:            maxAge,
+		StaleAfter:        jetstream.DefaultStaleAfter,
+		InactiveThreshold: inactiveThreshold,
+	})
+	if err != nil {
+		_ = work.Close()
+		return nil, fmt.Errorf("error initializing the %s bus: %s", BusDriverJetStream, err)
+	}
+	logger.WithField("url", url).Info("bridging the bus with NATS JetStream")
+	return bridge.New(bridge.Config{
+		Bus:         wizardBus,
+		Work:        work,
+		Broadcast:   broadcast,
+		BackendName: config.Name,
+		Pipeline:    config.HasRole(RolePipeline),
+		Durable:     true,
+	}), nil
+}
+
 // Initialize instantiates a Backend struct with the provided config, which creates
 // a list of daemons. The daemons will be started according to their position in the
 // b.Daemons list, and stopped in reverse order
@@ -164,9 +254,46 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 	b := &Backend{Cfg: config}
 
 	// Initialize the bus
-	bus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+	wizardBus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
 	if err != nil {
-		return nil, fmt.Errorf("error initializing %s: %s", bus.Name(), err)
+		return nil, fmt.Errorf("error initializing %s: %s", wizardBus.Name(), err)
+	}
+	var bus messaging.MessageBus = wizardBus
+
+	switch driver := viper.GetString(FlagBusDriver); driver {
+	case "", BusDriverWizard:
+		// Bridge the bus with the backends that run the other roles
+		if config.SplitRoles() {
+			logger.WithField("roles", config.Roles).Info("running a subset of the backend roles")
+			bus = bridge.New(bridge.Config{
+				Bus:         wizardBus,
+				Work:        postgres.NewQueue(pgdb),
+				Broadcast:   queue.NewClusteredQueue(postgres.NewQueue(pgdb), config.Name, postgres.NewOPC(pgdb)),
+				BackendName: config.Name,
+				Pipeline:    config.HasRole(RolePipeline),
+			})
+		}
+	case BusDriverJetStream:
+		// Bridge the bus with the JetStream streams, whatever the roles, so
+		// that its messages survive the restarts of the backend
+		bus, err = newJetStreamBus(wizardBus, config)
+		if err != nil {
+			return nil, err
+		}
+	case BusDriverKafka:
+		brokers := viper.GetStringSlice(FlagBusKafkaBrokers)
+		bus, err = kafkabus.New(kafkabus.Config{
+			Bus:         wizardBus,
+			Brokers:     brokers,
+			TopicPrefix: viper.GetString(FlagBusKafkaTopicPrefix),
+			BackendName: config.Name,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error initializing the %s bus: %s", BusDriverKafka, err)
+		}
+		logger.WithField("brokers", brokers).Info("writing the messages of the bus to Kafka")
+	default:
+		return nil, fmt.Errorf("unknown %s %q, drivers are %s, %s and %s", FlagBusDriver, driver, BusDriverWizard, BusDriverJetStream, BusDriverKafka)
 	}
 	b.Bus = bus
 	b.Daemons = append(b.Daemons, bus)
@@ -180,13 +307,21 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		DisableEventCache: config.Store.PostgresStore.DisableEventCache,
 	})
 
+	// Migrate the database, and run the steps that need the store, once the
+	// database is reachable
+	storeDaemon := newStoreStartup(ctx, config.Store.PostgresStore.DSN)
+	b.Daemons = append(b.Daemons, storeDaemon)
+
 	jwtClient := api.JWT{Store: b.Store}
-	jwtSecret, err := jwtClient.GetSecret(ctx)
-	if err != nil {
-		return nil, err
-	}
-	// TODO: don't use global variables
-	jwt.SetSecret(jwtSecret)
+	storeDaemon.AddStep(func(ctx context.Context) error {
+		jwtSecret, err := jwtClient.GetSecret(ctx)
+		if err != nil {
+			return err
+		}
+		// TODO: don't use global variables
+		jwt.SetSecret(jwtSecret)
+		return nil
+	})
 
 	// Initialize the LicenseGetter
 	b.LicenseGetter = config.LicenseGetter
@@ -213,13 +348,22 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 
 	// Create sensu-system namespace and backend entity
 	br := resource.New(b.Store.GetNamespaceStore(), b.Store.GetEntityConfigStore(), b.Store.GetEntityStateStore(), bus)
-	if err := br.EnsureBackendResources(ctx); err != nil {
-		return nil, fmt.Errorf("error creating system namespace and backend entity: %s", err.Error())
-	}
+	storeDaemon.AddStep(func(ctx context.Context) error {
+		if err := br.EnsureBackendResources(ctx); err != nil {
+			return fmt.Errorf("error creating system namespace and backend entity: %s", err.Error())
+		}
+		return nil
+	})
 
 	// Initialize the secrets provider manager
 	b.SecretsProviderManager = secrets.NewProviderManager(br)
 
+	// Enable the extensions
+	exts, err := b.loadExtensions(config)
+	if err != nil {
+		return nil, fmt.Errorf("error loading extensions: %s", err)
+	}
+
 	auth := &rbac.Authorizer{Store: b.Store}
 
 	// Initialize pipelined
@@ -227,22 +371,48 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		Bus:         bus,
 		BufferSize:  viper.GetInt(FlagPipelinedBufferSize),
 		WorkerCount: viper.GetInt(FlagPipelinedWorkers),
+
+		NamespaceWorkers: viper.GetInt(FlagPipelinedNamespaceWorkers),
+		NamespaceBudget:  viper.GetDuration(FlagPipelinedNamespaceBudget),
+
+		FeatureGates: config.FeatureGates,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing %s: %s", pipelineDaemon.Name(), err)
 	}
 
+	// Traced events record their steps through eventd and pipelined
+	traceStore := postgres.NewEventTraceStore(pgdb)
+
+	// Remediation locks are shared by pipelined and apid
+	remediationLocks := postgres.NewRemediationLockStore(pgdb)
+
+	// Idempotency keys are shared by the apid of every backend
+	idempotencyKeys := postgres.NewIdempotencyKeyStore(pgdb)
+
+	// Pipelined and schedulerd read checks, assets, handlers and filters for
+	// every event and check execution, through a read cache of the store
+	var pipelineStore storev2.Interface = b.Store
+	if config.HasRole(RolePipeline) {
+		pipelineStore = storev2.NewCachedStore(ctx, b.Store, storev2.HotResources...)
+	}
+
 	// Initialize PipelineAdapterV1
 	storeTimeout := 2 * time.Minute
 	b.PipelineAdapterV1 = pipeline.AdapterV1{
-		Store:        b.Store,
-		StoreTimeout: storeTimeout,
+		Store:                pipelineStore,
+		StoreTimeout:         storeTimeout,
+		TraceStore:           traceStore,
+		NotificationCounters: postgres.NewNotificationCounterStore(pgdb),
+		RemediationLocks:     remediationLocks,
+
+		AllowPipeMutatorTests: viper.GetBool(FlagAllowPipeMutatorTests),
 	}
 
 	// Initialize PipelineAdapterV1 filter adapters
 	legacyFilterAdapter := &filter.LegacyAdapter{
 		AssetGetter:  assetGetter,
-		Store:        b.Store,
+		Store:        pipelineStore,
 		StoreTimeout: storeTimeout,
 	}
 	hasMetricsFilterAdapter := &filter.HasMetricsAdapter{}
@@ -261,7 +431,7 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		AssetGetter:            assetGetter,
 		Executor:               command.NewExecutor(),
 		SecretsProviderManager: b.SecretsProviderManager,
-		Store:                  b.Store,
+		Store:                  pipelineStore,
 		StoreTimeout:           storeTimeout,
 	}
 	onlyCheckOutputMutatorAdapter := &mutator.OnlyCheckOutputAdapter{}
@@ -279,7 +449,7 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		Executor:               command.NewExecutor(),
 		LicenseGetter:          b.LicenseGetter,
 		SecretsProviderManager: b.SecretsProviderManager,
-		Store:                  b.Store,
+		Store:                  pipelineStore,
 		StoreTimeout:           storeTimeout,
 	}
 
@@ -288,17 +458,36 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 	}
 
 	pipelineDaemon.AddAdapter(&b.PipelineAdapterV1)
-	b.Daemons = append(b.Daemons, pipelineDaemon)
+	if config.HasRole(RolePipeline) {
+		b.Daemons = append(b.Daemons, pipelineDaemon)
+	}
 
 	pgOPC := postgres.NewOPC(pgdb)
 
 	go CheckInLoop(ctx, b.Cfg.Name, pgOPC)
 
+	// Compute the cluster version, so that features that are not compatible
+	// with older backends are only enabled once every backend is upgraded
+	go upgrade.New(upgrade.Config{
+		Queryer:      pgOPC,
+		FeatureGates: config.FeatureGates,
+	}).Run(ctx)
+
+	// Agentd, eventd and keepalived retry the store operations that fail
+	// with a transient error, rather than dropping events or agents
+	resilientStore := storev2.NewResilientStore(b.Store, storev2.ResilienceConfig{
+		MaxRetries:       viper.GetInt(FlagStoreMaxRetries),
+		RetryDelay:       storev2.DefaultStoreRetryDelay,
+		MaxRetryDelay:    storev2.DefaultStoreMaxRetryDelay,
+		BreakerThreshold: viper.GetInt(FlagStoreBreakerThreshold),
+		BreakerCooldown:  viper.GetDuration(FlagStoreBreakerCooldown),
+	})
+
 	// Initialize eventd
 	event, err := eventd.New(
 		ctx,
 		eventd.Config{
-			Store:               b.Store,
+			Store:               resilientStore,
 			Bus:                 bus,
 			BufferSize:          viper.GetInt(FlagEventdBufferSize),
 			WorkerCount:         viper.GetInt(FlagEventdWorkers),
@@ -311,30 +500,100 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 			OperatorMonitor:     pgOPC,
 			OperatorQueryer:     pgOPC,
 			BackendName:         b.Cfg.Name,
+			FeatureGates:        config.FeatureGates,
+			TraceStore:          traceStore,
+			MaxAnnotationsSize:  viper.GetInt(FlagEventdMaxAnnotationsSize),
+			MaxLabelsSize:       viper.GetInt(FlagEventdMaxLabelsSize),
 		},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing %s: %s", event.Name(), err)
 	}
-	b.Daemons = append(b.Daemons, event)
+	if config.HasRole(RolePipeline) {
+		b.Daemons = append(b.Daemons, event)
+	}
 
 	// Initialize work queue
 	pgQueue := postgres.NewQueue(pgdb)
 	workQueue := queue.NewClusteredQueue(pgQueue, b.Cfg.Name, pgOPC)
 
+	// Initialize the job runner. Jobs are submitted by apid and run by the
+	// backends of the pipeline role, one of which supervises them.
+	jobRunner := jobs.NewRunner(jobs.Config{
+		Store:       postgres.NewJobStore(pgdb),
+		Queue:       pgQueue,
+		Executor:    &postgres.SynchronizedExecutor{DB: pgdb, CheckinInterval: 10 * time.Second},
+		Workers:     viper.GetInt(FlagJobsWorkers),
+		MaxAttempts: viper.GetInt(FlagJobsMaxAttempts),
+	})
+	jobRunner.Register(actions.DeleteEventsJob, actions.NewEventController(b.Store, bus).DeleteEvents)
+	jobRunner.Register(actions.ReplayHandlerJob, actions.NewHandlerReplayController(b.Store, &b.PipelineAdapterV1).ReplayJob)
+	if config.HasRole(RolePipeline) {
+		b.Daemons = append(b.Daemons, jobRunner)
+	}
+
+	// Initialize retentiond, which scrubs, anonymizes and deletes the events
+	// according to the retention policies of their namespaces.
+	if replacer, ok := b.Store.GetEventStore().(store.EventReplacer); ok && config.HasRole(RolePipeline) {
+		b.Daemons = append(b.Daemons, retention.New(retention.Config{
+			Store:    b.Store,
+			Events:   b.Store.GetEventStore(),
+			Replacer: replacer,
+			Executor: &postgres.SynchronizedExecutor{DB: pgdb, CheckinInterval: 10 * time.Second},
+			Interval: viper.GetDuration(FlagRetentionInterval),
+		}))
+	}
+
+	// Initialize mirrord, which mirrors the processed events to the peer
+	// cluster of an active/passive setup
+	if url := viper.GetString(FlagMirrorURL); url != "" && config.HasRole(RolePipeline) {
+		labels, err := mirror.ParseLabels(viper.GetStringSlice(FlagMirrorLabels))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", FlagMirrorLabels, err)
+		}
+		tlsOptions := corev2.TLSOptions{
+			TrustedCAFile:      viper.GetString(FlagMirrorTrustedCAFile),
+			InsecureSkipVerify: viper.GetBool(FlagMirrorInsecureSkipTLSVerify),
+		}
+		tlsConfig, err := tlsOptions.ToClientTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS configuration of the mirror: %s", err)
+		}
+		mirrord, err := mirror.New(mirror.Config{
+			Bus:           bus,
+			URL:           url,
+			APIKey:        viper.GetString(FlagMirrorAPIKey),
+			TLS:           tlsConfig,
+			BackendName:   b.Cfg.Name,
+			Namespaces:    viper.GetStringSlice(FlagMirrorNamespaces),
+			Labels:        labels,
+			BatchSize:     viper.GetInt(FlagMirrorBatchSize),
+			FlushInterval: viper.GetDuration(FlagMirrorFlushInterval),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error initializing mirrord: %s", err)
+		}
+		b.Daemons = append(b.Daemons, mirrord)
+	}
+
 	// Initialize schedulerd
 	scheduler, err := schedulerd.New(
 		ctx,
 		schedulerd.Config{
-			Store:                  b.Store,
+			Store:                  pipelineStore,
 			Bus:                    bus,
 			SecretsProviderManager: b.SecretsProviderManager,
 			Queue:                  workQueue,
+			PublishRate:            viper.GetFloat64(FlagSchedulerPublishRate),
+			DispatchWindow:         viper.GetDuration(FlagSchedulerDispatchWindow),
+			PrioritySubscriptions:  viper.GetStringSlice(FlagSchedulerPrioritySubscriptions),
 		})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing %s: %s", scheduler.Name(), err)
 	}
-	b.Daemons = append(b.Daemons, scheduler)
+	if config.HasRole(RolePipeline) {
+		b.Daemons = append(b.Daemons, scheduler)
+	}
 
 	// Use the common TLS flags for agentd if wasn't explicitely configured with
 	// its own TLS configuration
@@ -343,24 +602,32 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 	}
 
 	// Start the entity config watcher, so agentd sessions are notified of updates
-	entityConfigWatcher := agentd.GetEntityConfigWatcher(ctx, b.Store)
+	var entityConfigWatcher <-chan []storev2.WatchEvent
+	if config.HasRole(RoleAgentListener) {
+		entityConfigWatcher = agentd.GetEntityConfigWatcher(ctx, b.Store)
+	}
 
 	// Initialize keepalived
 	keepalive, err := keepalived.New(keepalived.Config{
 		DeregistrationHandler: config.DeregistrationHandler,
+		EntityStateHandler:    config.EntityStateHandler,
 		Bus:                   bus,
-		Store:                 b.Store,
+		Store:                 resilientStore,
 		BufferSize:            viper.GetInt(FlagKeepalivedBufferSize),
 		WorkerCount:           viper.GetInt(FlagKeepalivedWorkers),
 		StoreTimeout:          2 * time.Minute,
 		OperatorConcierge:     pgOPC,
 		OperatorMonitor:       pgOPC,
+		OperatorQueryer:       pgOPC,
 		BackendName:           b.Cfg.Name,
+		FeatureGates:          config.FeatureGates,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing %s: %s", keepalive.Name(), err)
 	}
-	b.Daemons = append(b.Daemons, keepalive)
+	if config.HasRole(RolePipeline) {
+		b.Daemons = append(b.Daemons, keepalive)
+	}
 
 	// Prepare the authentication providers
 	authenticator := &authentication.Authenticator{}
@@ -369,6 +636,9 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		Store:      b.Store,
 	}
 	authenticator.AddProvider(provider)
+	for _, provider := range exts.AuthProviders {
+		authenticator.AddProvider(provider)
+	}
 
 	var clusterVersion string
 
@@ -379,6 +649,8 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 
 	// Initialize the health router
 	b.HealthRouter = routers.NewHealthRouter(actions.HealthController{})
+	b.HealthRouter.SetFeatureGates(config.FeatureGates)
+	b.HealthRouter.SetTimeZoneDatabase(timezone.Lookup)
 
 	// Initialize GraphQL service
 	b.GraphQLService, err = graphql.NewService(graphql.ServiceConfig{
@@ -395,7 +667,7 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		HookClient:        api.NewHookConfigClient(b.Store, auth),
 		UserClient:        api.NewUserClient(b.Store, auth),
 		RBACClient:        api.NewRBACClient(b.Store, auth),
-		VersionController: actions.NewVersionController(clusterVersion),
+		VersionController: actions.NewVersionController(clusterVersion, config.FeatureGates),
 		MetricGatherer:    prometheus.DefaultGatherer,
 		GenericClient:     &api.GenericClient{Store: b.Store, Auth: auth},
 	})
@@ -403,25 +675,73 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		return nil, fmt.Errorf("error initializing graphql.Service: %s", err)
 	}
 
+	// The agent events that agentd could not publish wait in the dead letter
+	// queue, which is managed through apid
+	deadLetters := agentd.NewDeadLetterQueue(bus, config.AgentDeadLetterSize, config.AgentDeadLetterInterval)
+	configAudit := agentd.NewConfigAuditLog(postgres.NewConfigAuditStore(pgdb), config.AgentConfigAuditSize)
+
 	// Initialize apid
 	b.APIDConfig = apid.Config{
-		ListenAddress:  config.APIListenAddress,
-		RequestLimit:   config.APIRequestLimit,
-		WriteTimeout:   config.APIWriteTimeout,
-		URL:            config.APIURL,
-		Bus:            bus,
-		Store:          b.Store,
-		TLS:            config.TLS,
-		Authenticator:  authenticator,
-		ClusterVersion: clusterVersion,
-		GraphQLService: b.GraphQLService,
-		Queue:          workQueue,
+		ListenAddress:        config.APIListenAddress,
+		RequestLimit:         config.APIRequestLimit,
+		EventsRequestLimit:   config.APIEventsRequestLimit,
+		BulkRequestLimit:     config.APIBulkRequestLimit,
+		WriteTimeout:         config.APIWriteTimeout,
+		ListCacheTTL:         config.APIListCacheTTL,
+		URL:                  config.APIURL,
+		Bus:                  bus,
+		Store:                b.Store,
+		TLS:                  config.TLS,
+		Authenticator:        authenticator,
+		ClusterVersion:       clusterVersion,
+		FeatureGates:         config.FeatureGates,
+		GraphQLService:       b.GraphQLService,
+		Queue:                workQueue,
+		Membership:           membership.NewRegistry(pgOPC),
+		Schedules:            scheduler,
+		EventTraces:          traceStore,
+		Remediation:          remediationLocks,
+		IdempotencyKeys:      idempotencyKeys,
+		IdempotencyWindow:    config.APIIdempotencyWindow,
+		Jobs:                 jobRunner,
+		Sessions:             agentd.SessionVersions{},
+		Keepalives:           keepalive,
+		AgentSessions:        agentd.Sessions{DrainWindow: config.AgentDrainWindow, DeadLetterQueue: deadLetters, ConfigAuditLog: configAudit},
+		BusTopics:            wizardBus,
+		Pipeline:             &b.PipelineAdapterV1,
+		Replayer:             &b.PipelineAdapterV1,
+		Bonsai:               bonsai.New(bonsai.Config{}),
+		IdleTimeout:          config.APIIdleTimeout,
+		MaxConcurrentStreams: config.APIMaxConcurrentStreams,
+		ShutdownTimeout:      config.APIShutdownTimeout,
+		ReadOnly:             middlewares.NewReadOnly(b.Store),
+		CORS: middlewares.CORS{
+			AllowedOrigins:   config.APICORSAllowedOrigins,
+			AllowedMethods:   config.APICORSAllowedMethods,
+			AllowedHeaders:   config.APICORSAllowedHeaders,
+			AllowCredentials: config.APICORSAllowCredentials,
+		},
+		Deadline: middlewares.Deadline{
+			Read:  config.APIReadDeadline,
+			List:  config.APIListDeadline,
+			Write: config.APIWriteDeadline,
+		},
+	}
+	if !config.HasRole(RolePipeline) {
+		// The check schedulers only run in the backends of the pipeline role
+		b.APIDConfig.Schedules = nil
+	}
+	if exts.Federation != nil && config.HasRole(RoleAPI) {
+		b.APIDConfig.ExtensionRouters = exts.Federation.Routers()
+		b.Daemons = append(b.Daemons, exts.Federation)
 	}
 	newApi, err := apid.New(b.APIDConfig)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing %s: %s", newApi.Name(), err)
 	}
-	b.Daemons = append(b.Daemons, newApi)
+	if config.HasRole(RoleAPI) {
+		b.Daemons = append(b.Daemons, newApi)
+	}
 
 	// Initialize tessend
 	pgDSN := b.Cfg.Store.PostgresStore.DSN
@@ -436,11 +756,6 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 		return ring
 	})
 
-	var clusterID string
-	if clusterID, err = GetClusterID(ctx, b.Store); err != nil {
-		return nil, err
-	}
-
 	tessen, err := tessend.New(
 		ctx,
 		tessend.Config{
@@ -448,34 +763,198 @@ func Initialize(ctx context.Context, pgdb postgres.DBI, config *Config) (*Backen
 			EventStore: b.Store.GetEventStore(),
 			RingPool:   ringPool,
 			Bus:        bus,
-			ClusterID:  clusterID,
 			OPCQueryer: pgOPC,
 		})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing %s: %s", tessen.Name(), err)
 	}
-	b.Daemons = append(b.Daemons, tessen)
+	storeDaemon.AddStep(func(ctx context.Context) error {
+		clusterID, err := GetClusterID(ctx, b.Store)
+		if err != nil {
+			return err
+		}
+		tessen.SetClusterID(clusterID)
+		return nil
+	})
+	if config.HasRole(RolePipeline) {
+		b.Daemons = append(b.Daemons, tessen)
+	}
+
+	// Monitor the saturation of the internal queues
+	b.Backpressure = backpressure.NewMonitor()
+	b.Backpressure.Register("bus", wizardBus)
+	if config.HasRole(RolePipeline) {
+		b.Backpressure.Register("eventd", event)
+		b.Backpressure.Register("keepalived", keepalive)
+		b.Backpressure.Register("pipelined", pipelineDaemon)
+	}
+	if config.HasRole(RoleAgentListener) {
+		b.Backpressure.Register("agentd", backpressure.QueueFunc(agentd.SessionQueueDepth))
+	}
+	_ = prometheus.Register(b.Backpressure)
+	b.HealthRouter.SetBackpressure(b.Backpressure)
+
+	// Initialize certmonitord, which reports the TLS certificates of the
+	// backend and of its agents before they expire
+	var certMonitor *certmonitor.Monitor
+	if warning := viper.GetDuration(FlagCertExpiryWarningThreshold); warning > 0 {
+		var certificates []certmonitor.Certificate
+		if config.HasRole(RoleAgentListener) && config.AgentTLSOptions != nil && config.AgentTLSOptions.CertFile != "" {
+			certificates = append(certificates, certmonitor.Certificate{Name: "agentd", File: config.AgentTLSOptions.CertFile})
+		}
+		if config.HasRole(RoleAPI) && config.TLS != nil && config.TLS.CertFile != "" {
+			certificates = append(certificates, certmonitor.Certificate{Name: "apid", File: config.TLS.CertFile})
+		}
+		if config.DashboardTLSCertFile != "" {
+			certificates = append(certificates, certmonitor.Certificate{Name: "dashboard", File: config.DashboardTLSCertFile})
+		}
+		if certFile := postgresClientCertFile(config.Store.PostgresStore.DSN); certFile != "" {
+			certificates = append(certificates, certmonitor.Certificate{Name: "postgres-client", File: certFile})
+		}
+		certMonitor, err = certmonitor.New(certmonitor.Config{
+			EventReceiver:     br,
+			Certificates:      certificates,
+			WarningThreshold:  warning,
+			CriticalThreshold: viper.GetDuration(FlagCertExpiryCriticalThreshold),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error initializing certmonitord: %s", err)
+		}
+		b.Daemons = append(b.Daemons, certMonitor)
+	}
+
+	// Initialize selfmonitord, which reports the internal errors of the
+	// backend with the events of the backend entity
+	var selfMonitor *selfmonitor.Monitor
+	if threshold := viper.GetInt(FlagSelfMonitoringThreshold); threshold > 0 {
+		selfMonitor, err = selfmonitor.New(selfmonitor.Config{
+			EventReceiver: br,
+			Threshold:     threshold,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error initializing selfmonitord: %s", err)
+		}
+		b.Daemons = append(b.Daemons, selfMonitor)
+	}
 
 	// Initialize agentd
 	agent, err := agentd.New(agentd.Config{
 		Host:          config.AgentHost,
 		Port:          config.AgentPort,
 		Bus:           bus,
-		Store:         b.Store,
+		Store:         resilientStore,
 		TLS:           config.AgentTLSOptions,
 		WriteTimeout:  config.AgentWriteTimeout,
 		Watcher:       entityConfigWatcher,
 		HealthRouter:  b.HealthRouter,
 		Authenticator: authenticator,
+
+		EntityConfigRate: config.AgentEntityConfigRate,
+		EventRateLimits: agentd.EventRateLimits{
+			Namespace: config.AgentEventRateNamespace,
+			Agent:     config.AgentEventRateAgent,
+			Backoff:   config.AgentEventRateBackoff,
+		},
+		DrainWindow: config.AgentDrainWindow,
+		EventThrottle: agentd.EventThrottle{
+			Saturation: config.AgentThrottleSaturation,
+			Rate:       config.AgentThrottleRate,
+			Monitor:    b.Backpressure,
+		},
+		CheckChannelSize:  config.AgentCheckChannelSize,
+		DropCheckRequests: config.AgentCheckChannelDrop,
+
+		ClockSkewThreshold: config.AgentClockSkewThreshold,
+		Compression:        config.AgentCompression,
+		MaxEventSize:       config.AgentMaxEventSize,
+		PingInterval:       config.AgentPingInterval,
+		MinProtocolVersion: config.AgentMinProtocolVersion,
+		DeadLetterQueue:    deadLetters,
+		ConfigAuditLog:     configAudit,
+		ProxyProtocol:      config.AgentProxyProtocol,
+		AdmissionWebhook:   agentd.NewAdmissionWebhook(config.AgentAdmissionURL, config.AgentAdmissionTimeout, config.AgentAdmissionFailOpen),
+		SessionLimits: agentd.SessionLimits{
+			Backend:   config.AgentSessionLimitBackend,
+			Namespace: config.AgentSessionLimitNamespace,
+		},
+		TLSReloadInterval: config.AgentTLSReloadInterval,
+		GRPCPort:          config.AgentGRPCPort,
+		CertMonitor:       certMonitor,
+		SelfMonitor:       selfMonitor,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing %s: %s", agent.Name(), err)
 	}
-	b.Daemons = append(b.Daemons, agent)
+	if config.HasRole(RoleAgentListener) {
+		b.Daemons = append(b.Daemons, agent)
+	}
+
+	b.DaemonStatuses = daemon.NewStatuses(startupOrder(b.Daemons))
+	b.HealthRouter.SetDaemonStatuses(b.DaemonStatuses)
 
 	return b, nil
 }
 
+// startupOrder returns daemons in the order they are started: apid first, so
+// that the API is available, in degraded read-only mode, while the store
+// daemon waits for the database, then the store daemon, then the other
+// daemons in the order they depend on each other.
+func startupOrder(daemons []daemon.Daemon) []daemon.Daemon {
+	ordered := make([]daemon.Daemon, 0, len(daemons))
+	for _, name := range []string{"apid", "store"} {
+		for _, d := range daemons {
+			if d.Name() == name {
+				ordered = append(ordered, d)
+			}
+		}
+	}
+	for _, d := range daemons {
+		if d.Name() != "apid" && d.Name() != "store" {
+			ordered = append(ordered, d)
+		}
+	}
+	return ordered
+}
+
+// startDaemon starts d. If d is restartable, its start is retried with an
+// exponential backoff until the startup retry timeout is reached, so that
+// transient store outages don't fail the whole backend.
+func (b *Backend) startDaemon(ctx context.Context, d daemon.Daemon) error {
+	b.DaemonStatuses.Set(d.Name(), daemon.StateStarting, nil)
+	if r, ok := d.(daemon.Restartable); !ok || !r.Restartable() || b.Cfg.StartupRetryTimeout <= 0 {
+		if err := d.Start(); err != nil {
+			b.DaemonStatuses.Set(d.Name(), daemon.StateFailed, err)
+			return err
+		}
+		b.DaemonStatuses.Set(d.Name(), daemon.StateRunning, nil)
+		return nil
+	}
+	backoff := retry.ExponentialBackoff{
+		Ctx:                  ctx,
+		InitialDelayInterval: time.Second,
+		MaxDelayInterval:     30 * time.Second,
+		MaxElapsedTime:       b.Cfg.StartupRetryTimeout,
+		Multiplier:           2,
+	}
+	var startErr error
+	err := backoff.Retry(func(int) (bool, error) {
+		if startErr = d.Start(); startErr != nil {
+			logger.WithError(startErr).Errorf("error starting daemon %s, retrying...", d.Name())
+			b.DaemonStatuses.Set(d.Name(), daemon.StateFailed, startErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		if startErr != nil {
+			return startErr
+		}
+		return err
+	}
+	b.DaemonStatuses.Set(d.Name(), daemon.StateRunning, nil)
+	return nil
+}
+
 // Run starts all of the Backend server's daemons
 func (b *Backend) Run(ctx context.Context) error {
 	var derr error
@@ -489,10 +968,12 @@ func (b *Backend) Run(ctx context.Context) error {
 	// crash the stopgroup after a hard-coded timeout
 	sg := &stopGroup{crashOnTimeout: true, waitTime: 30 * time.Second}
 
-	// Loop across the daemons in order to start them, then add them to our groups
-	for _, d := range b.Daemons {
+	// Loop across the daemons in order to start them, then add them to our
+	// groups. The API rejects the changes until all of them are running.
+	for _, d := range startupOrder(b.Daemons) {
 		logger.Infof("starting daemon: %s", d.Name())
-		if err := d.Start(); err != nil {
+		b.APIDConfig.ReadOnly.SetDegraded(fmt.Sprintf("the backend is starting %s", d.Name()))
+		if err := b.startDaemon(ctx, d); err != nil {
 			_ = sg.Stop()
 			return ErrStartup{Err: err, Name: d.Name()}
 		}
@@ -537,6 +1018,8 @@ func (b *Backend) Run(ctx context.Context) error {
 		}
 	}
 
+	b.APIDConfig.ReadOnly.SetDegraded("")
+
 	errCtx, errCancel := context.WithCancel(ctx)
 	defer errCancel()
 	eg.Go(errCtx)
@@ -554,6 +1037,9 @@ func (b *Backend) Run(ctx context.Context) error {
 			derr = err
 		}
 	}
+	for _, d := range b.Daemons {
+		b.DaemonStatuses.Set(d.Name(), daemon.StateStopped, nil)
+	}
 	if derr == nil && ctx.Err() != context.Canceled {
 		derr = ctx.Err()
 	}
@@ -48,3 +48,18 @@ func TestStopGroupNormalStop(t *testing.T) {
 		t.Fatal("expected non-nil error")
 	}
 }
+
+func TestPostgresClientCertFile(t *testing.T) {
+	tests := map[string]string{
+		"host=localhost user=sensu sslmode=verify-full sslcert=/etc/sensu/pg.crt":  "/etc/sensu/pg.crt",
+		"postgres://sensu@localhost/sensu?sslmode=verify-full&sslcert=/etc/pg.crt": "/etc/pg.crt",
+		"postgresql://sensu@localhost/sensu?sslcert=%2Fetc%2Fsensu%2Fclient.pem":   "/etc/sensu/client.pem",
+		"host=localhost user=sensu sslmode=disable":                                "",
+		"postgres://sensu@localhost/sensu?sslmode=disable":                         "",
+	}
+	for dsn, want := range tests {
+		if got := postgresClientCertFile(dsn); got != want {
+			t.Errorf("postgresClientCertFile(%q) = %q, want %q", dsn, got, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package backpressure collects the depths of the internal queues of the
+// backend into a saturation score, which signals how close the backend is to
+// falling behind its workload.
+package backpressure
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	queueDepthName      = "sensu_go_queue_depth"
+	queueSaturationName = "sensu_go_queue_saturation"
+	saturationScoreName = "sensu_go_saturation_score"
+)
+
+var (
+	queueDepthDesc = prometheus.NewDesc(
+		queueDepthName,
+		"Number of messages waiting in an internal queue of the backend",
+		[]string{"queue"}, nil,
+	)
+
+	queueSaturationDesc = prometheus.NewDesc(
+		queueSaturationName,
+		"Ratio of the capacity of an internal queue of the backend in use",
+		[]string{"queue"}, nil,
+	)
+
+	saturationScoreDesc = prometheus.NewDesc(
+		saturationScoreName,
+		"Saturation of the most saturated internal queue of the backend",
+		nil, nil,
+	)
+)
+
+// Queue is an internal queue of the backend.
+type Queue interface {
+	// QueueDepth returns the number of messages in the queue and its
+	// capacity.
+	QueueDepth() (depth, capacity int)
+}
+
+// QueueFunc adapts a function to the Queue interface.
+type QueueFunc func() (depth, capacity int)
+
+// QueueDepth calls f.
+func (f QueueFunc) QueueDepth() (int, int) {
+	return f()
+}
+
+// QueueReport is the depth and saturation of a queue.
+type QueueReport struct {
+	Name       string  `json:"name"`
+	Depth      int     `json:"depth"`
+	Capacity   int     `json:"capacity"`
+	Saturation float64 `json:"saturation"`
+}
+
+// Report is the saturation of the queues of the backend. The score is the
+// saturation of the most saturated queue, between 0 and 1.
+type Report struct {
+	Score  float64       `json:"score"`
+	Queues []QueueReport `json:"queues"`
+}
+
+// Monitor monitors the queues of the backend. It is a prometheus collector
+// of the depth and saturation of the queues.
+type Monitor struct {
+	mu     sync.Mutex
+	queues map[string]Queue
+}
+
+// NewMonitor returns a monitor without queues.
+func NewMonitor() *Monitor {
+	return &Monitor{queues: make(map[string]Queue)}
+}
+
+// Register adds queue to the monitored queues, under name.
+func (m *Monitor) Register(name string, queue Queue) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queues[name] = queue
+}
+
+// Report returns the current saturation of the monitored queues.
+func (m *Monitor) Report() Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	report := Report{Queues: make([]QueueReport, 0, len(m.queues))}
+	for name, queue := range m.queues {
+		depth, capacity := queue.QueueDepth()
+		queueReport := QueueReport{Name: name, Depth: depth, Capacity: capacity}
+		if capacity > 0 {
+			queueReport.Saturation = float64(depth) / float64(capacity)
+		}
+		if queueReport.Saturation > report.Score {
+			report.Score = queueReport.Saturation
+		}
+		report.Queues = append(report.Queues, queueReport)
+	}
+	sort.Slice(report.Queues, func(i, j int) bool {
+		return report.Queues[i].Name < report.Queues[j].Name
+	})
+	return report
+}
+
+// Score returns the saturation of the most saturated queue, between 0 and 1.
+func (m *Monitor) Score() float64 {
+	return m.Report().Score
+}
+
+// Describe implements prometheus.Collector.
+func (m *Monitor) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+	ch <- queueSaturationDesc
+	ch <- saturationScoreDesc
+}
+
+// Collect implements prometheus.Collector.
+func (m *Monitor) Collect(ch chan<- prometheus.Metric) {
+	report := m.Report()
+	for _, queue := range report.Queues {
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(queue.Depth), queue.Name)
+		ch <- prometheus.MustNewConstMetric(queueSaturationDesc, prometheus.GaugeValue, queue.Saturation, queue.Name)
+	}
+	ch <- prometheus.MustNewConstMetric(saturationScoreDesc, prometheus.GaugeValue, report.Score)
+}
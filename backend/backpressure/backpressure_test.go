@@ -0,0 +1,43 @@
+package backpressure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitorReport(t *testing.T) {
+	m := NewMonitor()
+	assert.Equal(t, Report{Queues: []QueueReport{}}, m.Report())
+
+	m.Register("pipelined", QueueFunc(func() (int, int) { return 50, 100 }))
+	m.Register("eventd", QueueFunc(func() (int, int) { return 10, 100 }))
+	m.Register("unbuffered", QueueFunc(func() (int, int) { return 0, 0 }))
+
+	report := m.Report()
+	assert.Equal(t, 0.5, report.Score)
+	assert.Equal(t, []QueueReport{
+		{Name: "eventd", Depth: 10, Capacity: 100, Saturation: 0.1},
+		{Name: "pipelined", Depth: 50, Capacity: 100, Saturation: 0.5},
+		{Name: "unbuffered"},
+	}, report.Queues)
+	assert.Equal(t, 0.5, m.Score())
+}
+
+func TestMonitorCollect(t *testing.T) {
+	m := NewMonitor()
+	m.Register("eventd", QueueFunc(func() (int, int) { return 25, 100 }))
+
+	expected := `
+# HELP sensu_go_queue_depth Number of messages waiting in an internal queue of the backend
+# TYPE sensu_go_queue_depth gauge
+sensu_go_queue_depth{queue="eventd"} 25
+# HELP sensu_go_saturation_score Saturation of the most saturated internal queue of the backend
+# TYPE sensu_go_saturation_score gauge
+sensu_go_saturation_score 0.25
+`
+	err := testutil.CollectAndCompare(m, strings.NewReader(expected), queueDepthName, saturationScoreName)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,310 @@
+// Package bridge connects the message buses of backend processes that run
+// different roles, so that the messages published by the daemons of a
+// process reach the daemons of other processes.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// WorkQueue is the queue of the messages handled by a single process of
+	// the pipeline role, such as the events of agents.
+	WorkQueue = "bridge/work"
+
+	// BroadcastQueue is the queue of the messages delivered to every
+	// process, such as the check requests of agents.
+	BroadcastQueue = "bridge/broadcast"
+
+	// workReservers is the number of goroutines that reserve messages from
+	// the work queue.
+	workReservers = 4
+
+	// reserveRetryInterval is how long to wait before reserving messages
+	// again after an error.
+	reserveRetryInterval = time.Second
+
+	kindEvent             = "event"
+	kindCheckRequest      = "check_request"
+	kindAgentNotification = "agent_notification"
+	kindNone              = "none"
+)
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "bridge",
+})
+
+// envelope is a message bridged between processes.
+type envelope struct {
+	Origin  string          `json:"origin"`
+	Topic   string          `json:"topic"`
+	Kind    string          `json:"kind"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Config configures a Bus.
+type Config struct {
+	// Bus is the message bus of the process.
+	Bus messaging.MessageBus
+
+	// Work is the queue of the messages handled by a single process.
+	Work queue.Client
+
+	// Broadcast is the queue of the messages delivered to every process. It
+	// must deliver every message to every process, like a ClusteredQueue.
+	Broadcast queue.Client
+
+	// BackendName is the name of the backend process.
+	BackendName string
+
+	// Pipeline is true if the process runs the pipeline role, and so handles
+	// the messages of the work queue.
+	Pipeline bool
+
+	// Durable is true if the queues keep their messages across the restarts
+	// of the processes, like the queues of the jetstream package. The
+	// messages of the work topics are then always enqueued, even by the
+	// processes of the pipeline role, so that they survive the restart of
+	// the process that published them and are shared by the processes of
+	// the pipeline role.
+	Durable bool
+}
+
+// Bus is a message bus that bridges the messages of the topics consumed by
+// the daemons of other roles through the queues, and publishes the messages
+// of the queues on the bus of the process.
+type Bus struct {
+	messaging.MessageBus
+
+	work        queue.Client
+	broadcast   queue.Client
+	backendName string
+	pipeline    bool
+	durable     bool
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// New returns a Bus that bridges the messages of c.Bus.
+func New(c Config) *Bus {
+	return &Bus{
+		MessageBus:  c.Bus,
+		work:        c.Work,
+		broadcast:   c.Broadcast,
+		backendName: c.BackendName,
+		pipeline:    c.Pipeline,
+		durable:     c.Durable,
+	}
+}
+
+// Start starts the bus of the process, and the reservation of the messages of
+// the queues.
+func (b *Bus) Start() error {
+	if err := b.MessageBus.Start(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	if b.pipeline {
+		for i := 0; i < workReservers; i++ {
+			b.wg.Add(1)
+			go b.receive(ctx, b.work, WorkQueue)
+		}
+	}
+	b.wg.Add(1)
+	go b.receive(ctx, b.broadcast, BroadcastQueue)
+	return nil
+}
+
+// Stop stops the reservation of the messages of the queues, and the bus of
+// the process. The queues that are io.Closers are closed.
+func (b *Bus) Stop() error {
+	if b.cancel != nil {
+		b.cancel()
+	}
+	b.wg.Wait()
+	for _, client := range []queue.Client{b.work, b.broadcast} {
+		if closer, ok := client.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.WithError(err).Error("error closing bridge queue")
+			}
+		}
+	}
+	return b.MessageBus.Stop()
+}
+
+// Publish sends a message to a topic. The messages of the topics consumed by
+// the pipeline role are enqueued to the work queue, unless the process runs
+// the pipeline role and the queues are not durable. The messages of the topics
+// consumed by agent sessions are published on the bus of the process and
+// enqueued to the broadcast queue.
+func (b *Bus) Publish(topic string, message interface{}) error {
+	switch {
+	case IsWorkTopic(topic):
+		if b.pipeline && !b.durable {
+			return b.MessageBus.Publish(topic, message)
+		}
+		return b.enqueue(b.work, WorkQueue, topic, message)
+	case IsBroadcastTopic(topic):
+		if err := b.MessageBus.Publish(topic, message); err != nil {
+			return err
+		}
+		return b.enqueue(b.broadcast, BroadcastQueue, topic, message)
+	default:
+		return b.MessageBus.Publish(topic, message)
+	}
+}
+
+func (b *Bus) enqueue(client queue.Client, queueName, topic string, message interface{}) error {
+	env, err := encode(b.backendName, topic, message)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return client.Enqueue(context.Background(), queue.Item{Queue: queueName, Value: value})
+}
+
+// receive publishes the messages of a queue on the bus of the process, until
+// ctx is cancelled.
+func (b *Bus) receive(ctx context.Context, client queue.Client, queueName string) {
+	defer b.wg.Done()
+	lager := logger.WithField("queue", queueName)
+	for {
+		reservation, err := client.Reserve(ctx, queueName)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			lager.WithError(err).Error("error reserving bridged message")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reserveRetryInterval):
+			}
+			continue
+		}
+		if err := b.deliver(reservation.Item()); err != nil {
+			lager.WithError(err).Error("error publishing bridged message")
+			if err := reservation.Nack(ctx); err != nil {
+				lager.WithError(err).Error("error returning bridged message to the queue")
+			}
+			continue
+		}
+		if err := reservation.Ack(ctx); err != nil {
+			lager.WithError(err).Error("error acknowledging bridged message")
+		}
+	}
+}
+
+// deliver publishes a bridged message on the bus of the process. Messages
+// that can't be decoded are dropped.
+func (b *Bus) deliver(item queue.Item) error {
+	var env envelope
+	if err := json.Unmarshal(item.Value, &env); err != nil {
+		logger.WithError(err).Error("dropping invalid bridged message")
+		return nil
+	}
+	if env.Origin == b.backendName && IsBroadcastTopic(env.Topic) {
+		// The message was already published on the bus of this process
+		return nil
+	}
+	message, err := decode(env)
+	if err != nil {
+		logger.WithError(err).WithField("topic", env.Topic).Error("dropping invalid bridged message")
+		return nil
+	}
+	return b.MessageBus.Publish(env.Topic, message)
+}
+
+// IsWorkTopic returns true if the messages of topic are consumed by the
+// daemons of the pipeline role.
+func IsWorkTopic(topic string) bool {
+	switch topic {
+	case messaging.TopicEventRaw, messaging.TopicKeepaliveRaw, messaging.TopicKeepalive:
+		return true
+	}
+	return false
+}
+
+// IsBroadcastTopic returns true if the messages of topic are consumed by the
+// daemons of every process.
+func IsBroadcastTopic(topic string) bool {
+	return topic == messaging.TopicAgentConnectionState ||
+		strings.HasPrefix(topic, messaging.TopicSubscriptions+":") ||
+		strings.HasPrefix(topic, messaging.TopicBurial+":")
+}
+
+// MessageKind returns the kind of message, which tells DecodeMessage how to
+// decode it once bridged, or an error if message can't be bridged.
+func MessageKind(message interface{}) (string, error) {
+	switch message.(type) {
+	case *corev2.Event:
+		return kindEvent, nil
+	case *corev2.CheckRequest:
+		return kindCheckRequest, nil
+	case messaging.AgentNotification:
+		return kindAgentNotification, nil
+	case nil:
+		return kindNone, nil
+	default:
+		return "", fmt.Errorf("can't bridge message of type %T", message)
+	}
+}
+
+// DecodeMessage decodes payload, the JSON encoding of a message of kind.
+func DecodeMessage(kind string, payload []byte) (interface{}, error) {
+	switch kind {
+	case kindEvent:
+		var event corev2.Event
+		err := json.Unmarshal(payload, &event)
+		return &event, err
+	case kindCheckRequest:
+		var request corev2.CheckRequest
+		err := json.Unmarshal(payload, &request)
+		return &request, err
+	case kindAgentNotification:
+		var notification messaging.AgentNotification
+		err := json.Unmarshal(payload, &notification)
+		return notification, err
+	case kindNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown bridged message kind %q", kind)
+	}
+}
+
+func encode(origin, topic string, message interface{}) (envelope, error) {
+	env := envelope{Origin: origin, Topic: topic}
+	kind, err := MessageKind(message)
+	if err != nil {
+		return env, fmt.Errorf("%s on topic %s", err, topic)
+	}
+	env.Kind = kind
+	if kind == kindNone {
+		return env, nil
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return env, err
+	}
+	env.Payload = payload
+	return env, nil
+}
+
+func decode(env envelope) (interface{}, error) {
+	return DecodeMessage(env.Kind, env.Payload)
+}
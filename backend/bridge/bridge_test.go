@@ -0,0 +1,169 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fanoutClient enqueues items to every client, like a ClusteredQueue, and
+// reserves them from the first one.
+type fanoutClient []queue.Client
+
+func (f fanoutClient) Enqueue(ctx context.Context, item queue.Item) error {
+	for _, client := range f {
+		if err := client.Enqueue(ctx, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f fanoutClient) Reserve(ctx context.Context, queueName string) (queue.Reservation, error) {
+	return f[0].Reserve(ctx, queueName)
+}
+
+func newTestBus(t *testing.T, name string, pipeline bool, work, broadcast queue.Client) *Bus {
+	t.Helper()
+	wizardBus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+	require.NoError(t, err)
+	bus := New(Config{
+		Bus:         wizardBus,
+		Work:        work,
+		Broadcast:   broadcast,
+		BackendName: name,
+		Pipeline:    pipeline,
+	})
+	require.NoError(t, bus.Start())
+	t.Cleanup(func() {
+		assert.NoError(t, bus.Stop())
+	})
+	return bus
+}
+
+func subscribe(t *testing.T, bus messaging.MessageBus, topic string) chan interface{} {
+	t.Helper()
+	ch := make(chan interface{}, 10)
+	_, err := bus.Subscribe(topic, t.Name(), messaging.ChanSubscriber(ch))
+	require.NoError(t, err)
+	return ch
+}
+
+func receive(t *testing.T, ch chan interface{}) interface{} {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(10 * time.Second):
+		t.Fatal("message not received")
+		return nil
+	}
+}
+
+func TestBusBridgesEventsToThePipeline(t *testing.T) {
+	work := queue.NewMemoryClient()
+	listener := newTestBus(t, "listener", false, work, queue.NewMemoryClient())
+	pipeline := newTestBus(t, "pipeline", true, work, queue.NewMemoryClient())
+
+	listenerEvents := subscribe(t, listener, messaging.TopicEventRaw)
+	pipelineEvents := subscribe(t, pipeline, messaging.TopicEventRaw)
+
+	event := corev2.FixtureEvent("entity1", "check1")
+	require.NoError(t, listener.Publish(messaging.TopicEventRaw, event))
+
+	received, ok := receive(t, pipelineEvents).(*corev2.Event)
+	require.True(t, ok)
+	assert.Equal(t, event.Check.Name, received.Check.Name)
+	assert.Equal(t, event.Entity.Name, received.Entity.Name)
+	assert.Empty(t, listenerEvents)
+}
+
+func TestBusBroadcastsCheckRequests(t *testing.T) {
+	listenerQueue, pipelineQueue := queue.NewMemoryClient(), queue.NewMemoryClient()
+	listener := newTestBus(t, "listener", false, queue.NewMemoryClient(), fanoutClient{listenerQueue, pipelineQueue})
+	pipeline := newTestBus(t, "pipeline", true, queue.NewMemoryClient(), fanoutClient{pipelineQueue, listenerQueue})
+
+	topic := messaging.SubscriptionTopic("default", "linux")
+	listenerRequests := subscribe(t, listener, topic)
+	pipelineRequests := subscribe(t, pipeline, topic)
+
+	request := &corev2.CheckRequest{Config: corev2.FixtureCheckConfig("check1")}
+	require.NoError(t, pipeline.Publish(topic, request))
+
+	received, ok := receive(t, listenerRequests).(*corev2.CheckRequest)
+	require.True(t, ok)
+	assert.Equal(t, "check1", received.Config.Name)
+
+	// The request is published once on the bus of its origin
+	receive(t, pipelineRequests)
+	time.Sleep(2 * time.Second)
+	assert.Empty(t, pipelineRequests)
+}
+
+func TestBusDurableWorkQueue(t *testing.T) {
+	work := queue.NewMemoryClient()
+
+	// The pipeline process that publishes the event stops before handling it
+	wizardBus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+	require.NoError(t, err)
+	stopped := New(Config{
+		Bus:         wizardBus,
+		Work:        work,
+		Broadcast:   queue.NewMemoryClient(),
+		BackendName: "pipeline1",
+		Pipeline:    true,
+		Durable:     true,
+	})
+	stoppedEvents := subscribe(t, stopped, messaging.TopicEventRaw)
+	event := corev2.FixtureEvent("entity1", "check1")
+	require.NoError(t, stopped.Publish(messaging.TopicEventRaw, event))
+	assert.Empty(t, stoppedEvents)
+
+	// Another pipeline process handles it
+	wizardBus, err = messaging.NewWizardBus(messaging.WizardBusConfig{})
+	require.NoError(t, err)
+	pipeline := New(Config{
+		Bus:         wizardBus,
+		Work:        work,
+		Broadcast:   queue.NewMemoryClient(),
+		BackendName: "pipeline2",
+		Pipeline:    true,
+		Durable:     true,
+	})
+	pipelineEvents := subscribe(t, pipeline, messaging.TopicEventRaw)
+	require.NoError(t, pipeline.Start())
+	defer func() {
+		assert.NoError(t, pipeline.Stop())
+	}()
+	received, ok := receive(t, pipelineEvents).(*corev2.Event)
+	require.True(t, ok)
+	assert.Equal(t, event.Check.Name, received.Check.Name)
+
+	// The events it publishes itself also go through the work queue
+	require.NoError(t, pipeline.Publish(messaging.TopicEventRaw, event))
+	receive(t, pipelineEvents)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	notification := messaging.AgentNotification{Namespace: "default", Name: "agent1", Connected: true}
+	env, err := encode("backend1", messaging.TopicAgentConnectionState, notification)
+	require.NoError(t, err)
+	decoded, err := decode(env)
+	require.NoError(t, err)
+	assert.Equal(t, notification, decoded)
+
+	env, err = encode("backend1", messaging.BurialTopic("default", "agent1"), nil)
+	require.NoError(t, err)
+	decoded, err = decode(env)
+	require.NoError(t, err)
+	assert.Nil(t, decoded)
+
+	_, err = encode("backend1", "topic", "message")
+	assert.Error(t, err)
+}
@@ -0,0 +1,380 @@
+// Package certmonitor monitors the expiry of the TLS certificates of the
+// backend: the certificates it serves to the agents and to the API clients,
+// the certificates it presents as a client, and the client certificates the
+// agents present when they connect. An expired certificate silently breaks
+// the connections that rely on it, so the monitor publishes the events of the
+// backend entity that warn before the certificates expire.
+package certmonitor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/backend/daemon"
+	"github.com/sensu/sensu-go/backend/resource"
+)
+
+const (
+	componentName = "certmonitord"
+
+	// CheckPrefix is the prefix of the names of the checks of the events
+	// published for the certificates, followed by the certificate name.
+	CheckPrefix = "certificate-"
+
+	// AgentCertificates is the name of the certificates presented by the
+	// agents, reported together by one event.
+	AgentCertificates = "agents"
+
+	// DefaultInterval is the interval at which the certificates are checked
+	// when Config.Interval is zero.
+	DefaultInterval = time.Hour
+
+	// DefaultWarningThreshold is the default time before their expiry from
+	// which the certificates are reported by a warning event.
+	DefaultWarningThreshold = 30 * 24 * time.Hour
+
+	// DefaultCriticalThreshold is the default time before their expiry from
+	// which the certificates are reported by a critical event.
+	DefaultCriticalThreshold = 7 * 24 * time.Hour
+
+	// ExpiryGaugeVec is the name of the gauge of the time left before the
+	// certificates expire.
+	ExpiryGaugeVec = "sensu_go_certificate_expiry_seconds"
+
+	statusOK       = 0
+	statusWarning  = 1
+	statusCritical = 2
+	statusUnknown  = 3
+
+	// maxReportedAgents is the number of agents named by the event of the
+	// agent certificates.
+	maxReportedAgents = 10
+)
+
+var (
+	// Expiry is the number of seconds left before the certificates of the
+	// backend expire, by certificate name.
+	Expiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: ExpiryGaugeVec,
+			Help: "The number of seconds left before the TLS certificates of the backend expire",
+		},
+		[]string{"certificate"},
+	)
+)
+
+func init() {
+	if err := prometheus.Register(Expiry); err != nil {
+		panic(err)
+	}
+}
+
+// Certificate is a certificate of the backend monitored by a Monitor, read
+// from a file or from a TLS configuration.
+type Certificate struct {
+	// Name is the name of the certificate, such as agentd or apid, in the
+	// name of the check of its event.
+	Name string
+
+	// File is the path to the PEM file of the certificate. It is read at
+	// every check, so that the rotated certificates are monitored.
+	File string
+
+	// TLS is the TLS configuration whose certificates are monitored, when
+	// File is empty.
+	TLS *tls.Config
+}
+
+// Config configures a Monitor.
+type Config struct {
+	EventReceiver resource.EventReceiver
+
+	// Certificates are the certificates of the backend to monitor.
+	Certificates []Certificate
+
+	// WarningThreshold and CriticalThreshold are the times before their
+	// expiry from which the certificates are reported by a warning and by a
+	// critical event.
+	WarningThreshold  time.Duration
+	CriticalThreshold time.Duration
+
+	// Interval is the interval at which the certificates are checked.
+	Interval time.Duration
+}
+
+// agentCertificate is the certificate presented by an agent.
+type agentCertificate struct {
+	agent    string
+	notAfter time.Time
+}
+
+// Monitor is the daemon that monitors the expiry of the certificates of the
+// backend and of its agents.
+type Monitor struct {
+	receiver     resource.EventReceiver
+	certificates []Certificate
+	warning      time.Duration
+	critical     time.Duration
+	interval     time.Duration
+	agentsStatus uint32
+	loop         *daemon.Loop
+
+	mu     sync.Mutex
+	agents map[string]*agentCertificate
+}
+
+// New creates a new Monitor.
+func New(cfg Config) (*Monitor, error) {
+	if cfg.EventReceiver == nil {
+		return nil, errors.New("an event receiver is required")
+	}
+	m := &Monitor{
+		receiver:     cfg.EventReceiver,
+		certificates: cfg.Certificates,
+		warning:      cfg.WarningThreshold,
+		critical:     cfg.CriticalThreshold,
+		interval:     cfg.Interval,
+		loop:         daemon.NewLoop(),
+		agents:       make(map[string]*agentCertificate),
+	}
+	if m.warning <= 0 {
+		m.warning = DefaultWarningThreshold
+	}
+	if m.critical <= 0 {
+		m.critical = DefaultCriticalThreshold
+	}
+	if m.critical > m.warning {
+		return nil, fmt.Errorf("the critical threshold (%s) exceeds the warning threshold (%s)", m.critical, m.warning)
+	}
+	if m.interval <= 0 {
+		m.interval = DefaultInterval
+	}
+	for _, certificate := range m.certificates {
+		if certificate.Name == "" || certificate.File == "" && certificate.TLS == nil {
+			return nil, fmt.Errorf("invalid certificate %q: a name and a file or TLS configuration are required", certificate.Name)
+		}
+	}
+	return m, nil
+}
+
+// WatchAgent monitors cert, the client certificate presented by the agent
+// name of namespace, until the returned function is called once the session
+// of the agent ends. A nil Monitor monitors nothing.
+func (m *Monitor) WatchAgent(namespace, name string, cert *x509.Certificate) (forget func()) {
+	if m == nil || cert == nil {
+		return func() {}
+	}
+	key := namespace + "/" + name
+	watched := &agentCertificate{agent: key, notAfter: cert.NotAfter}
+	m.mu.Lock()
+	m.agents[key] = watched
+	m.mu.Unlock()
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		// The agent may have connected again with another certificate
+		if m.agents[key] == watched {
+			delete(m.agents, key)
+		}
+	}
+}
+
+// Start starts the monitoring of the certificates.
+func (m *Monitor) Start() error {
+	m.loop.Go(m.run)
+	return nil
+}
+
+// Stop stops the monitoring of the certificates.
+func (m *Monitor) Stop() error {
+	return m.loop.Stop()
+}
+
+// Err returns a channel on which to listen for terminal errors.
+func (m *Monitor) Err() <-chan error {
+	return m.loop.Err()
+}
+
+// Name returns the daemon name.
+func (m *Monitor) Name() string {
+	return componentName
+}
+
+func (m *Monitor) run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.check(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check publishes the events of the certificates, as of now.
+func (m *Monitor) check(now time.Time) {
+	for _, certificate := range m.certificates {
+		var status uint32
+		var output string
+		notAfter, err := certificate.notAfter()
+		if err != nil {
+			status = statusUnknown
+			output = fmt.Sprintf("could not read the %s certificate: %s", certificate.Name, err)
+		} else {
+			Expiry.WithLabelValues(certificate.Name).Set(notAfter.Sub(now).Seconds())
+			status, output = m.certificateStatus(certificate.Name, notAfter, now)
+		}
+		m.publish(certificate.Name, status, output)
+	}
+
+	status, output, ok := m.agentsCheck(now)
+	if ok {
+		m.publish(AgentCertificates, status, output)
+		m.agentsStatus = status
+	}
+}
+
+func (m *Monitor) publish(name string, status uint32, output string) {
+	if err := m.receiver.GenerateBackendEvent(CheckPrefix+name, status, output); err != nil {
+		logger.WithError(err).WithField("certificate", name).Error("could not publish the certificate event")
+	}
+}
+
+// status returns the status of a certificate that expires at notAfter.
+func (m *Monitor) status(notAfter, now time.Time) uint32 {
+	switch left := notAfter.Sub(now); {
+	case left <= m.critical:
+		return statusCritical
+	case left <= m.warning:
+		return statusWarning
+	default:
+		return statusOK
+	}
+}
+
+// certificateStatus returns the status and output of the event of the
+// certificate name, which expires at notAfter.
+func (m *Monitor) certificateStatus(name string, notAfter, now time.Time) (uint32, string) {
+	status := m.status(notAfter, now)
+	if !notAfter.After(now) {
+		return status, fmt.Sprintf("the %s certificate expired on %s", name, formatTime(notAfter))
+	}
+	return status, fmt.Sprintf("the %s certificate expires on %s, in %s", name, formatTime(notAfter), formatDuration(notAfter.Sub(now)))
+}
+
+// agentsCheck returns the status and output of the event of the agent
+// certificates, and false if there is no event to publish because no agent
+// presented a certificate since the last resolved event.
+func (m *Monitor) agentsCheck(now time.Time) (uint32, string, bool) {
+	m.mu.Lock()
+	certificates := make([]*agentCertificate, 0, len(m.agents))
+	for _, certificate := range m.agents {
+		certificates = append(certificates, certificate)
+	}
+	m.mu.Unlock()
+	if len(certificates) == 0 && m.agentsStatus == statusOK {
+		return 0, "", false
+	}
+
+	sort.Slice(certificates, func(i, j int) bool {
+		if certificates[i].notAfter.Equal(certificates[j].notAfter) {
+			return certificates[i].agent < certificates[j].agent
+		}
+		return certificates[i].notAfter.Before(certificates[j].notAfter)
+	})
+	var status uint32
+	var expiring []string
+	for _, certificate := range certificates {
+		agentStatus := m.status(certificate.notAfter, now)
+		if agentStatus == statusOK {
+			break
+		}
+		if agentStatus > status {
+			status = agentStatus
+		}
+		expiring = append(expiring, fmt.Sprintf("%s (%s)", certificate.agent, formatTime(certificate.notAfter)))
+	}
+	if len(expiring) == 0 {
+		return statusOK, fmt.Sprintf("the certificates of the %d connected agents expire in more than %s", len(certificates), formatDuration(m.warning)), true
+	}
+	if len(expiring) > maxReportedAgents {
+		expiring = append(expiring[:maxReportedAgents], fmt.Sprintf("and %d more", len(expiring)-maxReportedAgents))
+	}
+	return status, fmt.Sprintf("the certificates of %d of the %d connected agents expire within %s: %s", len(expiring), len(certificates), formatDuration(m.warning), strings.Join(expiring, ", ")), true
+}
+
+// notAfter returns the earliest expiry of the certificates of c, which
+// includes the intermediate certificates of its chain.
+func (c Certificate) notAfter() (time.Time, error) {
+	var certificates []*x509.Certificate
+	if c.File != "" {
+		data, err := os.ReadFile(c.File)
+		if err != nil {
+			return time.Time{}, err
+		}
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return time.Time{}, err
+			}
+			certificates = append(certificates, certificate)
+		}
+	} else {
+		for _, chain := range c.TLS.Certificates {
+			for _, der := range chain.Certificate {
+				certificate, err := x509.ParseCertificate(der)
+				if err != nil {
+					return time.Time{}, err
+				}
+				certificates = append(certificates, certificate)
+			}
+		}
+	}
+	if len(certificates) == 0 {
+		return time.Time{}, errors.New("no certificate found")
+	}
+	notAfter := certificates[0].NotAfter
+	for _, certificate := range certificates[1:] {
+		if certificate.NotAfter.Before(notAfter) {
+			notAfter = certificate.NotAfter
+		}
+	}
+	return notAfter, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatDuration formats d in days, or in hours and minutes under a day.
+func formatDuration(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d >= 2*day:
+		return fmt.Sprintf("%d days", d/day)
+	case d >= day:
+		return "1 day"
+	default:
+		return d.Round(time.Minute).String()
+	}
+}
@@ -0,0 +1,167 @@
+package certmonitor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/testing/mockresource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCertificate(t *testing.T, notAfter time.Time) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sensu"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func writeCertificates(t *testing.T, certs ...*x509.Certificate) string {
+	t.Helper()
+	var data []byte
+	for _, cert := range certs {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestMonitorCertificates(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	day := 24 * time.Hour
+	receiver := &mockresource.EventReceiver{}
+	m, err := New(Config{
+		EventReceiver: receiver,
+		Certificates: []Certificate{
+			{Name: "agentd", File: writeCertificates(t, newCertificate(t, now.Add(90*day)))},
+			// The intermediate certificate of the chain expires first
+			{Name: "apid", File: writeCertificates(t, newCertificate(t, now.Add(90*day)), newCertificate(t, now.Add(20*day)))},
+			{Name: "dashboard", File: writeCertificates(t, newCertificate(t, now.Add(3*day)))},
+			{Name: "client", TLS: &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{newCertificate(t, now.Add(-day)).Raw}}}}},
+			{Name: "missing", File: filepath.Join(t.TempDir(), "missing.pem")},
+		},
+	})
+	require.NoError(t, err)
+	m.check(now)
+
+	event, _ := receiver.Event("certificate-agentd")
+	assert.Equal(t, uint32(0), event.Status)
+	assert.Contains(t, event.Output, "in 90 days")
+
+	event, _ = receiver.Event("certificate-apid")
+	assert.Equal(t, uint32(1), event.Status)
+	assert.Contains(t, event.Output, "in 20 days")
+
+	event, _ = receiver.Event("certificate-dashboard")
+	assert.Equal(t, uint32(2), event.Status)
+
+	event, _ = receiver.Event("certificate-client")
+	assert.Equal(t, uint32(2), event.Status)
+	assert.Contains(t, event.Output, "expired on")
+
+	event, _ = receiver.Event("certificate-missing")
+	assert.Equal(t, uint32(3), event.Status)
+
+	// No agent presented a certificate
+	_, ok := receiver.Event("certificate-agents")
+	assert.False(t, ok)
+}
+
+func TestMonitorAgentCertificates(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	day := 24 * time.Hour
+	receiver := &mockresource.EventReceiver{}
+	m, err := New(Config{EventReceiver: receiver})
+	require.NoError(t, err)
+
+	forget1 := m.WatchAgent("default", "agent1", newCertificate(t, now.Add(90*day)))
+	forget2 := m.WatchAgent("default", "agent2", newCertificate(t, now.Add(10*day)))
+	m.check(now)
+	event, _ := receiver.Event("certificate-agents")
+	assert.Equal(t, uint32(1), event.Status)
+	assert.Contains(t, event.Output, "1 of the 2 connected agents")
+	assert.Contains(t, event.Output, "default/agent2")
+	assert.NotContains(t, event.Output, "default/agent1")
+
+	// The agent connects again with a renewed certificate, before its
+	// previous session ends
+	forget3 := m.WatchAgent("default", "agent2", newCertificate(t, now.Add(365*day)))
+	forget2()
+	m.check(now)
+	event, _ = receiver.Event("certificate-agents")
+	assert.Equal(t, uint32(0), event.Status)
+	assert.Contains(t, event.Output, "the 2 connected agents")
+
+	// The agents disconnect, while the event is resolved
+	forget1()
+	forget3()
+	receiver.Reset()
+	m.check(now)
+	_, ok := receiver.Event("certificate-agents")
+	assert.False(t, ok)
+}
+
+func TestMonitorAgentCertificatesResolved(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	receiver := &mockresource.EventReceiver{}
+	m, err := New(Config{EventReceiver: receiver})
+	require.NoError(t, err)
+
+	for i := 0; i < maxReportedAgents+2; i++ {
+		m.WatchAgent("default", strings.Repeat("a", i+1), newCertificate(t, now.Add(time.Hour)))
+	}
+	m.check(now)
+	event, _ := receiver.Event("certificate-agents")
+	assert.Equal(t, uint32(2), event.Status)
+	assert.Contains(t, event.Output, "and 2 more")
+
+	// The event is resolved once the agents are gone, and is no longer
+	// published afterwards
+	m.mu.Lock()
+	m.agents = map[string]*agentCertificate{}
+	m.mu.Unlock()
+	m.check(now)
+	event, _ = receiver.Event("certificate-agents")
+	assert.Equal(t, uint32(0), event.Status)
+
+	receiver.Reset()
+	m.check(now)
+	_, ok := receiver.Event("certificate-agents")
+	assert.False(t, ok)
+}
+
+func TestNilMonitorWatchAgent(t *testing.T) {
+	var m *Monitor
+	m.WatchAgent("default", "agent1", &x509.Certificate{})()
+}
+
+func TestNewInvalidThresholds(t *testing.T) {
+	_, err := New(Config{
+		EventReceiver:     &mockresource.EventReceiver{},
+		WarningThreshold:  time.Hour,
+		CriticalThreshold: 2 * time.Hour,
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,7 @@
+package certmonitor
+
+import "github.com/sirupsen/logrus"
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "certmonitor",
+})
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	flagShowSecrets = "show-secrets"
+
+	redacted = "REDACTED"
+)
+
+// secretFlags are the flags whose values are redacted when printing the
+// configuration, unless --show-secrets is used.
+var secretFlags = map[string]struct{}{
+	flagPGDSN: {},
+}
+
+// ConfigCommand is the 'sensu-backend config' subcommand.
+func ConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "inspect the sensu-backend configuration",
+	}
+
+	cmd.AddCommand(PrintEffectiveConfigCommand())
+
+	return cmd
+}
+
+// PrintEffectiveConfigCommand is the 'sensu-backend config print-effective'
+// subcommand.
+func PrintEffectiveConfigCommand() *cobra.Command {
+	var setupErr error
+	cmd := &cobra.Command{
+		Use:           "print-effective",
+		Short:         "print the configuration of 'sensu-backend start', after applying the config file, environment variables and flags",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = viper.BindPFlags(cmd.Flags())
+			if setupErr != nil {
+				return setupErr
+			}
+
+			settings := effectiveConfig(cmd.Flags(), viper.GetBool(flagShowSecrets))
+			b, err := yaml.Marshal(settings)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %d\n%s", configKeyVersion, configVersionStructured, b)
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool(flagShowSecrets, false, "print the values of secret settings, such as the postgresql DSN")
+
+	setupErr = handleConfig(cmd, os.Args[1:], true)
+
+	return cmd
+}
+
+// effectiveConfig returns the value of every setting of flags, keyed by flag
+// name. The result can be used as a structured configuration file.
+func effectiveConfig(flags *pflag.FlagSet, showSecrets bool) map[string]interface{} {
+	settings := make(map[string]interface{})
+	flags.VisitAll(func(flag *pflag.Flag) {
+		name := flag.Name
+		switch name {
+		case flagConfigFile, flagShowSecrets, "help":
+			return
+		}
+		if _, ok := secretFlags[name]; ok && !showSecrets {
+			if viper.GetString(name) != "" {
+				settings[name] = redacted
+			}
+			return
+		}
+		switch flag.Value.Type() {
+		case "bool":
+			settings[name] = viper.GetBool(name)
+		case "int", "int64", "uint", "uint32", "uint64":
+			settings[name] = viper.GetInt64(name)
+		case "float64":
+			settings[name] = viper.GetFloat64(name)
+		case "duration":
+			settings[name] = viper.GetDuration(name).String()
+		case "stringSlice", "stringArray":
+			settings[name] = viper.GetStringSlice(name)
+		case "stringToString":
+			value := viper.GetStringMapString(name)
+			if flag.Changed {
+				value, _ = flags.GetStringToString(name)
+			}
+			settings[name] = value
+		default:
+			settings[name] = viper.GetString(name)
+		}
+	})
+	return settings
+}
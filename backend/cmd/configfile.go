@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	// configVersionLegacy is the version of configuration files that don't
+	// specify one. Legacy files are a flat mapping of flag names to values, and
+	// are not validated.
+	configVersionLegacy = 1
+
+	// configVersionStructured is the version of structured configuration
+	// files. See loadStructuredConfig for a description of the format.
+	configVersionStructured = 2
+
+	configKeyVersion  = "version"
+	configKeyIncludes = "includes"
+)
+
+// configFile is a parsed backend configuration file.
+type configFile struct {
+	// Version is the version of the configuration file format.
+	Version int
+
+	// Raw is the content of the configuration file. It is only set for legacy
+	// configuration files.
+	Raw []byte
+
+	// Settings are the flattened settings of a structured configuration file,
+	// keyed by flag name.
+	Settings map[string]interface{}
+}
+
+// loadConfigFile loads the configuration file at path into viper. Settings
+// from the configuration file have a lower precedence than flags and
+// environment variables.
+func loadConfigFile(path string, flags *pflag.FlagSet) error {
+	file, err := readConfigFile(path, flags)
+	if err != nil {
+		return err
+	}
+	if file.Version == configVersionLegacy {
+		return viper.ReadConfig(bytes.NewReader(file.Raw))
+	}
+	return viper.MergeConfigMap(file.Settings)
+}
+
+// readConfigFile reads the configuration file at path. Structured
+// configuration files are validated against the flags of the command.
+func readConfigFile(path string, flags *pflag.FlagSet) (*configFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	version, err := configVersion(b)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	switch version {
+	case configVersionLegacy:
+		return &configFile{Version: version, Raw: b}, nil
+	case configVersionStructured:
+		settings, err := loadStructuredConfig(path, flags, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &configFile{Version: version, Settings: settings}, nil
+	default:
+		return nil, fmt.Errorf("error reading config file %s: unsupported version %d", path, version)
+	}
+}
+
+func configVersion(b []byte) (int, error) {
+	var header map[string]interface{}
+	if err := yaml.Unmarshal(b, &header); err != nil {
+		return 0, err
+	}
+	value, ok := header[configKeyVersion]
+	if !ok {
+		return configVersionLegacy, nil
+	}
+	version, ok := value.(float64)
+	if !ok || version != math.Trunc(version) {
+		return 0, fmt.Errorf("invalid version: %v", value)
+	}
+	return int(version), nil
+}
+
+// loadStructuredConfig loads a structured configuration file, and returns its
+// settings keyed by flag name.
+//
+// Settings can be nested; the keys of nested settings are joined with "-" to
+// form the flag name, so that `agent: {host: "[::]"}` sets --agent-host.
+// String values can refer to environment variables with ${VAR}, or
+// ${VAR:-default} to use a default value when VAR is unset or empty.
+//
+// Files listed in "includes" are loaded first, relative to the directory of
+// the including file, and may use glob patterns. Settings of the including
+// file take precedence over the settings of the files it includes.
+func loadStructuredConfig(path string, flags *pflag.FlagSet, seen []string) (map[string]interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range seen {
+		if p == abs {
+			return nil, fmt.Errorf("error reading config file %s: include cycle detected", path)
+		}
+	}
+	seen = append(seen, abs)
+
+	b, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	if version, err := configVersion(b); err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	} else if version != configVersionStructured {
+		return nil, fmt.Errorf("error reading config file %s: version %d is required", path, configVersionStructured)
+	}
+
+	settings := make(map[string]interface{})
+
+	includes, err := configIncludes(filepath.Dir(abs), doc[configKeyIncludes])
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+	for _, include := range includes {
+		included, err := loadStructuredConfig(include, flags, seen)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range included {
+			settings[k] = v
+		}
+	}
+
+	delete(doc, configKeyVersion)
+	delete(doc, configKeyIncludes)
+
+	var errs configErrors
+	flattenConfig("", doc, flags, settings, &errs)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, errs)
+	}
+
+	return settings, nil
+}
+
+func configIncludes(dir string, value interface{}) ([]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	patterns, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a list of paths", configKeyIncludes)
+	}
+	var includes []string
+	for _, pattern := range patterns {
+		s, ok := pattern.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a list of paths", configKeyIncludes)
+		}
+		s, err := interpolateEnv(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", configKeyIncludes, err)
+		}
+		if !filepath.IsAbs(s) {
+			s = filepath.Join(dir, s)
+		}
+		matches, err := filepath.Glob(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", configKeyIncludes, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(s, "*?[") {
+			return nil, fmt.Errorf("%s: %s does not exist", configKeyIncludes, s)
+		}
+		sort.Strings(matches)
+		includes = append(includes, matches...)
+	}
+	return includes, nil
+}
+
+// configErrors collects all the problems found in a configuration file, so
+// that they can be reported at once.
+type configErrors []error
+
+func (e configErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func flattenConfig(prefix string, doc map[string]interface{}, flags *pflag.FlagSet, settings map[string]interface{}, errs *configErrors) {
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key := k
+		if prefix != "" {
+			key = prefix + "-" + k
+		}
+		value := doc[k]
+		if flag := flags.Lookup(key); flag != nil && key != flagConfigFile {
+			v, err := configValue(flag, value)
+			if err != nil {
+				*errs = append(*errs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+			settings[key] = v
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenConfig(key, nested, flags, settings, errs)
+			continue
+		}
+		*errs = append(*errs, fmt.Errorf("%s: unknown setting", key))
+	}
+}
+
+// configValue validates value against the type of flag, interpolating
+// environment variables in string values.
+func configValue(flag *pflag.Flag, value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		var err error
+		if value, err = interpolateEnv(s); err != nil {
+			return nil, err
+		}
+	}
+	switch flag.Value.Type() {
+	case "string":
+		switch value := value.(type) {
+		case string:
+			return value, nil
+		case float64, bool:
+			return fmt.Sprint(value), nil
+		}
+	case "bool":
+		switch value := value.(type) {
+		case bool:
+			return value, nil
+		case string:
+			if b, err := strconv.ParseBool(value); err == nil {
+				return b, nil
+			}
+		}
+	case "int", "int64", "uint", "uint32", "uint64":
+		switch value := value.(type) {
+		case float64:
+			if value == math.Trunc(value) {
+				return int64(value), nil
+			}
+		case string:
+			if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+	case "float64":
+		switch value := value.(type) {
+		case float64:
+			return value, nil
+		case string:
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return f, nil
+			}
+		}
+	case "duration":
+		if s, ok := value.(string); ok {
+			if _, err := time.ParseDuration(s); err == nil {
+				return s, nil
+			}
+		}
+	case "stringSlice", "stringArray":
+		if list, ok := value.([]interface{}); ok {
+			result := make([]string, 0, len(list))
+			for _, item := range list {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected a list of strings")
+				}
+				s, err := interpolateEnv(s)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, s)
+			}
+			return result, nil
+		}
+	case "stringToString":
+		if m, ok := value.(map[string]interface{}); ok {
+			result := make(map[string]string, len(m))
+			for k, v := range m {
+				s, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected a map of strings")
+				}
+				s, err := interpolateEnv(s)
+				if err != nil {
+					return nil, err
+				}
+				result[k] = s
+			}
+			return result, nil
+		}
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("invalid value %v: expected %s", value, flag.Value.Type())
+}
+
+// interpolateEnv replaces ${VAR} and ${VAR:-default} in s with the value of
+// the environment variable VAR. It is an error to refer to a variable that is
+// unset, unless a default is provided. A literal "$" is written as "$$".
+func interpolateEnv(s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' {
+			buf.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '$' {
+			buf.WriteByte('$')
+			i++
+			continue
+		}
+		if i+1 >= len(s) || s[i+1] != '{' {
+			buf.WriteByte('$')
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", s)
+		}
+		expr := s[i+2 : i+end]
+		name, def, hasDefault := strings.Cut(expr, ":-")
+		if name == "" {
+			return "", errors.New("empty variable reference")
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok || (value == "" && hasDefault) {
+			if !hasDefault {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+			value = def
+		}
+		buf.WriteString(value)
+		i += end
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func testConfigFlags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String(flagAgentHost, "", "")
+	flags.Int(flagAgentPort, 0, "")
+	flags.String(flagPGDSN, "", "")
+	flags.Duration(flagAPIWriteTimeout, 0, "")
+	flags.Bool(flagDisableEventCache, false, "")
+	flags.StringToString(flagLabels, nil, "")
+	return flags
+}
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInterpolateEnv(t *testing.T) {
+	t.Setenv("SENSU_TEST_HOST", "example.com")
+	t.Setenv("SENSU_TEST_EMPTY", "")
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "no variables", want: "no variables"},
+		{input: "${SENSU_TEST_HOST}", want: "example.com"},
+		{input: "postgresql://${SENSU_TEST_HOST}:5432", want: "postgresql://example.com:5432"},
+		{input: "${SENSU_TEST_UNSET:-default}", want: "default"},
+		{input: "${SENSU_TEST_EMPTY:-default}", want: "default"},
+		{input: "$$5", want: "$5"},
+		{input: "$PLAIN", want: "$PLAIN"},
+		{input: "${SENSU_TEST_UNSET}", wantErr: true},
+		{input: "${SENSU_TEST_HOST", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := interpolateEnv(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("interpolateEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("interpolateEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadConfigFileLegacy(t *testing.T) {
+	path := writeConfig(t, t.TempDir(), "backend.yml", "agent-host: localhost\nunknown: true\n")
+	file, err := readConfigFile(path, testConfigFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := file.Version, configVersionLegacy; got != want {
+		t.Errorf("bad version: got %d, want %d", got, want)
+	}
+}
+
+func TestReadConfigFileStructured(t *testing.T) {
+	t.Setenv("SENSU_TEST_DSN", "postgresql://localhost")
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "conf.d"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, filepath.Join(dir, "conf.d"), "store.yml", `
+version: 2
+pg-dsn: ${SENSU_TEST_DSN}
+agent:
+  port: 1234
+`)
+	path := writeConfig(t, dir, "backend.yml", `
+version: 2
+includes:
+- conf.d/*.yml
+agent:
+  host: localhost
+  port: 8081
+api:
+  write-timeout: 30s
+disable-event-cache: true
+labels:
+  region: us-west-2
+`)
+	file, err := readConfigFile(path, testConfigFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		flagAgentHost:         "localhost",
+		flagAgentPort:         int64(8081),
+		flagPGDSN:             "postgresql://localhost",
+		flagAPIWriteTimeout:   "30s",
+		flagDisableEventCache: true,
+		flagLabels:            map[string]string{"region": "us-west-2"},
+	}
+	if !reflect.DeepEqual(file.Settings, want) {
+		t.Errorf("bad settings: got %v, want %v", file.Settings, want)
+	}
+}
+
+func TestReadConfigFileStructuredErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "unknown setting",
+			content: "version: 2\nagent:\n  hots: localhost\n",
+			wantErr: "agent-hots: unknown setting",
+		},
+		{
+			name:    "bad type",
+			content: "version: 2\nagent-port: eighty\n",
+			wantErr: "agent-port: invalid value",
+		},
+		{
+			name:    "bad duration",
+			content: "version: 2\napi-write-timeout: 30\n",
+			wantErr: "api-write-timeout: invalid value",
+		},
+		{
+			name:    "unsupported version",
+			content: "version: 3\n",
+			wantErr: "unsupported version 3",
+		},
+		{
+			name:    "missing include",
+			content: "version: 2\nincludes:\n- missing.yml\n",
+			wantErr: "does not exist",
+		},
+		{
+			name:    "include cycle",
+			content: "version: 2\nincludes:\n- backend.yml\n",
+			wantErr: "include cycle detected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, t.TempDir(), "backend.yml", tt.content)
+			_, err := readConfigFile(path, testConfigFlags())
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/google/uuid"
 	"github.com/sensu/sensu-go/backend"
 	"github.com/sensu/sensu-go/backend/seeds"
 	"github.com/sensu/sensu-go/backend/store/postgres"
@@ -25,6 +27,9 @@ const (
 	flagTimeout                  = "timeout"
 	flagWait                     = "wait"
 	flagInitAdminAPIKey          = "cluster-admin-api-key"
+	flagSeedFile                 = "seed-file"
+	flagInitAdditionalAdmins     = "additional-cluster-admin"
+	flagBreakGlassAPIKeyFile     = "break-glass-api-key-file"
 )
 
 type initConfig struct {
@@ -37,9 +42,73 @@ func (c *initConfig) Validate() error {
 	if c.SeedConfig.AdminUsername == "" || c.SeedConfig.AdminPassword == "" {
 		return fmt.Errorf("both %s and %s are required to be set (or an API key)", flagInitAdminUsername, flagInitAdminPassword)
 	}
+	usernames := map[string]struct{}{
+		c.SeedConfig.AdminUsername: {},
+	}
+	for _, admin := range c.SeedConfig.AdditionalAdmins {
+		if admin.Username == "" || admin.Password == "" {
+			return fmt.Errorf("%s must be of the form username:password", flagInitAdditionalAdmins)
+		}
+		if _, ok := usernames[admin.Username]; ok {
+			return fmt.Errorf("cluster admin %q specified more than once", admin.Username)
+		}
+		usernames[admin.Username] = struct{}{}
+	}
 	return nil
 }
 
+// parseAdmins parses cluster admins of the form username:password.
+func parseAdmins(values []string) []seeds.Admin {
+	admins := make([]seeds.Admin, 0, len(values))
+	for _, value := range values {
+		var admin seeds.Admin
+		admin.Username, admin.Password, _ = strings.Cut(value, ":")
+		admins = append(admins, admin)
+	}
+	return admins
+}
+
+// breakGlassKeyFile is the destination of a generated break-glass API key.
+type breakGlassKeyFile struct {
+	path string
+	file *os.File
+}
+
+// createBreakGlassKeyFile creates the file that the break-glass API key is
+// written to. The file is created with 0600 permissions, and an existing file
+// is never overwritten. If path is "-", the key is written to stdout.
+func createBreakGlassKeyFile(path string) (*breakGlassKeyFile, error) {
+	if path == "-" {
+		return &breakGlassKeyFile{path: path, file: os.Stdout}, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create break-glass api key file: %w", err)
+	}
+	return &breakGlassKeyFile{path: path, file: f}, nil
+}
+
+// finish writes the key if initErr is nil. Otherwise the file is removed, so
+// that a key that was never stored is not left behind.
+func (b *breakGlassKeyFile) finish(key string, initErr error) error {
+	if b.file == os.Stdout {
+		if initErr == nil {
+			fmt.Fprintf(b.file, "Break-glass API key (this key will not be shown again): %s\n", key)
+		}
+		return initErr
+	}
+	if initErr != nil {
+		_ = b.file.Close()
+		_ = os.Remove(b.path)
+		return initErr
+	}
+	if _, err := fmt.Fprintln(b.file, key); err != nil {
+		_ = b.file.Close()
+		return fmt.Errorf("couldn't write break-glass api key file: %w", err)
+	}
+	return b.file.Close()
+}
+
 type initOpts struct {
 	AdminUsername             string `survey:"cluster-admin-username"`
 	AdminPassword             string `survey:"cluster-admin-password"`
@@ -111,9 +180,10 @@ func InitCommand() *cobra.Command {
 			initConfig := initConfig{
 				Config: *cfg,
 				SeedConfig: seeds.Config{
-					AdminUsername: viper.GetString(flagInitAdminUsername),
-					AdminPassword: viper.GetString(flagInitAdminPassword),
-					AdminAPIKey:   viper.GetString(flagInitAdminAPIKey),
+					AdminUsername:    viper.GetString(flagInitAdminUsername),
+					AdminPassword:    viper.GetString(flagInitAdminPassword),
+					AdminAPIKey:      viper.GetString(flagInitAdminAPIKey),
+					AdditionalAdmins: parseAdmins(viper.GetStringSlice(flagInitAdditionalAdmins)),
 				},
 				Timeout: timeout,
 			}
@@ -136,7 +206,31 @@ func InitCommand() *cobra.Command {
 				return err
 			}
 
+			if seedFile := viper.GetString(flagSeedFile); seedFile != "" {
+				resources, err := seeds.ReadSeedFile(seedFile)
+				if err != nil {
+					return err
+				}
+				initConfig.SeedConfig.Resources = resources
+			}
+
+			var keyFile *breakGlassKeyFile
+			if path := viper.GetString(flagBreakGlassAPIKeyFile); path != "" {
+				secret, err := uuid.NewRandom()
+				if err != nil {
+					return err
+				}
+				initConfig.SeedConfig.BreakGlassAPIKey = secret.String()
+				keyFile, err = createBreakGlassKeyFile(path)
+				if err != nil {
+					return err
+				}
+			}
+
 			err := initializeStore(initConfig)
+			if keyFile != nil {
+				err = keyFile.finish(initConfig.SeedConfig.BreakGlassAPIKey, err)
+			}
 			if err != nil {
 				if errors.Is(err, seeds.ErrAlreadyInitialized) {
 					if viper.GetBool(flagIgnoreAlreadyInitialized) {
@@ -157,6 +251,9 @@ func InitCommand() *cobra.Command {
 	cmd.Flags().String(flagTimeout, defaultTimeout, "duration to wait before a connection attempt to etcd is considered failed (must be >= 1s)")
 	cmd.Flags().Bool(flagWait, false, "continuously retry to establish a connection to etcd until it is successful")
 	cmd.Flags().String(flagInitAdminAPIKey, "", "cluster admin API key")
+	cmd.Flags().StringSlice(flagInitAdditionalAdmins, nil, "additional cluster admin, of the form username:password (can be specified multiple times)")
+	cmd.Flags().String(flagBreakGlassAPIKeyFile, "", "generate a break-glass API key for the cluster admin, and write it to this file (- for stdout)")
+	cmd.Flags().String(flagSeedFile, "", "path to a manifest of resources (in sensuctl create format) to create after the cluster admin")
 
 	setupErr = handleConfig(cmd, os.Args[1:], false)
 
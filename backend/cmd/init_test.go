@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sensu/sensu-go/backend/seeds"
+)
+
+func TestInitConfigValidateAdditionalAdmins(t *testing.T) {
+	tests := []struct {
+		name    string
+		admins  []string
+		wantErr bool
+	}{
+		{
+			name:   "valid admins",
+			admins: []string{"alice:P@ssw0rd!", "bob:pass:with:colons"},
+		},
+		{
+			name:    "missing password",
+			admins:  []string{"alice"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate of the cluster admin",
+			admins:  []string{"admin:P@ssw0rd!"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate admins",
+			admins:  []string{"alice:a", "alice:b"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := initConfig{
+				SeedConfig: seeds.Config{
+					AdminUsername:    "admin",
+					AdminPassword:    "P@ssw0rd!",
+					AdditionalAdmins: parseAdmins(tt.admins),
+				},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBreakGlassKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+
+	keyFile, err := createBreakGlassKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keyFile.finish("secret", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := info.Mode().Perm(), os.FileMode(0600); got != want {
+		t.Errorf("bad permissions: got %v, want %v", got, want)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "secret\n"; got != want {
+		t.Errorf("bad key: got %q, want %q", got, want)
+	}
+
+	// existing files are never overwritten
+	if _, err := createBreakGlassKeyFile(path); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestBreakGlassKeyFileRemovedOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+
+	keyFile, err := createBreakGlassKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	initErr := errors.New("init failed")
+	if err := keyFile.finish("secret", initErr); err != initErr {
+		t.Fatalf("expected init error, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected key file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/replay"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagReplayAPIURL        = "api-url"
+	flagReplayAPIKey        = "api-key"
+	flagReplayFile          = "file"
+	flagReplayFromNamespace = "from-namespace"
+	flagReplayNamespace     = "namespace"
+	flagReplayID            = "id"
+	flagReplaySpeed         = "speed"
+	flagReplayKeepalives    = "keepalives"
+)
+
+// ReplayCommand is the 'sensu-backend replay' subcommand.
+func ReplayCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "replay recorded events through the event pipeline of a backend, with handlers stubbed",
+		Long: `Replay recorded events through the event pipeline of a running backend.
+
+The events are read from a capture file, such as the event log file of the
+backend (--event-log-file), or from the events stored in a namespace. They are
+replayed in an existing namespace of their own, in their recorded order, with
+the sensu.io/replay annotation: they are stored and go through the filters and
+mutators of their pipelines, but their handlers are not run. The user of the
+API key must be allowed to create replays in the namespace.`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			apiURL, _ := flags.GetString(flagReplayAPIURL)
+			apiKey, _ := flags.GetString(flagReplayAPIKey)
+			file, _ := flags.GetString(flagReplayFile)
+			fromNamespace, _ := flags.GetString(flagReplayFromNamespace)
+			namespace, _ := flags.GetString(flagReplayNamespace)
+			id, _ := flags.GetString(flagReplayID)
+			speed, _ := flags.GetFloat64(flagReplaySpeed)
+			keepalives, _ := flags.GetBool(flagReplayKeepalives)
+
+			if (file == "") == (fromNamespace == "") {
+				return fmt.Errorf("one of --%s and --%s is required", flagReplayFile, flagReplayFromNamespace)
+			}
+			if fromNamespace == namespace {
+				return fmt.Errorf("--%s must be different from --%s", flagReplayNamespace, flagReplayFromNamespace)
+			}
+			if id == "" {
+				id = fmt.Sprintf("replay-%d", time.Now().Unix())
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+
+			client := &replay.APIClient{URL: apiURL, APIKey: apiKey}
+			replayer, err := replay.New(replay.Config{
+				Publisher:  client,
+				Namespace:  namespace,
+				ID:         id,
+				Speed:      speed,
+				Keepalives: keepalives,
+			})
+			if err != nil {
+				return err
+			}
+
+			var src replay.Source
+			if file != "" {
+				var r io.Reader = os.Stdin
+				if file != "-" {
+					f, err := os.Open(file)
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+					r = f
+				}
+				src = replay.NewDecoder(r)
+			} else {
+				events, err := client.Events(ctx, fromNamespace)
+				if err != nil {
+					return err
+				}
+				// The stored events are replayed in the order in which they
+				// last occurred
+				sort.SliceStable(events, func(i, j int) bool {
+					return events[i].Timestamp < events[j].Timestamp
+				})
+				source := replay.SliceSource(events)
+				src = &source
+			}
+
+			result, err := replayer.Replay(ctx, src)
+			b, _ := json.MarshalIndent(struct {
+				ID string `json:"id"`
+				replay.Result
+			}{ID: id, Result: result}, "", "  ")
+			fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		},
+	}
+
+	apiKey := os.Getenv("SENSU_API_KEY")
+	cmd.Flags().String(flagReplayAPIURL, "http://localhost:8080", "URL of the API of the backend")
+	cmd.Flags().String(flagReplayAPIKey, apiKey, "API key used to authenticate with the backend, defaults to $SENSU_API_KEY")
+	cmd.Flags().String(flagReplayFile, "", "capture file of the events to replay, one JSON event per line, - for stdin")
+	cmd.Flags().String(flagReplayFromNamespace, "", "namespace of the stored events to replay")
+	cmd.Flags().String(flagReplayNamespace, "replay", "existing namespace in which the events are replayed")
+	cmd.Flags().String(flagReplayID, "", "ID of the replay, in the sensu.io/replay annotation of the replayed events")
+	cmd.Flags().Float64(flagReplaySpeed, 0, "speed of the replay relative to the recording, as fast as possible if 0")
+	cmd.Flags().Bool(flagReplayKeepalives, false, "replay the keepalive events")
+
+	return cmd
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -16,12 +17,24 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sensu/sensu-go/backend/apid"
 	"github.com/sensu/sensu-go/backend/apid/middlewares"
 	"github.com/sensu/sensu-go/backend/store/postgres"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/asset"
 	"github.com/sensu/sensu-go/backend"
+	"github.com/sensu/sensu-go/backend/agentd"
+	"github.com/sensu/sensu-go/backend/certmonitor"
+	"github.com/sensu/sensu-go/backend/featuregate"
+	"github.com/sensu/sensu-go/backend/jobs"
+	"github.com/sensu/sensu-go/backend/kafkabus"
+	"github.com/sensu/sensu-go/backend/mirror"
+	"github.com/sensu/sensu-go/backend/queue/jetstream"
+	"github.com/sensu/sensu-go/backend/retention"
+	"github.com/sensu/sensu-go/backend/selfmonitor"
+	"github.com/sensu/sensu-go/transport"
 	"github.com/sensu/sensu-go/util/path"
 	stringsutil "github.com/sensu/sensu-go/util/strings"
 	"github.com/sirupsen/logrus"
@@ -45,31 +58,50 @@ const (
 	environmentPrefix = "sensu_backend"
 
 	// Flag constants
-	flagConfigFile            = "config-file"
-	flagAgentHost             = "agent-host"
-	flagAgentPort             = "agent-port"
-	flagAPIListenAddress      = "api-listen-address"
-	flagAPIRequestLimit       = "api-request-limit"
-	flagAPIURL                = "api-url"
-	flagAPIWriteTimeout       = "api-write-timeout"
-	flagAssetsRateLimit       = "assets-rate-limit"
-	flagAssetsBurstLimit      = "assets-burst-limit"
-	flagDashboardHost         = "dashboard-host"
-	flagDashboardPort         = "dashboard-port"
-	flagDashboardCertFile     = "dashboard-cert-file"
-	flagDashboardKeyFile      = "dashboard-key-file"
-	flagDashboardWriteTimeout = "dashboard-write-timeout"
-	flagDeregistrationHandler = "deregistration-handler"
-	flagCacheDir              = "cache-dir"
-	flagCertFile              = "cert-file"
-	flagKeyFile               = "key-file"
-	flagTrustedCAFile         = "trusted-ca-file"
-	flagInsecureSkipTLSVerify = "insecure-skip-tls-verify"
-	flagDebug                 = "debug"
-	flagLogLevel              = "log-level"
-	flagLabels                = "labels"
-	flagAnnotations           = "annotations"
-	flagName                  = "name"
+	flagConfigFile              = "config-file"
+	flagAgentHost               = "agent-host"
+	flagAgentPort               = "agent-port"
+	flagAPIBulkRequestLimit     = "api-bulk-request-limit"
+	flagAPICORSAllowCredentials = "api-cors-allow-credentials"
+	flagAPICORSAllowedHeaders   = "api-cors-allowed-headers"
+	flagAPICORSAllowedMethods   = "api-cors-allowed-methods"
+	flagAPICORSAllowedOrigins   = "api-cors-allowed-origins"
+	flagAPIEventsRequestLimit   = "api-events-request-limit"
+	flagAPIIdempotencyWindow    = "api-idempotency-window"
+	flagAPIIdleTimeout          = "api-idle-timeout"
+	flagAPIListDeadline         = "api-list-deadline"
+	flagAPIListCacheTTL         = "api-list-cache-ttl"
+	flagAPIListenAddress        = "api-listen-address"
+	flagAPIMaxConcurrentStreams = "api-max-concurrent-streams"
+	flagAPIReadDeadline         = "api-read-deadline"
+	flagAPIRequestLimit         = "api-request-limit"
+	flagAPIShutdownTimeout      = "api-shutdown-timeout"
+	flagAPIURL                  = "api-url"
+	flagAPIWriteDeadline        = "api-write-deadline"
+	flagAPIWriteTimeout         = "api-write-timeout"
+	flagAssetsRateLimit         = "assets-rate-limit"
+	flagAssetsBurstLimit        = "assets-burst-limit"
+	flagDashboardHost           = "dashboard-host"
+	flagDashboardPort           = "dashboard-port"
+	flagDashboardCertFile       = "dashboard-cert-file"
+	flagDashboardKeyFile        = "dashboard-key-file"
+	flagDashboardWriteTimeout   = "dashboard-write-timeout"
+	flagDeregistrationHandler   = "deregistration-handler"
+	flagEntityStateHandler      = "entity-state-handler"
+	flagRoles                   = "roles"
+	flagCacheDir                = "cache-dir"
+	flagCertFile                = "cert-file"
+	flagKeyFile                 = "key-file"
+	flagTrustedCAFile           = "trusted-ca-file"
+	flagInsecureSkipTLSVerify   = "insecure-skip-tls-verify"
+	flagDebug                   = "debug"
+	flagLogLevel                = "log-level"
+	flagLabels                  = "labels"
+	flagAnnotations             = "annotations"
+	flagName                    = "name"
+	flagFeatureGates            = "feature-gates"
+	flagExtensions              = "extensions"
+	flagSecretsNamespaces       = "secrets-namespaces"
 
 	// Postgres store
 	flagPGDSN                = "pg-dsn"                  // postgresql connection string
@@ -158,23 +190,62 @@ func StartCommand(initialize InitializeFunc) *cobra.Command {
 			logrus.SetLevel(level)
 
 			cfg := &backend.Config{
-				AgentHost:             viper.GetString(flagAgentHost),
-				AgentPort:             viper.GetInt(flagAgentPort),
-				AgentWriteTimeout:     viper.GetInt(backend.FlagAgentWriteTimeout),
-				APIListenAddress:      viper.GetString(flagAPIListenAddress),
-				APIRequestLimit:       viper.GetInt64(flagAPIRequestLimit),
-				APIURL:                viper.GetString(flagAPIURL),
-				APIWriteTimeout:       viper.GetDuration(flagAPIWriteTimeout),
-				AssetsRateLimit:       rate.Limit(viper.GetFloat64(flagAssetsRateLimit)),
-				AssetsBurstLimit:      viper.GetInt(flagAssetsBurstLimit),
-				DashboardHost:         viper.GetString(flagDashboardHost),
-				DashboardPort:         viper.GetInt(flagDashboardPort),
-				DashboardTLSCertFile:  viper.GetString(flagDashboardCertFile),
-				DashboardTLSKeyFile:   viper.GetString(flagDashboardKeyFile),
-				DashboardWriteTimeout: viper.GetDuration(flagDashboardWriteTimeout),
-				DeregistrationHandler: viper.GetString(flagDeregistrationHandler),
-				CacheDir:              viper.GetString(flagCacheDir),
-				Name:                  viper.GetString(flagName),
+				AgentHost:               viper.GetString(flagAgentHost),
+				AgentPort:               viper.GetInt(flagAgentPort),
+				AgentWriteTimeout:       viper.GetInt(backend.FlagAgentWriteTimeout),
+				AgentEntityConfigRate:   viper.GetFloat64(backend.FlagAgentEntityConfigRate),
+				AgentEventRateNamespace: viper.GetFloat64(backend.FlagAgentEventRateNamespace),
+				AgentEventRateAgent:     viper.GetFloat64(backend.FlagAgentEventRateAgent),
+				AgentEventRateBackoff:   viper.GetBool(backend.FlagAgentEventRateBackoff),
+				AgentDrainWindow:        viper.GetDuration(backend.FlagAgentDrainWindow),
+				AgentThrottleSaturation: viper.GetFloat64(backend.FlagAgentThrottleSaturation),
+				AgentThrottleRate:       viper.GetFloat64(backend.FlagAgentThrottleRate),
+				AgentCheckChannelSize:   viper.GetInt(backend.FlagAgentCheckChannelSize),
+				AgentCheckChannelDrop:   viper.GetBool(backend.FlagAgentCheckChannelDrop),
+				AgentClockSkewThreshold: viper.GetDuration(backend.FlagAgentClockSkewThreshold),
+				AgentCompression:        viper.GetStringSlice(backend.FlagAgentCompression),
+				AgentMaxEventSize:       viper.GetInt(backend.FlagAgentMaxEventSize),
+				AgentPingInterval:       viper.GetDuration(backend.FlagAgentPingInterval),
+				AgentMinProtocolVersion: viper.GetInt(backend.FlagAgentMinProtocolVersion),
+				AgentDeadLetterSize:     viper.GetInt(backend.FlagAgentDeadLetterSize),
+				AgentDeadLetterInterval: viper.GetDuration(backend.FlagAgentDeadLetterInterval),
+				AgentConfigAuditSize:    viper.GetInt(backend.FlagAgentConfigAuditSize),
+				AgentTLSReloadInterval:  viper.GetDuration(backend.FlagAgentTLSReloadInterval),
+				AgentGRPCPort:           viper.GetInt(backend.FlagAgentGRPCPort),
+				AgentProxyProtocol:      viper.GetBool(backend.FlagAgentProxyProtocol),
+				AgentAdmissionURL:       viper.GetString(backend.FlagAgentAdmissionURL),
+				AgentAdmissionTimeout:   viper.GetDuration(backend.FlagAgentAdmissionTimeout),
+				AgentAdmissionFailOpen:  viper.GetBool(backend.FlagAgentAdmissionFailOpen),
+				APIBulkRequestLimit:     viper.GetInt64(flagAPIBulkRequestLimit),
+				APICORSAllowCredentials: viper.GetBool(flagAPICORSAllowCredentials),
+				APICORSAllowedHeaders:   viper.GetStringSlice(flagAPICORSAllowedHeaders),
+				APICORSAllowedMethods:   viper.GetStringSlice(flagAPICORSAllowedMethods),
+				APICORSAllowedOrigins:   viper.GetStringSlice(flagAPICORSAllowedOrigins),
+				APIEventsRequestLimit:   viper.GetInt64(flagAPIEventsRequestLimit),
+				APIIdempotencyWindow:    viper.GetDuration(flagAPIIdempotencyWindow),
+				APIIdleTimeout:          viper.GetDuration(flagAPIIdleTimeout),
+				APIListCacheTTL:         viper.GetDuration(flagAPIListCacheTTL),
+				APIListDeadline:         viper.GetDuration(flagAPIListDeadline),
+				APIListenAddress:        viper.GetString(flagAPIListenAddress),
+				APIMaxConcurrentStreams: viper.GetUint32(flagAPIMaxConcurrentStreams),
+				APIReadDeadline:         viper.GetDuration(flagAPIReadDeadline),
+				APIRequestLimit:         viper.GetInt64(flagAPIRequestLimit),
+				APIShutdownTimeout:      viper.GetDuration(flagAPIShutdownTimeout),
+				APIURL:                  viper.GetString(flagAPIURL),
+				APIWriteDeadline:        viper.GetDuration(flagAPIWriteDeadline),
+				APIWriteTimeout:         viper.GetDuration(flagAPIWriteTimeout),
+				AssetsRateLimit:         rate.Limit(viper.GetFloat64(flagAssetsRateLimit)),
+				AssetsBurstLimit:        viper.GetInt(flagAssetsBurstLimit),
+				DashboardHost:           viper.GetString(flagDashboardHost),
+				DashboardPort:           viper.GetInt(flagDashboardPort),
+				DashboardTLSCertFile:    viper.GetString(flagDashboardCertFile),
+				DashboardTLSKeyFile:     viper.GetString(flagDashboardKeyFile),
+				DashboardWriteTimeout:   viper.GetDuration(flagDashboardWriteTimeout),
+				DeregistrationHandler:   viper.GetString(flagDeregistrationHandler),
+				EntityStateHandler:      viper.GetString(flagEntityStateHandler),
+				CacheDir:                viper.GetString(flagCacheDir),
+				Name:                    viper.GetString(flagName),
+				StartupRetryTimeout:     viper.GetDuration(backend.FlagStartupRetryTimeout),
 
 				Labels:                         viper.GetStringMapString(flagLabels),
 				Annotations:                    viper.GetStringMapString(flagAnnotations),
@@ -185,6 +256,8 @@ func StartCommand(initialize InitializeFunc) *cobra.Command {
 				EventLogBufferWait:             viper.GetDuration(flagEventLogBufferWait),
 				EventLogFile:                   viper.GetString(flagEventLogFile),
 				EventLogParallelEncoders:       viper.GetBool(flagEventLogParallelEncoders),
+				AgentSessionLimitBackend:       viper.GetInt(backend.FlagAgentSessionLimitBackend),
+				AgentSessionLimitNamespace:     viper.GetInt(backend.FlagAgentSessionLimitNamespace),
 
 				Store: backend.StoreConfig{
 					PostgresStore: postgres.Config{
@@ -199,6 +272,22 @@ func StartCommand(initialize InitializeFunc) *cobra.Command {
 				return errors.New("cache dir not set")
 			}
 
+			cfg.Roles = viper.GetStringSlice(flagRoles)
+			if err := backend.ValidateRoles(cfg.Roles); err != nil {
+				return fmt.Errorf("--%s: %w", flagRoles, err)
+			}
+
+			cfg.Extensions = viper.GetStringSlice(flagExtensions)
+			cfg.SecretsNamespaces = viper.GetStringSlice(flagSecretsNamespaces)
+
+			cfg.FeatureGates = featuregate.NewDefault()
+			if err := cfg.FeatureGates.Set(viper.GetString(flagFeatureGates)); err != nil {
+				return fmt.Errorf("--%s: %w", flagFeatureGates, err)
+			}
+			if gates := cfg.FeatureGates.String(); gates != "" {
+				logger.WithField("feature_gates", gates).Warn("feature gates set")
+			}
+
 			if flag := cmd.Flags().Lookup(flagLabels); flag != nil && flag.Changed {
 				cfg.Labels = labels
 			}
@@ -279,8 +368,9 @@ func newPostgresPool(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
 		return nil, err
 	}
 
-	// Create the event store, which runs on top of postgres
-	db, err := postgres.Open(ctx, pgxConfig, true)
+	// Create the pool lazily, the store daemon of the backend migrates the
+	// database once it is reachable, while apid answers in degraded mode
+	db, err := pgxpool.NewWithConfig(ctx, pgxConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -318,9 +408,23 @@ func handleConfig(cmd *cobra.Command, arguments []string, server bool) error {
 		// Flag defaults
 		viper.SetDefault(flagAgentHost, "[::]")
 		viper.SetDefault(flagAgentPort, 8081)
+		viper.SetDefault(flagAPIBulkRequestLimit, middlewares.DefaultBulkBytesLimit)
+		viper.SetDefault(flagAPICORSAllowCredentials, false)
+		viper.SetDefault(flagAPICORSAllowedHeaders, middlewares.DefaultCORSAllowedHeaders)
+		viper.SetDefault(flagAPICORSAllowedMethods, middlewares.DefaultCORSAllowedMethods)
+		viper.SetDefault(flagAPICORSAllowedOrigins, []string{})
+		viper.SetDefault(flagAPIEventsRequestLimit, middlewares.DefaultEventsBytesLimit)
+		viper.SetDefault(flagAPIIdempotencyWindow, apid.DefaultIdempotencyWindow)
+		viper.SetDefault(flagAPIIdleTimeout, apid.DefaultIdleTimeout)
+		viper.SetDefault(flagAPIListCacheTTL, "2s")
+		viper.SetDefault(flagAPIListDeadline, middlewares.DefaultListDeadline)
 		viper.SetDefault(flagAPIListenAddress, "[::]:8080")
+		viper.SetDefault(flagAPIMaxConcurrentStreams, apid.DefaultMaxConcurrentStreams)
+		viper.SetDefault(flagAPIReadDeadline, middlewares.DefaultReadDeadline)
 		viper.SetDefault(flagAPIRequestLimit, middlewares.MaxBytesLimit)
+		viper.SetDefault(flagAPIShutdownTimeout, apid.DefaultShutdownTimeout)
 		viper.SetDefault(flagAPIURL, "http://localhost:8080")
+		viper.SetDefault(flagAPIWriteDeadline, middlewares.DefaultWriteDeadline)
 		viper.SetDefault(flagAPIWriteTimeout, "15s")
 		viper.SetDefault(flagAssetsRateLimit, asset.DefaultAssetsRateLimit)
 		viper.SetDefault(flagAssetsBurstLimit, asset.DefaultAssetsBurstLimit)
@@ -330,6 +434,10 @@ func handleConfig(cmd *cobra.Command, arguments []string, server bool) error {
 		viper.SetDefault(flagDashboardKeyFile, "")
 		viper.SetDefault(flagDashboardWriteTimeout, "15s")
 		viper.SetDefault(flagDeregistrationHandler, "")
+		viper.SetDefault(flagEntityStateHandler, "")
+		viper.SetDefault(flagRoles, []string{})
+		viper.SetDefault(flagExtensions, []string{})
+		viper.SetDefault(flagSecretsNamespaces, []string{})
 		viper.SetDefault(flagCertFile, "")
 		viper.SetDefault(flagKeyFile, "")
 		viper.SetDefault(flagTrustedCAFile, "")
@@ -337,11 +445,67 @@ func handleConfig(cmd *cobra.Command, arguments []string, server bool) error {
 		viper.SetDefault(flagLogLevel, "warn")
 		viper.SetDefault(backend.FlagEventdWorkers, 100)
 		viper.SetDefault(backend.FlagEventdBufferSize, 1000)
+		viper.SetDefault(backend.FlagEventdMaxAnnotationsSize, 0)
+		viper.SetDefault(backend.FlagEventdMaxLabelsSize, 0)
 		viper.SetDefault(backend.FlagKeepalivedWorkers, 100)
 		viper.SetDefault(backend.FlagKeepalivedBufferSize, 1000)
 		viper.SetDefault(backend.FlagPipelinedWorkers, 100)
 		viper.SetDefault(backend.FlagPipelinedBufferSize, 1000)
+		viper.SetDefault(backend.FlagPipelinedNamespaceWorkers, 0)
+		viper.SetDefault(backend.FlagPipelinedNamespaceBudget, 0)
+		viper.SetDefault(backend.FlagAllowPipeMutatorTests, false)
+		viper.SetDefault(backend.FlagJobsWorkers, jobs.DefaultWorkers)
+		viper.SetDefault(backend.FlagJobsMaxAttempts, jobs.DefaultMaxAttempts)
+		viper.SetDefault(backend.FlagRetentionInterval, retention.DefaultInterval)
+		viper.SetDefault(backend.FlagMirrorURL, "")
+		viper.SetDefault(backend.FlagMirrorAPIKey, "")
+		viper.SetDefault(backend.FlagMirrorTrustedCAFile, "")
+		viper.SetDefault(backend.FlagMirrorInsecureSkipTLSVerify, false)
+		viper.SetDefault(backend.FlagMirrorNamespaces, []string{})
+		viper.SetDefault(backend.FlagMirrorLabels, []string{})
+		viper.SetDefault(backend.FlagMirrorBatchSize, mirror.DefaultBatchSize)
+		viper.SetDefault(backend.FlagMirrorFlushInterval, mirror.DefaultFlushInterval)
+		viper.SetDefault(backend.FlagBusDriver, backend.BusDriverWizard)
+		viper.SetDefault(backend.FlagBusNATSURL, "")
+		viper.SetDefault(backend.FlagBusNATSMaxAge, jetstream.DefaultMaxAge)
+		viper.SetDefault(backend.FlagBusKafkaBrokers, []string{})
+		viper.SetDefault(backend.FlagBusKafkaTopicPrefix, kafkabus.DefaultTopicPrefix)
+		viper.SetDefault(backend.FlagCertExpiryWarningThreshold, certmonitor.DefaultWarningThreshold)
+		viper.SetDefault(backend.FlagCertExpiryCriticalThreshold, certmonitor.DefaultCriticalThreshold)
+		viper.SetDefault(backend.FlagSelfMonitoringThreshold, selfmonitor.DefaultThreshold)
+		viper.SetDefault(backend.FlagSchedulerPublishRate, 0)
+		viper.SetDefault(backend.FlagSchedulerDispatchWindow, 0)
+		viper.SetDefault(backend.FlagSchedulerPrioritySubscriptions, []string{})
+		viper.SetDefault(backend.FlagStoreMaxRetries, storev2.DefaultStoreMaxRetries)
+		viper.SetDefault(backend.FlagStoreBreakerThreshold, storev2.DefaultStoreBreakerThreshold)
+		viper.SetDefault(backend.FlagStoreBreakerCooldown, storev2.DefaultStoreBreakerCooldown)
 		viper.SetDefault(backend.FlagAgentWriteTimeout, 15)
+		viper.SetDefault(backend.FlagAgentEntityConfigRate, 0)
+		viper.SetDefault(backend.FlagAgentEventRateNamespace, 0)
+		viper.SetDefault(backend.FlagAgentEventRateAgent, 0)
+		viper.SetDefault(backend.FlagAgentEventRateBackoff, false)
+		viper.SetDefault(backend.FlagAgentDrainWindow, agentd.DefaultDrainWindow)
+		viper.SetDefault(backend.FlagAgentThrottleSaturation, 0)
+		viper.SetDefault(backend.FlagAgentThrottleRate, 1)
+		viper.SetDefault(backend.FlagAgentCheckChannelSize, agentd.DefaultCheckChannelSize)
+		viper.SetDefault(backend.FlagAgentCheckChannelDrop, false)
+		viper.SetDefault(backend.FlagAgentClockSkewThreshold, agentd.DefaultClockSkewThreshold)
+		viper.SetDefault(backend.FlagAgentCompression, transport.Compressions)
+		viper.SetDefault(backend.FlagAgentMaxEventSize, 0)
+		viper.SetDefault(backend.FlagAgentPingInterval, agentd.DefaultPingInterval)
+		viper.SetDefault(backend.FlagAgentMinProtocolVersion, transport.ProtocolVersionLegacy)
+		viper.SetDefault(backend.FlagAgentDeadLetterSize, agentd.DefaultDeadLetterQueueSize)
+		viper.SetDefault(backend.FlagAgentDeadLetterInterval, agentd.DefaultDeadLetterRetryInterval)
+		viper.SetDefault(backend.FlagAgentConfigAuditSize, 0)
+		viper.SetDefault(backend.FlagAgentTLSReloadInterval, agentd.DefaultTLSReloadInterval)
+		viper.SetDefault(backend.FlagAgentGRPCPort, 0)
+		viper.SetDefault(backend.FlagAgentProxyProtocol, false)
+		viper.SetDefault(backend.FlagAgentAdmissionURL, "")
+		viper.SetDefault(backend.FlagAgentAdmissionTimeout, agentd.DefaultAdmissionTimeout)
+		viper.SetDefault(backend.FlagAgentAdmissionFailOpen, false)
+		viper.SetDefault(backend.FlagAgentSessionLimitBackend, 0)
+		viper.SetDefault(backend.FlagAgentSessionLimitNamespace, 0)
+		viper.SetDefault(backend.FlagStartupRetryTimeout, 5*time.Minute)
 		viper.SetDefault(flagDisablePlatformMetrics, defaultDisablePlatformMetrics)
 		viper.SetDefault(flagPlatformMetricsLoggingInterval, defaultPlatformMetricsLoggingInterval)
 		viper.SetDefault(flagPlatformMetricsLogFile, defaultPlatformMetricsLogFile)
@@ -351,6 +515,7 @@ func handleConfig(cmd *cobra.Command, arguments []string, server bool) error {
 		viper.SetDefault(flagEventLogParallelEncoders, false)
 		viper.SetDefault(flagEventCacheWriteLimit, 1000)
 		viper.SetDefault(flagDisableEventCache, false)
+		viper.SetDefault(flagFeatureGates, "")
 
 		backendName, err := os.Hostname()
 		if err != nil {
@@ -365,9 +530,12 @@ func handleConfig(cmd *cobra.Command, arguments []string, server bool) error {
 	flags := flagSet(server)
 	cmd.Flags().AddFlagSet(flags)
 
-	// Load the configuration file but only error out if flagConfigFile is used
-	if err := viper.ReadInConfig(); err != nil && configFilePathIsDefined {
-		return err
+	// Load the configuration file but only error out if flagConfigFile is used,
+	// or if the default configuration file exists but is invalid
+	if err := loadConfigFile(configFilePath, cmd.Flags()); err != nil {
+		if configFilePathIsDefined || !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
 	}
 
 	viper.SetEnvPrefix(environmentPrefix)
@@ -418,10 +586,25 @@ func flagSet(server bool) *pflag.FlagSet {
 		// Main Flags
 		flagSet.String(flagName, viper.GetString(flagName), "backend name")
 		flagSet.String(flagAgentHost, viper.GetString(flagAgentHost), "agent listener host")
+		flagSet.String(flagFeatureGates, viper.GetString(flagFeatureGates), "comma-separated list of Feature=bool pairs that enable or disable experimental features")
 		flagSet.Int(flagAgentPort, viper.GetInt(flagAgentPort), "agent listener port")
+		flagSet.Int64(flagAPIBulkRequestLimit, viper.GetInt64(flagAPIBulkRequestLimit), "maximum body size of the API requests that create or update resources in bulk, in bytes")
+		flagSet.Bool(flagAPICORSAllowCredentials, viper.GetBool(flagAPICORSAllowCredentials), "allow credentials in cross-origin API requests")
+		flagSet.StringSlice(flagAPICORSAllowedHeaders, viper.GetStringSlice(flagAPICORSAllowedHeaders), "headers allowed in cross-origin API requests")
+		flagSet.StringSlice(flagAPICORSAllowedMethods, viper.GetStringSlice(flagAPICORSAllowedMethods), "methods allowed in cross-origin API requests")
+		flagSet.StringSlice(flagAPICORSAllowedOrigins, viper.GetStringSlice(flagAPICORSAllowedOrigins), "origins allowed to make cross-origin API requests, * allows any origin (disabled when empty)")
+		flagSet.Int64(flagAPIEventsRequestLimit, viper.GetInt64(flagAPIEventsRequestLimit), "maximum body size of the API requests that create or update events, in bytes")
+		flagSet.Duration(flagAPIIdempotencyWindow, viper.GetDuration(flagAPIIdempotencyWindow), "duration during which the event submissions with the same Idempotency-Key header are only processed once, 0 to ignore the header")
+		flagSet.Duration(flagAPIIdleTimeout, viper.GetDuration(flagAPIIdleTimeout), "maximum duration for which idle keep-alive connections are kept open")
+		flagSet.Duration(flagAPIListCacheTTL, viper.GetDuration(flagAPIListCacheTTL), "duration for which entity and event list responses are cached, 0 to disable caching")
+		flagSet.Duration(flagAPIListDeadline, viper.GetDuration(flagAPIListDeadline), "maximum duration of the API requests that list resources, 0 for no deadline")
 		flagSet.String(flagAPIListenAddress, viper.GetString(flagAPIListenAddress), "address to listen on for api traffic")
+		flagSet.Uint32(flagAPIMaxConcurrentStreams, viper.GetUint32(flagAPIMaxConcurrentStreams), "maximum number of concurrent HTTP/2 streams per connection")
+		flagSet.Duration(flagAPIReadDeadline, viper.GetDuration(flagAPIReadDeadline), "maximum duration of the API requests that get a resource, 0 for no deadline")
 		flagSet.Int64(flagAPIRequestLimit, viper.GetInt64(flagAPIRequestLimit), "maximum API request body size, in bytes")
+		flagSet.Duration(flagAPIShutdownTimeout, viper.GetDuration(flagAPIShutdownTimeout), "maximum duration given to open connections to drain when shutting down")
 		flagSet.String(flagAPIURL, viper.GetString(flagAPIURL), "url of the api to connect to")
+		flagSet.Duration(flagAPIWriteDeadline, viper.GetDuration(flagAPIWriteDeadline), "maximum duration of the API requests that create, update or delete resources, 0 for no deadline")
 		flagSet.Duration(flagAPIWriteTimeout, viper.GetDuration(flagAPIWriteTimeout), "maximum duration before timing out writes of responses")
 		flagSet.Float64(flagAssetsRateLimit, viper.GetFloat64(flagAssetsRateLimit), "maximum number of assets fetched per second")
 		flagSet.Int(flagAssetsBurstLimit, viper.GetInt(flagAssetsBurstLimit), "asset fetch burst limit")
@@ -431,6 +614,10 @@ func flagSet(server bool) *pflag.FlagSet {
 		flagSet.String(flagDashboardKeyFile, viper.GetString(flagDashboardKeyFile), "dashboard TLS certificate key in PEM format")
 		flagSet.Duration(flagDashboardWriteTimeout, viper.GetDuration(flagDashboardWriteTimeout), "maximum duration before timing out writes of responses")
 		flagSet.String(flagDeregistrationHandler, viper.GetString(flagDeregistrationHandler), "default deregistration handler")
+		flagSet.String(flagEntityStateHandler, viper.GetString(flagEntityStateHandler), "handler of entity state change events, no events are emitted if empty")
+		flagSet.StringSlice(flagRoles, viper.GetStringSlice(flagRoles), fmt.Sprintf("roles run by the backend %v, every role if empty", backend.Roles))
+		flagSet.StringSlice(flagExtensions, viper.GetStringSlice(flagExtensions), "extensions enabled in the backend, every extension compiled into the backend if empty")
+		flagSet.StringSlice(flagSecretsNamespaces, viper.GetStringSlice(flagSecretsNamespaces), "namespaces allowed to use the secrets of the env secrets provider, none if empty")
 		flagSet.String(flagCacheDir, viper.GetString(flagCacheDir), "path to store cached data")
 		flagSet.String(flagCertFile, viper.GetString(flagCertFile), "TLS certificate in PEM format")
 		flagSet.String(flagKeyFile, viper.GetString(flagKeyFile), "TLS certificate key in PEM format")
@@ -440,11 +627,67 @@ func flagSet(server bool) *pflag.FlagSet {
 		flagSet.String(flagLogLevel, viper.GetString(flagLogLevel), "logging level [panic, fatal, error, warn, info, debug, trace]")
 		flagSet.Int(backend.FlagEventdWorkers, viper.GetInt(backend.FlagEventdWorkers), "number of workers spawned for processing incoming events")
 		flagSet.Int(backend.FlagEventdBufferSize, viper.GetInt(backend.FlagEventdBufferSize), "number of incoming events that can be buffered")
+		flagSet.Int(backend.FlagEventdMaxAnnotationsSize, viper.GetInt(backend.FlagEventdMaxAnnotationsSize), "size budget, in bytes, of the annotations of each event and of its check, beyond which they are pruned (0 for unlimited)")
+		flagSet.Int(backend.FlagEventdMaxLabelsSize, viper.GetInt(backend.FlagEventdMaxLabelsSize), "size budget, in bytes, of the labels of each event and of its check, beyond which they are pruned (0 for unlimited)")
 		flagSet.Int(backend.FlagKeepalivedWorkers, viper.GetInt(backend.FlagKeepalivedWorkers), "number of workers spawned for processing incoming keepalives")
 		flagSet.Int(backend.FlagKeepalivedBufferSize, viper.GetInt(backend.FlagKeepalivedBufferSize), "number of incoming keepalives that can be buffered")
 		flagSet.Int(backend.FlagPipelinedWorkers, viper.GetInt(backend.FlagPipelinedWorkers), "number of workers spawned for handling events through the event pipeline")
 		flagSet.Int(backend.FlagPipelinedBufferSize, viper.GetInt(backend.FlagPipelinedBufferSize), "number of events to handle that can be buffered")
+		flagSet.Int(backend.FlagPipelinedNamespaceWorkers, viper.GetInt(backend.FlagPipelinedNamespaceWorkers), "number of workers spawned for handling the events of each namespace, 0 to share the pipelined workers between namespaces")
+		flagSet.Duration(backend.FlagPipelinedNamespaceBudget, viper.GetDuration(backend.FlagPipelinedNamespaceBudget), "maximum duration of the handling of an event by the workers of a namespace, 0 for no limit")
+		flagSet.Bool(backend.FlagAllowPipeMutatorTests, viper.GetBool(backend.FlagAllowPipeMutatorTests), "allow the mutator test API to run pipe mutators, which execute their command on the backend")
+		flagSet.Int(backend.FlagJobsWorkers, viper.GetInt(backend.FlagJobsWorkers), "number of background jobs run concurrently by the backend")
+		flagSet.Int(backend.FlagJobsMaxAttempts, viper.GetInt(backend.FlagJobsMaxAttempts), "number of attempts of a background job before it fails")
+		flagSet.Duration(backend.FlagRetentionInterval, viper.GetDuration(backend.FlagRetentionInterval), "interval of the enforcement of the retention policies of the namespaces")
+		flagSet.String(backend.FlagMirrorURL, viper.GetString(backend.FlagMirrorURL), "URL of the API of the peer cluster to which the processed events are mirrored (disabled when empty)")
+		flagSet.String(backend.FlagMirrorAPIKey, viper.GetString(backend.FlagMirrorAPIKey), "API key with which the events are mirrored to the peer cluster")
+		flagSet.String(backend.FlagMirrorTrustedCAFile, viper.GetString(backend.FlagMirrorTrustedCAFile), "path to the CA certificate of the API of the peer cluster")
+		flagSet.Bool(backend.FlagMirrorInsecureSkipTLSVerify, viper.GetBool(backend.FlagMirrorInsecureSkipTLSVerify), "skip the verification of the certificate of the API of the peer cluster")
+		flagSet.StringSlice(backend.FlagMirrorNamespaces, viper.GetStringSlice(backend.FlagMirrorNamespaces), "namespaces of the mirrored events, every namespace when empty")
+		flagSet.StringSlice(backend.FlagMirrorLabels, viper.GetStringSlice(backend.FlagMirrorLabels), "key=value labels the mirrored events must have, on themselves or on their entity")
+		flagSet.Int(backend.FlagMirrorBatchSize, viper.GetInt(backend.FlagMirrorBatchSize), "number of events mirrored at once to the peer cluster")
+		flagSet.Duration(backend.FlagMirrorFlushInterval, viper.GetDuration(backend.FlagMirrorFlushInterval), "interval at which the incomplete batches of events are mirrored to the peer cluster")
+		flagSet.String(backend.FlagBusDriver, viper.GetString(backend.FlagBusDriver), "driver of the message bus shared with the other backends: wizard, bridged with postgres when the backend runs a subset of the roles, jetstream, which keeps the messages across the restarts of the backends, or kafka, which writes the messages of the pipeline and of the agent sessions to Kafka topics")
+		flagSet.String(backend.FlagBusNATSURL, viper.GetString(backend.FlagBusNATSURL), "URL of the NATS server of the jetstream bus driver, or comma-separated URLs of the servers of a cluster")
+		flagSet.Duration(backend.FlagBusNATSMaxAge, viper.GetDuration(backend.FlagBusNATSMaxAge), "how long the JetStream streams of the bus keep their messages")
+		flagSet.StringSlice(backend.FlagBusKafkaBrokers, viper.GetStringSlice(backend.FlagBusKafkaBrokers), "addresses of the Kafka brokers of the kafka bus driver")
+		flagSet.String(backend.FlagBusKafkaTopicPrefix, viper.GetString(backend.FlagBusKafkaTopicPrefix), "prefix of the Kafka topics and consumer groups of the kafka bus driver")
+		flagSet.Duration(backend.FlagCertExpiryWarningThreshold, viper.GetDuration(backend.FlagCertExpiryWarningThreshold), "time before their expiry from which the TLS certificates of the backend and of the agents are reported by a warning event of the backend entity, 0 to disable the monitoring of the certificates")
+		flagSet.Duration(backend.FlagCertExpiryCriticalThreshold, viper.GetDuration(backend.FlagCertExpiryCriticalThreshold), "time before their expiry from which the TLS certificates of the backend and of the agents are reported by a critical event of the backend entity")
+		flagSet.Int(backend.FlagSelfMonitoringThreshold, viper.GetInt(backend.FlagSelfMonitoringThreshold), "number of internal errors in a minute, such as internal store errors and message bus publish failures, from which they are reported by a critical event of the backend entity in the sensu-system namespace, 0 to disable the self-monitoring events")
+		flagSet.Float64(backend.FlagSchedulerPublishRate, viper.GetFloat64(backend.FlagSchedulerPublishRate), "maximum number of check requests published per second by the backend, 0 for no limit")
+		flagSet.Duration(backend.FlagSchedulerDispatchWindow, viper.GetDuration(backend.FlagSchedulerDispatchWindow), "window over which the executions of the checks scheduled at the same time are spread with a random jitter, shorter than the check intervals, 0 for no jitter")
+		flagSet.StringSlice(backend.FlagSchedulerPrioritySubscriptions, viper.GetStringSlice(backend.FlagSchedulerPrioritySubscriptions), "subscriptions, or subscription patterns with *, whose check requests are published first when the publish rate is limited, like those of the critical entities")
+		flagSet.Int(backend.FlagStoreMaxRetries, viper.GetInt(backend.FlagStoreMaxRetries), "number of times the store operations of agentd, eventd and keepalived are retried after a transient error (0 to disable the retries)")
+		flagSet.Int(backend.FlagStoreBreakerThreshold, viper.GetInt(backend.FlagStoreBreakerThreshold), "number of consecutive transient store errors that open the store circuit breaker (0 to disable the circuit breaker)")
+		flagSet.Duration(backend.FlagStoreBreakerCooldown, viper.GetDuration(backend.FlagStoreBreakerCooldown), "duration the store circuit breaker stays open before it probes the store")
 		flagSet.Int(backend.FlagAgentWriteTimeout, viper.GetInt(backend.FlagAgentWriteTimeout), "timeout in seconds for agent writes")
+		flagSet.Float64(backend.FlagAgentEntityConfigRate, viper.GetFloat64(backend.FlagAgentEntityConfigRate), "maximum number of entity config updates pushed to agents per second, 0 for no limit")
+		flagSet.Float64(backend.FlagAgentEventRateNamespace, viper.GetFloat64(backend.FlagAgentEventRateNamespace), "maximum number of events received per second from the agents of a namespace connected to the backend, 0 for no limit")
+		flagSet.Float64(backend.FlagAgentEventRateAgent, viper.GetFloat64(backend.FlagAgentEventRateAgent), "maximum number of events received per second from an agent, 0 for no limit")
+		flagSet.Bool(backend.FlagAgentEventRateBackoff, viper.GetBool(backend.FlagAgentEventRateBackoff), "ask the agents whose events are rate limited to back off")
+		flagSet.Duration(backend.FlagAgentDrainWindow, viper.GetDuration(backend.FlagAgentDrainWindow), "duration over which the agent sessions are closed when they are drained, on shutdown (up to 20s) or through the API")
+		flagSet.Float64(backend.FlagAgentThrottleSaturation, viper.GetFloat64(backend.FlagAgentThrottleSaturation), "saturation of the backend, between 0 and 1, from which the agents are asked to throttle their events, 0 to never throttle them")
+		flagSet.Float64(backend.FlagAgentThrottleRate, viper.GetFloat64(backend.FlagAgentThrottleRate), "maximum number of events sent per second by each agent while it is throttled")
+		flagSet.Int(backend.FlagAgentCheckChannelSize, viper.GetInt(backend.FlagAgentCheckChannelSize), "number of check requests buffered for each agent session")
+		flagSet.Bool(backend.FlagAgentCheckChannelDrop, viper.GetBool(backend.FlagAgentCheckChannelDrop), "drop the check requests sent to an agent session whose buffer is full, instead of blocking the message bus")
+		flagSet.Duration(backend.FlagAgentClockSkewThreshold, viper.GetDuration(backend.FlagAgentClockSkewThreshold), "clock skew of the agents from which a warning event is published for their entity, 0 to not check it")
+		flagSet.StringSlice(backend.FlagAgentCompression, viper.GetStringSlice(backend.FlagAgentCompression), "comma-delimited list of the compression algorithms accepted for the messages of the agent sessions [zstd, deflate]")
+		flagSet.Int(backend.FlagAgentMaxEventSize, viper.GetInt(backend.FlagAgentMaxEventSize), "maximum size of the events received from the agents, in bytes, 0 for no limit")
+		flagSet.Duration(backend.FlagAgentPingInterval, viper.GetDuration(backend.FlagAgentPingInterval), "interval at which the agent sessions ping their agent to measure the round-trip time of their connection, 0 to not ping them")
+		flagSet.Int(backend.FlagAgentMinProtocolVersion, viper.GetInt(backend.FlagAgentMinProtocolVersion), "oldest protocol version served to the agents, agents that only speak older versions are rejected")
+		flagSet.Int(backend.FlagAgentDeadLetterSize, viper.GetInt(backend.FlagAgentDeadLetterSize), "number of agent events that could not be published held to be published again, 0 to drop them")
+		flagSet.Duration(backend.FlagAgentDeadLetterInterval, viper.GetDuration(backend.FlagAgentDeadLetterInterval), "interval at which the agent events that could not be published are published again, 0 to only publish them again through the API")
+		flagSet.Int(backend.FlagAgentConfigAuditSize, viper.GetInt(backend.FlagAgentConfigAuditSize), "number of entity config updates sent to the agents by all backends kept in the config audit log, 0 to record none")
+		flagSet.Duration(backend.FlagAgentTLSReloadInterval, viper.GetDuration(backend.FlagAgentTLSReloadInterval), "interval at which the agentd TLS certificate, key and CA files are checked for changes, to reload them without closing the agent sessions, 0 to only reload them on SIGHUP")
+		flagSet.Int(backend.FlagAgentGRPCPort, viper.GetInt(backend.FlagAgentGRPCPort), "port on which the agents connect over gRPC streams, with the grpc:// or grpcs:// backend URLs, 0 to disable it")
+		flagSet.Bool(backend.FlagAgentProxyProtocol, viper.GetBool(backend.FlagAgentProxyProtocol), "read the agent addresses from the PROXY protocol header (v1 or v2) sent by the load balancer in front of agentd, closing the connections without one")
+		flagSet.String(backend.FlagAgentAdmissionURL, viper.GetString(backend.FlagAgentAdmissionURL), "URL of the admission webhook that reviews the agent connections when their session is established, to reject them or change their subscriptions")
+		flagSet.Duration(backend.FlagAgentAdmissionTimeout, viper.GetDuration(backend.FlagAgentAdmissionTimeout), "timeout of the calls to the agent admission webhook")
+		flagSet.Bool(backend.FlagAgentAdmissionFailOpen, viper.GetBool(backend.FlagAgentAdmissionFailOpen), "accept the agent connections when the admission webhook fails, instead of rejecting them")
+		flagSet.Int(backend.FlagAgentSessionLimitBackend, viper.GetInt(backend.FlagAgentSessionLimitBackend), "maximum number of concurrent agent sessions of the backend, 0 for no limit")
+		flagSet.Int(backend.FlagAgentSessionLimitNamespace, viper.GetInt(backend.FlagAgentSessionLimitNamespace), "maximum number of concurrent sessions of the agents of a namespace on the backend, 0 for no limit")
+		flagSet.Duration(backend.FlagStartupRetryTimeout, viper.GetDuration(backend.FlagStartupRetryTimeout), "how long the start of the restartable backend daemons, like the store, is retried with the API in degraded read-only mode before the backend fails to start, 0 to fail on the first error")
 		flagSet.String(backend.FlagJWTPrivateKeyFile, viper.GetString(backend.FlagJWTPrivateKeyFile), "path to the PEM-encoded private key to use to sign JWTs")
 		flagSet.String(backend.FlagJWTPublicKeyFile, viper.GetString(backend.FlagJWTPublicKeyFile), "path to the PEM-encoded public key to use to verify JWT signatures")
 		flagSet.StringToStringVar(&labels, flagLabels, nil, "entity labels map")
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/featuregate"
 	"github.com/sensu/sensu-go/backend/licensing"
 	"github.com/sensu/sensu-go/backend/store/postgres"
 	"golang.org/x/time/rate"
@@ -14,6 +15,12 @@ const (
 	FlagEventdWorkers = "eventd-workers"
 	// FlagEventdBufferSize defines the buffer size for eventd
 	FlagEventdBufferSize = "eventd-buffer-size"
+	// FlagEventdMaxAnnotationsSize defines the size budget, in bytes, of the
+	// annotations of the events and of their checks
+	FlagEventdMaxAnnotationsSize = "eventd-max-annotations-size"
+	// FlagEventdMaxLabelsSize defines the size budget, in bytes, of the
+	// labels of the events and of their checks
+	FlagEventdMaxLabelsSize = "eventd-max-labels-size"
 	// FlagKeepalivedWorkers defines the number of workers for keepalived
 	FlagKeepalivedWorkers = "keepalived-workers"
 	// FlagKeepalivedBufferSize defines buffer size for keepalived
@@ -22,11 +29,204 @@ const (
 	FlagPipelinedWorkers = "pipelined-workers"
 	// FlagPipelinedBufferSize defines the buffer size for pipelined
 	FlagPipelinedBufferSize = "pipelined-buffer-size"
+	// FlagPipelinedNamespaceWorkers defines the number of pipelined workers
+	// of each namespace
+	FlagPipelinedNamespaceWorkers = "pipelined-namespace-workers"
+	// FlagPipelinedNamespaceBudget defines the maximum duration of the
+	// handling of an event by the pipelined workers of a namespace
+	FlagPipelinedNamespaceBudget = "pipelined-namespace-budget"
+	// FlagAllowPipeMutatorTests allows the mutator test API to run pipe
+	// mutators, which execute commands on the backend
+	FlagAllowPipeMutatorTests = "allow-pipe-mutator-tests"
+
+	// FlagJobsWorkers defines the number of background jobs run concurrently
+	// by a backend
+	FlagJobsWorkers = "jobs-workers"
+	// FlagJobsMaxAttempts defines the number of attempts of a background job
+	FlagJobsMaxAttempts = "jobs-max-attempts"
+
+	// FlagRetentionInterval defines the interval of the enforcement of the
+	// retention policies of the namespaces
+	FlagRetentionInterval = "retention-interval"
+
+	// FlagMirrorURL defines the URL of the API of the peer cluster to which
+	// the events are mirrored
+	FlagMirrorURL = "mirror-url"
+	// FlagMirrorAPIKey defines the API key with which the events are
+	// mirrored to the peer cluster
+	FlagMirrorAPIKey = "mirror-api-key"
+	// FlagMirrorTrustedCAFile defines the CA certificate of the API of the
+	// peer cluster
+	FlagMirrorTrustedCAFile = "mirror-trusted-ca-file"
+	// FlagMirrorInsecureSkipTLSVerify disables the verification of the
+	// certificate of the API of the peer cluster
+	FlagMirrorInsecureSkipTLSVerify = "mirror-insecure-skip-tls-verify"
+	// FlagMirrorNamespaces defines the namespaces of the mirrored events
+	FlagMirrorNamespaces = "mirror-namespaces"
+	// FlagMirrorLabels defines the labels of the mirrored events
+	FlagMirrorLabels = "mirror-labels"
+	// FlagMirrorBatchSize defines the number of events mirrored at once
+	FlagMirrorBatchSize = "mirror-batch-size"
+	// FlagMirrorFlushInterval defines the interval at which the incomplete
+	// batches of events are mirrored
+	FlagMirrorFlushInterval = "mirror-flush-interval"
+
+	// FlagBusDriver defines the driver of the message bus shared with the
+	// other backends
+	FlagBusDriver = "bus-driver"
+	// FlagBusNATSURL defines the URL of the NATS server of the jetstream
+	// driver of the message bus
+	FlagBusNATSURL = "bus-nats-url"
+	// FlagBusNATSMaxAge defines how long the JetStream streams keep the
+	// messages of the bus
+	FlagBusNATSMaxAge = "bus-nats-max-age"
+	// FlagBusKafkaBrokers defines the Kafka brokers of the kafka driver of
+	// the message bus
+	FlagBusKafkaBrokers = "bus-kafka-brokers"
+	// FlagBusKafkaTopicPrefix defines the prefix of the Kafka topics and
+	// consumer groups of the message bus
+	FlagBusKafkaTopicPrefix = "bus-kafka-topic-prefix"
+
+	// FlagCertExpiryWarningThreshold defines the time before their expiry
+	// from which the TLS certificates are reported by a warning event
+	FlagCertExpiryWarningThreshold = "cert-expiry-warning-threshold"
+	// FlagCertExpiryCriticalThreshold defines the time before their expiry
+	// from which the TLS certificates are reported by a critical event
+	FlagCertExpiryCriticalThreshold = "cert-expiry-critical-threshold"
+
+	// FlagSelfMonitoringThreshold defines the number of internal errors in
+	// a minute from which they are reported by a critical event of the
+	// backend entity
+	FlagSelfMonitoringThreshold = "self-monitoring-threshold"
+
+	// FlagSchedulerPublishRate defines the maximum number of check requests
+	// published per second by schedulerd
+	FlagSchedulerPublishRate = "scheduler-publish-rate"
+	// FlagSchedulerDispatchWindow defines the window over which the
+	// executions of the checks scheduled at the same time are spread
+	FlagSchedulerDispatchWindow = "scheduler-dispatch-window"
+	// FlagSchedulerPrioritySubscriptions defines the subscriptions whose
+	// check requests are published first when the publish rate is limited
+	FlagSchedulerPrioritySubscriptions = "scheduler-priority-subscriptions"
+
+	// FlagStoreMaxRetries defines the number of times the store operations
+	// of agentd, eventd and keepalived are retried after a transient error
+	FlagStoreMaxRetries = "store-max-retries"
+	// FlagStoreBreakerThreshold defines the number of consecutive transient
+	// store errors that open the store circuit breaker
+	FlagStoreBreakerThreshold = "store-breaker-threshold"
+	// FlagStoreBreakerCooldown defines how long the store circuit breaker
+	// stays open before it probes the store
+	FlagStoreBreakerCooldown = "store-breaker-cooldown"
 
 	// FlagAgentWriteTimeout specifies the time in seconds to wait before
 	// giving up on a write to an agent and disposing of the connection.
 	FlagAgentWriteTimeout = "agent-write-timeout"
 
+	// FlagAgentEntityConfigRate specifies the maximum number of entity config
+	// updates pushed to agents per second.
+	FlagAgentEntityConfigRate = "agent-entity-config-rate"
+
+	// FlagAgentEventRateNamespace specifies the maximum number of events
+	// received per second from the agents of a namespace.
+	FlagAgentEventRateNamespace = "agent-event-rate-namespace"
+
+	// FlagAgentEventRateAgent specifies the maximum number of events received
+	// per second from an agent.
+	FlagAgentEventRateAgent = "agent-event-rate-agent"
+
+	// FlagAgentEventRateBackoff specifies whether the agents whose events are
+	// rejected by the event rate limits are asked to back off.
+	FlagAgentEventRateBackoff = "agent-event-rate-backoff"
+
+	// FlagAgentDrainWindow specifies the duration over which the agent
+	// sessions are closed when they are drained.
+	FlagAgentDrainWindow = "agent-drain-window"
+
+	// FlagAgentThrottleSaturation specifies the saturation of the backend
+	// from which the agents are asked to throttle their events.
+	FlagAgentThrottleSaturation = "agent-throttle-saturation"
+
+	// FlagAgentThrottleRate specifies the number of events per second sent by
+	// each agent while it is throttled.
+	FlagAgentThrottleRate = "agent-throttle-rate"
+
+	// FlagAgentCheckChannelSize specifies the number of check requests
+	// buffered for each agent session.
+	FlagAgentCheckChannelSize = "agent-check-channel-size"
+
+	// FlagAgentCheckChannelDrop specifies whether the check requests sent to
+	// an agent session whose buffer is full are dropped.
+	FlagAgentCheckChannelDrop = "agent-check-channel-drop"
+
+	// FlagAgentClockSkewThreshold specifies the clock skew of the agents from
+	// which a warning event is published for their entity.
+	FlagAgentClockSkewThreshold = "agent-clock-skew-threshold"
+
+	// FlagAgentCompression specifies the compression algorithms accepted for
+	// the messages of the agent sessions.
+	FlagAgentCompression = "agent-compression"
+
+	// FlagAgentMaxEventSize specifies the maximum size of the events received
+	// from the agents, in bytes.
+	FlagAgentMaxEventSize = "agent-max-event-size"
+
+	// FlagAgentPingInterval specifies the interval at which the agent
+	// sessions ping their agent.
+	FlagAgentPingInterval = "agent-ping-interval"
+
+	// FlagAgentMinProtocolVersion specifies the oldest protocol version
+	// served to the agents.
+	FlagAgentMinProtocolVersion = "agent-min-protocol-version"
+
+	// FlagAgentDeadLetterSize specifies the number of agent events that
+	// could not be published held by the dead letter queue.
+	FlagAgentDeadLetterSize = "agent-dead-letter-size"
+
+	// FlagAgentDeadLetterInterval specifies the interval at which the
+	// events of the dead letter queue are published again.
+	FlagAgentDeadLetterInterval = "agent-dead-letter-interval"
+
+	// FlagAgentConfigAuditSize specifies the number of entity config updates
+	// sent to the agents by all backends kept in the config audit log.
+	FlagAgentConfigAuditSize = "agent-config-audit-size"
+
+	// FlagAgentTLSReloadInterval specifies the interval at which the agentd
+	// TLS files are checked for changes, to reload them.
+	FlagAgentTLSReloadInterval = "agent-tls-reload-interval"
+
+	// FlagAgentGRPCPort specifies the port on which the agents connect over
+	// gRPC streams.
+	FlagAgentGRPCPort = "agent-grpc-port"
+
+	// FlagAgentProxyProtocol specifies whether the agent connections start
+	// with a PROXY protocol header.
+	FlagAgentProxyProtocol = "agent-proxy-protocol"
+
+	// FlagAgentAdmissionURL specifies the URL of the admission webhook that
+	// reviews the agent connections.
+	FlagAgentAdmissionURL = "agent-admission-url"
+
+	// FlagAgentAdmissionTimeout specifies the timeout of the calls to the
+	// admission webhook.
+	FlagAgentAdmissionTimeout = "agent-admission-timeout"
+
+	// FlagAgentAdmissionFailOpen specifies whether the agent connections are
+	// accepted when the admission webhook fails.
+	FlagAgentAdmissionFailOpen = "agent-admission-fail-open"
+
+	// FlagAgentSessionLimitBackend specifies the maximum number of concurrent
+	// agent sessions of the backend.
+	FlagAgentSessionLimitBackend = "agent-session-limit-backend"
+
+	// FlagAgentSessionLimitNamespace specifies the maximum number of
+	// concurrent sessions of the agents of a namespace on the backend.
+	FlagAgentSessionLimitNamespace = "agent-session-limit-namespace"
+
+	// FlagStartupRetryTimeout specifies how long the start of the restartable
+	// daemons, like the store, is retried before the backend fails to start.
+	FlagStartupRetryTimeout = "startup-retry-timeout"
+
 	// FlagJWTPrivateKeyFile defines the path to the private key file for JWT
 	// signatures
 	FlagJWTPrivateKeyFile = "jwt-private-key-file"
@@ -35,6 +235,22 @@ const (
 	FlagJWTPublicKeyFile = "jwt-public-key-file"
 )
 
+const (
+	// BusDriverWizard is the driver of the in-memory message bus, bridged
+	// with postgres when the backend runs a subset of the roles.
+	BusDriverWizard = "wizard"
+
+	// BusDriverJetStream is the driver of the message bus bridged with the
+	// streams of a NATS JetStream server, which keep its messages across the
+	// restarts of the backends.
+	BusDriverJetStream = "jetstream"
+
+	// BusDriverKafka is the driver of the message bus that writes the
+	// messages of the pipeline and of the agent sessions to the topics of a
+	// Kafka cluster.
+	BusDriverKafka = "kafka"
+)
+
 type StoreConfig struct {
 	// PostgresStore contains postgres configuration store details.
 	PostgresStore postgres.Config
@@ -47,18 +263,176 @@ type Config struct {
 	CacheDir string
 	Name     string
 
+	// Roles are the roles run by the backend, or every role if empty
+	Roles []string
+
+	// Extensions are the names of the extensions enabled in the backend, or
+	// every registered extension if empty
+	Extensions []string
+
+	// SecretsNamespaces are the namespaces allowed to use the secrets of the
+	// env secrets provider
+	SecretsNamespaces []string
+
 	// Agentd Configuration
 	AgentHost         string
 	AgentPort         int
 	AgentTLSOptions   *corev2.TLSOptions
 	AgentWriteTimeout int
 
+	// AgentEntityConfigRate is the maximum number of entity config updates
+	// pushed to agents per second, or zero for no limit
+	AgentEntityConfigRate float64
+
+	// AgentEventRateNamespace and AgentEventRateAgent are the maximum number
+	// of events received per second from the agents of a namespace and from an
+	// agent, or zero for no limit. AgentEventRateBackoff asks the agents whose
+	// events are rejected to back off.
+	AgentEventRateNamespace float64
+	AgentEventRateAgent     float64
+	AgentEventRateBackoff   bool
+
+	// AgentDrainWindow is the duration over which the agent sessions are
+	// closed when they are drained, on shutdown or through the API.
+	AgentDrainWindow time.Duration
+
+	// AgentThrottleSaturation is the saturation of the backend, between 0
+	// and 1, from which the agents are asked to send AgentThrottleRate events
+	// per second. The agents are never throttled if it is zero.
+	AgentThrottleSaturation float64
+	AgentThrottleRate       float64
+
+	// AgentCheckChannelSize is the number of check requests buffered for
+	// each agent session. AgentCheckChannelDrop drops the check requests sent
+	// to a full session instead of blocking the message bus.
+	AgentCheckChannelSize int
+	AgentCheckChannelDrop bool
+
+	// AgentClockSkewThreshold is the clock skew of the agents from which a
+	// warning event is published for their entity, or zero to not check it.
+	AgentClockSkewThreshold time.Duration
+
+	// AgentCompression is the list of the compression algorithms accepted
+	// for the messages of the agent sessions. The agents pick the first of
+	// the algorithms they offer that is accepted.
+	AgentCompression []string
+
+	// AgentMaxEventSize is the maximum size of the events received from the
+	// agents, in bytes, or zero for no limit.
+	AgentMaxEventSize int
+
+	// AgentPingInterval is the interval at which the agent sessions ping
+	// their agent to measure the round-trip time of their connection, or
+	// zero to not ping them.
+	AgentPingInterval time.Duration
+
+	// AgentMinProtocolVersion is the oldest protocol version served to the
+	// agents. Agents that only speak older versions are rejected.
+	AgentMinProtocolVersion int
+
+	// AgentDeadLetterSize is the number of agent events that could not
+	// be published held by the dead letter queue, or zero to drop them.
+	AgentDeadLetterSize int
+
+	// AgentDeadLetterInterval is the interval at which the events of
+	// the dead letter queue are published again.
+	AgentDeadLetterInterval time.Duration
+
+	// AgentConfigAuditSize is the number of entity config updates sent to
+	// the agents by all backends kept in the config audit log, or zero to
+	// record none.
+	AgentConfigAuditSize int
+
+	// AgentTLSReloadInterval is the interval at which the agentd TLS files
+	// are checked for changes, or zero to only reload them on SIGHUP.
+	AgentTLSReloadInterval time.Duration
+
+	// AgentGRPCPort is the port on which the agents connect over gRPC
+	// streams, or zero to only accept WebSocket connections.
+	AgentGRPCPort int
+
+	// AgentProxyProtocol is true if the agent connections start with a
+	// PROXY protocol header, sent by the load balancer in front of agentd.
+	AgentProxyProtocol bool
+
+	// AgentAdmissionURL is the URL of the admission webhook that reviews
+	// the agent connections, or empty to accept them all.
+	AgentAdmissionURL string
+
+	// AgentAdmissionTimeout is the timeout of the calls to the admission
+	// webhook.
+	AgentAdmissionTimeout time.Duration
+
+	// AgentAdmissionFailOpen accepts the agent connections when the
+	// admission webhook fails, instead of rejecting them.
+	AgentAdmissionFailOpen bool
+
+	// AgentSessionLimitBackend and AgentSessionLimitNamespace are the
+	// maximum number of concurrent agent sessions of the backend and of the
+	// agents of a namespace on the backend, or zero for no limit.
+	AgentSessionLimitBackend   int
+	AgentSessionLimitNamespace int
+
+	// StartupRetryTimeout is how long the start of the restartable daemons,
+	// like the store, is retried while the API is in degraded read-only mode,
+	// before the backend fails to start. Zero means the backend fails on the
+	// first error.
+	StartupRetryTimeout time.Duration
+
 	// Apid Configuration
 	APIListenAddress string
 	APIRequestLimit  int64
 	APIURL           string
 	APIWriteTimeout  time.Duration
 
+	// APIEventsRequestLimit and APIBulkRequestLimit are the max sizes, in
+	// bytes, of the bodies of the API requests that create or update events
+	// and resources in bulk. Zero means APIRequestLimit.
+	APIEventsRequestLimit int64
+	APIBulkRequestLimit   int64
+
+	// APIListCacheTTL is how long the responses of the entities and events
+	// list endpoints are cached for.
+	APIListCacheTTL time.Duration
+
+	// APIIdempotencyWindow is how long the event submissions with the same
+	// idempotency key are only processed once.
+	APIIdempotencyWindow time.Duration
+
+	// APIIdleTimeout is how long idle keep-alive API connections are kept open.
+	APIIdleTimeout time.Duration
+
+	// APIMaxConcurrentStreams is the number of concurrent HTTP/2 streams
+	// allowed per API connection.
+	APIMaxConcurrentStreams uint32
+
+	// APIShutdownTimeout is how long open API connections are given to drain
+	// when the backend stops.
+	APIShutdownTimeout time.Duration
+
+	// APIReadDeadline, APIListDeadline and APIWriteDeadline are the maximum
+	// durations of the API requests that get, list and write resources, after
+	// which their store operations are abandoned. Zero means no deadline.
+	APIReadDeadline  time.Duration
+	APIListDeadline  time.Duration
+	APIWriteDeadline time.Duration
+
+	// APICORSAllowedOrigins are the origins allowed to make cross-origin API
+	// requests. Cross-origin requests are not allowed when it is empty.
+	APICORSAllowedOrigins []string
+
+	// APICORSAllowedMethods are the methods allowed in cross-origin API
+	// requests.
+	APICORSAllowedMethods []string
+
+	// APICORSAllowedHeaders are the headers allowed in cross-origin API
+	// requests.
+	APICORSAllowedHeaders []string
+
+	// APICORSAllowCredentials indicates whether cross-origin API requests can
+	// include credentials.
+	APICORSAllowCredentials bool
+
 	// AssetsRateLimit is the maximum number of assets per second that will be fetched.
 	AssetsRateLimit rate.Limit
 
@@ -75,6 +449,10 @@ type Config struct {
 	// Pipelined Configuration
 	DeregistrationHandler string
 
+	// EntityStateHandler handles the events emitted when entities become
+	// healthy, unhealthy or deregistered
+	EntityStateHandler string
+
 	// Labels are key-value pairs that users can provide to backend entities
 	Labels map[string]string
 
@@ -97,4 +475,7 @@ type Config struct {
 	EventLogParallelEncoders bool
 
 	Store StoreConfig
+
+	// FeatureGates enable or disable experimental features.
+	FeatureGates *featuregate.Gates
 }
@@ -19,6 +19,14 @@ type Daemon interface {
 	Name() string
 }
 
+// Restartable is implemented by the daemons whose Start method can be called
+// again after it failed, because a failed start leaves no goroutine or
+// subscription behind.
+type Restartable interface {
+	// Restartable returns true if the start of the daemon can be retried.
+	Restartable() bool
+}
+
 // Get returns the daemon with the provided name
 func Get(daemons []Daemon, name string) Daemon {
 	for _, daemon := range daemons {
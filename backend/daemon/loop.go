@@ -0,0 +1,50 @@
+package daemon
+
+import "context"
+
+// Loop is the skeleton of the daemons that run a loop in a goroutine until
+// they are stopped. Such a daemon delegates its Stop and Err methods to its
+// Loop.
+type Loop struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	errChan chan error
+}
+
+// NewLoop creates a new Loop.
+func NewLoop() *Loop {
+	l := &Loop{
+		done:    make(chan struct{}),
+		errChan: make(chan error, 1),
+	}
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+	return l
+}
+
+// Context returns the context of the loop, which is done once it is stopped.
+func (l *Loop) Context() context.Context {
+	return l.ctx
+}
+
+// Go runs fn in a goroutine, with the context of the loop. It must be called
+// once, by the Start method of the daemon.
+func (l *Loop) Go(fn func(ctx context.Context)) {
+	go func() {
+		defer close(l.done)
+		fn(l.ctx)
+	}()
+}
+
+// Stop stops the loop, and waits for the function run by Go to return.
+func (l *Loop) Stop() error {
+	l.cancel()
+	<-l.done
+	close(l.errChan)
+	return nil
+}
+
+// Err returns a channel on which to listen for terminal errors.
+func (l *Loop) Err() <-chan error {
+	return l.errChan
+}
@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the state of a daemon of a backend.
+type State string
+
+const (
+	// StatePending is the state of the daemons waiting for the daemons they
+	// depend on to start.
+	StatePending State = "pending"
+
+	// StateStarting is the state of the daemons being started.
+	StateStarting State = "starting"
+
+	// StateRunning is the state of the started daemons.
+	StateRunning State = "running"
+
+	// StateFailed is the state of the daemons that failed to start, and
+	// whose start is retried.
+	StateFailed State = "failed"
+
+	// StateStopped is the state of the stopped daemons.
+	StateStopped State = "stopped"
+)
+
+// Status is the status of a daemon of a backend.
+type Status struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+
+	// Error is the last error that kept the daemon from starting.
+	Error string `json:"error,omitempty"`
+
+	// Since is when the daemon entered its state.
+	Since time.Time `json:"since"`
+}
+
+// Statuses are the statuses of the daemons of a backend, in their startup
+// order. It is safe for concurrent use.
+type Statuses struct {
+	mu       sync.Mutex
+	statuses []Status
+}
+
+// NewStatuses returns the statuses of daemons, all pending.
+func NewStatuses(daemons []Daemon) *Statuses {
+	s := &Statuses{}
+	now := time.Now()
+	for _, d := range daemons {
+		s.statuses = append(s.statuses, Status{Name: d.Name(), State: StatePending, Since: now})
+	}
+	return s
+}
+
+// Set sets the state of the daemon named name, with the error that kept it
+// from starting, if any.
+func (s *Statuses) Set(name string, state State, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := Status{Name: name, State: state, Since: time.Now()}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	for i := range s.statuses {
+		if s.statuses[i].Name == name {
+			if s.statuses[i].State == state {
+				// The daemon stays in its state since it entered it
+				status.Since = s.statuses[i].Since
+			}
+			s.statuses[i] = status
+			return
+		}
+	}
+	s.statuses = append(s.statuses, status)
+}
+
+// List returns the statuses of the daemons.
+func (s *Statuses) List() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Status(nil), s.statuses...)
+}
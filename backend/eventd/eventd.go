@@ -12,6 +12,7 @@ import (
 
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/featuregate"
 	"github.com/sensu/sensu-go/backend/messaging"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
@@ -79,6 +80,10 @@ const (
 	// track average latencies of publishing to the bus.
 	BusPublishDuration = "sensu_go_eventd_bus_publish_duration"
 
+	// EventsShedCounter is the name of the prometheus counter used to count
+	// the repeated OK events shed by eventd when it is overloaded.
+	EventsShedCounter = "sensu_go_eventd_events_shed"
+
 	// defaultStoreTimeout is the store timeout used if the backend did not configure one
 	defaultStoreTimeout = time.Minute
 )
@@ -147,6 +152,14 @@ var (
 		},
 		[]string{metricspkg.StatusLabelName, metricspkg.EventTypeLabelName},
 	)
+
+	// EventsShed counts the number of events shed by eventd.
+	EventsShed = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: EventsShedCounter,
+			Help: "The total number of repeated OK events shed by eventd under load",
+		},
+	)
 )
 
 const deletedEventSentinel = -1
@@ -158,8 +171,10 @@ type Eventd struct {
 	store               storev2.Interface
 	bus                 messaging.MessageBus
 	workerCount         int
+	bufferSize          int
 	eventChan           chan interface{}
 	keepaliveChan       chan interface{}
+	queue               *priorityQueue
 	subscription        messaging.Subscription
 	errChan             chan error
 	mu                  *sync.Mutex
@@ -175,6 +190,10 @@ type Eventd struct {
 	operatorMonitor     store.OperatorMonitor
 	operatorQueryer     store.OperatorQueryer
 	backendName         string
+	featureGates        *featuregate.Gates
+	traceStore          store.EventTraceStore
+	redactions          *redactionPolicies
+	metadataLimits      metadataLimits
 }
 
 // Option is a functional option.
@@ -195,6 +214,16 @@ type Config struct {
 	OperatorMonitor     store.OperatorMonitor
 	OperatorQueryer     store.OperatorQueryer
 	BackendName         string
+	FeatureGates        *featuregate.Gates
+	TraceStore          store.EventTraceStore
+
+	// MaxAnnotationsSize is the size budget, in bytes, of the annotations of
+	// the event and of the check of each event. Unlimited if zero.
+	MaxAnnotationsSize int
+
+	// MaxLabelsSize is the size budget, in bytes, of the labels of the event
+	// and of the check of each event. Unlimited if zero.
+	MaxLabelsSize int
 }
 
 // New creates a new Eventd.
@@ -216,6 +245,7 @@ func New(ctx context.Context, c Config, opts ...Option) (*Eventd, error) {
 		store:               c.Store,
 		bus:                 c.Bus,
 		workerCount:         c.WorkerCount,
+		bufferSize:          c.BufferSize,
 		errChan:             make(chan error, 1),
 		shutdownChan:        make(chan struct{}, 1),
 		eventChan:           make(chan interface{}, c.BufferSize),
@@ -229,8 +259,15 @@ func New(ctx context.Context, c Config, opts ...Option) (*Eventd, error) {
 		logParallelEncoders: c.LogParallelEncoders,
 		Logger:              NoopLogger{},
 		operatorConcierge:   c.OperatorConcierge,
+		featureGates:        c.FeatureGates,
 		operatorMonitor:     c.OperatorMonitor,
 		backendName:         c.BackendName,
+		traceStore:          c.TraceStore,
+		redactions:          newRedactionPolicies(),
+		metadataLimits: metadataLimits{
+			annotations: c.MaxAnnotationsSize,
+			labels:      c.MaxLabelsSize,
+		},
 	}
 
 	e.ctx, e.cancel = context.WithCancel(ctx)
@@ -272,6 +309,8 @@ func New(ctx context.Context, c Config, opts ...Option) (*Eventd, error) {
 	_ = prometheus.Register(createProxyEntityDuration)
 	_ = prometheus.Register(updateEventDuration)
 	_ = prometheus.Register(busPublishDuration)
+	_ = prometheus.Register(EventsShed)
+	_ = prometheus.Register(metadataPruned)
 
 	return e, nil
 }
@@ -283,27 +322,62 @@ func (e *Eventd) Receiver() chan<- interface{} {
 
 // Start eventd.
 func (e *Eventd) Start() error {
-	e.wg.Add(e.workerCount)
-	sub, err := e.bus.Subscribe(messaging.TopicEventRaw, "eventd", e)
+	sub, err := messaging.Supervise(e.ctx, e.bus, messaging.TopicEventRaw, "eventd", e)
 	e.subscription = sub
 	if err != nil {
 		return err
 	}
+	// The workers are only counted once the subscription succeeds, so that
+	// the start can be retried
+	e.wg.Add(e.workerCount)
 
 	if logger := e.startFileLogger(); logger != nil {
 		e.Logger = logger
 	}
 
-	e.startHandlers()
+	if e.featureGates.Enabled(featuregate.OrderedEvents) {
+		// Prioritizing the events would reorder the events of a check
+		if e.featureGates.Enabled(featuregate.EventPriority) {
+			logger.Warnf("the %s feature gate is ignored while %s is enabled", featuregate.EventPriority, featuregate.OrderedEvents)
+		}
+		e.startOrderedHandlers()
+	} else if e.featureGates.Enabled(featuregate.EventPriority) {
+		e.queue = newPriorityQueue(e.bufferSize)
+		go e.prioritizeEvents()
+		e.startPriorityHandlers()
+	} else {
+		e.startHandlers()
+	}
 	go e.monitorCheckTTLs(e.ctx)
+	go e.refreshRedactionPolicies(e.ctx)
 
 	return nil
 }
 
+// refreshRedactionPolicies periodically refreshes the redaction policies of
+// the namespaces.
+func (e *Eventd) refreshRedactionPolicies(ctx context.Context) {
+	ticker := time.NewTicker(redactionRefreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := e.redactions.Refresh(ctx, e.store); err != nil && ctx.Err() == nil {
+			logger.WithError(err).Error("error refreshing redaction policies")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func withEventFields(e interface{}, logger *logrus.Entry) *logrus.Entry {
 	event, _ := e.(*corev2.Event)
 	if event != nil {
 		fields := utillogging.EventFields(event, false)
+		if id := store.EventRequestID(event); id != "" {
+			fields["request_id"] = id
+		}
 		logger = logger.WithFields(fields)
 	}
 	return logger
@@ -456,6 +530,9 @@ func (e *Eventd) handleMessage(msg interface{}) (fEvent *corev2.Event, fErr erro
 	}
 
 	fields := utillogging.EventFields(event, false)
+	if id := store.EventRequestID(event); id != "" {
+		fields["request_id"] = id
+	}
 	logger.WithFields(fields).Info("eventd received event")
 
 	// Validate the received event
@@ -464,6 +541,16 @@ func (e *Eventd) handleMessage(msg interface{}) (fEvent *corev2.Event, fErr erro
 		return event, err
 	}
 
+	// Keep the secrets of the namespace out of the store and the pipelines
+	e.redactions.Redact(event)
+
+	// Keep the metadata added by the mutators from growing the stored events
+	// without bound
+	e.metadataLimits.Enforce(event)
+
+	// Events that were not received from an agent are received now
+	store.EnsureEventReceived(event, time.Now())
+
 	if event.HasMetrics() {
 		MetricPointsProcessed.Add(float64(len(event.Metrics.Points)))
 	}
@@ -500,6 +587,7 @@ func (e *Eventd) handleMessage(msg interface{}) (fEvent *corev2.Event, fErr erro
 	}
 
 	e.Logger.Println(event)
+	e.traceEvent(ctx, event)
 
 	ostate := store.OperatorState{
 		Namespace: event.Check.Namespace,
@@ -691,6 +779,12 @@ func (e *Eventd) Err() <-chan error {
 	return e.errChan
 }
 
+// QueueDepth returns the number of events and keepalives waiting to be
+// processed by eventd and the capacity of its buffers.
+func (e *Eventd) QueueDepth() (int, int) {
+	return len(e.eventChan) + len(e.keepaliveChan), cap(e.eventChan) + cap(e.keepaliveChan)
+}
+
 // Name returns the daemon name
 func (e *Eventd) Name() string {
 	return "eventd"
@@ -720,3 +814,26 @@ func (e Eventd) startFileLogger() Logger {
 	}
 	return &log
 }
+
+// traceEvent starts a new trace for the event if it is traced, recording the
+// state of the event after it was stored.
+func (e *Eventd) traceEvent(ctx context.Context, event *corev2.Event) {
+	if e.traceStore == nil || !store.IsEventTraced(event) {
+		return
+	}
+	trace := store.NewEventTrace(event)
+	trace.Steps = []store.EventTraceStep{{
+		Time:      time.Now(),
+		Component: ComponentName,
+		Step:      "store",
+		Result:    "stored",
+		Output: fmt.Sprintf("state=%s status=%d occurrences=%d is_incident=%t is_resolution=%t is_silenced=%t",
+			event.Check.State, event.Check.Status, event.Check.Occurrences,
+			event.IsIncident(), event.IsResolution(), event.IsSilenced()),
+	}}
+	tctx, cancel := context.WithTimeout(ctx, e.storeTimeout)
+	defer cancel()
+	if err := e.traceStore.AppendEventTrace(tctx, trace); err != nil {
+		logger.WithError(err).Error("error storing event trace")
+	}
+}
@@ -0,0 +1,135 @@
+package eventd
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MetadataPrunedCounterVec is the name of the prometheus counter vec used
+	// to count the labels and annotations of the events pruned by eventd.
+	MetadataPrunedCounterVec = "sensu_go_eventd_metadata_pruned_total"
+
+	metadataKindAnnotations = "annotations"
+	metadataKindLabels      = "labels"
+
+	metadataPrunedTruncated = "truncated"
+	metadataPrunedDropped   = "dropped"
+
+	// sensuMetadataPrefix is the prefix of the labels and annotations set by
+	// Sensu itself, which are never pruned.
+	sensuMetadataPrefix = "sensu.io/"
+)
+
+// metadataPruned counts the labels and annotations of the events that were
+// truncated or dropped to fit the size budgets of eventd.
+var metadataPruned = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: MetadataPrunedCounterVec,
+		Help: "The total number of event labels and annotations pruned by eventd, by kind and action",
+	},
+	[]string{"kind", "action"},
+)
+
+// metadataLimits are the size budgets, in bytes, of the labels and of the
+// annotations of the event and of the check of each event. The size of the
+// labels and of the annotations is the sum of the lengths of their keys and
+// values. A budget of zero or lower is unlimited.
+//
+// The budgets keep the mutators that add metadata to the events from growing
+// the stored events without bound as the events go through the pipelines
+// again and again.
+type metadataLimits struct {
+	annotations int
+	labels      int
+}
+
+// Enforce prunes the labels and annotations of event in place to fit the
+// budgets.
+func (l metadataLimits) Enforce(event *corev2.Event) {
+	var truncated, dropped int
+	enforce := func(meta *corev2.ObjectMeta) {
+		t, d := pruneMetadata(meta.Annotations, l.annotations, metadataKindAnnotations)
+		truncated, dropped = truncated+t, dropped+d
+		t, d = pruneMetadata(meta.Labels, l.labels, metadataKindLabels)
+		truncated, dropped = truncated+t, dropped+d
+	}
+	enforce(&event.ObjectMeta)
+	if event.Check != nil {
+		enforce(&event.Check.ObjectMeta)
+	}
+	if truncated > 0 || dropped > 0 {
+		withEventFields(event, logger).WithFields(logrus.Fields{
+			"truncated": truncated,
+			"dropped":   dropped,
+		}).Warn("event labels or annotations exceed their size budget, pruning them")
+	}
+}
+
+// pruneMetadata prunes the entries of m to fit budget, and returns the number
+// of entries truncated and dropped. The entries of the sensu.io/ prefix are
+// set by Sensu itself, they are neither counted nor pruned. The largest
+// entries are pruned first, in the order of their keys for the entries of the
+// same size, so that the same entries are kept every time the event is
+// handled: the value of an entry is truncated if that is enough to fit the
+// budget, otherwise the entry is dropped.
+func pruneMetadata(m map[string]string, budget int, kind string) (truncated, dropped int) {
+	if budget <= 0 {
+		return 0, 0
+	}
+	keys := make([]string, 0, len(m))
+	var size int
+	for key, value := range m {
+		if strings.HasPrefix(key, sensuMetadataPrefix) {
+			continue
+		}
+		keys = append(keys, key)
+		size += len(key) + len(value)
+	}
+	excess := size - budget
+	if excess <= 0 {
+		return 0, 0
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		si, sj := len(keys[i])+len(m[keys[i]]), len(keys[j])+len(m[keys[j]])
+		if si != sj {
+			return si > sj
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		if excess <= 0 {
+			break
+		}
+		value := m[key]
+		if len(value) > excess {
+			m[key] = truncateString(value, len(value)-excess)
+			excess = 0
+			truncated++
+			continue
+		}
+		delete(m, key)
+		excess -= len(key) + len(value)
+		dropped++
+	}
+	metadataPruned.WithLabelValues(kind, metadataPrunedTruncated).Add(float64(truncated))
+	metadataPruned.WithLabelValues(kind, metadataPrunedDropped).Add(float64(dropped))
+	return truncated, dropped
+}
+
+// truncateString truncates s to at most n bytes, without splitting a UTF-8
+// encoded rune.
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,123 @@
+package eventd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+func TestPruneMetadata(t *testing.T) {
+	tests := []struct {
+		name          string
+		metadata      map[string]string
+		budget        int
+		want          map[string]string
+		wantTruncated int
+		wantDropped   int
+	}{
+		{
+			name:     "unlimited",
+			metadata: map[string]string{"a": strings.Repeat("x", 100)},
+			want:     map[string]string{"a": strings.Repeat("x", 100)},
+		},
+		{
+			name:     "within budget",
+			metadata: map[string]string{"a": "12", "b": "34"},
+			budget:   6,
+			want:     map[string]string{"a": "12", "b": "34"},
+		},
+		{
+			name:          "truncated",
+			metadata:      map[string]string{"a": "1234567890"},
+			budget:        6,
+			want:          map[string]string{"a": "12345"},
+			wantTruncated: 1,
+		},
+		{
+			name:        "largest dropped first",
+			metadata:    map[string]string{"c": "5678", "a": "1", "b": "34"},
+			budget:      6,
+			want:        map[string]string{"a": "1", "b": "34"},
+			wantDropped: 1,
+		},
+		{
+			name:          "largest truncated first",
+			metadata:      map[string]string{"a": "12", "b": "3456789"},
+			budget:        6,
+			want:          map[string]string{"a": "12", "b": "34"},
+			wantTruncated: 1,
+		},
+		{
+			name:        "same size dropped in key order",
+			metadata:    map[string]string{"b": "34", "a": "12"},
+			budget:      4,
+			want:        map[string]string{"b": "34"},
+			wantDropped: 1,
+		},
+		{
+			name:          "sensu entries exempt",
+			metadata:      map[string]string{"sensu.io/received": "1234567890", "a": "1234567890"},
+			budget:        6,
+			want:          map[string]string{"sensu.io/received": "1234567890", "a": "12345"},
+			wantTruncated: 1,
+		},
+		{
+			name:        "key too long",
+			metadata:    map[string]string{"a": "12", "long_key": "34"},
+			budget:      6,
+			want:        map[string]string{"a": "12"},
+			wantDropped: 1,
+		},
+		{
+			name:          "runes kept whole",
+			metadata:      map[string]string{"a": "日本語"},
+			budget:        6,
+			want:          map[string]string{"a": "日"},
+			wantTruncated: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			truncated, dropped := pruneMetadata(tt.metadata, tt.budget, metadataKindAnnotations)
+			if !reflect.DeepEqual(tt.metadata, tt.want) {
+				t.Errorf("got %v, want %v", tt.metadata, tt.want)
+			}
+			if truncated != tt.wantTruncated || dropped != tt.wantDropped {
+				t.Errorf("got %d truncated and %d dropped, want %d and %d", truncated, dropped, tt.wantTruncated, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestMetadataLimitsEnforce(t *testing.T) {
+	event := corev2.FixtureEvent("entity1", "check1")
+	event.Annotations = map[string]string{"mutated": strings.Repeat("x", 100)}
+	event.Check.Annotations = map[string]string{"mutated": strings.Repeat("x", 100)}
+	event.Check.Labels = map[string]string{"region": "us-west-2", "zone": "a"}
+	event.Entity.Annotations = map[string]string{"entity": strings.Repeat("x", 100)}
+
+	limits := metadataLimits{annotations: 10, labels: 10}
+	limits.Enforce(event)
+
+	if got := event.Annotations["mutated"]; got != "xxx" {
+		t.Errorf("event annotation not truncated: %q", got)
+	}
+	if got := event.Check.Annotations["mutated"]; got != "xxx" {
+		t.Errorf("check annotation not truncated: %q", got)
+	}
+	if want := map[string]string{"zone": "a"}; !reflect.DeepEqual(event.Check.Labels, want) {
+		t.Errorf("check labels not pruned: got %v, want %v", event.Check.Labels, want)
+	}
+	// The entity metadata is managed by the entity configs
+	if got := event.Entity.Annotations["entity"]; len(got) != 100 {
+		t.Errorf("entity annotation pruned: %q", got)
+	}
+
+	// The pruned metadata fits the budgets the next time it is handled
+	limits.Enforce(event)
+	if got := event.Annotations["mutated"]; got != "xxx" {
+		t.Errorf("event annotation pruned again: %q", got)
+	}
+}
@@ -0,0 +1,47 @@
+package eventd
+
+import (
+	"github.com/sensu/sensu-go/backend/messaging"
+)
+
+// startOrderedHandlers starts workers that each handle the events of the
+// entities and checks of their shard, so that the events of a check are
+// handled in the order in which they are received: a delayed OK event can't
+// be handled after a newer CRITICAL event and flip the state of the check
+// back. The events of different checks are still handled in parallel.
+// Workers return once the event channel is closed and drained.
+func (e *Eventd) startOrderedHandlers() {
+	bufferSize := e.bufferSize / e.workerCount
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	shards := make([]chan interface{}, e.workerCount)
+	for i := range shards {
+		shards[i] = make(chan interface{}, bufferSize)
+	}
+
+	for _, shard := range shards {
+		go func(shard chan interface{}) {
+			defer e.wg.Done()
+			for msg := range shard {
+				eventHandlersBusy.WithLabelValues().Inc()
+				if _, err := e.handleMessage(msg); err != nil {
+					logger := withEventFields(msg, logger)
+					logger.WithError(err).Error("error handling event from ordered event channel")
+				}
+				eventHandlersBusy.WithLabelValues().Dec()
+			}
+		}(shard)
+	}
+
+	go func() {
+		defer func() {
+			for _, shard := range shards {
+				close(shard)
+			}
+		}()
+		for msg := range e.eventChan {
+			shards[messaging.Shard(messaging.OrderingKey(msg), len(shards))] <- msg
+		}
+	}()
+}
@@ -0,0 +1,189 @@
+package eventd
+
+import (
+	"path"
+	"sync"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+// eventPriority is the processing priority of an event. When eventd is
+// overloaded, events of a higher priority are processed first.
+type eventPriority int
+
+const (
+	// priorityLow is the priority of repeated OK events. They are shed when
+	// their queue is full.
+	priorityLow eventPriority = iota
+
+	// priorityNormal is the priority of metrics-only events and of repeated
+	// non-OK events.
+	priorityNormal
+
+	// priorityHigh is the priority of keepalives and state changes.
+	priorityHigh
+
+	priorityCount = int(priorityHigh) + 1
+)
+
+// maxStatusCacheSize is the maximum number of checks whose last status is
+// remembered by a statusCache. The cache is reset once it is reached.
+const maxStatusCacheSize = 100000
+
+// statusCache remembers the last status of the checks of the events received
+// by eventd, so that state changes are classified without querying the store.
+// It is best effort: the first event of a check after a reset, or after it
+// was processed by another backend, is not considered a state change.
+type statusCache struct {
+	statuses map[string]uint32
+}
+
+func newStatusCache() *statusCache {
+	return &statusCache{statuses: make(map[string]uint32)}
+}
+
+// classify returns the priority of event, and remembers the status of its
+// check.
+func (c *statusCache) classify(event *corev2.Event) eventPriority {
+	if !event.HasCheck() {
+		return priorityNormal
+	}
+	if event.Check.Name == corev2.KeepaliveCheckName {
+		return priorityHigh
+	}
+	entityName := event.Check.ProxyEntityName
+	if entityName == "" && event.Entity != nil {
+		entityName = event.Entity.Name
+	}
+	key := path.Join(event.Check.Namespace, entityName, event.Check.Name)
+	status := event.Check.Status
+	last, ok := c.statuses[key]
+	if !ok && len(c.statuses) >= maxStatusCacheSize {
+		c.statuses = make(map[string]uint32)
+	}
+	c.statuses[key] = status
+	switch {
+	case ok && last != status:
+		return priorityHigh
+	case !ok && status != 0:
+		// the state of a check seen for the first time is only known to be
+		// interesting if it is failing
+		return priorityHigh
+	case status == 0:
+		return priorityLow
+	default:
+		return priorityNormal
+	}
+}
+
+// priorityQueue is a bounded queue of events that are dequeued by priority.
+// Each priority has its own buffer: pushing an event of a full buffer blocks,
+// except for low priority events, which are shed.
+type priorityQueue struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	size     int
+	queues   [priorityCount][]interface{}
+	closed   bool
+}
+
+func newPriorityQueue(size int) *priorityQueue {
+	if size < 1 {
+		size = 1
+	}
+	q := &priorityQueue{size: size}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds msg to the queue of priority p. It returns false if msg was shed.
+func (q *priorityQueue) push(msg interface{}, p eventPriority) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.queues[p]) >= q.size && !q.closed {
+		if p == priorityLow {
+			return false
+		}
+		q.notFull.Wait()
+	}
+	if q.closed {
+		return false
+	}
+	q.queues[p] = append(q.queues[p], msg)
+	q.notEmpty.Signal()
+	return true
+}
+
+// pop removes and returns the oldest message of the highest priority. It
+// blocks until a message is available, and returns false once the queue is
+// closed and empty.
+func (q *priorityQueue) pop() (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		for p := priorityCount - 1; p >= 0; p-- {
+			if len(q.queues[p]) == 0 {
+				continue
+			}
+			msg := q.queues[p][0]
+			q.queues[p][0] = nil
+			q.queues[p] = q.queues[p][1:]
+			q.notFull.Broadcast()
+			return msg, true
+		}
+		if q.closed {
+			return nil, false
+		}
+		q.notEmpty.Wait()
+	}
+}
+
+// close closes the queue. The queued messages can still be popped.
+func (q *priorityQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+}
+
+// prioritizeEvents classifies the events of the event channel, and queues
+// them by priority. The queue is closed once the event channel is closed.
+func (e *Eventd) prioritizeEvents() {
+	defer e.queue.close()
+	statuses := newStatusCache()
+	for msg := range e.eventChan {
+		p := priorityNormal
+		if event, ok := msg.(*corev2.Event); ok {
+			p = statuses.classify(event)
+		}
+		if !e.queue.push(msg, p) {
+			EventsShed.Inc()
+			withEventFields(msg, logger).Debug("eventd overloaded, shedding repeated OK event")
+		}
+	}
+}
+
+// startPriorityHandlers starts the workers that handle the events of the
+// priority queue. Workers return once the queue is closed and drained.
+func (e *Eventd) startPriorityHandlers() {
+	for i := 0; i < e.workerCount; i++ {
+		go func() {
+			defer e.wg.Done()
+			for {
+				msg, ok := e.queue.pop()
+				if !ok {
+					return
+				}
+				eventHandlersBusy.WithLabelValues().Inc()
+				if _, err := e.handleMessage(msg); err != nil {
+					logger := withEventFields(msg, logger)
+					logger.WithError(err).Error("error handling event from priority queue")
+				}
+				eventHandlersBusy.WithLabelValues().Dec()
+			}
+		}()
+	}
+}
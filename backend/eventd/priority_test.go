@@ -0,0 +1,77 @@
+package eventd
+
+import (
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusCacheClassify(t *testing.T) {
+	event := func(check string, status uint32) *corev2.Event {
+		event := corev2.FixtureEvent("entity", check)
+		event.Check.Status = status
+		return event
+	}
+	metrics := corev2.FixtureEvent("entity", "metrics")
+	metrics.Check = nil
+	metrics.Metrics = corev2.FixtureMetrics()
+
+	statuses := newStatusCache()
+	testCases := []struct {
+		name  string
+		event *corev2.Event
+		want  eventPriority
+	}{
+		{name: "keepalive", event: event(corev2.KeepaliveCheckName, 0), want: priorityHigh},
+		{name: "metrics only", event: metrics, want: priorityNormal},
+		{name: "new ok check", event: event("check", 0), want: priorityLow},
+		{name: "repeated ok", event: event("check", 0), want: priorityLow},
+		{name: "state change", event: event("check", 2), want: priorityHigh},
+		{name: "repeated critical", event: event("check", 2), want: priorityNormal},
+		{name: "resolution", event: event("check", 0), want: priorityHigh},
+		{name: "new failing check", event: event("other", 1), want: priorityHigh},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, statuses.classify(tc.event))
+		})
+	}
+}
+
+func TestPriorityQueue(t *testing.T) {
+	q := newPriorityQueue(2)
+	assert.True(t, q.push("ok-1", priorityLow))
+	assert.True(t, q.push("ok-2", priorityLow))
+	// repeated OK events are shed when their buffer is full
+	assert.False(t, q.push("ok-3", priorityLow))
+	assert.True(t, q.push("critical", priorityNormal))
+	assert.True(t, q.push("keepalive", priorityHigh))
+	q.close()
+
+	var got []interface{}
+	for {
+		msg, ok := q.pop()
+		if !ok {
+			break
+		}
+		got = append(got, msg)
+	}
+	assert.Equal(t, []interface{}{"keepalive", "critical", "ok-1", "ok-2"}, got)
+}
+
+func TestPriorityQueueBlocksWhenFull(t *testing.T) {
+	q := newPriorityQueue(1)
+	assert.True(t, q.push("first", priorityHigh))
+	pushed := make(chan bool)
+	go func() {
+		pushed <- q.push("second", priorityHigh)
+	}()
+	msg, ok := q.pop()
+	assert.True(t, ok)
+	assert.Equal(t, "first", msg)
+	assert.True(t, <-pushed)
+	msg, ok = q.pop()
+	assert.True(t, ok)
+	assert.Equal(t, "second", msg)
+}
@@ -0,0 +1,294 @@
+package eventd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+const (
+	// RedactKeysAnnotation is the namespace annotation that lists, separated
+	// by commas, the keys of the labels, annotations and check environment
+	// variables of the events of the namespace whose values are redacted, like
+	// the redact option of the agent.
+	RedactKeysAnnotation = "sensu.io/redact_keys"
+
+	// RedactPathsAnnotation is the namespace annotation that lists, separated
+	// by commas, the paths of the fields of the events of the namespace that
+	// are redacted, such as check.output or entity.system.network. String
+	// fields are replaced with REDACTED, and the other fields are cleared.
+	RedactPathsAnnotation = "sensu.io/redact_paths"
+
+	// RedactPatternsAnnotation is the namespace annotation that holds a JSON
+	// array of regular expressions whose matches are replaced with REDACTED
+	// in the outputs and commands of the checks and hooks, and in the values
+	// of the labels, annotations and check environment variables of the
+	// events of the namespace.
+	RedactPatternsAnnotation = "sensu.io/redact_patterns"
+
+	// redactionRefreshInterval is the interval of the refreshes of the
+	// redaction policies of the namespaces.
+	redactionRefreshInterval = 10 * time.Second
+)
+
+// redactionPolicy is the redaction policy of the events of a namespace.
+type redactionPolicy struct {
+	keys     map[string]struct{}
+	paths    [][]string
+	patterns []*regexp.Regexp
+}
+
+// newRedactionPolicy returns the redaction policy set by the annotations of
+// namespace, or nil if the namespace has none. The invalid rules of the policy
+// are returned as an error, and the other rules are applied.
+func newRedactionPolicy(namespace *corev3.Namespace) (*redactionPolicy, error) {
+	if namespace == nil || namespace.Metadata == nil {
+		return nil, nil
+	}
+	annotations := namespace.Metadata.Annotations
+	policy := &redactionPolicy{keys: make(map[string]struct{})}
+	var errs []string
+	for _, key := range splitList(annotations[RedactKeysAnnotation]) {
+		policy.keys[key] = struct{}{}
+	}
+	for _, path := range splitList(annotations[RedactPathsAnnotation]) {
+		segments := strings.Split(path, ".")
+		if last := segments[len(segments)-1]; last == "name" || last == "namespace" {
+			// The events are stored and routed by name and namespace
+			errs = append(errs, fmt.Sprintf("%s can't be redacted", path))
+			continue
+		}
+		policy.paths = append(policy.paths, segments)
+	}
+	if value := annotations[RedactPatternsAnnotation]; value != "" {
+		var patterns []string
+		if err := json.Unmarshal([]byte(value), &patterns); err != nil {
+			errs = append(errs, fmt.Sprintf("%s must be a JSON array of strings", RedactPatternsAnnotation))
+		}
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			policy.patterns = append(policy.patterns, re)
+		}
+	}
+	var err error
+	if len(errs) > 0 {
+		err = fmt.Errorf("invalid redaction policy: %s", strings.Join(errs, "; "))
+	}
+	if len(policy.keys) == 0 && len(policy.paths) == 0 && len(policy.patterns) == 0 {
+		return nil, err
+	}
+	return policy, err
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Redact redacts event in place.
+func (p *redactionPolicy) Redact(event *corev2.Event) {
+	p.redactMeta(&event.ObjectMeta)
+	if event.Entity != nil {
+		p.redactMeta(&event.Entity.ObjectMeta)
+	}
+	if event.Check != nil {
+		p.redactMeta(&event.Check.ObjectMeta)
+		for i, envVar := range event.Check.EnvVars {
+			key, value, ok := strings.Cut(envVar, "=")
+			if !ok {
+				continue
+			}
+			event.Check.EnvVars[i] = key + "=" + p.redactValue(key, value)
+		}
+		event.Check.Output = p.redactText(event.Check.Output)
+		event.Check.Command = p.redactText(event.Check.Command)
+		for _, hook := range event.Check.Hooks {
+			if hook == nil {
+				continue
+			}
+			hook.Output = p.redactText(hook.Output)
+			hook.Command = p.redactText(hook.Command)
+		}
+	}
+	for _, path := range p.paths {
+		redactPath(reflect.ValueOf(event), path)
+	}
+}
+
+func (p *redactionPolicy) redactMeta(meta *corev2.ObjectMeta) {
+	for key, value := range meta.Labels {
+		meta.Labels[key] = p.redactValue(key, value)
+	}
+	for key, value := range meta.Annotations {
+		meta.Annotations[key] = p.redactValue(key, value)
+	}
+}
+
+func (p *redactionPolicy) redactValue(key, value string) string {
+	if _, ok := p.keys[key]; ok {
+		return corev2.Redacted
+	}
+	return p.redactText(value)
+}
+
+func (p *redactionPolicy) redactText(text string) string {
+	for _, re := range p.patterns {
+		text = re.ReplaceAllLiteralString(text, corev2.Redacted)
+	}
+	return text
+}
+
+// redactPath redacts the field of v at path, where each segment of the path
+// is the JSON name of a field or the key of a map. The fields of the elements
+// of slices are redacted.
+func redactPath(v reflect.Value, path []string) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		field, ok := fieldByJSONName(v, path[0])
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			redactField(field)
+			return
+		}
+		redactPath(field, path[1:])
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		key := reflect.ValueOf(path[0]).Convert(v.Type().Key())
+		value := v.MapIndex(key)
+		if !value.IsValid() {
+			return
+		}
+		if len(path) > 1 {
+			// The values of maps can't be set in place
+			redactPath(value, path[1:])
+			return
+		}
+		if value.Kind() == reflect.String {
+			v.SetMapIndex(key, reflect.ValueOf(corev2.Redacted).Convert(v.Type().Elem()))
+		} else {
+			v.SetMapIndex(key, reflect.Value{})
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			redactPath(v.Index(i), path)
+		}
+	}
+}
+
+// fieldByJSONName returns the field of the struct v named name in JSON,
+// including the fields of the structs embedded without a JSON name.
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if tag == "" && field.Anonymous {
+			if f, ok := fieldByJSONName(reflect.Indirect(v.Field(i)), name); ok {
+				return f, true
+			}
+			continue
+		}
+		if tag == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func redactField(field reflect.Value) {
+	if !field.CanSet() {
+		return
+	}
+	if field.Kind() == reflect.String {
+		field.SetString(corev2.Redacted)
+		return
+	}
+	field.Set(reflect.Zero(field.Type()))
+}
+
+// redactionPolicies are the redaction policies of the namespaces. A nil
+// *redactionPolicies redacts nothing.
+type redactionPolicies struct {
+	mu       sync.RWMutex
+	policies map[string]*redactionPolicy
+}
+
+func newRedactionPolicies() *redactionPolicies {
+	return &redactionPolicies{policies: make(map[string]*redactionPolicy)}
+}
+
+// Refresh replaces the policies with the policies of the namespaces of s.
+func (r *redactionPolicies) Refresh(ctx context.Context, s storev2.Interface) error {
+	namespaces, err := storev2.Of[*corev3.Namespace](s).List(ctx, storev2.ID{}, nil)
+	if err != nil {
+		return err
+	}
+	policies := make(map[string]*redactionPolicy)
+	for _, namespace := range namespaces {
+		policy, err := newRedactionPolicy(namespace)
+		if err != nil {
+			logger.WithError(err).WithField("namespace", namespace.Metadata.Name).Error("ignoring invalid redaction rules")
+		}
+		if policy != nil {
+			policies[namespace.Metadata.Name] = policy
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policies = policies
+	return nil
+}
+
+// Redact redacts event according to the policy of its namespace.
+func (r *redactionPolicies) Redact(event *corev2.Event) {
+	if r == nil {
+		return
+	}
+	namespace := event.Namespace
+	if event.Entity != nil {
+		namespace = event.Entity.Namespace
+	}
+	r.mu.RLock()
+	policy := r.policies[namespace]
+	r.mu.RUnlock()
+	if policy != nil {
+		policy.Redact(event)
+	}
+}
@@ -0,0 +1,115 @@
+package eventd
+
+import (
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+)
+
+func redactionNamespace(annotations map[string]string) *corev3.Namespace {
+	namespace := corev3.FixtureNamespace("default")
+	namespace.Metadata.Annotations = annotations
+	return namespace
+}
+
+func TestNewRedactionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		policy      bool
+		err         bool
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name:        "keys",
+			annotations: map[string]string{RedactKeysAnnotation: "password, api_key"},
+			policy:      true,
+		},
+		{
+			name:        "invalid pattern",
+			annotations: map[string]string{RedactPatternsAnnotation: `["("]`},
+			err:         true,
+		},
+		{
+			name:        "patterns must be an array",
+			annotations: map[string]string{RedactPatternsAnnotation: "secret"},
+			err:         true,
+		},
+		{
+			name: "names can't be redacted",
+			annotations: map[string]string{
+				RedactPathsAnnotation: "entity.metadata.name, check.output",
+			},
+			policy: true,
+			err:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := newRedactionPolicy(redactionNamespace(tt.annotations))
+			if (err != nil) != tt.err {
+				t.Fatalf("bad error: %v", err)
+			}
+			if (policy != nil) != tt.policy {
+				t.Fatalf("bad policy: %v", policy)
+			}
+		})
+	}
+}
+
+func TestRedactionPolicyRedact(t *testing.T) {
+	policy, err := newRedactionPolicy(redactionNamespace(map[string]string{
+		RedactKeysAnnotation:     "password",
+		RedactPathsAnnotation:    "entity.system.network, entity.metadata.labels.region, check.hooks.command",
+		RedactPatternsAnnotation: `["token=\\w+"]`,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := corev2.FixtureEvent("entity1", "check1")
+	event.Entity.System.Network.Interfaces = []corev2.NetworkInterface{{Name: "eth0"}}
+	event.Entity.Labels = map[string]string{"password": "hunter2", "region": "us", "url": "https://x?token=abc"}
+	event.Check.Annotations = map[string]string{"password": "hunter2"}
+	event.Check.EnvVars = []string{"password=hunter2", "PATH=/bin"}
+	event.Check.Output = "login token=abc failed"
+	event.Check.Hooks = []*corev2.Hook{{HookConfig: corev2.HookConfig{Command: "echo"}, Output: "token=def"}}
+
+	(&redactionPolicies{policies: map[string]*redactionPolicy{"default": policy}}).Redact(event)
+
+	checks := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"entity label by key", event.Entity.Labels["password"], corev2.Redacted},
+		{"entity label by path", event.Entity.Labels["region"], corev2.Redacted},
+		{"entity label by pattern", event.Entity.Labels["url"], "https://x?" + corev2.Redacted},
+		{"check annotation", event.Check.Annotations["password"], corev2.Redacted},
+		{"env var", event.Check.EnvVars[0], "password=" + corev2.Redacted},
+		{"other env var", event.Check.EnvVars[1], "PATH=/bin"},
+		{"check output", event.Check.Output, "login " + corev2.Redacted + " failed"},
+		{"hook output", event.Check.Hooks[0].Output, corev2.Redacted},
+		{"hook command", event.Check.Hooks[0].Command, corev2.Redacted},
+		{"entity name", event.Entity.Name, "entity1"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, c.got, c.want)
+		}
+	}
+	if len(event.Entity.System.Network.Interfaces) != 0 {
+		t.Errorf("entity network not redacted: %v", event.Entity.System.Network)
+	}
+
+	// Events of the other namespaces are not redacted
+	other := corev2.FixtureEvent("entity1", "check1")
+	other.Entity.Namespace = "ops"
+	other.Check.Output = "token=abc"
+	(&redactionPolicies{policies: map[string]*redactionPolicy{"default": policy}}).Redact(other)
+	if other.Check.Output != "token=abc" {
+		t.Errorf("event of another namespace redacted: %q", other.Check.Output)
+	}
+}
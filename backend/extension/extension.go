@@ -0,0 +1,193 @@
+// Package extension is the registry of the extensions of the backend. The
+// extensions implement the extension points of the backend: the secrets
+// providers, the authentication providers, the federation of clusters and the
+// license getter.
+//
+// Extensions register themselves in the init functions of their packages,
+// like the database/sql drivers. The packages compiled into sensu-backend are
+// selected with build tags, and the registered extensions are enabled by name
+// with the --extensions flag, so that forks can provide their own
+// implementations without patching the backend.
+package extension
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/apid/routers"
+	"github.com/sensu/sensu-go/backend/daemon"
+	"github.com/sensu/sensu-go/backend/licensing"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/secrets"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// Deps are the dependencies of the backend given to the extensions.
+type Deps struct {
+	Store storev2.Interface
+	Bus   messaging.MessageBus
+
+	// SecretsNamespaces are the namespaces allowed to use the secrets
+	// providers that are not resolved through namespaced resources.
+	SecretsNamespaces []string
+}
+
+// Extension is an extension of the backend. It implements one or more of the
+// SecretsExtension, AuthExtension, FederationExtension and LicenseExtension
+// interfaces.
+type Extension interface {
+	// Name is the name of the extension, which enables it.
+	Name() string
+}
+
+// SecretsExtension provides secrets providers, and the getter that resolves
+// the secrets of the checks, handlers and mutators.
+type SecretsExtension interface {
+	Extension
+	SecretsGetter(Deps) (secrets.Getter, error)
+	SecretsProviders(Deps) ([]secrets.Provider, error)
+}
+
+// AuthExtension provides authentication providers, which are added to the
+// built-in basic provider.
+type AuthExtension interface {
+	Extension
+	AuthProviders(Deps) ([]corev3.AuthProvider, error)
+}
+
+// FederationExtension provides the federation of clusters.
+type FederationExtension interface {
+	Extension
+	Federation(Deps) (Federation, error)
+}
+
+// LicenseExtension provides the license getter.
+type LicenseExtension interface {
+	Extension
+	LicenseGetter(Deps) (licensing.Getter, error)
+}
+
+// Federation federates the cluster with other clusters. It runs as a daemon
+// of the backends of the API role, whose API serves its routers. The routers
+// are mounted under /api/{group}/{version}/, outside of the core API group.
+type Federation interface {
+	daemon.Daemon
+	Routers() []routers.Router
+}
+
+var (
+	mu         sync.RWMutex
+	extensions = make(map[string]Extension)
+)
+
+// Register registers ext. It panics if ext is nil or if an extension of the
+// same name is already registered.
+func Register(ext Extension) {
+	mu.Lock()
+	defer mu.Unlock()
+	if ext == nil {
+		panic("extension: Register extension is nil")
+	}
+	if _, ok := extensions[ext.Name()]; ok {
+		panic(fmt.Sprintf("extension: Register called twice for extension %q", ext.Name()))
+	}
+	extensions[ext.Name()] = ext
+}
+
+// Registered returns the sorted names of the registered extensions.
+func Registered() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return registered()
+}
+
+func registered() []string {
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Extensions are the implementations of the extension points provided by the
+// enabled extensions. The extension points that no extension implements are
+// nil.
+type Extensions struct {
+	SecretsGetter    secrets.Getter
+	SecretsProviders []secrets.Provider
+	AuthProviders    []corev3.AuthProvider
+	Federation       Federation
+	LicenseGetter    licensing.Getter
+}
+
+// Load enables the extensions named names. The secrets getter, federation and
+// license getter can be provided by one enabled extension at most, while the
+// providers of all the enabled extensions are combined.
+func Load(names []string, deps Deps) (*Extensions, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	exts := &Extensions{}
+	// providers of the singular extension points, for error messages
+	var secretsGetter, federation, licenseGetter string
+	for _, name := range names {
+		ext, ok := extensions[name]
+		if !ok {
+			return nil, fmt.Errorf("extension %q is not registered, the registered extensions are %v", name, registered())
+		}
+		if ext, ok := ext.(SecretsExtension); ok {
+			getter, err := ext.SecretsGetter(deps)
+			if err != nil {
+				return nil, fmt.Errorf("error loading the secrets getter of extension %q: %s", name, err)
+			}
+			if getter != nil {
+				if secretsGetter != "" {
+					return nil, fmt.Errorf("extensions %q and %q both provide a secrets getter", secretsGetter, name)
+				}
+				secretsGetter = name
+				exts.SecretsGetter = getter
+			}
+			providers, err := ext.SecretsProviders(deps)
+			if err != nil {
+				return nil, fmt.Errorf("error loading the secrets providers of extension %q: %s", name, err)
+			}
+			exts.SecretsProviders = append(exts.SecretsProviders, providers...)
+		}
+		if ext, ok := ext.(AuthExtension); ok {
+			providers, err := ext.AuthProviders(deps)
+			if err != nil {
+				return nil, fmt.Errorf("error loading the authentication providers of extension %q: %s", name, err)
+			}
+			exts.AuthProviders = append(exts.AuthProviders, providers...)
+		}
+		if ext, ok := ext.(FederationExtension); ok {
+			fed, err := ext.Federation(deps)
+			if err != nil {
+				return nil, fmt.Errorf("error loading the federation of extension %q: %s", name, err)
+			}
+			if fed != nil {
+				if federation != "" {
+					return nil, fmt.Errorf("extensions %q and %q both provide a federation", federation, name)
+				}
+				federation = name
+				exts.Federation = fed
+			}
+		}
+		if ext, ok := ext.(LicenseExtension); ok {
+			getter, err := ext.LicenseGetter(deps)
+			if err != nil {
+				return nil, fmt.Errorf("error loading the license getter of extension %q: %s", name, err)
+			}
+			if getter != nil {
+				if licenseGetter != "" {
+					return nil, fmt.Errorf("extensions %q and %q both provide a license getter", licenseGetter, name)
+				}
+				licenseGetter = name
+				exts.LicenseGetter = getter
+			}
+		}
+	}
+	return exts, nil
+}
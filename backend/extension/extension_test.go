@@ -0,0 +1,115 @@
+package extension
+
+import (
+	"context"
+	"testing"
+
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/licensing"
+	"github.com/sensu/sensu-go/backend/secrets"
+)
+
+type testGetter struct{}
+
+func (testGetter) Get(ctx context.Context, name string) (string, string, error) {
+	return "test", name, nil
+}
+
+type testSecretsExtension struct {
+	name string
+}
+
+func (e testSecretsExtension) Name() string {
+	return e.name
+}
+
+func (e testSecretsExtension) SecretsGetter(Deps) (secrets.Getter, error) {
+	return testGetter{}, nil
+}
+
+func (e testSecretsExtension) SecretsProviders(Deps) ([]secrets.Provider, error) {
+	provider := secrets.NewEnvProvider()
+	provider.Metadata.Name = e.name
+	return []secrets.Provider{provider}, nil
+}
+
+type testAuthExtension struct {
+	name string
+}
+
+func (e testAuthExtension) Name() string {
+	return e.name
+}
+
+func (e testAuthExtension) AuthProviders(Deps) ([]corev3.AuthProvider, error) {
+	return []corev3.AuthProvider{nil}, nil
+}
+
+func (e testAuthExtension) LicenseGetter(Deps) (licensing.Getter, error) {
+	return &licensing.DummyGetter{}, nil
+}
+
+func withRegistry(t *testing.T) {
+	t.Helper()
+	registry := extensions
+	extensions = make(map[string]Extension)
+	t.Cleanup(func() {
+		extensions = registry
+	})
+}
+
+func TestLoad(t *testing.T) {
+	withRegistry(t)
+	Register(testSecretsExtension{name: "secrets"})
+	Register(testAuthExtension{name: "auth"})
+
+	if got, want := Registered(), []string{"auth", "secrets"}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("Registered() = %v, want %v", got, want)
+	}
+
+	exts, err := Load([]string{"secrets", "auth"}, Deps{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exts.SecretsGetter == nil || len(exts.SecretsProviders) != 1 {
+		t.Error("expected the secrets extension points to be loaded")
+	}
+	if len(exts.AuthProviders) != 1 || exts.LicenseGetter == nil {
+		t.Error("expected the auth and license extension points to be loaded")
+	}
+	if exts.Federation != nil {
+		t.Error("expected no federation")
+	}
+
+	exts, err = Load([]string{"auth"}, Deps{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exts.SecretsGetter != nil {
+		t.Error("expected the secrets extension not to be enabled")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	withRegistry(t)
+	Register(testSecretsExtension{name: "secrets"})
+	Register(testSecretsExtension{name: "other-secrets"})
+
+	if _, err := Load([]string{"unknown"}, Deps{}); err == nil {
+		t.Error("expected an error for an unregistered extension")
+	}
+	if _, err := Load([]string{"secrets", "other-secrets"}, Deps{}); err == nil {
+		t.Error("expected an error for two secrets getters")
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	withRegistry(t)
+	Register(testSecretsExtension{name: "secrets"})
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	Register(testSecretsExtension{name: "secrets"})
+}
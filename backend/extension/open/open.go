@@ -0,0 +1,43 @@
+// Package open registers the open reference extension of the backend, named
+// "open". It provides the secrets.EnvProvider secrets provider, the
+// secrets.PathGetter secrets getter and the licensing.DummyGetter license
+// getter. It provides no federation, and no authentication provider besides
+// the built-in basic provider.
+package open
+
+import (
+	"github.com/sensu/sensu-go/backend/extension"
+	"github.com/sensu/sensu-go/backend/licensing"
+	"github.com/sensu/sensu-go/backend/secrets"
+)
+
+// Name is the name of the open extension.
+const Name = "open"
+
+func init() {
+	extension.Register(Extension{})
+}
+
+// Extension is the open reference extension.
+type Extension struct{}
+
+// Name returns the name of the extension.
+func (Extension) Name() string {
+	return Name
+}
+
+// SecretsGetter returns a secrets.PathGetter, which resolves the secrets of
+// the namespaces in deps.SecretsNamespaces.
+func (Extension) SecretsGetter(deps extension.Deps) (secrets.Getter, error) {
+	return secrets.PathGetter{Namespaces: deps.SecretsNamespaces}, nil
+}
+
+// SecretsProviders returns a secrets.EnvProvider.
+func (Extension) SecretsProviders(extension.Deps) ([]secrets.Provider, error) {
+	return []secrets.Provider{secrets.NewEnvProvider()}, nil
+}
+
+// LicenseGetter returns a licensing.DummyGetter.
+func (Extension) LicenseGetter(extension.Deps) (licensing.Getter, error) {
+	return &licensing.DummyGetter{}, nil
+}
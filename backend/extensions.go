@@ -0,0 +1,35 @@
+package backend
+
+import (
+	"github.com/sensu/sensu-go/backend/extension"
+)
+
+// loadExtensions enables the extensions of config, or every registered
+// extension if config enables none. The license getter of config takes
+// precedence over the license getter of the extensions. It must be called
+// once the store, bus and secrets provider manager of b are initialized.
+func (b *Backend) loadExtensions(config *Config) (*extension.Extensions, error) {
+	names := config.Extensions
+	if len(names) == 0 {
+		names = extension.Registered()
+	}
+	exts, err := extension.Load(names, extension.Deps{
+		Store:             b.Store,
+		Bus:               b.Bus,
+		SecretsNamespaces: config.SecretsNamespaces,
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.WithField("extensions", names).Info("extensions enabled")
+	if b.LicenseGetter == nil {
+		b.LicenseGetter = exts.LicenseGetter
+	}
+	if exts.SecretsGetter != nil {
+		b.SecretsProviderManager.Getter = exts.SecretsGetter
+	}
+	for _, provider := range exts.SecretsProviders {
+		b.SecretsProviderManager.AddProvider(provider)
+	}
+	return exts, nil
+}
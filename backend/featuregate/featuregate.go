@@ -0,0 +1,269 @@
+// Package featuregate allows experimental backend subsystems to ship disabled,
+// and to be enabled per deployment with --feature-gates.
+package featuregate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blang/semver/v4"
+)
+
+// Feature is the name of a feature gate.
+type Feature string
+
+// Stage is the maturity of a feature.
+type Stage string
+
+const (
+	// Alpha features are disabled by default, and may change or be removed
+	// without notice.
+	Alpha Stage = "alpha"
+
+	// Beta features are well tested, but may still change.
+	Beta Stage = "beta"
+
+	// GA features are stable. Their gate is kept for a while, so that
+	// deployments that set it keep working, and is then removed.
+	GA Stage = "ga"
+
+	// Deprecated features are going away.
+	Deprecated Stage = "deprecated"
+)
+
+// Spec describes a feature gate.
+type Spec struct {
+	// Default is the value of the gate when it is not set.
+	Default bool
+
+	// Stage is the maturity of the feature.
+	Stage Stage
+
+	// Description is a short description of the feature.
+	Description string
+
+	// MinClusterVersion is the version that every backend of the cluster must
+	// run before the feature can be enabled. It is used by features that are
+	// not compatible with older backends, so that they are only enabled once a
+	// rolling upgrade has completed.
+	MinClusterVersion string
+}
+
+const (
+	// EventPriority schedules the events processed by eventd by priority, and
+	// sheds repeated OK events when eventd is overloaded.
+	EventPriority Feature = "EventPriority"
+
+	// KeepaliveSharding shards the keepalives processed by keepalived across
+	// its workers by entity, so that the keepalives of an entity are processed
+	// in order by the same worker.
+	KeepaliveSharding Feature = "KeepaliveSharding"
+
+	// OrderedEvents shards the events processed by eventd and pipelined
+	// across their workers by entity and check, so that the events of a check
+	// are processed in the order in which they are received.
+	OrderedEvents Feature = "OrderedEvents"
+)
+
+// DefaultFeatures are the feature gates known to sensu-backend. Subsystems
+// add their gates here.
+var DefaultFeatures = map[Feature]Spec{
+	EventPriority: {
+		Default:     false,
+		Stage:       Alpha,
+		Description: "Process keepalives and state changes first, and shed repeated OK events under load",
+	},
+	KeepaliveSharding: {
+		Default:     false,
+		Stage:       Alpha,
+		Description: "Shard keepalive processing across keepalived workers by entity",
+	},
+	OrderedEvents: {
+		Default:     false,
+		Stage:       Alpha,
+		Description: "Process the events of each entity and check in order, sharding eventd and pipelined workers by entity and check",
+	},
+}
+
+// Status is the state of a feature gate.
+type Status struct {
+	Name    Feature `json:"name"`
+	Enabled bool    `json:"enabled"`
+	Default bool    `json:"default"`
+	Stage   Stage   `json:"stage"`
+
+	// Pending is true if the feature is set to be enabled, but is waiting for
+	// every backend of the cluster to be upgraded.
+	Pending bool `json:"pending,omitempty"`
+
+	// MinClusterVersion is the version that every backend of the cluster must
+	// run before the feature can be enabled.
+	MinClusterVersion string `json:"min_cluster_version,omitempty"`
+}
+
+// Gates is a set of feature gates. A nil *Gates reports the default value of
+// every feature in DefaultFeatures. Gates is safe for concurrent use.
+type Gates struct {
+	known map[Feature]Spec
+
+	mu             sync.RWMutex
+	enabled        map[Feature]bool
+	clusterVersion *semver.Version
+}
+
+// New creates a set of gates for the known features.
+func New(known map[Feature]Spec) *Gates {
+	return &Gates{
+		known:   known,
+		enabled: make(map[Feature]bool),
+	}
+}
+
+// NewDefault creates a set of gates for DefaultFeatures.
+func NewDefault() *Gates {
+	return New(DefaultFeatures)
+}
+
+// Set parses a comma-separated list of Feature=bool pairs, such as
+// "NewBus=true,Dedup=false", and sets the gates accordingly. Unknown features
+// are an error, and none of the gates are set if an error is returned.
+func (g *Gates) Set(value string) error {
+	values := make(map[string]bool)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid feature gate %q: expected Feature=bool", pair)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("invalid value for feature gate %s: %s", name, v)
+		}
+		values[strings.TrimSpace(name)] = enabled
+	}
+	return g.SetFromMap(values)
+}
+
+// SetFromMap sets the gates of values. Unknown features are an error, and none
+// of the gates are set if an error is returned.
+func (g *Gates) SetFromMap(values map[string]bool) error {
+	for name := range values {
+		if _, ok := g.known[Feature(name)]; !ok {
+			return fmt.Errorf("unknown feature gate: %s", name)
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for name, enabled := range values {
+		g.enabled[Feature(name)] = enabled
+	}
+	return nil
+}
+
+// SetClusterVersion sets the version of the cluster, which is the lowest
+// version run by its backends, or nil if it is unknown. Features that specify
+// a MinClusterVersion are disabled until the cluster version is known, and at
+// least MinClusterVersion.
+func (g *Gates) SetClusterVersion(version *semver.Version) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.clusterVersion = version
+}
+
+// ClusterVersion returns the version of the cluster, as computed from the
+// versions of its members, or nil if it is unknown.
+func (g *Gates) ClusterVersion() *semver.Version {
+	if g == nil {
+		return nil
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.clusterVersion
+}
+
+// Enabled returns true if the feature is enabled. Unknown features are never
+// enabled.
+func (g *Gates) Enabled(feature Feature) bool {
+	set, pending := g.state(feature)
+	return set && !pending
+}
+
+// state returns whether the feature is set to be enabled, and whether it is
+// waiting for the cluster to be upgraded.
+func (g *Gates) state(feature Feature) (set, pending bool) {
+	if g == nil {
+		return DefaultFeatures[feature].Default, false
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	spec := g.known[feature]
+	set = spec.Default
+	if enabled, ok := g.enabled[feature]; ok {
+		set = enabled
+	}
+	if !set || spec.MinClusterVersion == "" {
+		return set, false
+	}
+	min, err := semver.ParseTolerant(spec.MinClusterVersion)
+	if err != nil {
+		// a programming error; keep the feature disabled
+		return set, true
+	}
+	return set, g.clusterVersion == nil || g.clusterVersion.LT(min)
+}
+
+// List returns the status of every known feature gate, sorted by name.
+func (g *Gates) List() []Status {
+	known := DefaultFeatures
+	if g != nil {
+		known = g.known
+	}
+	statuses := make([]Status, 0, len(known))
+	for name, spec := range known {
+		set, pending := g.state(name)
+		statuses = append(statuses, Status{
+			Name:              name,
+			Enabled:           set && !pending,
+			Default:           spec.Default,
+			Stage:             spec.Stage,
+			Pending:           pending,
+			MinClusterVersion: spec.MinClusterVersion,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// EnabledFeatures returns the enabled features, sorted by name.
+func (g *Gates) EnabledFeatures() []Feature {
+	var features []Feature
+	for _, status := range g.List() {
+		if status.Enabled {
+			features = append(features, status.Name)
+		}
+	}
+	return features
+}
+
+// String returns the gates that differ from their default, in the format
+// accepted by Set.
+func (g *Gates) String() string {
+	var pairs []string
+	for _, status := range g.List() {
+		if set := status.Enabled || status.Pending; set != status.Default {
+			pairs = append(pairs, fmt.Sprintf("%s=%t", status.Name, set))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,139 @@
+package featuregate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blang/semver/v4"
+)
+
+const (
+	testAlpha Feature = "TestAlpha"
+	testBeta  Feature = "TestBeta"
+)
+
+var testFeatures = map[Feature]Spec{
+	testAlpha: {Default: false, Stage: Alpha},
+	testBeta:  {Default: true, Stage: Beta},
+}
+
+func TestGatesSet(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		wantAlpha bool
+		wantBeta  bool
+		wantErr   bool
+	}{
+		{
+			name:      "defaults",
+			value:     "",
+			wantAlpha: false,
+			wantBeta:  true,
+		},
+		{
+			name:      "enable and disable",
+			value:     "TestAlpha=true, TestBeta=false",
+			wantAlpha: true,
+			wantBeta:  false,
+		},
+		{
+			name:     "unknown feature",
+			value:    "TestAlpha=true,Unknown=true",
+			wantBeta: true,
+			wantErr:  true,
+		},
+		{
+			name:     "bad value",
+			value:    "TestAlpha=yes please",
+			wantBeta: true,
+			wantErr:  true,
+		},
+		{
+			name:     "missing value",
+			value:    "TestAlpha",
+			wantBeta: true,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gates := New(testFeatures)
+			if err := gates.Set(tt.value); (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := gates.Enabled(testAlpha); got != tt.wantAlpha {
+				t.Errorf("Enabled(%s) = %v, want %v", testAlpha, got, tt.wantAlpha)
+			}
+			if got := gates.Enabled(testBeta); got != tt.wantBeta {
+				t.Errorf("Enabled(%s) = %v, want %v", testBeta, got, tt.wantBeta)
+			}
+		})
+	}
+}
+
+func TestGatesList(t *testing.T) {
+	gates := New(testFeatures)
+	if err := gates.Set("TestAlpha=true"); err != nil {
+		t.Fatal(err)
+	}
+	want := []Status{
+		{Name: testAlpha, Enabled: true, Default: false, Stage: Alpha},
+		{Name: testBeta, Enabled: true, Default: true, Stage: Beta},
+	}
+	if got := gates.List(); !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+	if got, want := gates.String(), "TestAlpha=true"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if err := gates.Set("TestBeta=false"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gates.EnabledFeatures(), []Feature{testAlpha}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EnabledFeatures() = %v, want %v", got, want)
+	}
+}
+
+func TestNilGates(t *testing.T) {
+	var gates *Gates
+	if gates.Enabled(testAlpha) {
+		t.Error("unknown features should never be enabled")
+	}
+	if got := gates.List(); len(got) != len(DefaultFeatures) {
+		t.Errorf("List() returned %d gates, want %d", len(got), len(DefaultFeatures))
+	}
+}
+
+func TestGatesClusterVersion(t *testing.T) {
+	const testUpgrade Feature = "TestUpgrade"
+	gates := New(map[Feature]Spec{
+		testUpgrade: {Default: true, Stage: Beta, MinClusterVersion: "7.1.0"},
+	})
+	if gates.Enabled(testUpgrade) {
+		t.Error("feature should be disabled until the cluster version is known")
+	}
+	if got := gates.List(); !got[0].Pending || got[0].MinClusterVersion != "7.1.0" {
+		t.Errorf("feature should be pending until 7.1.0: %+v", got[0])
+	}
+	old := semver.MustParse("7.0.2")
+	gates.SetClusterVersion(&old)
+	if got := gates.ClusterVersion(); got == nil || !got.EQ(old) {
+		t.Errorf("ClusterVersion() = %v, want %v", got, old)
+	}
+	if gates.Enabled(testUpgrade) {
+		t.Error("feature should be disabled until every backend is upgraded")
+	}
+	upgraded := semver.MustParse("7.1.0")
+	gates.SetClusterVersion(&upgraded)
+	if !gates.Enabled(testUpgrade) {
+		t.Error("feature should be enabled once every backend is upgraded")
+	}
+	if got := gates.String(); got != "" {
+		t.Errorf("String() = %q, want empty", got)
+	}
+	gates.SetClusterVersion(nil)
+	if gates.Enabled(testUpgrade) {
+		t.Error("feature should be disabled when the cluster version is unknown")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/sensu/sensu-go/backend/apid"
 	"github.com/sensu/sensu-go/backend/apid/actions"
 	"github.com/sensu/sensu-go/backend/apid/graphql"
+	"github.com/sensu/sensu-go/backend/apid/middlewares"
 	"github.com/sensu/sensu-go/backend/apid/routers"
 	"github.com/sensu/sensu-go/backend/authentication"
 	"github.com/sensu/sensu-go/backend/authentication/jwt"
@@ -94,6 +95,12 @@ func InitializeStore(ctx context.Context, db *pgxpool.Pool, config *Config) (*Ba
 	// Initialize the secrets provider manager
 	b.SecretsProviderManager = secrets.NewProviderManager(br)
 
+	// Enable the extensions
+	exts, err := b.loadExtensions(config)
+	if err != nil {
+		return nil, fmt.Errorf("error loading extensions: %s", err)
+	}
+
 	auth := &rbac.Authorizer{Store: b.Store}
 
 	// Initialize pipelined
@@ -196,6 +203,9 @@ func InitializeStore(ctx context.Context, db *pgxpool.Pool, config *Config) (*Ba
 			Store:                  b.Store,
 			Bus:                    bus,
 			SecretsProviderManager: b.SecretsProviderManager,
+			PublishRate:            viper.GetFloat64(FlagSchedulerPublishRate),
+			DispatchWindow:         viper.GetDuration(FlagSchedulerDispatchWindow),
+			PrioritySubscriptions:  viper.GetStringSlice(FlagSchedulerPrioritySubscriptions),
 		})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing %s: %s", scheduler.Name(), err)
@@ -221,6 +231,7 @@ func InitializeStore(ctx context.Context, db *pgxpool.Pool, config *Config) (*Ba
 		StoreTimeout:          2 * time.Minute,
 		OperatorConcierge:     pgOPC,
 		OperatorMonitor:       pgOPC,
+		OperatorQueryer:       pgOPC,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing %s: %s", keepalive.Name(), err)
@@ -234,6 +245,9 @@ func InitializeStore(ctx context.Context, db *pgxpool.Pool, config *Config) (*Ba
 		Store:      b.Store,
 	}
 	authenticator.AddProvider(provider)
+	for _, provider := range exts.AuthProviders {
+		authenticator.AddProvider(provider)
+	}
 
 	// Load the JWT key pair
 	if err := jwt.LoadKeyPair(viper.GetString(FlagJWTPrivateKeyFile), viper.GetString(FlagJWTPublicKeyFile)); err != nil {
@@ -258,7 +272,7 @@ func InitializeStore(ctx context.Context, db *pgxpool.Pool, config *Config) (*Ba
 		HookClient:        api.NewHookConfigClient(b.Store, auth),
 		UserClient:        api.NewUserClient(b.Store, auth),
 		RBACClient:        api.NewRBACClient(b.Store, auth),
-		VersionController: actions.NewVersionController("no version"),
+		VersionController: actions.NewVersionController("no version", config.FeatureGates),
 		MetricGatherer:    prometheus.DefaultGatherer,
 		GenericClient:     &api.GenericClient{Store: b.Store, Auth: auth},
 	})
@@ -268,16 +282,28 @@ func InitializeStore(ctx context.Context, db *pgxpool.Pool, config *Config) (*Ba
 
 	// Initialize apid
 	b.APIDConfig = apid.Config{
-		ListenAddress:  config.APIListenAddress,
-		RequestLimit:   config.APIRequestLimit,
-		WriteTimeout:   config.APIWriteTimeout,
-		URL:            config.APIURL,
-		Bus:            bus,
-		Store:          b.Store,
-		TLS:            config.TLS,
-		Authenticator:  authenticator,
-		ClusterVersion: "no version",
-		GraphQLService: b.GraphQLService,
+		ListenAddress:        config.APIListenAddress,
+		RequestLimit:         config.APIRequestLimit,
+		EventsRequestLimit:   config.APIEventsRequestLimit,
+		BulkRequestLimit:     config.APIBulkRequestLimit,
+		WriteTimeout:         config.APIWriteTimeout,
+		ListCacheTTL:         config.APIListCacheTTL,
+		URL:                  config.APIURL,
+		Bus:                  bus,
+		Store:                b.Store,
+		TLS:                  config.TLS,
+		Authenticator:        authenticator,
+		ClusterVersion:       "no version",
+		GraphQLService:       b.GraphQLService,
+		IdleTimeout:          config.APIIdleTimeout,
+		MaxConcurrentStreams: config.APIMaxConcurrentStreams,
+		ShutdownTimeout:      config.APIShutdownTimeout,
+		CORS: middlewares.CORS{
+			AllowedOrigins:   config.APICORSAllowedOrigins,
+			AllowedMethods:   config.APICORSAllowedMethods,
+			AllowedHeaders:   config.APICORSAllowedHeaders,
+			AllowCredentials: config.APICORSAllowCredentials,
+		},
 	}
 	newApi, err := apid.New(b.APIDConfig)
 	if err != nil {
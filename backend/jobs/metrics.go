@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// JobAttemptsCounterVec is the name of the counter of the completed job
+	// attempts.
+	JobAttemptsCounterVec = "sensu_go_job_attempts"
+
+	// JobDurationHistogramVec is the name of the histogram of the duration of
+	// the job attempts.
+	JobDurationHistogramVec = "sensu_go_job_duration_seconds"
+
+	// JobsRunningGaugeVec is the name of the gauge of the jobs that are
+	// running.
+	JobsRunningGaugeVec = "sensu_go_jobs_running"
+
+	// JobLabelKind is the kind label of the job metrics.
+	JobLabelKind = "kind"
+
+	// JobLabelResult is the result label of the job attempts: succeeded,
+	// failed, retried or interrupted.
+	JobLabelResult = "result"
+
+	resultSucceeded   = "succeeded"
+	resultFailed      = "failed"
+	resultRetried     = "retried"
+	resultInterrupted = "interrupted"
+)
+
+var (
+	// JobAttempts counts the completed job attempts.
+	JobAttempts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: JobAttemptsCounterVec,
+			Help: "The total number of completed background job attempts",
+		},
+		[]string{JobLabelKind, JobLabelResult},
+	)
+
+	// JobDuration is the distribution of the duration of the job attempts.
+	JobDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    JobDurationHistogramVec,
+			Help:    "The duration of the background job attempts",
+			Buckets: prometheus.ExponentialBuckets(0.1, 4, 8),
+		},
+		[]string{JobLabelKind},
+	)
+
+	// JobsRunning is the number of jobs that the runner is executing.
+	JobsRunning = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: JobsRunningGaugeVec,
+			Help: "The number of background jobs running",
+		},
+		[]string{JobLabelKind},
+	)
+)
+
+func init() {
+	for _, collector := range []prometheus.Collector{JobAttempts, JobDuration, JobsRunning} {
+		if err := prometheus.Register(collector); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,480 @@
+// Package jobs runs long running operations, such as the deletion of all the
+// events of an entity, in the background of the backends.
+//
+// Jobs are stored in the store, and queued for execution by the first runner
+// that reserves them. A failed job is retried with an exponential backoff,
+// until it succeeds or runs out of attempts. One runner of the cluster, elected
+// with a mutex, supervises the jobs: it queues the jobs whose retry is due,
+// and retries the jobs whose runner stopped.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+const (
+	componentName = "jobs"
+
+	// Queue is the name of the queue of the jobs to run. Each job is run by
+	// the first runner that reserves it.
+	Queue = "jobs"
+
+	// DefaultWorkers is the number of jobs that a runner executes
+	// concurrently when Config.Workers is zero.
+	DefaultWorkers = 2
+
+	// DefaultMaxAttempts is the number of attempts of a job when
+	// Config.MaxAttempts is zero.
+	DefaultMaxAttempts = 3
+
+	// DefaultRetryDelay is the delay before the first retry of a failed job
+	// when Config.RetryDelay is zero. The delay doubles with each attempt.
+	DefaultRetryDelay = 10 * time.Second
+
+	// DefaultStaleTimeout is the duration after which a running job whose
+	// runner stopped refreshing it is retried, when Config.StaleTimeout is
+	// zero.
+	DefaultStaleTimeout = time.Minute
+
+	// maxRetryDelay caps the exponential backoff of the retries.
+	maxRetryDelay = time.Hour
+
+	// progressInterval is the minimum interval between two updates of the
+	// progress of a job in the store.
+	progressInterval = time.Second
+)
+
+// errRunnerStopped is the error of the attempts of the jobs whose runner
+// stopped refreshing them.
+var errRunnerStopped = errors.New("the runner of the job stopped")
+
+// ProgressFunc reports that done units of work of a job out of total are
+// completed.
+type ProgressFunc func(done, total int)
+
+// Handler executes a job of a kind, reporting its progress to progress. A job
+// can be retried, so handlers must be idempotent.
+type Handler func(ctx context.Context, job *store.Job, progress ProgressFunc) error
+
+// Config configures a Runner.
+type Config struct {
+	Store store.JobStore
+	Queue queue.Client
+
+	// Executor elects the runner that supervises the jobs of the cluster.
+	Executor store.SynchronizedExecutor
+
+	// Workers is the number of jobs executed concurrently.
+	Workers int
+
+	// MaxAttempts is the number of attempts of the jobs submitted to the
+	// runner.
+	MaxAttempts int
+
+	// RetryDelay is the delay before the first retry of a failed job.
+	RetryDelay time.Duration
+
+	// StaleTimeout is the duration after which a running job whose runner
+	// stopped refreshing it is retried.
+	StaleTimeout time.Duration
+}
+
+// Runner is the daemon that executes the jobs submitted by the backends.
+type Runner struct {
+	store        store.JobStore
+	queue        queue.Client
+	executor     store.SynchronizedExecutor
+	workers      int
+	maxAttempts  int
+	retryDelay   time.Duration
+	staleTimeout time.Duration
+	handlers     map[string]Handler
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	errChan      chan error
+}
+
+// queueItem is the value of the queue item of a job.
+type queueItem struct {
+	Namespace string `json:"namespace"`
+	ID        string `json:"id"`
+}
+
+// NewRunner creates a new Runner.
+func NewRunner(cfg Config) *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Runner{
+		store:        cfg.Store,
+		queue:        cfg.Queue,
+		executor:     cfg.Executor,
+		workers:      cfg.Workers,
+		maxAttempts:  cfg.MaxAttempts,
+		retryDelay:   cfg.RetryDelay,
+		staleTimeout: cfg.StaleTimeout,
+		handlers:     make(map[string]Handler),
+		ctx:          ctx,
+		cancel:       cancel,
+		errChan:      make(chan error, 1),
+	}
+	if r.workers <= 0 {
+		r.workers = DefaultWorkers
+	}
+	if r.maxAttempts <= 0 {
+		r.maxAttempts = DefaultMaxAttempts
+	}
+	if r.retryDelay <= 0 {
+		r.retryDelay = DefaultRetryDelay
+	}
+	if r.staleTimeout <= 0 {
+		r.staleTimeout = DefaultStaleTimeout
+	}
+	return r
+}
+
+// Register registers the handler of the jobs of kind. Handlers must be
+// registered before the runner is started.
+func (r *Runner) Register(kind string, handler Handler) {
+	r.handlers[kind] = handler
+}
+
+// SubmitJob creates a pending job of kind in namespace, and queues it for
+// execution by the first available runner.
+func (r *Runner) SubmitJob(ctx context.Context, namespace, kind string, params map[string]string) (*store.Job, error) {
+	if _, ok := r.handlers[kind]; !ok {
+		return nil, fmt.Errorf("unknown job kind: %s", kind)
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	job := &store.Job{
+		ID:          id.String(),
+		Namespace:   namespace,
+		Kind:        kind,
+		Params:      params,
+		Status:      store.JobPending,
+		MaxAttempts: r.maxAttempts,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := r.store.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := r.enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetJob gets the job of namespace with the given id.
+func (r *Runner) GetJob(ctx context.Context, namespace, id string) (*store.Job, error) {
+	return r.store.GetJob(ctx, namespace, id)
+}
+
+// ListJobs lists the jobs of namespace that have the given status, or any
+// status if status is empty.
+func (r *Runner) ListJobs(ctx context.Context, namespace string, status store.JobStatus) ([]*store.Job, error) {
+	return r.store.ListJobs(ctx, namespace, status)
+}
+
+// Start starts the workers and the supervisor of the runner.
+func (r *Runner) Start() error {
+	for i := 0; i < r.workers; i++ {
+		r.wg.Add(1)
+		go r.work()
+	}
+	if r.executor != nil {
+		r.wg.Add(1)
+		go r.supervise()
+	}
+	return nil
+}
+
+// Stop stops the runner. The jobs that it is executing are interrupted, and
+// resumed by another runner.
+func (r *Runner) Stop() error {
+	r.cancel()
+	r.wg.Wait()
+	close(r.errChan)
+	return nil
+}
+
+// Err returns a channel on which to listen for terminal errors.
+func (r *Runner) Err() <-chan error {
+	return r.errChan
+}
+
+// Name returns the daemon name.
+func (r *Runner) Name() string {
+	return componentName
+}
+
+func (r *Runner) enqueue(ctx context.Context, job *store.Job) error {
+	value, err := json.Marshal(queueItem{Namespace: job.Namespace, ID: job.ID})
+	if err != nil {
+		return err
+	}
+	if err := r.queue.Enqueue(ctx, queue.Item{Queue: Queue, Value: value}); err != nil {
+		return fmt.Errorf("could not queue job: %w", err)
+	}
+	return nil
+}
+
+func (r *Runner) work() {
+	defer r.wg.Done()
+	for {
+		reservation, err := r.queue.Reserve(r.ctx, Queue)
+		if err != nil {
+			if r.ctx.Err() != nil {
+				return
+			}
+			logger.WithError(err).Error("could not reserve job")
+			select {
+			case <-time.After(progressInterval):
+				continue
+			case <-r.ctx.Done():
+				return
+			}
+		}
+		job, err := r.claim(reservation)
+		if err != nil {
+			logger.WithError(err).Error("could not claim job")
+			continue
+		}
+		if job != nil {
+			r.run(job)
+		}
+	}
+}
+
+// claim marks the job of reservation as running and acknowledges the
+// reservation, so that the queue is not held for the duration of the job. It
+// returns a nil job if the job no longer exists or is not pending.
+func (r *Runner) claim(reservation queue.Reservation) (*store.Job, error) {
+	var item queueItem
+	if err := json.Unmarshal(reservation.Item().Value, &item); err != nil {
+		// The item can never be handled
+		_ = reservation.Ack(r.ctx)
+		return nil, fmt.Errorf("invalid queue item: %w", err)
+	}
+	job, err := r.store.GetJob(r.ctx, item.Namespace, item.ID)
+	if err != nil {
+		var notFound *store.ErrNotFound
+		if errors.As(err, &notFound) {
+			return nil, reservation.Ack(r.ctx)
+		}
+		_ = reservation.Nack(r.ctx)
+		return nil, err
+	}
+	if job.Status != store.JobPending || !job.RetryAt.IsZero() {
+		// The job was queued more than once
+		return nil, reservation.Ack(r.ctx)
+	}
+	job.Status = store.JobRunning
+	job.Attempts++
+	job.UpdatedAt = time.Now().UTC()
+	if err := r.store.UpdateJob(r.ctx, job); err != nil {
+		_ = reservation.Nack(r.ctx)
+		return nil, err
+	}
+	return job, reservation.Ack(r.ctx)
+}
+
+// runningJob is a job that a worker is executing. Its progress is reported by
+// its handler while the worker refreshes it.
+type runningJob struct {
+	mu  sync.Mutex
+	job *store.Job
+}
+
+func (r *Runner) run(job *store.Job) {
+	fields := map[string]interface{}{"namespace": job.Namespace, "job": job.ID, "kind": job.Kind, "attempt": job.Attempts}
+	logger.WithFields(fields).Info("running job")
+
+	running := &runningJob{job: job}
+	gauge := JobsRunning.WithLabelValues(job.Kind)
+	gauge.Inc()
+	defer gauge.Dec()
+	start := time.Now()
+
+	// Refresh the job while it runs, so that the supervisor does not retry it
+	heartbeatCtx, stopHeartbeat := context.WithCancel(r.ctx)
+	heartbeat := make(chan struct{})
+	go func() {
+		defer close(heartbeat)
+		ticker := time.NewTicker(r.staleTimeout / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.update(running)
+			case <-heartbeatCtx.Done():
+				return
+			}
+		}
+	}()
+
+	var err error
+	if handler, ok := r.handlers[job.Kind]; ok {
+		var lastUpdate time.Time
+		err = handler(r.ctx, job, func(done, total int) {
+			running.mu.Lock()
+			job.Done, job.Total = done, total
+			running.mu.Unlock()
+			if time.Since(lastUpdate) < progressInterval {
+				return
+			}
+			lastUpdate = time.Now()
+			r.update(running)
+		})
+	} else {
+		err = fmt.Errorf("unknown job kind: %s", job.Kind)
+	}
+	stopHeartbeat()
+	<-heartbeat
+
+	JobDuration.WithLabelValues(job.Kind).Observe(time.Since(start).Seconds())
+	running.mu.Lock()
+	result := r.complete(job, err)
+	running.mu.Unlock()
+	JobAttempts.WithLabelValues(job.Kind, result).Inc()
+
+	entry := logger.WithFields(fields).WithField("result", result)
+	if err != nil {
+		entry = entry.WithError(err)
+	}
+	if result == resultFailed {
+		entry.Error("job failed")
+	} else {
+		entry.Info("job attempt completed")
+	}
+	r.update(running)
+}
+
+// complete records the result of an attempt of job. A failed job is retried
+// until it runs out of attempts, and a job interrupted by the stop of its
+// runner is resumed by another runner without consuming an attempt.
+func (r *Runner) complete(job *store.Job, err error) string {
+	switch {
+	case err == nil:
+		job.Status = store.JobSucceeded
+		job.Error = ""
+		return resultSucceeded
+	case r.ctx.Err() != nil:
+		job.Status = store.JobPending
+		job.Attempts--
+		job.RetryAt = time.Now().UTC()
+		return resultInterrupted
+	}
+	return r.fail(job, err)
+}
+
+// fail records a failed attempt of job, and schedules its retry if it has
+// attempts left.
+func (r *Runner) fail(job *store.Job, err error) string {
+	job.Error = err.Error()
+	if job.Attempts >= job.MaxAttempts {
+		job.Status = store.JobFailed
+		return resultFailed
+	}
+	delay := r.retryDelay << (job.Attempts - 1)
+	if delay > maxRetryDelay || delay <= 0 {
+		delay = maxRetryDelay
+	}
+	job.Status = store.JobPending
+	job.RetryAt = time.Now().UTC().Add(delay)
+	return resultRetried
+}
+
+func (r *Runner) update(running *runningJob) {
+	running.mu.Lock()
+	running.job.UpdatedAt = time.Now().UTC()
+	job := *running.job
+	running.mu.Unlock()
+	// The final status of a job is recorded even if the runner is stopping
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := r.store.UpdateJob(ctx, &job); err != nil {
+		logger.WithError(err).WithField("job", job.ID).Error("could not update job")
+	}
+}
+
+// supervise supervises the jobs of the cluster while the runner holds the
+// jobs mutex.
+func (r *Runner) supervise() {
+	defer r.wg.Done()
+	for r.ctx.Err() == nil {
+		err := r.executor.Execute(r.ctx, store.MutexJobs, func(ctx context.Context) error {
+			logger.Info("supervising jobs")
+			ticker := time.NewTicker(r.staleTimeout / 2)
+			defer ticker.Stop()
+			for {
+				r.recover(ctx)
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		})
+		if err != nil && r.ctx.Err() == nil {
+			logger.WithError(err).Error("lost the supervision of jobs")
+		}
+	}
+}
+
+// recover queues the pending jobs whose retry is due, and fails the attempts
+// of the running jobs whose runner stopped refreshing them.
+func (r *Runner) recover(ctx context.Context) {
+	now := time.Now()
+	running, err := r.store.ListJobs(ctx, "", store.JobRunning)
+	if err != nil {
+		logger.WithError(err).Error("could not list running jobs")
+		return
+	}
+	for _, job := range running {
+		if now.Sub(job.UpdatedAt) < r.staleTimeout {
+			continue
+		}
+		result := r.fail(job, errRunnerStopped)
+		JobAttempts.WithLabelValues(job.Kind, result).Inc()
+		job.UpdatedAt = now.UTC()
+		if err := r.store.UpdateJob(ctx, job); err != nil {
+			logger.WithError(err).WithField("job", job.ID).Error("could not update job")
+		}
+	}
+
+	pending, err := r.store.ListJobs(ctx, "", store.JobPending)
+	if err != nil {
+		logger.WithError(err).Error("could not list pending jobs")
+		return
+	}
+	for _, job := range pending {
+		if job.RetryAt.IsZero() || job.RetryAt.After(now) {
+			continue
+		}
+		job.RetryAt = time.Time{}
+		job.UpdatedAt = now.UTC()
+		if err := r.store.UpdateJob(ctx, job); err != nil {
+			logger.WithError(err).WithField("job", job.ID).Error("could not update job")
+			continue
+		}
+		if err := r.enqueue(ctx, job); err != nil {
+			logger.WithError(err).WithField("job", job.ID).Error("could not queue job")
+			// Retry with the next supervision
+			job.RetryAt = now.UTC()
+			_ = r.store.UpdateJob(ctx, job)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+type testJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]store.Job
+}
+
+func newTestJobStore() *testJobStore {
+	return &testJobStore{jobs: make(map[string]store.Job)}
+}
+
+func (s *testJobStore) CreateJob(ctx context.Context, job *store.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.Namespace+"/"+job.ID] = *job
+	return nil
+}
+
+func (s *testJobStore) GetJob(ctx context.Context, namespace, id string) (*store.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[namespace+"/"+id]
+	if !ok {
+		return nil, &store.ErrNotFound{Key: id}
+	}
+	return &job, nil
+}
+
+func (s *testJobStore) ListJobs(ctx context.Context, namespace string, status store.JobStatus) ([]*store.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := []*store.Job{}
+	for _, job := range s.jobs {
+		job := job
+		if (namespace == "" || job.Namespace == namespace) && (status == "" || job.Status == status) {
+			jobs = append(jobs, &job)
+		}
+	}
+	return jobs, nil
+}
+
+func (s *testJobStore) UpdateJob(ctx context.Context, job *store.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := job.Namespace + "/" + job.ID
+	if _, ok := s.jobs[key]; !ok {
+		return &store.ErrNotFound{Key: job.ID}
+	}
+	s.jobs[key] = *job
+	return nil
+}
+
+// testExecutor elects every runner.
+type testExecutor struct{}
+
+func (testExecutor) Execute(ctx context.Context, mux store.Mutex, handler store.MutexHandler) error {
+	return handler(ctx)
+}
+
+func newTestRunner(maxAttempts int) *Runner {
+	return NewRunner(Config{
+		Store:        newTestJobStore(),
+		Queue:        queue.NewMemoryClient(),
+		Executor:     testExecutor{},
+		MaxAttempts:  maxAttempts,
+		RetryDelay:   time.Millisecond,
+		StaleTimeout: 100 * time.Millisecond,
+	})
+}
+
+func waitJob(t *testing.T, runner *Runner, job *store.Job) *store.Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		got, err := runner.GetJob(context.Background(), job.Namespace, job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Completed() {
+			return got
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("job did not complete")
+	return nil
+}
+
+func TestRunner(t *testing.T) {
+	runner := newTestRunner(1)
+	runner.Register("count", func(ctx context.Context, job *store.Job, progress ProgressFunc) error {
+		for i := 1; i <= 3; i++ {
+			progress(i, 3)
+		}
+		return nil
+	})
+	runner.Register("fail", func(ctx context.Context, job *store.Job, progress ProgressFunc) error {
+		return errors.New("the job failed")
+	})
+	if err := runner.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = runner.Stop() }()
+
+	ctx := context.Background()
+	if _, err := runner.SubmitJob(ctx, "default", "unknown", nil); err == nil {
+		t.Fatal("expected an error for an unknown kind")
+	}
+
+	job, err := runner.SubmitJob(ctx, "default", "count", map[string]string{"key": "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != store.JobPending {
+		t.Fatalf("bad status: %s", job.Status)
+	}
+	got := waitJob(t, runner, job)
+	if got.Status != store.JobSucceeded || got.Done != 3 || got.Total != 3 {
+		t.Fatalf("bad job: %+v", got)
+	}
+
+	job, err = runner.SubmitJob(ctx, "default", "fail", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = waitJob(t, runner, job)
+	if got.Status != store.JobFailed || got.Error != "the job failed" || got.Attempts != 1 {
+		t.Fatalf("bad job: %+v", got)
+	}
+}
+
+func TestRunnerRetry(t *testing.T) {
+	runner := newTestRunner(3)
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	runner.Register("flaky", func(ctx context.Context, job *store.Job, progress ProgressFunc) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[job.ID]++
+		if attempts[job.ID] < 2 {
+			return errors.New("try again")
+		}
+		return nil
+	})
+	runner.Register("fail", func(ctx context.Context, job *store.Job, progress ProgressFunc) error {
+		return errors.New("the job failed")
+	})
+	if err := runner.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = runner.Stop() }()
+
+	ctx := context.Background()
+	job, err := runner.SubmitJob(ctx, "default", "flaky", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := waitJob(t, runner, job)
+	if got.Status != store.JobSucceeded || got.Attempts != 2 || got.Error != "" {
+		t.Fatalf("bad job: %+v", got)
+	}
+
+	job, err = runner.SubmitJob(ctx, "default", "fail", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = waitJob(t, runner, job)
+	if got.Status != store.JobFailed || got.Attempts != 3 {
+		t.Fatalf("bad job: %+v", got)
+	}
+}
+
+func TestRunnerRecoversStaleJobs(t *testing.T) {
+	runner := newTestRunner(2)
+	runner.Register("noop", func(ctx context.Context, job *store.Job, progress ProgressFunc) error {
+		return nil
+	})
+
+	// The runner of the job stopped during its first attempt
+	stale := &store.Job{
+		ID:          "stale",
+		Namespace:   "default",
+		Kind:        "noop",
+		Status:      store.JobRunning,
+		Attempts:    1,
+		MaxAttempts: 2,
+		UpdatedAt:   time.Now().Add(-time.Hour),
+	}
+	if err := runner.store.CreateJob(context.Background(), stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = runner.Stop() }()
+
+	got := waitJob(t, runner, stale)
+	if got.Status != store.JobSucceeded || got.Attempts != 2 {
+		t.Fatalf("bad job: %+v", got)
+	}
+}
@@ -0,0 +1,436 @@
+// Package kafkabus implements the message bus of the backend with the topics
+// of a Kafka cluster. The messages consumed by the pipeline and by the agent
+// sessions are written to Kafka, and are read back by the consumer groups of
+// their subscribers, or by a consumer group of each backend for the broadcast
+// topics, so that they are shared by the backends and kept for the external
+// consumers of the cluster, such as the consumers of the raw events.
+//
+// The messages are written as JSON to the Kafka topics named after the topic
+// prefix and the bus topics:
+//
+//	<prefix>.event-raw      the raw events of the agents
+//	<prefix>.keepalive-raw  the raw keepalives of the agents
+//	<prefix>.keepalive      the keepalives handled by keepalived
+//	<prefix>.check          the check requests of every subscription
+//	<prefix>.agent-conn     the connections and disconnections of the agents
+//	<prefix>.burial         the keepalive burials of the entities
+//
+// The headers of the messages carry their bus topic and kind, and the events
+// and agent notifications are partitioned by entity. The messages of the
+// other topics never leave the backend.
+package kafkabus
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/bridge"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultTopicPrefix is the prefix of the Kafka topics when
+	// Config.TopicPrefix is empty.
+	DefaultTopicPrefix = "sensu"
+
+	// HeaderTopic is the header of the bus topic of the messages.
+	HeaderTopic = "sensu-topic"
+
+	// HeaderKind is the header of the kind of the messages, such as event or
+	// check_request.
+	HeaderKind = "sensu-kind"
+
+	// HeaderOrigin is the header of the name of the backend that wrote the
+	// messages.
+	HeaderOrigin = "sensu-origin"
+
+	// batchTimeout is how long the writes wait for other messages to batch
+	// with.
+	batchTimeout = 10 * time.Millisecond
+
+	// commitInterval is the interval at which the offsets of the messages
+	// delivered to the subscribers are committed.
+	commitInterval = time.Second
+
+	// readMaxWait is how long a fetch waits for new messages.
+	readMaxWait = time.Second
+
+	// readRetryInterval is how long to wait before reading messages again
+	// after an error.
+	readRetryInterval = time.Second
+
+	// staleBroadcast is how long before a reader of a broadcast topic started
+	// the messages are written for the reader to drop them, so that the check
+	// requests and notifications missed by a consumer group are not
+	// delivered once it resumes.
+	staleBroadcast = 10 * time.Second
+)
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "kafkabus",
+})
+
+// Config configures a Bus.
+type Config struct {
+	// Bus is the message bus of the process, which delivers the messages of
+	// the topics that stay in the backend and the messages read from Kafka.
+	Bus messaging.MessageBus
+
+	// Brokers are the addresses of the Kafka brokers.
+	Brokers []string
+
+	// TLS is the TLS configuration of the connections to the brokers, or nil
+	// for plaintext connections.
+	TLS *tls.Config
+
+	// TopicPrefix is the prefix of the names of the Kafka topics and of the
+	// consumer groups.
+	TopicPrefix string
+
+	// BackendName is the name of the backend process, in the names of the
+	// consumer groups of the broadcast topics.
+	BackendName string
+}
+
+// Bus is a message bus that writes the messages of the topics consumed by
+// the pipeline and by the agent sessions to Kafka. The subscriptions to the
+// work topics read them with the consumer group of their consumer, shared
+// with the same consumers of the other backends. The broadcast topics, such
+// as the check requests of the agent sessions, are read once by each backend,
+// with a consumer group of its own, and their messages are delivered to the
+// subscribers of their bus topic in the backend.
+type Bus struct {
+	messaging.MessageBus
+
+	writer      *kafka.Writer
+	brokers     []string
+	tls         *tls.Config
+	prefix      string
+	backendName string
+
+	mu      sync.Mutex
+	readers map[readerKey]*reader
+}
+
+// readerKey identifies the reader of the Kafka topic of a consumer, shared by
+// its subscriptions to the bus topics of the Kafka topic. The consumer of the
+// readers of the broadcast topics is empty, since they are shared by all the
+// subscriptions of the backend.
+type readerKey struct {
+	topic    string
+	consumer string
+}
+
+type reader struct {
+	reader *kafka.Reader
+	refs   int
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New returns a Bus that writes to the brokers of c.
+func New(c Config) (*Bus, error) {
+	if c.Bus == nil {
+		return nil, errors.New("a message bus is required")
+	}
+	if len(c.Brokers) == 0 {
+		return nil, errors.New("at least one broker is required")
+	}
+	prefix := c.TopicPrefix
+	if prefix == "" {
+		prefix = DefaultTopicPrefix
+	}
+	return &Bus{
+		MessageBus: c.Bus,
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(c.Brokers...),
+			Balancer:               &kafka.Hash{},
+			BatchTimeout:           batchTimeout,
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+			Transport: &kafka.Transport{
+				ClientID: c.BackendName,
+				TLS:      c.TLS,
+			},
+		},
+		brokers:     c.Brokers,
+		tls:         c.TLS,
+		prefix:      prefix,
+		backendName: c.BackendName,
+		readers:     make(map[readerKey]*reader),
+	}, nil
+}
+
+// Stop stops the readers of the subscriptions, the writer, and the bus of
+// the process.
+func (b *Bus) Stop() error {
+	b.mu.Lock()
+	readers := b.readers
+	b.readers = make(map[readerKey]*reader)
+	b.mu.Unlock()
+	for key, r := range readers {
+		if err := r.close(); err != nil {
+			logger.WithError(err).WithField("topic", key.topic).Error("error closing kafka reader")
+		}
+	}
+	if err := b.writer.Close(); err != nil {
+		logger.WithError(err).Error("error closing kafka writer")
+	}
+	return b.MessageBus.Stop()
+}
+
+// Publish sends a message to a topic. The messages of the topics consumed by
+// the pipeline and by the agent sessions are written to Kafka, once the
+// brokers acknowledge them, and the others are published on the bus of the
+// process.
+func (b *Bus) Publish(topic string, message interface{}) error {
+	kafkaTopic, ok := b.kafkaTopic(topic)
+	if !ok {
+		return b.MessageBus.Publish(topic, message)
+	}
+	kind, err := bridge.MessageKind(message)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return b.writer.WriteMessages(context.Background(), kafka.Message{
+		Topic: kafkaTopic,
+		Key:   messageKey(topic, message),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: HeaderTopic, Value: []byte(topic)},
+			{Key: HeaderKind, Value: []byte(kind)},
+			{Key: HeaderOrigin, Value: []byte(b.backendName)},
+		},
+		Time: time.Now(),
+	})
+}
+
+// Subscribe binds subscriber to topic as consumer. The subscribers of the
+// work topics written to Kafka receive the messages read by the consumer
+// group of their consumer, and the subscribers of the broadcast topics the
+// messages read by the consumer group of the backend.
+func (b *Bus) Subscribe(topic string, consumer string, subscriber messaging.Subscriber) (messaging.Subscription, error) {
+	kafkaTopic, ok := b.kafkaTopic(topic)
+	if !ok {
+		return b.MessageBus.Subscribe(topic, consumer, subscriber)
+	}
+	broadcast := bridge.IsBroadcastTopic(topic)
+	key := readerKey{topic: kafkaTopic}
+	local := topic
+	if !broadcast {
+		// The messages read by the consumer are published on a topic of its
+		// own, so that the other consumers of the topic don't receive them
+		// twice
+		key.consumer = consumer
+		local = localTopic(topic, consumer)
+	}
+	localSubscription, err := b.MessageBus.Subscribe(local, consumer, subscriber)
+	if err != nil {
+		return messaging.Subscription{}, err
+	}
+	b.acquire(key, broadcast)
+	var once sync.Once
+	cancel := func(string) error {
+		once.Do(func() {
+			b.release(key)
+		})
+		return localSubscription.Cancel()
+	}
+	return messaging.NewSubscription(consumer, cancel, localSubscription.Broken()), nil
+}
+
+// acquire starts the reader of key, unless its consumer, or the backend for
+// the broadcast topics, already reads the Kafka topic.
+func (b *Bus) acquire(key readerKey, broadcast bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r, ok := b.readers[key]; ok {
+		r.refs++
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &reader{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: b.brokers,
+			GroupID: b.groupID(key.topic, key.consumer, broadcast),
+			Topic:   key.topic,
+			Dialer: &kafka.Dialer{
+				ClientID:  b.backendName,
+				Timeout:   10 * time.Second,
+				DualStack: true,
+				TLS:       b.tls,
+			},
+			MaxWait:        readMaxWait,
+			CommitInterval: commitInterval,
+			StartOffset:    kafka.LastOffset,
+		}),
+		refs:   1,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	b.readers[key] = r
+	var since time.Time
+	if broadcast {
+		since = time.Now().Add(-staleBroadcast)
+	}
+	go b.read(ctx, r, key, since)
+}
+
+// release stops the reader of key once its last subscription is cancelled.
+func (b *Bus) release(key readerKey) {
+	b.mu.Lock()
+	r, ok := b.readers[key]
+	if ok {
+		r.refs--
+		if r.refs > 0 {
+			ok = false
+		} else {
+			delete(b.readers, key)
+		}
+	}
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+	if err := r.close(); err != nil {
+		logger.WithError(err).WithField("topic", key.topic).Error("error closing kafka reader")
+	}
+}
+
+// read delivers the messages of the reader to its consumer, until ctx is
+// cancelled. The messages written before since are dropped. The offset of a
+// message is only committed once it is delivered: the delivery of a message
+// that can't be decoded, such as a message of a kind written by a newer
+// backend, is retried until it succeeds, so that the message is not lost.
+func (b *Bus) read(ctx context.Context, r *reader, key readerKey, since time.Time) {
+	defer close(r.done)
+	lager := logger.WithFields(logrus.Fields{
+		"topic":    key.topic,
+		"consumer": key.consumer,
+	})
+	for {
+		msg, err := r.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			lager.WithError(err).Error("error reading kafka message")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(readRetryInterval):
+			}
+			continue
+		}
+		if msg.Time.IsZero() || !msg.Time.Before(since) {
+			for {
+				err := b.deliver(msg, key.consumer)
+				if err == nil {
+					break
+				}
+				lager.WithError(err).WithFields(logrus.Fields{
+					"partition": msg.Partition,
+					"offset":    msg.Offset,
+				}).Error("error delivering kafka message, retrying")
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(readRetryInterval):
+				}
+			}
+		}
+		if err := r.reader.CommitMessages(ctx, msg); err != nil && ctx.Err() == nil {
+			lager.WithError(err).Error("error committing kafka message")
+		}
+	}
+}
+
+// deliver publishes a message read from Kafka to the subscribers of its
+// consumer, or to the subscribers of its bus topic if consumer is empty. It
+// returns an error if the message can't be decoded or published.
+func (b *Bus) deliver(msg kafka.Message, consumer string) error {
+	topic := header(msg, HeaderTopic)
+	if topic == "" {
+		return errors.New("missing " + HeaderTopic + " header")
+	}
+	message, err := bridge.DecodeMessage(header(msg, HeaderKind), msg.Value)
+	if err != nil {
+		return err
+	}
+	if consumer != "" {
+		topic = localTopic(topic, consumer)
+	}
+	return b.MessageBus.Publish(topic, message)
+}
+
+// kafkaTopic returns the Kafka topic of the messages of topic, and false if
+// they are not written to Kafka. The bus topics of the same kind, such as the
+// topics of the check subscriptions, share their Kafka topic.
+func (b *Bus) kafkaTopic(topic string) (string, bool) {
+	if !bridge.IsWorkTopic(topic) && !bridge.IsBroadcastTopic(topic) {
+		return "", false
+	}
+	name := strings.TrimPrefix(topic, "sensu:")
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name = name[:i]
+	}
+	return b.prefix + "." + name, true
+}
+
+// groupID returns the consumer group of consumer for kafkaTopic. Every
+// backend has a group of its own for the broadcast topics, since every
+// backend receives their messages.
+func (b *Bus) groupID(kafkaTopic, consumer string, broadcast bool) string {
+	if broadcast {
+		return kafkaTopic + "." + b.backendName
+	}
+	return kafkaTopic + "." + consumer
+}
+
+func (r *reader) close() error {
+	r.cancel()
+	<-r.done
+	return r.reader.Close()
+}
+
+// localTopic returns the topic of the bus of the process on which the
+// messages of topic read by consumer are published.
+func localTopic(topic, consumer string) string {
+	return topic + "@" + consumer
+}
+
+// messageKey returns the key of the partition of message: the entity of the
+// events and of the agent notifications, so that the messages of an entity
+// are read in order, and topic otherwise.
+func messageKey(topic string, message interface{}) []byte {
+	switch message := message.(type) {
+	case *corev2.Event:
+		if message.Entity != nil {
+			return []byte(message.Entity.Namespace + "/" + message.Entity.Name)
+		}
+	case messaging.AgentNotification:
+		return []byte(message.Namespace + "/" + message.Name)
+	}
+	return []byte(topic)
+}
+
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,246 @@
+package kafkabus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBus(t *testing.T, brokers []string, prefix, name string) *Bus {
+	t.Helper()
+	wizardBus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+	require.NoError(t, err)
+	bus, err := New(Config{
+		Bus:         wizardBus,
+		Brokers:     brokers,
+		TopicPrefix: prefix,
+		BackendName: name,
+	})
+	require.NoError(t, err)
+	require.NoError(t, bus.Start())
+	t.Cleanup(func() {
+		assert.NoError(t, bus.Stop())
+	})
+	return bus
+}
+
+func receive(t *testing.T, ch chan interface{}, timeout time.Duration) interface{} {
+	t.Helper()
+	select {
+	case message := <-ch:
+		return message
+	case <-time.After(timeout):
+		t.Fatal("no message received")
+		return nil
+	}
+}
+
+func TestNew(t *testing.T) {
+	wizardBus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+	require.NoError(t, err)
+	_, err = New(Config{Bus: wizardBus})
+	assert.Error(t, err)
+	_, err = New(Config{Brokers: []string{"localhost:9092"}})
+	assert.Error(t, err)
+
+	bus, err := New(Config{Bus: wizardBus, Brokers: []string{"localhost:9092"}})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTopicPrefix, bus.prefix)
+}
+
+func TestKafkaTopic(t *testing.T) {
+	bus := &Bus{prefix: "acme"}
+	tests := []struct {
+		topic string
+		want  string
+	}{
+		{topic: messaging.TopicEventRaw, want: "acme.event-raw"},
+		{topic: messaging.TopicKeepaliveRaw, want: "acme.keepalive-raw"},
+		{topic: messaging.TopicKeepalive, want: "acme.keepalive"},
+		{topic: messaging.TopicAgentConnectionState, want: "acme.agent-conn"},
+		{topic: messaging.SubscriptionTopic("default", "linux"), want: "acme.check"},
+		{topic: messaging.BurialTopic("default", "agent1"), want: "acme.burial"},
+		{topic: messaging.TopicEvent},
+		{topic: messaging.EntityConfigTopic("default", "agent1")},
+		{topic: messaging.TopicTessen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			got, ok := bus.kafkaTopic(tt.topic)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGroupID(t *testing.T) {
+	bus := &Bus{prefix: "sensu", backendName: "backend1"}
+	assert.Equal(t, "sensu.event-raw.eventd", bus.groupID("sensu.event-raw", "eventd", false))
+	assert.Equal(t, "sensu.check.backend1", bus.groupID("sensu.check", "", true))
+}
+
+func TestMessageKey(t *testing.T) {
+	event := corev2.FixtureEvent("agent1", "check")
+	assert.Equal(t, "default/agent1", string(messageKey(messaging.TopicEventRaw, event)))
+
+	notification := messaging.AgentNotification{Namespace: "acme", Name: "agent2"}
+	assert.Equal(t, "acme/agent2", string(messageKey(messaging.TopicAgentConnectionState, notification)))
+
+	topic := messaging.SubscriptionTopic("default", "linux")
+	assert.Equal(t, topic, string(messageKey(topic, corev2.FixtureCheckRequest("check"))))
+}
+
+func TestDeliver(t *testing.T) {
+	bus := newTestBus(t, []string{"localhost:9092"}, "", "backend1")
+	ch := make(chan interface{}, 10)
+	_, err := bus.MessageBus.Subscribe(localTopic(messaging.TopicEventRaw, "eventd"), "eventd", messaging.ChanSubscriber(ch))
+	require.NoError(t, err)
+
+	event := corev2.FixtureEvent("agent1", "check")
+	value, err := json.Marshal(event)
+	require.NoError(t, err)
+	require.NoError(t, bus.deliver(kafka.Message{
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: HeaderTopic, Value: []byte(messaging.TopicEventRaw)},
+			{Key: HeaderKind, Value: []byte("event")},
+		},
+	}, "eventd"))
+	assert.IsType(t, &corev2.Event{}, receive(t, ch, time.Second))
+
+	// The messages of the broadcast topics are delivered to the subscribers
+	// of their bus topic
+	topic := messaging.SubscriptionTopic("default", "linux")
+	for _, agent := range []string{"agent1", "agent2"} {
+		_, err := bus.MessageBus.Subscribe(topic, agent, messaging.ChanSubscriber(ch))
+		require.NoError(t, err)
+	}
+	request := corev2.FixtureCheckRequest("check")
+	value, err = json.Marshal(request)
+	require.NoError(t, err)
+	require.NoError(t, bus.deliver(kafka.Message{
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: HeaderTopic, Value: []byte(topic)},
+			{Key: HeaderKind, Value: []byte("check_request")},
+		},
+	}, ""))
+	for i := 0; i < 2; i++ {
+		received := receive(t, ch, time.Second)
+		require.IsType(t, &corev2.CheckRequest{}, received)
+		assert.Equal(t, "check", received.(*corev2.CheckRequest).Config.Name)
+	}
+
+	// The messages without a topic or with an unknown kind are not delivered
+	assert.Error(t, bus.deliver(kafka.Message{Value: value, Headers: []kafka.Header{{Key: HeaderKind, Value: []byte("check_request")}}}, ""))
+	assert.Error(t, bus.deliver(kafka.Message{Value: value, Headers: []kafka.Header{{Key: HeaderTopic, Value: []byte(topic)}}}, ""))
+	select {
+	case message := <-ch:
+		t.Fatalf("unexpected message: %v", message)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSubscribeReaders(t *testing.T) {
+	bus := newTestBus(t, []string{"localhost:1"}, "", "backend1")
+	ch := make(chan interface{}, 10)
+	subscriber := messaging.ChanSubscriber(ch)
+
+	// The subscriptions of the agent sessions to the topics of a broadcast
+	// Kafka topic share the reader of the backend
+	sub1, err := bus.Subscribe(messaging.SubscriptionTopic("default", "linux"), "agent1", subscriber)
+	require.NoError(t, err)
+	sub2, err := bus.Subscribe(messaging.SubscriptionTopic("acme", "windows"), "agent2", subscriber)
+	require.NoError(t, err)
+	key := readerKey{topic: "sensu.check"}
+	bus.mu.Lock()
+	assert.Len(t, bus.readers, 1)
+	assert.Equal(t, 2, bus.readers[key].refs)
+	bus.mu.Unlock()
+
+	require.NoError(t, sub1.Cancel())
+	require.NoError(t, sub1.Cancel())
+	bus.mu.Lock()
+	assert.Equal(t, 1, bus.readers[key].refs)
+	bus.mu.Unlock()
+	require.NoError(t, sub2.Cancel())
+	bus.mu.Lock()
+	assert.Empty(t, bus.readers)
+	bus.mu.Unlock()
+
+	// The messages of the other topics stay in the backend
+	_, err = bus.Subscribe(messaging.TopicEvent, "pipelined", subscriber)
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(messaging.TopicEvent, corev2.FixtureEvent("agent1", "check")))
+	assert.IsType(t, &corev2.Event{}, receive(t, ch, time.Second))
+	bus.mu.Lock()
+	assert.Empty(t, bus.readers)
+	bus.mu.Unlock()
+}
+
+// TestBusKafka writes and reads messages with the brokers of the
+// KAFKA_BROKERS environment variable.
+func TestBusKafka(t *testing.T) {
+	brokers := os.Getenv("KAFKA_BROKERS")
+	if brokers == "" {
+		t.Skip("skipping kafka test: KAFKA_BROKERS not set")
+	}
+	prefix := fmt.Sprintf("sensu-test-%d", time.Now().UnixNano())
+	backend1 := newTestBus(t, strings.Split(brokers, ","), prefix, "backend1")
+	backend2 := newTestBus(t, strings.Split(brokers, ","), prefix, "backend2")
+
+	// The consumers of the broadcast topics receive the messages in every
+	// backend
+	topic := messaging.SubscriptionTopic("default", "linux")
+	agent1 := make(chan interface{}, 10)
+	agent2 := make(chan interface{}, 10)
+	sub1, err := backend1.Subscribe(topic, "default:agent1-uuid", messaging.ChanSubscriber(agent1))
+	require.NoError(t, err)
+	defer sub1.Cancel()
+	sub2, err := backend2.Subscribe(topic, "default:agent2-uuid", messaging.ChanSubscriber(agent2))
+	require.NoError(t, err)
+	defer sub2.Cancel()
+
+	// The consumers of the work topics share the messages of the backends
+	eventd1 := make(chan interface{}, 10)
+	eventd2 := make(chan interface{}, 10)
+	sub3, err := backend1.Subscribe(messaging.TopicEventRaw, "eventd", messaging.ChanSubscriber(eventd1))
+	require.NoError(t, err)
+	defer sub3.Cancel()
+	sub4, err := backend2.Subscribe(messaging.TopicEventRaw, "eventd", messaging.ChanSubscriber(eventd2))
+	require.NoError(t, err)
+	defer sub4.Cancel()
+
+	// Wait for the consumer groups to join
+	time.Sleep(10 * time.Second)
+
+	require.NoError(t, backend1.Publish(topic, corev2.FixtureCheckRequest("check")))
+	assert.IsType(t, &corev2.CheckRequest{}, receive(t, agent1, 30*time.Second))
+	assert.IsType(t, &corev2.CheckRequest{}, receive(t, agent2, 30*time.Second))
+
+	require.NoError(t, backend2.Publish(messaging.TopicEventRaw, corev2.FixtureEvent("agent1", "check")))
+	select {
+	case message := <-eventd1:
+		assert.IsType(t, &corev2.Event{}, message)
+	case message := <-eventd2:
+		assert.IsType(t, &corev2.Event{}, message)
+	case <-time.After(30 * time.Second):
+		t.Fatal("no event received")
+	}
+	select {
+	case <-eventd1:
+		t.Fatal("event received twice")
+	case <-eventd2:
+		t.Fatal("event received twice")
+	case <-time.After(time.Second):
+	}
+}
@@ -0,0 +1,91 @@
+package keepalived
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+)
+
+const (
+	// EntityStateCheckName is the name of the check of entity state change
+	// events.
+	EntityStateCheckName = "entity_state"
+
+	// EntityStateAnnotation is the annotation of entity state change events
+	// that holds the new state of the entity.
+	EntityStateAnnotation = "sensu.io/entity_state"
+
+	// PreviousEntityStateAnnotation is the annotation of entity state change
+	// events that holds the previous state of the entity.
+	PreviousEntityStateAnnotation = "sensu.io/previous_entity_state"
+
+	// EntityStateHealthy is the state of an entity that sends keepalives.
+	EntityStateHealthy = "healthy"
+
+	// EntityStateUnhealthy is the state of an entity whose keepalives timed
+	// out.
+	EntityStateUnhealthy = "unhealthy"
+
+	// EntityStateDeregistered is the state of an entity deregistered after its
+	// keepalives timed out.
+	EntityStateDeregistered = "deregistered"
+)
+
+// createEntityStateEvent returns an event that notifies handler that entity
+// transitioned from the state previous to the state current.
+func createEntityStateEvent(entity *corev2.Entity, handler, previous, current string) *corev2.Event {
+	var status uint32
+	if current != EntityStateHealthy {
+		status = 1
+	}
+	check := &corev2.Check{
+		ObjectMeta: corev2.ObjectMeta{
+			Name:      EntityStateCheckName,
+			Namespace: entity.Namespace,
+			Annotations: map[string]string{
+				EntityStateAnnotation:         current,
+				PreviousEntityStateAnnotation: previous,
+			},
+		},
+		Interval: 1,
+		Handlers: []string{handler},
+		Status:   status,
+		Output:   fmt.Sprintf("Entity %s transitioned from %s to %s", entity.Name, previous, current),
+		Executed: time.Now().Unix(),
+		Issued:   time.Now().Unix(),
+	}
+	event := &corev2.Event{
+		ObjectMeta: corev2.ObjectMeta{
+			Namespace: entity.Namespace,
+		},
+		Timestamp: time.Now().Unix(),
+		Entity:    entity,
+		Check:     check,
+	}
+	uid, _ := uuid.NewRandom()
+	event.ID = uid[:]
+
+	return event
+}
+
+// publishEntityStateChange publishes an entity state change event, if an
+// entity state handler is configured.
+func (k *Keepalived) publishEntityStateChange(entity *corev2.Entity, previous, current string) error {
+	if k.entityStateHandler == "" {
+		return nil
+	}
+	event := createEntityStateEvent(entity, k.entityStateHandler, previous, current)
+	return k.bus.Publish(messaging.TopicEvent, event)
+}
+
+// entityState returns the state of an entity given its keepalive event.
+func entityState(keepalive *corev2.Event) string {
+	if keepalive.HasCheck() && keepalive.Check.Status != 0 {
+		return EntityStateUnhealthy
+	}
+	return EntityStateHealthy
+}
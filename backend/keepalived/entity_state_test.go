@@ -0,0 +1,96 @@
+package keepalived
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/testing/mockstore"
+)
+
+func TestCreateEntityStateEvent(t *testing.T) {
+	entity := corev2.FixtureEntity("entity1")
+	event := createEntityStateEvent(entity, "fleet", EntityStateHealthy, EntityStateUnhealthy)
+	assert.Equal(t, EntityStateCheckName, event.Check.Name)
+	assert.Equal(t, []string{"fleet"}, event.Check.Handlers)
+	assert.Equal(t, uint32(1), event.Check.Status)
+	assert.Equal(t, EntityStateUnhealthy, event.Check.Annotations[EntityStateAnnotation])
+	assert.Equal(t, EntityStateHealthy, event.Check.Annotations[PreviousEntityStateAnnotation])
+	assert.Equal(t, "default", event.Check.Namespace)
+	assert.NotEmpty(t, event.ID)
+
+	event = createEntityStateEvent(entity, "fleet", EntityStateUnhealthy, EntityStateHealthy)
+	assert.Equal(t, uint32(0), event.Check.Status)
+}
+
+func TestHandleRecovery(t *testing.T) {
+	failing := corev2.FixtureEvent("entity1", corev2.KeepaliveCheckName)
+	failing.Check.Status = 2
+	passing := corev2.FixtureEvent("entity1", corev2.KeepaliveCheckName)
+
+	tt := []struct {
+		name             string
+		handler          string
+		keepalive        *corev2.Event
+		expectedEventLen int
+	}{
+		{
+			name:             "recovered entity",
+			handler:          "fleet",
+			keepalive:        failing,
+			expectedEventLen: 1,
+		},
+		{
+			name:             "healthy entity",
+			handler:          "fleet",
+			keepalive:        passing,
+			expectedEventLen: 0,
+		},
+		{
+			name:             "new entity",
+			handler:          "fleet",
+			expectedEventLen: 0,
+		},
+		{
+			name:             "no entity state handler",
+			keepalive:        failing,
+			expectedEventLen: 0,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			messageBus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+			require.NoError(t, err)
+			require.NoError(t, messageBus.Start())
+
+			tsubEvent := testSubscriber{
+				ch: make(chan interface{}, 1),
+			}
+			subscriptionEvent, err := messageBus.Subscribe(messaging.TopicEvent, "testSubscriberEvent", tsubEvent)
+			require.NoError(t, err)
+
+			stor := &mockstore.V2MockStore{}
+			eventStore := &mockstore.MockStore{}
+			stor.On("GetEventStore").Return(eventStore)
+			eventStore.On("GetEventByEntityCheck", mock.Anything, "entity1", corev2.KeepaliveCheckName).Return(tc.keepalive, nil)
+
+			keepalived, err := New(Config{
+				Store:              stor,
+				Bus:                messageBus,
+				EntityStateHandler: tc.handler,
+				StoreTimeout:       time.Minute,
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, keepalived.handleRecovery(corev2.FixtureEntity("entity1")))
+			assert.Equal(t, tc.expectedEventLen, len(tsubEvent.ch))
+			assert.NoError(t, subscriptionEvent.Cancel())
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/agent"
+	"github.com/sensu/sensu-go/backend/featuregate"
 	"github.com/sensu/sensu-go/backend/messaging"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
@@ -56,8 +57,10 @@ const deletedEventSentinel = -1
 type Keepalived struct {
 	bus                   messaging.MessageBus
 	workerCount           int
+	bufferSize            int
 	store                 storev2.Interface
 	deregistrationHandler string
+	entityStateHandler    string
 	mu                    *sync.Mutex
 	wg                    *sync.WaitGroup
 	keepaliveChan         chan interface{}
@@ -69,7 +72,9 @@ type Keepalived struct {
 	reconstructionPeriod  time.Duration
 	operatorConcierge     store.OperatorConcierge
 	operatorMonitor       store.OperatorMonitor
+	operatorQueryer       store.OperatorQueryer
 	backendName           string
+	featureGates          *featuregate.Gates
 }
 
 // Option is a functional option.
@@ -81,12 +86,15 @@ type Config struct {
 	EventStore            store.EventStore
 	Bus                   messaging.MessageBus
 	DeregistrationHandler string
+	EntityStateHandler    string
 	BufferSize            int
 	WorkerCount           int
 	StoreTimeout          time.Duration
 	OperatorConcierge     store.OperatorConcierge
 	OperatorMonitor       store.OperatorMonitor
+	OperatorQueryer       store.OperatorQueryer
 	BackendName           string
+	FeatureGates          *featuregate.Gates
 }
 
 // New creates a new Keepalived.
@@ -110,8 +118,10 @@ func New(c Config, opts ...Option) (*Keepalived, error) {
 		store:                 c.Store,
 		bus:                   c.Bus,
 		deregistrationHandler: c.DeregistrationHandler,
+		entityStateHandler:    c.EntityStateHandler,
 		keepaliveChan:         make(chan interface{}, c.BufferSize),
 		workerCount:           c.WorkerCount,
+		bufferSize:            c.BufferSize,
 		mu:                    &sync.Mutex{},
 		errChan:               make(chan error, 1),
 		ctx:                   ctx,
@@ -120,7 +130,9 @@ func New(c Config, opts ...Option) (*Keepalived, error) {
 		reconstructionPeriod:  time.Second * 120,
 		operatorConcierge:     c.OperatorConcierge,
 		operatorMonitor:       c.OperatorMonitor,
+		operatorQueryer:       c.OperatorQueryer,
 		backendName:           c.BackendName,
+		featureGates:          c.FeatureGates,
 	}
 	for _, o := range opts {
 		if err := o(k); err != nil {
@@ -138,7 +150,7 @@ func (k *Keepalived) Receiver() chan<- interface{} {
 // Start starts the daemon, returning an error if preconditions for startup
 // fail.
 func (k *Keepalived) Start() error {
-	sub, err := k.bus.Subscribe(messaging.TopicKeepalive, "keepalived", k)
+	sub, err := messaging.Supervise(k.ctx, k.bus, messaging.TopicKeepalive, "keepalived", k)
 	if err != nil {
 		return err
 	}
@@ -194,27 +206,38 @@ func (k *Keepalived) Err() <-chan error {
 	return k.errChan
 }
 
+// QueueDepth returns the number of keepalives waiting to be processed by
+// keepalived and the capacity of its buffer.
+func (k *Keepalived) QueueDepth() (int, int) {
+	return len(k.keepaliveChan), cap(k.keepaliveChan)
+}
+
 // Name returns the daemon name
 func (k *Keepalived) Name() string {
 	return "keepalived"
 }
 
 func (k *Keepalived) startWorkers() {
+	if k.featureGates.Enabled(featuregate.KeepaliveSharding) {
+		k.startShardedWorkers()
+		return
+	}
+
 	k.wg.Add(k.workerCount)
 
 	for i := 0; i < k.workerCount; i++ {
-		go k.processKeepalives(k.ctx)
+		go k.processKeepalives(k.ctx, k.keepaliveChan)
 	}
 }
 
-func (k *Keepalived) processKeepalives(ctx context.Context) {
+func (k *Keepalived) processKeepalives(ctx context.Context, keepaliveChan <-chan interface{}) {
 	defer k.wg.Done()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case msg, ok := <-k.keepaliveChan:
+		case msg, ok := <-keepaliveChan:
 			if !ok {
 				return
 			}
@@ -522,6 +545,8 @@ func (k *Keepalived) handleNotification(ctx context.Context, state store.Operato
 		}
 		if err := deregisterer.Deregister(currentEvent.Entity); err != nil {
 			lager.WithError(err).Error("error deregistering entity")
+		} else if err := k.publishEntityStateChange(currentEvent.Entity, entityState(currentEvent), EntityStateDeregistered); err != nil {
+			lager.WithError(err).Error("error publishing entity state change")
 		}
 		lager.Debug("deregistering entity")
 		return k.operatorConcierge.CheckOut(ctx, key)
@@ -545,9 +570,18 @@ func (k *Keepalived) handleNotification(ctx context.Context, state store.Operato
 	}
 	event.Check.Output = fmt.Sprintf("No keepalive sent from %s for %v seconds (>= %v)", event.Entity.Name, timeSinceLastSeen, timeout)
 
-	if err := k.bus.Publish(messaging.TopicEventRaw, event); err != nil {
-		lager.WithError(err).Error("error publishing event")
-		return err
+	if k.keepaliveAlertsSuppressed(ctx, state.Namespace) {
+		lager.Warn("keepalive alerts are suppressed in namespace, not publishing event")
+	} else {
+		if err := k.bus.Publish(messaging.TopicEventRaw, event); err != nil {
+			lager.WithError(err).Error("error publishing event")
+			return err
+		}
+		if previous := entityState(currentEvent); previous == EntityStateHealthy {
+			if err := k.publishEntityStateChange(event.Entity, previous, EntityStateUnhealthy); err != nil {
+				lager.WithError(err).Error("error publishing entity state change")
+			}
+		}
 	}
 
 	var meta agentMetadata
@@ -588,6 +622,18 @@ func (k *Keepalived) handleNotification(ctx context.Context, state store.Operato
 	return nil
 }
 
+// keepaliveAlertsSuppressed returns whether keepalive failures of the
+// namespace must not raise alerts, because scheduling is paused in the
+// namespace. Alerts are raised if the namespace can't be read.
+func (k *Keepalived) keepaliveAlertsSuppressed(ctx context.Context, name string) bool {
+	namespace, err := storev2.Of[*corev3.Namespace](k.store).Get(ctx, storev2.ID{Name: name})
+	if err != nil {
+		logger.WithError(err).WithField("namespace", name).Error("error reading namespace")
+		return false
+	}
+	return store.KeepaliveAlertsSuppressed(namespace)
+}
+
 type agentMetadata struct {
 	Warning  int `json:"w"`
 	Critical int `json:"c"`
@@ -612,9 +658,34 @@ func (k *Keepalived) handleUpdate(e *corev2.Event) error {
 		return err
 	}
 
+	if err := k.handleRecovery(entity); err != nil {
+		logger.WithError(err).Error("error publishing entity state change")
+	}
+
 	event := createKeepaliveEvent(e)
 	event.Check.Status = 0
 	event.Check.Output = fmt.Sprintf("Keepalive last sent from %s at %s", entity.Name, time.Unix(entity.LastSeen, 0).String())
 
 	return k.bus.Publish(messaging.TopicEventRaw, event)
 }
+
+// handleRecovery publishes an entity state change event if the stored
+// keepalive event of entity is failing, before it is replaced by a passing
+// one. The keepalive event is only read if an entity state handler is
+// configured.
+func (k *Keepalived) handleRecovery(entity *corev2.Entity) error {
+	if k.entityStateHandler == "" {
+		return nil
+	}
+	ctx := store.NamespaceContext(k.ctx, entity.Namespace)
+	tctx, cancel := context.WithTimeout(ctx, k.storeTimeout)
+	defer cancel()
+	keepalive, err := k.store.GetEventStore().GetEventByEntityCheck(tctx, entity.Name, corev2.KeepaliveCheckName)
+	if err != nil {
+		return err
+	}
+	if keepalive == nil || entityState(keepalive) == EntityStateHealthy {
+		return nil
+	}
+	return k.publishEntityStateChange(entity, EntityStateUnhealthy, EntityStateHealthy)
+}
@@ -2,6 +2,7 @@ package keepalived
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -409,3 +410,22 @@ func TestCreateRegistrationEvent(t *testing.T) {
 	assert.Equal(t, "default", keepaliveEvent.Check.Namespace)
 	assert.Equal(t, "default", keepaliveEvent.ObjectMeta.Namespace)
 }
+
+func TestKeepaliveAlertsSuppressed(t *testing.T) {
+	paused := corev3.FixtureNamespace("paused")
+	paused.Metadata.Annotations = map[string]string{
+		store.SchedulingPausedAnnotation:        "true",
+		store.SuppressKeepaliveAlertsAnnotation: "true",
+	}
+	stor := new(mockstore.V2MockStore)
+	ns := new(mockstore.NamespaceStore)
+	ns.On("Get", mock.Anything, "paused").Return(paused, nil)
+	ns.On("Get", mock.Anything, "default").Return(corev3.FixtureNamespace("default"), nil)
+	ns.On("Get", mock.Anything, "missing").Return((*corev3.Namespace)(nil), errors.New("oh noes"))
+	stor.On("GetNamespaceStore").Return(ns)
+
+	k := &Keepalived{store: stor}
+	assert.True(t, k.keepaliveAlertsSuppressed(context.Background(), "paused"))
+	assert.False(t, k.keepaliveAlertsSuppressed(context.Background(), "default"))
+	assert.False(t, k.keepaliveAlertsSuppressed(context.Background(), "missing"))
+}
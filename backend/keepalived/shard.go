@@ -0,0 +1,54 @@
+package keepalived
+
+import (
+	"hash/fnv"
+	"path"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+// shardOf returns the shard, between 0 and shards-1, of the entity identified
+// by key.
+func shardOf(key string, shards int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// startShardedWorkers starts workers that each process the keepalives of the
+// entities of their shard. The keepalives of an entity are processed in order,
+// and a slow entity only delays the keepalives of its shard.
+func (k *Keepalived) startShardedWorkers() {
+	bufferSize := k.bufferSize / k.workerCount
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	shards := make([]chan interface{}, k.workerCount)
+	for i := range shards {
+		shards[i] = make(chan interface{}, bufferSize)
+	}
+
+	k.wg.Add(k.workerCount)
+	for _, shard := range shards {
+		go k.processKeepalives(k.ctx, shard)
+	}
+
+	go func() {
+		defer func() {
+			for _, shard := range shards {
+				close(shard)
+			}
+		}()
+		for msg := range k.keepaliveChan {
+			var key string
+			if event, ok := msg.(*corev2.Event); ok && event.Entity != nil {
+				key = path.Join(event.Entity.Namespace, event.Entity.Name)
+			}
+			select {
+			case shards[shardOf(key, len(shards))] <- msg:
+			case <-k.ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,24 @@
+package keepalived
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardOf(t *testing.T) {
+	counts := map[int]int{}
+	for i := 0; i < 4000; i++ {
+		key := fmt.Sprintf("default/entity-%d", i)
+		shard := shardOf(key, 4)
+		assert.Equal(t, shard, shardOf(key, 4), "shards must be stable")
+		counts[shard]++
+	}
+	assert.Len(t, counts, 4)
+	for shard, count := range counts {
+		// keys are spread evenly, within a reasonable margin
+		assert.InDelta(t, 1000, count, 250, shard)
+	}
+	assert.Equal(t, 0, shardOf("default/entity", 1))
+}
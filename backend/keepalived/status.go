@@ -0,0 +1,105 @@
+package keepalived
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+const (
+	// KeepaliveStateAlive is the state of agents that checked in before their
+	// keepalive deadline.
+	KeepaliveStateAlive = "alive"
+
+	// KeepaliveStateDead is the state of agents that missed their keepalive
+	// deadline.
+	KeepaliveStateDead = "dead"
+)
+
+// KeepaliveStatus is the liveness state of an agent, as recorded by the
+// operator concierge.
+type KeepaliveStatus struct {
+	// Namespace is the namespace of the entity of the agent.
+	Namespace string `json:"namespace"`
+
+	// Entity is the name of the entity of the agent.
+	Entity string `json:"entity"`
+
+	// State is the liveness state of the agent, alive or dead.
+	State string `json:"state"`
+
+	// LastSeen is the time at which the agent last checked in, or at which
+	// keepalived last noticed that it was still dead.
+	LastSeen int64 `json:"last_seen"`
+
+	// Deadline is the time at which the agent is considered dead if it does
+	// not check in again.
+	Deadline int64 `json:"deadline"`
+
+	// Controller is the name of the backend that monitors the agent.
+	Controller string `json:"controller,omitempty"`
+
+	// Warning, Critical and Interval are the keepalive timeouts and interval
+	// of the agent, in seconds.
+	Warning  int `json:"warning"`
+	Critical int `json:"critical"`
+	Interval int `json:"interval"`
+}
+
+func newKeepaliveStatus(op store.OperatorState, now time.Time) KeepaliveStatus {
+	status := KeepaliveStatus{
+		Namespace: op.Namespace,
+		Entity:    op.Name,
+		State:     KeepaliveStateDead,
+		LastSeen:  op.LastUpdate.Unix(),
+	}
+	deadline := op.LastUpdate.Add(op.CheckInTimeout)
+	status.Deadline = deadline.Unix()
+	if op.Present && (op.CheckInTimeout <= 0 || !now.After(deadline)) {
+		status.State = KeepaliveStateAlive
+	}
+	if op.Controller != nil {
+		status.Controller = op.Controller.Name
+	}
+	if op.Metadata != nil {
+		var meta agentMetadata
+		if err := json.Unmarshal(*op.Metadata, &meta); err == nil {
+			status.Warning = meta.Warning
+			status.Critical = meta.Critical
+			status.Interval = meta.Interval
+		}
+	}
+	return status
+}
+
+// Keepalives returns the liveness state of the agents of namespace, sorted by
+// entity name. If namespace is empty, the agents of every namespace are
+// returned.
+func (k *Keepalived) Keepalives(ctx context.Context, namespace string) ([]KeepaliveStatus, error) {
+	if k.operatorQueryer == nil {
+		return nil, errors.New("keepalived has no operator queryer")
+	}
+	operators, err := k.operatorQueryer.ListOperators(ctx, store.OperatorKey{
+		Namespace: namespace,
+		Type:      store.AgentOperator,
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	statuses := make([]KeepaliveStatus, 0, len(operators))
+	for _, op := range operators {
+		statuses = append(statuses, newKeepaliveStatus(op, now))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Namespace != statuses[j].Namespace {
+			return statuses[i].Namespace < statuses[j].Namespace
+		}
+		return statuses[i].Entity < statuses[j].Entity
+	})
+	return statuses, nil
+}
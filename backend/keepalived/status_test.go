@@ -0,0 +1,88 @@
+package keepalived
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+type testOperatorQueryer []store.OperatorState
+
+func (q testOperatorQueryer) QueryOperator(context.Context, store.OperatorKey) (store.OperatorState, error) {
+	return store.OperatorState{}, &store.ErrNotFound{}
+}
+
+func (q testOperatorQueryer) ListOperators(ctx context.Context, key store.OperatorKey) ([]store.OperatorState, error) {
+	var states []store.OperatorState
+	for _, state := range q {
+		if state.Namespace == key.Namespace && state.Type == key.Type {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+func TestKeepalives(t *testing.T) {
+	now := time.Now()
+	metadata := json.RawMessage(`{"w":120,"c":180,"i":20}`)
+	queryer := testOperatorQueryer{
+		{
+			Namespace:      "default",
+			Name:           "entity2",
+			Type:           store.AgentOperator,
+			Present:        true,
+			LastUpdate:     now.Add(-time.Minute),
+			CheckInTimeout: 2 * time.Minute,
+			Controller:     &store.OperatorKey{Name: "backend1", Type: store.BackendOperator},
+			Metadata:       &metadata,
+		},
+		{
+			// an agent that missed its deadline, before keepalived noticed
+			Namespace:      "default",
+			Name:           "entity1",
+			Type:           store.AgentOperator,
+			Present:        true,
+			LastUpdate:     now.Add(-3 * time.Minute),
+			CheckInTimeout: 2 * time.Minute,
+		},
+		{
+			Namespace:      "default",
+			Name:           "entity3",
+			Type:           store.AgentOperator,
+			LastUpdate:     now,
+			CheckInTimeout: 20 * time.Second,
+		},
+		{
+			Namespace: "default",
+			Name:      "backend1",
+			Type:      store.BackendOperator,
+			Present:   true,
+		},
+	}
+	k, err := New(Config{OperatorQueryer: queryer})
+	require.NoError(t, err)
+
+	statuses, err := k.Keepalives(context.Background(), "default")
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+
+	assert.Equal(t, "entity1", statuses[0].Entity)
+	assert.Equal(t, KeepaliveStateDead, statuses[0].State)
+
+	assert.Equal(t, "entity2", statuses[1].Entity)
+	assert.Equal(t, KeepaliveStateAlive, statuses[1].State)
+	assert.Equal(t, "backend1", statuses[1].Controller)
+	assert.Equal(t, now.Add(time.Minute).Unix(), statuses[1].Deadline)
+	assert.Equal(t, 120, statuses[1].Warning)
+	assert.Equal(t, 180, statuses[1].Critical)
+	assert.Equal(t, 20, statuses[1].Interval)
+
+	assert.Equal(t, "entity3", statuses[2].Entity)
+	assert.Equal(t, KeepaliveStateDead, statuses[2].State)
+}
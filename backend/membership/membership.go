@@ -0,0 +1,136 @@
+// Package membership reports the backends of a sensu-backend cluster.
+//
+// Backends check in with the operator concierge every second, with a
+// heartbeat that describes the backend. The Registry lists the backends from
+// these check-ins, so that cluster membership does not depend on the type of
+// store that the cluster uses.
+package membership
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// StorePostgres is the store type of backends that use postgres.
+const StorePostgres = "postgres"
+
+// Load describes the amount of work done by a backend.
+type Load struct {
+	// AgentSessions is the number of agents connected to the backend.
+	AgentSessions int64 `json:"agent_sessions"`
+
+	// Goroutines is the number of goroutines of the backend.
+	Goroutines int `json:"goroutines"`
+}
+
+// Heartbeat is the metadata that backends check in with.
+type Heartbeat struct {
+	// Version is the version of the backend.
+	Version string `json:"version"`
+
+	// StoreType is the type of store used by the backend.
+	StoreType string `json:"store_type,omitempty"`
+
+	// StartedAt is the time at which the backend started.
+	StartedAt int64 `json:"started_at,omitempty"`
+
+	// Load is the load of the backend.
+	Load Load `json:"load"`
+}
+
+// Encode returns the heartbeat as operator metadata.
+func (h Heartbeat) Encode() *json.RawMessage {
+	b, _ := json.Marshal(h)
+	msg := json.RawMessage(b)
+	return &msg
+}
+
+// DecodeHeartbeat decodes the heartbeat of a backend operator. Backends that
+// predate heartbeats check in without metadata, and have an empty heartbeat.
+func DecodeHeartbeat(op store.OperatorState) (Heartbeat, error) {
+	var heartbeat Heartbeat
+	if op.Metadata == nil {
+		return heartbeat, nil
+	}
+	err := json.Unmarshal(*op.Metadata, &heartbeat)
+	return heartbeat, err
+}
+
+// Member is a backend of the cluster.
+type Member struct {
+	// Name is the name of the backend.
+	Name string `json:"name"`
+
+	// Present is true if the backend checked in recently.
+	Present bool `json:"present"`
+
+	// LastSeen is the time at which the backend last checked in, or was last
+	// noticed to be absent.
+	LastSeen int64 `json:"last_seen"`
+
+	Heartbeat
+}
+
+// Registry lists the members of the cluster.
+type Registry struct {
+	queryer store.OperatorQueryer
+}
+
+// NewRegistry creates a new Registry that lists the backends known to
+// queryer.
+func NewRegistry(queryer store.OperatorQueryer) *Registry {
+	return &Registry{queryer: queryer}
+}
+
+// List returns the members of the cluster, sorted by name. Backends that
+// have stopped checking in are reported as absent until the operator
+// concierge forgets them.
+func (r *Registry) List(ctx context.Context) ([]Member, error) {
+	operators, err := r.queryer.ListOperators(ctx, store.OperatorKey{Type: store.BackendOperator})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	members := make([]Member, 0, len(operators))
+	for _, op := range operators {
+		members = append(members, newMember(op, now))
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Name < members[j].Name
+	})
+	return members, nil
+}
+
+// Get returns the member named name.
+func (r *Registry) Get(ctx context.Context, name string) (Member, error) {
+	op, err := r.queryer.QueryOperator(ctx, store.OperatorKey{Type: store.BackendOperator, Name: name})
+	if err != nil {
+		return Member{}, err
+	}
+	return newMember(op, time.Now()), nil
+}
+
+func newMember(op store.OperatorState, now time.Time) Member {
+	member := Member{
+		Name:     op.Name,
+		Present:  IsPresent(op, now),
+		LastSeen: op.LastUpdate.Unix(),
+	}
+	if heartbeat, err := DecodeHeartbeat(op); err == nil {
+		member.Heartbeat = heartbeat
+	}
+	return member
+}
+
+// IsPresent returns true if the operator is present, and checked in within
+// its check-in timeout.
+func IsPresent(op store.OperatorState, now time.Time) bool {
+	if !op.Present {
+		return false
+	}
+	return op.CheckInTimeout <= 0 || now.Sub(op.LastUpdate) <= op.CheckInTimeout
+}
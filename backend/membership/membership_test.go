@@ -0,0 +1,84 @@
+package membership
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+type testQueryer struct {
+	operators []store.OperatorState
+}
+
+func (q testQueryer) QueryOperator(ctx context.Context, key store.OperatorKey) (store.OperatorState, error) {
+	for _, op := range q.operators {
+		if op.Key() == key {
+			return op, nil
+		}
+	}
+	return store.OperatorState{}, &store.ErrNotFound{Key: key.Name}
+}
+
+func (q testQueryer) ListOperators(context.Context, store.OperatorKey) ([]store.OperatorState, error) {
+	return q.operators, nil
+}
+
+func TestRegistry(t *testing.T) {
+	now := time.Now()
+	heartbeat := Heartbeat{
+		Version:   "7.0.0",
+		StoreType: StorePostgres,
+		StartedAt: now.Add(-time.Hour).Unix(),
+		Load:      Load{AgentSessions: 42, Goroutines: 100},
+	}
+	empty := json.RawMessage("{}")
+	queryer := testQueryer{
+		operators: []store.OperatorState{
+			{
+				Type:           store.BackendOperator,
+				Name:           "b",
+				CheckInTimeout: 10 * time.Second,
+				Present:        true,
+				LastUpdate:     now,
+				Metadata:       heartbeat.Encode(),
+			},
+			{
+				Type:           store.BackendOperator,
+				Name:           "a",
+				CheckInTimeout: 10 * time.Second,
+				Present:        true,
+				LastUpdate:     now.Add(-time.Minute),
+				Metadata:       &empty,
+			},
+		},
+	}
+	registry := NewRegistry(queryer)
+
+	members, err := registry.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Member{
+		{Name: "a", Present: false, LastSeen: now.Add(-time.Minute).Unix()},
+		{Name: "b", Present: true, LastSeen: now.Unix(), Heartbeat: heartbeat},
+	}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("List() = %v, want %v", members, want)
+	}
+
+	member, err := registry.Get(context.Background(), "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(member, want[1]) {
+		t.Errorf("Get() = %v, want %v", member, want[1])
+	}
+
+	if _, err := registry.Get(context.Background(), "c"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,9 @@
+package messaging
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "message_bus",
+})
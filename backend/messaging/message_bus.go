@@ -43,6 +43,10 @@ const (
 	// connectedness. agentd will send notifications about agents that connect
 	// and disconnect on this channel.
 	TopicAgentConnectionState = "sensu:agent-conn"
+
+	// TopicBurial is the topic prefix for the keepalive burial notifications
+	// of each entity.
+	TopicBurial = "sensu:burial"
 )
 
 var (
@@ -62,10 +66,22 @@ type Subscriber interface {
 	Receiver() chan<- interface{}
 }
 
+// An OverflowHandler is a Subscriber that handles the messages sent to it
+// while its receiver is full.
+type OverflowHandler interface {
+	Subscriber
+
+	// Overflow is called when message is sent to the subscriber while its
+	// receiver is full. The send blocks until the receiver has room for the
+	// message if it returns true, and the message is dropped otherwise.
+	Overflow(message interface{}) (block bool)
+}
+
 // A Subscription is a cancellable subscription to a WizardTopic.
 type Subscription struct {
 	id     string
 	cancel func(string) error
+	broken <-chan struct{}
 }
 
 // Cancel a WizardSubscription.
@@ -73,6 +89,21 @@ func (t Subscription) Cancel() error {
 	return t.cancel(t.id)
 }
 
+// Broken returns a channel that is closed if the bus drops the subscription
+// before it is cancelled, such as when the bus stops. The subscriber receives
+// no more messages once it is closed. It returns nil if the bus never drops
+// its subscriptions.
+func (t Subscription) Broken() <-chan struct{} {
+	return t.broken
+}
+
+// NewSubscription returns a subscription of consumer id, for the message buses
+// implemented outside of this package. Cancel calls cancel with id, and Broken
+// returns broken.
+func NewSubscription(id string, cancel func(string) error, broken <-chan struct{}) Subscription {
+	return Subscription{id: id, cancel: cancel, broken: broken}
+}
+
 type ChanSubscriber chan interface{}
 
 func (c ChanSubscriber) Receiver() chan<- interface{} {
@@ -118,5 +149,5 @@ func SubscriptionTopic(namespace, sub string) string {
 // BurialTopic is used to signal to agentd sessions that a keepalive burial
 // has been processed.
 func BurialTopic(namespace, entity string) string {
-	return fmt.Sprintf("sensu:burial:%s:%s", namespace, entity)
+	return fmt.Sprintf("%s:%s:%s", TopicBurial, namespace, entity)
 }
@@ -0,0 +1,39 @@
+package messaging
+
+import (
+	"hash/fnv"
+	"path"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+// OrderingKey returns the key of the events whose processing must keep the
+// order in which they are received: the events of the same entity and check.
+// The keepalives of an entity share the key of its keepalive check. Messages
+// other than events have an empty key.
+func OrderingKey(msg interface{}) string {
+	event, ok := msg.(*corev2.Event)
+	if !ok || event == nil {
+		return ""
+	}
+	var namespace, entityName, checkName string
+	if event.Entity != nil {
+		namespace = event.Entity.Namespace
+		entityName = event.Entity.Name
+	}
+	if event.HasCheck() {
+		namespace = event.Check.Namespace
+		checkName = event.Check.Name
+		if event.Check.ProxyEntityName != "" {
+			entityName = event.Check.ProxyEntityName
+		}
+	}
+	return path.Join(namespace, entityName, checkName)
+}
+
+// Shard returns the shard, between 0 and shards-1, of the ordering key.
+func Shard(key string, shards int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(shards))
+}
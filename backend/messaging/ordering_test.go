@@ -0,0 +1,39 @@
+package messaging
+
+import (
+	"fmt"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderingKey(t *testing.T) {
+	event := corev2.FixtureEvent("entity1", "check1")
+	assert.Equal(t, "default/entity1/check1", OrderingKey(event))
+
+	proxy := corev2.FixtureEvent("entity1", "check1")
+	proxy.Check.ProxyEntityName = "router"
+	assert.Equal(t, "default/router/check1", OrderingKey(proxy))
+
+	metrics := corev2.FixtureEvent("entity1", "check1")
+	metrics.Check = nil
+	assert.Equal(t, "default/entity1", OrderingKey(metrics))
+
+	assert.Equal(t, "", OrderingKey("not an event"))
+}
+
+func TestShard(t *testing.T) {
+	counts := map[int]int{}
+	for i := 0; i < 4000; i++ {
+		key := fmt.Sprintf("default/entity-%d/check", i)
+		shard := Shard(key, 4)
+		assert.Equal(t, shard, Shard(key, 4), "shards must be stable")
+		counts[shard]++
+	}
+	assert.Len(t, counts, 4)
+	for shard, count := range counts {
+		assert.InDelta(t, 1000, count, 250, shard)
+	}
+	assert.Equal(t, 0, Shard("default/entity/check", 1))
+}
@@ -0,0 +1,83 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// SubscriptionWildcard matches any sequence of characters in a check
+	// subscription pattern, e.g. linux-*.
+	SubscriptionWildcard = "*"
+
+	// SubscriptionNegation prefixes the entity subscriptions that exclude an
+	// entity from the checks of matching subscriptions, e.g. !canary.
+	SubscriptionNegation = "!"
+)
+
+// IsSubscriptionPattern returns true if sub is a wildcard subscription.
+func IsSubscriptionPattern(sub string) bool {
+	return strings.Contains(sub, SubscriptionWildcard)
+}
+
+// IsNegatedSubscription returns true if sub is a negated subscription.
+func IsNegatedSubscription(sub string) bool {
+	return strings.HasPrefix(sub, SubscriptionNegation)
+}
+
+// ValidateSubscriptionPattern returns an error if pattern is not a valid
+// subscription pattern. Patterns can only use the * wildcard, and must match
+// a literal part, so that they can't match every subscription.
+func ValidateSubscriptionPattern(pattern string) error {
+	if IsNegatedSubscription(pattern) {
+		pattern = strings.TrimPrefix(pattern, SubscriptionNegation)
+	}
+	if strings.Trim(pattern, SubscriptionWildcard) == "" {
+		return errors.New("subscription pattern must contain characters other than wildcards")
+	}
+	if strings.ContainsAny(pattern, "?[]!") {
+		return fmt.Errorf("subscription pattern %q can only use the %s wildcard", pattern, SubscriptionWildcard)
+	}
+	return nil
+}
+
+// MatchSubscription returns true if the subscription sub matches pattern,
+// where * matches any sequence of characters.
+func MatchSubscription(pattern, sub string) bool {
+	parts := strings.Split(pattern, SubscriptionWildcard)
+	if len(parts) == 1 {
+		return pattern == sub
+	}
+	if !strings.HasPrefix(sub, parts[0]) {
+		return false
+	}
+	sub = sub[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(sub, part)
+		if i < 0 {
+			return false
+		}
+		sub = sub[i+len(part):]
+	}
+	return len(sub) >= len(last) && strings.HasSuffix(sub, last)
+}
+
+// ExcludedBySubscriptions returns true if one of the negated subscriptions
+// of an entity matches one of the check subscriptions, in which case the
+// check must not be executed by the entity. Invalid negations are ignored.
+func ExcludedBySubscriptions(entitySubscriptions, checkSubscriptions []string) bool {
+	for _, sub := range entitySubscriptions {
+		if !IsNegatedSubscription(sub) || ValidateSubscriptionPattern(sub) != nil {
+			continue
+		}
+		pattern := strings.TrimPrefix(sub, SubscriptionNegation)
+		for _, checkSub := range checkSubscriptions {
+			if MatchSubscription(pattern, checkSub) {
+				return true
+			}
+		}
+	}
+	return false
+}
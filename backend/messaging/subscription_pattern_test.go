@@ -0,0 +1,65 @@
+package messaging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSubscriptionPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{pattern: "linux-*"},
+		{pattern: "*-prod"},
+		{pattern: "linux-*-prod"},
+		{pattern: "!canary"},
+		{pattern: "!canary-*"},
+		{pattern: "*", wantErr: true},
+		{pattern: "**", wantErr: true},
+		{pattern: "!*", wantErr: true},
+		{pattern: "linux-?", wantErr: true},
+		{pattern: "linux-[ab]", wantErr: true},
+		{pattern: "!!canary", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			err := ValidateSubscriptionPattern(tt.pattern)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestMatchSubscription(t *testing.T) {
+	tests := []struct {
+		pattern string
+		sub     string
+		want    bool
+	}{
+		{pattern: "linux", sub: "linux", want: true},
+		{pattern: "linux", sub: "linux-prod"},
+		{pattern: "linux-*", sub: "linux-prod", want: true},
+		{pattern: "linux-*", sub: "linux-", want: true},
+		{pattern: "linux-*", sub: "windows-prod"},
+		{pattern: "*-prod", sub: "linux-prod", want: true},
+		{pattern: "*-prod", sub: "linux-dev"},
+		{pattern: "linux-*-prod", sub: "linux-eu-prod", want: true},
+		{pattern: "linux-*-prod", sub: "linux-prod"},
+		{pattern: "a*a", sub: "a"},
+		{pattern: "a*a", sub: "aa", want: true},
+		{pattern: "a*b*c", sub: "abbc", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.sub, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchSubscription(tt.pattern, tt.sub))
+		})
+	}
+}
+
+func TestExcludedBySubscriptions(t *testing.T) {
+	assert.False(t, ExcludedBySubscriptions([]string{"linux"}, []string{"linux"}))
+	assert.True(t, ExcludedBySubscriptions([]string{"linux", "!canary"}, []string{"linux", "canary"}))
+	assert.True(t, ExcludedBySubscriptions([]string{"!canary-*"}, []string{"canary-eu"}))
+	assert.False(t, ExcludedBySubscriptions([]string{"!*"}, []string{"linux"}))
+}
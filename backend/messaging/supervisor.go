@@ -0,0 +1,139 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/util/retry"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// BusResubscriptions is the name of the counter of the subscriptions
+	// re-established by Supervise, by topic and outcome.
+	BusResubscriptions = "sensu_go_bus_resubscriptions_total"
+
+	resubscriptionSucceeded = "succeeded"
+	resubscriptionFailed    = "failed"
+)
+
+var (
+	resubscriptionCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: BusResubscriptions,
+			Help: "The total number of attempts to re-establish the subscriptions dropped by the message bus",
+		},
+		[]string{WizardBusTopicLabelName, "outcome"},
+	)
+
+	errSupervisionStopped = errors.New("subscription supervision stopped")
+)
+
+func init() {
+	_ = prometheus.Register(resubscriptionCounter)
+}
+
+// supervisor re-establishes a subscription whenever the bus drops it.
+type supervisor struct {
+	bus        MessageBus
+	topic      string
+	consumer   string
+	subscriber Subscriber
+
+	mu      sync.Mutex
+	current Subscription
+	stopped bool
+	stop    chan struct{}
+}
+
+// Supervise subscribes subscriber to topic, as consumer, like bus.Subscribe,
+// and subscribes it again whenever the bus drops the subscription, such as
+// when the bus restarts, until the returned subscription is cancelled or ctx
+// is done. The subscription is re-established with an exponential backoff
+// while the bus fails.
+func Supervise(ctx context.Context, bus MessageBus, topic, consumer string, subscriber Subscriber) (Subscription, error) {
+	subscription, err := bus.Subscribe(topic, consumer, subscriber)
+	if err != nil {
+		return Subscription{}, err
+	}
+	s := &supervisor{
+		bus:        bus,
+		topic:      topic,
+		consumer:   consumer,
+		subscriber: subscriber,
+		current:    subscription,
+		stop:       make(chan struct{}),
+	}
+	go s.run(ctx)
+	return Subscription{id: consumer, cancel: s.cancel}, nil
+}
+
+func (s *supervisor) run(ctx context.Context) {
+	lager := logger.WithFields(logrus.Fields{
+		"topic":    s.topic,
+		"consumer": s.consumer,
+	})
+	genericTopic := findGenericTopic(s.topic)
+	for {
+		s.mu.Lock()
+		broken := s.current.Broken()
+		s.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-broken:
+		}
+
+		lager.Warn("the message bus dropped the subscription, subscribing again")
+		backoff := retry.ExponentialBackoff{
+			Ctx:                  ctx,
+			InitialDelayInterval: 100 * time.Millisecond,
+			MaxDelayInterval:     10 * time.Second,
+			Multiplier:           2,
+		}
+		err := backoff.Retry(func(int) (bool, error) {
+			select {
+			case <-s.stop:
+				return false, errSupervisionStopped
+			default:
+			}
+			subscription, err := s.bus.Subscribe(s.topic, s.consumer, s.subscriber)
+			if err != nil {
+				resubscriptionCounter.WithLabelValues(genericTopic, resubscriptionFailed).Inc()
+				lager.WithError(err).Error("could not subscribe again, retrying")
+				return false, nil
+			}
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.stopped {
+				// The subscription was cancelled in the meantime
+				_ = subscription.Cancel()
+				return false, errSupervisionStopped
+			}
+			s.current = subscription
+			return true, nil
+		})
+		if err != nil {
+			return
+		}
+		resubscriptionCounter.WithLabelValues(genericTopic, resubscriptionSucceeded).Inc()
+		lager.Info("subscribed again to the message bus")
+	}
+}
+
+// cancel stops the supervision, and cancels the current subscription.
+func (s *supervisor) cancel(string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return nil
+	}
+	s.stopped = true
+	close(s.stop)
+	return s.current.Cancel()
+}
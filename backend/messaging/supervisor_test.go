@@ -0,0 +1,75 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuperviseResubscribes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus, err := NewWizardBus(WizardBusConfig{})
+	require.NoError(t, err)
+	require.NoError(t, bus.Start())
+
+	subscriber := channelSubscriber{Channel: make(chan interface{}, 10)}
+	subscription, err := Supervise(ctx, bus, "topic", "consumer", subscriber)
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish("topic", "before"))
+	require.Equal(t, "before", <-subscriber.Channel)
+
+	// The bus restarts, which drops the subscriptions
+	require.NoError(t, bus.Stop())
+	require.NoError(t, bus.Start())
+
+	require.Eventually(t, func() bool {
+		require.NoError(t, bus.Publish("topic", "after"))
+		select {
+		case msg := <-subscriber.Channel:
+			return msg == "after"
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The subscription is not re-established once cancelled
+	require.NoError(t, subscription.Cancel())
+	require.NoError(t, bus.Stop())
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, bus.Publish("topic", "cancelled"))
+	for len(subscriber.Channel) > 0 {
+		if msg := <-subscriber.Channel; msg == "cancelled" {
+			t.Fatal("message received after the subscription was cancelled")
+		}
+	}
+}
+
+func TestSubscriptionBroken(t *testing.T) {
+	bus, err := NewWizardBus(WizardBusConfig{})
+	require.NoError(t, err)
+
+	subscriber := channelSubscriber{Channel: make(chan interface{}, 1)}
+	subscription, err := bus.Subscribe("topic", "consumer", subscriber)
+	require.NoError(t, err)
+
+	// Cancelling the subscription does not break it
+	other, err := bus.Subscribe("topic", "other", subscriber)
+	require.NoError(t, err)
+	require.NoError(t, other.Cancel())
+	select {
+	case <-subscription.Broken():
+		t.Fatal("subscription broken by the cancellation of another one")
+	default:
+	}
+
+	require.NoError(t, bus.Stop())
+	select {
+	case <-subscription.Broken():
+	case <-time.After(time.Second):
+		t.Fatal("subscription not broken by the bus stop")
+	}
+}
@@ -1,6 +1,7 @@
 package messaging
 
 import (
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -9,9 +10,12 @@ import (
 )
 
 const (
-	WizardBusMessagesPublished      = "sensu_go_bus_messages_published"
-	WizardBusMessagePublishDuration = "sensu_go_bus_message_duration"
-	WizardBusTopicLabelName         = "topic"
+	WizardBusMessagesPublished       = "sensu_go_bus_messages_published"
+	WizardBusMessagePublishDuration  = "sensu_go_bus_message_duration"
+	WizardBusMessageDeliveryDuration = "sensu_go_bus_message_delivery_duration_seconds"
+	WizardBusMessagesDropped         = "sensu_go_bus_messages_dropped_total"
+	WizardBusSubscribers             = "sensu_go_bus_subscribers"
+	WizardBusTopicLabelName          = "topic"
 )
 
 var (
@@ -31,11 +35,57 @@ var (
 		},
 		[]string{WizardBusTopicLabelName},
 	)
+
+	messageDeliveryDurations = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    WizardBusMessageDeliveryDuration,
+			Help:    "The time the messages published to wizard bus wait to be delivered to each subscriber",
+			Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+		},
+		[]string{WizardBusTopicLabelName},
+	)
+
+	messageDroppedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: WizardBusMessagesDropped,
+			Help: "The total number of messages published to wizard bus that a subscriber did not receive",
+		},
+		[]string{WizardBusTopicLabelName},
+	)
+
+	subscriberGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: WizardBusSubscribers,
+			Help: "The number of subscribers of the wizard bus topics",
+		},
+		[]string{WizardBusTopicLabelName},
+	)
 )
 
 func init() {
 	_ = prometheus.Register(messagePublishedCounter)
 	_ = prometheus.Register(messagePublishedDurations)
+	_ = prometheus.Register(messageDeliveryDurations)
+	_ = prometheus.Register(messageDroppedCounter)
+	_ = prometheus.Register(subscriberGauge)
+}
+
+// TopicInfo describes a topic of the bus, and its subscribers.
+type TopicInfo struct {
+	Topic           string           `json:"topic"`
+	Subscribers     []SubscriberInfo `json:"subscribers"`
+	Published       uint64           `json:"published"`
+	Dropped         uint64           `json:"dropped"`
+	LastPublishedAt *time.Time       `json:"last_published_at,omitempty"`
+	Closed          bool             `json:"closed"`
+}
+
+// SubscriberInfo describes the subscriber of a consumer to a topic, such as
+// an agent session or a daemon of the backend.
+type SubscriberInfo struct {
+	Consumer      string `json:"consumer"`
+	QueueDepth    int    `json:"queue_depth"`
+	QueueCapacity int    `json:"queue_capacity"`
 }
 
 // WizardBus is a message bus.
@@ -91,6 +141,40 @@ func (b *WizardBus) Err() <-chan error {
 	return b.errchan
 }
 
+// QueueDepth returns the number of messages waiting to be received by the
+// most saturated subscriber of the bus, and the capacity of its channel.
+func (b *WizardBus) QueueDepth() (depth, capacity int) {
+	b.topics.Range(func(_, value interface{}) bool {
+		t := value.(*wizardTopic)
+		t.RLock()
+		defer t.RUnlock()
+		for _, subscriber := range t.bindings {
+			receiver := subscriber.Receiver()
+			l, c := len(receiver), cap(receiver)
+			if capacity == 0 || l*capacity > depth*c {
+				depth, capacity = l, c
+			}
+		}
+		return true
+	})
+	return depth, capacity
+}
+
+// Topics describes the topics of the bus, sorted by name. The counts of
+// messages of a topic start when it is created by its first subscription, and
+// the topics whose subscriptions were all cancelled are closed.
+func (b *WizardBus) Topics() []TopicInfo {
+	var topics []TopicInfo
+	b.topics.Range(func(_, value interface{}) bool {
+		topics = append(topics, value.(*wizardTopic).info())
+		return true
+	})
+	sort.Slice(topics, func(i, j int) bool {
+		return topics[i].Topic < topics[j].Topic
+	})
+	return topics
+}
+
 // Name returns the daemon name
 func (b *WizardBus) Name() string {
 	return "message_bus"
@@ -109,6 +193,7 @@ func (b *WizardBus) createTopic(topic string) *wizardTopic {
 		id:       topic,
 		bindings: make(map[string]Subscriber),
 		done:     make(chan struct{}),
+		closed:   make(chan struct{}),
 	}
 	return wTopic
 }
@@ -139,6 +224,19 @@ func (b *WizardBus) Subscribe(topic string, consumer string, sub Subscriber) (Su
 	return subscription, err
 }
 
+// topicLabel returns the label of topic in the metrics of the bus. The topics
+// of the check subscriptions, of the entity configs and of the keepalive
+// burials are labelled together, since they are named after their namespace
+// and subscription or entity.
+func topicLabel(topic string) string {
+	for _, prefix := range []string{TopicSubscriptions, TopicEntityConfig, TopicBurial} {
+		if strings.HasPrefix(topic, prefix+":") {
+			return prefix
+		}
+	}
+	return topic
+}
+
 func findGenericTopic(topic string) string {
 	index := strings.IndexRune(topic, ':')
 	if index <= 0 {
@@ -150,17 +248,17 @@ func findGenericTopic(topic string) string {
 // Publish publishes a message to a topic. If the topic does not
 // exist, this is a noop.
 func (b *WizardBus) Publish(topic string, msg interface{}) error {
-	genericTopic := findGenericTopic(topic)
+	label := topicLabel(topic)
 	then := time.Now()
 	defer func() {
 		duration := time.Since(then)
-		messagePublishedDurations.WithLabelValues(genericTopic).Observe(float64(duration) / float64(time.Millisecond))
+		messagePublishedDurations.WithLabelValues(label).Observe(float64(duration) / float64(time.Millisecond))
 	}()
 
 	value, ok := b.topics.Load(topic)
 	if ok {
 		wTopic := value.(*wizardTopic)
-		defer messagePublishedCounter.WithLabelValues(genericTopic).Inc()
+		defer messagePublishedCounter.WithLabelValues(label).Inc()
 		wTopic.Send(msg)
 	}
 
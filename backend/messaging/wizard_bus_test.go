@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -133,3 +134,113 @@ func TestBug1407(t *testing.T) {
 	topic := value.(*wizardTopic)
 	assert.False(t, topic.IsClosed())
 }
+
+func TestWizardBusQueueDepth(t *testing.T) {
+	b, err := NewWizardBus(WizardBusConfig{})
+	require.NoError(t, err)
+	require.NoError(t, b.Start())
+
+	depth, capacity := b.QueueDepth()
+	assert.Equal(t, 0, depth)
+	assert.Equal(t, 0, capacity)
+
+	small := channelSubscriber{make(chan interface{}, 4)}
+	large := channelSubscriber{make(chan interface{}, 100)}
+	_, err = b.Subscribe("small", "1", small)
+	require.NoError(t, err)
+	_, err = b.Subscribe("large", "1", large)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.Publish("small", i))
+		require.NoError(t, b.Publish("large", i))
+	}
+
+	// The most saturated subscriber is reported
+	depth, capacity = b.QueueDepth()
+	assert.Equal(t, 3, depth)
+	assert.Equal(t, 4, capacity)
+}
+
+type overflowSubscriber struct {
+	channelSubscriber
+	overflows []interface{}
+}
+
+func (o *overflowSubscriber) Overflow(message interface{}) bool {
+	o.overflows = append(o.overflows, message)
+	return false
+}
+
+func TestWizardBusOverflow(t *testing.T) {
+	b, err := NewWizardBus(WizardBusConfig{})
+	require.NoError(t, err)
+	require.NoError(t, b.Start())
+
+	subscriber := &overflowSubscriber{channelSubscriber: channelSubscriber{make(chan interface{}, 1)}}
+	_, err = b.Subscribe("topic", "1", subscriber)
+	require.NoError(t, err)
+
+	// The messages sent to the full receiver are handed to the subscriber,
+	// which drops them instead of blocking the bus
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.Publish("topic", i))
+	}
+	assert.Equal(t, []interface{}{1, 2}, subscriber.overflows)
+	assert.Equal(t, 0, <-subscriber.Channel)
+}
+
+func TestWizardBusTopics(t *testing.T) {
+	b, err := NewWizardBus(WizardBusConfig{})
+	require.NoError(t, err)
+	require.NoError(t, b.Start())
+
+	topic := SubscriptionTopic("default", "linux")
+	agent1 := &overflowSubscriber{channelSubscriber: channelSubscriber{make(chan interface{}, 1)}}
+	agent2 := channelSubscriber{make(chan interface{}, 10)}
+	_, err = b.Subscribe(topic, "default:agent1-uuid", agent1)
+	require.NoError(t, err)
+	subscription, err := b.Subscribe(topic, "default:agent2-uuid", agent2)
+	require.NoError(t, err)
+	_, err = b.Subscribe(TopicEventRaw, "eventd", channelSubscriber{make(chan interface{}, 10)})
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, testutil.ToFloat64(subscriberGauge.WithLabelValues(TopicSubscriptions)))
+
+	// The second message overflows the receiver of the first agent
+	require.NoError(t, b.Publish(topic, "request1"))
+	require.NoError(t, b.Publish(topic, "request2"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(messageDroppedCounter.WithLabelValues(TopicSubscriptions)))
+
+	topics := b.Topics()
+	require.Len(t, topics, 2)
+	assert.Equal(t, topic, topics[0].Topic)
+	assert.Equal(t, uint64(2), topics[0].Published)
+	assert.Equal(t, uint64(1), topics[0].Dropped)
+	assert.NotNil(t, topics[0].LastPublishedAt)
+	assert.Equal(t, []SubscriberInfo{
+		{Consumer: "default:agent1-uuid", QueueDepth: 1, QueueCapacity: 1},
+		{Consumer: "default:agent2-uuid", QueueDepth: 2, QueueCapacity: 10},
+	}, topics[0].Subscribers)
+	assert.Equal(t, TopicEventRaw, topics[1].Topic)
+	assert.Equal(t, uint64(0), topics[1].Published)
+	assert.Nil(t, topics[1].LastPublishedAt)
+
+	// Cancelling a subscription twice only removes its subscriber once
+	require.NoError(t, subscription.Cancel())
+	require.NoError(t, subscription.Cancel())
+	assert.Equal(t, 1.0, testutil.ToFloat64(subscriberGauge.WithLabelValues(TopicSubscriptions)))
+
+	require.NoError(t, b.Stop())
+	assert.Equal(t, 0.0, testutil.ToFloat64(subscriberGauge.WithLabelValues(TopicSubscriptions)))
+	for _, info := range b.Topics() {
+		assert.True(t, info.Closed)
+		assert.Empty(t, info.Subscribers)
+	}
+}
+
+func TestTopicLabel(t *testing.T) {
+	assert.Equal(t, TopicSubscriptions, topicLabel(SubscriptionTopic("default", "linux")))
+	assert.Equal(t, TopicEntityConfig, topicLabel(EntityConfigTopic("default", "agent1")))
+	assert.Equal(t, TopicBurial, topicLabel(BurialTopic("default", "agent1")))
+	assert.Equal(t, TopicEventRaw, topicLabel(TopicEventRaw))
+}
@@ -1,16 +1,30 @@
 package messaging
 
 import (
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // wizardTopic encapsulates state around a WizardBus topic and its
 // consumer channel bindings.
 type wizardTopic struct {
+	// published and dropped count the messages sent to the topic, and the
+	// messages a subscriber did not receive. lastPublished is the time of the
+	// last message in nanoseconds. They come first to be 64-bit aligned.
+	published     uint64
+	dropped       uint64
+	lastPublished int64
+
 	id       string
 	bindings map[string]Subscriber
 	sync.RWMutex
 	done chan struct{}
+
+	// closed is closed when the topic is closed by the bus, which drops its
+	// subscriptions.
+	closed chan struct{}
 }
 
 // Send a message to all subscribers to this topic.
@@ -22,9 +36,24 @@ func (t *wizardTopic) Send(msg interface{}) {
 	}
 	t.RUnlock()
 
+	atomic.AddUint64(&t.published, 1)
+	atomic.StoreInt64(&t.lastPublished, time.Now().UnixNano())
+	label := topicLabel(t.id)
 	for _, subscriber := range subscribers {
 		topicCounter.WithLabelValues(t.id).Set(float64(len(subscriber.Receiver())))
-		safeSend(subscriber.Receiver(), msg, t.done)
+		start := time.Now()
+		var delivered bool
+		if handler, ok := subscriber.(OverflowHandler); ok {
+			delivered = safeSendOverflow(handler, msg, t.done)
+		} else {
+			delivered = safeSend(subscriber.Receiver(), msg, t.done)
+		}
+		if !delivered {
+			atomic.AddUint64(&t.dropped, 1)
+			messageDroppedCounter.WithLabelValues(label).Inc()
+			continue
+		}
+		messageDeliveryDurations.WithLabelValues(label).Observe(time.Since(start).Seconds())
 	}
 }
 
@@ -34,32 +63,66 @@ func (t *wizardTopic) Send(msg interface{}) {
 //
 // The topic reads the subscribers and then releases its lock, in Send(). In rare cases,
 // cancelling a subscription can lead to a send on a closed channel.
-func safeSend(c chan<- interface{}, message interface{}, done chan struct{}) {
+//
+// It returns false if the message was not sent.
+func safeSend(c chan<- interface{}, message interface{}, done chan struct{}) (sent bool) {
 	defer func() {
 		_ = recover()
 	}()
 	select {
 	case c <- message:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// safeSendOverflow is like safeSend, but lets handler decide whether to block
+// or to drop message when its receiver is full.
+func safeSendOverflow(handler OverflowHandler, message interface{}, done chan struct{}) (sent bool) {
+	defer func() {
+		_ = recover()
+	}()
+	c := handler.Receiver()
+	select {
+	case c <- message:
+		return true
+	default:
+	}
+	if !handler.Overflow(message) {
+		return false
+	}
+	select {
+	case c <- message:
+		return true
 	case <-done:
+		return false
 	}
 }
 
 // Subscribe a Subscriber to this topic and receive a Subscription.
 func (t *wizardTopic) Subscribe(id string, sub Subscriber) (Subscription, error) {
 	t.Lock()
+	if _, ok := t.bindings[id]; !ok {
+		subscriberGauge.WithLabelValues(topicLabel(t.id)).Inc()
+	}
 	t.bindings[id] = sub
 	t.Unlock()
 
 	return Subscription{
 		id:     id,
 		cancel: t.unsubscribe,
+		broken: t.closed,
 	}, nil
 }
 
 // Unsubscribe a consumer from this topic.
 func (t *wizardTopic) unsubscribe(id string) error {
 	t.Lock()
-	delete(t.bindings, id)
+	if _, ok := t.bindings[id]; ok {
+		subscriberGauge.WithLabelValues(topicLabel(t.id)).Dec()
+		delete(t.bindings, id)
+	}
 	if len(t.bindings) == 0 {
 		select {
 		case <-t.done:
@@ -75,16 +138,47 @@ func (t *wizardTopic) unsubscribe(id string) error {
 // Close all WizardTopic bindings.
 func (t *wizardTopic) Close() {
 	t.Lock()
+	defer t.Unlock()
 	select {
 	case <-t.done:
 		return
 	default:
 	}
 	close(t.done)
+	close(t.closed)
+	subscriberGauge.WithLabelValues(topicLabel(t.id)).Sub(float64(len(t.bindings)))
 	for consumer := range t.bindings {
 		delete(t.bindings, consumer)
 	}
-	t.Unlock()
+}
+
+// info describes the topic and its subscribers, sorted by consumer.
+func (t *wizardTopic) info() TopicInfo {
+	info := TopicInfo{
+		Topic:       t.id,
+		Subscribers: []SubscriberInfo{},
+		Published:   atomic.LoadUint64(&t.published),
+		Dropped:     atomic.LoadUint64(&t.dropped),
+		Closed:      t.IsClosed(),
+	}
+	if last := atomic.LoadInt64(&t.lastPublished); last > 0 {
+		lastPublishedAt := time.Unix(0, last)
+		info.LastPublishedAt = &lastPublishedAt
+	}
+	t.RLock()
+	defer t.RUnlock()
+	for consumer, subscriber := range t.bindings {
+		receiver := subscriber.Receiver()
+		info.Subscribers = append(info.Subscribers, SubscriberInfo{
+			Consumer:      consumer,
+			QueueDepth:    len(receiver),
+			QueueCapacity: cap(receiver),
+		})
+	}
+	sort.Slice(info.Subscribers, func(i, j int) bool {
+		return info.Subscribers[i].Consumer < info.Subscribers[j].Consumer
+	})
+	return info
 }
 
 func (t *wizardTopic) IsClosed() bool {
@@ -0,0 +1,7 @@
+package mirror
+
+import "github.com/sirupsen/logrus"
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "mirror",
+})
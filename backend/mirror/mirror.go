@@ -0,0 +1,485 @@
+// Package mirror mirrors the events processed by the backend to the events
+// API of a peer cluster, so that a passive cluster is kept up to date and can
+// take over the monitoring if the active one fails. The events are mirrored
+// asynchronously, in batches: a peer that is unavailable delays the mirroring
+// but never the event pipeline, and the events that don't fit in the buffer
+// while it is unavailable are dropped.
+//
+// Each batch is created with a request to the bulk events API of the peer per
+// namespace. The events are sent one at a time to the peers without it.
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/daemon"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/sensu/sensu-go/backend/metrics"
+	"github.com/sensu/sensu-go/util/retry"
+)
+
+const (
+	componentName = "mirrord"
+
+	// MirroredAnnotation is the annotation of the mirrored events, set to
+	// the name of the backend that mirrored them. The events that have it
+	// are not mirrored again, so that two clusters can mirror each other.
+	MirroredAnnotation = "sensu.io/mirrored-by"
+
+	// DefaultBatchSize is the maximum number of events sent at once when
+	// Config.BatchSize is zero.
+	DefaultBatchSize = 100
+
+	// DefaultFlushInterval is the interval at which the incomplete batches
+	// are sent when Config.FlushInterval is zero.
+	DefaultFlushInterval = time.Second
+
+	// DefaultBufferSize is the number of events buffered while the peer is
+	// unavailable when Config.BufferSize is zero.
+	DefaultBufferSize = 10000
+
+	// DefaultMaxRetryDelay is the maximum delay between the attempts to send
+	// a batch when Config.MaxRetryDelay is zero.
+	DefaultMaxRetryDelay = time.Minute
+
+	// EventsCounterVec is the name of the counter of the mirrored events.
+	EventsCounterVec = "sensu_go_mirror_events_total"
+
+	// EventsLabelOutcome is the outcome label of the events counter.
+	EventsLabelOutcome = "outcome"
+
+	outcomeSent     = "sent"
+	outcomeRejected = "rejected"
+	outcomeDropped  = "dropped"
+
+	// The statuses of the events in the responses of the bulk events API.
+	bulkStatusCreated  = "created"
+	bulkStatusRejected = "rejected"
+
+	requestTimeout = 30 * time.Second
+)
+
+var (
+	// Events counts the events sent to the peer, rejected by the peer, and
+	// dropped because the buffer was full.
+	Events = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: EventsCounterVec,
+			Help: "The total number of events mirrored to the peer cluster, by outcome",
+		},
+		[]string{EventsLabelOutcome},
+	)
+)
+
+func init() {
+	if err := prometheus.Register(Events); err != nil {
+		metrics.LogError(logger, EventsCounterVec, err)
+	}
+}
+
+// Config configures a Mirrord.
+type Config struct {
+	Bus messaging.MessageBus
+
+	// URL is the URL of the API of the peer cluster.
+	URL string
+
+	// APIKey is the API key with which the events are created on the peer.
+	APIKey string
+
+	// TLS is the TLS configuration of the connections to the peer, or nil
+	// for the default one.
+	TLS *tls.Config
+
+	// BackendName is the name of the backend, set as the MirroredAnnotation
+	// of the mirrored events.
+	BackendName string
+
+	// Namespaces are the namespaces of the mirrored events, or empty to
+	// mirror the events of every namespace.
+	Namespaces []string
+
+	// Labels are the labels the mirrored events must have, on themselves or
+	// on their entity.
+	Labels map[string]string
+
+	// BatchSize is the maximum number of events sent at once, in a single
+	// request to the bulk events API of the peer.
+	BatchSize int
+
+	// FlushInterval is the interval at which the incomplete batches are
+	// sent.
+	FlushInterval time.Duration
+
+	// BufferSize is the number of events buffered while a batch is sent.
+	BufferSize int
+
+	// MaxRetryDelay is the maximum delay between the attempts to send a
+	// batch to the peer while it is unavailable.
+	MaxRetryDelay time.Duration
+}
+
+// Mirrord is the daemon that mirrors the events processed by the backend to
+// a peer cluster.
+type Mirrord struct {
+	bus           messaging.MessageBus
+	client        *http.Client
+	url           string
+	apiKey        string
+	backendName   string
+	namespaces    map[string]bool
+	labels        map[string]string
+	batchSize     int
+	flushInterval time.Duration
+	maxRetryDelay time.Duration
+	events        chan interface{}
+	subscription  messaging.Subscription
+	loop          *daemon.Loop
+}
+
+// New creates a new Mirrord.
+func New(cfg Config) (*Mirrord, error) {
+	if cfg.Bus == nil {
+		return nil, errors.New("a message bus is required")
+	}
+	if _, err := url.ParseRequestURI(cfg.URL); err != nil {
+		return nil, fmt.Errorf("invalid peer URL: %s", err)
+	}
+	m := &Mirrord{
+		bus:           cfg.Bus,
+		client:        &http.Client{Timeout: requestTimeout},
+		url:           strings.TrimSuffix(cfg.URL, "/"),
+		apiKey:        cfg.APIKey,
+		backendName:   cfg.BackendName,
+		labels:        cfg.Labels,
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+		maxRetryDelay: cfg.MaxRetryDelay,
+		loop:          daemon.NewLoop(),
+	}
+	if cfg.TLS != nil {
+		m.client.Transport = &http.Transport{TLSClientConfig: cfg.TLS}
+	}
+	if len(cfg.Namespaces) > 0 {
+		m.namespaces = make(map[string]bool, len(cfg.Namespaces))
+		for _, namespace := range cfg.Namespaces {
+			m.namespaces[namespace] = true
+		}
+	}
+	if m.batchSize <= 0 {
+		m.batchSize = DefaultBatchSize
+	}
+	if m.flushInterval <= 0 {
+		m.flushInterval = DefaultFlushInterval
+	}
+	if m.maxRetryDelay <= 0 {
+		m.maxRetryDelay = DefaultMaxRetryDelay
+	}
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	m.events = make(chan interface{}, bufferSize)
+	return m, nil
+}
+
+// Receiver implements messaging.Subscriber.
+func (m *Mirrord) Receiver() chan<- interface{} {
+	return m.events
+}
+
+// Overflow implements messaging.OverflowHandler. The events are dropped when
+// the buffer is full, rather than blocking the event pipeline.
+func (m *Mirrord) Overflow(interface{}) bool {
+	Events.WithLabelValues(outcomeDropped).Inc()
+	return false
+}
+
+// Start starts the mirroring of the events.
+func (m *Mirrord) Start() error {
+	subscription, err := messaging.Supervise(m.loop.Context(), m.bus, messaging.TopicEvent, componentName, m)
+	if err != nil {
+		return err
+	}
+	m.subscription = subscription
+	m.loop.Go(m.run)
+	return nil
+}
+
+// Stop stops the mirroring of the events. The buffered events are dropped.
+func (m *Mirrord) Stop() error {
+	err := m.subscription.Cancel()
+	_ = m.loop.Stop()
+	return err
+}
+
+// Err returns a channel on which to listen for terminal errors.
+func (m *Mirrord) Err() <-chan error {
+	return m.loop.Err()
+}
+
+// Name returns the daemon name.
+func (m *Mirrord) Name() string {
+	return componentName
+}
+
+func (m *Mirrord) run(ctx context.Context) {
+	ticker := time.NewTicker(m.flushInterval)
+	defer ticker.Stop()
+	batch := make([]*corev2.Event, 0, m.batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-m.events:
+			event, ok := msg.(*corev2.Event)
+			if !ok || !m.matches(event) {
+				continue
+			}
+			batch = append(batch, event)
+			if len(batch) < m.batchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		if err := m.send(ctx, batch); err != nil {
+			return
+		}
+		batch = batch[:0]
+	}
+}
+
+// matches returns true if event is mirrored: it is in one of the mirrored
+// namespaces, has the mirrored labels and was not mirrored from a peer. The
+// events without a check, such as the metrics events, are not mirrored.
+func (m *Mirrord) matches(event *corev2.Event) bool {
+	if !event.HasCheck() || event.Entity == nil {
+		return false
+	}
+	if _, ok := event.Annotations[MirroredAnnotation]; ok {
+		return false
+	}
+	if m.namespaces != nil && !m.namespaces[event.Namespace] {
+		return false
+	}
+	for key, value := range m.labels {
+		label, ok := event.Labels[key]
+		if !ok && event.Entity != nil {
+			label, ok = event.Entity.Labels[key]
+		}
+		if !ok || label != value {
+			return false
+		}
+	}
+	return true
+}
+
+// send sends the events of batch to the peer, with a request per run of
+// events of the same namespace. The events that were not created are sent
+// again, before the others, with an exponential backoff while the peer is
+// unavailable, until ctx is done. The events that the peer rejects are
+// dropped.
+func (m *Mirrord) send(ctx context.Context, batch []*corev2.Event) error {
+	backoff := retry.ExponentialBackoff{
+		Ctx:                  ctx,
+		InitialDelayInterval: 100 * time.Millisecond,
+		MaxDelayInterval:     m.maxRetryDelay,
+		Multiplier:           2,
+	}
+	return backoff.Retry(func(int) (bool, error) {
+		for len(batch) > 0 {
+			n := 1
+			for n < len(batch) && batch[n].Namespace == batch[0].Namespace {
+				n++
+			}
+			pending, err := m.sendNamespace(ctx, batch[:n])
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.WithError(err).Warn("could not mirror the events to the peer, retrying")
+				}
+				batch = append(pending[:len(pending):len(pending)], batch[n:]...)
+				return false, nil
+			}
+			batch = batch[n:]
+		}
+		return true, nil
+	})
+}
+
+// sendNamespace sends events, which are in the same namespace, to the bulk
+// events API of the peer, or one at a time if the peer doesn't have it. It
+// returns the events that must be sent again, with the error for which they
+// were not created.
+func (m *Mirrord) sendNamespace(ctx context.Context, events []*corev2.Event) ([]*corev2.Event, error) {
+	mirrored := make([]*corev2.Event, 0, len(events))
+	for _, event := range events {
+		mirrored = append(mirrored, m.mirrored(event))
+	}
+	path := fmt.Sprintf("%s/api/core/v2/namespaces/%s/events/bulk", m.url, url.PathEscape(events[0].Namespace))
+	var result bulkResult
+	err := m.do(ctx, http.MethodPost, path, mirrored, &result)
+	var rejected *rejectedError
+	if errors.As(err, &rejected) {
+		switch rejected.status {
+		case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusRequestEntityTooLarge:
+			// The peer doesn't have the bulk events API, or the batch is
+			// too large for it
+			return m.sendEach(ctx, events)
+		}
+		for _, event := range events {
+			m.reject(event, err)
+		}
+		return nil, nil
+	} else if err != nil {
+		return events, err
+	}
+	if len(result.Events) != len(events) {
+		err := fmt.Errorf("peer responded with %d results for %d events", len(result.Events), len(events))
+		for _, event := range events {
+			m.reject(event, err)
+		}
+		return nil, nil
+	}
+	var pending []*corev2.Event
+	for i, item := range result.Events {
+		switch item.Status {
+		case bulkStatusCreated:
+			Events.WithLabelValues(outcomeSent).Inc()
+		case bulkStatusRejected:
+			m.reject(events[i], errors.New(item.Error))
+		default:
+			pending = append(pending, events[i])
+			err = fmt.Errorf("peer could not create the event %s: %s", eventKey(events[i]), item.Error)
+		}
+	}
+	return pending, err
+}
+
+// sendEach sends events to the peer one at a time. It returns the events
+// that must be sent again, with the error for which they were not created.
+func (m *Mirrord) sendEach(ctx context.Context, events []*corev2.Event) ([]*corev2.Event, error) {
+	for i, event := range events {
+		path := fmt.Sprintf("%s/api/core/v2/namespaces/%s/events/%s/%s",
+			m.url,
+			url.PathEscape(event.Namespace),
+			url.PathEscape(event.Entity.Name),
+			url.PathEscape(event.Check.Name),
+		)
+		err := m.do(ctx, http.MethodPut, path, m.mirrored(event), nil)
+		var rejected *rejectedError
+		if errors.As(err, &rejected) {
+			m.reject(event, err)
+		} else if err != nil {
+			return events[i:], err
+		} else {
+			Events.WithLabelValues(outcomeSent).Inc()
+		}
+	}
+	return nil, nil
+}
+
+func (m *Mirrord) reject(event *corev2.Event, err error) {
+	Events.WithLabelValues(outcomeRejected).Inc()
+	logger.WithError(err).WithField("event", eventKey(event)).Error("the peer rejected the mirrored event, dropping it")
+}
+
+// mirrored returns a copy of event with the MirroredAnnotation.
+func (m *Mirrord) mirrored(event *corev2.Event) *corev2.Event {
+	mirrored := *event
+	mirrored.ObjectMeta.Annotations = make(map[string]string, len(event.Annotations)+1)
+	for key, value := range event.Annotations {
+		mirrored.ObjectMeta.Annotations[key] = value
+	}
+	mirrored.ObjectMeta.Annotations[MirroredAnnotation] = m.backendName
+	return &mirrored
+}
+
+// bulkResult is the response of the bulk events API, with the status of each
+// event of the request, in order.
+type bulkResult struct {
+	Events []bulkItem `json:"events"`
+}
+
+type bulkItem struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// rejectedError is the error of a request that the peer rejected, which is
+// not sent again.
+type rejectedError struct {
+	status int
+	msg    string
+}
+
+func (e *rejectedError) Error() string {
+	return e.msg
+}
+
+// do sends a request with the JSON body to path, and decodes the response in
+// result, unless it is nil. The errors are transient, unless they are a
+// *rejectedError.
+func (m *Mirrord) do(ctx context.Context, method, path string, body, result interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return &rejectedError{msg: err.Error()}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Key "+m.apiKey)
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if result == nil {
+			return nil
+		}
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("peer responded with %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		// The peer is unavailable, the events are sent again
+		return err
+	}
+	return &rejectedError{status: resp.StatusCode, msg: err.Error()}
+}
+
+func eventKey(event *corev2.Event) string {
+	return fmt.Sprintf("%s/%s/%s", event.Namespace, event.Entity.Name, event.Check.Name)
+}
+
+// ParseLabels parses the labels of the key=value pairs of labels.
+func ParseLabels(labels []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(labels))
+	for _, label := range labels {
+		key, value, ok := strings.Cut(label, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", label)
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
@@ -0,0 +1,212 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// peer is the events API of a peer cluster. The statuses of the responses,
+// and of the events created in bulk, are created by default.
+type peer struct {
+	mu           sync.Mutex
+	noBulk       bool
+	statuses     []int
+	bulkStatuses []string
+	paths        []string
+	events       []*corev2.Event
+}
+
+func (p *peer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	bulk := strings.HasSuffix(r.URL.Path, "/bulk")
+	if bulk && p.noBulk {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	status := http.StatusCreated
+	if len(p.statuses) > 0 {
+		status, p.statuses = p.statuses[0], p.statuses[1:]
+	}
+	if status != http.StatusCreated {
+		w.WriteHeader(status)
+		return
+	}
+	if r.Header.Get("Authorization") != "Key secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if !bulk {
+		var event corev2.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		p.paths = append(p.paths, r.URL.Path)
+		p.events = append(p.events, &event)
+		w.WriteHeader(status)
+		return
+	}
+	var events []*corev2.Event
+	if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var result bulkResult
+	result.Events = make([]bulkItem, len(events))
+	for i, event := range events {
+		result.Events[i].Status = bulkStatusCreated
+		if len(p.bulkStatuses) > 0 {
+			result.Events[i].Status, p.bulkStatuses = p.bulkStatuses[0], p.bulkStatuses[1:]
+		}
+		if result.Events[i].Status == bulkStatusCreated {
+			p.paths = append(p.paths, r.URL.Path)
+			p.events = append(p.events, event)
+		}
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (p *peer) received() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.paths...)
+}
+
+func newMirrord(t *testing.T, p *peer, cfg Config) *Mirrord {
+	t.Helper()
+	server := httptest.NewServer(p)
+	t.Cleanup(server.Close)
+	bus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+	require.NoError(t, err)
+	cfg.Bus = bus
+	cfg.URL = server.URL
+	cfg.APIKey = "secret"
+	cfg.BackendName = "backend1"
+	m, err := New(cfg)
+	require.NoError(t, err)
+	return m
+}
+
+func TestMatches(t *testing.T) {
+	m := newMirrord(t, &peer{}, Config{
+		Namespaces: []string{"default"},
+		Labels:     map[string]string{"region": "us"},
+	})
+
+	event := corev2.FixtureEvent("entity1", "check1")
+	assert.False(t, m.matches(event))
+
+	event.Entity.Labels = map[string]string{"region": "us"}
+	assert.True(t, m.matches(event))
+
+	event.Labels = map[string]string{"region": "eu"}
+	assert.False(t, m.matches(event))
+
+	event = corev2.FixtureEvent("entity1", "check1")
+	event.Labels = map[string]string{"region": "us"}
+	event.Namespace = "dev"
+	assert.False(t, m.matches(event))
+
+	event.Namespace = "default"
+	event.Annotations = map[string]string{MirroredAnnotation: "peer1"}
+	assert.False(t, m.matches(event))
+
+	event.Annotations = nil
+	event.Check = nil
+	assert.False(t, m.matches(event))
+}
+
+func TestSend(t *testing.T) {
+	p := &peer{
+		bulkStatuses: []string{bulkStatusCreated, "failed", bulkStatusRejected},
+		statuses:     []int{http.StatusServiceUnavailable},
+	}
+	m := newMirrord(t, p, Config{MaxRetryDelay: 10 * time.Millisecond})
+
+	acme := corev2.FixtureEvent("entity3", "check3")
+	acme.Namespace = "acme"
+	acme.Entity.Namespace = "acme"
+	acme.Check.Namespace = "acme"
+	batch := []*corev2.Event{
+		corev2.FixtureEvent("entity1", "check1"),
+		corev2.FixtureEvent("entity2", "check2"),
+		acme,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, m.send(ctx, batch))
+
+	// The events are sent again once the peer is available, the event that
+	// failed is sent again before the next namespace, and is rejected
+	assert.Equal(t, []string{
+		"/api/core/v2/namespaces/default/events/bulk",
+		"/api/core/v2/namespaces/acme/events/bulk",
+	}, p.received())
+	assert.Equal(t, "entity1", p.events[0].Entity.Name)
+	assert.Equal(t, "entity3", p.events[1].Entity.Name)
+	assert.Equal(t, "backend1", p.events[0].Annotations[MirroredAnnotation])
+	assert.Empty(t, batch[0].Annotations[MirroredAnnotation])
+}
+
+func TestSendEach(t *testing.T) {
+	p := &peer{noBulk: true, statuses: []int{http.StatusServiceUnavailable, http.StatusCreated, http.StatusBadRequest}}
+	m := newMirrord(t, p, Config{MaxRetryDelay: 10 * time.Millisecond})
+
+	batch := []*corev2.Event{
+		corev2.FixtureEvent("entity1", "check1"),
+		corev2.FixtureEvent("entity2", "check2"),
+		corev2.FixtureEvent("entity3", "check3"),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, m.send(ctx, batch))
+
+	// The first event is sent again once the peer is available, and the
+	// second one is rejected
+	assert.Equal(t, []string{
+		"/api/core/v2/namespaces/default/events/entity1/check1",
+		"/api/core/v2/namespaces/default/events/entity3/check3",
+	}, p.received())
+	assert.Equal(t, "backend1", p.events[0].Annotations[MirroredAnnotation])
+	assert.Empty(t, batch[0].Annotations[MirroredAnnotation])
+}
+
+func TestMirrord(t *testing.T) {
+	p := &peer{}
+	m := newMirrord(t, p, Config{BatchSize: 2, FlushInterval: time.Hour})
+	require.NoError(t, m.bus.Start())
+	require.NoError(t, m.Start())
+	defer func() {
+		require.NoError(t, m.Stop())
+	}()
+
+	require.NoError(t, m.bus.Publish(messaging.TopicEvent, corev2.FixtureEvent("entity1", "check1")))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, p.received(), "incomplete batch sent")
+
+	require.NoError(t, m.bus.Publish(messaging.TopicEvent, corev2.FixtureEvent("entity2", "check2")))
+	assert.Eventually(t, func() bool {
+		return len(p.received()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels([]string{"region=us", "tier="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "us", "tier": ""}, labels)
+
+	_, err = ParseLabels([]string{"region"})
+	assert.Error(t, err)
+}
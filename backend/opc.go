@@ -2,12 +2,24 @@ package backend
 
 import (
 	"context"
+	"runtime"
 	"time"
 
+	"github.com/sensu/sensu-go/backend/agentd"
+	"github.com/sensu/sensu-go/backend/membership"
 	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/version"
 )
 
+// CheckInLoop checks the backend in with the operator concierge every second,
+// until ctx is canceled. Each check-in carries the membership heartbeat of the
+// backend.
 func CheckInLoop(ctx context.Context, backendName string, opc store.OperatorConcierge) {
+	heartbeat := membership.Heartbeat{
+		Version:   version.Semver(),
+		StoreType: membership.StorePostgres,
+		StartedAt: time.Now().Unix(),
+	}
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 	state := store.OperatorState{
@@ -21,6 +33,11 @@ func CheckInLoop(ctx context.Context, backendName string, opc store.OperatorConc
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			heartbeat.Load = membership.Load{
+				AgentSessions: agentd.ActiveSessions(),
+				Goroutines:    runtime.NumGoroutine(),
+			}
+			state.Metadata = heartbeat.Encode()
 			if err := opc.CheckIn(ctx, state); err != nil {
 				logger.WithError(err).Error("error checking-in backend operator")
 			}
@@ -119,6 +119,22 @@ type AdapterV1 struct {
 	FilterAdapters  []FilterAdapter
 	MutatorAdapters []MutatorAdapter
 	HandlerAdapters []HandlerAdapter
+
+	// TraceStore stores the traces of traced events. Events are not traced
+	// if it is nil.
+	TraceStore store.EventTraceStore
+
+	// NotificationCounters counts the notifications of the workflows with a
+	// max_notifications_per_hour. The limit is not enforced if it is nil.
+	NotificationCounters store.NotificationCounterStore
+
+	// RemediationLocks holds the locks of the workflows with a remediation
+	// cooldown. The cooldown is not enforced if it is nil.
+	RemediationLocks store.RemediationLockStore
+
+	// AllowPipeMutatorTests allows SimulateMutator to run pipe mutators,
+	// which execute their command on the backend.
+	AllowPipeMutatorTests bool
 }
 
 func (a *AdapterV1) Name() string {
@@ -156,20 +172,34 @@ func (a *AdapterV1) Run(ctx context.Context, ref *corev2.ResourceReference, reso
 	fields := event.LogFields(false)
 	fields["adapter_name"] = a.Name()
 	fields["pipeline"] = ref.LogFields(false)
+	if id := store.EventRequestID(event); id != "" {
+		fields["request_id"] = id
+	}
 
 	// Prepare debug log entry
 	debugFields := event.LogFields(true)
 	debugFields["adapter_name"] = fields["adapter_name"]
 	debugFields["pipeline"] = fields["pipeline"]
+	if id, ok := fields["request_id"]; ok {
+		debugFields["request_id"] = id
+	}
 	logger.WithFields(debugFields).Debugf("adapter received event")
 
 	ctx = context.WithValue(ctx, corev2.NamespaceKey, event.Entity.Namespace)
 
+	if a.TraceStore != nil {
+		tracer := newTracer(event)
+		ctx = withTracer(ctx, tracer)
+		defer tracer.save(ctx, a.TraceStore)
+	}
+
 	pipeline, err := a.resolvePipelineReference(ctx, ref, event)
 	if err != nil {
+		tracerFromContext(ctx).record(ctx, TraceStepPipeline, ref.ResourceID(), "", err, nil)
 		return err
 	}
 	ctx = context.WithValue(ctx, corev2.PipelineKey, pipeline.Name)
+	event = withEventTimestamp(pipeline, event)
 
 	if len(pipeline.Workflows) < 1 {
 		return &ErrNoWorkflows{}
@@ -190,6 +220,19 @@ func (a *AdapterV1) Run(ctx context.Context, ref *corev2.ResourceReference, reso
 			continue
 		}
 
+		// Skip the workflow if it throttles the event
+		if a.throttleWorkflow(ctx, pipeline, workflow.Name, event) {
+			logger.WithFields(fields).Debug("event throttled by pipeline workflow")
+			continue
+		}
+
+		// Skip the workflow if its handler is in its remediation cooldown
+		locked, releaseRemediation := a.remediationLocked(ctx, pipeline, workflow, event)
+		if locked {
+			logger.WithFields(fields).Debug("pipeline workflow handler is in its remediation cooldown")
+			continue
+		}
+
 		// If no workflow mutator is set, use the JSON mutator
 		if workflow.Mutator == nil {
 			workflow.Mutator = &corev2.ResourceReference{
@@ -202,6 +245,7 @@ func (a *AdapterV1) Run(ctx context.Context, ref *corev2.ResourceReference, reso
 		// Process the event through the workflow mutator
 		mutatedData, err := a.processMutator(ctx, workflow.Mutator, event)
 		if err != nil {
+			releaseRemediation()
 			return err
 		}
 
@@ -210,6 +254,7 @@ func (a *AdapterV1) Run(ctx context.Context, ref *corev2.ResourceReference, reso
 		err = a.processHandler(ctx, workflow.Handler, event, mutatedData)
 		incrementCounter(workflow.Handler, err)
 		if err != nil {
+			releaseRemediation()
 			return err
 		}
 	}
@@ -60,15 +60,23 @@ func (a *AdapterV1) processFilter(ctx context.Context, ref *corev2.ResourceRefer
 			status = metricspkg.StatusLabelError
 		}
 		filterDuration.WithLabelValues(status, ref.ResourceID()).Observe(v * float64(1000))
+		observeFilter(event, ref, filtered, fErr, v)
 	}))
 	defer filterTimer.ObserveDuration()
 
 	filter, err := a.getFilterAdapterForResource(ctx, ref)
 	if err != nil {
+		tracerFromContext(ctx).record(ctx, TraceStepFilter, ref.ResourceID(), "", err, nil)
 		return false, err
 	}
 
-	return filter.Filter(ctx, ref, event)
+	filtered, err = filter.Filter(ctx, ref, event)
+	result := TraceResultAllowed
+	if filtered {
+		result = TraceResultFiltered
+	}
+	tracerFromContext(ctx).record(ctx, TraceStepFilter, ref.ResourceID(), result, err, nil)
+	return filtered, err
 }
 
 func (a *AdapterV1) getFilterAdapterForResource(ctx context.Context, ref *corev2.ResourceReference) (FilterAdapter, error) {
@@ -38,22 +38,46 @@ func init() {
 	}
 }
 
+// ReplayAnnotation is the annotation of the events replayed by the replay
+// harness. The handlers of these events are stubbed: the events go through
+// the filters and mutators of their pipelines, but are not handled. It is
+// only set by the replays API: the events received from the agents and
+// through the events API are stripped of it, so that they can't stub their
+// own handlers.
+const ReplayAnnotation = "sensu.io/replay"
+
+// StripReplayAnnotation removes the ReplayAnnotation of event.
+func StripReplayAnnotation(event *corev2.Event) {
+	if event != nil {
+		delete(event.Annotations, ReplayAnnotation)
+	}
+}
+
 func (a *AdapterV1) processHandler(ctx context.Context, ref *corev2.ResourceReference, event *corev2.Event, mutatedData []byte) (fErr error) {
+	if event != nil && event.Annotations[ReplayAnnotation] != "" {
+		tracerFromContext(ctx).record(ctx, TraceStepHandler, ref.ResourceID(), TraceResultStubbed, nil, nil)
+		return nil
+	}
+
 	handlerTimer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
 		status := metricspkg.StatusLabelSuccess
 		if fErr != nil {
 			status = metricspkg.StatusLabelError
 		}
 		handlerDuration.WithLabelValues(status, ref.ResourceID()).Observe(v * float64(1000))
+		observeHandler(event, ref, fErr, v)
 	}))
 	defer handlerTimer.ObserveDuration()
 
 	handler, err := a.getHandlerAdapterForResource(ctx, ref)
 	if err != nil {
+		tracerFromContext(ctx).record(ctx, TraceStepHandler, ref.ResourceID(), "", err, nil)
 		return err
 	}
 
-	return handler.Handle(ctx, ref, event, mutatedData)
+	err = handler.Handle(ctx, ref, event, mutatedData)
+	tracerFromContext(ctx).record(ctx, TraceStepHandler, ref.ResourceID(), TraceResultHandled, err, nil)
+	return err
 }
 
 func (a *AdapterV1) getHandlerAdapterForResource(ctx context.Context, ref *corev2.ResourceReference) (HandlerAdapter, error) {
@@ -141,6 +141,13 @@ func (l *LegacyAdapter) pipeHandler(ctx context.Context, handler *corev2.Handler
 	handlerExec.Env = env
 	handlerExec.Input = string(mutatedData[:])
 
+	// Keep the end of the large outputs, where the errors usually are
+	maxOutputSize, err := command.MaxOutputSize(handler.ObjectMeta)
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Warn("not limiting the handler output")
+	}
+	handlerExec.MaxOutputSize = maxOutputSize
+
 	// Only add assets to execution context if handler requires them
 	if len(handler.RuntimeAssets) != 0 {
 		logger.WithFields(fields).Debug("fetching assets for handler")
@@ -36,6 +36,7 @@ func TestAdapterV1_processHandler(t *testing.T) {
 		{
 			name: "returns an error when getHandlerAdapterForResource() returns an error",
 			args: args{
+				ctx: context.Background(),
 				ref: &corev2.ResourceReference{
 					APIVersion: "core/v2",
 					Type:       "Handler",
@@ -57,6 +58,7 @@ func TestAdapterV1_processHandler(t *testing.T) {
 				}(),
 			},
 			args: args{
+				ctx: context.Background(),
 				ref: &corev2.ResourceReference{
 					APIVersion: "core/v2",
 					Type:       "Handler",
@@ -78,11 +80,38 @@ func TestAdapterV1_processHandler(t *testing.T) {
 				}(),
 			},
 			args: args{
+				ctx: context.Background(),
+				ref: &corev2.ResourceReference{
+					APIVersion: "core/v2",
+					Type:       "Handler",
+					Name:       "handler1",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "stubs the handlers of replayed events",
+			fields: fields{
+				HandlerAdapters: func() []HandlerAdapter {
+					adapter := &mockpipeline.HandlerAdapter{}
+					adapter.On("CanHandle", mock.Anything).Return(true)
+					adapter.On("Handle", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+						Return(errors.New("handler error"))
+					return []HandlerAdapter{adapter}
+				}(),
+			},
+			args: args{
+				ctx: context.Background(),
 				ref: &corev2.ResourceReference{
 					APIVersion: "core/v2",
 					Type:       "Handler",
 					Name:       "handler1",
 				},
+				event: &corev2.Event{
+					ObjectMeta: corev2.ObjectMeta{
+						Annotations: map[string]string{ReplayAnnotation: "replay1"},
+					},
+				},
 			},
 			wantErr: false,
 		},
@@ -0,0 +1,154 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev2 "github.com/sensu/core/v2"
+)
+
+const (
+	// FilterLatency is the name of the prometheus histogram vec used to
+	// track the evaluation latency of each pipeline filter, by namespace.
+	FilterLatency = "sensu_go_pipeline_filter_latency_seconds"
+
+	// FilterEvaluations is the name of the prometheus counter vec used to
+	// count the evaluations of each pipeline filter, by namespace and
+	// outcome: allowed, filtered or error.
+	FilterEvaluations = "sensu_go_pipeline_filter_evaluations_total"
+
+	// MutatorLatency is the name of the prometheus histogram vec used to
+	// track the latency of each pipeline mutator, by namespace.
+	MutatorLatency = "sensu_go_pipeline_mutator_latency_seconds"
+
+	// MutatorEvaluations is the name of the prometheus counter vec used to
+	// count the executions of each pipeline mutator, by namespace and
+	// outcome: mutated or error.
+	MutatorEvaluations = "sensu_go_pipeline_mutator_evaluations_total"
+
+	// HandlerLatency is the name of the prometheus histogram vec used to
+	// track the latency of each pipeline handler, by namespace.
+	HandlerLatency = "sensu_go_pipeline_handler_latency_seconds"
+
+	// HandlerEvaluations is the name of the prometheus counter vec used to
+	// count the executions of each pipeline handler, by namespace and
+	// outcome: handled or error.
+	HandlerEvaluations = "sensu_go_pipeline_handler_evaluations_total"
+)
+
+var (
+	filterLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    FilterLatency,
+			Help:    "pipeline filter evaluation latency distribution, by namespace and filter",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"namespace", "filter"},
+	)
+
+	filterEvaluations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: FilterEvaluations,
+			Help: "The total number of pipeline filter evaluations, by namespace, filter and outcome",
+		},
+		[]string{"namespace", "filter", "outcome"},
+	)
+
+	mutatorLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    MutatorLatency,
+			Help:    "pipeline mutator execution latency distribution, by namespace and mutator",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"namespace", "mutator"},
+	)
+
+	mutatorEvaluations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: MutatorEvaluations,
+			Help: "The total number of pipeline mutator executions, by namespace, mutator and outcome",
+		},
+		[]string{"namespace", "mutator", "outcome"},
+	)
+
+	handlerLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    HandlerLatency,
+			Help:    "pipeline handler execution latency distribution, by namespace and handler",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"namespace", "handler"},
+	)
+
+	handlerEvaluations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: HandlerEvaluations,
+			Help: "The total number of pipeline handler executions, by namespace, handler and outcome",
+		},
+		[]string{"namespace", "handler", "outcome"},
+	)
+)
+
+func init() {
+	for name, collector := range map[string]prometheus.Collector{
+		FilterLatency:      filterLatency,
+		FilterEvaluations:  filterEvaluations,
+		MutatorLatency:     mutatorLatency,
+		MutatorEvaluations: mutatorEvaluations,
+		HandlerLatency:     handlerLatency,
+		HandlerEvaluations: handlerEvaluations,
+	} {
+		if err := prometheus.Register(collector); err != nil {
+			panic(fmt.Errorf("error registering %s: %s", name, err))
+		}
+	}
+}
+
+// eventNamespace returns the namespace of event, for the metric labels.
+func eventNamespace(event *corev2.Event) string {
+	if event == nil {
+		return ""
+	}
+	if event.Entity != nil {
+		return event.Entity.Namespace
+	}
+	if event.Check != nil {
+		return event.Check.Namespace
+	}
+	return ""
+}
+
+// observeFilter records an evaluation of the filter ref, that took seconds.
+func observeFilter(event *corev2.Event, ref *corev2.ResourceReference, filtered bool, err error, seconds float64) {
+	outcome := TraceResultAllowed
+	if err != nil {
+		outcome = TraceResultError
+	} else if filtered {
+		outcome = TraceResultFiltered
+	}
+	namespace := eventNamespace(event)
+	filterLatency.WithLabelValues(namespace, ref.Name).Observe(seconds)
+	filterEvaluations.WithLabelValues(namespace, ref.Name, outcome).Inc()
+}
+
+// observeMutator records an execution of the mutator ref, that took seconds.
+func observeMutator(event *corev2.Event, ref *corev2.ResourceReference, err error, seconds float64) {
+	outcome := TraceResultMutated
+	if err != nil {
+		outcome = TraceResultError
+	}
+	namespace := eventNamespace(event)
+	mutatorLatency.WithLabelValues(namespace, ref.Name).Observe(seconds)
+	mutatorEvaluations.WithLabelValues(namespace, ref.Name, outcome).Inc()
+}
+
+// observeHandler records an execution of the handler ref, that took seconds.
+func observeHandler(event *corev2.Event, ref *corev2.ResourceReference, err error, seconds float64) {
+	outcome := TraceResultHandled
+	if err != nil {
+		outcome = TraceResultError
+	}
+	namespace := eventNamespace(event)
+	handlerLatency.WithLabelValues(namespace, ref.Name).Observe(seconds)
+	handlerEvaluations.WithLabelValues(namespace, ref.Name, outcome).Inc()
+}
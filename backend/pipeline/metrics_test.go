@@ -0,0 +1,59 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline/filter"
+	"github.com/sensu/sensu-go/backend/pipeline/mutator"
+)
+
+func TestPipelineComponentMetrics(t *testing.T) {
+	a := &AdapterV1{
+		FilterAdapters:  []FilterAdapter{&filter.IsIncidentAdapter{}},
+		MutatorAdapters: []MutatorAdapter{&mutator.JSONAdapter{}},
+	}
+	event := corev2.FixtureEvent("entity", "check")
+	event.Entity.Namespace = "metrics"
+	event.Check.Namespace = "metrics"
+	event.Check.Status = 0
+
+	isIncident := &corev2.ResourceReference{APIVersion: "core/v2", Type: "EventFilter", Name: "is_incident"}
+	unknown := &corev2.ResourceReference{APIVersion: "core/v2", Type: "EventFilter", Name: "unknown"}
+	json := &corev2.ResourceReference{APIVersion: "core/v2", Type: "Mutator", Name: "json"}
+
+	if _, err := a.processFilter(context.Background(), isIncident, event); err != nil {
+		t.Fatal(err)
+	}
+	event.Check.Status = 2
+	if _, err := a.processFilter(context.Background(), isIncident, event); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.processFilter(context.Background(), unknown, event); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := a.processMutator(context.Background(), json, event); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"filtered", testutil.ToFloat64(filterEvaluations.WithLabelValues("metrics", "is_incident", TraceResultFiltered)), 1},
+		{"allowed", testutil.ToFloat64(filterEvaluations.WithLabelValues("metrics", "is_incident", TraceResultAllowed)), 1},
+		{"error", testutil.ToFloat64(filterEvaluations.WithLabelValues("metrics", "unknown", TraceResultError)), 1},
+		{"mutated", testutil.ToFloat64(mutatorEvaluations.WithLabelValues("metrics", "json", TraceResultMutated)), 1},
+	}
+	for _, c := range counts {
+		if c.got != c.want {
+			t.Errorf("%s evaluations: got %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if got := testutil.CollectAndCount(filterLatency); got < 2 {
+		t.Errorf("filter latency series: got %d, want at least 2", got)
+	}
+}
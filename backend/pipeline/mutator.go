@@ -45,15 +45,19 @@ func (a *AdapterV1) processMutator(ctx context.Context, ref *corev2.ResourceRefe
 			status = metricspkg.StatusLabelError
 		}
 		mutatorDuration.WithLabelValues(status, ref.ResourceID()).Observe(v * float64(1000))
+		observeMutator(event, ref, fErr, v)
 	}))
 	defer mutatorTimer.ObserveDuration()
 
 	mutator, err := a.getMutatorAdapterForResource(ctx, ref)
 	if err != nil {
+		tracerFromContext(ctx).record(ctx, TraceStepMutator, ref.ResourceID(), "", err, nil)
 		return nil, err
 	}
 
-	return mutator.Mutate(ctx, ref, event)
+	data, err = mutator.Mutate(ctx, ref, event)
+	tracerFromContext(ctx).record(ctx, TraceStepMutator, ref.ResourceID(), TraceResultMutated, err, data)
+	return data, err
 }
 
 func (a *AdapterV1) getMutatorAdapterForResource(ctx context.Context, ref *corev2.ResourceReference) (MutatorAdapter, error) {
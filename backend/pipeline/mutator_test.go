@@ -37,6 +37,7 @@ func TestAdapterV1_processMutator(t *testing.T) {
 		{
 			name: "returns an error when getMutatorAdapterForResource() returns an error",
 			args: args{
+				ctx: context.Background(),
 				ref: &corev2.ResourceReference{
 					APIVersion: "core/v2",
 					Type:       "Mutator",
@@ -58,6 +59,7 @@ func TestAdapterV1_processMutator(t *testing.T) {
 				}(),
 			},
 			args: args{
+				ctx: context.Background(),
 				ref: &corev2.ResourceReference{
 					APIVersion: "core/v2",
 					Type:       "Mutator",
@@ -80,6 +82,7 @@ func TestAdapterV1_processMutator(t *testing.T) {
 				}(),
 			},
 			args: args{
+				ctx: context.Background(),
 				ref: &corev2.ResourceReference{
 					APIVersion: "core/v2",
 					Type:       "Mutator",
@@ -0,0 +1,61 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// remediationLocked returns true if event must not be handled by workflow,
+// because the handler of the workflow already ran for the entity of the event
+// during the remediation cooldown set by the annotations of pipeline. Events
+// are handled when the cooldown is invalid or when the lock can't be
+// acquired because of an error. Otherwise, release releases the lock that
+// was acquired for event, if any, and must be called when the event could not
+// be handled, so that the remediation is attempted again by the next event.
+func (a *AdapterV1) remediationLocked(ctx context.Context, pipeline *corev2.Pipeline, workflow *corev2.PipelineWorkflow, event *corev2.Event) (locked bool, release func()) {
+	release = func() {}
+	if a.RemediationLocks == nil || workflow.Handler == nil {
+		return false, release
+	}
+	cooldown, err := store.RemediationCooldownFromPipeline(pipeline, workflow.Name)
+	if err != nil {
+		logger.WithError(err).WithField("pipeline", pipeline.Name).Warn("invalid remediation cooldown, not locking workflow")
+		return false, release
+	}
+	if cooldown == 0 {
+		return false, release
+	}
+
+	now := time.Now()
+	lock := &store.RemediationLock{
+		Namespace:  event.Entity.Namespace,
+		Handler:    workflow.Handler.Name,
+		Entity:     event.Entity.Name,
+		Event:      event.GetUUID().String(),
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(cooldown),
+	}
+	tctx, cancel := context.WithTimeout(ctx, a.StoreTimeout)
+	defer cancel()
+	acquired, err := a.RemediationLocks.AcquireRemediationLock(tctx, lock)
+	if err != nil {
+		logger.WithError(err).WithField("pipeline", pipeline.Name).Error("could not acquire remediation lock, not locking workflow")
+		return false, release
+	}
+	if !acquired {
+		tracerFromContext(ctx).record(ctx, TraceStepRemediationLock, workflow.Handler.ResourceID(), TraceResultLocked, nil, nil)
+		return true, release
+	}
+	return false, func() {
+		// The lock is released even if ctx is done, since the failure may be
+		// caused by the cancellation of ctx
+		rctx, cancel := context.WithTimeout(context.Background(), a.StoreTimeout)
+		defer cancel()
+		if err := a.RemediationLocks.ReleaseRemediationLock(rctx, lock.Namespace, lock.Handler, lock.Entity); err != nil {
+			logger.WithError(err).WithField("pipeline", pipeline.Name).Error("could not release remediation lock")
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline/mutator"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/mock"
+)
+
+type testRemediationLocks map[string]time.Time
+
+func (l testRemediationLocks) AcquireRemediationLock(ctx context.Context, lock *store.RemediationLock) (bool, error) {
+	key := lock.Namespace + "/" + lock.Handler + "/" + lock.Entity
+	if expiresAt, ok := l[key]; ok && expiresAt.After(lock.AcquiredAt) {
+		return false, nil
+	}
+	l[key] = lock.ExpiresAt
+	return true, nil
+}
+
+func (l testRemediationLocks) ListRemediationLocks(ctx context.Context, namespace string) ([]*store.RemediationLock, error) {
+	return nil, nil
+}
+
+func (l testRemediationLocks) ReleaseRemediationLock(ctx context.Context, namespace, handler, entity string) error {
+	delete(l, namespace+"/"+handler+"/"+entity)
+	return nil
+}
+
+func TestAdapterV1_RunRemediationLock(t *testing.T) {
+	pipeline := &corev2.Pipeline{
+		ObjectMeta: corev2.NewObjectMeta("pipeline1", "default"),
+		Workflows: []*corev2.PipelineWorkflow{
+			{
+				Name:    "workflow1",
+				Handler: &corev2.ResourceReference{APIVersion: "core/v2", Type: "Handler", Name: "restart-service"},
+			},
+		},
+	}
+	pipeline.Annotations = map[string]string{
+		fmt.Sprintf(store.RemediationCooldownAnnotation, "workflow1"): "30m",
+	}
+	stor := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*corev2.Pipeline]{Value: pipeline}, nil)
+
+	handler := &countingHandlerAdapter{}
+	a := &AdapterV1{
+		Store:            stor,
+		StoreTimeout:     time.Second,
+		MutatorAdapters:  []MutatorAdapter{&mutator.JSONAdapter{}},
+		HandlerAdapters:  []HandlerAdapter{handler},
+		RemediationLocks: testRemediationLocks{},
+	}
+	ref := &corev2.ResourceReference{APIVersion: "core/v2", Type: "Pipeline", Name: "pipeline1"}
+
+	for _, entity := range []string{"foo", "foo", "bar"} {
+		if err := a.Run(context.Background(), ref, corev2.FixtureEvent(entity, "check")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if handler.handled != 2 {
+		t.Errorf("got %d handled events, want 2", handler.handled)
+	}
+}
+
+func TestAdapterV1_RunRemediationLockReleasedOnError(t *testing.T) {
+	pipeline := &corev2.Pipeline{
+		ObjectMeta: corev2.NewObjectMeta("pipeline1", "default"),
+		Workflows: []*corev2.PipelineWorkflow{
+			{
+				Name:    "workflow1",
+				Handler: &corev2.ResourceReference{APIVersion: "core/v2", Type: "Handler", Name: "restart-service"},
+			},
+		},
+	}
+	pipeline.Annotations = map[string]string{
+		fmt.Sprintf(store.RemediationCooldownAnnotation, "workflow1"): "30m",
+	}
+	stor := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*corev2.Pipeline]{Value: pipeline}, nil)
+
+	handler := &countingHandlerAdapter{err: errors.New("service unreachable")}
+	locks := testRemediationLocks{}
+	a := &AdapterV1{
+		Store:            stor,
+		StoreTimeout:     time.Second,
+		MutatorAdapters:  []MutatorAdapter{&mutator.JSONAdapter{}},
+		HandlerAdapters:  []HandlerAdapter{handler},
+		RemediationLocks: locks,
+	}
+	ref := &corev2.ResourceReference{APIVersion: "core/v2", Type: "Pipeline", Name: "pipeline1"}
+
+	// The remediation is attempted again when the handler fails
+	for i := 0; i < 2; i++ {
+		if err := a.Run(context.Background(), ref, corev2.FixtureEvent("foo", "check")); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if handler.handled != 2 {
+		t.Errorf("got %d handled events, want 2", handler.handled)
+	}
+	if len(locks) != 0 {
+		t.Errorf("got %d held locks, want 0", len(locks))
+	}
+}
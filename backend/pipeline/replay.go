@@ -0,0 +1,66 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev2 "github.com/sensu/core/v2"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// ReplayHandler sends event through the handler named name, and through every
+// handler of the set if it is a handler set. The mutator of each handler is
+// applied to the event, but its filters are not evaluated: the events to
+// replay are chosen by the caller. The handler is looked up in the namespace
+// of the entity of the event.
+func (a *AdapterV1) ReplayHandler(ctx context.Context, name string, event *corev2.Event) error {
+	namespace := event.Entity.Namespace
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, namespace)
+
+	// Make sure that the handler exists, missing handlers are otherwise
+	// ignored when handler sets are expanded
+	hstore := storev2.Of[*corev2.Handler](a.Store)
+	tctx, cancel := context.WithTimeout(ctx, a.StoreTimeout)
+	_, err := hstore.Get(tctx, storev2.ID{Namespace: namespace, Name: name})
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	handlers, err := a.expandHandlers(ctx, namespace, []string{name}, 1)
+	if err != nil {
+		return err
+	}
+
+	handlerNames := make([]string, 0, len(handlers))
+	for handlerName := range handlers {
+		handlerNames = append(handlerNames, handlerName)
+	}
+	sort.Strings(handlerNames)
+
+	for _, handlerName := range handlerNames {
+		workflowName := fmt.Sprintf(LegacyPipelineWorkflowName, handlerName)
+		workflow := corev2.PipelineWorkflowFromHandler(ctx, workflowName, handlers[handlerName])
+		if workflow.Mutator == nil {
+			workflow.Mutator = &corev2.ResourceReference{
+				APIVersion: "core/v2",
+				Type:       "Mutator",
+				Name:       "json",
+			}
+		}
+
+		mutatedData, err := a.processMutator(ctx, workflow.Mutator, event)
+		if err != nil {
+			return err
+		}
+		handlerRequestsTotalCounter.Inc()
+		err = a.processHandler(ctx, workflow.Handler, event, mutatedData)
+		incrementCounter(workflow.Handler, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
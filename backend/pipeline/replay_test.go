@@ -0,0 +1,66 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/sensu/sensu-go/backend/pipeline/mutator"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/testing/mockstore"
+)
+
+type recordingHandlerAdapter struct {
+	handled []string
+}
+
+func (r *recordingHandlerAdapter) Name() string {
+	return "recording"
+}
+
+func (r *recordingHandlerAdapter) CanHandle(*corev2.ResourceReference) bool {
+	return true
+}
+
+func (r *recordingHandlerAdapter) Handle(_ context.Context, ref *corev2.ResourceReference, _ *corev2.Event, _ []byte) error {
+	r.handled = append(r.handled, ref.Name)
+	return nil
+}
+
+func TestAdapterV1_ReplayHandler(t *testing.T) {
+	set := &corev2.Handler{
+		ObjectMeta: corev2.NewObjectMeta("set", "default"),
+		Type:       corev2.HandlerSetType,
+		Handlers:   []string{"slack", "pagerduty"},
+	}
+	stor := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	for _, h := range []*corev2.Handler{set, corev2.FixtureHandler("slack"), corev2.FixtureHandler("pagerduty")} {
+		name := h.Name
+		cs.On("Get", mock.Anything, mock.MatchedBy(func(req storev2.ResourceRequest) bool {
+			return req.Name == name
+		})).Return(mockstore.Wrapper[*corev2.Handler]{Value: h}, nil)
+	}
+	cs.On("Get", mock.Anything, mock.Anything).Return(nil, &store.ErrNotFound{})
+
+	handlers := &recordingHandlerAdapter{}
+	a := &AdapterV1{
+		Store:           stor,
+		StoreTimeout:    time.Second,
+		MutatorAdapters: []MutatorAdapter{&mutator.JSONAdapter{}},
+		HandlerAdapters: []HandlerAdapter{handlers},
+	}
+
+	err := a.ReplayHandler(context.Background(), "set", corev2.FixtureEvent("entity1", "check1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pagerduty", "slack"}, handlers.handled)
+
+	err = a.ReplayHandler(context.Background(), "missing", corev2.FixtureEvent("entity1", "check1"))
+	assert.Error(t, err)
+}
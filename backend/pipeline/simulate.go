@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+// ErrPipeMutatorTestsDisabled is returned when a pipe mutator is tested while
+// AllowPipeMutatorTests is false.
+type ErrPipeMutatorTestsDisabled struct {
+	Name string
+}
+
+func (e *ErrPipeMutatorTestsDisabled) Error() string {
+	return fmt.Sprintf("mutator %s is a pipe mutator, which runs a command on the backend, and pipe mutator tests are disabled", e.Name)
+}
+
+// SimulateFilter evaluates the event filter named name against event, without
+// running the rest of a pipeline. It returns true if the event is filtered.
+// The filter is looked up in the namespace of the entity of the event.
+func (a *AdapterV1) SimulateFilter(ctx context.Context, name string, event *corev2.Event) (bool, error) {
+	ref := &corev2.ResourceReference{
+		APIVersion: "core/v2",
+		Type:       "EventFilter",
+		Name:       name,
+	}
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, event.Entity.Namespace)
+	filter, err := a.getFilterAdapterForResource(ctx, ref)
+	if err != nil {
+		return false, err
+	}
+	return filter.Filter(ctx, ref, event)
+}
+
+// SimulateMutator mutates event with the mutator named name, without running
+// the rest of a pipeline, and returns the mutated event. The mutator is looked
+// up in the namespace of the entity of the event. Pipe mutators execute their
+// command on the backend, and are refused unless AllowPipeMutatorTests is true.
+func (a *AdapterV1) SimulateMutator(ctx context.Context, name string, event *corev2.Event) ([]byte, error) {
+	ref := &corev2.ResourceReference{
+		APIVersion: "core/v2",
+		Type:       "Mutator",
+		Name:       name,
+	}
+	ctx = context.WithValue(ctx, corev2.NamespaceKey, event.Entity.Namespace)
+	if !a.AllowPipeMutatorTests {
+		if err := a.checkPipeMutator(ctx, name, event.Entity.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	mutator, err := a.getMutatorAdapterForResource(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return mutator.Mutate(ctx, ref, event)
+}
+
+// checkPipeMutator returns an ErrPipeMutatorTestsDisabled error if the mutator
+// named name is a pipe mutator. Built-in mutators are not stored, and are not
+// pipe mutators.
+func (a *AdapterV1) checkPipeMutator(ctx context.Context, name, namespace string) error {
+	tctx, cancel := context.WithTimeout(ctx, a.StoreTimeout)
+	defer cancel()
+	mutator, err := storev2.Of[*corev2.Mutator](a.Store).Get(tctx, storev2.ID{Namespace: namespace, Name: name})
+	if err != nil {
+		var notFound *store.ErrNotFound
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return err
+	}
+	if mutator.Type == "" || mutator.Type == corev2.PipeMutator {
+		return &ErrPipeMutatorTestsDisabled{Name: name}
+	}
+	return nil
+}
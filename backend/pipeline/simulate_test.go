@@ -0,0 +1,32 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSimulateMutatorRefusesPipeMutators(t *testing.T) {
+	mutator := corev2.FixtureMutator("mutator1")
+	mutator.Type = corev2.PipeMutator
+	stor := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*corev2.Mutator]{Value: mutator}, nil)
+
+	a := &AdapterV1{Store: stor, StoreTimeout: time.Second}
+	event := corev2.FixtureEvent("entity1", "check1")
+	_, err := a.SimulateMutator(context.Background(), "mutator1", event)
+	var disabled *ErrPipeMutatorTestsDisabled
+	if !errors.As(err, &disabled) {
+		t.Fatalf("expected ErrPipeMutatorTestsDisabled, got %v", err)
+	}
+	if got, want := disabled.Name, "mutator1"; got != want {
+		t.Errorf("bad mutator name: got %q, want %q", got, want)
+	}
+}
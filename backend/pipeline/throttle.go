@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// throttleWorkflow returns true if event must not be handled by the workflow
+// named workflow, according to the throttling set by the annotations of
+// pipeline. Events are handled when the throttling is invalid or when the
+// notifications can't be counted, so that alerts are not lost.
+func (a *AdapterV1) throttleWorkflow(ctx context.Context, pipeline *corev2.Pipeline, workflow string, event *corev2.Event) bool {
+	throttle, err := store.WorkflowThrottleFromPipeline(pipeline, workflow)
+	if err != nil {
+		logger.WithError(err).WithField("pipeline", pipeline.Name).Warn("invalid workflow throttling, not throttling events")
+		return false
+	}
+
+	if !throttle.AllowsOccurrence(event) {
+		tracerFromContext(ctx).record(ctx, TraceStepThrottle, "alert_every", TraceResultThrottled, nil, nil)
+		return true
+	}
+
+	if throttle.MaxNotificationsPerHour == 0 || a.NotificationCounters == nil || !event.HasCheck() {
+		return false
+	}
+	key := store.NotificationKey{
+		Namespace: event.Entity.Namespace,
+		Pipeline:  pipeline.Name,
+		Workflow:  workflow,
+		Entity:    event.Entity.Name,
+		Check:     event.Check.Name,
+	}
+	tctx, cancel := context.WithTimeout(ctx, a.StoreTimeout)
+	defer cancel()
+	allowed, err := a.NotificationCounters.IncrementNotifications(tctx, key, time.Now(), throttle.MaxNotificationsPerHour)
+	if err != nil {
+		logger.WithError(err).WithField("pipeline", pipeline.Name).Error("could not count workflow notification, not throttling event")
+		return false
+	}
+	if !allowed {
+		tracerFromContext(ctx).record(ctx, TraceStepThrottle, "max_notifications_per_hour", TraceResultThrottled, nil, nil)
+		return true
+	}
+	return false
+}
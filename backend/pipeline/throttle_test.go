@@ -0,0 +1,133 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline/mutator"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/mock"
+)
+
+type testNotificationCounters struct {
+	counts map[store.NotificationKey]int64
+	err    error
+}
+
+func (c *testNotificationCounters) IncrementNotifications(ctx context.Context, key store.NotificationKey, now time.Time, max int64) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	if c.counts[key] >= max {
+		return false, nil
+	}
+	c.counts[key]++
+	return true, nil
+}
+
+type countingHandlerAdapter struct {
+	handled int
+	err     error
+}
+
+func (*countingHandlerAdapter) Name() string                             { return "counting" }
+func (*countingHandlerAdapter) CanHandle(*corev2.ResourceReference) bool { return true }
+func (h *countingHandlerAdapter) Handle(context.Context, *corev2.ResourceReference, *corev2.Event, []byte) error {
+	h.handled++
+	return h.err
+}
+
+func TestAdapterV1_RunThrottle(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		countersErr error
+		occurrences []int64
+		wantHandled int
+	}{
+		{
+			name:        "no throttling",
+			occurrences: []int64{1, 2, 3, 4},
+			wantHandled: 4,
+		},
+		{
+			name:        "alert every",
+			annotations: map[string]string{fmt.Sprintf(store.AlertEveryAnnotation, "workflow1"): "2"},
+			occurrences: []int64{1, 2, 3, 4, 5},
+			wantHandled: 3,
+		},
+		{
+			name:        "max notifications per hour",
+			annotations: map[string]string{fmt.Sprintf(store.MaxNotificationsPerHourAnnotation, "workflow1"): "2"},
+			occurrences: []int64{1, 2, 3, 4},
+			wantHandled: 2,
+		},
+		{
+			name:        "throttling of another workflow",
+			annotations: map[string]string{fmt.Sprintf(store.AlertEveryAnnotation, "workflow2"): "2"},
+			occurrences: []int64{1, 2, 3},
+			wantHandled: 3,
+		},
+		{
+			name:        "invalid throttling",
+			annotations: map[string]string{fmt.Sprintf(store.AlertEveryAnnotation, "workflow1"): "often"},
+			occurrences: []int64{1, 2, 3},
+			wantHandled: 3,
+		},
+		{
+			name:        "counters error",
+			annotations: map[string]string{fmt.Sprintf(store.MaxNotificationsPerHourAnnotation, "workflow1"): "1"},
+			countersErr: errors.New("unavailable"),
+			occurrences: []int64{1, 2, 3},
+			wantHandled: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := &corev2.Pipeline{
+				ObjectMeta: corev2.NewObjectMeta("pipeline1", "default"),
+				Workflows: []*corev2.PipelineWorkflow{
+					{
+						Name:    "workflow1",
+						Handler: &corev2.ResourceReference{APIVersion: "core/v2", Type: "Handler", Name: "handler1"},
+					},
+				},
+			}
+			pipeline.Annotations = tt.annotations
+			stor := &mockstore.V2MockStore{}
+			cs := new(mockstore.ConfigStore)
+			stor.On("GetConfigStore").Return(cs)
+			cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*corev2.Pipeline]{Value: pipeline}, nil)
+
+			handler := &countingHandlerAdapter{}
+			a := &AdapterV1{
+				Store:           stor,
+				StoreTimeout:    time.Second,
+				MutatorAdapters: []MutatorAdapter{&mutator.JSONAdapter{}},
+				HandlerAdapters: []HandlerAdapter{handler},
+				NotificationCounters: &testNotificationCounters{
+					counts: make(map[store.NotificationKey]int64),
+					err:    tt.countersErr,
+				},
+			}
+			ref := &corev2.ResourceReference{APIVersion: "core/v2", Type: "Pipeline", Name: "pipeline1"}
+
+			for _, occurrences := range tt.occurrences {
+				event := corev2.FixtureEvent("foo", "bar")
+				event.Check.Status = 2
+				event.Check.Occurrences = occurrences
+				if err := a.Run(context.Background(), ref, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if handler.handled != tt.wantHandled {
+				t.Errorf("got %d handled events, want %d", handler.handled, tt.wantHandled)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package pipeline
+
+import (
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+const (
+	// EventTimestampAnnotation is the annotation of the pipelines, or of the
+	// checks for the pipelines of their handlers, that sets which time the
+	// timestamp of the events processed by their filters, mutators and
+	// handlers is: EventTimestampAgent or EventTimestampReceived.
+	EventTimestampAnnotation = "sensu.io/event-timestamp"
+
+	// EventTimestampAgent is the time at which the event was created by its
+	// agent. It is the default.
+	EventTimestampAgent = "agent"
+
+	// EventTimestampReceived is the time at which the event was received by
+	// the backend, as annotated by store.EventReceivedAnnotation.
+	EventTimestampReceived = "received"
+)
+
+// withEventTimestamp returns event, or a copy of event whose timestamp is the
+// time at which it was received by the backend, if pipeline or the check of
+// event asks for it.
+func withEventTimestamp(pipeline *corev2.Pipeline, event *corev2.Event) *corev2.Event {
+	timestamp := pipeline.Annotations[EventTimestampAnnotation]
+	if timestamp == "" && event.HasCheck() {
+		timestamp = event.Check.Annotations[EventTimestampAnnotation]
+	}
+	if timestamp != EventTimestampReceived {
+		return event
+	}
+	received, ok := store.EventReceived(event)
+	if !ok {
+		return event
+	}
+	copied := *event
+	copied.Timestamp = received
+	return &copied
+}
@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+func TestWithEventTimestamp(t *testing.T) {
+	newEvent := func() *corev2.Event {
+		event := corev2.FixtureEvent("entity", "check")
+		event.Timestamp = 42
+		store.SetEventReceived(event, time.Unix(84, 0))
+		return event
+	}
+	received := &corev2.Pipeline{ObjectMeta: corev2.ObjectMeta{
+		Annotations: map[string]string{EventTimestampAnnotation: EventTimestampReceived},
+	}}
+	agent := &corev2.Pipeline{ObjectMeta: corev2.ObjectMeta{
+		Annotations: map[string]string{EventTimestampAnnotation: EventTimestampAgent},
+	}}
+	legacy := &corev2.Pipeline{}
+
+	tests := []struct {
+		name     string
+		pipeline *corev2.Pipeline
+		event    func() *corev2.Event
+		want     int64
+	}{
+		{
+			name:     "agent timestamp by default",
+			pipeline: legacy,
+			event:    newEvent,
+			want:     42,
+		},
+		{
+			name:     "agent timestamp",
+			pipeline: agent,
+			event:    newEvent,
+			want:     42,
+		},
+		{
+			name:     "received timestamp",
+			pipeline: received,
+			event:    newEvent,
+			want:     84,
+		},
+		{
+			name:     "received timestamp asked by the check",
+			pipeline: legacy,
+			event: func() *corev2.Event {
+				event := newEvent()
+				event.Check.Annotations = map[string]string{EventTimestampAnnotation: EventTimestampReceived}
+				return event
+			},
+			want: 84,
+		},
+		{
+			name:     "event not annotated with its received timestamp",
+			pipeline: received,
+			event: func() *corev2.Event {
+				event := newEvent()
+				delete(event.Annotations, store.EventReceivedAnnotation)
+				return event
+			},
+			want: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := tt.event()
+			got := withEventTimestamp(tt.pipeline, event)
+			if got.Timestamp != tt.want {
+				t.Errorf("got timestamp %d, want %d", got.Timestamp, tt.want)
+			}
+			if event.Timestamp != 42 {
+				t.Error("the original event was modified")
+			}
+		})
+	}
+}
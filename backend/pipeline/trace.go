@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+const (
+	// traceComponent is the component of the steps recorded by pipelines.
+	traceComponent = "pipelined"
+
+	// maxTraceOutput is the maximum size of the output recorded for a step.
+	maxTraceOutput = 4096
+
+	TraceStepPipeline        = "pipeline"
+	TraceStepFilter          = "filter"
+	TraceStepMutator         = "mutator"
+	TraceStepHandler         = "handler"
+	TraceStepThrottle        = "throttle"
+	TraceStepRemediationLock = "remediation_lock"
+
+	TraceResultAllowed   = "allowed"
+	TraceResultFiltered  = "filtered"
+	TraceResultMutated   = "mutated"
+	TraceResultHandled   = "handled"
+	TraceResultThrottled = "throttled"
+	TraceResultLocked    = "locked"
+	TraceResultStubbed   = "stubbed"
+	TraceResultError     = "error"
+)
+
+type tracerKey struct{}
+
+// tracer records the steps of a traced event through a pipeline. A nil
+// *tracer records nothing, so that untraced events don't pay for tracing.
+type tracer struct {
+	mu    sync.Mutex
+	trace *store.EventTrace
+}
+
+// newTracer returns a tracer for event, or nil if the event is not traced.
+func newTracer(event *corev2.Event) *tracer {
+	if !store.IsEventTraced(event) {
+		return nil
+	}
+	return &tracer{trace: store.NewEventTrace(event)}
+}
+
+func withTracer(ctx context.Context, t *tracer) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+func tracerFromContext(ctx context.Context) *tracer {
+	t, _ := ctx.Value(tracerKey{}).(*tracer)
+	return t
+}
+
+// record records a step of the pipeline and workflow of ctx.
+func (t *tracer) record(ctx context.Context, step, resource, result string, err error, output []byte) {
+	if t == nil {
+		return
+	}
+	s := store.EventTraceStep{
+		Time:      time.Now(),
+		Component: traceComponent,
+		Step:      step,
+		Resource:  resource,
+		Result:    result,
+	}
+	s.Pipeline, _ = ctx.Value(corev2.PipelineKey).(string)
+	s.Workflow, _ = ctx.Value(corev2.PipelineWorkflowKey).(string)
+	if err != nil {
+		s.Result = TraceResultError
+		s.Error = err.Error()
+	}
+	if len(output) > maxTraceOutput {
+		output = output[:maxTraceOutput]
+	}
+	s.Output = string(output)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.trace.Steps = append(t.trace.Steps, s)
+}
+
+// save appends the recorded steps to the stored trace of the event.
+func (t *tracer) save(ctx context.Context, traces store.EventTraceStore) {
+	if t == nil || traces == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.trace.Steps) == 0 {
+		return
+	}
+	if err := traces.AppendEventTrace(ctx, t.trace); err != nil {
+		logger.WithError(err).Error("error storing event trace")
+	}
+}
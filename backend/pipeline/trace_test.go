@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline/mutator"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/mock"
+)
+
+type testTraceStore struct {
+	traces []*store.EventTrace
+}
+
+func (s *testTraceStore) AppendEventTrace(ctx context.Context, trace *store.EventTrace) error {
+	s.traces = append(s.traces, trace)
+	return nil
+}
+
+func (s *testTraceStore) GetEventTrace(ctx context.Context, namespace, entity, check string) (*store.EventTrace, error) {
+	return nil, &store.ErrNotFound{}
+}
+
+type allowFilterAdapter struct{}
+
+func (allowFilterAdapter) Name() string                             { return "allow" }
+func (allowFilterAdapter) CanFilter(*corev2.ResourceReference) bool { return true }
+func (allowFilterAdapter) Filter(context.Context, *corev2.ResourceReference, *corev2.Event) (bool, error) {
+	return false, nil
+}
+
+type okHandlerAdapter struct{}
+
+func (okHandlerAdapter) Name() string                             { return "ok" }
+func (okHandlerAdapter) CanHandle(*corev2.ResourceReference) bool { return true }
+func (okHandlerAdapter) Handle(context.Context, *corev2.ResourceReference, *corev2.Event, []byte) error {
+	return nil
+}
+
+func TestAdapterV1_RunTrace(t *testing.T) {
+	pipeline := &corev2.Pipeline{
+		ObjectMeta: corev2.NewObjectMeta("pipeline1", "default"),
+		Workflows: []*corev2.PipelineWorkflow{
+			{
+				Name: "workflow1",
+				Filters: []*corev2.ResourceReference{
+					{APIVersion: "core/v2", Type: "EventFilter", Name: "filter1"},
+				},
+				Handler: &corev2.ResourceReference{APIVersion: "core/v2", Type: "Handler", Name: "handler1"},
+			},
+		},
+	}
+	stor := &mockstore.V2MockStore{}
+	cs := new(mockstore.ConfigStore)
+	stor.On("GetConfigStore").Return(cs)
+	cs.On("Get", mock.Anything, mock.Anything).Return(mockstore.Wrapper[*corev2.Pipeline]{Value: pipeline}, nil)
+
+	traces := &testTraceStore{}
+	a := &AdapterV1{
+		Store:           stor,
+		FilterAdapters:  []FilterAdapter{allowFilterAdapter{}},
+		MutatorAdapters: []MutatorAdapter{&mutator.JSONAdapter{}},
+		HandlerAdapters: []HandlerAdapter{okHandlerAdapter{}},
+		TraceStore:      traces,
+	}
+	ref := &corev2.ResourceReference{APIVersion: "core/v2", Type: "Pipeline", Name: "pipeline1"}
+
+	// events are not traced by default
+	if err := a.Run(context.Background(), ref, corev2.FixtureEvent("foo", "bar")); err != nil {
+		t.Fatal(err)
+	}
+	if len(traces.traces) != 0 {
+		t.Fatalf("expected no traces, got %d", len(traces.traces))
+	}
+
+	event := corev2.FixtureEvent("foo", "bar")
+	event.Check.Annotations = map[string]string{store.EventTraceAnnotation: "true"}
+	if err := a.Run(context.Background(), ref, event); err != nil {
+		t.Fatal(err)
+	}
+	if len(traces.traces) != 1 {
+		t.Fatalf("expected 1 trace, got %d", len(traces.traces))
+	}
+	trace := traces.traces[0]
+	if trace.Entity != "foo" || trace.Check != "bar" {
+		t.Errorf("bad trace: %v", trace)
+	}
+	want := []struct{ step, result string }{
+		{TraceStepFilter, TraceResultAllowed},
+		{TraceStepMutator, TraceResultMutated},
+		{TraceStepHandler, TraceResultHandled},
+	}
+	if len(trace.Steps) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(trace.Steps), len(want))
+	}
+	for i, step := range trace.Steps {
+		if step.Step != want[i].step || step.Result != want[i].result {
+			t.Errorf("step %d: got %s/%s, want %s/%s", i, step.Step, step.Result, want[i].step, want[i].result)
+		}
+		if step.Pipeline != "pipeline1" || step.Workflow != "workflow1" {
+			t.Errorf("step %d: bad pipeline and workflow: %s/%s", i, step.Pipeline, step.Workflow)
+		}
+	}
+	if trace.Steps[1].Output == "" {
+		t.Error("expected the mutator output to be recorded")
+	}
+}
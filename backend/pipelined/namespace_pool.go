@@ -0,0 +1,193 @@
+package pipelined
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+)
+
+const (
+	namespaceQueueDepthName     = "sensu_go_pipelined_namespace_queue_depth"
+	namespaceSaturationName     = "sensu_go_pipelined_namespace_saturation"
+	namespaceDroppedName        = "sensu_go_pipelined_namespace_events_dropped"
+	namespaceBudgetExceededName = "sensu_go_pipelined_namespace_budget_exceeded"
+)
+
+var (
+	namespaceQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: namespaceQueueDepthName,
+			Help: "Number of events waiting for a pipelined worker of the namespace",
+		},
+		[]string{"namespace"},
+	)
+
+	namespaceSaturation = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: namespaceSaturationName,
+			Help: "Ratio of busy pipelined workers of the namespace",
+		},
+		[]string{"namespace"},
+	)
+
+	namespaceDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: namespaceDroppedName,
+			Help: "Number of events of the namespace dropped because its pipelined queue was full",
+		},
+		[]string{"namespace"},
+	)
+
+	namespaceBudgetExceeded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: namespaceBudgetExceededName,
+			Help: "Number of events of the namespace whose handling exceeded the pipelined time budget",
+		},
+		[]string{"namespace"},
+	)
+)
+
+func init() {
+	_ = prometheus.Register(namespaceQueueDepth)
+	_ = prometheus.Register(namespaceSaturation)
+	_ = prometheus.Register(namespaceDropped)
+	_ = prometheus.Register(namespaceBudgetExceeded)
+}
+
+// namespacePools isolates the handling of the events of each namespace in a
+// pool of workers of its own, so that the slow pipelines of a namespace don't
+// hold up the events of other namespaces.
+type namespacePools struct {
+	workers    int
+	bufferSize int
+	budget     time.Duration
+	ctx        context.Context
+	handle     func(context.Context, interface{}) error
+	stopping   chan struct{}
+	wg         *sync.WaitGroup
+
+	// ordered gives each worker a queue of its own, to which the events are
+	// dispatched by entity and check, so that the events of a check are
+	// handled in order.
+	ordered bool
+
+	mu    sync.Mutex
+	pools map[string]*namespacePool
+}
+
+// namespacePool is the queues and workers of a namespace. The workers share
+// a single queue, unless the pools are ordered.
+type namespacePool struct {
+	namespace string
+	queues    []chan interface{}
+	mu        sync.Mutex
+	busy      int
+}
+
+// dispatch queues msg in the pool of its namespace. The message is dropped
+// if the queue of the namespace is full.
+func (n *namespacePools) dispatch(msg interface{}) {
+	pool := n.pool(messageNamespace(msg))
+	queue := pool.queues[0]
+	if len(pool.queues) > 1 {
+		queue = pool.queues[messaging.Shard(messaging.OrderingKey(msg), len(pool.queues))]
+	}
+	select {
+	case queue <- msg:
+		namespaceQueueDepth.WithLabelValues(pool.namespace).Set(float64(pool.depth()))
+	case <-n.stopping:
+	default:
+		namespaceDropped.WithLabelValues(pool.namespace).Inc()
+		logger.WithField("namespace", pool.namespace).Warn("pipelined queue of namespace is full, dropping event")
+	}
+}
+
+// pool returns the pool of namespace, starting its workers if needed.
+func (n *namespacePools) pool(namespace string) *namespacePool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if pool, ok := n.pools[namespace]; ok {
+		return pool
+	}
+	pool := &namespacePool{namespace: namespace}
+	if n.ordered && n.workers > 1 {
+		bufferSize := n.bufferSize / n.workers
+		if bufferSize < 1 {
+			bufferSize = 1
+		}
+		for i := 0; i < n.workers; i++ {
+			pool.queues = append(pool.queues, make(chan interface{}, bufferSize))
+		}
+	} else {
+		pool.queues = []chan interface{}{make(chan interface{}, n.bufferSize)}
+	}
+	n.pools[namespace] = pool
+	for i := 0; i < n.workers; i++ {
+		n.wg.Add(1)
+		go n.work(pool, pool.queues[i%len(pool.queues)])
+	}
+	return pool
+}
+
+func (n *namespacePools) work(pool *namespacePool, queue chan interface{}) {
+	defer n.wg.Done()
+	for {
+		select {
+		case <-n.stopping:
+			return
+		case msg := <-queue:
+			namespaceQueueDepth.WithLabelValues(pool.namespace).Set(float64(pool.depth()))
+			pool.setBusy(1, n.workers)
+			err := n.run(pool, msg)
+			pool.setBusy(-1, n.workers)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// run handles msg within the time budget, if any. It only returns the
+// errors that must stop pipelined.
+func (n *namespacePools) run(pool *namespacePool, msg interface{}) error {
+	ctx := n.ctx
+	if n.budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.budget)
+		defer cancel()
+	}
+	err := n.handle(ctx, msg)
+	if ctx.Err() == context.DeadlineExceeded {
+		namespaceBudgetExceeded.WithLabelValues(pool.namespace).Inc()
+		logger.WithField("namespace", pool.namespace).Warn("event handling exceeded the pipelined time budget")
+	}
+	return err
+}
+
+// depth returns the number of events waiting in the queues of the pool.
+func (p *namespacePool) depth() int {
+	var depth int
+	for _, queue := range p.queues {
+		depth += len(queue)
+	}
+	return depth
+}
+
+func (p *namespacePool) setBusy(delta, workers int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.busy += delta
+	namespaceSaturation.WithLabelValues(p.namespace).Set(float64(p.busy) / float64(workers))
+}
+
+// messageNamespace returns the namespace of a pipelined message.
+func messageNamespace(msg interface{}) string {
+	if resource, ok := msg.(interface{ GetObjectMeta() corev2.ObjectMeta }); ok {
+		return resource.GetObjectMeta().Namespace
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package pipelined
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingAdapter blocks the events of the namespace slow until their
+// context is done or they are released, and reports the namespaces of the
+// events it runs.
+type blockingAdapter struct {
+	handled chan string
+	release chan struct{}
+}
+
+func (a *blockingAdapter) Name() string {
+	return "blocking"
+}
+
+func (a *blockingAdapter) CanRun(*corev2.ResourceReference) bool {
+	return true
+}
+
+func (a *blockingAdapter) Run(ctx context.Context, ref *corev2.ResourceReference, msg interface{}) error {
+	namespace := messageNamespace(msg)
+	if namespace == "slow" {
+		select {
+		case <-ctx.Done():
+		case <-a.release:
+		}
+	}
+	a.handled <- namespace
+	return nil
+}
+
+func namespaceEvent(namespace string) *corev2.Event {
+	event := corev2.FixtureEvent("entity1", "check1")
+	event.Namespace = namespace
+	event.Pipelines = []*corev2.ResourceReference{{APIVersion: "core/v2", Type: "Pipeline", Name: "pipeline1"}}
+	return event
+}
+
+func newNamespacePipelined(t *testing.T, budget time.Duration) (*Pipelined, messaging.MessageBus, *blockingAdapter) {
+	t.Helper()
+	bus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+	require.NoError(t, err)
+	require.NoError(t, bus.Start())
+
+	p, err := New(Config{Bus: bus, BufferSize: 10, WorkerCount: 1, NamespaceWorkers: 1, NamespaceBudget: budget})
+	require.NoError(t, err)
+	adapter := &blockingAdapter{handled: make(chan string, 10), release: make(chan struct{})}
+	p.AddAdapter(adapter)
+	require.NoError(t, p.Start())
+	return p, bus, adapter
+}
+
+func TestPipelinedNamespaceIsolation(t *testing.T) {
+	p, bus, adapter := newNamespacePipelined(t, time.Minute)
+
+	// The worker of the namespace slow is blocked, but the events of the
+	// namespace fast are still handled
+	require.NoError(t, bus.Publish(messaging.TopicEvent, namespaceEvent("slow")))
+	require.NoError(t, bus.Publish(messaging.TopicEvent, namespaceEvent("fast")))
+	require.NoError(t, bus.Publish(messaging.TopicEvent, namespaceEvent("fast")))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case namespace := <-adapter.handled:
+			assert.Equal(t, "fast", namespace)
+		case <-time.After(5 * time.Second):
+			t.Fatal("events of namespace fast were not handled")
+		}
+	}
+
+	close(adapter.release)
+	assert.Equal(t, "slow", <-adapter.handled)
+	assert.NoError(t, p.Stop())
+}
+
+func TestPipelinedNamespaceBudget(t *testing.T) {
+	p, bus, adapter := newNamespacePipelined(t, 10*time.Millisecond)
+
+	require.NoError(t, bus.Publish(messaging.TopicEvent, namespaceEvent("slow")))
+	select {
+	case namespace := <-adapter.handled:
+		assert.Equal(t, "slow", namespace)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event handling was not stopped by the budget")
+	}
+	assert.NoError(t, p.Stop())
+}
@@ -0,0 +1,68 @@
+package pipelined
+
+import (
+	"github.com/sensu/sensu-go/backend/messaging"
+)
+
+// createOrderedWorkers creates count goroutines that each handle the events
+// of the entities and checks of their shard, so that the events of a check
+// are handled in the order in which they are received. A single goroutine
+// pulls the events from channel, as several would race to dispatch them.
+func (p *Pipelined) createOrderedWorkers(count int, channel chan interface{}) {
+	if p.namespaces != nil {
+		// The namespace pools shard their own workers
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for {
+				select {
+				case <-p.stopping:
+					return
+				case msg := <-channel:
+					p.namespaces.dispatch(msg)
+				}
+			}
+		}()
+		return
+	}
+
+	bufferSize := cap(channel) / count
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	shards := make([]chan interface{}, count)
+	for i := range shards {
+		shards[i] = make(chan interface{}, bufferSize)
+		p.wg.Add(1)
+		go func(shard chan interface{}) {
+			defer p.wg.Done()
+			for {
+				select {
+				case <-p.stopping:
+					return
+				case msg := <-shard:
+					if err := p.process(p.ctx, msg); err != nil {
+						return
+					}
+				}
+			}
+		}(shards[i])
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			select {
+			case <-p.stopping:
+				return
+			case msg := <-channel:
+				select {
+				case shards[messaging.Shard(messaging.OrderingKey(msg), len(shards))] <- msg:
+				case <-p.stopping:
+					return
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,79 @@
+package pipelined
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/featuregate"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderAdapter reports the checks and sequence numbers of the events it runs,
+// after a delay that depends on the sequence number, so that the events
+// handled in parallel would finish out of order.
+type orderAdapter struct {
+	handled chan [2]string
+}
+
+func (a *orderAdapter) Name() string {
+	return "order"
+}
+
+func (a *orderAdapter) CanRun(*corev2.ResourceReference) bool {
+	return true
+}
+
+func (a *orderAdapter) Run(ctx context.Context, ref *corev2.ResourceReference, msg interface{}) error {
+	event := msg.(*corev2.Event)
+	if event.Check.Output == "0" {
+		time.Sleep(20 * time.Millisecond)
+	}
+	a.handled <- [2]string{event.Check.Name, event.Check.Output}
+	return nil
+}
+
+func TestPipelinedOrderedEvents(t *testing.T) {
+	for _, namespaceWorkers := range []int{0, 4} {
+		t.Run(fmt.Sprintf("namespace workers %d", namespaceWorkers), func(t *testing.T) {
+			bus, err := messaging.NewWizardBus(messaging.WizardBusConfig{})
+			require.NoError(t, err)
+			require.NoError(t, bus.Start())
+
+			gates := featuregate.NewDefault()
+			require.NoError(t, gates.Set("OrderedEvents=true"))
+			p, err := New(Config{Bus: bus, BufferSize: 100, WorkerCount: 4, NamespaceWorkers: namespaceWorkers, FeatureGates: gates})
+			require.NoError(t, err)
+			adapter := &orderAdapter{handled: make(chan [2]string, 100)}
+			p.AddAdapter(adapter)
+			require.NoError(t, p.Start())
+
+			const checks, events = 4, 5
+			for i := 0; i < events; i++ {
+				for c := 0; c < checks; c++ {
+					event := corev2.FixtureEvent("entity1", fmt.Sprintf("check%d", c))
+					event.Check.Output = strconv.Itoa(i)
+					event.Pipelines = []*corev2.ResourceReference{{APIVersion: "core/v2", Type: "Pipeline", Name: "pipeline1"}}
+					require.NoError(t, bus.Publish(messaging.TopicEvent, event))
+				}
+			}
+
+			next := map[string]int{}
+			for i := 0; i < checks*events; i++ {
+				select {
+				case handled := <-adapter.handled:
+					assert.Equal(t, strconv.Itoa(next[handled[0]]), handled[1], handled[0])
+					next[handled[0]]++
+				case <-time.After(5 * time.Second):
+					t.Fatal("events were not handled")
+				}
+			}
+			assert.NoError(t, p.Stop())
+		})
+	}
+}
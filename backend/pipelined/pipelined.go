@@ -10,6 +10,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/featuregate"
 	"github.com/sensu/sensu-go/backend/messaging"
 	"github.com/sensu/sensu-go/backend/pipeline"
 	"github.com/sensu/sensu-go/backend/store"
@@ -42,6 +43,8 @@ var (
 // handler configuration determines which Sensu filters and mutator
 // are used.
 type Pipelined struct {
+	ctx          context.Context
+	cancel       context.CancelFunc
 	stopping     chan struct{}
 	running      *atomic.Value
 	wg           *sync.WaitGroup
@@ -51,6 +54,8 @@ type Pipelined struct {
 	bus          messaging.MessageBus
 	workerCount  int
 	adapters     []pipeline.Adapter
+	namespaces   *namespacePools
+	ordered      bool
 }
 
 // Config configures a Pipelined.
@@ -58,6 +63,19 @@ type Config struct {
 	Bus         messaging.MessageBus
 	BufferSize  int
 	WorkerCount int
+
+	// NamespaceWorkers is the number of workers of each namespace. When it is
+	// set, the events of each namespace are handled by workers of their own,
+	// and the WorkerCount workers only dispatch events to them.
+	NamespaceWorkers int
+
+	// NamespaceBudget is the maximum duration of the handling of an event by
+	// the workers of a namespace, or zero for no limit.
+	NamespaceBudget time.Duration
+
+	// FeatureGates enables the OrderedEvents feature, which handles the
+	// events of each entity and check in order.
+	FeatureGates *featuregate.Gates
 }
 
 // Option is a functional option used to configure Pipelined.
@@ -89,6 +107,21 @@ func New(c Config, options ...Option) (*Pipelined, error) {
 		errChan:     make(chan error, 1),
 		eventChan:   make(chan interface{}, c.BufferSize),
 		workerCount: c.WorkerCount,
+		ordered:     c.FeatureGates.Enabled(featuregate.OrderedEvents),
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	if c.NamespaceWorkers > 0 {
+		p.namespaces = &namespacePools{
+			workers:    c.NamespaceWorkers,
+			bufferSize: c.BufferSize,
+			budget:     c.NamespaceBudget,
+			ctx:        p.ctx,
+			handle:     p.process,
+			stopping:   p.stopping,
+			wg:         p.wg,
+			pools:      make(map[string]*namespacePool),
+			ordered:    p.ordered,
+		}
 	}
 	for _, o := range options {
 		if err := o(p); err != nil {
@@ -115,13 +148,17 @@ func (p *Pipelined) Receiver() chan<- interface{} {
 // Start pipelined, subscribing to the "event" message bus topic to
 // pass Sensu events to the pipelines for handling (goroutines).
 func (p *Pipelined) Start() error {
-	sub, err := p.bus.Subscribe(messaging.TopicEvent, "pipelined", p)
+	sub, err := messaging.Supervise(p.ctx, p.bus, messaging.TopicEvent, "pipelined", p)
 	if err != nil {
 		return err
 	}
 	p.subscription = sub
 
-	p.createWorkers(p.workerCount, p.eventChan)
+	if p.ordered {
+		p.createOrderedWorkers(p.workerCount, p.eventChan)
+	} else {
+		p.createWorkers(p.workerCount, p.eventChan)
+	}
 
 	return nil
 }
@@ -129,6 +166,7 @@ func (p *Pipelined) Start() error {
 // Stop pipelined.
 func (p *Pipelined) Stop() error {
 	p.running.Store(false)
+	p.cancel()
 	close(p.stopping)
 	p.wg.Wait()
 	close(p.errChan)
@@ -143,6 +181,12 @@ func (p *Pipelined) Err() <-chan error {
 	return p.errChan
 }
 
+// QueueDepth returns the number of events waiting to be handled by pipelined
+// and the capacity of its buffer.
+func (p *Pipelined) QueueDepth() (int, int) {
+	return len(p.eventChan), cap(p.eventChan)
+}
+
 // Name returns the daemon name
 func (p *Pipelined) Name() string {
 	return "pipelined"
@@ -165,14 +209,12 @@ func (p *Pipelined) createWorkers(count int, channel chan interface{}) {
 				case <-p.stopping:
 					return
 				case msg := <-channel:
-					if _, err := p.handleMessage(context.Background(), msg); err != nil {
-						if _, ok := err.(*store.ErrInternal); ok {
-							select {
-							case p.errChan <- err:
-							case <-p.stopping:
-							}
-							return
-						}
+					if p.namespaces != nil {
+						p.namespaces.dispatch(msg)
+						continue
+					}
+					if err := p.process(p.ctx, msg); err != nil {
+						return
 					}
 				}
 			}
@@ -180,6 +222,21 @@ func (p *Pipelined) createWorkers(count int, channel chan interface{}) {
 	}
 }
 
+// process handles msg and reports the internal store errors that must stop
+// pipelined on its error channel. It only returns these errors.
+func (p *Pipelined) process(ctx context.Context, msg interface{}) error {
+	if _, err := p.handleMessage(ctx, msg); err != nil {
+		if _, ok := err.(*store.ErrInternal); ok {
+			select {
+			case p.errChan <- err:
+			case <-p.stopping:
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *Pipelined) handleMessage(ctx context.Context, msg interface{}) (hadPipelines bool, fErr error) {
 	begin := time.Now()
 	defer func() {
@@ -208,6 +265,9 @@ func (p *Pipelined) handleMessage(ctx context.Context, msg interface{}) (hadPipe
 	// Add a legacy pipeline "reference" if msg is a
 	// corev2.Event & has handlers.
 	if event, ok := msg.(*corev2.Event); ok {
+		if id := store.EventRequestID(event); id != "" {
+			fields["request_id"] = id
+		}
 		if event.HasHandlers() {
 			pipelineRefs = append(pipelineRefs, pipeline.LegacyPipelineReference())
 		} else {
@@ -0,0 +1,316 @@
+// Package jetstream implements the queue Client of the bridged message bus
+// with the streams of a NATS JetStream server, which keep the messages across
+// the restarts of the backends and share them between the backends.
+package jetstream
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sensu/sensu-go/backend/queue"
+)
+
+const (
+	// WorkStream is the stream of the messages handled by a single backend,
+	// such as the events of the agents.
+	WorkStream = "SENSU_WORK"
+
+	// BroadcastStream is the stream of the messages delivered to every
+	// backend, such as the check requests of the agents.
+	BroadcastStream = "SENSU_BROADCAST"
+
+	// DefaultMaxAge is how long the streams keep their messages when
+	// Config.MaxAge is zero.
+	DefaultMaxAge = time.Hour
+
+	// DefaultStaleAfter is how old the messages of the broadcast stream can
+	// be when a backend subscribes, like the broadcast messages of the Kafka
+	// bus.
+	DefaultStaleAfter = 10 * time.Second
+
+	// ackWait is how long a reserved message waits to be acknowledged before
+	// it is delivered again, like the items of the other queues.
+	ackWait = 60 * time.Second
+
+	// fetchWait is how long a reservation waits for a message before asking
+	// the server again, unless its context expires before.
+	fetchWait = 5 * time.Second
+)
+
+// Config configures a Client.
+type Config struct {
+	// URL is the URL of the NATS server, or a comma-separated list of the
+	// URLs of the servers of a cluster.
+	URL string
+
+	// Name is the name of the connection, such as the name of the backend.
+	Name string
+
+	// TLS is the TLS configuration of the connection, or nil for the
+	// default one.
+	TLS *tls.Config
+
+	// Stream is the name of the stream of the messages, created if it
+	// doesn't exist.
+	Stream string
+
+	// Consumer is the name of the durable consumer of the messages. The
+	// clients that share a consumer share its messages, and every consumer
+	// receives every message.
+	Consumer string
+
+	// WorkQueue is true if the stream removes the messages once they are
+	// acknowledged by a consumer, rather than once they are older than
+	// MaxAge.
+	WorkQueue bool
+
+	// MaxAge is how long the stream keeps the messages.
+	MaxAge time.Duration
+
+	// Replicas is the number of replicas of the stream in a JetStream
+	// cluster, or 1 if it is zero.
+	Replicas int
+
+	// StaleAfter is how old the messages can be when the client subscribes
+	// to a queue, or zero to receive all the messages of the consumer. If it
+	// is set, the durable consumer is created again from that point in time
+	// each time the client subscribes, so that the messages missed while the
+	// backend was stopped, such as check requests, are not delivered once it
+	// starts again.
+	StaleAfter time.Duration
+
+	// InactiveThreshold is how long the server keeps a durable consumer no
+	// client fetches from, or zero to keep it forever, so that the consumers
+	// of the backends that are gone don't pile up.
+	InactiveThreshold time.Duration
+}
+
+// Client is a queue Client whose queues are the subjects of a JetStream
+// stream.
+type Client struct {
+	conn              *nats.Conn
+	js                nats.JetStreamContext
+	stream            string
+	consumer          string
+	staleAfter        time.Duration
+	inactiveThreshold time.Duration
+
+	mu            sync.Mutex
+	subscriptions map[string]*nats.Subscription
+}
+
+// New connects to the NATS server of cfg, and creates the stream of the
+// messages if it doesn't exist.
+func New(cfg Config) (*Client, error) {
+	if cfg.Stream == "" || cfg.Consumer == "" {
+		return nil, errors.New("a stream and a consumer are required")
+	}
+	opts := []nats.Option{
+		nats.Name(cfg.Name),
+		// The bus is never abandoned while the backend runs
+		nats.MaxReconnects(-1),
+	}
+	if cfg.TLS != nil {
+		opts = append(opts, nats.Secure(cfg.TLS))
+	}
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the NATS server: %w", err)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := ensureStream(js, cfg); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not create the stream %s: %w", cfg.Stream, err)
+	}
+	return &Client{
+		conn:              conn,
+		js:                js,
+		stream:            cfg.Stream,
+		consumer:          cfg.Consumer,
+		staleAfter:        cfg.StaleAfter,
+		inactiveThreshold: cfg.InactiveThreshold,
+		subscriptions:     make(map[string]*nats.Subscription),
+	}, nil
+}
+
+// ensureStream creates the stream of cfg if it doesn't exist. The
+// configuration of an existing stream is left as is.
+func ensureStream(js nats.JetStreamContext, cfg Config) error {
+	_, err := js.StreamInfo(cfg.Stream)
+	if err == nil || !errors.Is(err, nats.ErrStreamNotFound) {
+		return err
+	}
+	streamConfig := &nats.StreamConfig{
+		Name:      cfg.Stream,
+		Subjects:  []string{cfg.Stream + ".>"},
+		Retention: nats.LimitsPolicy,
+		MaxAge:    cfg.MaxAge,
+		Storage:   nats.FileStorage,
+		Replicas:  cfg.Replicas,
+	}
+	if cfg.WorkQueue {
+		streamConfig.Retention = nats.WorkQueuePolicy
+	}
+	if streamConfig.MaxAge <= 0 {
+		streamConfig.MaxAge = DefaultMaxAge
+	}
+	if streamConfig.Replicas <= 0 {
+		streamConfig.Replicas = 1
+	}
+	_, err = js.AddStream(streamConfig)
+	if errors.Is(err, nats.ErrStreamNameAlreadyInUse) {
+		// Another backend created it in the meantime
+		return nil
+	}
+	return err
+}
+
+// Enqueue publishes the value of item to the subject of its queue, once the
+// stream has stored it.
+func (c *Client) Enqueue(ctx context.Context, item queue.Item) error {
+	_, err := c.js.Publish(c.subject(item.Queue), item.Value, nats.Context(ctx))
+	return err
+}
+
+// Reserve receives the next message of the subject of queueName from the
+// consumer of c. It blocks until a message is received or ctx is done.
+func (c *Client) Reserve(ctx context.Context, queueName string) (queue.Reservation, error) {
+	subscription, err := c.subscription(queueName)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		wait := fetchWait
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			wait = time.Until(deadline)
+		}
+		if wait <= 0 {
+			return nil, ctx.Err()
+		}
+		msgs, err := subscription.Fetch(1, nats.MaxWait(wait))
+		if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			continue
+		}
+		if errors.Is(err, nats.ErrConsumerDeleted) || errors.Is(err, nats.ErrConsumerNotFound) {
+			// The server deleted the inactive consumer, it is created again
+			// by the next reservation
+			c.forget(queueName, subscription)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(msgs) == 0 {
+			continue
+		}
+		return &reservation{msg: msgs[0], queue: queueName}, nil
+	}
+}
+
+// Close closes the connection to the NATS server. The durable consumers are
+// kept, so that their messages are received once the backend starts again,
+// unless they are older than the StaleAfter of the client.
+func (c *Client) Close() error {
+	c.conn.Close()
+	return nil
+}
+
+// subscription returns the pull subscription of the consumer of c to the
+// subject of queueName.
+func (c *Client) subscription(queueName string) (*nats.Subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if subscription, ok := c.subscriptions[queueName]; ok {
+		return subscription, nil
+	}
+	durable := durableName(c.consumer, queueName)
+	opts := []nats.SubOpt{
+		nats.BindStream(c.stream),
+		nats.AckWait(ackWait),
+	}
+	if c.staleAfter > 0 {
+		// The start time of an existing consumer can't be changed
+		err := c.js.DeleteConsumer(c.stream, durable)
+		if err != nil && !errors.Is(err, nats.ErrConsumerNotFound) {
+			return nil, err
+		}
+		opts = append(opts, nats.StartTime(time.Now().Add(-c.staleAfter)))
+	}
+	if c.inactiveThreshold > 0 {
+		opts = append(opts, nats.InactiveThreshold(c.inactiveThreshold))
+	}
+	subscription, err := c.js.PullSubscribe(c.subject(queueName), durable, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.subscriptions[queueName] = subscription
+	return subscription, nil
+}
+
+// forget drops the subscription of queueName, whose consumer was deleted, so
+// that it is created again.
+func (c *Client) forget(queueName string, subscription *nats.Subscription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subscriptions[queueName] == subscription {
+		delete(c.subscriptions, queueName)
+		_ = subscription.Unsubscribe()
+	}
+}
+
+// subject returns the subject of the messages of queueName, in the stream of
+// c.
+func (c *Client) subject(queueName string) string {
+	return c.stream + "." + sanitize(queueName)
+}
+
+// durableName returns the name of the durable consumer of queueName.
+func durableName(consumer, queueName string) string {
+	return sanitize(consumer) + "_" + sanitize(queueName)
+}
+
+// sanitize replaces the characters that separate the tokens of the subjects
+// and that are not allowed in the names of the consumers.
+func sanitize(name string) string {
+	return strings.NewReplacer("/", "_", ".", "_", "*", "_", ">", "_", " ", "_").Replace(name)
+}
+
+// reservation is a message received from a stream, which is received again
+// if it is not acknowledged in time.
+type reservation struct {
+	msg   *nats.Msg
+	queue string
+}
+
+// Item returns the item of the message, identified by its sequence in the
+// stream.
+func (r *reservation) Item() queue.Item {
+	item := queue.Item{Queue: r.queue, Value: r.msg.Data}
+	if meta, err := r.msg.Metadata(); err == nil {
+		item.ID = strconv.FormatUint(meta.Sequence.Stream, 10)
+	}
+	return item
+}
+
+// Ack acknowledges the message, which removes it from the work queues.
+func (r *reservation) Ack(context.Context) error {
+	return r.msg.Ack()
+}
+
+// Nack asks the server to deliver the message again.
+func (r *reservation) Nack(context.Context) error {
+	return r.msg.Nak()
+}
@@ -0,0 +1,103 @@
+package jetstream
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/queue"
+	"github.com/sensu/sensu-go/backend/queue/testspec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client of a new stream of the NATS server of the
+// NATS_URL environment variable, which is deleted once the test ends.
+func newTestClient(t *testing.T, stream, consumer string, workQueue bool) *Client {
+	t.Helper()
+	url := os.Getenv("NATS_URL")
+	if url == "" {
+		t.Skip("skipping jetstream test: NATS_URL not set")
+	}
+	client, err := New(Config{
+		URL:       url,
+		Name:      t.Name(),
+		Stream:    stream,
+		Consumer:  consumer,
+		WorkQueue: workQueue,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.js.DeleteStream(stream)
+		_ = client.Close()
+	})
+	return client
+}
+
+func testStream() string {
+	return fmt.Sprintf("SENSU_TEST_%d", time.Now().UnixNano())
+}
+
+func TestClientSpec(t *testing.T) {
+	client := newTestClient(t, testStream(), "spec", true)
+	testspec.RunClientTestSuite(t, context.Background(), client)
+}
+
+func TestClientBroadcast(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	stream := testStream()
+	backend1 := newTestClient(t, stream, "backend1", false)
+	backend2 := newTestClient(t, stream, "backend2", false)
+
+	require.NoError(t, backend1.Enqueue(ctx, queue.Item{Queue: "bridge/broadcast", Value: []byte("request")}))
+
+	// Every consumer receives the message
+	for _, client := range []*Client{backend1, backend2} {
+		reservation, err := client.Reserve(ctx, "bridge/broadcast")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("request"), reservation.Item().Value)
+		require.NoError(t, reservation.Ack(ctx))
+	}
+
+	// The consumer receives the messages enqueued while it was closed
+	require.NoError(t, backend2.Close())
+	require.NoError(t, backend1.Enqueue(ctx, queue.Item{Queue: "bridge/broadcast", Value: []byte("missed")}))
+	restarted := newTestClient(t, stream, "backend2", false)
+	reservation, err := restarted.Reserve(ctx, "bridge/broadcast")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("missed"), reservation.Item().Value)
+}
+
+func TestClientBroadcastStale(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	stream := testStream()
+	backend1 := newTestClient(t, stream, "backend1", false)
+	require.NoError(t, backend1.Enqueue(ctx, queue.Item{Queue: "bridge/broadcast", Value: []byte("stale")}))
+	time.Sleep(100 * time.Millisecond)
+
+	// The consumer skips the messages older than StaleAfter once it starts
+	// again
+	restarted, err := New(Config{
+		URL:               os.Getenv("NATS_URL"),
+		Name:              t.Name(),
+		Stream:            stream,
+		Consumer:          "backend2",
+		StaleAfter:        50 * time.Millisecond,
+		InactiveThreshold: time.Minute,
+	})
+	require.NoError(t, err)
+	defer restarted.Close()
+	require.NoError(t, backend1.Enqueue(ctx, queue.Item{Queue: "bridge/broadcast", Value: []byte("fresh")}))
+	reservation, err := restarted.Reserve(ctx, "bridge/broadcast")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("fresh"), reservation.Item().Value)
+}
+
+func TestSanitize(t *testing.T) {
+	assert.Equal(t, "bridge_work", sanitize("bridge/work"))
+	assert.Equal(t, "backend1_example_com_bridge_broadcast", durableName("backend1.example.com", "bridge/broadcast"))
+}
@@ -0,0 +1,101 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/core/v3/types"
+	"github.com/sensu/sensu-go/backend/pipeline"
+)
+
+// listLimit is the number of events read at once from the events API.
+const listLimit = 500
+
+// APIClient publishes the replayed events with the replays API of a backend,
+// and reads the events stored in a namespace. It authenticates with an API
+// key, whose user must be allowed to create replays.
+type APIClient struct {
+	// URL is the URL of the API, such as http://localhost:8080.
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+func (c *APIClient) do(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	u := strings.TrimSuffix(c.URL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Key "+c.APIKey)
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return resp, nil
+}
+
+// PublishEvent creates event in its namespace, as an event of the replay of
+// its pipeline.ReplayAnnotation, which sends it through the event pipeline
+// with its handlers stubbed.
+func (c *APIClient) PublishEvent(ctx context.Context, event *corev2.Event) error {
+	body, err := json.Marshal(types.WrapResource(event))
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/api/core/v2/namespaces/%s/replays/%s/events",
+		url.PathEscape(event.Namespace), url.PathEscape(event.Annotations[pipeline.ReplayAnnotation]))
+	resp, err := c.do(ctx, http.MethodPost, path, nil, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// Events returns the events stored in namespace.
+func (c *APIClient) Events(ctx context.Context, namespace string) ([]*corev2.Event, error) {
+	var events []*corev2.Event
+	path := fmt.Sprintf("/api/core/v2/namespaces/%s/events", url.PathEscape(namespace))
+	query := url.Values{"limit": []string{fmt.Sprint(listLimit)}}
+	for {
+		resp, err := c.do(ctx, http.MethodGet, path, query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []*corev2.Event
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not decode events: %s", err)
+		}
+		events = append(events, page...)
+		next := resp.Header.Get(corev2.PaginationContinueHeader)
+		if next == "" || len(page) == 0 {
+			return events, nil
+		}
+		query.Set("continue", next)
+	}
+}
@@ -0,0 +1,230 @@
+// Package replay replays recorded events through the event pipeline, to
+// reproduce bugs and benchmark pipeline changes against production-shaped
+// traffic. The events are read from a capture file, such as the event log
+// file of eventd, or from the events stored in a namespace. They are replayed
+// in a namespace of their own, with the pipeline.ReplayAnnotation, so that
+// eventd stores them and pipelined runs their filters and mutators, but does
+// not run their handlers.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline"
+)
+
+// Decoder decodes the events of a capture file, one JSON event per line, as
+// written by the event log of eventd.
+type Decoder struct {
+	dec *json.Decoder
+}
+
+// NewDecoder returns a new Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// Decode returns the next event of the capture file, or io.EOF at its end.
+func (d *Decoder) Decode() (*corev2.Event, error) {
+	var event corev2.Event
+	if err := d.dec.Decode(&event); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not decode event: %s", err)
+	}
+	return &event, nil
+}
+
+// Source is a source of recorded events. Decode returns io.EOF after the last
+// event.
+type Source interface {
+	Decode() (*corev2.Event, error)
+}
+
+// SliceSource is a Source of the events of a slice, such as the events stored
+// in a namespace.
+type SliceSource []*corev2.Event
+
+// Decode returns the first event of the slice, and removes it.
+func (s *SliceSource) Decode() (*corev2.Event, error) {
+	if len(*s) == 0 {
+		return nil, io.EOF
+	}
+	event := (*s)[0]
+	*s = (*s)[1:]
+	return event, nil
+}
+
+// Publisher publishes the replayed events to the event pipeline.
+type Publisher interface {
+	PublishEvent(ctx context.Context, event *corev2.Event) error
+}
+
+// Config configures a Replayer.
+type Config struct {
+	Publisher Publisher
+
+	// Namespace is the namespace in which the events are replayed.
+	Namespace string
+
+	// ID identifies the replay in the pipeline.ReplayAnnotation of the
+	// replayed events.
+	ID string
+
+	// Speed is the speed of the replay relative to the recording: the events
+	// are replayed Speed times faster than they were recorded. The events are
+	// replayed as fast as possible when it is zero.
+	Speed float64
+
+	// Keepalives replays the keepalive events when true. They are skipped by
+	// default, so that keepalived does not monitor the replayed entities.
+	Keepalives bool
+}
+
+// Result is the result of a replay.
+type Result struct {
+	Replayed int           `json:"replayed"`
+	Skipped  int           `json:"skipped"`
+	Errors   []string      `json:"errors,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Replayer replays recorded events.
+type Replayer struct {
+	config Config
+	now    func() time.Time
+	sleep  func(context.Context, time.Duration) error
+}
+
+// New returns a new Replayer.
+func New(cfg Config) (*Replayer, error) {
+	if cfg.Publisher == nil {
+		return nil, errors.New("a publisher is required")
+	}
+	if cfg.Namespace == "" {
+		return nil, errors.New("a namespace is required")
+	}
+	if cfg.ID == "" {
+		return nil, errors.New("a replay ID is required")
+	}
+	if cfg.Speed < 0 {
+		return nil, errors.New("the speed must not be negative")
+	}
+	return &Replayer{config: cfg, now: time.Now, sleep: sleep}, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Replay replays the events of src in their recorded order, and returns once
+// src is exhausted or ctx is done. The timestamps of the events are shifted
+// so that the first event occurs when the replay starts, and their relative
+// timing is kept.
+func (r *Replayer) Replay(ctx context.Context, src Source) (Result, error) {
+	var result Result
+	start := r.now()
+	var first int64
+	for {
+		event, err := src.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.Duration = r.now().Sub(start)
+			return result, err
+		}
+		if !event.HasCheck() && !event.HasMetrics() {
+			result.Skipped++
+			continue
+		}
+		if event.HasCheck() && event.Check.Name == corev2.KeepaliveCheckName && !r.config.Keepalives {
+			result.Skipped++
+			continue
+		}
+		if first == 0 {
+			first = event.Timestamp
+		}
+		if r.config.Speed > 0 {
+			offset := time.Duration(float64(time.Duration(event.Timestamp-first)*time.Second) / r.config.Speed)
+			if wait := start.Add(offset).Sub(r.now()); wait > 0 {
+				if err := r.sleep(ctx, wait); err != nil {
+					result.Duration = r.now().Sub(start)
+					return result, err
+				}
+			}
+		}
+		Rewrite(event, r.config.Namespace, r.config.ID, start.Unix()-first)
+		if err := r.config.Publisher.PublishEvent(ctx, event); err != nil {
+			if ctx.Err() != nil {
+				result.Duration = r.now().Sub(start)
+				return result, ctx.Err()
+			}
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", eventID(event), err))
+			continue
+		}
+		result.Replayed++
+	}
+	result.Duration = r.now().Sub(start)
+	return result, nil
+}
+
+// Rewrite moves event to namespace, annotates it with the replay ID, and
+// shifts its timestamps by shift seconds. Its ID is cleared so that a new ID
+// is assigned to it.
+func Rewrite(event *corev2.Event, namespace, id string, shift int64) {
+	event.ID = nil
+	event.Namespace = namespace
+	if event.Annotations == nil {
+		event.Annotations = make(map[string]string)
+	}
+	event.Annotations[pipeline.ReplayAnnotation] = id
+	if event.Timestamp != 0 {
+		event.Timestamp += shift
+	}
+	if event.Entity != nil {
+		event.Entity.Namespace = namespace
+		if event.Entity.LastSeen != 0 {
+			event.Entity.LastSeen += shift
+		}
+	}
+	if event.Check != nil {
+		event.Check.Namespace = namespace
+		if event.Check.Executed != 0 {
+			event.Check.Executed += shift
+		}
+		if event.Check.Issued != 0 {
+			event.Check.Issued += shift
+		}
+		// The history of the replayed event is built by eventd
+		event.Check.History = nil
+		event.Check.LastOK = 0
+		event.Check.Occurrences = 0
+		event.Check.OccurrencesWatermark = 0
+	}
+}
+
+func eventID(event *corev2.Event) string {
+	name := "unknown"
+	if event.Entity != nil {
+		name = event.Entity.Name
+	}
+	if event.HasCheck() {
+		name += "/" + event.Check.Name
+	}
+	return name
+}
@@ -0,0 +1,185 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/pipeline"
+)
+
+type testPublisher struct {
+	events []*corev2.Event
+	err    error
+}
+
+func (p *testPublisher) PublishEvent(ctx context.Context, event *corev2.Event) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func fixtureEvent(check string, timestamp int64) *corev2.Event {
+	event := corev2.FixtureEvent("entity1", check)
+	event.Timestamp = timestamp
+	event.Check.Executed = timestamp
+	event.Check.History = []corev2.CheckHistory{{Executed: timestamp}}
+	return event
+}
+
+func captureFile(t *testing.T, events ...*corev2.Event) *Decoder {
+	t.Helper()
+	var b strings.Builder
+	for _, event := range events {
+		if err := json.NewEncoder(&b).Encode(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewDecoder(strings.NewReader(b.String()))
+}
+
+func TestReplay(t *testing.T) {
+	publisher := &testPublisher{}
+	r, err := New(Config{Publisher: publisher, Namespace: "replay", ID: "replay1", Speed: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(2000, 0)
+	now := start
+	r.now = func() time.Time { return now }
+	var waits []time.Duration
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		now = now.Add(d)
+		return nil
+	}
+
+	src := captureFile(t,
+		fixtureEvent("check1", 1000),
+		fixtureEvent(corev2.KeepaliveCheckName, 1005),
+		fixtureEvent("check2", 1010),
+	)
+	result, err := r.Replay(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Replayed != 2 || result.Skipped != 1 || len(result.Errors) != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(waits) != 1 || waits[0] != 5*time.Second {
+		t.Errorf("expected the relative timing to be kept at twice the speed, got waits %v", waits)
+	}
+
+	for i, want := range []string{"check1", "check2"} {
+		event := publisher.events[i]
+		if got := event.Check.Name; got != want {
+			t.Errorf("event %d: got check %q, want %q", i, got, want)
+		}
+		if event.Namespace != "replay" || event.Entity.Namespace != "replay" || event.Check.Namespace != "replay" {
+			t.Errorf("event %d: expected the event to be moved to the replay namespace", i)
+		}
+		if got := event.Annotations[pipeline.ReplayAnnotation]; got != "replay1" {
+			t.Errorf("event %d: got replay annotation %q", i, got)
+		}
+		if len(event.Check.History) != 0 {
+			t.Errorf("event %d: expected the history to be cleared", i)
+		}
+	}
+	if got, want := publisher.events[0].Timestamp, start.Unix(); got != want {
+		t.Errorf("got timestamp %d, want %d", got, want)
+	}
+	if got, want := publisher.events[1].Timestamp, start.Unix()+10; got != want {
+		t.Errorf("got timestamp %d, want %d", got, want)
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	publisher := &testPublisher{err: errors.New("unavailable")}
+	r, err := New(Config{Publisher: publisher, Namespace: "replay", ID: "replay1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := SliceSource{fixtureEvent("check1", 1000)}
+	result, err := r.Replay(context.Background(), &src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Replayed != 0 || len(result.Errors) != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	_, err = r.Replay(context.Background(), NewDecoder(strings.NewReader("{")))
+	if err == nil {
+		t.Error("expected an error for an invalid capture file")
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	publisher := &testPublisher{}
+	for _, cfg := range []Config{
+		{Namespace: "replay", ID: "replay1"},
+		{Publisher: publisher, ID: "replay1"},
+		{Publisher: publisher, Namespace: "replay"},
+		{Publisher: publisher, Namespace: "replay", ID: "replay1", Speed: -1},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
+
+func TestAPIClient(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if got := req.Header.Get("Authorization"); got != "Key secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch req.Method {
+		case http.MethodPost:
+			posted = append(posted, req.URL.Path)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			var events []*corev2.Event
+			if req.URL.Query().Get("continue") == "" {
+				w.Header().Set(corev2.PaginationContinueHeader, "next")
+				events = append(events, fixtureEvent("check1", 1000))
+			} else {
+				events = append(events, fixtureEvent("check2", 1010))
+			}
+			_ = json.NewEncoder(w).Encode(events)
+		}
+	}))
+	defer server.Close()
+
+	client := &APIClient{URL: server.URL, APIKey: "secret"}
+	events, err := client.Events(context.Background(), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+
+	event := fixtureEvent("check1", 1000)
+	Rewrite(event, "replay", "replay-1", 0)
+	if err := client.PublishEvent(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if len(posted) != 1 || posted[0] != "/api/core/v2/namespaces/replay/replays/replay-1/events" {
+		t.Errorf("unexpected requests: %v", posted)
+	}
+
+	client.APIKey = "wrong"
+	if err := client.PublishEvent(context.Background(), event); err == nil {
+		t.Error("expected an error")
+	}
+}
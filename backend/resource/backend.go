@@ -3,6 +3,7 @@ package resource
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,8 +26,12 @@ type BackendResource struct {
 	entityStateStore  storev2.EntityStateStore
 	bus               messaging.MessageBus
 	backendEntity     *corev2.Entity
-	lastEvents        map[string]*eventInfo
 	repeatIntervalSec int64
+
+	// mu protects lastEvents, since the backend events are generated by
+	// several components
+	mu         sync.Mutex
+	lastEvents map[string]*eventInfo
 }
 
 const (
@@ -37,6 +42,12 @@ const (
 	systemNamespaceName = "sensu-system"
 )
 
+// EventReceiver publishes the events of the backend entity, like the
+// BackendResource does.
+type EventReceiver interface {
+	GenerateBackendEvent(component string, status uint32, output string) error
+}
+
 func New(ns storev2.NamespaceStore, entc storev2.EntityConfigStore, ents storev2.EntityStateStore, bus messaging.MessageBus) *BackendResource {
 	return &BackendResource{
 		namespaceStore:    ns,
@@ -88,6 +99,8 @@ func (b *BackendResource) GenerateBackendEvent(component string, status uint32,
 		return errors.New("backend entity doesn't exist")
 	}
 
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	now := time.Now().Unix()
 	if lastEvent, ok := b.lastEvents[component]; ok {
 		if lastEvent.status == status && now-lastEvent.timestampSec < b.repeatIntervalSec {
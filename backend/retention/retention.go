@@ -0,0 +1,278 @@
+// Package retention enforces the retention policies of the namespaces, which
+// scrub, anonymize and delete the stored events as they age.
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+const (
+	componentName = "retention"
+
+	// DefaultInterval is the interval of the enforcement of the retention
+	// policies when Config.Interval is zero.
+	DefaultInterval = time.Hour
+
+	// EventsCounterVec is the name of the counter of the events scrubbed,
+	// anonymized and deleted by the retention policies.
+	EventsCounterVec = "sensu_go_retention_events"
+
+	// EventsLabelAction is the action label of the events counter.
+	EventsLabelAction = "action"
+
+	actionScrubbed   = "scrubbed"
+	actionAnonymized = "anonymized"
+	actionDeleted    = "deleted"
+
+	// pageSize is the number of events read at once from the store.
+	pageSize = 500
+)
+
+var (
+	// Events counts the events scrubbed, anonymized and deleted by the
+	// retention policies.
+	Events = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: EventsCounterVec,
+			Help: "The total number of events scrubbed, anonymized and deleted by retention policies",
+		},
+		[]string{EventsLabelAction},
+	)
+)
+
+func init() {
+	if err := prometheus.Register(Events); err != nil {
+		panic(err)
+	}
+}
+
+// Config configures a Retentiond.
+type Config struct {
+	Store    storev2.Interface
+	Events   store.EventStore
+	Replacer store.EventReplacer
+
+	// Executor elects the backend that enforces the retention policies.
+	Executor store.SynchronizedExecutor
+
+	// Interval is the interval of the enforcement of the retention policies.
+	Interval time.Duration
+}
+
+// Retentiond is the daemon that enforces the retention policies of the
+// namespaces. One backend of the cluster, elected with a mutex, enforces
+// them.
+type Retentiond struct {
+	store    storev2.Interface
+	events   store.EventStore
+	replacer store.EventReplacer
+	executor store.SynchronizedExecutor
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	errChan  chan error
+}
+
+// New creates a new Retentiond.
+func New(cfg Config) *Retentiond {
+	ctx, cancel := context.WithCancel(context.Background())
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Retentiond{
+		store:    cfg.Store,
+		events:   cfg.Events,
+		replacer: cfg.Replacer,
+		executor: cfg.Executor,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		errChan:  make(chan error, 1),
+	}
+}
+
+// Start starts the enforcement of the retention policies.
+func (r *Retentiond) Start() error {
+	go r.run()
+	return nil
+}
+
+// Stop stops the enforcement of the retention policies.
+func (r *Retentiond) Stop() error {
+	r.cancel()
+	<-r.done
+	close(r.errChan)
+	return nil
+}
+
+// Err returns a channel on which to listen for terminal errors.
+func (r *Retentiond) Err() <-chan error {
+	return r.errChan
+}
+
+// Name returns the daemon name.
+func (r *Retentiond) Name() string {
+	return componentName
+}
+
+func (r *Retentiond) run() {
+	defer close(r.done)
+	for r.ctx.Err() == nil {
+		err := r.executor.Execute(r.ctx, store.MutexRetention, func(ctx context.Context) error {
+			ticker := time.NewTicker(r.interval)
+			defer ticker.Stop()
+			for {
+				if err := r.Enforce(ctx); err != nil && ctx.Err() == nil {
+					logger.WithError(err).Error("error enforcing retention policies")
+				}
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		})
+		if err != nil && r.ctx.Err() == nil {
+			logger.WithError(err).Error("lost the enforcement of retention policies")
+		}
+	}
+}
+
+// Enforce enforces the retention policies of every namespace once. The errors
+// of the namespaces are logged, and don't stop the enforcement of the others.
+func (r *Retentiond) Enforce(ctx context.Context) error {
+	namespaces, err := storev2.Of[*corev3.Namespace](r.store).List(ctx, storev2.ID{}, nil)
+	if err != nil {
+		return err
+	}
+	for _, namespace := range namespaces {
+		policy, err := store.RetentionPolicyFromNamespace(namespace)
+		if err != nil {
+			logger.WithError(err).WithField("namespace", namespace.Metadata.Name).Error("invalid retention policy")
+			continue
+		}
+		if policy.IsZero() {
+			continue
+		}
+		if err := r.enforceNamespace(ctx, namespace.Metadata.Name, policy); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			logger.WithError(err).WithField("namespace", namespace.Metadata.Name).Error("error enforcing retention policy")
+		}
+	}
+	return nil
+}
+
+// enforceNamespace enforces policy on the events of namespace. The events
+// deleted while the events are paged through can shift the pages, in which
+// case some events are only enforced by the next enforcement.
+func (r *Retentiond) enforceNamespace(ctx context.Context, namespace string, policy store.RetentionPolicy) error {
+	ctx = store.NamespaceContext(ctx, namespace)
+	now := time.Now()
+	pred := &store.SelectionPredicate{Limit: pageSize}
+	for {
+		events, err := r.events.GetEvents(ctx, pred)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := r.enforceEvent(ctx, event, policy, now); err != nil {
+				return err
+			}
+		}
+		if pred.Continue == "" || len(events) == 0 {
+			return nil
+		}
+	}
+}
+
+func (r *Retentiond) enforceEvent(ctx context.Context, event *corev2.Event, policy store.RetentionPolicy, now time.Time) error {
+	if !event.HasCheck() || event.Entity == nil {
+		return nil
+	}
+	age := now.Sub(time.Unix(event.Timestamp, 0))
+	if policy.DeleteAfter > 0 && age >= policy.DeleteAfter {
+		if err := r.events.DeleteEventByEntityCheck(ctx, event.Entity.Name, event.Check.Name); err != nil {
+			return err
+		}
+		Events.WithLabelValues(actionDeleted).Inc()
+		return nil
+	}
+	var action string
+	switch {
+	case policy.AnonymizeAfter > 0 && age >= policy.AnonymizeAfter:
+		if Anonymize(event) {
+			action = actionAnonymized
+		}
+	case policy.ScrubOutputAfter > 0 && age >= policy.ScrubOutputAfter:
+		if ScrubOutput(event) {
+			action = actionScrubbed
+		}
+	}
+	if action == "" {
+		return nil
+	}
+	if err := r.replacer.ReplaceEvent(ctx, event); err != nil {
+		var notFound *store.ErrNotFound
+		if errors.As(err, &notFound) {
+			// The event was deleted since it was read
+			return nil
+		}
+		return err
+	}
+	Events.WithLabelValues(action).Inc()
+	return nil
+}
+
+// ScrubOutput removes the outputs of the check and hooks of event. It returns
+// true if the event was changed.
+func ScrubOutput(event *corev2.Event) bool {
+	changed := event.Check.Output != ""
+	event.Check.Output = ""
+	for _, hook := range event.Check.Hooks {
+		if hook != nil && hook.Output != "" {
+			hook.Output = ""
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Anonymize removes the outputs, commands, environment variables, metrics,
+// labels and annotations of event, and the system information and user of its
+// entity. The names, statuses and status history of the event are kept. It
+// returns true if the event was changed.
+func Anonymize(event *corev2.Event) bool {
+	before := proto.Clone(event)
+	ScrubOutput(event)
+	event.Metrics = nil
+	event.Labels = nil
+	event.Annotations = nil
+	event.Check.Command = ""
+	event.Check.EnvVars = nil
+	event.Check.Labels = nil
+	event.Check.Annotations = nil
+	for _, hook := range event.Check.Hooks {
+		if hook != nil {
+			hook.Command = ""
+		}
+	}
+	event.Entity.Labels = nil
+	event.Entity.Annotations = nil
+	event.Entity.System = corev2.System{}
+	event.Entity.User = ""
+	return !proto.Equal(before, event)
+}
@@ -0,0 +1,112 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+type testEventStore struct {
+	store.EventStore
+	events   map[string]*corev2.Event
+	replaced []string
+}
+
+func (s *testEventStore) GetEvents(ctx context.Context, pred *store.SelectionPredicate) ([]*corev2.Event, error) {
+	var events []*corev2.Event
+	for _, event := range s.events {
+		events = append(events, event)
+	}
+	pred.Continue = ""
+	return events, nil
+}
+
+func (s *testEventStore) DeleteEventByEntityCheck(ctx context.Context, entity, check string) error {
+	delete(s.events, check)
+	return nil
+}
+
+func (s *testEventStore) ReplaceEvent(ctx context.Context, event *corev2.Event) error {
+	s.replaced = append(s.replaced, event.Check.Name)
+	return nil
+}
+
+func fixtureEvent(check string, age time.Duration) *corev2.Event {
+	event := corev2.FixtureEvent("entity1", check)
+	event.Timestamp = time.Now().Add(-age).Unix()
+	event.Labels = map[string]string{"owner": "alice"}
+	event.Entity.User = "alice"
+	event.Entity.System.Hostname = "alice-laptop"
+	event.Check.Output = "logged in as alice"
+	event.Check.Command = "check-login --user alice"
+	event.Check.EnvVars = []string{"USER=alice"}
+	event.Check.Status = 2
+	event.Check.History = []corev2.CheckHistory{{Status: 2, Executed: event.Timestamp}}
+	return event
+}
+
+func TestEnforceNamespace(t *testing.T) {
+	events := &testEventStore{events: map[string]*corev2.Event{
+		"fresh":      fixtureEvent("fresh", time.Minute),
+		"scrubbed":   fixtureEvent("scrubbed", 2*time.Hour),
+		"anonymized": fixtureEvent("anonymized", 25*time.Hour),
+		"deleted":    fixtureEvent("deleted", 49*time.Hour),
+	}}
+	r := New(Config{Events: events, Replacer: events})
+	policy := store.RetentionPolicy{
+		ScrubOutputAfter: time.Hour,
+		AnonymizeAfter:   24 * time.Hour,
+		DeleteAfter:      48 * time.Hour,
+	}
+	if err := r.enforceNamespace(context.Background(), "default", policy); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := events.events["deleted"]; ok {
+		t.Error("expected the oldest event to be deleted")
+	}
+	if got, want := len(events.replaced), 2; got != want {
+		t.Fatalf("got %d replaced events, want %d", got, want)
+	}
+
+	fresh := events.events["fresh"]
+	if fresh.Check.Output == "" || fresh.Entity.User == "" {
+		t.Error("expected the fresh event to be retained as it is")
+	}
+
+	scrubbed := events.events["scrubbed"]
+	if scrubbed.Check.Output != "" {
+		t.Errorf("expected the output to be scrubbed, got %q", scrubbed.Check.Output)
+	}
+	if scrubbed.Check.Command == "" || scrubbed.Entity.User == "" {
+		t.Error("expected only the output to be scrubbed")
+	}
+
+	anonymized := events.events["anonymized"]
+	if anonymized.Check.Output != "" || anonymized.Check.Command != "" || len(anonymized.Check.EnvVars) > 0 {
+		t.Error("expected the check to be anonymized")
+	}
+	if anonymized.Entity.User != "" || anonymized.Entity.System.Hostname != "" || len(anonymized.Labels) > 0 {
+		t.Error("expected the entity and labels to be anonymized")
+	}
+	if anonymized.Check.Status != 2 || len(anonymized.Check.History) != 1 {
+		t.Error("expected the status history to be kept")
+	}
+}
+
+func TestEnforceNamespaceUnchanged(t *testing.T) {
+	event := fixtureEvent("check1", 2*time.Hour)
+	event.Check.Output = ""
+	events := &testEventStore{events: map[string]*corev2.Event{"check1": event}}
+	r := New(Config{Events: events, Replacer: events})
+	policy := store.RetentionPolicy{ScrubOutputAfter: time.Hour}
+	if err := r.enforceNamespace(context.Background(), "default", policy); err != nil {
+		t.Fatal(err)
+	}
+	if len(events.replaced) > 0 {
+		t.Error("expected an already scrubbed event not to be replaced")
+	}
+}
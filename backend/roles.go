@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"fmt"
+)
+
+const (
+	// RoleAgentListener is the role of the backends that accept agent
+	// connections, with agentd.
+	RoleAgentListener = "agent-listener"
+
+	// RoleAPI is the role of the backends that serve the API, with apid.
+	RoleAPI = "api"
+
+	// RolePipeline is the role of the backends that process events and
+	// schedule checks, with eventd, keepalived, pipelined, schedulerd and
+	// tessend.
+	RolePipeline = "pipeline"
+)
+
+// Roles are the roles a backend can run.
+var Roles = []string{RoleAgentListener, RoleAPI, RolePipeline}
+
+// ValidateRoles returns an error if roles contains an unknown role.
+func ValidateRoles(roles []string) error {
+	for _, role := range roles {
+		if !isRole(role) {
+			return fmt.Errorf("unknown role %q, roles are %v", role, Roles)
+		}
+	}
+	return nil
+}
+
+func isRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole returns true if the backend runs role. Backends without roles run
+// every role.
+func (c *Config) HasRole(role string) bool {
+	if len(c.Roles) == 0 {
+		return true
+	}
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitRoles returns true if the backend doesn't run every role, in which
+// case it bridges its messages with the backends that run the other roles.
+func (c *Config) SplitRoles() bool {
+	for _, role := range Roles {
+		if !c.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
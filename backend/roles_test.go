@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"testing"
+)
+
+func TestValidateRoles(t *testing.T) {
+	if err := ValidateRoles(nil); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := ValidateRoles([]string{RoleAgentListener, RoleAPI}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := ValidateRoles([]string{RoleAPI, "scheduler"}); err == nil {
+		t.Error("expected an error for an unknown role")
+	}
+}
+
+func TestConfigRoles(t *testing.T) {
+	tests := []struct {
+		name      string
+		roles     []string
+		hasRoles  []string
+		splitRole bool
+	}{
+		{
+			name:     "every role by default",
+			hasRoles: Roles,
+		},
+		{
+			name:     "every role",
+			roles:    []string{RolePipeline, RoleAPI, RoleAgentListener},
+			hasRoles: Roles,
+		},
+		{
+			name:      "agent listener",
+			roles:     []string{RoleAgentListener},
+			hasRoles:  []string{RoleAgentListener},
+			splitRole: true,
+		},
+		{
+			name:      "api and pipeline",
+			roles:     []string{RoleAPI, RolePipeline},
+			hasRoles:  []string{RoleAPI, RolePipeline},
+			splitRole: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Roles: tt.roles}
+			for _, role := range Roles {
+				want := false
+				for _, r := range tt.hasRoles {
+					want = want || r == role
+				}
+				if got := config.HasRole(role); got != want {
+					t.Errorf("HasRole(%q) = %v, want %v", role, got, want)
+				}
+			}
+			if got := config.SplitRoles(); got != tt.splitRole {
+				t.Errorf("SplitRoles() = %v, want %v", got, tt.splitRole)
+			}
+		})
+	}
+}
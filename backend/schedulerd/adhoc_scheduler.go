@@ -61,6 +61,18 @@ func (a *AdhocScheduler) schedule() {
 		}
 		logger.WithFields(logFields).Debug("attempting to schedule ad hoc check")
 
+		if a.executor.paused.IsPaused(check.Namespace) {
+			// the request is dropped rather than returned to the queue, so
+			// that it isn't executed when scheduling is resumed
+			logger.WithFields(logFields).Warn("scheduling is paused in namespace, dropping ad hoc check")
+			if err := res.Ack(ctx); err != nil {
+				logger.WithError(err).
+					WithFields(logFields).
+					Error("error acknowledging dropped adhoc check")
+			}
+			continue
+		}
+
 		if err := a.executor.processCheck(ctx, &check); err != nil {
 			logger.WithError(err).WithFields(logFields).Error("error processing adhoc check request")
 			if nackErr := res.Nack(ctx); nackErr != nil {
@@ -6,8 +6,14 @@ import (
 
 	time "github.com/echlebek/timeproxy"
 	cron "github.com/robfig/cron/v3"
+	corev2 "github.com/sensu/core/v2"
 )
 
+// maxSubduedCronTimes is the maximum number of times of a cron schedule that
+// are skipped because the check is subdued at them, so that a check that is
+// subdued for good still wakes up its scheduler once in a while.
+const maxSubduedCronTimes = 1000
+
 // A CheckTimer handles starting and stopping timers for a given check
 type CheckTimer interface {
 	// C channel emits events when timer's duration has reached 0
@@ -20,6 +26,8 @@ type CheckTimer interface {
 	Next()
 	// Stop ends the timer
 	Stop()
+	// Deadline returns the time at which the timer fires next
+	Deadline() time.Time
 }
 
 // A IntervalTimer handles starting a stopping timers for a given check
@@ -27,6 +35,7 @@ type IntervalTimer struct {
 	interval time.Duration
 	splay    uint64
 	timer    *time.Timer
+	deadline time.Time
 }
 
 // NewIntervalTimer establishes new check timer given a name & an initial interval
@@ -55,6 +64,7 @@ func (timerPtr *IntervalTimer) SetDuration(cron string, interval uint) {
 func (timerPtr *IntervalTimer) Start() {
 	initOffset := timerPtr.calcInitialOffset()
 	timerPtr.timer = time.NewTimer(initOffset)
+	timerPtr.deadline = time.Now().Add(initOffset)
 }
 
 // Next reset's timer using interval
@@ -65,6 +75,12 @@ func (timerPtr *IntervalTimer) Next() {
 		default:
 		}
 	}
+	timerPtr.deadline = time.Now().Add(timerPtr.interval)
+}
+
+// Deadline returns the time at which the timer fires next
+func (timerPtr *IntervalTimer) Deadline() time.Time {
+	return timerPtr.deadline
 }
 
 // Stop ends the timer
@@ -87,20 +103,29 @@ func (timerPtr *IntervalTimer) calcInitialOffset() time.Duration {
 
 // A CronTimer handles starting and stopping timers for a given check
 type CronTimer struct {
-	next  time.Duration
-	timer *time.Timer
+	next     time.Duration
+	timer    *time.Timer
+	deadline time.Time
+	dst      dstPolicy
+	subdues  []*corev2.TimeWindowRepeated
 }
 
 // NewCronTimer establishes new check timer given a name & an initial interval
 func NewCronTimer(name string, cronStr string) *CronTimer {
-	diff, err := NextCronTime(time.Now(), cronStr)
+	return newCronTimer(name, cronStr, defaultDSTPolicy, nil)
+}
+
+// newCronTimer is like NewCronTimer, but follows the DST policy dst, and
+// skips the times at which the check is subdued by subdues.
+func newCronTimer(name string, cronStr string, dst dstPolicy, subdues []*corev2.TimeWindowRepeated) *CronTimer {
+	diff, err := nextCronTime(time.Now(), cronStr, dst, subdues)
 	// we shouldn't hit this error because we've already validated the cron string
 	// but log and exit cleanly to revert to the interval timer
 	if err != nil {
 		logger.WithError(err).Error("invalid cron, reverting to interval")
 		return nil
 	}
-	timer := &CronTimer{next: diff}
+	timer := &CronTimer{next: diff, dst: dst, subdues: subdues}
 	return timer
 }
 
@@ -111,7 +136,7 @@ func (timerPtr *CronTimer) C() <-chan time.Time {
 
 // SetDuration updates the interval in which timers are set
 func (timerPtr *CronTimer) SetDuration(cronStr string, interval uint) {
-	diff, err := NextCronTime(time.Now(), cronStr)
+	diff, err := nextCronTime(time.Now(), cronStr, timerPtr.dst, timerPtr.subdues)
 	// we shouldn't hit this error because we've already validated the cron string
 	// but log and exit cleanly to revert to the interval timer
 	if err != nil {
@@ -124,6 +149,7 @@ func (timerPtr *CronTimer) SetDuration(cronStr string, interval uint) {
 // Start sets up a new timer
 func (timerPtr *CronTimer) Start() {
 	timerPtr.timer = time.NewTimer(timerPtr.next)
+	timerPtr.deadline = time.Now().Add(timerPtr.next)
 }
 
 // Next reset's timer using interval
@@ -134,6 +160,12 @@ func (timerPtr *CronTimer) Next() {
 		default:
 		}
 	}
+	timerPtr.deadline = time.Now().Add(timerPtr.next)
+}
+
+// Deadline returns the time at which the timer fires next
+func (timerPtr *CronTimer) Deadline() time.Time {
+	return timerPtr.deadline
 }
 
 // Stop ends the timer
@@ -149,12 +181,32 @@ func (timerPtr *CronTimer) Stop() {
 // NextCronTime calculates how much time is between the current time and the
 // time indidcated by the cron string
 func NextCronTime(now time.Time, cronStr string) (time.Duration, error) {
+	return nextCronTime(now, cronStr, defaultDSTPolicy, nil)
+}
+
+// nextCronTime is like NextCronTime, but follows the DST policy dst, and
+// skips the times at which the check is subdued by subdues, so that the
+// scheduler isn't woken up during the subdued periods.
+func nextCronTime(now time.Time, cronStr string, dst dstPolicy, subdues []*corev2.TimeWindowRepeated) (time.Duration, error) {
 	schedule, err := cron.ParseStandard(cronStr)
 	if err != nil {
 		return 0, err
 	}
-	nextTime := schedule.Next(now)
+	nextTime := nextScheduleTime(schedule, now, dst)
+	for i := 0; i < maxSubduedCronTimes && !nextTime.IsZero() && subduedAt(subdues, nextTime); i++ {
+		nextTime = nextScheduleTime(schedule, nextTime, dst)
+	}
 	diff := nextTime.Sub(now)
 
 	return diff, nil
 }
+
+// subduedAt returns true if one of subdues subdues the check at t.
+func subduedAt(subdues []*corev2.TimeWindowRepeated, t time.Time) bool {
+	for _, subdue := range subdues {
+		if subdue.InWindows(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	corev2 "github.com/sensu/core/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,6 +40,24 @@ func TestNextCronTime(t *testing.T) {
 	assert.True(t, nextCron == 0)
 }
 
+func TestNextCronTimeSubdued(t *testing.T) {
+	now := time.Date(2023, 3, 1, 8, 30, 0, 0, time.UTC)
+	subdues := []*corev2.TimeWindowRepeated{
+		{Begin: "2023-01-01T09:00:00Z", End: "2023-01-01T17:59:59Z", Repeat: []string{corev2.RepeatPeriodDaily}},
+	}
+
+	// the hourly executions during the subdued period are skipped
+	next, err := nextCronTime(now, "0 * * * *", defaultDSTPolicy, subdues)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 3, 1, 18, 0, 0, 0, time.UTC), now.Add(next))
+
+	// a check that is subdued for good is still woken up
+	forever := []*corev2.TimeWindowRepeated{{Begin: "2023-01-01T00:00:00Z", End: "2023-01-01T23:59:59Z", Repeat: []string{corev2.RepeatPeriodDaily}}}
+	next, err = nextCronTime(now, "* * * * *", defaultDSTPolicy, forever)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(maxSubduedCronTimes+1)*time.Minute, next)
+}
+
 func TestSplay(t *testing.T) {
 	timer := NewIntervalTimer("check1", 10)
 
@@ -19,6 +19,7 @@ type CronScheduler struct {
 	cancel        context.CancelFunc
 	interrupt     chan *corev2.CheckConfig
 	stopWg        sync.WaitGroup
+	state         *scheduleState
 }
 
 // NewCronScheduler initializes a CronScheduler
@@ -28,6 +29,7 @@ func NewCronScheduler(ctx context.Context, check *corev2.CheckConfig, executor *
 		executor:      executor,
 		lastCronState: check.Cron,
 		interrupt:     make(chan *corev2.CheckConfig),
+		state:         newScheduleState(check, CronType),
 		logger: logger.WithFields(logrus.Fields{
 			"name":           check.Name,
 			"namespace":      check.Namespace,
@@ -44,14 +46,23 @@ func (s *CronScheduler) schedule(timer *CronTimer, executor *CheckExecutor) {
 
 	if s.check.IsSubdued() {
 		s.logger.Debug("check is subdued")
+		s.state.setResult(s.check, nil)
 		return
 	}
 
 	s.logger.Debug("check is not subdued")
 
-	if err := executor.processCheck(s.ctx, s.check); err != nil {
+	if executor.paused.IsPaused(s.check.Namespace) {
+		s.logger.Debug("scheduling is paused in namespace")
+		s.state.setPaused()
+		return
+	}
+
+	err := executor.processCheck(s.ctx, s.check)
+	if err != nil {
 		logger.Error(err)
 	}
+	s.state.setResult(s.check, err)
 }
 
 // Start starts the cron scheduler.
@@ -64,8 +75,9 @@ func (s *CronScheduler) Start() {
 func (s *CronScheduler) start() {
 	defer s.stopWg.Done()
 	s.logger.Info("starting new cron scheduler")
-	timer := NewCronTimer(s.check.Name, s.check.Cron)
+	timer := newCronTimer(s.check.Name, s.check.Cron, dstPolicyOf(s.check), s.check.Subdues)
 	timer.Start()
+	s.state.setNext(timer.Deadline())
 
 	for {
 		select {
@@ -119,11 +131,19 @@ func (s *CronScheduler) setLastState() {
 }
 
 func (s *CronScheduler) resetTimer(timer *CronTimer) {
+	timer.dst = dstPolicyOf(s.check)
+	timer.subdues = s.check.Subdues
 	timer.SetDuration(s.check.Cron, 0)
 	timer.Next()
+	s.state.setNext(timer.Deadline())
 }
 
 // Type returns the type of the cron scheduler.
 func (s *CronScheduler) Type() SchedulerType {
 	return CronType
 }
+
+// Status returns the status of the cron scheduler.
+func (s *CronScheduler) Status() ScheduleStatus {
+	return s.state.get()
+}
@@ -0,0 +1,177 @@
+package schedulerd
+
+import (
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+	corev2 "github.com/sensu/core/v2"
+)
+
+const (
+	// DSTGapAnnotation is the annotation of the cron checks that sets what
+	// happens to their executions scheduled at a wall clock time skipped by a
+	// daylight saving time transition: DSTGapRun or DSTGapSkip.
+	DSTGapAnnotation = "sensu.io/dst-gap"
+
+	// DSTOverlapAnnotation is the annotation of the cron checks that sets
+	// what happens to their executions scheduled at a wall clock time repeated
+	// by a daylight saving time transition: DSTOverlapOnce or
+	// DSTOverlapTwice.
+	DSTOverlapAnnotation = "sensu.io/dst-overlap"
+
+	// DSTGapRun runs the executions skipped by a transition once, at the end
+	// of the transition. It is the default.
+	DSTGapRun = "run"
+
+	// DSTGapSkip skips the executions skipped by a transition.
+	DSTGapSkip = "skip"
+
+	// DSTOverlapOnce runs the executions repeated by a transition only the
+	// first time. It is the default.
+	DSTOverlapOnce = "once"
+
+	// DSTOverlapTwice runs the executions repeated by a transition twice.
+	DSTOverlapTwice = "twice"
+
+	// starBit is set in the fields of the cron schedules written with a *.
+	starBit = 1 << 63
+
+	// everyHour are the bits of the hour field of the cron schedules that run
+	// every hour.
+	everyHour = 1<<24 - 1
+)
+
+// dstPolicy is what happens to the executions of a cron check around the
+// daylight saving time transitions of its time zone. The policies only apply
+// to the schedules at fixed hours: the schedules that run every hour follow
+// the elapsed time through the transitions.
+type dstPolicy struct {
+	gap     string
+	overlap string
+}
+
+// defaultDSTPolicy is the DST policy of the checks without DST annotations.
+var defaultDSTPolicy = dstPolicy{gap: DSTGapRun, overlap: DSTOverlapOnce}
+
+// dstPolicyOf returns the DST policy of check, from its annotations.
+func dstPolicyOf(check *corev2.CheckConfig) dstPolicy {
+	policy := defaultDSTPolicy
+	annotations := check.GetObjectMeta().Annotations
+	switch gap := annotations[DSTGapAnnotation]; gap {
+	case "":
+	case DSTGapRun, DSTGapSkip:
+		policy.gap = gap
+	default:
+		logger.WithField("check", check.Name).WithField(DSTGapAnnotation, gap).Warn("invalid DST gap policy, using the default")
+	}
+	switch overlap := annotations[DSTOverlapAnnotation]; overlap {
+	case "":
+	case DSTOverlapOnce, DSTOverlapTwice:
+		policy.overlap = overlap
+	default:
+		logger.WithField("check", check.Name).WithField(DSTOverlapAnnotation, overlap).Warn("invalid DST overlap policy, using the default")
+	}
+	return policy
+}
+
+// nextScheduleTime returns the next time after now at which schedule runs,
+// following policy around daylight saving time transitions, or the zero time
+// if it never runs again.
+func nextScheduleTime(schedule cron.Schedule, now time.Time, policy dstPolicy) time.Time {
+	spec, ok := schedule.(*cron.SpecSchedule)
+	if !ok || spec.Hour&starBit != 0 || spec.Hour&everyHour == everyHour {
+		return schedule.Next(now)
+	}
+	loc := spec.Location
+	if loc == time.Local {
+		loc = now.Location()
+	}
+
+	// The schedule is evaluated on the wall clock of its time zone, without
+	// transitions, then each wall clock time is mapped to the instants at
+	// which it occurs. The wall clock times repeated by a transition that
+	// has just happened are evaluated again, as they can occur once more.
+	wallSpec := *spec
+	wallSpec.Location = time.UTC
+	start := wallClock(now, loc)
+	if offsets := surroundingOffsets(start, loc); len(offsets) == 2 && offsets[0] > offsets[1] {
+		start = start.Add(-time.Duration(offsets[0]-offsets[1]) * time.Second)
+	}
+	for wall := wallSpec.Next(start); !wall.IsZero(); wall = wallSpec.Next(wall) {
+		instants := wallInstants(wall, loc)
+		switch len(instants) {
+		case 0:
+			if policy.gap == DSTGapSkip {
+				continue
+			}
+			instants = []time.Time{zoneTransition(wall, loc)}
+		case 2:
+			if policy.overlap == DSTOverlapOnce {
+				instants = instants[:1]
+			}
+		}
+		for _, instant := range instants {
+			if instant.After(now) {
+				return instant
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// wallClock returns the wall clock time of t in loc, in UTC.
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// wallInstants returns the instants at which the wall clock of loc shows
+// wall, in order: none if a transition skips wall, two if a transition repeats
+// it.
+func wallInstants(wall time.Time, loc *time.Location) []time.Time {
+	var instants []time.Time
+	for _, offset := range surroundingOffsets(wall, loc) {
+		instant := wall.Add(-time.Duration(offset) * time.Second).In(loc)
+		if wallClock(instant, loc).Equal(wall) {
+			instants = append(instants, instant)
+		}
+	}
+	if len(instants) == 2 && instants[1].Before(instants[0]) {
+		instants[0], instants[1] = instants[1], instants[0]
+	}
+	return instants
+}
+
+// surroundingOffsets returns the distinct UTC offsets of loc in effect a day
+// before and a day after the wall clock time wall. Transitions are months
+// apart, so these are all the offsets that wall can have.
+func surroundingOffsets(wall time.Time, loc *time.Location) []int {
+	_, before := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(24 * time.Hour).In(loc).Zone()
+	if before == after {
+		return []int{before}
+	}
+	return []int{before, after}
+}
+
+// zoneTransition returns the instant at which the transition of loc that
+// skips the wall clock time wall happens.
+func zoneTransition(wall time.Time, loc *time.Location) time.Time {
+	offsets := surroundingOffsets(wall, loc)
+	lo := wall.Add(-24 * time.Hour).Unix()
+	hi := wall.Add(24 * time.Hour).Unix()
+	if len(offsets) == 2 {
+		lo -= int64(offsets[0])
+		hi -= int64(offsets[1])
+	}
+	_, want := time.Unix(hi, 0).In(loc).Zone()
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if _, offset := time.Unix(mid, 0).In(loc).Zone(); offset == want {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return time.Unix(hi, 0).In(loc)
+}
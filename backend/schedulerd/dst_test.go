@@ -0,0 +1,127 @@
+package schedulerd
+
+import (
+	"testing"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextScheduleTimeDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	runOnce := dstPolicy{gap: DSTGapRun, overlap: DSTOverlapOnce}
+	skipTwice := dstPolicy{gap: DSTGapSkip, overlap: DSTOverlapTwice}
+	tests := []struct {
+		name   string
+		now    string
+		cron   string
+		policy dstPolicy
+		want   string
+	}{
+		{
+			name:   "skipped time runs at the end of the transition",
+			now:    "2012-03-11T00:00:00-05:00",
+			cron:   "30 2 * * *",
+			policy: runOnce,
+			want:   "2012-03-11T03:00:00-04:00",
+		},
+		{
+			name:   "skipped time is skipped",
+			now:    "2012-03-11T00:00:00-05:00",
+			cron:   "30 2 * * *",
+			policy: skipTwice,
+			want:   "2012-03-12T02:30:00-04:00",
+		},
+		{
+			name:   "skipped times run once",
+			now:    "2012-03-11T03:00:00-04:00",
+			cron:   "*/15 2 * * *",
+			policy: runOnce,
+			want:   "2012-03-12T02:00:00-04:00",
+		},
+		{
+			name:   "repeated time runs once",
+			now:    "2012-11-04T01:30:00-04:00",
+			cron:   "30 1 * * *",
+			policy: runOnce,
+			want:   "2012-11-05T01:30:00-05:00",
+		},
+		{
+			name:   "repeated time runs twice",
+			now:    "2012-11-04T01:30:00-04:00",
+			cron:   "30 1 * * *",
+			policy: skipTwice,
+			want:   "2012-11-04T01:30:00-05:00",
+		},
+		{
+			name:   "repeated time ran twice",
+			now:    "2012-11-04T01:30:00-05:00",
+			cron:   "30 1 * * *",
+			policy: skipTwice,
+			want:   "2012-11-05T01:30:00-05:00",
+		},
+		{
+			name:   "hourly schedule follows the elapsed time",
+			now:    "2012-11-04T01:00:00-04:00",
+			cron:   "0 * * * *",
+			policy: runOnce,
+			want:   "2012-11-04T01:00:00-05:00",
+		},
+		{
+			name:   "time zone of the schedule",
+			now:    "2012-03-11T05:00:00Z",
+			cron:   "CRON_TZ=America/New_York 30 2 * * *",
+			policy: runOnce,
+			want:   "2012-03-11T03:00:00-04:00",
+		},
+		{
+			name:   "no transition",
+			now:    "2012-06-04T01:00:00-04:00",
+			cron:   "0 5 * * *",
+			policy: runOnce,
+			want:   "2012-06-04T05:00:00-04:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := time.Parse(time.RFC3339, tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			schedule, err := cron.ParseStandard(tt.cron)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := nextScheduleTime(schedule, now.In(newYork), tt.policy)
+			if !got.Equal(want) {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestDSTPolicyOf(t *testing.T) {
+	check := corev2.FixtureCheckConfig("check")
+	assert.Equal(t, defaultDSTPolicy, dstPolicyOf(check))
+
+	check.Annotations = map[string]string{
+		DSTGapAnnotation:     DSTGapSkip,
+		DSTOverlapAnnotation: DSTOverlapTwice,
+	}
+	assert.Equal(t, dstPolicy{gap: DSTGapSkip, overlap: DSTOverlapTwice}, dstPolicyOf(check))
+
+	check.Annotations = map[string]string{
+		DSTGapAnnotation:     "sometimes",
+		DSTOverlapAnnotation: "thrice",
+	}
+	assert.Equal(t, defaultDSTPolicy, dstPolicyOf(check))
+}
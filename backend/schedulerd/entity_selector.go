@@ -0,0 +1,65 @@
+package schedulerd
+
+import (
+	"fmt"
+	"sort"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/selector"
+)
+
+// EntitySelectorAnnotation is the check annotation that holds the
+// entity_selector of a check: a label selector, e.g. "region in (eu, us)",
+// evaluated against the agent entities of the check namespace. The check
+// requests of a check with an entity selector are sent to the matching
+// entities only, and its subscriptions are not used to select entities.
+// Round robin checks don't support entity selectors.
+const EntitySelectorAnnotation = "sensu.io/entity_selector"
+
+// entitySelector returns the parsed entity selector of check, or nil if the
+// check doesn't have one.
+func entitySelector(check *corev2.CheckConfig) (*selector.Selector, error) {
+	expression := check.Annotations[EntitySelectorAnnotation]
+	if expression == "" {
+		return nil, nil
+	}
+	sel, err := selector.ParseLabelSelector(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %s", EntitySelectorAnnotation, err)
+	}
+	return sel, nil
+}
+
+// selectEntitySubscriptions returns the entity subscriptions of the agent
+// entities of the check namespace whose labels match sel.
+func (c *CheckExecutor) selectEntitySubscriptions(check *corev2.CheckConfig, sel *selector.Selector) []string {
+	if c.entityCache == nil {
+		return nil
+	}
+	var subscriptions []string
+	for _, entity := range c.entityCache.Get(check.Namespace) {
+		config := entity.Resource
+		if config.EntityClass != corev2.EntityAgentClass {
+			continue
+		}
+		if sel.Matches(config.Metadata.Labels) {
+			subscriptions = append(subscriptions, corev2.GetEntitySubscription(config.Metadata.Name))
+		}
+	}
+	sort.Strings(subscriptions)
+	return subscriptions
+}
+
+// checkSubscriptions returns the subscriptions to publish the check requests
+// of check to: the entity subscriptions of the entities selected by its
+// entity selector, if it has one, or else its expanded subscriptions.
+func (c *CheckExecutor) checkSubscriptions(check *corev2.CheckConfig) ([]string, error) {
+	sel, err := entitySelector(check)
+	if err != nil {
+		return nil, err
+	}
+	if sel == nil {
+		return c.expandSubscriptions(check), nil
+	}
+	return c.selectEntitySubscriptions(check, sel), nil
+}
@@ -0,0 +1,75 @@
+package schedulerd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	cachev2 "github.com/sensu/sensu-go/backend/store/cache/v2"
+)
+
+func TestCheckExecutorCheckSubscriptions(t *testing.T) {
+	eu := corev3.FixtureEntityConfig("eu")
+	eu.Metadata.Labels = map[string]string{"region": "eu", "role": "web"}
+	us := corev3.FixtureEntityConfig("us")
+	us.Metadata.Labels = map[string]string{"region": "us", "role": "web"}
+	db := corev3.FixtureEntityConfig("db")
+	db.Metadata.Labels = map[string]string{"region": "eu", "role": "db"}
+	proxy := corev3.FixtureEntityConfig("proxy")
+	proxy.EntityClass = corev2.EntityProxyClass
+	proxy.Metadata.Labels = map[string]string{"region": "eu", "role": "web"}
+
+	exec := &CheckExecutor{
+		entityCache: cachev2.NewFromResources([]*corev3.EntityConfig{eu, us, db, proxy}, false),
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "subscriptions are used without a selector",
+			want: []string{"linux"},
+		},
+		{
+			name:     "agent entities matching the selector are selected",
+			selector: "role == web",
+			want:     []string{"entity:eu", "entity:us"},
+		},
+		{
+			name:     "selector operations are intersected",
+			selector: "role == web && region in [eu]",
+			want:     []string{"entity:eu"},
+		},
+		{
+			name:     "no entities match the selector",
+			selector: "role == cache",
+		},
+		{
+			name:     "invalid selector",
+			selector: "role ==",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := corev2.FixtureCheckConfig("check1")
+			check.Subscriptions = []string{"linux"}
+			if tt.selector != "" {
+				check.Annotations = map[string]string{EntitySelectorAnnotation: tt.selector}
+			}
+			got, err := exec.checkSubscriptions(check)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
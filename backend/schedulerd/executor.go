@@ -2,6 +2,7 @@ package schedulerd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -23,6 +24,8 @@ type CheckExecutor struct {
 	store                  storev2.Interface
 	entityCache            EntityCache
 	secretsProviderManager *secrets.ProviderManager
+	paused                 *pausedNamespaces
+	pacer                  *publishPacer
 	force                  bool
 }
 
@@ -41,11 +44,14 @@ func (c *CheckExecutor) getEntities(ctx context.Context) ([]EntityCacheValue, er
 	return c.entityCache.Get(store.NewNamespaceFromContext(ctx)), nil
 }
 
-func (c *CheckExecutor) publishProxyCheckRequests(entities []*corev3.EntityConfig, check *corev2.CheckConfig) error {
-	return publishProxyCheckRequests(c, entities, check)
+func (c *CheckExecutor) publishProxyCheckRequests(ctx context.Context, entities []*corev3.EntityConfig, check *corev2.CheckConfig) error {
+	return publishProxyCheckRequests(ctx, c, entities, check)
 }
 
-func (c *CheckExecutor) execute(check *corev2.CheckConfig) error {
+// execute publishes the check requests of check to its subscriptions, at the
+// pace of the publish pacer. The delay of their publication is measured from
+// scheduled.
+func (c *CheckExecutor) execute(ctx context.Context, check *corev2.CheckConfig, scheduled time.Time) error {
 	// Ensure the check is configured to publish check requests
 	if !c.force && !check.Publish {
 		return nil
@@ -57,7 +63,17 @@ func (c *CheckExecutor) execute(check *corev2.CheckConfig) error {
 		return err
 	}
 
-	for _, sub := range check.Subscriptions {
+	subscriptions, err := c.checkSubscriptions(check)
+	if err != nil {
+		return err
+	}
+	c.pacer.Sort(subscriptions)
+
+	for _, sub := range subscriptions {
+		if waitErr := c.pacer.Wait(ctx, sub); waitErr != nil {
+			return waitErr
+		}
+
 		topic := messaging.SubscriptionTopic(check.Namespace, sub)
 		logger.WithFields(logrus.Fields{
 			"check": check.Name,
@@ -67,12 +83,60 @@ func (c *CheckExecutor) execute(check *corev2.CheckConfig) error {
 		if pubErr := c.bus.Publish(topic, request); pubErr != nil {
 			logger.WithError(pubErr).Error("error publishing check request")
 			err = pubErr
+			continue
 		}
+		checkRequestPublishDelay.WithLabelValues(c.pacer.Priority(sub)).Observe(time.Since(scheduled).Seconds())
 	}
 
 	return err
 }
 
+// expandSubscriptions returns the subscriptions of check, where wildcard
+// subscriptions are replaced by the matching subscriptions of the entities
+// of the check namespace. Invalid wildcard subscriptions are skipped.
+func (c *CheckExecutor) expandSubscriptions(check *corev2.CheckConfig) []string {
+	var entities []EntityCacheValue
+	subscriptions := make([]string, 0, len(check.Subscriptions))
+	seen := make(map[string]struct{}, len(check.Subscriptions))
+	add := func(sub string) {
+		if _, ok := seen[sub]; !ok {
+			seen[sub] = struct{}{}
+			subscriptions = append(subscriptions, sub)
+		}
+	}
+	for _, sub := range check.Subscriptions {
+		if !messaging.IsSubscriptionPattern(sub) {
+			add(sub)
+			continue
+		}
+		err := messaging.ValidateSubscriptionPattern(sub)
+		if err == nil && messaging.IsNegatedSubscription(sub) {
+			err = errors.New("check subscriptions can't be negated")
+		}
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"check":        check.Name,
+				"subscription": sub,
+			}).WithError(err).Warn("ignoring invalid check subscription")
+			continue
+		}
+		if entities == nil && c.entityCache != nil {
+			entities = c.entityCache.Get(check.Namespace)
+		}
+		for _, entity := range entities {
+			for _, entitySub := range entity.Resource.Subscriptions {
+				if entitySub == "" || messaging.IsSubscriptionPattern(entitySub) || messaging.IsNegatedSubscription(entitySub) {
+					continue
+				}
+				if messaging.MatchSubscription(sub, entitySub) {
+					add(entitySub)
+				}
+			}
+		}
+	}
+	return subscriptions
+}
+
 func (c *CheckExecutor) executeOnEntity(check *corev2.CheckConfig, entity string) error {
 	// Ensure the check is configured to publish check requests
 	if !check.Publish {
@@ -120,7 +184,10 @@ func hookIsRelevant(hook *corev2.HookConfig, check *corev2.CheckConfig) bool {
 	return false
 }
 
-func publishProxyCheckRequests(e *CheckExecutor, entities []*corev3.EntityConfig, check *corev2.CheckConfig) error {
+// publishProxyCheckRequests publishes the check requests of check for each
+// proxy entity, spread over the splay of check. The check requests of an
+// entity are scheduled at the beginning of its splay.
+func publishProxyCheckRequests(ctx context.Context, e *CheckExecutor, entities []*corev3.EntityConfig, check *corev2.CheckConfig) error {
 	var splay time.Duration
 	if check.ProxyRequests.Splay {
 		var err error
@@ -136,12 +203,13 @@ func publishProxyCheckRequests(e *CheckExecutor, entities []*corev3.EntityConfig
 
 	for _, entity := range entities {
 		time.Sleep(splay)
+		scheduled := time.Now()
 		substitutedCheck, err := substituteProxyEntityTokens(entity, check)
 		if err != nil {
 			logger.WithFields(fields).WithError(err).Errorf("could not substitute tokens for proxy entity %q", entity.Metadata.Name)
 			continue
 		}
-		if err := e.execute(substitutedCheck); err != nil {
+		if err := e.execute(ctx, substitutedCheck, scheduled); err != nil {
 			logger.WithFields(fields).WithError(err).Errorf("could not send check request for entity %q", entity.Metadata.Name)
 			continue
 		}
@@ -154,6 +222,16 @@ func processCheck(ctx context.Context, executor *CheckExecutor, check *corev2.Ch
 		"check":     check.Name,
 		"namespace": check.Namespace,
 	}
+	scheduled := time.Now()
+
+	// Spread the checks scheduled at the same time over the dispatch window,
+	// unless they are executed on demand
+	if !executor.force {
+		if err := executor.pacer.Jitter(ctx); err != nil {
+			return err
+		}
+	}
+
 	if check.ProxyRequests != nil {
 		// get entities by namespace
 		entities, err := executor.getEntities(ctx)
@@ -162,14 +240,14 @@ func processCheck(ctx context.Context, executor *CheckExecutor, check *corev2.Ch
 		}
 		// publish proxy requests on matching entities
 		if matchedEntities := matchEntities(entities, check.ProxyRequests); len(matchedEntities) != 0 {
-			if err := executor.publishProxyCheckRequests(matchedEntities, check); err != nil {
+			if err := executor.publishProxyCheckRequests(ctx, matchedEntities, check); err != nil {
 				logger.WithFields(fields).WithError(err).Error("error publishing proxy check requests")
 			}
 		} else {
 			logger.WithFields(fields).Warn("no matching entities, check will not be published")
 		}
 	} else {
-		return executor.execute(check)
+		return executor.execute(ctx, check, scheduled)
 	}
 	return nil
 }
@@ -12,6 +12,7 @@ import (
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/backend/messaging"
+	cachev2 "github.com/sensu/sensu-go/backend/store/cache/v2"
 )
 
 func TestPublishProxyCheckRequest(t *testing.T) {
@@ -68,7 +69,7 @@ func TestPublishProxyCheckRequest(t *testing.T) {
 
 	}()
 
-	assert.NoError(scheduler.exec.publishProxyCheckRequests([]*corev3.EntityConfig{entity1, entity2}, check))
+	assert.NoError(scheduler.exec.publishProxyCheckRequests(ctx, []*corev3.EntityConfig{entity1, entity2}, check))
 
 	wg.Wait()
 }
@@ -123,7 +124,7 @@ func TestPublishProxyCheckRequestsInterval(t *testing.T) {
 		}
 	}()
 
-	assert.NoError(scheduler.exec.publishProxyCheckRequests(entities, check))
+	assert.NoError(scheduler.exec.publishProxyCheckRequests(ctx, entities, check))
 }
 
 func TestPublishProxyCheckRequestsCron(t *testing.T) {
@@ -175,7 +176,7 @@ func TestPublishProxyCheckRequestsCron(t *testing.T) {
 		}
 	}()
 
-	assert.NoError(scheduler.exec.publishProxyCheckRequests(entities, check))
+	assert.NoError(scheduler.exec.publishProxyCheckRequests(ctx, entities, check))
 }
 
 func TestCheckBuildRequestInterval(t *testing.T) {
@@ -248,3 +249,23 @@ func TestCheckBuildRequestCron(t *testing.T) {
 
 	assert.NoError(scheduler.msgBus.Stop())
 }
+
+func TestCheckExecutorExpandSubscriptions(t *testing.T) {
+	linux := corev3.FixtureEntityConfig("linux")
+	linux.Subscriptions = []string{"linux-prod", "linux-dev", "!canary"}
+	windows := corev3.FixtureEntityConfig("windows")
+	windows.Subscriptions = []string{"windows-prod", "linux-prod"}
+	other := corev3.FixtureEntityConfig("other")
+	other.Metadata.Namespace = "other"
+	other.Subscriptions = []string{"linux-other"}
+
+	exec := &CheckExecutor{
+		entityCache: cachev2.NewFromResources([]*corev3.EntityConfig{linux, windows, other}, false),
+	}
+
+	check := corev2.FixtureCheckConfig("check1")
+	check.Subscriptions = []string{"linux-*", "*-prod", "*", "!linux-*", "linux-?-*", "static"}
+
+	got := exec.expandSubscriptions(check)
+	assert.ElementsMatch(t, []string{"linux-prod", "linux-dev", "windows-prod", "static"}, got)
+}
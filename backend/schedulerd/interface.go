@@ -19,6 +19,9 @@ type Scheduler interface {
 
 	// Type returns the scheduler type
 	Type() SchedulerType
+
+	// Status returns the status of the scheduler.
+	Status() ScheduleStatus
 }
 
 // SchedulerType represents the type of a scheduler.
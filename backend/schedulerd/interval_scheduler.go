@@ -19,6 +19,7 @@ type IntervalScheduler struct {
 	cancel            context.CancelFunc
 	interrupt         chan *corev2.CheckConfig
 	stopWg            sync.WaitGroup
+	state             *scheduleState
 }
 
 // NewIntervalScheduler initializes an IntervalScheduler
@@ -28,6 +29,7 @@ func NewIntervalScheduler(ctx context.Context, check *corev2.CheckConfig, execut
 		executor:          executor,
 		lastIntervalState: check.Interval,
 		interrupt:         make(chan *corev2.CheckConfig),
+		state:             newScheduleState(check, IntervalType),
 		logger: logger.WithFields(logrus.Fields{
 			"name":           check.Name,
 			"namespace":      check.Namespace,
@@ -44,14 +46,23 @@ func (s *IntervalScheduler) schedule(timer CheckTimer, executor *CheckExecutor)
 
 	if s.check.IsSubdued() {
 		s.logger.Debug("check is subdued")
+		s.state.setResult(s.check, nil)
 		return
 	}
 
 	s.logger.Debug("check is not subdued")
 
-	if err := executor.processCheck(s.ctx, s.check); err != nil {
+	if executor.paused.IsPaused(s.check.Namespace) {
+		s.logger.Debug("scheduling is paused in namespace")
+		s.state.setPaused()
+		return
+	}
+
+	err := executor.processCheck(s.ctx, s.check)
+	if err != nil {
 		logger.WithError(err).Error("error executing check")
 	}
+	s.state.setResult(s.check, err)
 }
 
 // Start starts the IntervalScheduler.
@@ -67,6 +78,7 @@ func (s *IntervalScheduler) start() {
 	timer := NewIntervalTimer(s.check.Name, uint(s.check.Interval))
 
 	timer.Start()
+	s.state.setNext(timer.Deadline())
 
 	for {
 		select {
@@ -125,9 +137,15 @@ func (s *IntervalScheduler) setLastState() {
 func (s *IntervalScheduler) resetTimer(timer CheckTimer) {
 	timer.SetDuration("", uint(s.check.Interval))
 	timer.Next()
+	s.state.setNext(timer.Deadline())
 }
 
 // Type returns the type of the interval scheduler.
 func (s *IntervalScheduler) Type() SchedulerType {
 	return IntervalType
 }
+
+// Status returns the status of the interval scheduler.
+func (s *IntervalScheduler) Status() ScheduleStatus {
+	return s.state.get()
+}
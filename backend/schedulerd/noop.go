@@ -6,26 +6,41 @@ import (
 
 // NoopScheduler does not schedule checks
 // but serves as a placeholder
-type NoopScheduler SchedulerType
+type NoopScheduler struct {
+	typ   SchedulerType
+	state *scheduleState
+}
 
-func NewNoopScheduler(typ SchedulerType) NoopScheduler {
-	return NoopScheduler(typ)
+// NewNoopScheduler initializes a NoopScheduler for a check that can't be
+// scheduled.
+func NewNoopScheduler(check *corev2.CheckConfig, typ SchedulerType) *NoopScheduler {
+	state := newScheduleState(check, typ)
+	state.status.LastResult = ResultUnsupported
+	return &NoopScheduler{
+		typ:   typ,
+		state: state,
+	}
 }
 
 // Start starts the scheduler.
-func (s NoopScheduler) Start() {
+func (s *NoopScheduler) Start() {
 }
 
 // Interrupt nothing
-func (s NoopScheduler) Interrupt(check *corev2.CheckConfig) {
+func (s *NoopScheduler) Interrupt(check *corev2.CheckConfig) {
 }
 
 // Stop Nothing
-func (s NoopScheduler) Stop() error {
+func (s *NoopScheduler) Stop() error {
 	return nil
 }
 
 // Type returns underlying type of the NoopScheduler
-func (s NoopScheduler) Type() SchedulerType {
-	return SchedulerType(s)
+func (s *NoopScheduler) Type() SchedulerType {
+	return s.typ
+}
+
+// Status returns the status of the NoopScheduler.
+func (s *NoopScheduler) Status() ScheduleStatus {
+	return s.state.get()
 }
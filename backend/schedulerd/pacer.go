@@ -0,0 +1,159 @@
+package schedulerd
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+
+	time "github.com/echlebek/timeproxy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sensu/sensu-go/backend/messaging"
+	"golang.org/x/time/rate"
+)
+
+const (
+	checkRequestPublishDelayName = "sensu_go_check_request_publish_delay_seconds"
+	checkRequestsWaitingName     = "sensu_go_check_requests_waiting"
+)
+
+// The priorities of the check requests, by which they are published when the
+// publish rate is limited.
+const (
+	priorityHigh   = "high"
+	priorityNormal = "normal"
+)
+
+var (
+	checkRequestPublishDelay = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    checkRequestPublishDelayName,
+			Help:    "Delay between the scheduled execution of the checks and the publication of their check requests in seconds, per priority",
+			Buckets: []float64{0.005, 0.01, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"priority"},
+	)
+	checkRequestsWaiting = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: checkRequestsWaitingName,
+			Help: "The number of check requests waiting to be published, per priority",
+		},
+		[]string{"priority"},
+	)
+)
+
+// publishPacer paces the check requests published by schedulerd, so that the
+// checks scheduled at the same time, like on the minute, don't flood the
+// message bus. The executions of the checks are spread with a random jitter
+// over the dispatch window, and the check requests of the priority
+// subscriptions are published before the others when the rate is limited. A
+// nil publishPacer doesn't pace the check requests.
+type publishPacer struct {
+	limiter  *rate.Limiter
+	window   time.Duration
+	priority []string
+	high     chan struct{}
+	normal   chan struct{}
+}
+
+// newPublishPacer returns a publishPacer that publishes at most limit check
+// requests per second, and delays the executions of the checks by up to
+// window. Check requests are not rate limited if limit is zero.
+func newPublishPacer(limit float64, window time.Duration, priority []string) *publishPacer {
+	l := rate.Inf
+	if limit > 0 {
+		l = rate.Limit(limit)
+	}
+	return &publishPacer{
+		limiter:  rate.NewLimiter(l, 1),
+		window:   window,
+		priority: priority,
+		high:     make(chan struct{}),
+		normal:   make(chan struct{}),
+	}
+}
+
+// Run lets the waiting check requests be published at the pace of the rate
+// limit, those of the priority subscriptions first, until ctx is done.
+func (p *publishPacer) Run(ctx context.Context) {
+	if p == nil || p.limiter.Limit() == rate.Inf {
+		return
+	}
+	for {
+		if err := p.limiter.Wait(ctx); err != nil {
+			return
+		}
+		select {
+		case p.high <- struct{}{}:
+			continue
+		default:
+		}
+		select {
+		case p.high <- struct{}{}:
+		case p.normal <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Jitter waits for a random duration within the dispatch window, or until ctx
+// is done.
+func (p *publishPacer) Jitter(ctx context.Context) error {
+	if p == nil || p.window <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(p.window))))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Wait waits until the check request of the subscription sub can be
+// published, or until ctx is done.
+func (p *publishPacer) Wait(ctx context.Context, sub string) error {
+	if p == nil || p.limiter.Limit() == rate.Inf {
+		return nil
+	}
+	priority := p.Priority(sub)
+	grant := p.normal
+	if priority == priorityHigh {
+		grant = p.high
+	}
+	gauge := checkRequestsWaiting.WithLabelValues(priority)
+	gauge.Inc()
+	defer gauge.Dec()
+	select {
+	case <-grant:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Priority returns the priority of the check requests of the subscription
+// sub.
+func (p *publishPacer) Priority(sub string) string {
+	if p == nil {
+		return priorityNormal
+	}
+	for _, pattern := range p.priority {
+		if messaging.MatchSubscription(pattern, sub) {
+			return priorityHigh
+		}
+	}
+	return priorityNormal
+}
+
+// Sort moves the priority subscriptions of subs first, in place.
+func (p *publishPacer) Sort(subs []string) {
+	if p == nil || len(p.priority) == 0 {
+		return
+	}
+	sort.SliceStable(subs, func(i, j int) bool {
+		return p.Priority(subs[i]) == priorityHigh && p.Priority(subs[j]) != priorityHigh
+	})
+}
@@ -0,0 +1,89 @@
+package schedulerd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishPacerNoLimit(t *testing.T) {
+	ctx := context.Background()
+	for _, pacer := range []*publishPacer{nil, newPublishPacer(0, 0, nil)} {
+		go pacer.Run(ctx)
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, pacer.Wait(ctx, "linux"))
+		}
+		assert.NoError(t, pacer.Jitter(ctx))
+		assert.Equal(t, priorityNormal, pacer.Priority("linux"))
+	}
+}
+
+func TestPublishPacerRateLimits(t *testing.T) {
+	pacer := newPublishPacer(20, 0, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pacer.Run(ctx)
+
+	begin := time.Now()
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, pacer.Wait(ctx, "linux"))
+	}
+	// the first check request is published at once, the next ones every 50ms
+	if elapsed := time.Since(begin); elapsed < 150*time.Millisecond {
+		t.Errorf("check requests were published too fast: %s", elapsed)
+	}
+}
+
+func TestPublishPacerPriority(t *testing.T) {
+	pacer := newPublishPacer(20, 0, []string{"critical", "entity:*"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	published := make(chan string, 4)
+	wait := func(sub string) {
+		if err := pacer.Wait(ctx, sub); err == nil {
+			published <- sub
+		}
+	}
+	go wait("linux")
+	go wait("windows")
+	time.Sleep(20 * time.Millisecond)
+	go wait("critical")
+	go wait("entity:db")
+	time.Sleep(20 * time.Millisecond)
+	go pacer.Run(ctx)
+
+	// the check requests of the priority subscriptions are published first,
+	// even though they waited less
+	got := []string{<-published, <-published, <-published, <-published}
+	assert.ElementsMatch(t, []string{"critical", "entity:db"}, got[:2])
+	assert.ElementsMatch(t, []string{"linux", "windows"}, got[2:])
+}
+
+func TestPublishPacerWaitCanceled(t *testing.T) {
+	pacer := newPublishPacer(1, time.Hour, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, pacer.Wait(ctx, "linux"), context.Canceled)
+	assert.ErrorIs(t, pacer.Jitter(ctx), context.Canceled)
+}
+
+func TestPublishPacerJitter(t *testing.T) {
+	pacer := newPublishPacer(0, 50*time.Millisecond, nil)
+	begin := time.Now()
+	assert.NoError(t, pacer.Jitter(context.Background()))
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("jitter exceeded the dispatch window: %s", elapsed)
+	}
+}
+
+func TestPublishPacerSort(t *testing.T) {
+	pacer := newPublishPacer(10, 0, []string{"critical-*"})
+	subs := []string{"linux", "critical-db", "windows", "critical-web"}
+	pacer.Sort(subs)
+	assert.Equal(t, []string{"critical-db", "critical-web", "linux", "windows"}, subs)
+	assert.Equal(t, priorityHigh, pacer.Priority("critical-db"))
+	assert.Equal(t, priorityNormal, pacer.Priority("linux"))
+}
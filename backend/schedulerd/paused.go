@@ -0,0 +1,59 @@
+package schedulerd
+
+import (
+	"sort"
+	"sync"
+
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// pausedNamespaces is the set of namespaces in which check scheduling is
+// paused. A nil *pausedNamespaces pauses nothing.
+type pausedNamespaces struct {
+	mu  sync.RWMutex
+	set map[string]struct{}
+}
+
+func newPausedNamespaces() *pausedNamespaces {
+	return &pausedNamespaces{set: make(map[string]struct{})}
+}
+
+// Update replaces the set of paused namespaces with the namespaces that are
+// paused, and returns the namespaces that were paused and resumed.
+func (p *pausedNamespaces) Update(namespaces []*corev3.Namespace) (paused, resumed []string) {
+	next := make(map[string]struct{})
+	for _, namespace := range namespaces {
+		if store.SchedulingPaused(namespace) {
+			next[namespace.Metadata.Name] = struct{}{}
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for name := range next {
+		if _, ok := p.set[name]; !ok {
+			paused = append(paused, name)
+		}
+	}
+	for name := range p.set {
+		if _, ok := next[name]; !ok {
+			resumed = append(resumed, name)
+		}
+	}
+	p.set = next
+	sort.Strings(paused)
+	sort.Strings(resumed)
+	return paused, resumed
+}
+
+// IsPaused returns whether check scheduling is paused in namespace.
+func (p *pausedNamespaces) IsPaused(namespace string) bool {
+	if p == nil {
+		return false
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.set[namespace]
+	return ok
+}
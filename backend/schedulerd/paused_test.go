@@ -0,0 +1,41 @@
+package schedulerd
+
+import (
+	"testing"
+
+	corev3 "github.com/sensu/core/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+func pausedNamespace(name string) *corev3.Namespace {
+	namespace := corev3.FixtureNamespace(name)
+	namespace.Metadata.Annotations = map[string]string{store.SchedulingPausedAnnotation: "true"}
+	return namespace
+}
+
+func TestPausedNamespaces(t *testing.T) {
+	p := newPausedNamespaces()
+
+	paused, resumed := p.Update([]*corev3.Namespace{
+		corev3.FixtureNamespace("default"),
+		pausedNamespace("acme"),
+	})
+	assert.Equal(t, []string{"acme"}, paused)
+	assert.Empty(t, resumed)
+	assert.True(t, p.IsPaused("acme"))
+	assert.False(t, p.IsPaused("default"))
+
+	paused, resumed = p.Update([]*corev3.Namespace{
+		pausedNamespace("default"),
+		corev3.FixtureNamespace("acme"),
+	})
+	assert.Equal(t, []string{"default"}, paused)
+	assert.Equal(t, []string{"acme"}, resumed)
+	assert.False(t, p.IsPaused("acme"))
+	assert.True(t, p.IsPaused("default"))
+
+	var nilPaused *pausedNamespaces
+	assert.False(t, nilPaused.IsPaused("default"))
+}
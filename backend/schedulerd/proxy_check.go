@@ -79,12 +79,13 @@ func calculateSplayInterval(check *corev2.CheckConfig, numEntities int) (time.Du
 		if err != nil {
 			return 0, err
 		}
+		dst := dstPolicyOf(check)
 		now := time.Now()
-		then := schedule.Next(now)
+		then := nextScheduleTime(schedule, now, dst)
 		next = then.Sub(now)
 		if next < 5*time.Second {
 			now = time.Now().Add(next + time.Second)
-			then = schedule.Next(now)
+			then = nextScheduleTime(schedule, now, dst)
 			next = then.Sub(now)
 		}
 	}
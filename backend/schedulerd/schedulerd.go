@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	time "github.com/echlebek/timeproxy"
 	"github.com/prometheus/client_golang/prometheus"
@@ -55,8 +56,11 @@ type Schedulerd struct {
 	entityCache            EntityCache
 	secretsProviderManager *secrets.ProviderManager
 	queue                  queue.Client
+	pacer                  *publishPacer
 
 	checks         namespacedChecks
+	paused         *pausedNamespaces
+	schedulersMu   sync.RWMutex
 	schedulers     map[string]Scheduler
 	adhocScheduler *AdhocScheduler
 }
@@ -68,6 +72,20 @@ type Config struct {
 	SecretsProviderManager *secrets.ProviderManager
 	RefreshInterval        time.Duration
 	Queue                  queue.Client
+
+	// PublishRate is the maximum number of check requests published per
+	// second. Zero means no limit.
+	PublishRate float64
+
+	// DispatchWindow is the window over which the executions of the checks
+	// scheduled at the same time are spread, with a random jitter. Zero
+	// means no jitter.
+	DispatchWindow time.Duration
+
+	// PrioritySubscriptions are the subscriptions, or subscription patterns,
+	// whose check requests are published first when the publish rate is
+	// limited.
+	PrioritySubscriptions []string
 }
 
 // New creates a new Schedulerd.
@@ -79,8 +97,10 @@ func New(ctx context.Context, c Config) (*Schedulerd, error) {
 		errChan:                make(chan error, 1),
 		secretsProviderManager: c.SecretsProviderManager,
 		queue:                  c.Queue,
+		pacer:                  newPublishPacer(c.PublishRate, c.DispatchWindow, c.PrioritySubscriptions),
 
 		checks:     make(namespacedChecks),
+		paused:     newPausedNamespaces(),
 		schedulers: make(map[string]Scheduler),
 	}
 	if s.refreshInterval <= 0 {
@@ -101,11 +121,14 @@ func (s *Schedulerd) Start() error {
 	_ = prometheus.Register(intervalCounter)
 	_ = prometheus.Register(cronCounter)
 	_ = prometheus.Register(schedRefreshDuration)
+	_ = prometheus.Register(checkRequestPublishDelay)
+	_ = prometheus.Register(checkRequestsWaiting)
 	return s.start()
 }
 
 // start initializes schedulerd and begins polling for scheduling state changes
 func (s *Schedulerd) start() error {
+	go s.pacer.Run(s.ctx)
 	s.adhocScheduler = NewAdhocScheduler(s.ctx, s.queue, s.makeExecutor())
 	s.adhocScheduler.Start()
 	if err := s.refresh(); err != nil {
@@ -132,6 +155,19 @@ func (s *Schedulerd) start() error {
 func (s *Schedulerd) refresh() error {
 	timer := prometheus.NewTimer(schedRefreshDuration)
 	defer timer.ObserveDuration()
+	namespaceStore := storev2.Of[*corev3.Namespace](s.store)
+	namespaces, err := namespaceStore.List(s.ctx, storev2.ID{}, nil)
+	if err != nil {
+		return err
+	}
+	paused, resumed := s.paused.Update(namespaces)
+	if len(paused) > 0 {
+		logger.WithField("namespaces", paused).Warn("paused check scheduling")
+	}
+	if len(resumed) > 0 {
+		logger.WithField("namespaces", resumed).Warn("resumed check scheduling")
+	}
+
 	checkStore := storev2.Of[*corev2.CheckConfig](s.store)
 	next, err := checkStore.List(s.ctx, corev2.ObjectMeta{}, nil)
 	if err != nil {
@@ -139,6 +175,9 @@ func (s *Schedulerd) refresh() error {
 	}
 	added, changed, removed := s.checks.Update(next)
 
+	s.schedulersMu.Lock()
+	defer s.schedulersMu.Unlock()
+
 	checksAdded := make([]string, len(added))
 	checksChanged := make([]string, len(changed))
 	checksRemoved := make([]string, len(removed))
@@ -219,13 +258,13 @@ func (s *Schedulerd) makeScheduler(check *corev2.CheckConfig) Scheduler {
 	case CronType:
 		scheduler = NewCronScheduler(s.ctx, check, s.makeExecutor())
 	case RoundRobinIntervalType:
-		scheduler = NewNoopScheduler(RoundRobinIntervalType)
+		scheduler = NewNoopScheduler(check, RoundRobinIntervalType)
 		logger.WithFields(logrus.Fields{"namespace": check.Namespace, "check": check.Name}).
 			Error("checks configured with round robin enabled are not supported in this version of sensu. check will not be scheduled.")
 	case RoundRobinCronType:
 		logger.WithFields(logrus.Fields{"namespace": check.Namespace, "check": check.Name}).
 			Error("checks configured with round robin enabled are not supported in this version of sensu. check will not be scheduled.")
-		scheduler = NewNoopScheduler(RoundRobinCronType)
+		scheduler = NewNoopScheduler(check, RoundRobinCronType)
 	default:
 		logger.Error("bad scheduler type, falling back to interval scheduler")
 		scheduler = NewIntervalScheduler(s.ctx, check, s.makeExecutor())
@@ -234,7 +273,10 @@ func (s *Schedulerd) makeScheduler(check *corev2.CheckConfig) Scheduler {
 }
 
 func (s *Schedulerd) makeExecutor() *CheckExecutor {
-	return NewCheckExecutor(s.bus, s.store, s.entityCache, s.secretsProviderManager)
+	executor := NewCheckExecutor(s.bus, s.store, s.entityCache, s.secretsProviderManager)
+	executor.paused = s.paused
+	executor.pacer = s.pacer
+	return executor
 }
 
 // Stop the scheduler daemon.
@@ -135,6 +135,34 @@ func TestSchedulerdAdhoc(t *testing.T) {
 	wg.Wait()
 }
 
+func TestAdhocSchedulerPaused(t *testing.T) {
+	check := corev2.FixtureCheckConfig("adhoc")
+	checkB, _ := json.Marshal(check)
+
+	acked := make(chan struct{})
+	mockQRes := &mockqueue.MockReservation{}
+	mockQRes.On("Item").Return(queue.Item{ID: "aaa", Queue: adhocQueueName, Value: checkB})
+	mockQRes.On("Ack", mock.Anything).Run(func(mock.Arguments) { close(acked) }).Return(nil)
+	never := make(chan time.Time)
+	mockQ := &mockqueue.MockQueue{}
+	mockQ.On("Reserve", mock.Anything, adhocQueueName).Return(mockQRes, nil).Once()
+	mockQ.On("Reserve", mock.Anything, adhocQueueName).WaitUntil(never)
+
+	paused := newPausedNamespaces()
+	paused.Update([]*corev3.Namespace{pausedNamespace("default")})
+	// the executor has no bus, so the check would panic if it was executed
+	scheduler := NewAdhocScheduler(context.Background(), mockQ, &CheckExecutor{paused: paused})
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	select {
+	case <-acked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the ad hoc check was not dropped")
+	}
+	mockQRes.AssertNotCalled(t, "Nack", mock.Anything)
+}
+
 func stubStoreForCheck(check *corev2.CheckConfig) storev2.Interface {
 	stor := &mockstore.V2MockStore{}
 	cs := &mockstore.ConfigStore{}
@@ -163,7 +191,10 @@ func stubStoreForCheck(check *corev2.CheckConfig) storev2.Interface {
 		[]*corev3.EntityConfig{},
 		nil,
 	)
+	ns := &mockstore.NamespaceStore{}
+	ns.On("List", mock.Anything, mock.Anything).Return([]*corev3.Namespace{corev3.FixtureNamespace("default")}, nil)
 	stor.On("GetConfigStore").Return(cs)
 	stor.On("GetEntityConfigStore").Return(es)
+	stor.On("GetNamespaceStore").Return(ns)
 	return stor
 }
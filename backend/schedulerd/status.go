@@ -0,0 +1,150 @@
+package schedulerd
+
+import (
+	"sort"
+	"sync"
+
+	time "github.com/echlebek/timeproxy"
+	corev2 "github.com/sensu/core/v2"
+)
+
+const (
+	// ResultPublished means that check requests were published.
+	ResultPublished = "published"
+
+	// ResultSubdued means that the check was not executed, because it is
+	// subdued.
+	ResultSubdued = "subdued"
+
+	// ResultNotPublished means that the check was not executed, because it is
+	// not configured to publish check requests.
+	ResultNotPublished = "not_published"
+
+	// ResultError means that publishing check requests failed.
+	ResultError = "error"
+
+	// ResultPaused means that the check was not executed, because scheduling
+	// is paused in its namespace.
+	ResultPaused = "paused"
+
+	// ResultUnsupported means that the check is never executed, because its
+	// scheduler type is not supported.
+	ResultUnsupported = "unsupported"
+)
+
+// ScheduleStatus is the state of the scheduler of a check.
+type ScheduleStatus struct {
+	// Check is the name of the check.
+	Check string `json:"check"`
+
+	// Namespace is the namespace of the check.
+	Namespace string `json:"namespace"`
+
+	// SchedulerType is the type of the scheduler.
+	SchedulerType string `json:"scheduler_type"`
+
+	// NextExecution is the time of the next scheduled execution of the check,
+	// in seconds since the epoch.
+	NextExecution int64 `json:"next_execution,omitempty"`
+
+	// LastExecution is the time of the last scheduled execution of the check,
+	// in seconds since the epoch.
+	LastExecution int64 `json:"last_execution,omitempty"`
+
+	// LastResult is the result of the last scheduled execution of the check.
+	LastResult string `json:"last_result,omitempty"`
+
+	// LastError is the error of the last scheduled execution of the check,
+	// if it failed.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// scheduleState tracks the status of a scheduler, so that it can be
+// inspected while the scheduler is running. A nil *scheduleState does nothing.
+type scheduleState struct {
+	mu     sync.Mutex
+	status ScheduleStatus
+}
+
+func newScheduleState(check *corev2.CheckConfig, typ SchedulerType) *scheduleState {
+	return &scheduleState{
+		status: ScheduleStatus{
+			Check:         check.Name,
+			Namespace:     check.Namespace,
+			SchedulerType: typ.String(),
+		},
+	}
+}
+
+func (s *scheduleState) setNext(next time.Time) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.NextExecution = next.Unix()
+}
+
+func (s *scheduleState) setResult(check *corev2.CheckConfig, err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.LastExecution = time.Now().Unix()
+	s.status.LastError = ""
+	switch {
+	case err != nil:
+		s.status.LastResult = ResultError
+		s.status.LastError = err.Error()
+	case check.IsSubdued():
+		s.status.LastResult = ResultSubdued
+	case !check.Publish:
+		s.status.LastResult = ResultNotPublished
+	default:
+		s.status.LastResult = ResultPublished
+	}
+}
+
+func (s *scheduleState) setPaused() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.LastExecution = time.Now().Unix()
+	s.status.LastError = ""
+	s.status.LastResult = ResultPaused
+}
+
+func (s *scheduleState) get() ScheduleStatus {
+	if s == nil {
+		return ScheduleStatus{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Schedules returns the status of the schedulers of the checks in namespace,
+// sorted by check name. If namespace is empty, the schedulers of every
+// namespace are returned.
+func (s *Schedulerd) Schedules(namespace string) []ScheduleStatus {
+	s.schedulersMu.RLock()
+	defer s.schedulersMu.RUnlock()
+	statuses := make([]ScheduleStatus, 0, len(s.schedulers))
+	for _, scheduler := range s.schedulers {
+		status := scheduler.Status()
+		if namespace != "" && status.Namespace != namespace {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Namespace != statuses[j].Namespace {
+			return statuses[i].Namespace < statuses[j].Namespace
+		}
+		return statuses[i].Check < statuses[j].Check
+	})
+	return statuses
+}
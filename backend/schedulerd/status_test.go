@@ -0,0 +1,61 @@
+package schedulerd
+
+import (
+	"errors"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleStateResult(t *testing.T) {
+	check := corev2.FixtureCheckConfig("check")
+	check.Publish = true
+	state := newScheduleState(check, IntervalType)
+
+	state.setResult(check, nil)
+	assert.Equal(t, ResultPublished, state.get().LastResult)
+
+	state.setResult(check, errors.New("bus closed"))
+	assert.Equal(t, ResultError, state.get().LastResult)
+	assert.Equal(t, "bus closed", state.get().LastError)
+
+	check.Publish = false
+	state.setResult(check, nil)
+	assert.Equal(t, ResultNotPublished, state.get().LastResult)
+	assert.Empty(t, state.get().LastError)
+
+	state.setPaused()
+	assert.Equal(t, ResultPaused, state.get().LastResult)
+
+	var nilState *scheduleState
+	nilState.setResult(check, nil)
+	assert.Equal(t, ScheduleStatus{}, nilState.get())
+}
+
+func TestSchedulerdSchedules(t *testing.T) {
+	foo := corev2.FixtureCheckConfig("foo")
+	bar := corev2.FixtureCheckConfig("bar")
+	baz := corev2.FixtureCheckConfig("baz")
+	baz.Namespace = "other"
+	bar.RoundRobin = true
+
+	s := &Schedulerd{
+		schedulers: map[string]Scheduler{
+			"foo": &IntervalScheduler{state: newScheduleState(foo, IntervalType)},
+			"bar": NewNoopScheduler(bar, RoundRobinIntervalType),
+			"baz": &CronScheduler{state: newScheduleState(baz, CronType)},
+		},
+	}
+
+	statuses := s.Schedules("default")
+	if assert.Len(t, statuses, 2) {
+		assert.Equal(t, "bar", statuses[0].Check)
+		assert.Equal(t, "round-robin interval", statuses[0].SchedulerType)
+		assert.Equal(t, ResultUnsupported, statuses[0].LastResult)
+		assert.Equal(t, "foo", statuses[1].Check)
+		assert.Equal(t, "interval", statuses[1].SchedulerType)
+	}
+
+	assert.Len(t, s.Schedules(""), 3)
+}
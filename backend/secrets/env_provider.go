@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"os"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+const (
+	// EnvProviderName is the name of the EnvProvider.
+	EnvProviderName = "env"
+
+	// EnvSecretPrefix is the prefix of the environment variables of the
+	// backend that hold the secrets of the EnvProvider. Only these variables
+	// are exposed as secrets.
+	EnvSecretPrefix = "SENSU_SECRET_"
+)
+
+// asserts that EnvProvider implements Provider
+var _ Provider = new(EnvProvider)
+
+// EnvProvider is the reference secrets provider, which gets the secrets from
+// the environment variables of the backend. The secret with the ID "token" is
+// the value of the SENSU_SECRET_token environment variable.
+type EnvProvider struct {
+	TypeMeta corev2.TypeMeta
+	Metadata corev2.ObjectMeta
+}
+
+// NewEnvProvider returns a new EnvProvider.
+func NewEnvProvider() *EnvProvider {
+	return &EnvProvider{
+		TypeMeta: corev2.TypeMeta{APIVersion: "secrets/v1", Type: "Env"},
+		Metadata: corev2.ObjectMeta{Name: EnvProviderName},
+	}
+}
+
+func (e *EnvProvider) Get(id string) (string, error) {
+	value, ok := os.LookupEnv(EnvSecretPrefix + id)
+	if !ok {
+		return "", ErrSecretNotFound(id)
+	}
+	return value, nil
+}
+
+func (e *EnvProvider) GetMetadata() *corev2.ObjectMeta {
+	return &e.Metadata
+}
+
+func (e *EnvProvider) SetMetadata(o *corev2.ObjectMeta) {
+	e.Metadata = *o
+}
+
+func (e *EnvProvider) StoreName() string {
+	return ""
+}
+
+func (e *EnvProvider) URIPath() string {
+	return ""
+}
+
+func (e *EnvProvider) RBACName() string {
+	return ""
+}
+
+func (e *EnvProvider) Validate() error {
+	return nil
+}
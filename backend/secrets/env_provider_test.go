@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv(EnvSecretPrefix+"token", "s3cr3t")
+	provider := NewEnvProvider()
+
+	value, err := provider.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	_, err = provider.Get("unknown")
+	assert.Equal(t, ErrSecretNotFound("unknown"), err)
+}
+
+func TestPathGetter(t *testing.T) {
+	getter := PathGetter{Namespaces: []string{"default"}}
+	ctx := context.WithValue(context.Background(), corev2.NamespaceKey, "default")
+	provider, id, err := getter.Get(ctx, "env/token")
+	require.NoError(t, err)
+	assert.Equal(t, "env", provider)
+	assert.Equal(t, "token", id)
+
+	for _, name := range []string{"token", "/token", "env/"} {
+		_, _, err := getter.Get(ctx, name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestPathGetterNamespaces(t *testing.T) {
+	getter := PathGetter{Namespaces: []string{"default"}}
+	ctx := context.WithValue(context.Background(), corev2.NamespaceKey, "dev")
+	_, _, err := getter.Get(ctx, "env/token")
+	assert.Equal(t, ErrSecretsNotAllowed("dev"), err)
+
+	ctx = context.WithValue(context.Background(), corev2.NamespaceKey, "default")
+	_, _, err = PathGetter{}.Get(ctx, "env/token")
+	assert.Equal(t, ErrSecretsNotAllowed("default"), err)
+}
@@ -14,6 +14,7 @@ type ErrInvalidSecretInfo string
 type ErrProviderNotAvailable string
 type ErrProviderNotFound string
 type ErrSecretNotFound string
+type ErrSecretsNotAllowed string
 
 func (e ErrInvalidSecretInfo) Error() string {
 	return fmt.Sprintf("invalid secret info: %s", string(e))
@@ -30,3 +31,7 @@ func (e ErrProviderNotFound) Error() string {
 func (e ErrSecretNotFound) Error() string {
 	return fmt.Sprintf("secret not found: %s", string(e))
 }
+
+func (e ErrSecretsNotAllowed) Error() string {
+	return fmt.Sprintf("secrets are not allowed in namespace: %s", string(e))
+}
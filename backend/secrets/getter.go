@@ -2,6 +2,9 @@ package secrets
 
 import (
 	"context"
+	"strings"
+
+	corev2 "github.com/sensu/core/v2"
 )
 
 // Getter represents an abstracted secret getter.
@@ -9,3 +12,37 @@ type Getter interface {
 	// Get gets the name of the provider and secret ID associated with the Sensu secret name.
 	Get(ctx context.Context, name string) (provider string, id string, err error)
 }
+
+// PathGetter is the reference Getter, which gets the provider and secret ID
+// from the Sensu secret name itself, written as provider/id. It does not
+// resolve the secrets through namespaced resources, and their access is not
+// controlled by RBAC, so that it only resolves the secrets of the checks,
+// handlers and mutators of the namespaces in Namespaces.
+type PathGetter struct {
+	// Namespaces are the namespaces allowed to use secrets. No namespace
+	// is allowed if it is empty.
+	Namespaces []string
+}
+
+// Get splits name into the name of the provider and the secret ID, if the
+// namespace of ctx is allowed to use secrets.
+func (g PathGetter) Get(ctx context.Context, name string) (string, string, error) {
+	namespace := corev2.ContextNamespace(ctx)
+	if !g.allowed(namespace) {
+		return "", "", ErrSecretsNotAllowed(namespace)
+	}
+	provider, id, ok := strings.Cut(name, "/")
+	if !ok || provider == "" || id == "" {
+		return "", "", ErrInvalidSecretInfo(name)
+	}
+	return provider, id, nil
+}
+
+func (g PathGetter) allowed(namespace string) bool {
+	for _, ns := range g.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/authentication/bcrypt"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 )
@@ -19,6 +20,16 @@ func setupAPIKeys(ctx context.Context, s storev2.Interface, config Config) error
 		apiKeys = append(apiKeys, apiKey)
 	}
 
+	if config.BreakGlassAPIKey != "" {
+		apiKey, err := breakGlassAPIKey(config.AdminUsername, config.BreakGlassAPIKey)
+		if err != nil {
+			msg := "could not build the break-glass api key"
+			logger.WithError(err).Error(msg)
+			return fmt.Errorf("%s: %w", msg, err)
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
 	for _, apiKey := range apiKeys {
 		name := apiKey.ObjectMeta.Name
 
@@ -46,3 +57,17 @@ func adminAPIKey(username, apiKey string) *corev2.APIKey {
 		CreatedAt: time.Now().Unix(),
 	}
 }
+
+// BreakGlassAPIKeyName is the name of the API key that is created from
+// Config.BreakGlassAPIKey.
+const BreakGlassAPIKeyName = "break-glass"
+
+func breakGlassAPIKey(username, secret string) (*corev2.APIKey, error) {
+	hash, err := bcrypt.HashPassword(secret)
+	if err != nil {
+		return nil, err
+	}
+	apiKey := adminAPIKey(username, BreakGlassAPIKeyName)
+	apiKey.Hash = []byte(hash)
+	return apiKey, nil
+}
@@ -0,0 +1,146 @@
+package seeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/core/v3/types"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/cli/resource"
+)
+
+// ReadSeedFile reads a seed manifest from path. The manifest uses the same
+// format as sensuctl create, and can contain any number of JSON or YAML
+// resources. Namespaced resources that don't specify a namespace are placed
+// in the default namespace.
+func ReadSeedFile(path string) ([]*types.Wrapper, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read seed file: %w", err)
+	}
+	defer f.Close()
+
+	resources, err := resource.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse seed file %s: %w", path, err)
+	}
+	if err := resource.Validate(resources, defaultNamespace().Metadata.Name); err != nil {
+		return nil, fmt.Errorf("invalid seed file %s: %w", path, err)
+	}
+	return resources, nil
+}
+
+func setupSeedResources(ctx context.Context, s storev2.Interface, config Config) error {
+	// namespaces are created first so that the order of the manifest does not
+	// matter to the resources that live in them.
+	var namespaces, resources []*types.Wrapper
+	for _, w := range config.Resources {
+		switch w.Value.(type) {
+		case *corev2.Namespace, *corev3.Namespace:
+			namespaces = append(namespaces, w)
+		default:
+			resources = append(resources, w)
+		}
+	}
+
+	if err := checkSeedDuplicates(config.Resources); err != nil {
+		return err
+	}
+
+	for _, w := range append(namespaces, resources...) {
+		name := seedResourceName(w)
+
+		// Seed resources are created in the init transaction, which a
+		// conflicting insert aborts, so existing resources can't be skipped.
+		if err := createSeedResource(ctx, s, w.Value, config.AdminUsername); err != nil {
+			var alreadyExists *store.ErrAlreadyExists
+			if errors.As(err, &alreadyExists) {
+				return fmt.Errorf("could not initialize the %s seed resource: it already exists, remove it from the seed file", name)
+			}
+			msg := fmt.Sprintf("could not initialize the %s seed resource", name)
+			logger.WithError(err).Error(msg)
+			return fmt.Errorf("%s: %w", msg, err)
+		}
+	}
+
+	return nil
+}
+
+// checkSeedDuplicates returns an error if a seed resource is repeated, or is
+// a resource that init creates on its own, such as the default namespace.
+func checkSeedDuplicates(resources []*types.Wrapper) error {
+	seen := map[string]bool{
+		seedResourceKey(corev2.TypeMeta{Type: "Namespace"}, "", defaultNamespace().Metadata.Name): true,
+	}
+	for _, w := range resources {
+		if w.Value == nil {
+			return errors.New("could not initialize seed resource: nil resource")
+		}
+		var namespace, name string
+		switch value := w.Value.(type) {
+		case *corev2.Namespace:
+			name = value.Name
+		case corev3.Resource:
+			if meta := value.GetMetadata(); meta != nil {
+				namespace, name = meta.Namespace, meta.Name
+			}
+		}
+		if name == "" {
+			continue
+		}
+		key := seedResourceKey(corev2.TypeMeta{Type: w.Type, APIVersion: w.APIVersion}, namespace, name)
+		if seen[key] {
+			return fmt.Errorf("duplicate %s seed resource: it is already defined in the seed file or created by init", seedResourceName(w))
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func seedResourceKey(tm corev2.TypeMeta, namespace, name string) string {
+	// core/v2 and core/v3 namespaces are the same resource
+	if tm.Type == "Namespace" {
+		tm.APIVersion = ""
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", tm.APIVersion, tm.Type, namespace, name)
+}
+
+func createSeedResource(ctx context.Context, s storev2.Interface, value interface{}, createdBy string) error {
+	switch value := value.(type) {
+	case *corev2.Namespace:
+		return s.GetNamespaceStore().CreateIfNotExists(ctx, corev3.V2NamespaceToV3(value))
+	case *corev3.Namespace:
+		return s.GetNamespaceStore().CreateIfNotExists(ctx, value)
+	case *corev3.EntityConfig:
+		return s.GetEntityConfigStore().CreateIfNotExists(ctx, value)
+	case *corev3.EntityState:
+		return s.GetEntityStateStore().CreateIfNotExists(ctx, value)
+	case corev3.Resource:
+		if err := value.Validate(); err != nil {
+			return &store.ErrNotValid{Err: err}
+		}
+		if meta := value.GetMetadata(); meta != nil && meta.CreatedBy == "" {
+			meta.CreatedBy = createdBy
+		}
+		req := storev2.NewResourceRequestFromResource(value)
+		wrapper, err := storev2.WrapResource(value)
+		if err != nil {
+			return err
+		}
+		return s.GetConfigStore().CreateIfNotExists(ctx, req, wrapper)
+	default:
+		return fmt.Errorf("%T is not a sensu resource", value)
+	}
+}
+
+func seedResourceName(w *types.Wrapper) string {
+	if r, ok := w.Value.(corev3.Resource); ok && r.GetMetadata() != nil {
+		return fmt.Sprintf("%s %s", w.Type, r.GetMetadata().Name)
+	}
+	return w.Type
+}
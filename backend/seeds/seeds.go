@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/sensu/core/v3/types"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 )
@@ -18,6 +19,29 @@ type Config struct {
 	// AdminAPIKey is the API key of the cluster admin. Can be used instead of
 	// AdminUsername and AdminPassword.
 	AdminAPIKey string
+
+	// AdditionalAdmins are cluster admins that are created alongside the
+	// cluster admin identified by AdminUsername.
+	AdditionalAdmins []Admin
+
+	// BreakGlassAPIKey is the secret of an API key that is granted to the
+	// cluster admin identified by AdminUsername. Unlike AdminAPIKey, only a
+	// hash of the secret is stored.
+	BreakGlassAPIKey string
+
+	// Resources are additional resources, typically read from a seed file
+	// with ReadSeedFile, that are created after the built-in users, roles and
+	// role bindings.
+	Resources []*types.Wrapper
+}
+
+// Admin is the identity of a cluster admin.
+type Admin struct {
+	// Username is the username of the cluster admin.
+	Username string
+
+	// Password is the password of the cluster admin.
+	Password string
 }
 
 var ErrAlreadyInitialized = errors.New("sensu-backend already initialized")
@@ -50,6 +74,9 @@ func seedCluster(config Config) storev2.InitializeFunc {
 		if err := setupClusterRoleBindings(ctx, str, config); err != nil {
 			return err
 		}
+		if err := setupSeedResources(ctx, str, config); err != nil {
+			return err
+		}
 
 		return nil
 	}
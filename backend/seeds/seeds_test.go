@@ -2,10 +2,13 @@ package seeds
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/core/v3/types"
 	"github.com/sensu/sensu-go/backend/store"
 	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 	"github.com/sensu/sensu-go/testing/mockstore"
@@ -226,3 +229,123 @@ func TestSeedInitialDataWithContext(t *testing.T) {
 		storev2.NewResourceRequestFromResource(systemUserClusterRoleBinding),
 		mock.Anything)
 }
+
+func TestSeedResources(t *testing.T) {
+	manifest := `type: CheckConfig
+api_version: core/v2
+spec:
+  metadata:
+    name: check-cpu
+    namespace: production
+  command: check-cpu.sh
+  interval: 60
+  subscriptions:
+  - linux
+---
+type: Namespace
+api_version: core/v3
+spec:
+  metadata:
+    name: production
+`
+	path := filepath.Join(t.TempDir(), "seed.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(manifest), 0600))
+
+	resources, err := ReadSeedFile(path)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	nsStore := new(mockstore.NamespaceStore)
+	cs := new(mockstore.ConfigStore)
+	s := new(mockstore.V2MockStore)
+	s.On("GetNamespaceStore").Return(nsStore)
+	s.On("GetConfigStore").Return(cs)
+
+	var calls []string
+	nsStore.On("CreateIfNotExists", mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) {
+		calls = append(calls, "namespace")
+	})
+	cs.On("CreateIfNotExists", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(mock.Arguments) {
+		calls = append(calls, "check")
+	})
+
+	config := Config{AdminUsername: "admin", Resources: resources}
+	require.NoError(t, setupSeedResources(context.Background(), s, config))
+
+	// namespaces are created before the resources that live in them
+	require.Equal(t, []string{"namespace", "check"}, calls)
+	cs.AssertCalled(t, "CreateIfNotExists",
+		context.Background(),
+		storev2.NewResourceRequest(corev2.TypeMeta{Type: "CheckConfig", APIVersion: "core/v2"}, "production", "check-cpu", (&corev2.CheckConfig{}).StoreName()),
+		mock.Anything)
+}
+
+func TestReadSeedFileNotFound(t *testing.T) {
+	_, err := ReadSeedFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestSeedResourcesDuplicates(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{
+			name: "repeated resource",
+			manifest: `type: Namespace
+api_version: core/v3
+spec:
+  metadata:
+    name: production
+---
+type: Namespace
+api_version: core/v2
+spec:
+  name: production
+`,
+		},
+		{
+			name: "default namespace",
+			manifest: `type: Namespace
+api_version: core/v3
+spec:
+  metadata:
+    name: default
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "seed.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.manifest), 0600))
+			resources, err := ReadSeedFile(path)
+			require.NoError(t, err)
+
+			// the store is never called, so the init transaction isn't aborted
+			s := new(mockstore.V2MockStore)
+			config := Config{AdminUsername: "admin", Resources: resources}
+			err = setupSeedResources(context.Background(), s, config)
+			require.ErrorContains(t, err, "duplicate Namespace seed resource")
+			s.AssertNotCalled(t, "GetNamespaceStore")
+		})
+	}
+}
+
+func TestSeedResourcesAlreadyExists(t *testing.T) {
+	nsStore := new(mockstore.NamespaceStore)
+	s := new(mockstore.V2MockStore)
+	s.On("GetNamespaceStore").Return(nsStore)
+	nsStore.On("CreateIfNotExists", mock.Anything, mock.Anything).Return(&store.ErrAlreadyExists{})
+
+	config := Config{
+		AdminUsername: "admin",
+		Resources: []*types.Wrapper{
+			{
+				TypeMeta: corev2.TypeMeta{Type: "Namespace", APIVersion: "core/v3"},
+				Value:    &corev3.Namespace{Metadata: &corev2.ObjectMeta{Name: "production"}},
+			},
+		},
+	}
+	err := setupSeedResources(context.Background(), s, config)
+	require.ErrorContains(t, err, "already exists")
+}
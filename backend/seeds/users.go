@@ -16,8 +16,11 @@ type userFn func() (*corev2.User, error)
 func setupUsers(ctx context.Context, s storev2.Interface, config Config) error {
 	userFns := []userFn{
 		adminUser(config.AdminUsername, config.AdminPassword),
-		agentUser(),
 	}
+	for _, admin := range config.AdditionalAdmins {
+		userFns = append(userFns, adminUser(admin.Username, admin.Password))
+	}
+	userFns = append(userFns, agentUser())
 
 	for _, userFn := range userFns {
 		user, err := userFn()
@@ -0,0 +1,176 @@
+// Package selfmonitor reports the internal errors of the backend, such as the
+// internal store errors and the message bus publish failures, with the events
+// of the backend entity in the sensu-system namespace. The events go through
+// the pipeline of the backend, so that the monitoring system alerts on itself
+// with its own handlers.
+package selfmonitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/daemon"
+	"github.com/sensu/sensu-go/backend/resource"
+)
+
+const (
+	componentName = "selfmonitord"
+
+	// ConditionStoreInternal is the condition of the internal store errors,
+	// which stop the agent sessions.
+	ConditionStoreInternal = "store-internal-errors"
+
+	// ConditionBusPublish is the condition of the messages that could not be
+	// published on the message bus.
+	ConditionBusPublish = "bus-publish-errors"
+
+	// ConditionHandlerCrashLoop is the condition of the message handlers of
+	// the agent sessions that fail on consecutive messages.
+	ConditionHandlerCrashLoop = "handler-crash-loops"
+
+	// DefaultInterval is the interval at which the events are published when
+	// Config.Interval is zero.
+	DefaultInterval = time.Minute
+
+	// DefaultThreshold is the default number of errors in an interval from
+	// which a condition is reported by a critical event.
+	DefaultThreshold = 10
+
+	statusOK       = 0
+	statusWarning  = 1
+	statusCritical = 2
+)
+
+// Conditions are the conditions reported by the Monitor, which are the names
+// of the checks of their events.
+var Conditions = []string{ConditionStoreInternal, ConditionBusPublish, ConditionHandlerCrashLoop}
+
+var descriptions = map[string]string{
+	ConditionStoreInternal:    "internal store errors",
+	ConditionBusPublish:       "message bus publish failures",
+	ConditionHandlerCrashLoop: "agent session handler crash loops",
+}
+
+// Config configures a Monitor.
+type Config struct {
+	EventReceiver resource.EventReceiver
+
+	// Threshold is the number of errors in an interval from which a
+	// condition is reported by a critical event, rather than by a warning
+	// event.
+	Threshold int
+
+	// Interval is the interval at which the events are published.
+	Interval time.Duration
+}
+
+// Monitor is the daemon that publishes the events of the internal errors
+// recorded by the other components of the backend.
+type Monitor struct {
+	receiver  resource.EventReceiver
+	threshold int64
+	interval  time.Duration
+	loop      *daemon.Loop
+
+	// counts are the numbers of errors recorded since the previous check,
+	// by condition. The map is not modified once the Monitor is created.
+	counts map[string]*int64
+}
+
+// New creates a new Monitor.
+func New(cfg Config) (*Monitor, error) {
+	if cfg.EventReceiver == nil {
+		return nil, errors.New("an event receiver is required")
+	}
+	m := &Monitor{
+		receiver:  cfg.EventReceiver,
+		threshold: int64(cfg.Threshold),
+		interval:  cfg.Interval,
+		loop:      daemon.NewLoop(),
+		counts:    make(map[string]*int64, len(Conditions)),
+	}
+	if m.threshold <= 0 {
+		m.threshold = DefaultThreshold
+	}
+	if m.interval <= 0 {
+		m.interval = DefaultInterval
+	}
+	for _, condition := range Conditions {
+		m.counts[condition] = new(int64)
+	}
+	return m, nil
+}
+
+// Record records an error of condition, one of the Conditions. A nil Monitor
+// records nothing.
+func (m *Monitor) Record(condition string) {
+	if m == nil {
+		return
+	}
+	if count, ok := m.counts[condition]; ok {
+		atomic.AddInt64(count, 1)
+	}
+}
+
+// Start starts the publication of the events.
+func (m *Monitor) Start() error {
+	m.loop.Go(m.run)
+	return nil
+}
+
+// Stop stops the publication of the events.
+func (m *Monitor) Stop() error {
+	return m.loop.Stop()
+}
+
+// Err returns a channel on which to listen for terminal errors.
+func (m *Monitor) Err() <-chan error {
+	return m.loop.Err()
+}
+
+// Name returns the daemon name.
+func (m *Monitor) Name() string {
+	return componentName
+}
+
+func (m *Monitor) run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+// check publishes the event of every condition, with the errors recorded
+// since the previous check.
+func (m *Monitor) check() {
+	for _, condition := range Conditions {
+		count := atomic.SwapInt64(m.counts[condition], 0)
+		status, output := m.status(condition, count)
+		if err := m.receiver.GenerateBackendEvent(condition, status, output); err != nil {
+			logger.WithError(err).WithField("condition", condition).Error("could not publish the self-monitoring event")
+		}
+	}
+}
+
+// status returns the status and output of the event of condition, for count
+// errors.
+func (m *Monitor) status(condition string, count int64) (uint32, string) {
+	output := fmt.Sprintf("%d %s in the last %s", count, descriptions[condition], m.interval)
+	switch {
+	case count >= m.threshold:
+		return statusCritical, output
+	case count > 0:
+		return statusWarning, output
+	default:
+		return statusOK, output
+	}
+}
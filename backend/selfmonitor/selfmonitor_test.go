@@ -0,0 +1,63 @@
+package selfmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/testing/mockresource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorCheck(t *testing.T) {
+	receiver := &mockresource.EventReceiver{}
+	m, err := New(Config{EventReceiver: receiver, Threshold: 3, Interval: time.Minute})
+	require.NoError(t, err)
+
+	m.Record(ConditionStoreInternal)
+	for i := 0; i < 3; i++ {
+		m.Record(ConditionBusPublish)
+	}
+	m.Record("unknown")
+	m.check()
+
+	event, _ := receiver.Event(ConditionStoreInternal)
+	assert.Equal(t, uint32(1), event.Status)
+	assert.Equal(t, "1 internal store errors in the last 1m0s", event.Output)
+
+	event, _ = receiver.Event(ConditionBusPublish)
+	assert.Equal(t, uint32(2), event.Status)
+
+	event, ok := receiver.Event(ConditionHandlerCrashLoop)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(0), event.Status)
+
+	// The errors are counted again from the previous check
+	m.check()
+	for _, condition := range Conditions {
+		event, _ := receiver.Event(condition)
+		assert.Equal(t, uint32(0), event.Status, condition)
+	}
+}
+
+func TestMonitorRun(t *testing.T) {
+	receiver := &mockresource.EventReceiver{}
+	m, err := New(Config{EventReceiver: receiver, Interval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	require.NoError(t, m.Start())
+	m.Record(ConditionHandlerCrashLoop)
+	assert.Eventually(t, func() bool {
+		for _, event := range receiver.Events(ConditionHandlerCrashLoop) {
+			if event.Status == 1 {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, m.Stop())
+}
+
+func TestNilMonitorRecord(t *testing.T) {
+	var m *Monitor
+	m.Record(ConditionStoreInternal)
+}
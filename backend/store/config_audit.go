@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// AgentConfigPush records an entity config update sent by a backend to an
+// agent, with the subscriptions it added and removed.
+type AgentConfigPush struct {
+	Namespace            string    `json:"namespace"`
+	AgentName            string    `json:"agent_name"`
+	Action               string    `json:"action"`
+	UpdatedBy            string    `json:"updated_by,omitempty"`
+	ManagedBy            string    `json:"managed_by,omitempty"`
+	SubscriptionsAdded   []string  `json:"subscriptions_added"`
+	SubscriptionsRemoved []string  `json:"subscriptions_removed"`
+	SentAt               time.Time `json:"sent_at"`
+}
+
+// ConfigAuditStore stores the records of the entity config updates sent to
+// the agents by all backends.
+type ConfigAuditStore interface {
+	// RecordConfigPush records push, and drops the oldest records so that
+	// at most limit records are kept.
+	RecordConfigPush(ctx context.Context, push *AgentConfigPush, limit int) error
+
+	// ListConfigPushes lists the records of the entity config updates sent
+	// to the agents of namespace, oldest first, or to agent only unless it
+	// is empty.
+	ListConfigPushes(ctx context.Context, namespace, agent string) ([]*AgentConfigPush, error)
+}
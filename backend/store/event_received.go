@@ -0,0 +1,44 @@
+package store
+
+import (
+	"strconv"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+// EventReceivedAnnotation is the annotation of the events set to the time at
+// which the backend received them, in seconds since the Unix epoch. The
+// timestamp of the events is the time at which they were created by their
+// agent, which can be much older when they were buffered.
+const EventReceivedAnnotation = "sensu.io/received"
+
+// SetEventReceived annotates event with the time t at which the backend
+// received it from an agent or from the API. The annotation sent by the
+// client is overwritten, since it can't be trusted.
+func SetEventReceived(event *corev2.Event, t time.Time) {
+	if event.Annotations == nil {
+		event.Annotations = make(map[string]string)
+	}
+	event.Annotations[EventReceivedAnnotation] = strconv.FormatInt(t.Unix(), 10)
+}
+
+// EnsureEventReceived annotates event with the time t at which the backend
+// received it, unless it is already annotated by the agentd or apid that
+// received it.
+func EnsureEventReceived(event *corev2.Event, t time.Time) {
+	if _, ok := event.Annotations[EventReceivedAnnotation]; ok {
+		return
+	}
+	SetEventReceived(event, t)
+}
+
+// EventReceived returns the time at which the backend received event, in
+// seconds since the Unix epoch, and false if event is not annotated with it.
+func EventReceived(event *corev2.Event) (int64, bool) {
+	received, err := strconv.ParseInt(event.Annotations[EventReceivedAnnotation], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return received, true
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+func TestSetEventReceived(t *testing.T) {
+	event := corev2.FixtureEvent("entity", "check")
+	event.Annotations = nil
+	if _, ok := EventReceived(event); ok {
+		t.Fatal("the event should not be annotated")
+	}
+
+	SetEventReceived(event, time.Unix(42, 0))
+	if got, ok := EventReceived(event); !ok || got != 42 {
+		t.Fatalf("got received time %d, want 42", got)
+	}
+
+	// the annotation sent by the client is overwritten
+	SetEventReceived(event, time.Unix(84, 0))
+	if got, _ := EventReceived(event); got != 84 {
+		t.Errorf("got received time %d, want 84", got)
+	}
+}
+
+func TestEnsureEventReceived(t *testing.T) {
+	event := corev2.FixtureEvent("entity", "check")
+	event.Annotations = nil
+	EnsureEventReceived(event, time.Unix(42, 0))
+	if got, ok := EventReceived(event); !ok || got != 42 {
+		t.Fatalf("got received time %d, want 42", got)
+	}
+
+	// the backend that received the event annotated it first
+	EnsureEventReceived(event, time.Unix(84, 0))
+	if got, _ := EventReceived(event); got != 42 {
+		t.Errorf("got received time %d, want 42", got)
+	}
+}
@@ -0,0 +1,30 @@
+package store
+
+import (
+	corev2 "github.com/sensu/core/v2"
+)
+
+// EventRequestIDAnnotation is the annotation of the events set to the ID of
+// the API request that created them, so that the logs of their handling can
+// be correlated with the access logs of the API.
+const EventRequestIDAnnotation = "sensu.io/request-id"
+
+// SetEventRequestID annotates event with the ID of the API request that
+// created it. The annotation sent by the client is removed when id is empty,
+// e.g. for the events of agents, since it can't be trusted.
+func SetEventRequestID(event *corev2.Event, id string) {
+	if id == "" {
+		delete(event.Annotations, EventRequestIDAnnotation)
+		return
+	}
+	if event.Annotations == nil {
+		event.Annotations = make(map[string]string)
+	}
+	event.Annotations[EventRequestIDAnnotation] = id
+}
+
+// EventRequestID returns the ID of the API request that created event, or an
+// empty string if it was not created by the API.
+func EventRequestID(event *corev2.Event) string {
+	return event.Annotations[EventRequestIDAnnotation]
+}
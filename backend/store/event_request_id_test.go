@@ -0,0 +1,26 @@
+package store
+
+import (
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+func TestSetEventRequestID(t *testing.T) {
+	event := corev2.FixtureEvent("entity", "check")
+	event.Annotations = nil
+	if got := EventRequestID(event); got != "" {
+		t.Fatalf("got request ID %q, want none", got)
+	}
+
+	SetEventRequestID(event, "abcd")
+	if got := EventRequestID(event); got != "abcd" {
+		t.Fatalf("got request ID %q, want abcd", got)
+	}
+
+	// the annotation sent by an agent is removed
+	SetEventRequestID(event, "")
+	if _, ok := event.Annotations[EventRequestIDAnnotation]; ok {
+		t.Errorf("the request ID annotation was not removed")
+	}
+}
@@ -0,0 +1,27 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyKey is claimed by the first request that carries it, so that the
+// retries of the request, which carry the same key, are only processed once.
+type IdempotencyKey struct {
+	Namespace string    `json:"namespace"`
+	Key       string    `json:"key"`
+	ClaimedAt time.Time `json:"claimed_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IdempotencyKeyStore stores the idempotency keys shared by all backends.
+type IdempotencyKeyStore interface {
+	// ClaimIdempotencyKey claims key, unless the same key of the same
+	// namespace is claimed and not expired. It returns false if the key was
+	// not claimed.
+	ClaimIdempotencyKey(ctx context.Context, key *IdempotencyKey) (bool, error)
+
+	// ReleaseIdempotencyKey releases the key of namespace, so that it can be
+	// claimed again. It returns ErrNotFound if the key is not claimed.
+	ReleaseIdempotencyKey(ctx context.Context, namespace, key string) error
+}
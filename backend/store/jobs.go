@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// JobStatus is the status of a background job.
+type JobStatus string
+
+const (
+	// JobPending is the status of a job that is waiting for a runner.
+	JobPending JobStatus = "pending"
+
+	// JobRunning is the status of a job that a runner is executing.
+	JobRunning JobStatus = "running"
+
+	// JobSucceeded is the status of a job that completed without error.
+	JobSucceeded JobStatus = "succeeded"
+
+	// JobFailed is the status of a job that completed with an error.
+	JobFailed JobStatus = "failed"
+)
+
+// Job is a long running operation executed in the background by a job runner,
+// such as the deletion of all the events of an entity.
+type Job struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace"`
+	// Kind selects the handler of the job.
+	Kind string `json:"kind"`
+	// Params are the parameters of the handler of the job.
+	Params map[string]string `json:"params,omitempty"`
+	Status JobStatus         `json:"status"`
+	// Done and Total report the progress of the job, in units of work of its
+	// handler. Total is zero until the handler knows it.
+	Done  int `json:"done"`
+	Total int `json:"total"`
+	// Error is the error of a failed job, or of the last failed attempt of a
+	// job that is retried.
+	Error string `json:"error,omitempty"`
+	// Attempts is the number of attempts started, out of MaxAttempts.
+	Attempts    int `json:"attempts"`
+	MaxAttempts int `json:"max_attempts"`
+	// RetryAt is the time after which a pending job that failed is retried.
+	// It is zero for the pending jobs that are queued.
+	RetryAt   time.Time `json:"retry_at,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is refreshed by the runner of a running job, so that the jobs
+	// of runners that stopped can be detected.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Completed returns true if the job succeeded or failed.
+func (j *Job) Completed() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+// JobStore stores the background jobs shared by all backends.
+type JobStore interface {
+	// CreateJob creates job.
+	CreateJob(ctx context.Context, job *Job) error
+
+	// GetJob gets the job of namespace with the given id. It returns
+	// ErrNotFound if the job does not exist.
+	GetJob(ctx context.Context, namespace, id string) (*Job, error)
+
+	// ListJobs lists the jobs of namespace, or of every namespace if
+	// namespace is empty, that have the given status, or any status if status
+	// is empty. Jobs are listed from the oldest to the newest.
+	ListJobs(ctx context.Context, namespace string, status JobStatus) ([]*Job, error)
+
+	// UpdateJob updates the status, progress, error, attempts and retry time
+	// of job. It returns ErrNotFound if the job does not exist.
+	UpdateJob(ctx context.Context, job *Job) error
+}
@@ -404,6 +404,41 @@ func (e *EventStore) DeleteEventByEntityCheck(ctx context.Context, entity, check
 	return e.backingStore.DeleteEventByEntityCheck(ctx, entity, check)
 }
 
+// ReplaceEvent replaces the stored event of the entity and check of event with
+// event, in memory and in the backing store.
+func (e *EventStore) ReplaceEvent(ctx context.Context, event *corev2.Event) error {
+	replacer, ok := e.backingStore.(store.EventReplacer)
+	if !ok {
+		return errors.New("event replacement not supported")
+	}
+	key := strings.Join([]string{event.Entity.Namespace, event.Entity.Name, event.Check.Name}, "\n")
+	value, ok := e.db.data.Load(key)
+	if !ok {
+		return replacer.ReplaceEvent(ctx, event)
+	}
+	entry := value.(*eventEntry)
+	entry.Mu.Lock()
+	defer entry.Mu.Unlock()
+	if entry.Dirty {
+		// The event was updated since it was read from the backing store,
+		// and the update must not be lost
+		return nil
+	}
+	if err := replacer.ReplaceEvent(ctx, event); err != nil {
+		return err
+	}
+	if entry.EventBytes != nil {
+		eventBytes, err := proto.Marshal(event)
+		if err != nil {
+			// fatal developer error
+			panic(err)
+		}
+		entry.EventBytes = snappy.Encode(nil, eventBytes)
+		entry.Dirty = false
+	}
+	return nil
+}
+
 func (e *EventStore) CountEvents(ctx context.Context, pred *store.SelectionPredicate) (int64, error) {
 	return e.backingStore.CountEvents(ctx, pred)
 }
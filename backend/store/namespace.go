@@ -4,16 +4,44 @@ import (
 	"context"
 
 	v2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
 )
 
 const (
 	// WildcardValue is the symbol that denotes a wildcard namespace.
-	WildcardValue	= "*"
+	WildcardValue = "*"
 
 	// Root is the root of the sensu keyspace.
-	Root	= "/sensu.io"
+	Root = "/sensu.io"
+
+	// SchedulingPausedAnnotation is the namespace annotation that stops the
+	// scheduling of the checks of the namespace when set to "true".
+	SchedulingPausedAnnotation = "sensu.io/scheduling_paused"
+
+	// SuppressKeepaliveAlertsAnnotation is the namespace annotation that stops
+	// keepalive failures of the namespace from raising alerts when set to
+	// "true", while scheduling is paused.
+	SuppressKeepaliveAlertsAnnotation = "sensu.io/suppress_keepalive_alerts"
 )
 
+// SchedulingPaused returns whether the scheduling of the checks of the
+// namespace is paused.
+func SchedulingPaused(namespace *corev3.Namespace) bool {
+	if namespace == nil || namespace.Metadata == nil {
+		return false
+	}
+	return namespace.Metadata.Annotations[SchedulingPausedAnnotation] == "true"
+}
+
+// KeepaliveAlertsSuppressed returns whether keepalive failures of the
+// namespace must not raise alerts.
+func KeepaliveAlertsSuppressed(namespace *corev3.Namespace) bool {
+	if !SchedulingPaused(namespace) {
+		return false
+	}
+	return namespace.Metadata.Annotations[SuppressKeepaliveAlertsAnnotation] == "true"
+}
+
 // NewNamespaceFromContext creates a new Namespace from a context.
 func NewNamespaceFromContext(ctx context.Context) string {
 	if value := ctx.Value(v2.NamespaceKey); value != nil {
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"testing"
+
+	corev3 "github.com/sensu/core/v3"
+)
+
+func TestSchedulingPaused(t *testing.T) {
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		wantPaused   bool
+		wantSuppress bool
+	}{
+		{
+			name: "not paused",
+		},
+		{
+			name:        "paused",
+			annotations: map[string]string{SchedulingPausedAnnotation: "true"},
+			wantPaused:  true,
+		},
+		{
+			name: "paused with keepalive alerts suppressed",
+			annotations: map[string]string{
+				SchedulingPausedAnnotation:        "true",
+				SuppressKeepaliveAlertsAnnotation: "true",
+			},
+			wantPaused:   true,
+			wantSuppress: true,
+		},
+		{
+			name:        "keepalive alerts are only suppressed while paused",
+			annotations: map[string]string{SuppressKeepaliveAlertsAnnotation: "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace := corev3.FixtureNamespace("default")
+			namespace.Metadata.Annotations = tt.annotations
+			if got := SchedulingPaused(namespace); got != tt.wantPaused {
+				t.Errorf("SchedulingPaused() = %v, want %v", got, tt.wantPaused)
+			}
+			if got := KeepaliveAlertsSuppressed(namespace); got != tt.wantSuppress {
+				t.Errorf("KeepaliveAlertsSuppressed() = %v, want %v", got, tt.wantSuppress)
+			}
+		})
+	}
+}
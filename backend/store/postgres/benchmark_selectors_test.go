@@ -24,3 +24,30 @@ func BenchmarkCreateGetEventsQuery(b *testing.B) {
 		_, _, _ = CreateGetEventsQuery(namespace, entity, check, selector, nil)
 	}
 }
+
+func BenchmarkEntityConfigSelectorSQL(b *testing.B) {
+	selector := &selector.Selector{
+		Operations: []selector.Operation{
+			{
+				LValue:   EntityClassField,
+				Operator: selector.DoubleEqualSignOperator,
+				RValues:  []string{"agent"},
+			},
+			{
+				LValue:   "linux",
+				Operator: selector.InOperator,
+				RValues:  []string{EntitySubscriptionsField},
+			},
+			{
+				LValue:        "region",
+				Operator:      selector.InOperator,
+				RValues:       []string{"us-west-1", "us-west-2"},
+				OperationType: selector.OperationTypeLabelSelector,
+			},
+		},
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = EntityConfigSelectorSQL(selector, 5)
+	}
+}
@@ -0,0 +1,45 @@
+package postgres
+
+const configAuditSchema = `
+CREATE TABLE IF NOT EXISTS agent_config_pushes (
+	id			bigserial PRIMARY KEY,
+	namespace		text NOT NULL,
+	agent			text NOT NULL,
+	action			text NOT NULL,
+	updated_by		text NOT NULL,
+	managed_by		text NOT NULL,
+	subscriptions_added	text[] NOT NULL,
+	subscriptions_removed	text[] NOT NULL,
+	sent_at			timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS agent_config_pushes_agent_idx ON agent_config_pushes (namespace, agent);
+`
+
+// configAuditRecord records an entity config update, and deletes the records
+// that are older than the newest limit records.
+//
+// $1: namespace (text)
+// $2: agent name (text)
+// $3: watch action (text)
+// $4: updated by (text)
+// $5: managed by (text)
+// $6: subscriptions added (text[])
+// $7: subscriptions removed (text[])
+// $8: sent at (timestamptz)
+// $9: limit (bigint)
+const configAuditRecord = `
+WITH pushed AS (
+	INSERT INTO agent_config_pushes (namespace, agent, action, updated_by, managed_by, subscriptions_added, subscriptions_removed, sent_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id
+)
+DELETE FROM agent_config_pushes
+WHERE id <= (SELECT id FROM pushed) - $9;
+`
+
+const configAuditList = `
+SELECT namespace, agent, action, updated_by, managed_by, subscriptions_added, subscriptions_removed, sent_at
+FROM agent_config_pushes
+WHERE namespace = $1 AND ($2 = '' OR agent = $2)
+ORDER BY id;
+`
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// ConfigAuditStore stores the records of the entity config updates sent to
+// the agents in postgres.
+type ConfigAuditStore struct {
+	db DBI
+}
+
+// NewConfigAuditStore creates a new ConfigAuditStore.
+func NewConfigAuditStore(db DBI) *ConfigAuditStore {
+	return &ConfigAuditStore{db: db}
+}
+
+// RecordConfigPush records push, and drops the oldest records so that at
+// most limit records are kept.
+func (s *ConfigAuditStore) RecordConfigPush(ctx context.Context, push *store.AgentConfigPush, limit int) error {
+	added, removed := push.SubscriptionsAdded, push.SubscriptionsRemoved
+	if added == nil {
+		added = []string{}
+	}
+	if removed == nil {
+		removed = []string{}
+	}
+	_, err := s.db.Exec(ctx, configAuditRecord, push.Namespace, push.AgentName, push.Action, push.UpdatedBy, push.ManagedBy, added, removed, push.SentAt, limit)
+	if err != nil {
+		return &store.ErrInternal{Message: fmt.Sprintf("could not record config push: %s", err)}
+	}
+	return nil
+}
+
+// ListConfigPushes lists the records of the entity config updates sent to the
+// agents of namespace, oldest first, or to agent only unless it is empty.
+func (s *ConfigAuditStore) ListConfigPushes(ctx context.Context, namespace, agent string) ([]*store.AgentConfigPush, error) {
+	rows, err := s.db.Query(ctx, configAuditList, namespace, agent)
+	if err != nil {
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("could not list config pushes: %s", err)}
+	}
+	defer rows.Close()
+	pushes := []*store.AgentConfigPush{}
+	for rows.Next() {
+		var push store.AgentConfigPush
+		if err := rows.Scan(&push.Namespace, &push.AgentName, &push.Action, &push.UpdatedBy, &push.ManagedBy, &push.SubscriptionsAdded, &push.SubscriptionsRemoved, &push.SentAt); err != nil {
+			return nil, &store.ErrInternal{Message: fmt.Sprintf("could not list config pushes: %s", err)}
+		}
+		pushes = append(pushes, &push)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("could not list config pushes: %s", err)}
+	}
+	return pushes, nil
+}
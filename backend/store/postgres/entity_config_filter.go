@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/sensu/sensu-go/backend/selector"
+)
+
+const (
+	// EntityClassField is the field of the class of an entity, in entity
+	// selectors.
+	EntityClassField = "entity.entity_class"
+
+	// EntitySubscriptionsField is the field of the subscriptions of an
+	// entity, in entity selectors.
+	EntitySubscriptionsField = "entity.subscriptions"
+)
+
+var (
+	listEntityConfigTmpl  = template.Must(template.New("listEntityConfigQuery").Parse(listEntityConfigQueryTmpl))
+	countEntityConfigTmpl = template.Must(template.New("countEntityConfigQuery").Parse(countEntityConfigQueryTmpl))
+)
+
+type entityConfigTemplateValues struct {
+	Descending  bool
+	SelectorSQL string
+}
+
+func executeEntityConfigTemplate(tmpl *template.Template, values entityConfigTemplateValues) (string, error) {
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, values); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// EntityConfigSelectorSQL returns the SQL condition of an entity selector,
+// with arguments numbered after nargs. The selector can match the labels of
+// entities, their class with the ==, !=, in and notin operators, and their
+// subscriptions with the in and notin operators.
+func EntityConfigSelectorSQL(sel *selector.Selector, nargs int) (string, []interface{}, error) {
+	if sel == nil || len(sel.Operations) == 0 {
+		return "", nil, nil
+	}
+	ctr := &argCounter{value: nargs}
+	conds := make([]string, 0, len(sel.Operations))
+	args := make([]interface{}, 0, len(sel.Operations))
+	labels := &selector.Selector{}
+	for _, op := range sel.Operations {
+		if op.OperationType == selector.OperationTypeLabelSelector {
+			labels.Operations = append(labels.Operations, op)
+			continue
+		}
+		cond, arg, err := entityConfigFieldCond(ctr, op)
+		if err != nil {
+			return "", nil, err
+		}
+		conds = append(conds, cond)
+		args = append(args, arg)
+	}
+	if len(labels.Operations) > 0 {
+		builder := &SelectorSQLBuilder{
+			selectorColumn:      "entity_configs.selectors",
+			labelColumn:         "entity_configs.selectors",
+			labelPrefixes:       []string{""},
+			includeLabelCaption: true,
+			validFieldKeys:      map[string]struct{}{},
+			selector:            labels,
+		}
+		cond, labelArgs, err := builder.GetSelectorCond(ctr)
+		if err != nil {
+			return "", nil, err
+		}
+		conds = append(conds, fmt.Sprintf("(%s)", cond))
+		args = append(args, labelArgs...)
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+func entityConfigFieldCond(ctr *argCounter, op selector.Operation) (string, interface{}, error) {
+	switch {
+	case op.LValue == EntityClassField:
+		cond := fmt.Sprintf("entity_configs.entity_class = ANY($%d)", ctr.Next())
+		switch op.Operator {
+		case selector.DoubleEqualSignOperator, selector.InOperator:
+			return cond, op.RValues, nil
+		case selector.NotEqualOperator, selector.NotInOperator:
+			return fmt.Sprintf("NOT (%s)", cond), op.RValues, nil
+		}
+	case len(op.RValues) == 1 && op.RValues[0] == EntitySubscriptionsField:
+		cond := fmt.Sprintf("$%d = ANY(entity_configs.subscriptions)", ctr.Next())
+		switch op.Operator {
+		case selector.InOperator:
+			return cond, op.LValue, nil
+		case selector.NotInOperator:
+			return fmt.Sprintf("NOT (%s)", cond), op.LValue, nil
+		}
+	}
+	return "", nil, fmt.Errorf("entities can't be selected with %s %s %v", op.LValue, op.Operator, op.RValues)
+}
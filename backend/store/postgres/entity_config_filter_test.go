@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/sensu/sensu-go/backend/selector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntityConfigSelectorSQL(t *testing.T) {
+	testCases := []struct {
+		name          string
+		selector      *selector.Selector
+		expectedQuery string
+		expectedArgs  []interface{}
+		expectErr     bool
+	}{
+		{
+			name: "no selector",
+		},
+		{
+			name: "class",
+			selector: &selector.Selector{Operations: []selector.Operation{
+				{LValue: EntityClassField, Operator: selector.DoubleEqualSignOperator, RValues: []string{"agent"}},
+			}},
+			expectedQuery: "entity_configs.entity_class = ANY($6)",
+			expectedArgs:  []interface{}{[]string{"agent"}},
+		},
+		{
+			name: "excluded classes and subscription",
+			selector: &selector.Selector{Operations: []selector.Operation{
+				{LValue: EntityClassField, Operator: selector.NotInOperator, RValues: []string{"proxy", "backend"}},
+				{LValue: "linux", Operator: selector.InOperator, RValues: []string{EntitySubscriptionsField}},
+			}},
+			expectedQuery: "NOT (entity_configs.entity_class = ANY($6)) AND $7 = ANY(entity_configs.subscriptions)",
+			expectedArgs:  []interface{}{[]string{"proxy", "backend"}, "linux"},
+		},
+		{
+			name: "label",
+			selector: &selector.Selector{Operations: []selector.Operation{
+				{LValue: "region", Operator: selector.DoubleEqualSignOperator, RValues: []string{"us-west-1"}, OperationType: selector.OperationTypeLabelSelector},
+			}},
+			expectedQuery: "((entity_configs.selectors ? ('' || $6) AND entity_configs.selectors->>('' || $6) ~ $7))",
+			expectedArgs:  []interface{}{"labels.region", "(((^|,)us-west-1($|,)))"},
+		},
+		{
+			name: "unsupported field",
+			selector: &selector.Selector{Operations: []selector.Operation{
+				{LValue: "entity.system.os", Operator: selector.DoubleEqualSignOperator, RValues: []string{"linux"}},
+			}},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, args, err := EntityConfigSelectorSQL(tc.selector, 5)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedQuery, query)
+			assert.Equal(t, tc.expectedArgs, args)
+		})
+	}
+}
+
+func TestEntityConfigQueryTemplates(t *testing.T) {
+	query, err := executeEntityConfigTemplate(listEntityConfigTmpl, entityConfigTemplateValues{})
+	require.NoError(t, err)
+	assert.Contains(t, query, "entity_configs.name ) ASC")
+	assert.NotContains(t, query, "entity_configs.updated_at > $5\n\tAND")
+
+	query, err = executeEntityConfigTemplate(listEntityConfigTmpl, entityConfigTemplateValues{
+		Descending:  true,
+		SelectorSQL: "entity_configs.entity_class = ANY($6)",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, query, "entity_configs.name ) DESC")
+	assert.Contains(t, query, "AND entity_configs.entity_class = ANY($6)")
+
+	query, err = executeEntityConfigTemplate(countEntityConfigTmpl, entityConfigTemplateValues{
+		SelectorSQL: "$3 = ANY(entity_configs.subscriptions)",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, query, "AND $3 = ANY(entity_configs.subscriptions);")
+}
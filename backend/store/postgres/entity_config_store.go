@@ -247,9 +247,16 @@ func (s *EntityConfigStore) List(ctx context.Context, namespace string, pred *st
 	if pred == nil {
 		pred = &store.SelectionPredicate{}
 	}
-	query := listEntityConfigQuery
-	if pred.Descending {
-		query = listEntityConfigDescQuery
+	selectorSQL, selectorArgs, err := EntityConfigSelectorSQL(storev2.EntitySelectorFromContext(ctx), 5)
+	if err != nil {
+		return nil, &store.ErrNotValid{Err: err}
+	}
+	query, err := executeEntityConfigTemplate(listEntityConfigTmpl, entityConfigTemplateValues{
+		Descending:  pred.Descending,
+		SelectorSQL: selectorSQL,
+	})
+	if err != nil {
+		return nil, &store.ErrInternal{Message: err.Error()}
 	}
 
 	if pred.UpdatedSince == "" {
@@ -272,7 +279,10 @@ func (s *EntityConfigStore) List(ctx context.Context, namespace string, pred *st
 		sqlNamespace.Valid = true
 	}
 
-	rows, rerr := s.db.Query(ctx, query, sqlNamespace, limit, offset, pred.IncludeDeletes, updatedSince)
+	args := []interface{}{sqlNamespace, limit, offset, pred.IncludeDeletes, updatedSince}
+	args = append(args, selectorArgs...)
+
+	rows, rerr := s.db.Query(ctx, query, args...)
 	if rerr != nil {
 		return nil, &store.ErrInternal{Message: rerr.Error()}
 	}
@@ -305,8 +315,19 @@ func (s *EntityConfigStore) List(ctx context.Context, namespace string, pred *st
 }
 
 // Count returns the count of EntityConfigs found matching the namespace
-// and entity class provided.
+// and entity class provided, and the entity selector of ctx.
 func (s *EntityConfigStore) Count(ctx context.Context, namespace, entityClass string) (int, error) {
+	selectorSQL, selectorArgs, err := EntityConfigSelectorSQL(storev2.EntitySelectorFromContext(ctx), 2)
+	if err != nil {
+		return 0, &store.ErrNotValid{Err: err}
+	}
+	query, err := executeEntityConfigTemplate(countEntityConfigTmpl, entityConfigTemplateValues{
+		SelectorSQL: selectorSQL,
+	})
+	if err != nil {
+		return 0, &store.ErrInternal{Message: err.Error()}
+	}
+
 	var sqlNamespace sql.NullString
 	if namespace != "" {
 		sqlNamespace.String = namespace
@@ -318,7 +339,10 @@ func (s *EntityConfigStore) Count(ctx context.Context, namespace, entityClass st
 		sqlEntityClass.Valid = true
 	}
 
-	row := s.db.QueryRow(ctx, countEntityConfigQuery, sqlNamespace, sqlEntityClass)
+	args := []interface{}{sqlNamespace, sqlEntityClass}
+	args = append(args, selectorArgs...)
+
+	row := s.db.QueryRow(ctx, query, args...)
 	var ct int
 	if err := row.Scan(&ct); err != nil {
 		return 0, err
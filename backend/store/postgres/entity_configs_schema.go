@@ -269,7 +269,7 @@ SELECT NOT EXISTS (
 );
 `
 
-const listEntityConfigQuery = `
+const listEntityConfigQueryTmpl = `
 -- This query lists entity configs from a given namespace.
 --
 SELECT
@@ -296,44 +296,13 @@ WHERE
 	($4 OR entity_configs.deleted_at IS NULL) AND
 	(namespaces.name = $1 OR $1 IS NULL) AND
 	entity_configs.updated_at > $5
-ORDER BY ( namespaces.name, entity_configs.name ) ASC
+	{{if ne .SelectorSQL ""}}AND {{.SelectorSQL}}{{end}}
+ORDER BY ( namespaces.name, entity_configs.name ) {{if .Descending}}DESC{{else}}ASC{{end}}
 LIMIT $2
 OFFSET $3
 `
 
-const listEntityConfigDescQuery = `
--- This query lists entities from a given namespace.
---
-SELECT
-	namespaces.name,
-	entity_configs.name,
-	entity_configs.selectors,
-	entity_configs.annotations,
-	entity_configs.created_by,
-	entity_configs.entity_class,
-	entity_configs.sensu_user,
-	entity_configs.subscriptions,
-	entity_configs.deregister,
-	entity_configs.deregistration,
-	entity_configs.keepalive_handlers,
-	entity_configs.redact,
-	entity_configs.id,
-	entity_configs.namespace_id,
-	entity_configs.created_at,
-	entity_configs.updated_at,
-	entity_configs.deleted_at
-FROM entity_configs
-LEFT OUTER JOIN namespaces ON namespaces.id = entity_configs.namespace_id
-WHERE
-	($4 OR entity_configs.deleted_at IS NULL) AND
-	(namespaces.name = $1 OR $1 IS NULL) AND
-	entity_configs.updated_at > $5
-ORDER BY ( namespaces.name, entity_configs.name ) DESC
-LIMIT $2
-OFFSET $3
-`
-
-const countEntityConfigQuery = `
+const countEntityConfigQueryTmpl = `
 -- This query counts entity configs from a given namespace and entity class.
 --
 SELECT
@@ -344,7 +313,8 @@ WHERE
 	(namespaces.name = $1 OR $1 IS NULL) AND
 	(entity_configs.entity_class = $2 OR $2 IS NULL) AND
 	namespaces.deleted_at IS NULL AND
-	entity_configs.deleted_at IS NULL;
+	entity_configs.deleted_at IS NULL
+	{{if ne .SelectorSQL ""}}AND {{.SelectorSQL}}{{end}};
 `
 
 const existsEntityConfigQuery = `
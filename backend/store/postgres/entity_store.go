@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
 )
 
 type EntityStore struct {
@@ -133,6 +135,19 @@ func (s *EntityStore) GetEntities(ctx context.Context, pred *store.SelectionPred
 	return entitiesFromConfigsAndStates(configs, states)
 }
 
+// CountEntities counts the entities in the given ctx's namespace that match
+// the entity selector of ctx.
+func (s *EntityStore) CountEntities(ctx context.Context) (int, error) {
+	namespace := corev2.ContextNamespace(ctx)
+	return NewEntityConfigStore(s.db).Count(ctx, namespace, "")
+}
+
+// EntityStoreSupportsFiltering returns true: the entity configs are selected
+// by the database.
+func (s *EntityStore) EntityStoreSupportsFiltering(context.Context) bool {
+	return true
+}
+
 // Create a list of corev2.Entity values from corev3 configs & states
 func entitiesFromConfigsAndStates(configs []*corev3.EntityConfig, states uniqueEntityStates) ([]*corev2.Entity, error) {
 	entities := []*corev2.Entity{}
@@ -228,3 +243,82 @@ func (s *EntityStore) UpdateEntity(ctx context.Context, entity *corev2.Entity) e
 	}
 	return nil
 }
+
+// UpdateEntities creates or updates the entities, and deletes the entities
+// named deletes, returned by update within the namespace stored in ctx, in a
+// single transaction. update is called with every entity of the namespace, as
+// read in the transaction, after an advisory lock of the namespace is taken so
+// that the concurrent bulk updates of the namespace are serialized.
+func (s *EntityStore) UpdateEntities(ctx context.Context, update func([]*corev2.Entity) ([]*corev2.Entity, []string, error)) error {
+	namespace := corev2.ContextNamespace(ctx)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			logger.WithError(err).Error("error rolling back transaction for UpdateEntities()")
+		}
+	}()
+	entityConfigStore := NewEntityConfigStore(tx)
+	entityStateStore := NewEntityStateStore(tx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1));", "entities/"+namespace); err != nil {
+		return &store.ErrInternal{Message: err.Error()}
+	}
+
+	// The existing entities are read regardless of the selector of ctx
+	configs, err := entityConfigStore.List(storev2.EntityContextWithSelector(ctx, nil), namespace, &store.SelectionPredicate{})
+	if err != nil {
+		return err
+	}
+	resources := namespacedResourceNames{}
+	for _, config := range configs {
+		resources[namespace] = append(resources[namespace], config.Metadata.Name)
+	}
+	states, err := entityStateStore.GetMultiple(ctx, resources)
+	if err != nil {
+		return err
+	}
+	existing, err := entitiesFromConfigsAndStates(configs, states)
+	if err != nil {
+		return err
+	}
+
+	entities, deletes, err := update(existing)
+	if err != nil {
+		return err
+	}
+	if len(entities) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	for _, entity := range entities {
+		if entity.Namespace == "" {
+			entity.Namespace = namespace
+		}
+		cfg, state := corev3.V2EntityToV3(entity)
+		if err := entityConfigStore.CreateOrUpdate(ctx, cfg); err != nil {
+			return fmt.Errorf("error updating entity config %q: %w", entity.Name, err)
+		}
+		if err := entityStateStore.CreateOrUpdate(ctx, state); err != nil {
+			return fmt.Errorf("error updating entity state %q: %w", entity.Name, err)
+		}
+	}
+
+	for _, name := range deletes {
+		var e *store.ErrNotFound
+		if err := entityConfigStore.Delete(ctx, namespace, name); err != nil && !errors.As(err, &e) {
+			return fmt.Errorf("error deleting entity config %q: %w", name, err)
+		}
+		if err := entityStateStore.Delete(ctx, namespace, name); err != nil && !errors.As(err, &e) {
+			return fmt.Errorf("error deleting entity state %q: %w", name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return &store.ErrInternal{Message: err.Error()}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	corev2 "github.com/sensu/core/v2"
@@ -213,3 +214,51 @@ func TestEntityDeleteByName(t *testing.T) {
 		}
 	})
 }
+
+func TestEntityUpdateEntities(t *testing.T) {
+	testWithPostgresStore(t, func(str storev2.Interface) {
+		db := str.(*Store).db
+		s := NewEntityStore(db)
+		ctx := context.WithValue(context.Background(), corev2.NamespaceKey, "default")
+
+		namespace := corev3.FixtureNamespace("default")
+		if err := str.GetNamespaceStore().CreateOrUpdate(ctx, namespace); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.UpdateEntity(ctx, corev2.FixtureEntity("stale")); err != nil {
+			t.Fatal(err)
+		}
+
+		var existing []*corev2.Entity
+		err := s.UpdateEntities(ctx, func(entities []*corev2.Entity) ([]*corev2.Entity, []string, error) {
+			existing = entities
+			return []*corev2.Entity{corev2.FixtureEntity("foo"), corev2.FixtureEntity("bar")}, []string{"stale", "missing"}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(existing) != 1 || existing[0].Name != "stale" {
+			t.Errorf("bad existing entities: got %v, want stale", existing)
+		}
+
+		// nothing is changed when update fails
+		err = s.UpdateEntities(ctx, func([]*corev2.Entity) ([]*corev2.Entity, []string, error) {
+			return nil, []string{"foo"}, errors.New("update failed")
+		})
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+
+		got, err := s.GetEntities(ctx, &store.SelectionPredicate{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := map[string]bool{}
+		for _, entity := range got {
+			names[entity.Name] = true
+		}
+		if len(names) != 2 || !names["foo"] || !names["bar"] {
+			t.Errorf("bad entities: got %v, want foo and bar", names)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
@@ -398,6 +399,35 @@ func (e *EventStore) UpdateEvent(ctx context.Context, event *corev2.Event) (uEve
 	return event, prevEvent, nil
 }
 
+// ReplaceEvent replaces the stored event of the entity and check of event with
+// event, without merging it with the stored event.
+func (e *EventStore) ReplaceEvent(ctx context.Context, event *corev2.Event) error {
+	if event == nil || event.Check == nil || event.Entity == nil {
+		return &store.ErrNotValid{Err: errors.New("event has no entity or check")}
+	}
+	persistEvent := event
+	if event.HasMetrics() {
+		// Metrics are not persisted
+		newEvent := *event
+		persistEvent = &newEvent
+		persistEvent.Metrics = nil
+	}
+	b, err := proto.Marshal(persistEvent)
+	if err != nil {
+		return &store.ErrEncode{Err: err}
+	}
+	serialized := snappy.Encode(nil, b)
+	row := e.db.QueryRow(ctx, replaceEvent, event.Entity.Namespace, event.Entity.Name, event.Check.Name, marshalSelectors(event), serialized)
+	var id int64
+	if err := row.Scan(&id); err != nil {
+		if err == pgx.ErrNoRows {
+			return &store.ErrNotFound{Key: path.Join(event.Entity.Namespace, event.Entity.Name, event.Check.Name)}
+		}
+		return &store.ErrInternal{Message: fmt.Sprintf("couldn't replace event: %s", err)}
+	}
+	return nil
+}
+
 func updateOccurrences(check *corev2.Check) {
 	if check == nil {
 		return
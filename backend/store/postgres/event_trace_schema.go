@@ -0,0 +1,57 @@
+package postgres
+
+const eventTraceSchema = `
+CREATE TABLE IF NOT EXISTS event_traces (
+	namespace	text NOT NULL,
+	entity		text NOT NULL,
+	check_name	text NOT NULL,
+	event_id	text NOT NULL,
+	steps		jsonb NOT NULL,
+	updated_at	timestamptz NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (namespace, entity, check_name)
+);
+`
+
+// eventTraceCascadeSchema references the stored event of each trace, so that
+// traces are deleted with their event, and drops the traces of events that
+// were already deleted.
+const eventTraceCascadeSchema = `
+ALTER TABLE event_traces ADD COLUMN IF NOT EXISTS event_ref bigint REFERENCES events (id) ON DELETE CASCADE;
+UPDATE event_traces SET event_ref = events.id
+FROM events JOIN namespaces ON events.namespace = namespaces.id
+WHERE namespaces.name = event_traces.namespace
+  AND events.entity_name = event_traces.entity
+  AND events.check_name = event_traces.check_name;
+DELETE FROM event_traces WHERE event_ref IS NULL;
+ALTER TABLE event_traces ALTER COLUMN event_ref SET NOT NULL;
+CREATE INDEX IF NOT EXISTS event_traces_event_ref_idx ON event_traces (event_ref);
+`
+
+// eventTraceAppend appends steps to the trace of an event, or replaces the
+// trace of a previous event of the same entity and check. Nothing is stored
+// if the event does not exist.
+//
+// $1: namespace (text)
+// $2: entity name (text)
+// $3: check name (text)
+// $4: event id (text)
+// $5: steps (jsonb array)
+const eventTraceAppend = `
+INSERT INTO event_traces (namespace, entity, check_name, event_id, steps, event_ref)
+SELECT $1, $2, $3, $4, $5, events.id
+FROM events JOIN namespaces ON events.namespace = namespaces.id
+WHERE namespaces.name = $1 AND events.entity_name = $2 AND events.check_name = $3
+ON CONFLICT (namespace, entity, check_name) DO UPDATE
+SET steps = CASE
+		WHEN event_traces.event_id = EXCLUDED.event_id THEN event_traces.steps || EXCLUDED.steps
+		ELSE EXCLUDED.steps
+	END,
+	event_id = EXCLUDED.event_id,
+	event_ref = EXCLUDED.event_ref,
+	updated_at = NOW();
+`
+
+const eventTraceGet = `
+SELECT event_id, steps FROM event_traces
+WHERE namespace = $1 AND entity = $2 AND check_name = $3;
+`
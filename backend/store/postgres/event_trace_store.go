@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// EventTraceStore stores the traces of events in postgres.
+type EventTraceStore struct {
+	db DBI
+}
+
+// NewEventTraceStore creates a new EventTraceStore.
+func NewEventTraceStore(db DBI) *EventTraceStore {
+	return &EventTraceStore{db: db}
+}
+
+// AppendEventTrace appends the steps of trace to the stored trace of the same
+// event, or replaces the stored trace if it is the trace of another event.
+func (s *EventTraceStore) AppendEventTrace(ctx context.Context, trace *store.EventTrace) error {
+	steps, err := json.Marshal(trace.Steps)
+	if err != nil {
+		return &store.ErrEncode{Key: path.Join(trace.Namespace, trace.Entity, trace.Check), Err: err}
+	}
+	if _, err := s.db.Exec(ctx, eventTraceAppend, trace.Namespace, trace.Entity, trace.Check, trace.EventID, steps); err != nil {
+		return &store.ErrInternal{Message: fmt.Sprintf("could not store event trace: %s", err)}
+	}
+	return nil
+}
+
+// GetEventTrace gets the trace of the most recent traced event of the entity
+// and check.
+func (s *EventTraceStore) GetEventTrace(ctx context.Context, namespace, entity, check string) (*store.EventTrace, error) {
+	trace := &store.EventTrace{
+		Namespace: namespace,
+		Entity:    entity,
+		Check:     check,
+	}
+	var steps []byte
+	row := s.db.QueryRow(ctx, eventTraceGet, namespace, entity, check)
+	if err := row.Scan(&trace.EventID, &steps); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &store.ErrNotFound{Key: path.Join(namespace, entity, check)}
+		}
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("could not get event trace: %s", err)}
+	}
+	if err := json.Unmarshal(steps, &trace.Steps); err != nil {
+		return nil, &store.ErrDecode{Key: path.Join(namespace, entity, check), Err: err}
+	}
+	return trace, nil
+}
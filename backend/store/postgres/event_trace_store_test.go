@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+func TestEventTraceDeletedWithEvent(t *testing.T) {
+	testWithPostgresEventStore(t, func(s store.EventStore, sv2 storev2.Interface) {
+		pgStore := sv2.(*Store)
+		traces := NewEventTraceStore(pgStore.db)
+		event := corev2.FixtureEvent("entity1", "check1")
+		ctx := context.WithValue(context.Background(), corev2.NamespaceKey, event.Entity.Namespace)
+
+		trace := store.NewEventTrace(event)
+		trace.Steps = []store.EventTraceStep{{Time: time.Now(), Component: "eventd", Step: "store"}}
+
+		// the trace of an event that isn't stored is not stored either
+		if err := traces.AppendEventTrace(ctx, trace); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := traces.GetEventTrace(ctx, "default", "entity1", "check1"); !errors.As(err, new(*store.ErrNotFound)) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+
+		if _, _, err := s.UpdateEvent(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		if err := traces.AppendEventTrace(ctx, trace); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := traces.GetEventTrace(ctx, "default", "entity1", "check1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.DeleteEventByEntityCheck(ctx, "entity1", "check1"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := traces.GetEventTrace(ctx, "default", "entity1", "check1"); !errors.As(err, new(*store.ErrNotFound)) {
+			t.Fatalf("expected the trace to be deleted with its event, got %v", err)
+		}
+	})
+}
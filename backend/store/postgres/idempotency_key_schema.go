@@ -0,0 +1,39 @@
+package postgres
+
+const idempotencyKeySchema = `
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	namespace	text NOT NULL,
+	key		text NOT NULL,
+	claimed_at	timestamptz NOT NULL,
+	expires_at	timestamptz NOT NULL,
+	PRIMARY KEY (namespace, key)
+);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+`
+
+// idempotencyKeyClaim claims a key, unless the same key is claimed and not
+// expired. No row is returned if the key is not claimed.
+//
+// $1: namespace (text)
+// $2: key (text)
+// $3: claim time (timestamptz)
+// $4: expiration time (timestamptz)
+const idempotencyKeyClaim = `
+INSERT INTO idempotency_keys (namespace, key, claimed_at, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (namespace, key) DO UPDATE
+SET claimed_at = EXCLUDED.claimed_at,
+	expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= EXCLUDED.claimed_at
+RETURNING key;
+`
+
+const idempotencyKeyRelease = `
+DELETE FROM idempotency_keys
+WHERE namespace = $1 AND key = $2 AND expires_at > NOW();
+`
+
+const idempotencyKeyPurge = `
+DELETE FROM idempotency_keys
+WHERE expires_at <= $1;
+`
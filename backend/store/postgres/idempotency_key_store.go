@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// idempotencyKeyPurgeInterval is the minimum interval between the purges of
+// the expired idempotency keys.
+const idempotencyKeyPurgeInterval = time.Minute
+
+// IdempotencyKeyStore stores idempotency keys in postgres.
+type IdempotencyKeyStore struct {
+	db DBI
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+// NewIdempotencyKeyStore creates a new IdempotencyKeyStore.
+func NewIdempotencyKeyStore(db DBI) *IdempotencyKeyStore {
+	return &IdempotencyKeyStore{db: db}
+}
+
+// ClaimIdempotencyKey claims key, unless the same key of the same namespace
+// is claimed and not expired.
+func (s *IdempotencyKeyStore) ClaimIdempotencyKey(ctx context.Context, key *store.IdempotencyKey) (bool, error) {
+	s.purge(ctx, key.ClaimedAt)
+	var claimed string
+	row := s.db.QueryRow(ctx, idempotencyKeyClaim, key.Namespace, key.Key, key.ClaimedAt, key.ExpiresAt)
+	if err := row.Scan(&claimed); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, &store.ErrInternal{Message: fmt.Sprintf("could not claim idempotency key: %s", err)}
+	}
+	return true, nil
+}
+
+// ReleaseIdempotencyKey releases the key of namespace.
+func (s *IdempotencyKeyStore) ReleaseIdempotencyKey(ctx context.Context, namespace, key string) error {
+	tag, err := s.db.Exec(ctx, idempotencyKeyRelease, namespace, key)
+	if err != nil {
+		return &store.ErrInternal{Message: fmt.Sprintf("could not release idempotency key: %s", err)}
+	}
+	if tag.RowsAffected() == 0 {
+		return &store.ErrNotFound{Key: path.Join(namespace, key)}
+	}
+	return nil
+}
+
+// purge deletes the keys expired at now, at most once per
+// idempotencyKeyPurgeInterval. The keys are claimed again once expired, so
+// the purge only bounds the size of the table.
+func (s *IdempotencyKeyStore) purge(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastPurge) < idempotencyKeyPurgeInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPurge = now
+	s.mu.Unlock()
+	if _, err := s.db.Exec(ctx, idempotencyKeyPurge, now); err != nil {
+		logger.WithError(err).Warn("could not purge the expired idempotency keys")
+	}
+}
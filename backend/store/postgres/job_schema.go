@@ -0,0 +1,75 @@
+package postgres
+
+const jobSchema = `
+CREATE TABLE IF NOT EXISTS jobs (
+	namespace	text NOT NULL,
+	id		text NOT NULL,
+	kind		text NOT NULL,
+	params		jsonb NOT NULL DEFAULT '{}',
+	status		text NOT NULL,
+	done		integer NOT NULL DEFAULT 0,
+	total		integer NOT NULL DEFAULT 0,
+	error		text NOT NULL DEFAULT '',
+	created_at	timestamptz NOT NULL,
+	updated_at	timestamptz NOT NULL,
+	PRIMARY KEY (namespace, id)
+);
+`
+
+const jobRetrySchema = `
+ALTER TABLE jobs
+	ADD COLUMN IF NOT EXISTS attempts integer NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS max_attempts integer NOT NULL DEFAULT 1,
+	ADD COLUMN IF NOT EXISTS retry_at timestamptz;
+CREATE INDEX IF NOT EXISTS jobs_status_idx ON jobs (status);
+`
+
+// jobCreate creates a job.
+//
+// $1: namespace (text)
+// $2: job id (text)
+// $3: job kind (text)
+// $4: job parameters (jsonb)
+// $5: status (text)
+// $6: maximum number of attempts (integer)
+// $7: creation time (timestamptz)
+const jobCreate = `
+INSERT INTO jobs (namespace, id, kind, params, status, max_attempts, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7);
+`
+
+const jobColumns = `namespace, id, kind, params, status, done, total, error, attempts, max_attempts, retry_at, created_at, updated_at`
+
+const jobGet = `
+SELECT ` + jobColumns + `
+FROM jobs
+WHERE namespace = $1 AND id = $2;
+`
+
+// jobList lists jobs.
+//
+// $1: namespace, or an empty string for every namespace (text)
+// $2: status, or an empty string for any status (text)
+const jobList = `
+SELECT ` + jobColumns + `
+FROM jobs
+WHERE ($1 = '' OR namespace = $1) AND ($2 = '' OR status = $2)
+ORDER BY created_at, namespace, id;
+`
+
+// jobUpdate updates the status and progress of a job.
+//
+// $1: namespace (text)
+// $2: job id (text)
+// $3: status (text)
+// $4: done units of work (integer)
+// $5: total units of work (integer)
+// $6: error (text)
+// $7: attempts (integer)
+// $8: retry time, or NULL (timestamptz)
+// $9: update time (timestamptz)
+const jobUpdate = `
+UPDATE jobs
+SET status = $3, done = $4, total = $5, error = $6, attempts = $7, retry_at = $8, updated_at = $9
+WHERE namespace = $1 AND id = $2;
+`
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// JobStore stores background jobs in postgres.
+type JobStore struct {
+	db DBI
+}
+
+// NewJobStore creates a new JobStore.
+func NewJobStore(db DBI) *JobStore {
+	return &JobStore{db: db}
+}
+
+// CreateJob creates job.
+func (s *JobStore) CreateJob(ctx context.Context, job *store.Job) error {
+	params := job.Params
+	if params == nil {
+		params = map[string]string{}
+	}
+	if _, err := s.db.Exec(ctx, jobCreate, job.Namespace, job.ID, job.Kind, params, string(job.Status), job.MaxAttempts, job.CreatedAt); err != nil {
+		return &store.ErrInternal{Message: fmt.Sprintf("could not create job: %s", err)}
+	}
+	return nil
+}
+
+// GetJob gets the job of namespace with the given id.
+func (s *JobStore) GetJob(ctx context.Context, namespace, id string) (*store.Job, error) {
+	job, err := scanJob(s.db.QueryRow(ctx, jobGet, namespace, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &store.ErrNotFound{Key: path.Join(namespace, id)}
+		}
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("could not get job: %s", err)}
+	}
+	return job, nil
+}
+
+// ListJobs lists the jobs of namespace that have the given status.
+func (s *JobStore) ListJobs(ctx context.Context, namespace string, status store.JobStatus) ([]*store.Job, error) {
+	rows, err := s.db.Query(ctx, jobList, namespace, string(status))
+	if err != nil {
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("could not list jobs: %s", err)}
+	}
+	defer rows.Close()
+	jobs := []*store.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, &store.ErrInternal{Message: fmt.Sprintf("could not list jobs: %s", err)}
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("could not list jobs: %s", err)}
+	}
+	return jobs, nil
+}
+
+// UpdateJob updates the status, progress, error, attempts and retry time of
+// job.
+func (s *JobStore) UpdateJob(ctx context.Context, job *store.Job) error {
+	var retryAt sql.NullTime
+	if !job.RetryAt.IsZero() {
+		retryAt = sql.NullTime{Time: job.RetryAt, Valid: true}
+	}
+	tag, err := s.db.Exec(ctx, jobUpdate, job.Namespace, job.ID, string(job.Status), job.Done, job.Total, job.Error, job.Attempts, retryAt, job.UpdatedAt)
+	if err != nil {
+		return &store.ErrInternal{Message: fmt.Sprintf("could not update job: %s", err)}
+	}
+	if tag.RowsAffected() == 0 {
+		return &store.ErrNotFound{Key: path.Join(job.Namespace, job.ID)}
+	}
+	return nil
+}
+
+func scanJob(row pgx.Row) (*store.Job, error) {
+	var job store.Job
+	var status string
+	var retryAt sql.NullTime
+	err := row.Scan(&job.Namespace, &job.ID, &job.Kind, &job.Params, &status, &job.Done, &job.Total, &job.Error, &job.Attempts, &job.MaxAttempts, &retryAt, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Status = store.JobStatus(status)
+	if retryAt.Valid {
+		job.RetryAt = retryAt.Time
+	}
+	return &job, nil
+}
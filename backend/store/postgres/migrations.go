@@ -176,6 +176,51 @@ var Migrations = []migration.Migrator{
 		_, err := tx.Exec(context.Background(), "UPDATE configuration SET etag = digest(resource::text, 'sha1')")
 		return err
 	},
+	// Migration 29
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), eventTraceSchema)
+		return err
+	},
+	// Migration 30
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), notificationCounterSchema)
+		return err
+	},
+	// Migration 31
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), remediationLockSchema)
+		return err
+	},
+	// Migration 32
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), jobSchema)
+		return err
+	},
+	// Migration 33
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), jobRetrySchema)
+		return err
+	},
+	// Migration 34
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), eventTraceCascadeSchema)
+		return err
+	},
+	// Migration 35
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), idempotencyKeySchema)
+		return err
+	},
+	// Migration 36
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), configAuditSchema)
+		return err
+	},
+	// Migration 37
+	func(tx migration.LimitedTx) error {
+		_, err := tx.Exec(context.Background(), notificationCounterCascadeSchema)
+		return err
+	},
 }
 
 type eventRecord struct {
@@ -0,0 +1,72 @@
+package postgres
+
+const notificationCounterSchema = `
+CREATE TABLE IF NOT EXISTS notification_counters (
+	namespace	text NOT NULL,
+	pipeline	text NOT NULL,
+	workflow	text NOT NULL,
+	entity		text NOT NULL,
+	check_name	text NOT NULL,
+	window_start	timestamptz NOT NULL,
+	count		bigint NOT NULL,
+	PRIMARY KEY (namespace, pipeline, workflow, entity, check_name)
+);
+`
+
+// notificationCounterCascadeSchema references the stored event of each
+// counter, so that counters are deleted with their event, and drops the
+// counters of events that were already deleted.
+const notificationCounterCascadeSchema = `
+ALTER TABLE notification_counters ADD COLUMN IF NOT EXISTS event_ref bigint REFERENCES events (id) ON DELETE CASCADE;
+UPDATE notification_counters SET event_ref = events.id
+FROM events JOIN namespaces ON events.namespace = namespaces.id
+WHERE namespaces.name = notification_counters.namespace
+  AND events.entity_name = notification_counters.entity
+  AND events.check_name = notification_counters.check_name;
+DELETE FROM notification_counters WHERE event_ref IS NULL;
+ALTER TABLE notification_counters ALTER COLUMN event_ref SET NOT NULL;
+CREATE INDEX IF NOT EXISTS notification_counters_event_ref_idx ON notification_counters (event_ref);
+CREATE INDEX IF NOT EXISTS notification_counters_window_start_idx ON notification_counters (window_start);
+`
+
+// notificationCounterIncrement counts a notification in a window, unless the
+// maximum number of notifications of the window is reached. A new window
+// resets the count. Nothing is counted if the event does not exist. The row
+// returned reports whether the event exists, and whether the notification
+// was counted.
+//
+// $1: namespace (text)
+// $2: pipeline name (text)
+// $3: workflow name (text)
+// $4: entity name (text)
+// $5: check name (text)
+// $6: window start (timestamptz)
+// $7: maximum number of notifications per window (bigint)
+const notificationCounterIncrement = `
+WITH event AS (
+	SELECT events.id FROM events JOIN namespaces ON events.namespace = namespaces.id
+	WHERE namespaces.name = $1 AND events.entity_name = $4 AND events.check_name = $5
+), counted AS (
+	INSERT INTO notification_counters (namespace, pipeline, workflow, entity, check_name, window_start, count, event_ref)
+	SELECT $1, $2, $3, $4, $5, $6, 1, event.id FROM event
+	ON CONFLICT (namespace, pipeline, workflow, entity, check_name) DO UPDATE
+	SET count = CASE
+			WHEN notification_counters.window_start = EXCLUDED.window_start THEN notification_counters.count + 1
+			ELSE 1
+		END,
+		window_start = EXCLUDED.window_start,
+		event_ref = EXCLUDED.event_ref
+	WHERE notification_counters.window_start <> EXCLUDED.window_start
+		OR notification_counters.count < $7
+	RETURNING count
+)
+SELECT EXISTS (SELECT 1 FROM event), EXISTS (SELECT 1 FROM counted);
+`
+
+// notificationCounterPurge deletes the counters whose window ended before $1.
+// A counter is reset by the next notification of a new window, so this only
+// drops the counters of the workflows, handlers and events that are gone.
+const notificationCounterPurge = `
+DELETE FROM notification_counters
+WHERE window_start < $1;
+`
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// notificationCounterPurgeInterval is the minimum interval between the purges
+// of the counters of the past windows.
+const notificationCounterPurgeInterval = time.Minute
+
+// NotificationCounterStore counts the notifications of pipeline workflows in
+// postgres, per clock hour. The counters are deleted with their event, and
+// once their window has ended.
+type NotificationCounterStore struct {
+	db DBI
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+// NewNotificationCounterStore creates a new NotificationCounterStore.
+func NewNotificationCounterStore(db DBI) *NotificationCounterStore {
+	return &NotificationCounterStore{db: db}
+}
+
+// IncrementNotifications counts a notification in the hour of now, unless max
+// notifications were already counted in that hour. The notifications of
+// events that are not stored can't be counted, and are never throttled.
+func (s *NotificationCounterStore) IncrementNotifications(ctx context.Context, key store.NotificationKey, now time.Time, max int64) (bool, error) {
+	s.purge(ctx, now)
+	window := now.UTC().Truncate(time.Hour)
+	var found, counted bool
+	row := s.db.QueryRow(ctx, notificationCounterIncrement, key.Namespace, key.Pipeline, key.Workflow, key.Entity, key.Check, window, max)
+	if err := row.Scan(&found, &counted); err != nil {
+		return false, &store.ErrInternal{Message: fmt.Sprintf("could not count notification: %s", err)}
+	}
+	return counted || !found, nil
+}
+
+// purge deletes the counters of the windows that ended before now, at most
+// once per notificationCounterPurgeInterval.
+func (s *NotificationCounterStore) purge(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastPurge) < notificationCounterPurgeInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPurge = now
+	s.mu.Unlock()
+	if _, err := s.db.Exec(ctx, notificationCounterPurge, now.UTC().Truncate(time.Hour)); err != nil {
+		logger.WithError(err).Warn("could not purge the notification counters of past windows")
+	}
+}
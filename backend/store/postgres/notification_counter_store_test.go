@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+)
+
+func countNotificationCounters(t *testing.T, db DBI) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(context.Background(), "SELECT count(*) FROM notification_counters").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestNotificationCounterDeletedWithEvent(t *testing.T) {
+	testWithPostgresEventStore(t, func(s store.EventStore, sv2 storev2.Interface) {
+		pgStore := sv2.(*Store)
+		counters := NewNotificationCounterStore(pgStore.db)
+		event := corev2.FixtureEvent("entity1", "check1")
+		ctx := context.WithValue(context.Background(), corev2.NamespaceKey, event.Entity.Namespace)
+		key := store.NotificationKey{Namespace: "default", Pipeline: "pipeline1", Workflow: "workflow1", Entity: "entity1", Check: "check1"}
+		now := time.Now()
+
+		// the notifications of an event that isn't stored are not counted,
+		// nor throttled
+		for i := 0; i < 2; i++ {
+			allowed, err := counters.IncrementNotifications(ctx, key, now, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !allowed {
+				t.Fatal("expected the notification to be allowed")
+			}
+		}
+		if got := countNotificationCounters(t, pgStore.db); got != 0 {
+			t.Fatalf("expected no counter, got %d", got)
+		}
+
+		if _, _, err := s.UpdateEvent(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		if allowed, err := counters.IncrementNotifications(ctx, key, now, 1); err != nil || !allowed {
+			t.Fatalf("expected the first notification to be allowed, got %v, %v", allowed, err)
+		}
+		if allowed, err := counters.IncrementNotifications(ctx, key, now, 1); err != nil || allowed {
+			t.Fatalf("expected the second notification to be throttled, got %v, %v", allowed, err)
+		}
+
+		if err := s.DeleteEventByEntityCheck(ctx, "entity1", "check1"); err != nil {
+			t.Fatal(err)
+		}
+		if got := countNotificationCounters(t, pgStore.db); got != 0 {
+			t.Fatalf("expected the counter to be deleted with its event, got %d", got)
+		}
+	})
+}
+
+func TestNotificationCounterPurge(t *testing.T) {
+	testWithPostgresEventStore(t, func(s store.EventStore, sv2 storev2.Interface) {
+		pgStore := sv2.(*Store)
+		counters := NewNotificationCounterStore(pgStore.db)
+		event := corev2.FixtureEvent("entity1", "check1")
+		ctx := context.WithValue(context.Background(), corev2.NamespaceKey, event.Entity.Namespace)
+		if _, _, err := s.UpdateEvent(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		key := store.NotificationKey{Namespace: "default", Pipeline: "pipeline1", Workflow: "workflow1", Entity: "entity1", Check: "check1"}
+		now := time.Now()
+		if _, err := counters.IncrementNotifications(ctx, key, now, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		// the counter of a workflow that is gone is purged once its window
+		// has ended
+		key.Workflow = "workflow2"
+		if _, err := counters.IncrementNotifications(ctx, key, now.Add(time.Hour+notificationCounterPurgeInterval), 1); err != nil {
+			t.Fatal(err)
+		}
+		if got := countNotificationCounters(t, pgStore.db); got != 1 {
+			t.Fatalf("expected the counter of the past window to be purged, got %d counters", got)
+		}
+	})
+}
@@ -43,3 +43,15 @@ func open(ctx context.Context, config *pgxpool.Config, retryForever bool, migrat
 func Open(ctx context.Context, config *pgxpool.Config, retryForever bool) (*pgxpool.Pool, error) {
 	return open(ctx, config, retryForever, Migrations)
 }
+
+// Migrate upgrades the database to the latest schema version. Unlike Open, it
+// makes a single attempt, so that the caller can retry it while the backend
+// serves its API.
+func Migrate(config *pgxpool.Config) error {
+	db, err := migration.Open(config, Migrations)
+	if err != nil {
+		return fmt.Errorf("error migrating database to latest version: %v", err)
+	}
+	db.Close()
+	return nil
+}
@@ -16,3 +16,6 @@ var getKeepaliveCountsByNamespaceQuery string
 
 //go:embed getEventCountsByNamespaceQuery.sql
 var getEventCountsByNamespaceQuery string
+
+//go:embed replaceEvent.sql
+var replaceEvent string
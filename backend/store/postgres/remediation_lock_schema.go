@@ -0,0 +1,46 @@
+package postgres
+
+const remediationLockSchema = `
+CREATE TABLE IF NOT EXISTS remediation_locks (
+	namespace	text NOT NULL,
+	handler		text NOT NULL,
+	entity		text NOT NULL,
+	event_id	text NOT NULL,
+	acquired_at	timestamptz NOT NULL,
+	expires_at	timestamptz NOT NULL,
+	PRIMARY KEY (namespace, handler, entity)
+);
+`
+
+// remediationLockAcquire acquires a lock, unless an unexpired lock of the
+// same handler and entity is held. No row is returned if the lock is not
+// acquired.
+//
+// $1: namespace (text)
+// $2: handler name (text)
+// $3: entity name (text)
+// $4: event id (text)
+// $5: acquisition time (timestamptz)
+// $6: expiration time (timestamptz)
+const remediationLockAcquire = `
+INSERT INTO remediation_locks (namespace, handler, entity, event_id, acquired_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (namespace, handler, entity) DO UPDATE
+SET event_id = EXCLUDED.event_id,
+	acquired_at = EXCLUDED.acquired_at,
+	expires_at = EXCLUDED.expires_at
+WHERE remediation_locks.expires_at <= EXCLUDED.acquired_at
+RETURNING handler;
+`
+
+const remediationLockList = `
+SELECT namespace, handler, entity, event_id, acquired_at, expires_at
+FROM remediation_locks
+WHERE ($1 = '' OR namespace = $1) AND expires_at > NOW()
+ORDER BY namespace, handler, entity;
+`
+
+const remediationLockRelease = `
+DELETE FROM remediation_locks
+WHERE namespace = $1 AND handler = $2 AND entity = $3 AND expires_at > NOW();
+`
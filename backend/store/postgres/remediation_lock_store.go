@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// RemediationLockStore stores remediation locks in postgres.
+type RemediationLockStore struct {
+	db DBI
+}
+
+// NewRemediationLockStore creates a new RemediationLockStore.
+func NewRemediationLockStore(db DBI) *RemediationLockStore {
+	return &RemediationLockStore{db: db}
+}
+
+// AcquireRemediationLock acquires lock, unless a lock of the same handler and
+// entity is held and not expired.
+func (s *RemediationLockStore) AcquireRemediationLock(ctx context.Context, lock *store.RemediationLock) (bool, error) {
+	var handler string
+	row := s.db.QueryRow(ctx, remediationLockAcquire, lock.Namespace, lock.Handler, lock.Entity, lock.Event, lock.AcquiredAt, lock.ExpiresAt)
+	if err := row.Scan(&handler); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, &store.ErrInternal{Message: fmt.Sprintf("could not acquire remediation lock: %s", err)}
+	}
+	return true, nil
+}
+
+// ListRemediationLocks lists the held locks of namespace, or of every
+// namespace if namespace is empty.
+func (s *RemediationLockStore) ListRemediationLocks(ctx context.Context, namespace string) ([]*store.RemediationLock, error) {
+	rows, err := s.db.Query(ctx, remediationLockList, namespace)
+	if err != nil {
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("could not list remediation locks: %s", err)}
+	}
+	defer rows.Close()
+	locks := []*store.RemediationLock{}
+	for rows.Next() {
+		var lock store.RemediationLock
+		if err := rows.Scan(&lock.Namespace, &lock.Handler, &lock.Entity, &lock.Event, &lock.AcquiredAt, &lock.ExpiresAt); err != nil {
+			return nil, &store.ErrInternal{Message: fmt.Sprintf("could not list remediation locks: %s", err)}
+		}
+		locks = append(locks, &lock)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &store.ErrInternal{Message: fmt.Sprintf("could not list remediation locks: %s", err)}
+	}
+	return locks, nil
+}
+
+// ReleaseRemediationLock releases the lock of handler and entity.
+func (s *RemediationLockStore) ReleaseRemediationLock(ctx context.Context, namespace, handler, entity string) error {
+	tag, err := s.db.Exec(ctx, remediationLockRelease, namespace, handler, entity)
+	if err != nil {
+		return &store.ErrInternal{Message: fmt.Sprintf("could not release remediation lock: %s", err)}
+	}
+	if tag.RowsAffected() == 0 {
+		return &store.ErrNotFound{Key: path.Join(namespace, handler, entity)}
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+WITH ns AS (
+	SELECT id FROM namespaces
+	WHERE namespaces.name = $1
+	LIMIT 1
+)
+UPDATE events
+SET selectors = $4, serialized = $5
+FROM ns
+WHERE namespace = ns.id
+  AND entity_name = $2
+  AND check_name = $3
+RETURNING events.id;
@@ -178,7 +178,15 @@ func (s *ConfigStore) Initialize(ctx context.Context, fn storev2.InitializeFunc)
 	if cerr != nil {
 		return cerr
 	}
-	defer tx.Commit(ctx)
+	defer func() {
+		// roll back so that a failed initialization leaves no partial state
+		// behind, and can be safely retried.
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
 	return fn(ctx, &Store{db: tx})
 }
 
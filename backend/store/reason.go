@@ -0,0 +1,58 @@
+package store
+
+import "errors"
+
+// The reasons of the store errors are machine-readable descriptions of the
+// errors, which are returned by the API with the errors they cause.
+const (
+	ReasonAlreadyExists      = "already_exists"
+	ReasonDecodeFailed       = "decode_failed"
+	ReasonEncodeFailed       = "encode_failed"
+	ReasonNamespaceMissing   = "namespace_missing"
+	ReasonNamespaceNotEmpty  = "namespace_not_empty"
+	ReasonNotFound           = "not_found"
+	ReasonNotValid           = "not_valid"
+	ReasonPreconditionFailed = "precondition_failed"
+	ReasonInternal           = "store_internal"
+	ReasonUnavailable        = "store_unavailable"
+)
+
+// Reason returns the reason of the store error err, or an empty string if err
+// is not a store error.
+func Reason(err error) string {
+	var (
+		alreadyExists      *ErrAlreadyExists
+		decode             *ErrDecode
+		encode             *ErrEncode
+		namespaceMissing   *ErrNamespaceMissing
+		namespaceNotEmpty  *ErrNamespaceNotEmpty
+		notFound           *ErrNotFound
+		notValid           *ErrNotValid
+		preconditionFailed *ErrPreconditionFailed
+		internal           *ErrInternal
+		unavailable        *ErrUnavailable
+	)
+	switch {
+	case errors.As(err, &alreadyExists):
+		return ReasonAlreadyExists
+	case errors.As(err, &decode):
+		return ReasonDecodeFailed
+	case errors.As(err, &encode):
+		return ReasonEncodeFailed
+	case errors.As(err, &namespaceMissing):
+		return ReasonNamespaceMissing
+	case errors.As(err, &namespaceNotEmpty):
+		return ReasonNamespaceNotEmpty
+	case errors.As(err, &notFound):
+		return ReasonNotFound
+	case errors.As(err, &notValid):
+		return ReasonNotValid
+	case errors.As(err, &preconditionFailed):
+		return ReasonPreconditionFailed
+	case errors.As(err, &internal):
+		return ReasonInternal
+	case errors.As(err, &unavailable):
+		return ReasonUnavailable
+	}
+	return ""
+}
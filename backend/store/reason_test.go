@@ -0,0 +1,32 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"already exists", &ErrAlreadyExists{Key: "foo"}, ReasonAlreadyExists},
+		{"not found", &ErrNotFound{Key: "foo"}, ReasonNotFound},
+		{"wrapped not found", fmt.Errorf("get: %w", &ErrNotFound{Key: "foo"}), ReasonNotFound},
+		{"namespace missing", &ErrNamespaceMissing{Namespace: "dev"}, ReasonNamespaceMissing},
+		{"precondition failed", &ErrPreconditionFailed{Key: "foo"}, ReasonPreconditionFailed},
+		{"internal", &ErrInternal{Message: "boom"}, ReasonInternal},
+		{"unavailable", &ErrUnavailable{Message: "boom"}, ReasonUnavailable},
+		{"other error", errors.New("boom"), ""},
+		{"nil", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Reason(tt.err); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+// RemediationCooldownAnnotation is the format of the pipeline annotation that
+// sets the remediation cooldown of a workflow of the pipeline, as a duration.
+// The handler of the workflow is run at most once per cooldown for an entity,
+// across all pipelines and backends.
+const RemediationCooldownAnnotation = "sensu.io/workflows.%s.remediation_cooldown"
+
+// RemediationLock is held while a remediation handler must not run again for
+// an entity.
+type RemediationLock struct {
+	Namespace string `json:"namespace"`
+	Handler   string `json:"handler"`
+	Entity    string `json:"entity"`
+	// Event is the id of the event that acquired the lock.
+	Event      string    `json:"event"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// RemediationLockStore stores the remediation locks shared by all backends.
+type RemediationLockStore interface {
+	// AcquireRemediationLock acquires lock, unless a lock of the same handler
+	// and entity is held and not expired. It returns false if the lock was
+	// not acquired.
+	AcquireRemediationLock(ctx context.Context, lock *RemediationLock) (bool, error)
+
+	// ListRemediationLocks lists the held locks of namespace, or of every
+	// namespace if namespace is empty.
+	ListRemediationLocks(ctx context.Context, namespace string) ([]*RemediationLock, error)
+
+	// ReleaseRemediationLock releases the lock of handler and entity. It
+	// returns ErrNotFound if the lock is not held.
+	ReleaseRemediationLock(ctx context.Context, namespace, handler, entity string) error
+}
+
+// RemediationCooldownFromPipeline returns the remediation cooldown of the
+// workflow named workflow, as set by the annotations of pipeline. A zero
+// cooldown means that the workflow is not locked.
+func RemediationCooldownFromPipeline(pipeline *corev2.Pipeline, workflow string) (time.Duration, error) {
+	key := fmt.Sprintf(RemediationCooldownAnnotation, workflow)
+	value, ok := pipeline.ObjectMeta.Annotations[key]
+	if !ok {
+		return 0, nil
+	}
+	cooldown, err := time.ParseDuration(value)
+	if err != nil || cooldown < 0 {
+		return 0, fmt.Errorf("annotation %s must be a non-negative duration, got %q", key, value)
+	}
+	return cooldown, nil
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+func TestRemediationCooldownFromPipeline(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "no annotation"},
+		{name: "cooldown", value: "30m", want: 30 * time.Minute},
+		{name: "not a duration", value: "soon", wantErr: true},
+		{name: "negative", value: "-1m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := corev2.FixturePipeline("pipeline", "default")
+			if tt.value != "" {
+				pipeline.Annotations = map[string]string{
+					fmt.Sprintf(RemediationCooldownAnnotation, "remediate"): tt.value,
+				}
+			}
+			got, err := RemediationCooldownFromPipeline(pipeline, "remediate")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RemediationCooldownFromPipeline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RemediationCooldownFromPipeline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
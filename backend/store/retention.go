@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+)
+
+const (
+	// ScrubOutputAfterAnnotation is the namespace annotation that sets the
+	// age, as a duration, after which the outputs of the checks and hooks of
+	// the stored events of the namespace are scrubbed. The status history of
+	// the events is kept.
+	ScrubOutputAfterAnnotation = "sensu.io/retention.scrub_output_after"
+
+	// AnonymizeAfterAnnotation is the namespace annotation that sets the age,
+	// as a duration, after which the stored events of the namespace are
+	// anonymized: their outputs, commands, environment variables, metrics,
+	// labels, annotations, and the system information and user of their
+	// entity are removed. The status history of the events is kept.
+	AnonymizeAfterAnnotation = "sensu.io/retention.anonymize_after"
+
+	// DeleteAfterAnnotation is the namespace annotation that sets the age, as
+	// a duration, after which the stored events of the namespace are deleted.
+	DeleteAfterAnnotation = "sensu.io/retention.delete_after"
+)
+
+// RetentionPolicy is the retention policy of the stored events of a
+// namespace. The age of an event is the time elapsed since its last update,
+// and a zero duration disables an action.
+type RetentionPolicy struct {
+	ScrubOutputAfter time.Duration
+	AnonymizeAfter   time.Duration
+	DeleteAfter      time.Duration
+}
+
+// IsZero returns true if the policy retains events as they are.
+func (p RetentionPolicy) IsZero() bool {
+	return p.ScrubOutputAfter == 0 && p.AnonymizeAfter == 0 && p.DeleteAfter == 0
+}
+
+// RetentionPolicyFromNamespace returns the retention policy set by the
+// annotations of namespace.
+func RetentionPolicyFromNamespace(namespace *corev3.Namespace) (RetentionPolicy, error) {
+	var policy RetentionPolicy
+	if namespace == nil || namespace.Metadata == nil {
+		return policy, nil
+	}
+	for key, duration := range map[string]*time.Duration{
+		ScrubOutputAfterAnnotation: &policy.ScrubOutputAfter,
+		AnonymizeAfterAnnotation:   &policy.AnonymizeAfter,
+		DeleteAfterAnnotation:      &policy.DeleteAfter,
+	} {
+		value, ok := namespace.Metadata.Annotations[key]
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return RetentionPolicy{}, fmt.Errorf("annotation %s must be a non-negative duration, got %q", key, value)
+		}
+		*duration = d
+	}
+	return policy, nil
+}
+
+// EventReplacer replaces stored events.
+type EventReplacer interface {
+	// ReplaceEvent replaces the stored event of the entity and check of event
+	// with event, as it is. Unlike UpdateEvent, the event is not merged with
+	// the stored event. It returns ErrNotFound if no event is stored.
+	ReplaceEvent(ctx context.Context, event *corev2.Event) error
+}
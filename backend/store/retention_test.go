@@ -0,0 +1,60 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	corev3 "github.com/sensu/core/v3"
+)
+
+func TestRetentionPolicyFromNamespace(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        RetentionPolicy
+		wantErr     bool
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name: "policy",
+			annotations: map[string]string{
+				ScrubOutputAfterAnnotation: "24h",
+				AnonymizeAfterAnnotation:   "720h",
+				DeleteAfterAnnotation:      "2160h",
+			},
+			want: RetentionPolicy{
+				ScrubOutputAfter: 24 * time.Hour,
+				AnonymizeAfter:   720 * time.Hour,
+				DeleteAfter:      2160 * time.Hour,
+			},
+		},
+		{
+			name:        "invalid duration",
+			annotations: map[string]string{DeleteAfterAnnotation: "30 days"},
+			wantErr:     true,
+		},
+		{
+			name:        "negative duration",
+			annotations: map[string]string{ScrubOutputAfterAnnotation: "-1h"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace := corev3.FixtureNamespace("default")
+			namespace.Metadata.Annotations = tt.annotations
+			got, err := RetentionPolicyFromNamespace(namespace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RetentionPolicyFromNamespace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RetentionPolicyFromNamespace() = %v, want %v", got, tt.want)
+			}
+			if got.IsZero() != (tt.want == RetentionPolicy{}) {
+				t.Errorf("IsZero() = %v", got.IsZero())
+			}
+		})
+	}
+}
@@ -106,6 +106,18 @@ func (e *ErrInternal) Error() string {
 	return fmt.Sprintf("internal error: %s", e.Message)
 }
 
+// ErrUnavailable is returned when the store can't be reached for now, like
+// while a circuit breaker stops the operations from reaching it. Unlike
+// ErrInternal, it doesn't signal that the store is not functional, and the
+// operation can be tried again later.
+type ErrUnavailable struct {
+	Message string
+}
+
+func (e *ErrUnavailable) Error() string {
+	return fmt.Sprintf("store unavailable: %s", e.Message)
+}
+
 // SelectionPredicate represents the way to select resources from storage
 type SelectionPredicate struct {
 	// Continue provides the key from which the selection should start. If
@@ -381,6 +393,14 @@ type EntityStore interface {
 	// with no error is returned if none were found.
 	GetEntities(ctx context.Context, pred *SelectionPredicate) ([]*corev2.Entity, error)
 
+	// CountEntities counts the entities in the given ctx's namespace that
+	// match the entity selector of ctx.
+	CountEntities(ctx context.Context) (int, error)
+
+	// EntityStoreSupportsFiltering signals whether an entity store
+	// implementation evaluates the entity selector of ctx.
+	EntityStoreSupportsFiltering(ctx context.Context) bool
+
 	// GetEntityByName returns an entity using the given name and the namespace stored
 	// in ctx. The resulting entity is nil if none was found.
 	GetEntityByName(ctx context.Context, name string) (*corev2.Entity, error)
@@ -389,6 +409,18 @@ type EntityStore interface {
 	UpdateEntity(ctx context.Context, entity *corev2.Entity) error
 }
 
+// BulkEntityStore is implemented by the entity stores that update entities in
+// bulk.
+type BulkEntityStore interface {
+	// UpdateEntities creates or updates entities, and deletes the entities
+	// named deletes, within the namespace stored in ctx, in a single
+	// transaction. The changes are returned by update, which is called with
+	// every entity of the namespace, regardless of the selector of ctx, as
+	// read in the transaction. The concurrent bulk updates of a namespace
+	// are serialized. Nothing is changed if update returns an error.
+	UpdateEntities(ctx context.Context, update func(existing []*corev2.Entity) (entities []*corev2.Entity, deletes []string, err error)) error
+}
+
 // EventStore provides methods for managing events
 type EventStore interface {
 	// DeleteEventByEntityCheck deletes an event using the given entity and check,
@@ -21,6 +21,10 @@ const (
 const (
 	// mutex for tessend telemetry
 	MutexTelemetry Mutex = iota ^ BitmaskMutexOSS
+	// mutex of the supervisor of the background jobs
+	MutexJobs
+	// mutex of the enforcement of the retention policies
+	MutexRetention
 )
 
 // MutexHandler should listen for context cancellation. If a mutex is lost,
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+const (
+	// AlertEveryAnnotation is the format of the pipeline annotation that sets
+	// the alert_every of a workflow of the pipeline. Events of an incident are
+	// only handled by the workflow on the first occurrence and on every
+	// alert_every occurrences after it.
+	AlertEveryAnnotation = "sensu.io/workflows.%s.alert_every"
+
+	// MaxNotificationsPerHourAnnotation is the format of the pipeline
+	// annotation that sets the max_notifications_per_hour of a workflow of the
+	// pipeline. It is the maximum number of events of an entity and check
+	// handled by the workflow per clock hour, across all backends.
+	MaxNotificationsPerHourAnnotation = "sensu.io/workflows.%s.max_notifications_per_hour"
+)
+
+// WorkflowThrottle is the occurrence-based throttling of a pipeline workflow.
+// A zero value does not throttle events.
+type WorkflowThrottle struct {
+	AlertEvery              int64
+	MaxNotificationsPerHour int64
+}
+
+// WorkflowThrottleFromPipeline returns the throttling of the workflow named
+// workflow, as set by the annotations of pipeline.
+func WorkflowThrottleFromPipeline(pipeline *corev2.Pipeline, workflow string) (WorkflowThrottle, error) {
+	var throttle WorkflowThrottle
+	annotations := pipeline.ObjectMeta.Annotations
+	var err error
+	if throttle.AlertEvery, err = parseThrottleAnnotation(annotations, fmt.Sprintf(AlertEveryAnnotation, workflow)); err != nil {
+		return throttle, err
+	}
+	if throttle.MaxNotificationsPerHour, err = parseThrottleAnnotation(annotations, fmt.Sprintf(MaxNotificationsPerHourAnnotation, workflow)); err != nil {
+		return throttle, err
+	}
+	return throttle, nil
+}
+
+func parseThrottleAnnotation(annotations map[string]string, key string) (int64, error) {
+	value, ok := annotations[key]
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("annotation %s must be a non-negative integer, got %q", key, value)
+	}
+	return n, nil
+}
+
+// AllowsOccurrence returns true if the workflow handles event according to
+// its alert_every. Resolutions and events without a check are always handled.
+func (t WorkflowThrottle) AllowsOccurrence(event *corev2.Event) bool {
+	if t.AlertEvery <= 1 || !event.HasCheck() || event.IsResolution() {
+		return true
+	}
+	occurrences := event.Check.Occurrences
+	return occurrences == 1 || occurrences%t.AlertEvery == 0
+}
+
+// NotificationKey identifies the notifications of an entity and check by a
+// pipeline workflow.
+type NotificationKey struct {
+	Namespace string
+	Pipeline  string
+	Workflow  string
+	Entity    string
+	Check     string
+}
+
+// NotificationCounterStore counts the notifications of pipeline workflows,
+// so that every backend enforces the same limits.
+type NotificationCounterStore interface {
+	// IncrementNotifications counts a notification in the hour of now, unless
+	// max notifications were already counted in that hour. It returns false
+	// if the notification was not counted. The counters are pruned when their
+	// event is deleted, or when their window has ended.
+	IncrementNotifications(ctx context.Context, key NotificationKey, now time.Time, max int64) (bool, error)
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+func TestWorkflowThrottleFromPipeline(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        WorkflowThrottle
+		wantErr     bool
+	}{
+		{
+			name: "no annotations",
+		},
+		{
+			name: "alert every and max notifications",
+			annotations: map[string]string{
+				fmt.Sprintf(AlertEveryAnnotation, "slack"):              "10",
+				fmt.Sprintf(MaxNotificationsPerHourAnnotation, "slack"): "5",
+			},
+			want: WorkflowThrottle{AlertEvery: 10, MaxNotificationsPerHour: 5},
+		},
+		{
+			name: "another workflow",
+			annotations: map[string]string{
+				fmt.Sprintf(AlertEveryAnnotation, "pagerduty"): "10",
+			},
+		},
+		{
+			name: "not a number",
+			annotations: map[string]string{
+				fmt.Sprintf(AlertEveryAnnotation, "slack"): "ten",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative",
+			annotations: map[string]string{
+				fmt.Sprintf(MaxNotificationsPerHourAnnotation, "slack"): "-1",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := corev2.FixturePipeline("pipeline", "default")
+			pipeline.Annotations = tt.annotations
+			got, err := WorkflowThrottleFromPipeline(pipeline, "slack")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WorkflowThrottleFromPipeline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("WorkflowThrottleFromPipeline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowThrottleAllowsOccurrence(t *testing.T) {
+	throttle := WorkflowThrottle{AlertEvery: 3}
+	for occurrences, want := range map[int64]bool{1: true, 2: false, 3: true, 4: false, 6: true} {
+		event := corev2.FixtureEvent("entity", "check")
+		event.Check.Status = 2
+		event.Check.Occurrences = occurrences
+		if got := throttle.AllowsOccurrence(event); got != want {
+			t.Errorf("occurrence %d: got %v, want %v", occurrences, got, want)
+		}
+	}
+
+	resolution := corev2.FixtureEvent("entity", "check")
+	resolution.Check.Status = 0
+	resolution.Check.Occurrences = 2
+	resolution.Check.History = []corev2.CheckHistory{{Status: 2}, {Status: 0}}
+	if !throttle.AllowsOccurrence(resolution) {
+		t.Error("expected resolutions to be handled")
+	}
+}
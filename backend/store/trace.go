@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+// EventTraceAnnotation is the annotation that enables the tracing of an event
+// through eventd and pipelined, when it is set to "true" on the event or on
+// its check.
+const EventTraceAnnotation = "sensu.io/trace"
+
+// EventTraceStep is a step of a traced event.
+type EventTraceStep struct {
+	// Time is the time at which the step completed.
+	Time time.Time `json:"time"`
+
+	// Component is the backend component that performed the step, such as
+	// eventd or pipelined.
+	Component string `json:"component"`
+
+	// Pipeline is the name of the pipeline, for pipeline steps.
+	Pipeline string `json:"pipeline,omitempty"`
+
+	// Workflow is the name of the pipeline workflow, for pipeline steps.
+	Workflow string `json:"workflow,omitempty"`
+
+	// Step is the kind of step, such as filter, mutator or handler.
+	Step string `json:"step"`
+
+	// Resource identifies the resource used by the step, such as a filter.
+	Resource string `json:"resource,omitempty"`
+
+	// Result is the result of the step.
+	Result string `json:"result"`
+
+	// Error is the error of the step, if it failed.
+	Error string `json:"error,omitempty"`
+
+	// Output is the output of the step, such as the mutated event.
+	Output string `json:"output,omitempty"`
+}
+
+// EventTrace is the trace of the most recent traced event of a check.
+type EventTrace struct {
+	Namespace string           `json:"namespace"`
+	Entity    string           `json:"entity"`
+	Check     string           `json:"check"`
+	EventID   string           `json:"event_id"`
+	Steps     []EventTraceStep `json:"steps"`
+}
+
+// EventTraceStore stores the traces of events.
+type EventTraceStore interface {
+	// AppendEventTrace appends the steps of trace to the stored trace of the
+	// same event. If the stored trace is the trace of another event, it is
+	// replaced.
+	AppendEventTrace(ctx context.Context, trace *EventTrace) error
+
+	// GetEventTrace gets the trace of the most recent traced event of the
+	// entity and check.
+	GetEventTrace(ctx context.Context, namespace, entity, check string) (*EventTrace, error)
+}
+
+// IsEventTraced returns true if the event or its check has the
+// EventTraceAnnotation set to "true". Events without a check are never traced.
+func IsEventTraced(event *corev2.Event) bool {
+	if event == nil || !event.HasCheck() {
+		return false
+	}
+	if event.ObjectMeta.Annotations[EventTraceAnnotation] == "true" {
+		return true
+	}
+	return event.Check.ObjectMeta.Annotations[EventTraceAnnotation] == "true"
+}
+
+// NewEventTrace returns an empty trace for event.
+func NewEventTrace(event *corev2.Event) *EventTrace {
+	return &EventTrace{
+		Namespace: event.Entity.Namespace,
+		Entity:    event.Entity.Name,
+		Check:     event.Check.Name,
+		EventID:   event.GetUUID().String(),
+	}
+}
@@ -0,0 +1,261 @@
+package v2
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/backend/store/patch"
+)
+
+const (
+	// ReadCacheRequestsCounterVec is the name of the counter of the reads
+	// served by a ReadCache.
+	ReadCacheRequestsCounterVec = "sensu_go_store_read_cache_requests"
+
+	// ReadCacheLabelStore is the store name label of the reads.
+	ReadCacheLabelStore = "store"
+
+	// ReadCacheLabelResult is the result label of the reads, hit or miss.
+	ReadCacheLabelResult = "result"
+
+	readCacheHit  = "hit"
+	readCacheMiss = "miss"
+)
+
+var (
+	// ReadCacheRequests counts the reads served by a ReadCache.
+	ReadCacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: ReadCacheRequestsCounterVec,
+			Help: "The total number of reads of the store read cache",
+		},
+		[]string{ReadCacheLabelStore, ReadCacheLabelResult},
+	)
+)
+
+func init() {
+	if err := prometheus.Register(ReadCacheRequests); err != nil {
+		panic(err)
+	}
+}
+
+// HotResources are the configuration resources read by schedulerd and
+// pipelined for every check execution and event.
+var HotResources = []corev2.Resource{
+	&corev2.CheckConfig{},
+	&corev2.Asset{},
+	&corev2.Handler{},
+	&corev2.EventFilter{},
+}
+
+type readCacheKey struct {
+	storeName string
+	namespace string
+	name      string
+	sortOrder SortOrder
+}
+
+// ReadCache is a read-through cache of a ConfigStore. It caches the results
+// of Get and List for the store names it watches, and invalidates them when
+// the store reports a change, or when they are written through the cache.
+// Reads of other store names, and reads that page, filter or use
+// preconditions, go to the store.
+type ReadCache struct {
+	ConfigStore
+
+	mu          sync.RWMutex
+	watched     map[string]bool
+	generations map[string]uint64
+	gets        map[readCacheKey]Wrapper
+	lists       map[readCacheKey]WrapList
+}
+
+// NewReadCache returns a ReadCache of store for resources. The cache watches
+// the resources until ctx is done, and stops caching the resources of a
+// watcher that stops.
+func NewReadCache(ctx context.Context, store ConfigStore, resources ...corev2.Resource) *ReadCache {
+	c := &ReadCache{
+		ConfigStore: store,
+		watched:     make(map[string]bool),
+		generations: make(map[string]uint64),
+		gets:        make(map[readCacheKey]Wrapper),
+		lists:       make(map[readCacheKey]WrapList),
+	}
+	for _, resource := range resources {
+		req := NewResourceRequestFromV2Resource(resource)
+		ch := store.Watch(ctx, req)
+		if ch == nil {
+			continue
+		}
+		c.watched[req.StoreName] = true
+		go c.watch(req.StoreName, ch)
+	}
+	return c
+}
+
+func (c *ReadCache) watch(storeName string, ch <-chan []WatchEvent) {
+	for range ch {
+		c.invalidate(storeName)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.watched, storeName)
+	c.dropLocked(storeName)
+}
+
+// invalidate drops the cached results of storeName.
+func (c *ReadCache) invalidate(storeName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dropLocked(storeName)
+}
+
+func (c *ReadCache) dropLocked(storeName string) {
+	c.generations[storeName]++
+	for key := range c.gets {
+		if key.storeName == storeName {
+			delete(c.gets, key)
+		}
+	}
+	for key := range c.lists {
+		if key.storeName == storeName {
+			delete(c.lists, key)
+		}
+	}
+}
+
+// lookup returns whether the reads of storeName are cached, and the current
+// generation of its results.
+func (c *ReadCache) lookup(storeName string) (bool, uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.watched[storeName], c.generations[storeName]
+}
+
+func (c *ReadCache) cacheable(ctx context.Context, req ResourceRequest) bool {
+	if IfMatchFromContext(ctx) != nil || IfNoneMatchFromContext(ctx) != nil {
+		return false
+	}
+	tm := corev2.TypeMeta{Type: req.Type, APIVersion: req.APIVersion}
+	return SelectorFromContext(ctx, tm) == nil
+}
+
+// Get gets a wrapped resource from the cache, or from the store.
+func (c *ReadCache) Get(ctx context.Context, req ResourceRequest) (Wrapper, error) {
+	watched, generation := c.lookup(req.StoreName)
+	if !watched || !c.cacheable(ctx, req) {
+		return c.ConfigStore.Get(ctx, req)
+	}
+	key := readCacheKey{storeName: req.StoreName, namespace: req.Namespace, name: req.Name}
+	c.mu.RLock()
+	wrapper, ok := c.gets[key]
+	c.mu.RUnlock()
+	if ok {
+		ReadCacheRequests.WithLabelValues(req.StoreName, readCacheHit).Inc()
+		return wrapper, nil
+	}
+	ReadCacheRequests.WithLabelValues(req.StoreName, readCacheMiss).Inc()
+	wrapper, err := c.ConfigStore.Get(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watched[req.StoreName] && c.generations[req.StoreName] == generation {
+		c.gets[key] = wrapper
+	}
+	return wrapper, nil
+}
+
+// List lists resources from the cache, or from the store.
+func (c *ReadCache) List(ctx context.Context, req ResourceRequest, pred *store.SelectionPredicate) (WrapList, error) {
+	watched, generation := c.lookup(req.StoreName)
+	if !watched || !c.cacheable(ctx, req) || !cacheablePredicate(pred) {
+		return c.ConfigStore.List(ctx, req, pred)
+	}
+	key := readCacheKey{storeName: req.StoreName, namespace: req.Namespace, sortOrder: req.SortOrder}
+	c.mu.RLock()
+	list, ok := c.lists[key]
+	c.mu.RUnlock()
+	if ok {
+		ReadCacheRequests.WithLabelValues(req.StoreName, readCacheHit).Inc()
+		return list, nil
+	}
+	ReadCacheRequests.WithLabelValues(req.StoreName, readCacheMiss).Inc()
+	list, err := c.ConfigStore.List(ctx, req, pred)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watched[req.StoreName] && c.generations[req.StoreName] == generation {
+		c.lists[key] = list
+	}
+	return list, nil
+}
+
+// cacheablePredicate returns true if pred selects every resource, in a
+// single page.
+func cacheablePredicate(pred *store.SelectionPredicate) bool {
+	if pred == nil {
+		return true
+	}
+	return pred.Continue == "" && pred.Limit == 0 && pred.Offset == 0 &&
+		pred.Subcollection == "" && pred.UpdatedSince == "" && !pred.IncludeDeletes
+}
+
+// CreateOrUpdate creates or updates the wrapped resource.
+func (c *ReadCache) CreateOrUpdate(ctx context.Context, req ResourceRequest, wrapper Wrapper) error {
+	defer c.invalidate(req.StoreName)
+	return c.ConfigStore.CreateOrUpdate(ctx, req, wrapper)
+}
+
+// UpdateIfExists updates the resource with the wrapped resource, but only
+// if it already exists in the store.
+func (c *ReadCache) UpdateIfExists(ctx context.Context, req ResourceRequest, wrapper Wrapper) error {
+	defer c.invalidate(req.StoreName)
+	return c.ConfigStore.UpdateIfExists(ctx, req, wrapper)
+}
+
+// CreateIfNotExists writes the wrapped resource to the store, but only if
+// it does not already exist.
+func (c *ReadCache) CreateIfNotExists(ctx context.Context, req ResourceRequest, wrapper Wrapper) error {
+	defer c.invalidate(req.StoreName)
+	return c.ConfigStore.CreateIfNotExists(ctx, req, wrapper)
+}
+
+// Delete deletes a resource from the store.
+func (c *ReadCache) Delete(ctx context.Context, req ResourceRequest) error {
+	defer c.invalidate(req.StoreName)
+	return c.ConfigStore.Delete(ctx, req)
+}
+
+// Patch patches the resource given in the request.
+func (c *ReadCache) Patch(ctx context.Context, req ResourceRequest, patcher patch.Patcher) error {
+	defer c.invalidate(req.StoreName)
+	return c.ConfigStore.Patch(ctx, req, patcher)
+}
+
+// CachedStore is a store whose configuration store is a ReadCache.
+type CachedStore struct {
+	Interface
+
+	cache *ReadCache
+}
+
+// NewCachedStore returns a store that reads resources from a ReadCache of the
+// configuration store of s, until ctx is done.
+func NewCachedStore(ctx context.Context, s Interface, resources ...corev2.Resource) *CachedStore {
+	return &CachedStore{
+		Interface: s,
+		cache:     NewReadCache(ctx, s.GetConfigStore(), resources...),
+	}
+}
+
+// GetConfigStore returns the ReadCache of the configuration store.
+func (s *CachedStore) GetConfigStore() ConfigStore {
+	return s.cache
+}
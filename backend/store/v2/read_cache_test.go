@@ -0,0 +1,120 @@
+package v2_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/backend/selector"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/backend/store/v2/wrap"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newReadCache(t *testing.T) (*storev2.ReadCache, *mockstore.ConfigStore, chan []storev2.WatchEvent) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cs := new(mockstore.ConfigStore)
+	watch := make(chan []storev2.WatchEvent)
+	cs.On("Watch", mock.Anything, mock.Anything).Return((<-chan []storev2.WatchEvent)(watch))
+	return storev2.NewReadCache(ctx, cs, &corev2.CheckConfig{}), cs, watch
+}
+
+func checkRequest(name string) storev2.ResourceRequest {
+	return storev2.NewResourceRequestFromV2Resource(corev2.FixtureCheckConfig(name))
+}
+
+func TestReadCacheGet(t *testing.T) {
+	cache, cs, watch := newReadCache(t)
+	ctx := context.Background()
+	req := checkRequest("check1")
+	wrapper, err := wrap.V2Resource(corev2.FixtureCheckConfig("check1"))
+	require.NoError(t, err)
+	cs.On("Get", mock.Anything, req).Return(wrapper, nil)
+
+	for i := 0; i < 3; i++ {
+		got, err := cache.Get(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, wrapper, got)
+	}
+	cs.AssertNumberOfCalls(t, "Get", 1)
+
+	// A change reported by the store invalidates the cache
+	watch <- []storev2.WatchEvent{{Type: storev2.WatchUpdate, Key: req}}
+	assert.Eventually(t, func() bool {
+		_, err := cache.Get(ctx, req)
+		require.NoError(t, err)
+		return getCalls(cs) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Writes through the cache invalidate it
+	cs.On("Delete", mock.Anything, req).Return(nil)
+	require.NoError(t, cache.Delete(ctx, req))
+	_, err = cache.Get(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 3, getCalls(cs))
+}
+
+func getCalls(cs *mockstore.ConfigStore) int {
+	var n int
+	for _, call := range cs.Calls {
+		if call.Method == "Get" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestReadCacheGetNotFound(t *testing.T) {
+	cache, cs, _ := newReadCache(t)
+	req := checkRequest("check1")
+	cs.On("Get", mock.Anything, req).Return(nil, &store.ErrNotFound{Key: "check1"})
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.Get(context.Background(), req)
+		assert.Error(t, err)
+	}
+	cs.AssertNumberOfCalls(t, "Get", 2)
+}
+
+func TestReadCacheList(t *testing.T) {
+	cache, cs, _ := newReadCache(t)
+	req := checkRequest("")
+	list := wrap.List{}
+	cs.On("List", mock.Anything, req, mock.Anything).Return(list, nil)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err := cache.List(ctx, req, &store.SelectionPredicate{})
+		require.NoError(t, err)
+	}
+	cs.AssertNumberOfCalls(t, "List", 1)
+
+	// Pages and selections are not cached
+	_, err := cache.List(ctx, req, &store.SelectionPredicate{Limit: 10})
+	require.NoError(t, err)
+	sel, err := selector.ParseLabelSelector("region == \"us-west-1\"")
+	require.NoError(t, err)
+	selCtx := storev2.ContextWithSelector(ctx, corev2.TypeMeta{Type: req.Type, APIVersion: req.APIVersion}, sel)
+	_, err = cache.List(selCtx, req, nil)
+	require.NoError(t, err)
+	cs.AssertNumberOfCalls(t, "List", 3)
+}
+
+func TestReadCacheUncachedResource(t *testing.T) {
+	cache, cs, _ := newReadCache(t)
+	req := storev2.NewResourceRequestFromV2Resource(corev2.FixtureMutator("mutator1"))
+	cs.On("Get", mock.Anything, req).Return(nil, nil)
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.Get(context.Background(), req)
+		require.NoError(t, err)
+	}
+	cs.AssertNumberOfCalls(t, "Get", 2)
+}
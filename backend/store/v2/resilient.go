@@ -0,0 +1,578 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/backend/store/patch"
+)
+
+const (
+	// StoreRetriesCounterVec is the name of the counter of the store
+	// operations retried by a ResilientStore.
+	StoreRetriesCounterVec = "sensu_go_store_retries_total"
+
+	// StoreBreakerRejectionsCounterVec is the name of the counter of the
+	// store operations rejected by the circuit breaker of a ResilientStore.
+	StoreBreakerRejectionsCounterVec = "sensu_go_store_circuit_breaker_rejections_total"
+
+	// StoreBreakerOpenGauge is the name of the gauge that is 1 while the
+	// circuit breaker of a ResilientStore is open, and 0 otherwise.
+	StoreBreakerOpenGauge = "sensu_go_store_circuit_breaker_open"
+
+	// ResilientLabelStore is the store label of the retries and rejections.
+	ResilientLabelStore = "store"
+
+	// DefaultStoreMaxRetries is the default number of times a store
+	// operation is retried after a transient error.
+	DefaultStoreMaxRetries = 3
+
+	// DefaultStoreRetryDelay and DefaultStoreMaxRetryDelay are the default
+	// initial and maximum delays between the retries of a store operation.
+	DefaultStoreRetryDelay    = 100 * time.Millisecond
+	DefaultStoreMaxRetryDelay = 2 * time.Second
+
+	// DefaultStoreBreakerThreshold is the default number of consecutive
+	// transient errors that open the circuit breaker.
+	DefaultStoreBreakerThreshold = 20
+
+	// DefaultStoreBreakerCooldown is the default duration the circuit
+	// breaker stays open before it lets an operation probe the store.
+	DefaultStoreBreakerCooldown = 10 * time.Second
+
+	resilientStoreConfig       = "config"
+	resilientStoreEntityConfig = "entity_config"
+	resilientStoreEntityState  = "entity_state"
+	resilientStoreNamespace    = "namespace"
+	resilientStoreEvent        = "event"
+)
+
+var (
+	// StoreRetries counts the store operations retried by a ResilientStore.
+	StoreRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: StoreRetriesCounterVec,
+			Help: "The total number of store operations retried after a transient error",
+		},
+		[]string{ResilientLabelStore},
+	)
+
+	// StoreBreakerRejections counts the store operations rejected by the
+	// circuit breaker of a ResilientStore.
+	StoreBreakerRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: StoreBreakerRejectionsCounterVec,
+			Help: "The total number of store operations rejected by the open circuit breaker",
+		},
+		[]string{ResilientLabelStore},
+	)
+
+	// StoreBreakerOpen is 1 while the circuit breaker of a ResilientStore is
+	// open, and 0 otherwise.
+	StoreBreakerOpen = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: StoreBreakerOpenGauge,
+			Help: "Whether the store circuit breaker is open",
+		},
+	)
+)
+
+func init() {
+	if err := prometheus.Register(StoreRetries); err != nil {
+		panic(err)
+	}
+	if err := prometheus.Register(StoreBreakerRejections); err != nil {
+		panic(err)
+	}
+	if err := prometheus.Register(StoreBreakerOpen); err != nil {
+		panic(err)
+	}
+}
+
+// ErrCircuitOpen is returned by a ResilientStore while its circuit breaker
+// is open. It is not an internal error, which the daemons take as a store that
+// is not functional, but a transient one: the operations can be tried again
+// once the circuit breaker closes.
+var ErrCircuitOpen = &store.ErrUnavailable{Message: "the store circuit breaker is open"}
+
+// ResilienceConfig configures the retries and the circuit breaker of a
+// ResilientStore.
+type ResilienceConfig struct {
+	// MaxRetries is the number of times an operation is retried after a
+	// transient error. The operations are not retried if it is zero.
+	MaxRetries int
+
+	// RetryDelay is the delay before the first retry, doubled for every
+	// following retry up to MaxRetryDelay. A random jitter of up to the
+	// delay is added.
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+
+	// BreakerThreshold is the number of consecutive transient errors that
+	// open the circuit breaker. The operations fail with ErrCircuitOpen
+	// while it is open, without reaching the store. The circuit breaker is
+	// disabled if it is zero.
+	BreakerThreshold int
+
+	// BreakerCooldown is how long the circuit breaker stays open before it
+	// lets a single operation probe the store. The circuit breaker closes if
+	// the operation succeeds, and opens again otherwise.
+	BreakerCooldown time.Duration
+}
+
+// IsTransient returns true if err is a transient store error, which is worth
+// retrying: an internal error, an unavailable store, or a deadline exceeded
+// while ctx is not done.
+func IsTransient(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	var (
+		internal    *store.ErrInternal
+		unavailable *store.ErrUnavailable
+	)
+	if errors.As(err, &internal) || errors.As(err, &unavailable) {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil
+}
+
+// circuitBreaker counts the consecutive transient errors of a store, and
+// rejects the operations once they reach its threshold, until its cooldown
+// elapses.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow returns true if an operation can reach the store.
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	// Half-open: a single operation probes the store
+	b.probing = true
+	return true
+}
+
+// done records the outcome of an operation allowed by the circuit breaker.
+func (b *circuitBreaker) done(transient bool) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !transient {
+		b.failures = 0
+		StoreBreakerOpen.Set(0)
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		StoreBreakerOpen.Set(1)
+	}
+}
+
+// ResilientStore is a store that retries the operations that fail with a
+// transient error, with an exponential backoff, and that stops reaching the
+// store with a circuit breaker while the errors persist. It covers the
+// configuration, entity config, entity state, namespace and event stores;
+// the other stores are used as is. The watches are not retried, nor are the
+// operations that are not idempotent: the patches, the creations of the
+// resources that must not exist, and the updates of the events, whose
+// occurrences and history would be updated twice if an operation that timed
+// out after it was committed was retried.
+type ResilientStore struct {
+	Interface
+
+	cfg     ResilienceConfig
+	breaker *circuitBreaker
+}
+
+// NewResilientStore returns a ResilientStore of s.
+func NewResilientStore(s Interface, cfg ResilienceConfig) *ResilientStore {
+	if cfg.MaxRetryDelay < cfg.RetryDelay {
+		cfg.MaxRetryDelay = cfg.RetryDelay
+	}
+	return &ResilientStore{
+		Interface: s,
+		cfg:       cfg,
+		breaker:   &circuitBreaker{threshold: cfg.BreakerThreshold, cooldown: cfg.BreakerCooldown},
+	}
+}
+
+// do runs op, and retries it if it fails with a transient error, until it
+// succeeds, the retries are exhausted, ctx is done, or the circuit breaker
+// opens. storeName labels the metrics of the retries.
+func (s *ResilientStore) do(ctx context.Context, storeName string, retry bool, op func() error) error {
+	delay := s.cfg.RetryDelay
+	for attempt := 0; ; attempt++ {
+		if !s.breaker.allow() {
+			StoreBreakerRejections.WithLabelValues(storeName).Inc()
+			return ErrCircuitOpen
+		}
+		err := op()
+		transient := IsTransient(ctx, err)
+		s.breaker.done(transient)
+		if !transient || !retry || attempt >= s.cfg.MaxRetries {
+			return err
+		}
+		StoreRetries.WithLabelValues(storeName).Inc()
+		wait := delay
+		if delay > 0 {
+			wait += time.Duration(rand.Int63n(int64(delay)))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if delay *= 2; delay > s.cfg.MaxRetryDelay {
+			delay = s.cfg.MaxRetryDelay
+		}
+	}
+}
+
+// doValue is do for the operations that return a value.
+func doValue[T any](ctx context.Context, s *ResilientStore, storeName string, op func() (T, error)) (T, error) {
+	var value T
+	err := s.do(ctx, storeName, true, func() (err error) {
+		value, err = op()
+		return err
+	})
+	return value, err
+}
+
+// GetConfigStore returns the resilient configuration store.
+func (s *ResilientStore) GetConfigStore() ConfigStore {
+	return resilientConfigStore{ConfigStore: s.Interface.GetConfigStore(), s: s}
+}
+
+// GetEntityConfigStore returns the resilient entity config store.
+func (s *ResilientStore) GetEntityConfigStore() EntityConfigStore {
+	return resilientEntityConfigStore{EntityConfigStore: s.Interface.GetEntityConfigStore(), s: s}
+}
+
+// GetEntityStateStore returns the resilient entity state store.
+func (s *ResilientStore) GetEntityStateStore() EntityStateStore {
+	return resilientEntityStateStore{EntityStateStore: s.Interface.GetEntityStateStore(), s: s}
+}
+
+// GetNamespaceStore returns the resilient namespace store.
+func (s *ResilientStore) GetNamespaceStore() NamespaceStore {
+	return resilientNamespaceStore{NamespaceStore: s.Interface.GetNamespaceStore(), s: s}
+}
+
+// GetEventStore returns the resilient event store.
+func (s *ResilientStore) GetEventStore() store.EventStore {
+	return resilientEventStore{EventStore: s.Interface.GetEventStore(), s: s}
+}
+
+type resilientConfigStore struct {
+	ConfigStore
+	s *ResilientStore
+}
+
+func (c resilientConfigStore) CreateOrUpdate(ctx context.Context, req ResourceRequest, wrapper Wrapper) error {
+	return c.s.do(ctx, resilientStoreConfig, true, func() error {
+		return c.ConfigStore.CreateOrUpdate(ctx, req, wrapper)
+	})
+}
+
+func (c resilientConfigStore) UpdateIfExists(ctx context.Context, req ResourceRequest, wrapper Wrapper) error {
+	return c.s.do(ctx, resilientStoreConfig, true, func() error {
+		return c.ConfigStore.UpdateIfExists(ctx, req, wrapper)
+	})
+}
+
+func (c resilientConfigStore) CreateIfNotExists(ctx context.Context, req ResourceRequest, wrapper Wrapper) error {
+	return c.s.do(ctx, resilientStoreConfig, false, func() error {
+		return c.ConfigStore.CreateIfNotExists(ctx, req, wrapper)
+	})
+}
+
+func (c resilientConfigStore) Get(ctx context.Context, req ResourceRequest) (Wrapper, error) {
+	return doValue(ctx, c.s, resilientStoreConfig, func() (Wrapper, error) {
+		return c.ConfigStore.Get(ctx, req)
+	})
+}
+
+func (c resilientConfigStore) Delete(ctx context.Context, req ResourceRequest) error {
+	return c.s.do(ctx, resilientStoreConfig, true, func() error {
+		return c.ConfigStore.Delete(ctx, req)
+	})
+}
+
+func (c resilientConfigStore) List(ctx context.Context, req ResourceRequest, pred *store.SelectionPredicate) (WrapList, error) {
+	return doValue(ctx, c.s, resilientStoreConfig, func() (WrapList, error) {
+		return c.ConfigStore.List(ctx, req, pred)
+	})
+}
+
+func (c resilientConfigStore) Count(ctx context.Context, req ResourceRequest) (int, error) {
+	return doValue(ctx, c.s, resilientStoreConfig, func() (int, error) {
+		return c.ConfigStore.Count(ctx, req)
+	})
+}
+
+func (c resilientConfigStore) Exists(ctx context.Context, req ResourceRequest) (bool, error) {
+	return doValue(ctx, c.s, resilientStoreConfig, func() (bool, error) {
+		return c.ConfigStore.Exists(ctx, req)
+	})
+}
+
+func (c resilientConfigStore) Patch(ctx context.Context, req ResourceRequest, patcher patch.Patcher) error {
+	return c.s.do(ctx, resilientStoreConfig, false, func() error {
+		return c.ConfigStore.Patch(ctx, req, patcher)
+	})
+}
+
+type resilientEntityConfigStore struct {
+	EntityConfigStore
+	s *ResilientStore
+}
+
+func (c resilientEntityConfigStore) CreateOrUpdate(ctx context.Context, config *corev3.EntityConfig) error {
+	return c.s.do(ctx, resilientStoreEntityConfig, true, func() error {
+		return c.EntityConfigStore.CreateOrUpdate(ctx, config)
+	})
+}
+
+func (c resilientEntityConfigStore) UpdateIfExists(ctx context.Context, config *corev3.EntityConfig) error {
+	return c.s.do(ctx, resilientStoreEntityConfig, true, func() error {
+		return c.EntityConfigStore.UpdateIfExists(ctx, config)
+	})
+}
+
+func (c resilientEntityConfigStore) CreateIfNotExists(ctx context.Context, config *corev3.EntityConfig) error {
+	return c.s.do(ctx, resilientStoreEntityConfig, false, func() error {
+		return c.EntityConfigStore.CreateIfNotExists(ctx, config)
+	})
+}
+
+func (c resilientEntityConfigStore) Get(ctx context.Context, namespace, name string) (*corev3.EntityConfig, error) {
+	return doValue(ctx, c.s, resilientStoreEntityConfig, func() (*corev3.EntityConfig, error) {
+		return c.EntityConfigStore.Get(ctx, namespace, name)
+	})
+}
+
+func (c resilientEntityConfigStore) Delete(ctx context.Context, namespace, name string) error {
+	return c.s.do(ctx, resilientStoreEntityConfig, true, func() error {
+		return c.EntityConfigStore.Delete(ctx, namespace, name)
+	})
+}
+
+func (c resilientEntityConfigStore) List(ctx context.Context, namespace string, pred *store.SelectionPredicate) ([]*corev3.EntityConfig, error) {
+	return doValue(ctx, c.s, resilientStoreEntityConfig, func() ([]*corev3.EntityConfig, error) {
+		return c.EntityConfigStore.List(ctx, namespace, pred)
+	})
+}
+
+func (c resilientEntityConfigStore) Count(ctx context.Context, namespace, entityClass string) (int, error) {
+	return doValue(ctx, c.s, resilientStoreEntityConfig, func() (int, error) {
+		return c.EntityConfigStore.Count(ctx, namespace, entityClass)
+	})
+}
+
+func (c resilientEntityConfigStore) Exists(ctx context.Context, namespace, name string) (bool, error) {
+	return doValue(ctx, c.s, resilientStoreEntityConfig, func() (bool, error) {
+		return c.EntityConfigStore.Exists(ctx, namespace, name)
+	})
+}
+
+func (c resilientEntityConfigStore) Patch(ctx context.Context, namespace, name string, patcher patch.Patcher) error {
+	return c.s.do(ctx, resilientStoreEntityConfig, false, func() error {
+		return c.EntityConfigStore.Patch(ctx, namespace, name, patcher)
+	})
+}
+
+type resilientEntityStateStore struct {
+	EntityStateStore
+	s *ResilientStore
+}
+
+func (c resilientEntityStateStore) CreateOrUpdate(ctx context.Context, state *corev3.EntityState) error {
+	return c.s.do(ctx, resilientStoreEntityState, true, func() error {
+		return c.EntityStateStore.CreateOrUpdate(ctx, state)
+	})
+}
+
+func (c resilientEntityStateStore) UpdateIfExists(ctx context.Context, state *corev3.EntityState) error {
+	return c.s.do(ctx, resilientStoreEntityState, true, func() error {
+		return c.EntityStateStore.UpdateIfExists(ctx, state)
+	})
+}
+
+func (c resilientEntityStateStore) CreateIfNotExists(ctx context.Context, state *corev3.EntityState) error {
+	return c.s.do(ctx, resilientStoreEntityState, false, func() error {
+		return c.EntityStateStore.CreateIfNotExists(ctx, state)
+	})
+}
+
+func (c resilientEntityStateStore) Get(ctx context.Context, namespace, name string) (*corev3.EntityState, error) {
+	return doValue(ctx, c.s, resilientStoreEntityState, func() (*corev3.EntityState, error) {
+		return c.EntityStateStore.Get(ctx, namespace, name)
+	})
+}
+
+func (c resilientEntityStateStore) Delete(ctx context.Context, namespace, name string) error {
+	return c.s.do(ctx, resilientStoreEntityState, true, func() error {
+		return c.EntityStateStore.Delete(ctx, namespace, name)
+	})
+}
+
+func (c resilientEntityStateStore) List(ctx context.Context, namespace string, pred *store.SelectionPredicate) ([]*corev3.EntityState, error) {
+	return doValue(ctx, c.s, resilientStoreEntityState, func() ([]*corev3.EntityState, error) {
+		return c.EntityStateStore.List(ctx, namespace, pred)
+	})
+}
+
+func (c resilientEntityStateStore) Count(ctx context.Context, namespace string) (int, error) {
+	return doValue(ctx, c.s, resilientStoreEntityState, func() (int, error) {
+		return c.EntityStateStore.Count(ctx, namespace)
+	})
+}
+
+func (c resilientEntityStateStore) Exists(ctx context.Context, namespace, name string) (bool, error) {
+	return doValue(ctx, c.s, resilientStoreEntityState, func() (bool, error) {
+		return c.EntityStateStore.Exists(ctx, namespace, name)
+	})
+}
+
+func (c resilientEntityStateStore) Patch(ctx context.Context, namespace, name string, patcher patch.Patcher) error {
+	return c.s.do(ctx, resilientStoreEntityState, false, func() error {
+		return c.EntityStateStore.Patch(ctx, namespace, name, patcher)
+	})
+}
+
+type resilientNamespaceStore struct {
+	NamespaceStore
+	s *ResilientStore
+}
+
+func (c resilientNamespaceStore) CreateOrUpdate(ctx context.Context, namespace *corev3.Namespace) error {
+	return c.s.do(ctx, resilientStoreNamespace, true, func() error {
+		return c.NamespaceStore.CreateOrUpdate(ctx, namespace)
+	})
+}
+
+func (c resilientNamespaceStore) UpdateIfExists(ctx context.Context, namespace *corev3.Namespace) error {
+	return c.s.do(ctx, resilientStoreNamespace, true, func() error {
+		return c.NamespaceStore.UpdateIfExists(ctx, namespace)
+	})
+}
+
+func (c resilientNamespaceStore) CreateIfNotExists(ctx context.Context, namespace *corev3.Namespace) error {
+	return c.s.do(ctx, resilientStoreNamespace, false, func() error {
+		return c.NamespaceStore.CreateIfNotExists(ctx, namespace)
+	})
+}
+
+func (c resilientNamespaceStore) Get(ctx context.Context, name string) (*corev3.Namespace, error) {
+	return doValue(ctx, c.s, resilientStoreNamespace, func() (*corev3.Namespace, error) {
+		return c.NamespaceStore.Get(ctx, name)
+	})
+}
+
+func (c resilientNamespaceStore) Delete(ctx context.Context, name string) error {
+	return c.s.do(ctx, resilientStoreNamespace, true, func() error {
+		return c.NamespaceStore.Delete(ctx, name)
+	})
+}
+
+func (c resilientNamespaceStore) List(ctx context.Context, pred *store.SelectionPredicate) ([]*corev3.Namespace, error) {
+	return doValue(ctx, c.s, resilientStoreNamespace, func() ([]*corev3.Namespace, error) {
+		return c.NamespaceStore.List(ctx, pred)
+	})
+}
+
+func (c resilientNamespaceStore) Count(ctx context.Context) (int, error) {
+	return doValue(ctx, c.s, resilientStoreNamespace, func() (int, error) {
+		return c.NamespaceStore.Count(ctx)
+	})
+}
+
+func (c resilientNamespaceStore) Exists(ctx context.Context, name string) (bool, error) {
+	return doValue(ctx, c.s, resilientStoreNamespace, func() (bool, error) {
+		return c.NamespaceStore.Exists(ctx, name)
+	})
+}
+
+func (c resilientNamespaceStore) Patch(ctx context.Context, name string, patcher patch.Patcher) error {
+	return c.s.do(ctx, resilientStoreNamespace, false, func() error {
+		return c.NamespaceStore.Patch(ctx, name, patcher)
+	})
+}
+
+func (c resilientNamespaceStore) IsEmpty(ctx context.Context, name string) (bool, error) {
+	return doValue(ctx, c.s, resilientStoreNamespace, func() (bool, error) {
+		return c.NamespaceStore.IsEmpty(ctx, name)
+	})
+}
+
+type resilientEventStore struct {
+	store.EventStore
+	s *ResilientStore
+}
+
+func (c resilientEventStore) DeleteEventByEntityCheck(ctx context.Context, entity, check string) error {
+	return c.s.do(ctx, resilientStoreEvent, true, func() error {
+		return c.EventStore.DeleteEventByEntityCheck(ctx, entity, check)
+	})
+}
+
+func (c resilientEventStore) GetEvents(ctx context.Context, pred *store.SelectionPredicate) ([]*corev2.Event, error) {
+	return doValue(ctx, c.s, resilientStoreEvent, func() ([]*corev2.Event, error) {
+		return c.EventStore.GetEvents(ctx, pred)
+	})
+}
+
+func (c resilientEventStore) GetEventsByEntity(ctx context.Context, entity string, pred *store.SelectionPredicate) ([]*corev2.Event, error) {
+	return doValue(ctx, c.s, resilientStoreEvent, func() ([]*corev2.Event, error) {
+		return c.EventStore.GetEventsByEntity(ctx, entity, pred)
+	})
+}
+
+func (c resilientEventStore) GetEventByEntityCheck(ctx context.Context, entity, check string) (*corev2.Event, error) {
+	return doValue(ctx, c.s, resilientStoreEvent, func() (*corev2.Event, error) {
+		return c.EventStore.GetEventByEntityCheck(ctx, entity, check)
+	})
+}
+
+func (c resilientEventStore) UpdateEvent(ctx context.Context, event *corev2.Event) (old, new *corev2.Event, err error) {
+	err = c.s.do(ctx, resilientStoreEvent, false, func() (err error) {
+		old, new, err = c.EventStore.UpdateEvent(ctx, event)
+		return err
+	})
+	return old, new, err
+}
+
+func (c resilientEventStore) CountEvents(ctx context.Context, pred *store.SelectionPredicate) (int64, error) {
+	return doValue(ctx, c.s, resilientStoreEvent, func() (int64, error) {
+		return c.EventStore.CountEvents(ctx, pred)
+	})
+}
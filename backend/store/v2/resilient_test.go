@@ -0,0 +1,128 @@
+package v2_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/store"
+	storev2 "github.com/sensu/sensu-go/backend/store/v2"
+	"github.com/sensu/sensu-go/testing/mockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newResilientStore(t *testing.T, cfg storev2.ResilienceConfig) (*storev2.ResilientStore, *mockstore.EntityConfigStore) {
+	t.Helper()
+	ecs := new(mockstore.EntityConfigStore)
+	s := new(mockstore.V2MockStore)
+	s.On("GetEntityConfigStore").Return(ecs)
+	return storev2.NewResilientStore(s, cfg), ecs
+}
+
+func TestResilientStoreRetries(t *testing.T) {
+	rs, ecs := newResilientStore(t, storev2.ResilienceConfig{MaxRetries: 3, RetryDelay: time.Millisecond})
+	ctx := context.Background()
+	config := corev3.FixtureEntityConfig("agent1")
+	internal := &store.ErrInternal{Message: "connection reset"}
+
+	// Transient errors are retried
+	ecs.On("Get", mock.Anything, "default", "agent1").Return((*corev3.EntityConfig)(nil), internal).Twice()
+	ecs.On("Get", mock.Anything, "default", "agent1").Return(config, nil).Once()
+	got, err := rs.GetEntityConfigStore().Get(ctx, "default", "agent1")
+	require.NoError(t, err)
+	assert.Equal(t, config, got)
+	ecs.AssertNumberOfCalls(t, "Get", 3)
+
+	// Other errors are not
+	ecs.On("Get", mock.Anything, "default", "agent2").Return((*corev3.EntityConfig)(nil), &store.ErrNotFound{Key: "agent2"}).Once()
+	_, err = rs.GetEntityConfigStore().Get(ctx, "default", "agent2")
+	var notFound *store.ErrNotFound
+	assert.True(t, errors.As(err, &notFound))
+	ecs.AssertNumberOfCalls(t, "Get", 4)
+
+	// The last error is returned once the retries are exhausted
+	ecs.On("CreateOrUpdate", mock.Anything, config).Return(internal)
+	err = rs.GetEntityConfigStore().CreateOrUpdate(ctx, config)
+	assert.Equal(t, internal, err)
+	ecs.AssertNumberOfCalls(t, "CreateOrUpdate", 4)
+
+	// Patches are not retried
+	ecs.On("Patch", mock.Anything, "default", "agent1", mock.Anything).Return(internal)
+	err = rs.GetEntityConfigStore().Patch(ctx, "default", "agent1", nil)
+	assert.Equal(t, internal, err)
+	ecs.AssertNumberOfCalls(t, "Patch", 1)
+
+	// Nor are the creations of the resources that must not exist
+	ecs.On("CreateIfNotExists", mock.Anything, config).Return(internal)
+	err = rs.GetEntityConfigStore().CreateIfNotExists(ctx, config)
+	assert.Equal(t, internal, err)
+	ecs.AssertNumberOfCalls(t, "CreateIfNotExists", 1)
+}
+
+func TestResilientStoreUpdateEventNotRetried(t *testing.T) {
+	es := new(mockstore.MockStore)
+	s := new(mockstore.V2MockStore)
+	s.On("GetEventStore").Return(es)
+	rs := storev2.NewResilientStore(s, storev2.ResilienceConfig{MaxRetries: 3, RetryDelay: time.Millisecond})
+	event := corev2.FixtureEvent("agent1", "check1")
+	internal := &store.ErrInternal{Message: "connection reset"}
+
+	// The update may have been committed, and its retry would count the
+	// occurrence of the event twice
+	es.On("UpdateEvent", event).Return((*corev2.Event)(nil), (*corev2.Event)(nil), internal)
+	_, _, err := rs.GetEventStore().UpdateEvent(context.Background(), event)
+	assert.Equal(t, internal, err)
+	es.AssertNumberOfCalls(t, "UpdateEvent", 1)
+}
+
+func TestResilientStoreCircuitBreaker(t *testing.T) {
+	rs, ecs := newResilientStore(t, storev2.ResilienceConfig{BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond})
+	ctx := context.Background()
+	config := corev3.FixtureEntityConfig("agent1")
+	internal := &store.ErrInternal{Message: "connection refused"}
+
+	ecs.On("UpdateIfExists", mock.Anything, config).Return(internal).Times(3)
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, internal, rs.GetEntityConfigStore().UpdateIfExists(ctx, config))
+	}
+
+	// The circuit breaker is open, which is not an internal error
+	err := rs.GetEntityConfigStore().UpdateIfExists(ctx, config)
+	assert.Equal(t, storev2.ErrCircuitOpen, err)
+	_, fatal := err.(*store.ErrInternal)
+	assert.False(t, fatal)
+	assert.True(t, storev2.IsTransient(ctx, err))
+	ecs.AssertNumberOfCalls(t, "UpdateIfExists", 2)
+
+	// The probe after the cooldown fails, and the circuit breaker opens again
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, internal, rs.GetEntityConfigStore().UpdateIfExists(ctx, config))
+	assert.Equal(t, storev2.ErrCircuitOpen, rs.GetEntityConfigStore().UpdateIfExists(ctx, config))
+	ecs.AssertNumberOfCalls(t, "UpdateIfExists", 3)
+
+	// The probe after the cooldown succeeds, and the circuit breaker closes
+	ecs.On("UpdateIfExists", mock.Anything, config).Return(nil)
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, rs.GetEntityConfigStore().UpdateIfExists(ctx, config))
+	require.NoError(t, rs.GetEntityConfigStore().UpdateIfExists(ctx, config))
+	ecs.AssertNumberOfCalls(t, "UpdateIfExists", 5)
+}
+
+func TestIsTransient(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, storev2.IsTransient(ctx, nil))
+	assert.False(t, storev2.IsTransient(ctx, &store.ErrNotFound{Key: "agent1"}))
+	assert.True(t, storev2.IsTransient(ctx, &store.ErrInternal{Message: "connection reset"}))
+	assert.True(t, storev2.IsTransient(ctx, &store.ErrUnavailable{Message: "circuit breaker open"}))
+	assert.True(t, storev2.IsTransient(ctx, context.DeadlineExceeded))
+
+	// The deadline of the operation itself is exceeded
+	ctx, cancel := context.WithTimeout(ctx, 0)
+	defer cancel()
+	assert.False(t, storev2.IsTransient(ctx, context.DeadlineExceeded))
+}
@@ -19,6 +19,18 @@ func EventSelectorFromContext(ctx context.Context) *selector.Selector {
 	return SelectorFromContext(ctx, corev2.TypeMeta{APIVersion: "core/v2", Type: "Event"})
 }
 
+// EntityContextWithSelector returns a new context, with the selector of the
+// entities stored as a value.
+func EntityContextWithSelector(ctx context.Context, selector *selector.Selector) context.Context {
+	return ContextWithSelector(ctx, corev2.TypeMeta{APIVersion: "core/v2", Type: "Entity"}, selector)
+}
+
+// EntitySelectorFromContext extracts the selector of the entities stored as a
+// context value, if it exists.
+func EntitySelectorFromContext(ctx context.Context) *selector.Selector {
+	return SelectorFromContext(ctx, corev2.TypeMeta{APIVersion: "core/v2", Type: "Entity"})
+}
+
 type selectorCtxKey struct {
 	Type       string
 	APIVersion string
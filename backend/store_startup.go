@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sensu/sensu-go/backend/store/postgres"
+)
+
+// storeStartup is the daemon that migrates the database to the latest schema
+// version, then runs the initialization steps that need the store. It starts
+// right after apid and is retried while the database is unreachable, so that
+// the API answers in degraded mode instead of the backend blocking before it
+// starts.
+type storeStartup struct {
+	ctx     context.Context
+	dsn     string
+	steps   []func(context.Context) error
+	errChan chan error
+}
+
+func newStoreStartup(ctx context.Context, dsn string) *storeStartup {
+	return &storeStartup{
+		ctx:     ctx,
+		dsn:     dsn,
+		errChan: make(chan error, 1),
+	}
+}
+
+// AddStep adds a step to run once the database is migrated. The steps are
+// run again if the start is retried, so they must be idempotent.
+func (s *storeStartup) AddStep(step func(context.Context) error) {
+	s.steps = append(s.steps, step)
+}
+
+// Start migrates the database, then runs the steps.
+func (s *storeStartup) Start() error {
+	config, err := pgxpool.ParseConfig(s.dsn)
+	if err != nil {
+		return err
+	}
+	if err := postgres.Migrate(config); err != nil {
+		return err
+	}
+	for _, step := range s.steps {
+		if err := step(s.ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop does nothing, the store is closed by the caller of Initialize.
+func (s *storeStartup) Stop() error {
+	return nil
+}
+
+// Err returns a channel on which to listen for terminal errors.
+func (s *storeStartup) Err() <-chan error {
+	return s.errChan
+}
+
+// Name returns the daemon name.
+func (s *storeStartup) Name() string {
+	return "store"
+}
+
+// Restartable returns true, the migrations and the steps are idempotent.
+func (s *storeStartup) Restartable() bool {
+	return true
+}
@@ -171,6 +171,12 @@ func New(ctx context.Context, c Config, opts ...Option) (*Tessend, error) {
 	return t, nil
 }
 
+// SetClusterID sets the ID of the cluster reported by tessend. It must be
+// called before Start.
+func (t *Tessend) SetClusterID(id string) {
+	t.clusterID = id
+}
+
 // GetStoreConfig gets information about how the cluster stores information.
 func (t *Tessend) GetStoreConfig() StoreConfig {
 	return StoreConfig{
@@ -0,0 +1,85 @@
+// Package timezone describes the time zone database with which the backend
+// converts wall clock times, such as the ones of the cron schedules of the
+// checks, so that operators can tell when it is outdated.
+package timezone
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// zoneinfoDirs are the directories in which the time package looks for the
+// time zone database, in order, when ZONEINFO is not set.
+var zoneinfoDirs = []string{
+	"/usr/share/zoneinfo/",
+	"/usr/share/lib/zoneinfo/",
+	"/usr/lib/locale/TZ/",
+	"/etc/zoneinfo/",
+}
+
+// Database is the time zone database of the backend.
+type Database struct {
+	// Source is the directory or archive of the database. It is empty when
+	// the database embedded in the backend is used.
+	Source string `json:"source,omitempty"`
+
+	// Version is the release of the database, such as 2024a, when it is
+	// known.
+	Version string `json:"version,omitempty"`
+
+	// Local is the name of the time zone of the backend.
+	Local string `json:"local"`
+}
+
+// Lookup returns the time zone database in use. It is looked up on each call,
+// since the database can be updated while the backend runs.
+func Lookup() Database {
+	db := Database{Local: time.Local.String()}
+	sources := zoneinfoDirs
+	if zoneinfo := os.Getenv("ZONEINFO"); zoneinfo != "" {
+		// ZONEINFO names either a directory or a zip archive
+		if info, err := os.Stat(zoneinfo); err == nil && !info.IsDir() {
+			db.Source = zoneinfo
+			return db
+		}
+		sources = append([]string{zoneinfo}, sources...)
+	}
+	if runtime.GOOS != "windows" {
+		for _, source := range sources {
+			if _, err := os.Stat(filepath.Join(source, "UTC")); err == nil {
+				db.Source = source
+				db.Version = version(source)
+				return db
+			}
+		}
+	}
+	goroot := filepath.Join(runtime.GOROOT(), "lib", "time", "zoneinfo.zip")
+	if _, err := os.Stat(goroot); err == nil {
+		db.Source = goroot
+	}
+	return db
+}
+
+// version returns the version of the database in dir, from the files in which
+// the distributions record it.
+func version(dir string) string {
+	if b, err := os.ReadFile(filepath.Join(dir, "+VERSION")); err == nil {
+		return strings.TrimSpace(string(b))
+	}
+	f, err := os.Open(filepath.Join(dir, "tzdata.zi"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	if scanner.Scan() {
+		if v := strings.TrimPrefix(scanner.Text(), "# version "); v != scanner.Text() {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
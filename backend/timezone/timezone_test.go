@@ -0,0 +1,40 @@
+package timezone
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the time zone database is not read from the filesystem on windows")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "UTC"), []byte("TZif"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tzdata.zi"), []byte("# version 2024a\n# This zic input file is in the public domain.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZONEINFO", dir)
+
+	db := Lookup()
+	if db.Source != dir {
+		t.Errorf("got source %q, want %q", db.Source, dir)
+	}
+	if got, want := db.Version, "2024a"; got != want {
+		t.Errorf("got version %q, want %q", got, want)
+	}
+	if db.Local == "" {
+		t.Error("expected the local time zone")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "+VERSION"), []byte("2024b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Lookup().Version, "2024b"; got != want {
+		t.Errorf("got version %q, want %q", got, want)
+	}
+}
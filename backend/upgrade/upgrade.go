@@ -0,0 +1,148 @@
+// Package upgrade coordinates rolling upgrades of sensu-backend clusters.
+//
+// Every backend registers its version in the store with its membership
+// heartbeat. The Coordinator periodically computes the cluster version, which
+// is the lowest version run by a present backend, and hands it to the feature
+// gates. Features that are not compatible with older backends
+// stay disabled until every backend of the cluster has been upgraded.
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/sirupsen/logrus"
+
+	"github.com/sensu/sensu-go/backend/featuregate"
+	"github.com/sensu/sensu-go/backend/membership"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+// DefaultInterval is the default interval at which the cluster version is
+// computed.
+const DefaultInterval = 10 * time.Second
+
+// ErrUnknownVersion is returned by ClusterVersion when the version of a
+// present backend is unknown.
+var ErrUnknownVersion = errors.New("unknown backend version")
+
+var logger = logrus.WithFields(logrus.Fields{
+	"component": "upgrade",
+})
+
+// ClusterVersion returns the lowest version run by the present backends of
+// operators. Backends that checked in without a version predate version
+// registration, and are reported as an error, since they can't be assumed to
+// be compatible with anything. Backends that run a development build, whose
+// version can't be parsed, are ignored.
+func ClusterVersion(operators []store.OperatorState, now time.Time) (semver.Version, error) {
+	var (
+		min   semver.Version
+		found bool
+	)
+	for _, op := range operators {
+		if op.Type != store.BackendOperator || !membership.IsPresent(op, now) {
+			continue
+		}
+		heartbeat, err := membership.DecodeHeartbeat(op)
+		if err != nil {
+			return semver.Version{}, fmt.Errorf("backend %s: bad heartbeat: %s", op.Name, err)
+		}
+		if heartbeat.Version == "" {
+			return semver.Version{}, fmt.Errorf("backend %s did not register its version: %w", op.Name, ErrUnknownVersion)
+		}
+		version, err := semver.ParseTolerant(heartbeat.Version)
+		if err != nil {
+			logger.WithField("backend", op.Name).Debugf("ignoring backend with unknown version %q", heartbeat.Version)
+			continue
+		}
+		if !found || version.LT(min) {
+			min = version
+			found = true
+		}
+	}
+	if !found {
+		return semver.Version{}, fmt.Errorf("no backend registered a version: %w", ErrUnknownVersion)
+	}
+	return min, nil
+}
+
+// Config configures a Coordinator.
+type Config struct {
+	// Queryer is used to list the backends of the cluster.
+	Queryer store.OperatorQueryer
+
+	// FeatureGates are updated with the cluster version.
+	FeatureGates *featuregate.Gates
+
+	// Interval is the interval at which the cluster version is computed. It
+	// defaults to DefaultInterval.
+	Interval time.Duration
+}
+
+// Coordinator keeps the cluster version of feature gates up to date.
+type Coordinator struct {
+	queryer      store.OperatorQueryer
+	featureGates *featuregate.Gates
+	interval     time.Duration
+	version      *semver.Version
+}
+
+// New creates a new Coordinator.
+func New(c Config) *Coordinator {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Coordinator{
+		queryer:      c.Queryer,
+		featureGates: c.FeatureGates,
+		interval:     interval,
+	}
+}
+
+// Run computes the cluster version until ctx is canceled.
+func (c *Coordinator) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Update(ctx); err != nil {
+			logger.WithError(err).Debug("cluster version is unknown")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update computes the cluster version, and updates the feature gates if it
+// changed. The cluster version can decrease, if a backend is downgraded or an
+// older backend joins the cluster, in which case the features that depend on
+// it are disabled again.
+func (c *Coordinator) Update(ctx context.Context) error {
+	operators, err := c.queryer.ListOperators(ctx, store.OperatorKey{Type: store.BackendOperator})
+	if err != nil {
+		return err
+	}
+	version, err := ClusterVersion(operators, time.Now())
+	if errors.Is(err, ErrUnknownVersion) && c.version != nil {
+		logger.WithError(err).Warn("cluster version is unknown, disabling features that depend on it")
+		c.version = nil
+		c.featureGates.SetClusterVersion(nil)
+	}
+	if err != nil {
+		return err
+	}
+	if c.version != nil && version.EQ(*c.version) {
+		return nil
+	}
+	logger.WithField("version", version.String()).Info("cluster version updated")
+	c.version = &version
+	c.featureGates.SetClusterVersion(&version)
+	return nil
+}
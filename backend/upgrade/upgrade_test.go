@@ -0,0 +1,169 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sensu/sensu-go/backend/featuregate"
+	"github.com/sensu/sensu-go/backend/membership"
+	"github.com/sensu/sensu-go/backend/store"
+)
+
+func backend(name, version string, lastUpdate time.Time) store.OperatorState {
+	op := store.OperatorState{
+		Type:           store.BackendOperator,
+		Name:           name,
+		CheckInTimeout: 10 * time.Second,
+		Present:        true,
+		LastUpdate:     lastUpdate,
+	}
+	if version != "" {
+		op.Metadata = membership.Heartbeat{Version: version}.Encode()
+	}
+	return op
+}
+
+func TestClusterVersion(t *testing.T) {
+	now := time.Now()
+	absent := backend("absent", "6.0.0", now)
+	absent.Present = false
+	empty := json.RawMessage("{}")
+	old := backend("old", "", now)
+	old.Metadata = &empty
+
+	tests := []struct {
+		name      string
+		operators []store.OperatorState
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "lowest version",
+			operators: []store.OperatorState{
+				backend("a", "7.1.0", now),
+				backend("b", "7.0.2", now),
+				backend("c", "7.1.0", now),
+			},
+			want: "7.0.2",
+		},
+		{
+			name: "absent and timed out backends are ignored",
+			operators: []store.OperatorState{
+				backend("a", "7.1.0", now),
+				backend("b", "7.0.2", now.Add(-time.Minute)),
+				absent,
+			},
+			want: "7.1.0",
+		},
+		{
+			name: "development builds are ignored",
+			operators: []store.OperatorState{
+				backend("a", "7.1.0", now),
+				backend("b", "(devel)", now),
+			},
+			want: "7.1.0",
+		},
+		{
+			name: "backend without a version",
+			operators: []store.OperatorState{
+				backend("a", "7.1.0", now),
+				backend("b", "", now),
+			},
+			wantErr: true,
+		},
+		{
+			name: "backend with empty metadata",
+			operators: []store.OperatorState{
+				backend("a", "7.1.0", now),
+				old,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no backends",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ClusterVersion(tt.operators, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ClusterVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrUnknownVersion) {
+					t.Errorf("expected ErrUnknownVersion, got %v", err)
+				}
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("ClusterVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+type testQueryer struct {
+	operators []store.OperatorState
+	err       error
+}
+
+func (q *testQueryer) QueryOperator(context.Context, store.OperatorKey) (store.OperatorState, error) {
+	return store.OperatorState{}, errors.New("not implemented")
+}
+
+func (q *testQueryer) ListOperators(context.Context, store.OperatorKey) ([]store.OperatorState, error) {
+	return q.operators, q.err
+}
+
+func TestCoordinatorUpdate(t *testing.T) {
+	const feature featuregate.Feature = "TestUpgrade"
+	gates := featuregate.New(map[featuregate.Feature]featuregate.Spec{
+		feature: {Default: true, Stage: featuregate.Beta, MinClusterVersion: "7.1.0"},
+	})
+	queryer := &testQueryer{
+		operators: []store.OperatorState{
+			backend("a", "7.1.0", time.Now()),
+			backend("b", "7.0.2", time.Now()),
+		},
+	}
+	coordinator := New(Config{Queryer: queryer, FeatureGates: gates})
+	ctx := context.Background()
+
+	if err := coordinator.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if gates.Enabled(feature) {
+		t.Fatal("feature should be disabled during the upgrade")
+	}
+
+	queryer.operators[1] = backend("b", "7.1.0", time.Now())
+	if err := coordinator.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !gates.Enabled(feature) {
+		t.Fatal("feature should be enabled after the upgrade")
+	}
+
+	// a transient error doesn't change the cluster version
+	queryer.err = errors.New("connection refused")
+	if err := coordinator.Update(ctx); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !gates.Enabled(feature) {
+		t.Fatal("feature should stay enabled")
+	}
+
+	// a backend that predates version registration joins the cluster
+	queryer.err = nil
+	queryer.operators = append(queryer.operators, backend("c", "", time.Now()))
+	if err := coordinator.Update(ctx); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("expected ErrUnknownVersion, got %v", err)
+	}
+	if gates.Enabled(feature) {
+		t.Fatal("feature should be disabled")
+	}
+}
@@ -64,6 +64,8 @@ type OutdatedAsset struct {
 	BonsaiNamespace string `json:"bonsai_namespace,omitempty"`
 	// AssetName is the name of the Sensu asset
 	AssetName string `json:"asset_name,omitempty"`
+	// AssetNamespace is the namespace of the Sensu asset
+	AssetNamespace string `json:"asset_namespace,omitempty"`
 	// CurrentVersion is the version of the Sensu asset currently installed
 	CurrentVersion string `json:"current_version,omitempty"`
 	// LatestVersion is the latest version of the asset in Bonsai
@@ -0,0 +1,88 @@
+package bonsai
+
+import (
+	"encoding/json"
+	"fmt"
+
+	goversion "github.com/hashicorp/go-version"
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/core/v3/types"
+)
+
+// Outdated compares the assets installed from Bonsai against their latest
+// version on Bonsai and returns the assets that can be upgraded. The assets
+// that were not installed from Bonsai are ignored.
+func Outdated(assets []*corev2.Asset, client Client) ([]OutdatedAsset, error) {
+	outdatedAssets := []OutdatedAsset{}
+
+	for _, asset := range assets {
+		annotations := asset.ObjectMeta.Annotations
+		if annotations[URLAnnotation] == "" {
+			continue
+		}
+		bonsaiVersion := annotations[VersionAnnotation]
+		bonsaiNamespace := annotations[NamespaceAnnotation]
+		bonsaiName := annotations[NameAnnotation]
+
+		if bonsaiVersion == "" {
+			return nil, fmt.Errorf("asset missing %s annotation: %s", VersionAnnotation, asset.Name)
+		}
+		if bonsaiNamespace == "" {
+			return nil, fmt.Errorf("asset missing %s annotation: %s", NamespaceAnnotation, asset.Name)
+		}
+		if bonsaiName == "" {
+			return nil, fmt.Errorf("asset missing %s annotation: %s", NameAnnotation, asset.Name)
+		}
+
+		installedVersion, err := goversion.NewVersion(bonsaiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse version %q of asset %s: %s", bonsaiVersion, asset.Name, err)
+		}
+
+		bonsaiAsset, err := client.FetchAsset(bonsaiNamespace, bonsaiName)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch asset %s: %s", asset.Name, err)
+		}
+
+		latestVersion := bonsaiAsset.LatestVersion()
+		if latestVersion == nil {
+			return nil, fmt.Errorf("could not parse the latest version of asset %s", asset.Name)
+		}
+
+		if installedVersion.LessThan(latestVersion) {
+			outdatedAssets = append(outdatedAssets, OutdatedAsset{
+				BonsaiName:      bonsaiName,
+				BonsaiNamespace: bonsaiNamespace,
+				AssetName:       asset.Name,
+				AssetNamespace:  asset.Namespace,
+				CurrentVersion:  installedVersion.Original(),
+				LatestVersion:   latestVersion.Original(),
+			})
+		}
+	}
+
+	return outdatedAssets, nil
+}
+
+// Upgrade fetches the definition of the latest version of an outdated asset
+// from Bonsai, and returns it under the name and namespace of the installed
+// asset.
+func Upgrade(outdated OutdatedAsset, client Client) (*corev2.Asset, error) {
+	definition, err := client.FetchAssetVersion(outdated.BonsaiNamespace, outdated.BonsaiName, outdated.LatestVersion)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch version %s of asset %s: %s", outdated.LatestVersion, outdated.AssetName, err)
+	}
+
+	var wrapper types.Wrapper
+	if err := json.Unmarshal([]byte(definition), &wrapper); err != nil {
+		return nil, fmt.Errorf("could not parse version %s of asset %s: %s", outdated.LatestVersion, outdated.AssetName, err)
+	}
+	asset, ok := wrapper.Value.(*corev2.Asset)
+	if !ok {
+		return nil, fmt.Errorf("version %s of asset %s is not an asset definition", outdated.LatestVersion, outdated.AssetName)
+	}
+	asset.Name = outdated.AssetName
+	asset.Namespace = outdated.AssetNamespace
+
+	return asset, nil
+}
@@ -0,0 +1,295 @@
+package bonsai
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockedBonsaiClient struct {
+	mock.Mock
+}
+
+func (c *mockedBonsaiClient) FetchAsset(namespace, name string) (*Asset, error) {
+	args := c.Called(namespace, name)
+	return args.Get(0).(*Asset), args.Error(1)
+}
+
+func (c *mockedBonsaiClient) FetchAssetVersion(namespace, name, version string) (string, error) {
+	args := c.Called(namespace, name, version)
+	return args.String(0), args.Error(1)
+}
+
+func TestOutdated(t *testing.T) {
+	type clientFunc func(*mockedBonsaiClient)
+
+	bonsaiAsset := corev2.Asset{
+		ObjectMeta: corev2.ObjectMeta{
+			Name: "foo",
+			Annotations: map[string]string{
+				URLAnnotation:       "http://127.0.0.1",
+				VersionAnnotation:   "0.1.0",
+				NamespaceAnnotation: "sensu",
+				NameAnnotation:      "testasset",
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		assets     []*corev2.Asset
+		clientFunc clientFunc
+		want       []OutdatedAsset
+		wantErr    bool
+	}{
+		{
+			name: "asset without bonsai API URL annotation is ignored",
+			assets: []*corev2.Asset{
+				corev2.FixtureAsset("foo"),
+			},
+			want: []OutdatedAsset{},
+		},
+		{
+			name: "asset without bonsai version returns an error",
+			assets: []*corev2.Asset{
+				&corev2.Asset{
+					ObjectMeta: corev2.ObjectMeta{
+						Name: "foo",
+						Annotations: map[string]string{
+							URLAnnotation: "http://127.0.0.1",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "asset without bonsai namespace returns an error",
+			assets: []*corev2.Asset{
+				&corev2.Asset{
+					ObjectMeta: corev2.ObjectMeta{
+						Name: "foo",
+						Annotations: map[string]string{
+							URLAnnotation:     "http://127.0.0.1",
+							VersionAnnotation: "0.1.0",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "asset without bonsai name returns an error",
+			assets: []*corev2.Asset{
+				&corev2.Asset{
+					ObjectMeta: corev2.ObjectMeta{
+						Name: "foo",
+						Annotations: map[string]string{
+							URLAnnotation:       "http://127.0.0.1",
+							VersionAnnotation:   "0.1.0",
+							NamespaceAnnotation: "sensu",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "asset without bonsai name returns an error",
+			assets: []*corev2.Asset{
+				&corev2.Asset{
+					ObjectMeta: corev2.ObjectMeta{
+						Name: "foo",
+						Annotations: map[string]string{
+							URLAnnotation:       "http://127.0.0.1",
+							VersionAnnotation:   "0.1.0",
+							NamespaceAnnotation: "sensu",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "asset with an invalid version returns an error",
+			assets: []*corev2.Asset{
+				&corev2.Asset{
+					ObjectMeta: corev2.ObjectMeta{
+						Name: "foo",
+						Annotations: map[string]string{
+							URLAnnotation:       "http://127.0.0.1",
+							VersionAnnotation:   "invalid",
+							NamespaceAnnotation: "sensu",
+							NameAnnotation:      "testasset",
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "bonsai client error",
+			assets: []*corev2.Asset{&bonsaiAsset},
+			clientFunc: func(c *mockedBonsaiClient) {
+				c.On("FetchAsset", "sensu", "testasset").
+					Return(&Asset{}, errors.New("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "invalid asset version in fetched asset",
+			assets: []*corev2.Asset{&bonsaiAsset},
+			clientFunc: func(c *mockedBonsaiClient) {
+				c.On("FetchAsset", "sensu", "testasset").
+					Return(
+						&Asset{Versions: []*AssetVersionGrouping{&AssetVersionGrouping{Version: "invalid"}}},
+						nil,
+					)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "up-to-date assset is not marked as outdated",
+			assets: []*corev2.Asset{&bonsaiAsset},
+			clientFunc: func(c *mockedBonsaiClient) {
+				c.On("FetchAsset", "sensu", "testasset").
+					Return(
+						&Asset{Versions: []*AssetVersionGrouping{&AssetVersionGrouping{Version: "0.1.0"}}},
+						nil,
+					)
+			},
+			want: []OutdatedAsset{},
+		},
+		{
+			name:   "older asset is marked as outdated",
+			assets: []*corev2.Asset{&bonsaiAsset},
+			clientFunc: func(c *mockedBonsaiClient) {
+				c.On("FetchAsset", "sensu", "testasset").
+					Return(
+						&Asset{Versions: []*AssetVersionGrouping{&AssetVersionGrouping{Version: "0.2.0"}}},
+						nil,
+					)
+			},
+			want: []OutdatedAsset{
+				OutdatedAsset{
+					BonsaiName:      "testasset",
+					BonsaiNamespace: "sensu",
+					AssetName:       "foo",
+					CurrentVersion:  "0.1.0",
+					LatestVersion:   "0.2.0",
+				},
+			},
+		},
+		{
+			name:   "new prefixed version in Bonsai is still considered",
+			assets: []*corev2.Asset{&bonsaiAsset},
+			clientFunc: func(c *mockedBonsaiClient) {
+				c.On("FetchAsset", "sensu", "testasset").
+					Return(
+						&Asset{Versions: []*AssetVersionGrouping{&AssetVersionGrouping{Version: "v0.2.0"}}},
+						nil,
+					)
+			},
+			want: []OutdatedAsset{
+				OutdatedAsset{
+					BonsaiName:      "testasset",
+					BonsaiNamespace: "sensu",
+					AssetName:       "foo",
+					CurrentVersion:  "0.1.0",
+					LatestVersion:   "v0.2.0",
+				},
+			},
+		},
+		{
+			name: "local prefixed assets are still considered outdated with new Bonsai version that's not prefixed",
+			assets: []*corev2.Asset{
+				&corev2.Asset{
+					ObjectMeta: corev2.ObjectMeta{
+						Name: "foo",
+						Annotations: map[string]string{
+							URLAnnotation:       "http://127.0.0.1",
+							VersionAnnotation:   "v0.1.0",
+							NamespaceAnnotation: "sensu",
+							NameAnnotation:      "testasset",
+						},
+					},
+				},
+			},
+			clientFunc: func(c *mockedBonsaiClient) {
+				c.On("FetchAsset", "sensu", "testasset").
+					Return(
+						&Asset{Versions: []*AssetVersionGrouping{&AssetVersionGrouping{Version: "0.2.0"}}},
+						nil,
+					)
+			},
+			want: []OutdatedAsset{
+				OutdatedAsset{
+					BonsaiName:      "testasset",
+					BonsaiNamespace: "sensu",
+					AssetName:       "foo",
+					CurrentVersion:  "v0.1.0",
+					LatestVersion:   "0.2.0",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockedBonsaiClient{}
+			if tt.clientFunc != nil {
+				tt.clientFunc(client)
+			}
+
+			got, err := Outdated(tt.assets, client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Outdated() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Outdated() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	definition := `{"type": "Asset", "api_version": "core/v2", "spec": {"metadata": {"name": "sensu/testasset", "annotations": {"io.sensu.bonsai.version": "0.2.0"}}, "url": "http://127.0.0.1/testasset_0.2.0.tar.gz", "sha512": "abcd"}}`
+	outdated := OutdatedAsset{
+		BonsaiName:      "testasset",
+		BonsaiNamespace: "sensu",
+		AssetName:       "foo",
+		AssetNamespace:  "dev",
+		CurrentVersion:  "0.1.0",
+		LatestVersion:   "0.2.0",
+	}
+
+	client := &mockedBonsaiClient{}
+	client.On("FetchAssetVersion", "sensu", "testasset", "0.2.0").Return(definition, nil)
+
+	asset, err := Upgrade(outdated, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "foo", asset.Name)
+	assert.Equal(t, "dev", asset.Namespace)
+	assert.Equal(t, "0.2.0", asset.Annotations[VersionAnnotation])
+	assert.Equal(t, "http://127.0.0.1/testasset_0.2.0.tar.gz", asset.URL)
+}
+
+func TestUpgradeErrors(t *testing.T) {
+	outdated := OutdatedAsset{BonsaiName: "testasset", BonsaiNamespace: "sensu", AssetName: "foo", LatestVersion: "0.2.0"}
+
+	client := &mockedBonsaiClient{}
+	client.On("FetchAssetVersion", "sensu", "testasset", "0.2.0").Return("", errors.New("error"))
+	_, err := Upgrade(outdated, client)
+	assert.Error(t, err)
+
+	client = &mockedBonsaiClient{}
+	client.On("FetchAssetVersion", "sensu", "testasset", "0.2.0").Return(`{"type": "CheckConfig", "spec": {}}`, nil)
+	_, err = Upgrade(outdated, client)
+	assert.Error(t, err)
+}
@@ -0,0 +1,10 @@
+package client
+
+// AgentsPath is the api path for agent sessions.
+var AgentsPath = createNSBasePath(coreAPIGroup, coreAPIVersion, "agents")
+
+// DisconnectAgent closes the session of the given agent on the backend that
+// serves the request
+func (client *RestClient) DisconnectAgent(namespace, name string) error {
+	return client.Delete(AgentsPath(namespace, name))
+}
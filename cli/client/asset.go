@@ -6,6 +6,7 @@ import (
 
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/core/v3/types"
+	"github.com/sensu/sensu-go/bonsai"
 )
 
 // AssetsPath is the api path for assets.
@@ -73,3 +74,45 @@ func (client *RestClient) UpdateAsset(asset *corev2.Asset) (err error) {
 
 	return nil
 }
+
+// ListOutdatedAssets lists the assets of a namespace, or of every namespace if
+// it is empty, that have a newer version on Bonsai
+func (client *RestClient) ListOutdatedAssets(namespace string, options *ListOptions) ([]bonsai.OutdatedAsset, error) {
+	request := client.R().SetQueryParam("outdated", "true")
+	ApplyListOptions(request, options)
+
+	path := AssetsPath(namespace)
+	res, err := request.Get(path)
+	if err != nil {
+		return nil, fmt.Errorf("GET %q: %s", path, err)
+	}
+
+	if res.StatusCode() >= 400 {
+		return nil, UnmarshalError(res)
+	}
+
+	var assets []bonsai.OutdatedAsset
+	err = json.Unmarshal(res.Body(), &assets)
+	return assets, err
+}
+
+// UpgradeOutdatedAssets upgrades the outdated assets of a namespace, or of
+// every namespace if it is empty, to their latest version on Bonsai
+func (client *RestClient) UpgradeOutdatedAssets(namespace string, options *ListOptions) ([]bonsai.OutdatedAsset, error) {
+	request := client.R().SetQueryParam("outdated", "true")
+	ApplyListOptions(request, options)
+
+	path := AssetsPath(namespace)
+	res, err := request.Put(path)
+	if err != nil {
+		return nil, fmt.Errorf("PUT %q: %s", path, err)
+	}
+
+	if res.StatusCode() >= 400 {
+		return nil, UnmarshalError(res)
+	}
+
+	var assets []bonsai.OutdatedAsset
+	err = json.Unmarshal(res.Body(), &assets)
+	return assets, err
+}
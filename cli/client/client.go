@@ -4,7 +4,10 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -26,6 +29,12 @@ type RestClient struct {
 
 	configured   bool
 	expiredToken bool
+
+	// warnings is where the deprecation warnings of the API are printed,
+	// and warned the warnings already printed.
+	warnings io.Writer
+	warnedMu sync.Mutex
+	warned   map[string]struct{}
 }
 
 func init() {
@@ -37,7 +46,7 @@ func init() {
 // New builds a new client with defaults
 func New(config config.Config) *RestClient {
 	restyInst := resty.New()
-	client := &RestClient{resty: restyInst, config: config}
+	client := &RestClient{resty: restyInst, config: config, warnings: os.Stderr}
 
 	// set http client timeout
 	restyInst.SetTimeout(config.Timeout())
@@ -113,6 +122,9 @@ func New(config config.Config) *RestClient {
 		})
 	}
 
+	// Print the deprecation warnings of the API
+	restyInst.OnAfterResponse(client.printDeprecationWarnings)
+
 	restyInst.SetLogger(logger)
 
 	// Disable warning log entries from resty when an HTTP address is used to
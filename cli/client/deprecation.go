@@ -0,0 +1,34 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sensu/sensu-go/util/deprecation"
+)
+
+// printDeprecationWarnings prints the deprecation warnings of the response of
+// the API, so that the user knows before the deprecated endpoints and fields
+// are removed. Every warning is printed once.
+func (client *RestClient) printDeprecationWarnings(_ *resty.Client, resp *resty.Response) error {
+	if client.warnings == nil {
+		return nil
+	}
+	client.warnedMu.Lock()
+	defer client.warnedMu.Unlock()
+	for _, value := range resp.Header().Values(deprecation.WarningHeader) {
+		warning, ok := deprecation.ParseWarning(value)
+		if !ok {
+			continue
+		}
+		if _, ok := client.warned[warning]; ok {
+			continue
+		}
+		if client.warned == nil {
+			client.warned = make(map[string]struct{})
+		}
+		client.warned[warning] = struct{}{}
+		fmt.Fprintf(client.warnings, "Warning: %s\n", warning)
+	}
+	return nil
+}
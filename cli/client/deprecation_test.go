@@ -0,0 +1,32 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sensu/sensu-go/util/deprecation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintDeprecationWarnings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(deprecation.WarningHeader, deprecation.FormatWarning("field checks.subdue is deprecated"))
+		w.Header().Add(deprecation.WarningHeader, `199 - "not a deprecation"`)
+	}))
+	defer server.Close()
+
+	var warnings bytes.Buffer
+	client := &RestClient{resty: resty.New(), warnings: &warnings}
+	client.resty.OnAfterResponse(client.printDeprecationWarnings)
+
+	// The warning is printed once
+	for i := 0; i < 2; i++ {
+		_, err := client.resty.R().Get(server.URL)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "Warning: field checks.subdue is deprecated\n", warnings.String())
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -13,6 +14,9 @@ import (
 type APIError struct {
 	Message string `json:"message"`
 	Code    uint32 `json:"code,omitempty"`
+	// Reason is the machine-readable reason of the error. It is empty if
+	// the backend predates the reasons.
+	Reason string `json:"reason,omitempty"`
 }
 
 func (a APIError) Error() string {
@@ -45,3 +49,47 @@ func UnmarshalError(res *resty.Response) error {
 
 	return apiErr
 }
+
+// ErrorCode returns the code of the API error err.
+func ErrorCode(err error) (actions.ErrCode, bool) {
+	var apiErr APIError
+	if !errors.As(err, &apiErr) {
+		return 0, false
+	}
+	return actions.ErrCode(apiErr.Code), true
+}
+
+// ErrorReason returns the reason of the API error err, or the name of its
+// code if the backend didn't return a reason. It returns an empty string if
+// err is not an API error.
+func ErrorReason(err error) string {
+	var apiErr APIError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+	if apiErr.Reason != "" {
+		return apiErr.Reason
+	}
+	return actions.ErrCode(apiErr.Code).String()
+}
+
+// IsNotFound returns true if err is an API error for a resource that doesn't
+// exist.
+func IsNotFound(err error) bool {
+	code, ok := ErrorCode(err)
+	return ok && code == actions.NotFound
+}
+
+// IsAlreadyExists returns true if err is an API error for a resource that
+// already exists.
+func IsAlreadyExists(err error) bool {
+	code, ok := ErrorCode(err)
+	return ok && code == actions.AlreadyExistsErr
+}
+
+// IsPreconditionFailed returns true if err is an API error for a request
+// whose precondition failed.
+func IsPreconditionFailed(err error) bool {
+	code, ok := ErrorCode(err)
+	return ok && code == actions.PreconditionFailed
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorHelpers(t *testing.T) {
+	notFound := fmt.Errorf("get check: %w", APIError{Message: "not found", Code: uint32(actions.NotFound), Reason: "not_found"})
+	assert.True(t, IsNotFound(notFound))
+	assert.False(t, IsAlreadyExists(notFound))
+	assert.Equal(t, "not_found", ErrorReason(notFound))
+
+	// Backends that predate the reasons only return a code
+	exists := APIError{Message: "exists", Code: uint32(actions.AlreadyExistsErr)}
+	assert.True(t, IsAlreadyExists(exists))
+	assert.Equal(t, "already_exists", ErrorReason(exists))
+
+	code, ok := ErrorCode(errors.New("boom"))
+	assert.False(t, ok)
+	assert.Equal(t, actions.ErrCode(0), code)
+	assert.False(t, IsPreconditionFailed(errors.New("boom")))
+	assert.Equal(t, "", ErrorReason(errors.New("boom")))
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/go-resty/resty/v2"
 	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/core/v3/types"
 )
@@ -61,3 +62,45 @@ func (client *RestClient) ResolveEvent(event *corev2.Event) error {
 	event.Timestamp = event.Check.Executed
 	return client.UpdateEvent(event)
 }
+
+// BulkEventResult is the result of an operation on the events that match a
+// selector.
+type BulkEventResult struct {
+	Events []string `json:"events"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// BulkDeleteEvents deletes the events of namespace that match the selectors of
+// options.
+func (client *RestClient) BulkDeleteEvents(namespace string, options *ListOptions) (*BulkEventResult, error) {
+	request := client.R()
+	ApplyListOptions(request, options)
+	res, err := request.Delete(EventsPath(namespace))
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalBulkEventResult(res)
+}
+
+// BulkResolveEvents resolves the failing events of namespace that match the
+// selectors of options.
+func (client *RestClient) BulkResolveEvents(namespace string, options *ListOptions) (*BulkEventResult, error) {
+	request := client.R()
+	ApplyListOptions(request, options)
+	res, err := request.Patch(EventsPath(namespace))
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalBulkEventResult(res)
+}
+
+func unmarshalBulkEventResult(res *resty.Response) (*BulkEventResult, error) {
+	if res.StatusCode() >= 400 {
+		return nil, UnmarshalError(res)
+	}
+	var result BulkEventResult
+	if err := json.Unmarshal(res.Body(), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -7,6 +7,8 @@ import (
 	corev2 "github.com/sensu/core/v2"
 	corev3 "github.com/sensu/core/v3"
 	"github.com/sensu/core/v3/types"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/bonsai"
 )
 
 // ListOptions represents the various options that can be used when listing
@@ -26,6 +28,7 @@ type ListOptions struct {
 
 // APIClient client methods across the Sensu API
 type APIClient interface {
+	AgentAPIClient
 	APIKeyClient
 	AuthenticationAPIClient
 	AssetAPIClient
@@ -41,6 +44,7 @@ type APIClient interface {
 	MutatorAPIClient
 	NamespaceAPIClient
 	PipelineAPIClient
+	PruneReportAPIClient
 	RoleAPIClient
 	RoleBindingAPIClient
 	UserAPIClient
@@ -49,6 +53,11 @@ type APIClient interface {
 	LicenseClient
 }
 
+// AgentAPIClient client methods for agent sessions
+type AgentAPIClient interface {
+	DisconnectAgent(namespace, name string) error
+}
+
 // APIKeyClient exposes client methods for api keys.
 type APIKeyClient interface {
 	// PostAPIKey creates an api key and returns the location header.
@@ -87,6 +96,8 @@ type AssetAPIClient interface {
 	CreateAsset(*corev2.Asset) error
 	UpdateAsset(*corev2.Asset) error
 	FetchAsset(string) (*corev2.Asset, error)
+	ListOutdatedAssets(string, *ListOptions) ([]bonsai.OutdatedAsset, error)
+	UpgradeOutdatedAssets(string, *ListOptions) ([]bonsai.OutdatedAsset, error)
 }
 
 // CheckAPIClient client methods for checks
@@ -139,6 +150,12 @@ type EventAPIClient interface {
 	DeleteEvent(namespace, entity, check string) error
 	UpdateEvent(*corev2.Event) error
 	ResolveEvent(*corev2.Event) error
+
+	// BulkDeleteEvents deletes the events that match the selectors of options.
+	BulkDeleteEvents(namespace string, options *ListOptions) (*BulkEventResult, error)
+
+	// BulkResolveEvents resolves the events that match the selectors of options.
+	BulkResolveEvents(namespace string, options *ListOptions) (*BulkEventResult, error)
 }
 
 // HandlerAPIClient client methods for handlers
@@ -184,6 +201,11 @@ type PipelineAPIClient interface {
 	FetchPipeline(string) (*corev2.Pipeline, error)
 }
 
+// PruneReportAPIClient client methods for prune reports
+type PruneReportAPIClient interface {
+	FetchPruneReport(namespace string) (*actions.PruneReport, error)
+}
+
 // UserAPIClient client methods for users
 type UserAPIClient interface {
 	AddGroupToUser(string, string) error
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+)
+
+// PruneReportPath is the api path for prune reports.
+var PruneReportPath = createNSBasePath(coreAPIGroup, coreAPIVersion, "prune-report")
+
+// FetchPruneReport fetches the prune report of a namespace
+func (client *RestClient) FetchPruneReport(namespace string) (*actions.PruneReport, error) {
+	res, err := client.R().Get(PruneReportPath(namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() >= 400 {
+		return nil, UnmarshalError(res)
+	}
+
+	var report actions.PruneReport
+	if err := json.Unmarshal(res.Body(), &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,7 @@
+package testing
+
+// DisconnectAgent for use with mock lib
+func (c *MockClient) DisconnectAgent(namespace, name string) error {
+	args := c.Called(namespace, name)
+	return args.Error(0)
+}
@@ -2,6 +2,8 @@ package testing
 
 import (
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/bonsai"
+	"github.com/sensu/sensu-go/cli/client"
 )
 
 // FetchAsset for use with mock lib
@@ -21,3 +23,15 @@ func (c *MockClient) UpdateAsset(asset *corev2.Asset) error {
 	args := c.Called(asset)
 	return args.Error(0)
 }
+
+// ListOutdatedAssets for use with mock lib
+func (c *MockClient) ListOutdatedAssets(namespace string, options *client.ListOptions) ([]bonsai.OutdatedAsset, error) {
+	args := c.Called(namespace, options)
+	return args.Get(0).([]bonsai.OutdatedAsset), args.Error(1)
+}
+
+// UpgradeOutdatedAssets for use with mock lib
+func (c *MockClient) UpgradeOutdatedAssets(namespace string, options *client.ListOptions) ([]bonsai.OutdatedAsset, error) {
+	args := c.Called(namespace, options)
+	return args.Get(0).([]bonsai.OutdatedAsset), args.Error(1)
+}
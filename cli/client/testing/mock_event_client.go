@@ -2,6 +2,7 @@ package testing
 
 import (
 	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/cli/client"
 )
 
 // FetchEvent for use with mock lib
@@ -27,3 +28,15 @@ func (c *MockClient) ResolveEvent(event *corev2.Event) error {
 	args := c.Called(event)
 	return args.Error(0)
 }
+
+// BulkDeleteEvents for use with mock lib
+func (c *MockClient) BulkDeleteEvents(namespace string, options *client.ListOptions) (*client.BulkEventResult, error) {
+	args := c.Called(namespace, options)
+	return args.Get(0).(*client.BulkEventResult), args.Error(1)
+}
+
+// BulkResolveEvents for use with mock lib
+func (c *MockClient) BulkResolveEvents(namespace string, options *client.ListOptions) (*client.BulkEventResult, error) {
+	args := c.Called(namespace, options)
+	return args.Get(0).(*client.BulkEventResult), args.Error(1)
+}
@@ -0,0 +1,11 @@
+package testing
+
+import (
+	"github.com/sensu/sensu-go/backend/apid/actions"
+)
+
+// FetchPruneReport for use with mock lib
+func (c *MockClient) FetchPruneReport(namespace string) (*actions.PruneReport, error) {
+	args := c.Called(namespace)
+	return args.Get(0).(*actions.PruneReport), args.Error(1)
+}
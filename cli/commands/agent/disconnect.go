@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sensu/sensu-go/cli"
+	"github.com/spf13/cobra"
+)
+
+// DisconnectCommand adds a command that allows user to disconnect the session
+// of an agent
+func DisconnectCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disconnect [NAME]",
+		Short: "disconnect the session of an agent",
+		Long: "Disconnect the session of an agent from the backend that serves the request. " +
+			"The agent reconnects afterwards, possibly to another backend.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// If no name is present print out usage
+			if len(args) != 1 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+
+			if err := cli.Client.DisconnectAgent(cli.Config.Namespace(), args[0]); err != nil {
+				return err
+			}
+
+			_, err := fmt.Fprintln(cmd.OutOrStdout(), "Disconnected")
+			return err
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+
+	client "github.com/sensu/sensu-go/cli/client/testing"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisconnectCommand(t *testing.T) {
+	cli := test.NewMockCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("DisconnectAgent", "default", "agent1").Return(nil)
+
+	cmd := DisconnectCommand(cli)
+	out, err := test.RunCmd(cmd, []string{"agent1"})
+	require.NoError(t, err)
+	assert.Contains(t, out, "Disconnected")
+}
+
+func TestDisconnectCommandWithoutName(t *testing.T) {
+	cli := test.NewMockCLI()
+	cmd := DisconnectCommand(cli)
+	out, err := test.RunCmd(cmd, []string{})
+	assert.Error(t, err)
+	assert.Contains(t, out, "Usage")
+}
+
+func TestDisconnectCommandWithErr(t *testing.T) {
+	cli := test.NewMockCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("DisconnectAgent", "default", "agent1").Return(errors.New("not found"))
+
+	cmd := DisconnectCommand(cli)
+	out, err := test.RunCmd(cmd, []string{"agent1"})
+	assert.Error(t, err)
+	assert.NotContains(t, out, "Disconnected")
+}
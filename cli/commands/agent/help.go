@@ -0,0 +1,23 @@
+package agent
+
+import (
+	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/commands/helpers"
+	"github.com/spf13/cobra"
+)
+
+// HelpCommand defines new parent
+func HelpCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Manage agent sessions",
+		RunE:  helpers.DefaultSubCommandRunE,
+	}
+
+	// Add sub-commands
+	cmd.AddCommand(
+		DisconnectCommand(cli),
+	)
+
+	return cmd
+}
@@ -2,10 +2,8 @@ package asset
 
 import (
 	"errors"
-	"reflect"
 	"testing"
 
-	corev2 "github.com/sensu/core/v2"
 	"github.com/sensu/sensu-go/bonsai"
 	cliClient "github.com/sensu/sensu-go/cli/client/testing"
 	test "github.com/sensu/sensu-go/cli/commands/testing"
@@ -23,9 +21,8 @@ func TestOutdatedCommand(t *testing.T) {
 	config := cli.Config.(*cliClient.MockConfig)
 	config.On("Format").Return("none")
 
-	assets := []corev2.Asset{}
 	client := cli.Client.(*cliClient.MockClient)
-	client.On("List", mock.Anything, &assets, mock.Anything, mock.Anything).Return(nil)
+	client.On("ListOutdatedAssets", "default", mock.Anything).Return([]bonsai.OutdatedAsset{}, nil)
 
 	cmd := OutdatedCommand(cli)
 	out, err := test.RunCmd(cmd, []string{})
@@ -35,248 +32,45 @@ func TestOutdatedCommand(t *testing.T) {
 	assert.Nil(err)
 }
 
-type mockedBonsaiClient struct {
-	mock.Mock
-}
+func TestOutdatedCommandList(t *testing.T) {
+	cli := test.NewMockCLI()
 
-func (c *mockedBonsaiClient) FetchAsset(namespace, name string) (*bonsai.Asset, error) {
-	args := c.Called(namespace, name)
-	return args.Get(0).(*bonsai.Asset), args.Error(1)
-}
+	config := cli.Config.(*cliClient.MockConfig)
+	config.On("Format").Return("none")
 
-func (c *mockedBonsaiClient) FetchAssetVersion(namespace, name, version string) (string, error) {
-	args := c.Called(namespace, name)
-	return args.String(0), args.Error(1)
+	outdated := []bonsai.OutdatedAsset{
+		{BonsaiName: "testasset", BonsaiNamespace: "sensu", AssetName: "foo", CurrentVersion: "0.1.0", LatestVersion: "0.2.0"},
+		{BonsaiName: "otherasset", BonsaiNamespace: "sensu", AssetName: "bar", CurrentVersion: "1.0.0", LatestVersion: "1.1.0"},
+	}
+	client := cli.Client.(*cliClient.MockClient)
+	client.On("ListOutdatedAssets", "default", mock.Anything).Return(outdated, nil)
+
+	out, err := test.RunCmd(OutdatedCommand(cli), []string{})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "foo")
+	assert.Contains(t, out, "sensu/otherasset")
 }
 
-func Test_outdatedAssets(t *testing.T) {
-	type clientFunc func(*mockedBonsaiClient)
+func TestOutdatedCommandError(t *testing.T) {
+	cli := test.NewMockCLI()
 
-	bonsaiAsset := corev2.Asset{
-		ObjectMeta: corev2.ObjectMeta{
-			Name: "foo",
-			Annotations: map[string]string{
-				bonsai.URLAnnotation:       "http://127.0.0.1",
-				bonsai.VersionAnnotation:   "0.1.0",
-				bonsai.NamespaceAnnotation: "sensu",
-				bonsai.NameAnnotation:      "testasset",
-			},
-		},
-	}
+	client := cli.Client.(*cliClient.MockClient)
+	client.On("ListOutdatedAssets", "default", mock.Anything).Return([]bonsai.OutdatedAsset(nil), errors.New("error"))
 
-	tests := []struct {
-		name       string
-		assets     []corev2.Asset
-		clientFunc clientFunc
-		want       []bonsai.OutdatedAsset
-		wantErr    bool
-	}{
-		{
-			name: "asset without bonsai API URL annotation is ignored",
-			assets: []corev2.Asset{
-				*corev2.FixtureAsset("foo"),
-			},
-			want: []bonsai.OutdatedAsset{},
-		},
-		{
-			name: "asset without bonsai version returns an error",
-			assets: []corev2.Asset{
-				corev2.Asset{
-					ObjectMeta: corev2.ObjectMeta{
-						Name: "foo",
-						Annotations: map[string]string{
-							bonsai.URLAnnotation: "http://127.0.0.1",
-						},
-					},
-				},
-			},
-			wantErr: true,
-		},
-		{
-			name: "asset without bonsai namespace returns an error",
-			assets: []corev2.Asset{
-				corev2.Asset{
-					ObjectMeta: corev2.ObjectMeta{
-						Name: "foo",
-						Annotations: map[string]string{
-							bonsai.URLAnnotation:     "http://127.0.0.1",
-							bonsai.VersionAnnotation: "0.1.0",
-						},
-					},
-				},
-			},
-			wantErr: true,
-		},
-		{
-			name: "asset without bonsai name returns an error",
-			assets: []corev2.Asset{
-				corev2.Asset{
-					ObjectMeta: corev2.ObjectMeta{
-						Name: "foo",
-						Annotations: map[string]string{
-							bonsai.URLAnnotation:       "http://127.0.0.1",
-							bonsai.VersionAnnotation:   "0.1.0",
-							bonsai.NamespaceAnnotation: "sensu",
-						},
-					},
-				},
-			},
-			wantErr: true,
-		},
-		{
-			name: "asset without bonsai name returns an error",
-			assets: []corev2.Asset{
-				corev2.Asset{
-					ObjectMeta: corev2.ObjectMeta{
-						Name: "foo",
-						Annotations: map[string]string{
-							bonsai.URLAnnotation:       "http://127.0.0.1",
-							bonsai.VersionAnnotation:   "0.1.0",
-							bonsai.NamespaceAnnotation: "sensu",
-						},
-					},
-				},
-			},
-			wantErr: true,
-		},
-		{
-			name: "asset with an invalid version returns an error",
-			assets: []corev2.Asset{
-				corev2.Asset{
-					ObjectMeta: corev2.ObjectMeta{
-						Name: "foo",
-						Annotations: map[string]string{
-							bonsai.URLAnnotation:       "http://127.0.0.1",
-							bonsai.VersionAnnotation:   "invalid",
-							bonsai.NamespaceAnnotation: "sensu",
-							bonsai.NameAnnotation:      "testasset",
-						},
-					},
-				},
-			},
-			wantErr: true,
-		},
-		{
-			name:   "bonsai client error",
-			assets: []corev2.Asset{bonsaiAsset},
-			clientFunc: func(c *mockedBonsaiClient) {
-				c.On("FetchAsset", "sensu", "testasset").
-					Return(&bonsai.Asset{}, errors.New("error"))
-			},
-			wantErr: true,
-		},
-		{
-			name:   "invalid asset version in fetched asset",
-			assets: []corev2.Asset{bonsaiAsset},
-			clientFunc: func(c *mockedBonsaiClient) {
-				c.On("FetchAsset", "sensu", "testasset").
-					Return(
-						&bonsai.Asset{Versions: []*bonsai.AssetVersionGrouping{&bonsai.AssetVersionGrouping{Version: "invalid"}}},
-						nil,
-					)
-			},
-			wantErr: true,
-		},
-		{
-			name:   "up-to-date assset is not marked as outdated",
-			assets: []corev2.Asset{bonsaiAsset},
-			clientFunc: func(c *mockedBonsaiClient) {
-				c.On("FetchAsset", "sensu", "testasset").
-					Return(
-						&bonsai.Asset{Versions: []*bonsai.AssetVersionGrouping{&bonsai.AssetVersionGrouping{Version: "0.1.0"}}},
-						nil,
-					)
-			},
-			want: []bonsai.OutdatedAsset{},
-		},
-		{
-			name:   "older asset is marked as outdated",
-			assets: []corev2.Asset{bonsaiAsset},
-			clientFunc: func(c *mockedBonsaiClient) {
-				c.On("FetchAsset", "sensu", "testasset").
-					Return(
-						&bonsai.Asset{Versions: []*bonsai.AssetVersionGrouping{&bonsai.AssetVersionGrouping{Version: "0.2.0"}}},
-						nil,
-					)
-			},
-			want: []bonsai.OutdatedAsset{
-				bonsai.OutdatedAsset{
-					BonsaiName:      "testasset",
-					BonsaiNamespace: "sensu",
-					AssetName:       "foo",
-					CurrentVersion:  "0.1.0",
-					LatestVersion:   "0.2.0",
-				},
-			},
-		},
-		{
-			name:   "new prefixed version in Bonsai is still considered",
-			assets: []corev2.Asset{bonsaiAsset},
-			clientFunc: func(c *mockedBonsaiClient) {
-				c.On("FetchAsset", "sensu", "testasset").
-					Return(
-						&bonsai.Asset{Versions: []*bonsai.AssetVersionGrouping{&bonsai.AssetVersionGrouping{Version: "v0.2.0"}}},
-						nil,
-					)
-			},
-			want: []bonsai.OutdatedAsset{
-				bonsai.OutdatedAsset{
-					BonsaiName:      "testasset",
-					BonsaiNamespace: "sensu",
-					AssetName:       "foo",
-					CurrentVersion:  "0.1.0",
-					LatestVersion:   "v0.2.0",
-				},
-			},
-		},
-		{
-			name: "local prefixed assets are still considered outdated with new Bonsai version that's not prefixed",
-			assets: []corev2.Asset{
-				corev2.Asset{
-					ObjectMeta: corev2.ObjectMeta{
-						Name: "foo",
-						Annotations: map[string]string{
-							bonsai.URLAnnotation:       "http://127.0.0.1",
-							bonsai.VersionAnnotation:   "v0.1.0",
-							bonsai.NamespaceAnnotation: "sensu",
-							bonsai.NameAnnotation:      "testasset",
-						},
-					},
-				},
-			},
-			clientFunc: func(c *mockedBonsaiClient) {
-				c.On("FetchAsset", "sensu", "testasset").
-					Return(
-						&bonsai.Asset{Versions: []*bonsai.AssetVersionGrouping{&bonsai.AssetVersionGrouping{Version: "0.2.0"}}},
-						nil,
-					)
-			},
-			want: []bonsai.OutdatedAsset{
-				bonsai.OutdatedAsset{
-					BonsaiName:      "testasset",
-					BonsaiNamespace: "sensu",
-					AssetName:       "foo",
-					CurrentVersion:  "v0.1.0",
-					LatestVersion:   "0.2.0",
-				},
-			},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			client := &mockedBonsaiClient{}
-			if tt.clientFunc != nil {
-				tt.clientFunc(client)
-			}
+	_, err := test.RunCmd(OutdatedCommand(cli), []string{})
+	assert.Error(t, err)
+}
 
-			got, err := outdatedAssets(tt.assets, client)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("outdatedAssets() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("outdatedAssets() = %#v, want %#v", got, tt.want)
-			}
-		})
+func TestOutdatedCommandUpgrade(t *testing.T) {
+	cli := test.NewMockCLI()
+
+	upgraded := []bonsai.OutdatedAsset{
+		{BonsaiName: "testasset", BonsaiNamespace: "sensu", AssetName: "foo", AssetNamespace: "default", CurrentVersion: "0.1.0", LatestVersion: "0.2.0"},
 	}
+	client := cli.Client.(*cliClient.MockClient)
+	client.On("UpgradeOutdatedAssets", "default", mock.Anything).Return(upgraded, nil)
+
+	out, err := test.RunCmd(OutdatedCommand(cli), []string{"--upgrade"})
+	assert.NoError(t, err)
+	assert.Contains(t, out, "upgraded asset foo: 0.1.0 -> 0.2.0")
 }
@@ -104,8 +104,6 @@ func CreateCommand(cli *cli.SensuCli) *cobra.Command {
 		"optional resource names that the rule applies to",
 	)
 
-	cmd.Flags().MarkDeprecated("resource", "please use resources instead.")
-	cmd.Flags().MarkDeprecated("verb", "please use verbs instead.")
 	cmd.Flags().MarkHidden("resource")
 	cmd.Flags().MarkHidden("verb")
 
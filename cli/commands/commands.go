@@ -2,6 +2,7 @@ package commands
 
 import (
 	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/commands/agent"
 	"github.com/sensu/sensu-go/cli/commands/apikey"
 	"github.com/sensu/sensu-go/cli/commands/asset"
 	"github.com/sensu/sensu-go/cli/commands/check"
@@ -26,11 +27,14 @@ import (
 	"github.com/sensu/sensu-go/cli/commands/mutator"
 	"github.com/sensu/sensu-go/cli/commands/namespace"
 	"github.com/sensu/sensu-go/cli/commands/pipeline"
+	"github.com/sensu/sensu-go/cli/commands/prunereport"
+	"github.com/sensu/sensu-go/cli/commands/readonly"
 	"github.com/sensu/sensu-go/cli/commands/role"
 	"github.com/sensu/sensu-go/cli/commands/rolebinding"
 	"github.com/sensu/sensu-go/cli/commands/silenced"
 	"github.com/sensu/sensu-go/cli/commands/tessen"
 	"github.com/sensu/sensu-go/cli/commands/user"
+	"github.com/sensu/sensu-go/util/deprecation"
 	"github.com/spf13/cobra"
 )
 
@@ -43,6 +47,7 @@ func AddCommands(rootCmd *cobra.Command, cli *cli.SensuCli) {
 		logout.Command(cli),
 
 		// Management Commands
+		agent.HelpCommand(cli),
 		asset.HelpCommand(cli),
 		apikey.HelpCommand(cli),
 		check.HelpCommand(cli),
@@ -68,9 +73,28 @@ func AddCommands(rootCmd *cobra.Command, cli *cli.SensuCli) {
 		dump.Command(cli),
 		command.HelpCommand(cli),
 		describetype.Command(cli),
+		prunereport.Command(cli),
+		readonly.HelpCommand(cli),
 	)
 
 	for _, cmd := range rootCmd.Commands() {
 		rootCmd.ValidArgs = append(rootCmd.ValidArgs, cmd.Name())
 	}
+
+	markDeprecatedFlags(rootCmd)
+}
+
+// markDeprecatedFlags marks the flags of the deprecation registry as
+// deprecated, in the commands of cmd.
+func markDeprecatedFlags(cmd *cobra.Command) {
+	for _, d := range deprecation.List(deprecation.KindFlag) {
+		command, flag := d.Flag()
+		if command != cmd.CommandPath() || cmd.Flags().Lookup(flag) == nil {
+			continue
+		}
+		_ = cmd.Flags().MarkDeprecated(flag, d.Message)
+	}
+	for _, subcommand := range cmd.Commands() {
+		markDeprecatedFlags(subcommand)
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/sensu/sensu-go/util/deprecation"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 )
@@ -16,3 +17,33 @@ func TestAddCommands(t *testing.T) {
 	AddCommands(cmd, cli)
 	assert.NotEmpty(cmd.Commands())
 }
+
+func TestAddCommandsDeprecatedFlags(t *testing.T) {
+	cmd := &cobra.Command{Use: "sensuctl"}
+	cli := test.SimpleSensuCLI(nil)
+
+	AddCommands(cmd, cli)
+	for _, d := range deprecation.List(deprecation.KindFlag) {
+		command, flag := d.Flag()
+		found := false
+		for _, subcommand := range subcommands(cmd) {
+			if subcommand.CommandPath() != command {
+				continue
+			}
+			found = true
+			if f := subcommand.Flags().Lookup(flag); assert.NotNil(t, f, d.Name) {
+				assert.Equal(t, d.Message, f.Deprecated)
+			}
+		}
+		assert.True(t, found, d.Name)
+	}
+}
+
+func subcommands(cmd *cobra.Command) []*cobra.Command {
+	var commands []*cobra.Command
+	for _, subcommand := range cmd.Commands() {
+		commands = append(commands, subcommand)
+		commands = append(commands, subcommands(subcommand)...)
+	}
+	return commands
+}
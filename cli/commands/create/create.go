@@ -12,12 +12,13 @@ import (
 // CreateCommand creates generic Sensu resources.
 func CreateCommand(cli *cli.SensuCli) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "create [-r] [[-f URL] ... ]",
-		Short: "Create or replace resources from file or URL (path, file://, http[s]://), or STDIN otherwise.",
-		RunE:  execute(cli),
+		Use:     "create [-r] [[-f URL] ... ]",
+		Aliases: []string{"apply"},
+		Short:   "Create or replace resources from file or URL (path, file://, http[s]://, - for STDIN), or STDIN otherwise.",
+		RunE:    execute(cli),
 	}
 
-	_ = cmd.Flags().StringSliceP("file", "f", nil, "Files, directories, or URLs to create resources from")
+	_ = cmd.Flags().StringSliceP("file", "f", nil, "Files, directories, URLs, or - for STDIN to create resources from")
 	_ = cmd.Flags().BoolP("recursive", "r", false, "Follow subdirectories")
 
 	return cmd
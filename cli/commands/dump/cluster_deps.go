@@ -0,0 +1,60 @@
+package dump
+
+import (
+	"fmt"
+	"sort"
+
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/cli/client"
+)
+
+// isGlobal returns true if r is a cluster-wide resource.
+func isGlobal(r corev3.Resource) bool {
+	gr, ok := r.(corev3.GlobalResource)
+	return ok && gr.IsGlobalResource()
+}
+
+// clusterDeps returns the cluster-wide resources that the resources of
+// namespace depend on, so that they can be imported in another cluster: the
+// namespace itself, then the cluster roles bound by its role bindings.
+func clusterDeps(c client.APIClient, namespace string) ([]corev3.Resource, error) {
+	ns, err := c.FetchNamespace(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching namespace %q: %s", namespace, err)
+	}
+	deps := []corev3.Resource{ns}
+
+	var bindings []corev2.RoleBinding
+	err = c.List(client.RoleBindingsPath(namespace), &bindings, &client.ListOptions{
+		ChunkSize: ChunkSize,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error listing the role bindings of namespace %q: %s", namespace, err)
+	}
+	names := map[string]struct{}{}
+	for _, binding := range bindings {
+		if binding.RoleRef.Type == "ClusterRole" {
+			names[binding.RoleRef.Name] = struct{}{}
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		role, err := c.FetchClusterRole(name)
+		if err != nil {
+			// Role bindings can refer to cluster roles that don't exist
+			if err, ok := err.(client.APIError); ok && actions.ErrCode(err.Code) == actions.NotFound {
+				continue
+			}
+			return nil, fmt.Errorf("error fetching cluster role %q: %s", name, err)
+		}
+		deps = append(deps, role)
+	}
+	return deps, nil
+}
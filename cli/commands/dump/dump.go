@@ -36,6 +36,12 @@ $ sensuctl dump core/v2.CheckConfig,core/v2.Entity
 
 You can also use the 'all' qualifier to dump all supported resources:
 $ sensuctl dump all
+
+To export a namespace with the cluster-wide resources it depends on, so that
+it can be imported in another cluster, use --include-cluster-deps. The
+namespace and the cluster roles bound by its role bindings are dumped first,
+and the other cluster-wide resources are omitted:
+$ sensuctl dump all --namespace production --include-cluster-deps
 `
 
 // Command dumps generic Sensu resources to a file or STDOUT.
@@ -55,8 +61,8 @@ func Command(cli *cli.SensuCli) *cobra.Command {
 	_ = cmd.Flags().String("format", format, fmt.Sprintf(`format of data returned ("%s"|"%s")`, config.FormatJSON, config.FormatYAML))
 	_ = cmd.Flags().StringP("file", "f", "", "file to dump resources to")
 	_ = cmd.Flags().BoolP("types", "t", false, "list supported resource types")
-	_ = cmd.Flags().MarkDeprecated("types", `please use "sensuctl describe-type all" instead`)
 	_ = cmd.Flags().StringP("omit", "o", "", "when using 'sensuctl dump all', omit can be used to exclude types from being dumped")
+	_ = cmd.Flags().Bool("include-cluster-deps", false, "include the namespace and the cluster-wide resources it depends on, omitting the other cluster-wide resources")
 
 	return cmd
 }
@@ -133,6 +139,25 @@ func execute(cli *cli.SensuCli) func(*cobra.Command, []string) error {
 
 		requests = resource.TrimResources(requests, omitRequests)
 
+		allNamespaces, err := cmd.Flags().GetBool(flags.AllNamespaces)
+		if err != nil {
+			return err
+		}
+		includeDeps, err := cmd.Flags().GetBool("include-cluster-deps")
+		if err != nil {
+			return err
+		}
+		var deps []corev3.Resource
+		if includeDeps {
+			if allNamespaces {
+				return errors.New("--include-cluster-deps is mutually exclusive with --all-namespaces")
+			}
+			deps, err = clusterDeps(cli.Client, cli.Config.Namespace())
+			if err != nil {
+				return err
+			}
+		}
+
 		var w io.Writer = cmd.OutOrStdout()
 
 		// if a file is requested, write data to that
@@ -149,13 +174,25 @@ func execute(cli *cli.SensuCli) func(*cobra.Command, []string) error {
 			w = f
 		}
 
-		for i, req := range requests {
-			// set the namespaces on the requests
-			ok, err := cmd.Flags().GetBool(flags.AllNamespaces)
+		if len(deps) > 0 {
+			switch format {
+			case config.FormatJSON:
+				err = helpers.PrintResourceListJSON(deps, w)
+			default:
+				err = helpers.PrintYAML(deps, w)
+			}
 			if err != nil {
 				return err
 			}
-			if ok {
+		}
+
+		for i, req := range requests {
+			// the cluster-wide resources are replaced by the dependencies
+			if includeDeps && isGlobal(req) {
+				continue
+			}
+			// set the namespaces on the requests
+			if allNamespaces {
 				req.GetMetadata().Namespace = corev2.NamespaceTypeAll
 			} else {
 				req.GetMetadata().Namespace = cli.Config.Namespace()
@@ -194,7 +231,7 @@ func execute(cli *cli.SensuCli) func(*cobra.Command, []string) error {
 			case config.FormatJSON:
 				err = helpers.PrintResourceListJSON(resources, w)
 			case config.FormatYAML:
-				if i > 0 {
+				if i > 0 || len(deps) > 0 {
 					_, _ = fmt.Fprintln(w, "---")
 				}
 				err = helpers.PrintYAML(resources, w)
@@ -3,8 +3,15 @@ package dump
 import (
 	"testing"
 
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	sensuclient "github.com/sensu/sensu-go/cli/client"
+	clientmock "github.com/sensu/sensu-go/cli/client/testing"
 	test "github.com/sensu/sensu-go/cli/commands/testing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCommand(t *testing.T) {
@@ -49,3 +56,35 @@ func TestListFlags(t *testing.T) {
 	flag = cmd.Flag("file")
 	assert.NotNil(flag)
 }
+
+func TestCommandIncludeClusterDeps(t *testing.T) {
+	cli := test.NewCLI()
+	client := cli.Client.(*clientmock.MockClient)
+	client.On("FetchNamespace", "default").Return(corev3.FixtureNamespace("default"), nil)
+	client.On("List", "/api/core/v2/namespaces/default/rolebindings", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Run(func(args mock.Arguments) {
+		bindings := args.Get(1).(*[]corev2.RoleBinding)
+		*bindings = []corev2.RoleBinding{
+			*corev2.FixtureRoleBinding("admins", "default"),
+			{RoleRef: corev2.RoleRef{Type: "ClusterRole", Name: "namespace-admin"}},
+			{RoleRef: corev2.RoleRef{Type: "ClusterRole", Name: "deleted"}},
+		}
+	})
+	client.On("FetchClusterRole", "namespace-admin").Return(corev2.FixtureClusterRole("namespace-admin"), nil)
+	client.On("FetchClusterRole", "deleted").Return((*corev2.ClusterRole)(nil), sensuclient.APIError{Code: uint32(actions.NotFound)})
+	client.On("List", "/api/core/v2/namespaces/default/checks?types=CheckConfig", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	cmd := Command(cli)
+	require.NoError(t, cmd.Flags().Set("include-cluster-deps", "true"))
+	out, err := test.RunCmd(cmd, []string{"core/v2.ClusterRoleBinding,core/v2.CheckConfig"})
+	require.NoError(t, err)
+	assert.Contains(t, out, `"Namespace"`)
+	assert.Contains(t, out, `"namespace-admin"`)
+	assert.NotContains(t, out, `"deleted"`)
+	// The cluster role bindings are omitted
+	client.AssertNumberOfCalls(t, "List", 2)
+
+	require.NoError(t, cmd.Flags().Set("all-namespaces", "true"))
+	_, err = test.RunCmd(cmd, []string{"all"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package event
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sensu/sensu-go/cli/client"
+	"github.com/sensu/sensu-go/cli/commands/helpers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const allFromCheckFlag = "all-from-check"
+
+// addBulkFlags adds the flags that select the events of a bulk operation.
+func addBulkFlags(flagSet *pflag.FlagSet) {
+	helpers.AddLabelSelectorFlag(flagSet)
+	helpers.AddFieldSelectorFlag(flagSet)
+	flagSet.String(allFromCheckFlag, "", "select the events of every entity for the given check")
+}
+
+// bulkListOptions returns the selectors of a bulk operation, or nil if no
+// selector was given.
+func bulkListOptions(cmd *cobra.Command) (*client.ListOptions, error) {
+	opts, err := helpers.ListOptionsFromFlags(cmd.Flags())
+	if err != nil {
+		return nil, err
+	}
+	check, err := cmd.Flags().GetString(allFromCheckFlag)
+	if err != nil {
+		return nil, err
+	}
+	if check != "" {
+		selector := "event.check.name == " + strconv.Quote(check)
+		if opts.FieldSelector != "" {
+			selector = opts.FieldSelector + " && " + selector
+		}
+		opts.FieldSelector = selector
+	}
+	if opts.FieldSelector == "" && opts.LabelSelector == "" {
+		return nil, nil
+	}
+	return &opts, nil
+}
+
+// bulkSelectorDescription describes the selectors of a bulk operation.
+func bulkSelectorDescription(opts *client.ListOptions) string {
+	var selectors []string
+	for _, selector := range []string{opts.LabelSelector, opts.FieldSelector} {
+		if selector != "" {
+			selectors = append(selectors, selector)
+		}
+	}
+	return strings.Join(selectors, " && ")
+}
+
+// printBulkResult prints the events affected by a bulk operation, and returns
+// an error if the operation failed for some events.
+func printBulkResult(w io.Writer, op string, result *client.BulkEventResult) error {
+	for _, event := range result.Events {
+		fmt.Fprintf(w, "%s %s\n", op, event)
+	}
+	for _, err := range result.Errors {
+		fmt.Fprintf(w, "Error: %s\n", err)
+	}
+	if len(result.Events) == 0 && len(result.Errors) == 0 {
+		fmt.Fprintln(w, "No matching events")
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d event(s) could not be %s", len(result.Errors), strings.ToLower(op))
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/client"
 	"github.com/sensu/sensu-go/cli/commands/helpers"
 	"github.com/spf13/cobra"
 )
@@ -16,6 +17,14 @@ func DeleteCommand(cli *cli.SensuCli) *cobra.Command {
 		Short:        "delete events",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := bulkListOptions(cmd)
+			if err != nil {
+				return err
+			}
+			if opts != nil && len(args) == 0 {
+				return bulkDelete(cli, cmd, opts)
+			}
+
 			if len(args) != 2 {
 				_ = cmd.Help()
 				return errors.New("invalid argument(s) received")
@@ -33,7 +42,7 @@ func DeleteCommand(cli *cli.SensuCli) *cobra.Command {
 				}
 			}
 
-			err := cli.Client.DeleteEvent(namespace, entity, check)
+			err = cli.Client.DeleteEvent(namespace, entity, check)
 			if err != nil {
 				return err
 			}
@@ -44,6 +53,24 @@ func DeleteCommand(cli *cli.SensuCli) *cobra.Command {
 	}
 
 	_ = cmd.Flags().Bool("skip-confirm", false, "skip interactive confirmation prompt")
+	addBulkFlags(cmd.Flags())
 
 	return cmd
 }
+
+// bulkDelete deletes the events that match the selectors of opts
+func bulkDelete(cli *cli.SensuCli, cmd *cobra.Command, opts *client.ListOptions) error {
+	if skipConfirm, _ := cmd.Flags().GetBool("skip-confirm"); !skipConfirm {
+		confirm := &helpers.ConfirmDestructiveOp{Type: "the events matching", Op: "delete"}
+		if confirmed, _ := confirm.Ask(bulkSelectorDescription(opts)); !confirmed {
+			fmt.Fprintln(cmd.OutOrStdout(), "Canceled")
+			return nil
+		}
+	}
+
+	result, err := cli.Client.BulkDeleteEvents(cli.Config.Namespace(), opts)
+	if err != nil {
+		return err
+	}
+	return printBulkResult(cmd.OutOrStdout(), "Deleted", result)
+}
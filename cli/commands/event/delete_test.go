@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	apiclient "github.com/sensu/sensu-go/cli/client"
 	client "github.com/sensu/sensu-go/cli/client/testing"
 	test "github.com/sensu/sensu-go/cli/commands/testing"
 	"github.com/stretchr/testify/assert"
@@ -71,3 +72,35 @@ func TestDeleteCommandRunEFailConfirm(t *testing.T) {
 	assert.Contains(out, "Canceled")
 	assert.NoError(err)
 }
+
+func TestDeleteCommandBulk(t *testing.T) {
+	cli := test.NewMockCLI()
+	opts := &apiclient.ListOptions{FieldSelector: `event.check.name == "check_foo"`}
+	cli.Client.(*client.MockClient).
+		On("BulkDeleteEvents", mock.Anything, opts).
+		Return(&apiclient.BulkEventResult{Events: []string{"foo/check_foo", "bar/check_foo"}}, nil)
+
+	cmd := DeleteCommand(cli)
+	require.NoError(t, cmd.Flags().Set("skip-confirm", "t"))
+	require.NoError(t, cmd.Flags().Set("all-from-check", "check_foo"))
+	out, err := test.RunCmd(cmd, []string{})
+
+	require.NoError(t, err)
+	assert.Contains(t, out, "Deleted foo/check_foo")
+	assert.Contains(t, out, "Deleted bar/check_foo")
+}
+
+func TestDeleteCommandBulkWithErrors(t *testing.T) {
+	cli := test.NewMockCLI()
+	cli.Client.(*client.MockClient).
+		On("BulkDeleteEvents", mock.Anything, mock.Anything).
+		Return(&apiclient.BulkEventResult{Errors: []string{"foo/check_foo: error"}}, nil)
+
+	cmd := DeleteCommand(cli)
+	require.NoError(t, cmd.Flags().Set("skip-confirm", "t"))
+	require.NoError(t, cmd.Flags().Set("label-selector", "region == us-west-1"))
+	out, err := test.RunCmd(cmd, []string{})
+
+	require.Error(t, err)
+	assert.Contains(t, out, "foo/check_foo: error")
+}
@@ -12,9 +12,22 @@ import (
 func ResolveCommand(cli *cli.SensuCli) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:          "resolve [ENTITY] [CHECK]",
-		Short:        "manually resolves an event",
+		Short:        "manually resolves an event, or the events matching selectors",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := bulkListOptions(cmd)
+			if err != nil {
+				return err
+			}
+			if opts != nil && len(args) == 0 {
+				// Resolve the events that match the selectors via api
+				result, err := cli.Client.BulkResolveEvents(cli.Config.Namespace(), opts)
+				if err != nil {
+					return err
+				}
+				return printBulkResult(cmd.OutOrStdout(), "Resolved", result)
+			}
+
 			if len(args) != 2 {
 				_ = cmd.Help()
 				return errors.New("invalid argument(s) received")
@@ -38,5 +51,7 @@ func ResolveCommand(cli *cli.SensuCli) *cobra.Command {
 		},
 	}
 
+	addBulkFlags(cmd.Flags())
+
 	return cmd
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	v2 "github.com/sensu/core/v2"
+	apiclient "github.com/sensu/sensu-go/cli/client"
 	client "github.com/sensu/sensu-go/cli/client/testing"
 	test "github.com/sensu/sensu-go/cli/commands/testing"
 	"github.com/stretchr/testify/assert"
@@ -63,3 +64,23 @@ func TestResolveCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveCommandBulk(t *testing.T) {
+	cli := test.NewMockCLI()
+	opts := &apiclient.ListOptions{
+		LabelSelector: "region == us-west-1",
+		FieldSelector: `event.entity.name == foo && event.check.name == "bar"`,
+	}
+	client := cli.Client.(*client.MockClient)
+	client.On("BulkResolveEvents", mock.Anything, opts).
+		Return(&apiclient.BulkEventResult{Events: []string{"foo/bar"}}, nil)
+
+	cmd := ResolveCommand(cli)
+	assert.NoError(t, cmd.Flags().Set("label-selector", "region == us-west-1"))
+	assert.NoError(t, cmd.Flags().Set("field-selector", "event.entity.name == foo"))
+	assert.NoError(t, cmd.Flags().Set("all-from-check", "bar"))
+	out, err := test.RunCmd(cmd, []string{})
+
+	assert.NoError(t, err)
+	assert.Regexp(t, "Resolved foo/bar", out)
+}
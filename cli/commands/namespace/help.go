@@ -19,6 +19,8 @@ func HelpCommand(cli *cli.SensuCli) *cobra.Command {
 		CreateCommand(cli),
 		DeleteCommand(cli),
 		ListCommand(cli),
+		PauseSchedulingCommand(cli),
+		ResumeSchedulingCommand(cli),
 	)
 
 	return cmd
@@ -0,0 +1,90 @@
+package namespace
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sensu/sensu-go/backend/store"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/spf13/cobra"
+)
+
+const flagSuppressKeepaliveAlerts = "suppress-keepalive-alerts"
+
+// PauseSchedulingCommand adds a command that allows users to stop the
+// scheduling of the checks of a namespace
+func PauseSchedulingCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "pause-scheduling [NAME]",
+		Short:        "stop scheduling the checks of a namespace",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+
+			suppress, err := cmd.Flags().GetBool(flagSuppressKeepaliveAlerts)
+			if err != nil {
+				return err
+			}
+
+			namespace, err := cli.Client.FetchNamespace(args[0])
+			if err != nil {
+				return err
+			}
+			if namespace.Metadata.Annotations == nil {
+				namespace.Metadata.Annotations = make(map[string]string)
+			}
+			namespace.Metadata.Annotations[store.SchedulingPausedAnnotation] = "true"
+			if suppress {
+				namespace.Metadata.Annotations[store.SuppressKeepaliveAlertsAnnotation] = "true"
+			} else {
+				delete(namespace.Metadata.Annotations, store.SuppressKeepaliveAlertsAnnotation)
+			}
+
+			if err := cli.Client.UpdateNamespace(namespace); err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), "Paused")
+			return err
+		},
+	}
+
+	_ = cmd.Flags().Bool(flagSuppressKeepaliveAlerts, false, "do not raise keepalive alerts while scheduling is paused")
+
+	return cmd
+}
+
+// ResumeSchedulingCommand adds a command that allows users to resume the
+// scheduling of the checks of a namespace
+func ResumeSchedulingCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "resume-scheduling [NAME]",
+		Short:        "resume scheduling the checks of a namespace",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+
+			namespace, err := cli.Client.FetchNamespace(args[0])
+			if err != nil {
+				return err
+			}
+			delete(namespace.Metadata.Annotations, store.SchedulingPausedAnnotation)
+			delete(namespace.Metadata.Annotations, store.SuppressKeepaliveAlertsAnnotation)
+
+			if err := cli.Client.UpdateNamespace(namespace); err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), "Resumed")
+			return err
+		},
+	}
+
+	return cmd
+}
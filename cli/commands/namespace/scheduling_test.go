@@ -0,0 +1,71 @@
+package namespace
+
+import (
+	"errors"
+	"testing"
+
+	corev3 "github.com/sensu/core/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sensu/sensu-go/backend/store"
+	client "github.com/sensu/sensu-go/cli/client/testing"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+)
+
+func TestPauseSchedulingCommand(t *testing.T) {
+	cli := test.NewMockCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("FetchNamespace", "acme").Return(corev3.FixtureNamespace("acme"), nil)
+	client.On("UpdateNamespace", mock.MatchedBy(func(namespace *corev3.Namespace) bool {
+		return store.KeepaliveAlertsSuppressed(namespace)
+	})).Return(nil)
+
+	cmd := PauseSchedulingCommand(cli)
+	require.NoError(t, cmd.Flags().Set(flagSuppressKeepaliveAlerts, "true"))
+	out, err := test.RunCmd(cmd, []string{"acme"})
+
+	assert.NoError(t, err)
+	assert.Regexp(t, "Paused", out)
+}
+
+func TestPauseSchedulingCommandWithoutName(t *testing.T) {
+	cli := test.NewMockCLI()
+	cmd := PauseSchedulingCommand(cli)
+	out, err := test.RunCmd(cmd, []string{})
+
+	assert.Regexp(t, "Usage", out)
+	assert.Error(t, err)
+}
+
+func TestResumeSchedulingCommand(t *testing.T) {
+	namespace := corev3.FixtureNamespace("acme")
+	namespace.Metadata.Annotations = map[string]string{
+		store.SchedulingPausedAnnotation: "true",
+	}
+
+	cli := test.NewMockCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("FetchNamespace", "acme").Return(namespace, nil)
+	client.On("UpdateNamespace", mock.MatchedBy(func(namespace *corev3.Namespace) bool {
+		return !store.SchedulingPaused(namespace)
+	})).Return(nil)
+
+	cmd := ResumeSchedulingCommand(cli)
+	out, err := test.RunCmd(cmd, []string{"acme"})
+
+	assert.NoError(t, err)
+	assert.Regexp(t, "Resumed", out)
+}
+
+func TestResumeSchedulingCommandWithServerErr(t *testing.T) {
+	cli := test.NewMockCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("FetchNamespace", "acme").Return((*corev3.Namespace)(nil), errors.New("oh noes"))
+
+	cmd := ResumeSchedulingCommand(cli)
+	_, err := test.RunCmd(cmd, []string{"acme"})
+
+	assert.Error(t, err)
+}
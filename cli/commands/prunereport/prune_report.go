@@ -0,0 +1,85 @@
+package prunereport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/commands/helpers"
+	"github.com/sensu/sensu-go/cli/elements/list"
+	"github.com/spf13/cobra"
+)
+
+// Command reports the resources of a namespace that are not used by any
+// other resource
+func Command(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "prune-report",
+		Short:        "report the resources that are not used by any other resource",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+
+			report, err := cli.Client.FetchPruneReport(cli.Config.Namespace())
+			if err != nil {
+				return err
+			}
+
+			// Determine the format to use to output the data
+			flag := helpers.GetChangedStringValueViper("format", cmd.Flags())
+			format := cli.Config.Format()
+			return helpers.PrintFormatted(flag, format, report, cmd.OutOrStdout(), printToList)
+		},
+	}
+
+	helpers.AddFormatFlag(cmd.Flags())
+
+	return cmd
+}
+
+func printToList(v interface{}, writer io.Writer) error {
+	r, ok := v.(*actions.PruneReport)
+	if !ok {
+		return fmt.Errorf("%t is not a prune report", v)
+	}
+	cfg := &list.Config{
+		Title: "Prune Report",
+		Rows: []*list.Row{
+			{
+				Label: "Unused Handlers",
+				Value: joinNames(r.Handlers),
+			},
+			{
+				Label: "Unused Filters",
+				Value: joinNames(r.Filters),
+			},
+			{
+				Label: "Unused Assets",
+				Value: joinNames(r.Assets),
+			},
+			{
+				Label: "Subscriptions Without Checks",
+				Value: joinNames(r.SubscriptionsWithoutChecks),
+			},
+			{
+				Label: "Subscriptions Without Entities",
+				Value: joinNames(r.SubscriptionsWithoutEntities),
+			},
+		},
+	}
+
+	return list.Print(writer, cfg)
+}
+
+func joinNames(names []string) string {
+	if len(names) == 0 {
+		return "None"
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,51 @@
+package prunereport
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	client "github.com/sensu/sensu-go/cli/client/testing"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	cli := test.NewMockCLI()
+	config := cli.Config.(*client.MockConfig)
+	config.On("Format").Return("tabular")
+	client := cli.Client.(*client.MockClient)
+	client.On("FetchPruneReport", "default").Return(&actions.PruneReport{
+		Handlers:                   []string{"slack", "email"},
+		SubscriptionsWithoutChecks: []string{"database"},
+	}, nil)
+
+	cmd := Command(cli)
+	out, err := test.RunCmd(cmd, []string{})
+	require.NoError(t, err)
+
+	assert.Contains(out, "slack, email")
+	assert.Contains(out, "database")
+	assert.Contains(out, "None")
+}
+
+func TestCommandWithArgs(t *testing.T) {
+	cli := test.NewCLI()
+	cmd := Command(cli)
+	out, err := test.RunCmd(cmd, []string{"arg"})
+	require.Error(t, err)
+	assert.Contains(t, out, "Usage")
+}
+
+func TestCommandWithErr(t *testing.T) {
+	cli := test.NewCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("FetchPruneReport", "default").Return((*actions.PruneReport)(nil), errors.New("error"))
+
+	cmd := Command(cli)
+	_, err := test.RunCmd(cmd, []string{})
+	require.Error(t, err)
+}
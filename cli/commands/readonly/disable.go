@@ -0,0 +1,34 @@
+package readonly
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/spf13/cobra"
+)
+
+// DisableCommand puts the API back in read-write mode
+func DisableCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "disable",
+		Short:        "put the API back in read-write mode",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+
+			if err := cli.Client.Put(readOnlyPath, actions.ReadOnlyMode{}); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "The API is no longer in read-only mode")
+			return nil
+		},
+	}
+
+	return cmd
+}
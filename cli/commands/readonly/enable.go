@@ -0,0 +1,38 @@
+package readonly
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/spf13/cobra"
+)
+
+// EnableCommand puts the API in read-only mode
+func EnableCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "enable",
+		Short:        "put the API in read-only mode, rejecting every change but the event submissions",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+
+			reason, _ := cmd.Flags().GetString("reason")
+			mode := actions.ReadOnlyMode{Enabled: true, Reason: reason}
+			if err := cli.Client.Put(readOnlyPath, mode); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "The API is now in read-only mode")
+			return nil
+		},
+	}
+
+	cmd.Flags().String("reason", "", "reason of the read-only mode, given to the rejected requests")
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+package readonly
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	client "github.com/sensu/sensu-go/cli/client/testing"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableCommand(t *testing.T) {
+	testCases := []struct {
+		testName       string
+		args           []string
+		updateResponse error
+		expectedOutput string
+		expectError    bool
+	}{
+		{"args", []string{"foo"}, nil, "Usage", true},
+		{"update error", []string{}, fmt.Errorf("error"), "", true},
+		{"valid input", []string{}, nil, "read-only mode", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			cli := test.NewMockCLI()
+
+			mode := actions.ReadOnlyMode{Enabled: true, Reason: "backup"}
+			client := cli.Client.(*client.MockClient)
+			client.On("Put", readOnlyPath, mode).Return(tc.updateResponse)
+
+			cmd := EnableCommand(cli)
+			require.NoError(t, cmd.Flags().Set("reason", "backup"))
+			out, err := test.RunCmd(cmd, tc.args)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Regexp(t, tc.expectedOutput, out)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package readonly
+
+import (
+	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/commands/helpers"
+	"github.com/spf13/cobra"
+)
+
+// readOnlyPath is the API path of the read-only mode.
+const readOnlyPath = "/api/core/v2/read-only"
+
+// HelpCommand defines new parent
+func HelpCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "read-only",
+		Short: "Manage the read-only mode of the API",
+		RunE:  helpers.DefaultSubCommandRunE,
+	}
+
+	// Add sub-commands
+	cmd.AddCommand(
+		EnableCommand(cli),
+		DisableCommand(cli),
+		InfoCommand(cli),
+	)
+
+	return cmd
+}
@@ -0,0 +1,65 @@
+package readonly
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	"github.com/sensu/sensu-go/cli"
+	"github.com/sensu/sensu-go/cli/commands/helpers"
+	"github.com/sensu/sensu-go/cli/elements/list"
+	"github.com/spf13/cobra"
+)
+
+// InfoCommand shows the read-only mode of the API
+func InfoCommand(cli *cli.SensuCli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "info",
+		Short:        "show the read-only mode of the API",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				_ = cmd.Help()
+				return errors.New("invalid argument(s) received")
+			}
+
+			mode := &actions.ReadOnlyMode{}
+			if err := cli.Client.Get(readOnlyPath, mode); err != nil {
+				return err
+			}
+
+			// Determine the format to use to output the data
+			flag := helpers.GetChangedStringValueViper("format", cmd.Flags())
+			format := cli.Config.Format()
+			return helpers.PrintFormatted(flag, format, mode, cmd.OutOrStdout(), printToList)
+		},
+	}
+
+	helpers.AddFormatFlag(cmd.Flags())
+
+	return cmd
+}
+
+func printToList(v interface{}, writer io.Writer) error {
+	r, ok := v.(*actions.ReadOnlyMode)
+	if !ok {
+		return fmt.Errorf("%t is not a read-only mode", v)
+	}
+	cfg := &list.Config{
+		Title: "Read-Only Mode",
+		Rows: []*list.Row{
+			{
+				Label: "Enabled",
+				Value: strconv.FormatBool(r.Enabled),
+			},
+			{
+				Label: "Reason",
+				Value: r.Reason,
+			},
+		},
+	}
+
+	return list.Print(writer, cfg)
+}
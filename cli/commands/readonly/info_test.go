@@ -0,0 +1,38 @@
+package readonly
+
+import (
+	"testing"
+
+	"github.com/sensu/sensu-go/backend/apid/actions"
+	client "github.com/sensu/sensu-go/cli/client/testing"
+	test "github.com/sensu/sensu-go/cli/commands/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfoCommandRunEClosure(t *testing.T) {
+	cli := test.NewCLI()
+	client := cli.Client.(*client.MockClient)
+	client.On("Get", readOnlyPath, &actions.ReadOnlyMode{}).Return(nil).Run(func(args mock.Arguments) {
+		mode := args.Get(1).(*actions.ReadOnlyMode)
+		*mode = actions.ReadOnlyMode{Enabled: true, Reason: "store migration"}
+	})
+
+	cmd := InfoCommand(cli)
+	require.NoError(t, cmd.Flags().Set("format", "tabular"))
+	out, err := test.RunCmd(cmd, []string{})
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "true")
+	assert.Contains(t, out, "store migration")
+}
+
+func TestInfoCommandWithArgs(t *testing.T) {
+	cli := test.NewCLI()
+	cmd := InfoCommand(cli)
+	out, err := test.RunCmd(cmd, []string{"arg"})
+	require.Error(t, err)
+
+	assert.Contains(t, out, "Usage")
+}
@@ -7,9 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
-	"regexp"
-	"strings"
 
 	"github.com/ghodss/yaml"
 	corev2 "github.com/sensu/core/v2"
@@ -20,68 +19,129 @@ import (
 
 // Parse is a rather heroic function that will parse any number of valid
 // JSON or YAML resources. Since it attempts to be intelligent, it likely
-// contains bugs.
-//
-// The general approach is:
-// 1. detect if the stream is JSON by sniffing the first non-whitespace byte.
-// 2. If the stream is JSON, goto 4.
-// 3. If the stream is YAML, split it on '---' to support multiple yaml documents.
-// 3. Convert the YAML to JSON document-by-document.
-// 4. Unmarshal the JSON one resource at a time.
+// contains bugs. See ParseStream for how the resources are parsed.
 func Parse(in io.Reader) ([]*types.Wrapper, error) {
 	var resources []*types.Wrapper
+	err := ParseStream(in, func(w *types.Wrapper) error {
+		resources = append(resources, w)
+		return nil
+	})
+	return resources, err
+}
+
+// ParseStream parses any number of valid JSON or YAML resources from in, and
+// calls fn with each of them as soon as it is parsed, so that the stream is
+// never loaded in memory as a whole.
+//
+// The general approach is:
+// 1. split the stream on the lines that start with '---', to support multiple
+// documents.
+// 2. detect if a document is JSON by sniffing its first non-whitespace byte.
+// 3. If the document is JSON, goto 5.
+// 4. If the document is YAML, convert it to JSON.
+// 5. Decode the JSON one resource at a time, which supports concatenated and
+// newline-delimited JSON documents.
+//
+// The resources that can't be decoded are reported, and ParseStream returns
+// an error once the stream is parsed, or once there are too many of them.
+func ParseStream(in io.Reader, fn func(*types.Wrapper) error) error {
+	p := &parser{fn: fn}
+	docs := newDocumentReader(in)
+	for docs.next() {
+		if err := p.parseDocument(docs); err != nil {
+			return err
+		}
+	}
+	if docs.err != nil {
+		return fmt.Errorf("error parsing resources: %s", docs.err)
+	}
+	return p.err
+}
+
+// parser parses the resources of the documents of a stream.
+type parser struct {
+	fn    func(*types.Wrapper) error
+	count int
+	err   error
+}
 
-	resourceStrs, err := splitResources(in)
+// parseDocument parses the resources of the document doc.
+func (p *parser) parseDocument(doc io.Reader) error {
+	r := bufio.NewReader(doc)
+	if isJSON(r) {
+		return p.decode(r)
+	}
+	// We are dealing with YAML data, converted to JSON a document at a time
+	b, err := ioutil.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing resources: %s", err)
-	}
-
-	count := 0
-	for _, resourceStr := range resourceStrs {
-		b := []byte(resourceStr)
-		var jsonBytes []byte
-		if jsonRe.Match(b) {
-			// We are dealing with JSON data
-			jsonBytes = b
-		} else {
-			// We are dealing with YAML data
-			var err error
-			jsonBytes, err = yaml.YAMLToJSON(b)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing resources: %s", err)
-			}
+		return fmt.Errorf("error parsing resources: %s", err)
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+	jsonBytes, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return fmt.Errorf("error parsing resources: %s", err)
+	}
+	return p.decode(bytes.NewReader(jsonBytes))
+}
+
+// decode decodes the JSON resources of r, one at a time.
+func (p *parser) decode(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	errCount := 0
+	for dec.More() {
+		var raw json.RawMessage
+		rerr := dec.Decode(&raw)
+		var w types.Wrapper
+		if rerr == nil {
+			wrapperDec := json.NewDecoder(bytes.NewReader(raw))
+			wrapperDec.DisallowUnknownFields() // this will only warn about top-level keys like spec, api_version
+			rerr = wrapperDec.Decode(&w)
 		}
-		dec := json.NewDecoder(bytes.NewReader(jsonBytes))
-		dec.DisallowUnknownFields() // this will only warn about top-level keys like spec, api_version
-		warnDec := json.NewDecoder(bytes.NewReader(jsonBytes))
-		errCount := 0
-		for dec.More() {
-			var w types.Wrapper
-			if rerr := dec.Decode(&w); rerr != nil {
-				// Write out as many errors as possible before bailing,
-				// but cap it at 10.
-				err = errors.New("some resources couldn't be parsed")
-				if errCount > 10 {
-					err = errors.New("too many errors")
-					break
-				}
-				describeError(count, rerr)
-				errCount++
-				continue
+		if rerr != nil {
+			// Write out as many errors as possible before bailing,
+			// but cap it at 10.
+			p.err = errors.New("some resources couldn't be parsed")
+			if errCount > 10 {
+				return errors.New("too many errors")
 			}
+			describeError(p.count, rerr)
+			errCount++
+			continue
+		}
 
-			// Warn if there are unknown fields
-			stripWrapperAndMaybeWarn(warnDec, &w, count)
+		// Warn if there are unknown fields
+		stripWrapperAndMaybeWarn(json.NewDecoder(bytes.NewReader(raw)), &w, p.count)
 
-			resources = append(resources, &w)
-			count++
+		// TODO(echlebek): remove this
+		filterCheckSubdue(&w)
+
+		if err := p.fn(&w); err != nil {
+			return err
 		}
+		p.count++
 	}
+	return nil
+}
 
-	// TODO(echlebek): remove this
-	filterCheckSubdue(resources)
-
-	return resources, err
+// isJSON returns true if the first non-whitespace byte of r starts a JSON
+// object or array, without consuming it.
+func isJSON(r *bufio.Reader) bool {
+	for n := 1; ; n++ {
+		b, _ := r.Peek(n)
+		if len(b) < n {
+			return false
+		}
+		switch b[n-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{', '[':
+			return true
+		default:
+			return false
+		}
+	}
 }
 
 // warn if there are any unknown fields in the resource. ignores errors because
@@ -106,45 +166,78 @@ func stripWrapperAndMaybeWarn(dec *json.Decoder, wrapper *types.Wrapper, count i
 	}
 }
 
-// splitResources scans the content of the reader and splits the resources.
-// The resources should be separated by a line containing only "---".
-// An error will be returned if the data from the reader cannot be read.
-func splitResources(in io.Reader) ([]string, error) {
-	var resources []string
-	inScanner := bufio.NewScanner(in)
-	currentResource := ""
-	for inScanner.Scan() {
-		line := inScanner.Text()
-		if strings.HasPrefix(line, "---") {
-			if currentResource != "" {
-				resources = append(resources, currentResource)
-			}
-			currentResource = ""
-		} else {
-			currentResource += line + "\n"
-		}
+// documentReader reads the documents of a stream, separated by the lines that
+// start with "---", one line at a time. It reads the current document until
+// its separator, then next moves to the following document.
+type documentReader struct {
+	r    *bufio.Reader
+	line []byte
+	// sep is true once the separator of the current document is read, and
+	// started once the first document is read.
+	sep     bool
+	started bool
+	eof     bool
+	err     error
+}
+
+func newDocumentReader(in io.Reader) *documentReader {
+	return &documentReader{r: bufio.NewReader(in)}
+}
+
+// next moves to the next document, skipping what remains of the current
+// one. It returns false once the stream is read, or can't be read.
+func (d *documentReader) next() bool {
+	if !d.started {
+		d.started = true
+		return true
+	}
+	// Skip the rest of the current document
+	if _, err := io.Copy(ioutil.Discard, d); err != nil {
+		return false
 	}
-	if err := inScanner.Err(); err != nil {
-		return nil, err
+	if !d.sep {
+		return false
 	}
-	if len(currentResource) > 0 {
-		resources = append(resources, currentResource)
+	d.sep = false
+	return true
+}
+
+// Read reads the current document, and returns io.EOF at its end.
+func (d *documentReader) Read(p []byte) (int, error) {
+	for len(d.line) == 0 {
+		if d.sep || d.eof {
+			return 0, io.EOF
+		}
+		if d.err != nil {
+			return 0, d.err
+		}
+		line, err := d.r.ReadBytes('\n')
+		if err == io.EOF {
+			d.eof = true
+		} else if err != nil {
+			d.err = err
+		}
+		if bytes.HasPrefix(line, []byte("---")) {
+			d.sep = true
+			continue
+		}
+		d.line = line
 	}
-	return resources, nil
+	n := copy(p, d.line)
+	d.line = d.line[n:]
+	return n, nil
 }
 
 // filterCheckSubdue nils out any check subdue fields that are supplied.
 // TODO(echlebek): this is temporary; remove it after fixing check subdue.
-func filterCheckSubdue(resources []*types.Wrapper) {
-	for i := range resources {
-		switch val := resources[i].Value.(type) {
-		case *corev2.CheckConfig:
-			val.Subdue = nil
-		case *corev2.Check:
-			val.Subdue = nil
-		case *corev2.EventFilter:
-			val.When = nil
-		}
+func filterCheckSubdue(resource *types.Wrapper) {
+	switch val := resource.Value.(type) {
+	case *corev2.CheckConfig:
+		val.Subdue = nil
+	case *corev2.Check:
+		val.Subdue = nil
+	case *corev2.EventFilter:
+		val.When = nil
 	}
 }
 
@@ -170,8 +263,6 @@ func Validate(resources []*types.Wrapper, namespace string) error {
 	return nil
 }
 
-var jsonRe = regexp.MustCompile(`^(\s)*[\{\[]`)
-
 func describeError(index int, err error) {
 	jsonErr, ok := err.(*json.UnmarshalTypeError)
 	if !ok {
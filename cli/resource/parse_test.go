@@ -1,6 +1,9 @@
 package resource
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
 
@@ -219,3 +222,67 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStream(t *testing.T) {
+	const entities = 1000
+	// The stream is generated as it is read, so that it is never in memory
+	// as a whole
+	r, w := io.Pipe()
+	go func() {
+		for i := 0; i < entities; i++ {
+			fmt.Fprintf(w, `{"type": "Entity", "api_version": "core/v2", "spec": {"metadata": {"name": "entity-%d", "namespace": "default"}, "entity_class": "proxy"}}`+"\n", i)
+			if i == entities/2 {
+				// Multiple documents can be mixed
+				fmt.Fprint(w, "---\ntype: Entity\napi_version: core/v2\nspec:\n  metadata:\n    name: yaml\n  entity_class: proxy\n---\n")
+			}
+		}
+		w.Close()
+	}()
+
+	var names []string
+	err := ParseStream(r, func(w *types.Wrapper) error {
+		names = append(names, w.Value.(*corev2.Entity).Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(names), entities+1; got != want {
+		t.Fatalf("got %d resources, want %d", got, want)
+	}
+	if got, want := names[entities/2+1], "yaml"; got != want {
+		t.Errorf("got resource %q, want %q", got, want)
+	}
+	if got, want := names[entities], fmt.Sprintf("entity-%d", entities-1); got != want {
+		t.Errorf("got resource %q, want %q", got, want)
+	}
+}
+
+func TestParseStreamLongLine(t *testing.T) {
+	// Lines are not limited in length
+	command := strings.Repeat("x", 1<<17)
+	in := fmt.Sprintf(`{"type": "CheckConfig", "api_version": "core/v2", "spec": {"metadata": {"name": "check"}, "command": %q}}`, command)
+	resources, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 1 || resources[0].Value.(*corev2.CheckConfig).Command != command {
+		t.Fatal("bad check config")
+	}
+}
+
+func TestParseStreamStops(t *testing.T) {
+	in := "{\"type\": \"Namespace\", \"spec\": {\"name\": \"foo\"}}\n{\"type\": \"Namespace\", \"spec\": {\"name\": \"bar\"}}\n"
+	stop := errors.New("stop")
+	var count int
+	err := ParseStream(strings.NewReader(in), func(*types.Wrapper) error {
+		count++
+		return stop
+	})
+	if err != stop {
+		t.Fatalf("got error %v, want %v", err, stop)
+	}
+	if count != 1 {
+		t.Fatalf("got %d resources, want 1", count)
+	}
+}
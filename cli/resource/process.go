@@ -24,55 +24,83 @@ type Processor interface {
 	Process(client client.GenericClient, resources []*types.Wrapper) error
 }
 
+// StdinInput is the input that reads the resources from standard in, along
+// with the other inputs.
+const StdinInput = "-"
+
 type httpDirectory struct {
 	XMLName xml.Name `xml:"pre"`
 	Files   []string `xml:"a"`
 }
 
-// Process processes the input.
+// Process processes the inputs. Each resource is applied by processor as
+// soon as it is parsed, so that the inputs are never loaded in memory as a
+// whole.
 func Process(cli *cli.SensuCli, client *http.Client, inputs []string, recurse bool, processor Processor) error {
-	var resources []*types.Wrapper
+	a := newApplier(cli, processor)
 	for _, input := range inputs {
-		res, err := process(client, input, recurse)
-		if err != nil {
+		if err := walk(client, input, recurse, a.apply); err != nil {
 			return err
 		}
-		resources = append(resources, res...)
 	}
-	if err := Validate(resources, cli.Config.Namespace()); err != nil {
+	return nil
+}
+
+// applier applies the resources one at a time, as they are parsed.
+type applier struct {
+	cli       *cli.SensuCli
+	processor Processor
+	count     int
+}
+
+func newApplier(cli *cli.SensuCli, processor Processor) *applier {
+	return &applier{cli: cli, processor: processor}
+}
+
+// apply validates the resource w, and applies it with the processor.
+func (a *applier) apply(w *types.Wrapper) error {
+	defer func() { a.count++ }()
+	if w.Value == nil {
+		fmt.Fprintf(os.Stderr, "error validating resource #%d: resource is nil\n", a.count)
+		return nil
+	}
+	if err := Validate([]*types.Wrapper{w}, a.cli.Config.Namespace()); err != nil {
 		return err
 	}
-	return processor.Process(cli.Client, resources)
+	return a.processor.Process(a.cli.Client, []*types.Wrapper{w})
 }
 
-func process(client *http.Client, input string, recurse bool) ([]*types.Wrapper, error) {
-	var resources []*types.Wrapper
+// walk calls fn with each resource of input, as soon as it is parsed.
+func walk(client *http.Client, input string, recurse bool, fn func(*types.Wrapper) error) error {
+	if input == StdinInput {
+		return walkStdin(fn)
+	}
 	urly, err := url.Parse(input)
 	if err != nil {
-		return resources, err
+		return err
 	}
-	var res []*types.Wrapper
 	if urly.Scheme == "" || len(urly.Scheme) == 1 {
 		// We are dealing with a file path
-		res, err = ProcessFile(input, recurse)
-		if err != nil {
-			return resources, err
-		}
-	} else {
-		res, err = ProcessURL(client, urly, input, recurse)
-		if err != nil {
-			return resources, err
-		}
+		return walkFile(input, recurse, fn)
 	}
-	resources = append(resources, res...)
-	return resources, nil
+	return walkURL(client, urly, input, recurse, fn)
 }
 
 // ProcessFile processes a file.
 func ProcessFile(input string, recurse bool) ([]*types.Wrapper, error) {
 	var resources []*types.Wrapper
+	err := walkFile(input, recurse, func(w *types.Wrapper) error {
+		resources = append(resources, w)
+		return nil
+	})
+	return resources, err
+}
+
+// walkFile calls fn with each resource of the file or directory input, as
+// soon as it is parsed.
+func walkFile(input string, recurse bool, fn func(*types.Wrapper) error) error {
 	var tld = true
-	err := filepath.Walk(input, func(path string, info os.FileInfo, err error) error {
+	return filepath.Walk(input, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -99,70 +127,78 @@ func ProcessFile(input string, recurse bool) ([]*types.Wrapper, error) {
 		if err != nil {
 			return err
 		}
-		res, err := Parse(f)
-		if err != nil {
+		defer f.Close()
+		if err := ParseStream(f, fn); err != nil {
 			return fmt.Errorf("in %s: %s", input, err)
 		}
-		resources = append(resources, res...)
 		return nil
 	})
-	return resources, err
 }
 
 // ProcessURL processes a url.
 func ProcessURL(client *http.Client, urly *url.URL, input string, recurse bool) ([]*types.Wrapper, error) {
 	var resources []*types.Wrapper
+	err := walkURL(client, urly, input, recurse, func(w *types.Wrapper) error {
+		resources = append(resources, w)
+		return nil
+	})
+	return resources, err
+}
+
+// walkURL calls fn with each resource of the URL input, or of the files of
+// its directory listing, as soon as it is parsed.
+func walkURL(client *http.Client, urly *url.URL, input string, recurse bool, fn func(*types.Wrapper) error) error {
 	req, err := http.NewRequest("GET", urly.String(), nil)
 	if err != nil {
-		return resources, err
+		return err
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return resources, err
+		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
 		buf := new(bytes.Buffer)
 		_, _ = io.Copy(buf, resp.Body)
-		return resources, errors.New(buf.String())
+		return errors.New(buf.String())
 	}
 
 	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
 		// The server returned us a directory listing
 		if !recurse {
-			return resources, errors.New("use -r to enable directory recursion")
+			return errors.New("use -r to enable directory recursion")
 		}
 		dec := xml.NewDecoder(resp.Body)
 		var dir httpDirectory
 		if err := dec.Decode(&dir); err != nil {
-			return resources, err
+			return err
 		}
 		for _, file := range dir.Files {
-			res, err := process(client, filepath.Join(input, file), recurse)
-			if err != nil {
-				return resources, err
+			if err := walk(client, filepath.Join(input, file), recurse, fn); err != nil {
+				return err
 			}
-			resources = append(resources, res...)
-		}
-	} else {
-		resources, err = Parse(resp.Body)
-		if err != nil {
-			return resources, fmt.Errorf("in %s: %s", input, err)
 		}
+		return nil
 	}
-	return resources, nil
+	if err := ParseStream(resp.Body, fn); err != nil {
+		return fmt.Errorf("in %s: %s", input, err)
+	}
+	return nil
 }
 
-// ProcessStdin processes standard in.
+// ProcessStdin processes standard in. Each resource is applied by processor
+// as soon as it is parsed.
 func ProcessStdin(cli *cli.SensuCli, client *http.Client, processor Processor) error {
-	resources, err := Parse(os.Stdin)
-	if err != nil {
+	return walkStdin(newApplier(cli, processor).apply)
+}
+
+// walkStdin calls fn with each resource of standard in, as soon as it is
+// parsed.
+func walkStdin(fn func(*types.Wrapper) error) error {
+	if err := ParseStream(os.Stdin, fn); err != nil {
 		return fmt.Errorf("in stdin: %s", err)
 	}
-	if err := Validate(resources, cli.Config.Namespace()); err != nil {
-		return err
-	}
-	return processor.Process(cli.Client, resources)
+	return nil
 }
 
 // Putter is a Processor that puts resources in the API.
@@ -175,10 +211,10 @@ func NewPutter() *Putter {
 
 // Process puts resources in the API.
 func (p *Putter) Process(client client.GenericClient, resources []*types.Wrapper) error {
-	for i, resource := range resources {
+	for _, resource := range resources {
 		if err := client.PutResource(*resource); err != nil {
 			return fmt.Errorf(
-				"error putting resource #%d: %s", i, err,
+				"error putting %s %q: %s", resource.Type, compat.GetObjectMeta(resource.Value).Name, err,
 			)
 		}
 	}
@@ -2,6 +2,7 @@ package resource
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -47,6 +48,27 @@ func TestProcessFile(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestWalkFileAppliesEachResource(t *testing.T) {
+	td, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(td)
+
+	fp := filepath.Join(td, "input")
+	err = ioutil.WriteFile(fp, []byte(`{"type": "Namespace", "spec": {"name": "foo"}}
+{"type": "Namespace", "spec": {"name": "bar"}}`), 0644)
+	require.NoError(t, err)
+
+	// The resources are applied as they are parsed, and the first error
+	// stops the parsing
+	var applied int
+	err = walkFile(fp, false, func(*types.Wrapper) error {
+		applied++
+		return errors.New("forbidden")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, applied)
+}
+
 func TestManagedByLabelPutter_label(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/agent"
+)
+
+// stats are the statistics of the simulated agents, reported periodically.
+type stats struct {
+	agents     []*agent.Agent
+	eventsSent int64
+	errors     int64
+}
+
+func (s *stats) connected() int {
+	var connected int
+	for _, a := range s.agents {
+		if a.Connected() {
+			connected++
+		}
+	}
+	return connected
+}
+
+func (s *stats) report(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastSent int64
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			sent := atomic.LoadInt64(&s.eventsSent)
+			rate := float64(sent-lastSent) / now.Sub(last).Seconds()
+			fmt.Printf("connected agents: %d, events sent: %d (%.1f/s), errors: %d\n",
+				s.connected(), sent, rate, atomic.LoadInt64(&s.errors))
+			lastSent, last = sent, now
+		}
+	}
+}
+
+// eventGenerator sends synthetic check events on behalf of a simulated agent,
+// at a fixed interval for each of its checks.
+type eventGenerator struct {
+	agent       *agent.Agent
+	checks      int
+	interval    time.Duration
+	output      string
+	failureRate float64
+	stats       *stats
+}
+
+// newOutput returns a synthetic check output of size bytes.
+func newOutput(size int) string {
+	if size <= 0 {
+		return ""
+	}
+	return strings.Repeat("x", size)
+}
+
+func (g *eventGenerator) run(ctx context.Context) {
+	// Spread the events of the agents over the interval
+	select {
+	case <-time.After(time.Duration(rand.Int63n(int64(g.interval)))):
+	case <-ctx.Done():
+		return
+	}
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		for i := 0; i < g.checks; i++ {
+			if err := g.agent.SendEvent(ctx, g.event(i)); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				atomic.AddInt64(&g.stats.errors, 1)
+				continue
+			}
+			atomic.AddInt64(&g.stats.eventsSent, 1)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (g *eventGenerator) event(i int) *corev2.Event {
+	check := corev2.FixtureCheck(fmt.Sprintf("loadit-%d", i+1))
+	check.Interval = uint32(g.interval / time.Second)
+	if check.Interval == 0 {
+		check.Interval = 1
+	}
+	// The namespace of the agent is set when the event is sent
+	check.Namespace = ""
+	check.Output = g.output
+	check.Executed = time.Now().Unix()
+	if g.failureRate > 0 && rand.Float64() < g.failureRate {
+		check.Status = 2
+	}
+	return &corev2.Event{Check: check}
+}
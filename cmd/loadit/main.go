@@ -39,6 +39,12 @@ var (
 	flagMaxSessionLength  = flag.Duration("max-session-length", 0*time.Second, "maximum amount of time after which the agent will reconnect to one of the configured backends (no maximum by default)")
 	flagDeregister        = flag.Bool("deregister", true, "should loadit entities automatically deregister. defaults true")
 	flagHandshakeTimeout  = flag.Int("backend-handshake-timeout", 45, "timeout for exchanging handshake with backend")
+	flagRampUp            = flag.Duration("ramp-up", 0, "duration over which the agents are connected (all at once by default)")
+	flagEventInterval     = flag.Duration("event-interval", 0, "interval at which each agent sends synthetic events for each of its checks (disabled by default)")
+	flagEventChecks       = flag.Int("event-checks", 1, "number of synthetic checks of each agent")
+	flagEventSize         = flag.Int("event-size", 256, "size in bytes of the check output of synthetic events")
+	flagEventFailureRate  = flag.Float64("event-failure-rate", 0, "ratio of synthetic events with a critical status, between 0 and 1")
+	flagReportInterval    = flag.Duration("report-interval", 10*time.Second, "interval at which the statistics of the agents are printed")
 )
 
 func main() {
@@ -65,8 +71,18 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	stats := &stats{}
+	output := newOutput(*flagEventSize)
+	var rampUpDelay time.Duration
+	if *flagCount > 0 {
+		rampUpDelay = *flagRampUp / time.Duration(*flagCount)
+	}
+
 	start := time.Now()
 	for i := 0; i < *flagCount; i++ {
+		if i > 0 && rampUpDelay > 0 {
+			time.Sleep(rampUpDelay)
+		}
 		name := fmt.Sprintf("%s-%d", *flagBaseEntityName, i+1)
 
 		cfg := agent.NewConfig()
@@ -105,11 +121,28 @@ func main() {
 				log.Fatal(err)
 			}
 		}()
+		stats.agents = append(stats.agents, agent)
+
+		if *flagEventInterval > 0 {
+			generator := &eventGenerator{
+				agent:       agent,
+				checks:      *flagEventChecks,
+				interval:    *flagEventInterval,
+				output:      output,
+				failureRate: *flagEventFailureRate,
+				stats:       stats,
+			}
+			go generator.run(ctx)
+		}
 	}
 
 	elapsed := time.Since(start)
 	fmt.Printf("all agents have been connected in %s\n", elapsed)
 
+	if *flagReportInterval > 0 {
+		go stats.report(ctx, *flagReportInterval)
+	}
+
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
@@ -0,0 +1,9 @@
+//go:build !no_open_extensions
+
+package main
+
+// The open reference extension is compiled into sensu-backend unless the
+// no_open_extensions build tag is set. Forks provide their own extensions by
+// importing their packages in a file of this package, and can build without
+// the open extension.
+import _ "github.com/sensu/sensu-go/backend/extension/open"
@@ -24,6 +24,8 @@ func main() {
 	rootCmd.AddCommand(cmd.StartCommand(backend.Initialize))
 	rootCmd.AddCommand(cmd.VersionCommand())
 	rootCmd.AddCommand(cmd.InitCommand())
+	rootCmd.AddCommand(cmd.ConfigCommand())
+	rootCmd.AddCommand(cmd.ReplayCommand())
 
 	if err := rootCmd.Execute(); err != nil {
 		if err == seeds.ErrAlreadyInitialized {
@@ -94,6 +94,11 @@ type ExecutionRequest struct {
 
 	// InProgressMu is the mutex for the InProgress map.
 	InProgressMu	*sync.Mutex
+
+	// MaxOutputSize is the maximum size of the output captured, in bytes.
+	// The end of larger outputs is kept, since that is where the errors
+	// usually are. The output is not limited if it is zero.
+	MaxOutputSize	int
 }
 
 // ExecutionResponse provides the response information of an ExecutionRequest.
@@ -147,10 +152,10 @@ func (e *ExecutionRequest) Execute(ctx context.Context, execution ExecutionReque
 
 	// Share an output buffer between STDOUT/ERR, following the
 	// Nagios plugin spec.
-	var output bytesutil.SyncBuffer
+	output := bytesutil.NewTailBuffer(execution.MaxOutputSize)
 
-	cmd.Stdout = &output
-	cmd.Stderr = &output
+	cmd.Stdout = output
+	cmd.Stderr = output
 
 	// If Input is specified, write to STDIN.
 	if execution.Input != "" {
@@ -186,7 +191,7 @@ func (e *ExecutionRequest) Execute(ctx context.Context, execution ExecutionReque
 	var killErr error
 	select {
 	case <-waitCh:
-		resp.Output = output.String()
+		resp.Output = tailOutput(output)
 		if err != nil {
 			// The command most likely return a non-zero exit status.
 			if exitError, ok := err.(*exec.ExitError); ok {
@@ -213,7 +218,7 @@ func (e *ExecutionRequest) Execute(ctx context.Context, execution ExecutionReque
 			escapeZombie(&execution)
 		}
 		timeout()
-		resp.Output = fmt.Sprintf("%s%s%s", TimeoutOutput, killErrOutput, output.String())
+		resp.Output = fmt.Sprintf("%s%s%s", TimeoutOutput, killErrOutput, tailOutput(output))
 		resp.Status = TimeoutExitStatus
 	}
 
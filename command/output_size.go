@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	corev2 "github.com/sensu/core/v2"
+	bytesutil "github.com/sensu/sensu-go/util/bytes"
+)
+
+const (
+	// MaxOutputSizeAnnotation is the annotation of the hooks and handlers
+	// that sets the maximum size of their output, in bytes, or in kilobytes
+	// or megabytes with a KB or MB suffix, such as 64KB. The end of larger
+	// outputs is kept, since that is where the errors usually are.
+	MaxOutputSizeAnnotation = "sensu.io/max_output_size"
+
+	// TruncatedOutputFormat is the format of the line that replaces the
+	// start of the outputs larger than their maximum size, given the number
+	// of bytes truncated.
+	TruncatedOutputFormat = "(%d bytes of output truncated)\n"
+)
+
+// MaxOutputSize returns the maximum output size set by the
+// MaxOutputSizeAnnotation of meta, or zero if it has none.
+func MaxOutputSize(meta corev2.ObjectMeta) (int, error) {
+	value, ok := meta.Annotations[MaxOutputSizeAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	value = strings.ToUpper(strings.TrimSpace(value))
+	unit := 1
+	switch {
+	case strings.HasSuffix(value, "KB"):
+		unit, value = 1<<10, strings.TrimSuffix(value, "KB")
+	case strings.HasSuffix(value, "MB"):
+		unit, value = 1<<20, strings.TrimSuffix(value, "MB")
+	case strings.HasSuffix(value, "B"):
+		value = strings.TrimSuffix(value, "B")
+	}
+	size, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid %s annotation: %q", MaxOutputSizeAnnotation, meta.Annotations[MaxOutputSizeAnnotation])
+	}
+	return size * unit, nil
+}
+
+// tailOutput returns the output retained by buf, starting with a line that
+// tells how many bytes were truncated, if any.
+func tailOutput(buf *bytesutil.TailBuffer) string {
+	tail, truncated := buf.Bytes()
+	if truncated == 0 {
+		return string(tail)
+	}
+	// The output may be truncated in the middle of a UTF-8 encoded rune
+	for i := 0; i < utf8.UTFMax-1 && len(tail) > 0 && !utf8.RuneStart(tail[0]); i++ {
+		tail = tail[1:]
+		truncated++
+	}
+	return fmt.Sprintf(TruncatedOutputFormat, truncated) + string(tail)
+}
@@ -0,0 +1,66 @@
+package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/sensu/sensu-go/testing/testutil"
+	bytesutil "github.com/sensu/sensu-go/util/bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxOutputSize(t *testing.T) {
+	tests := []struct {
+		annotation string
+		want       int
+		wantErr    bool
+	}{
+		{annotation: "", want: 0, wantErr: true},
+		{annotation: "4096", want: 4096},
+		{annotation: "512B", want: 512},
+		{annotation: "64KB", want: 64 << 10},
+		{annotation: "2 mb", want: 2 << 20},
+		{annotation: "-1", wantErr: true},
+		{annotation: "lots", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.annotation, func(t *testing.T) {
+			meta := corev2.ObjectMeta{Annotations: map[string]string{MaxOutputSizeAnnotation: tt.annotation}}
+			size, err := MaxOutputSize(meta)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, size)
+		})
+	}
+
+	size, err := MaxOutputSize(corev2.ObjectMeta{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, size)
+}
+
+func TestTailOutput(t *testing.T) {
+	buf := bytesutil.NewTailBuffer(4)
+	_, _ = buf.Write([]byte("ok"))
+	assert.Equal(t, "ok", tailOutput(buf))
+
+	// The truncated rune is not kept
+	_, _ = buf.Write([]byte("日本"))
+	assert.Equal(t, "(5 bytes of output truncated)\n本", tailOutput(buf))
+}
+
+func TestExecuteMaxOutputSize(t *testing.T) {
+	cat := FakeCommand("cat")
+	cat.Input = strings.Repeat("a", 100) + "the error"
+	cat.MaxOutputSize = len("the error")
+
+	catExec, catErr := cat.Execute(context.Background(), cat)
+	require.NoError(t, catErr)
+	assert.Equal(t, "(100 bytes of output truncated)\nthe error", testutil.CleanOutput(catExec.Output))
+	assert.Equal(t, 0, catExec.Status)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
 	"github.com/graphql-go/graphql/language/parser"
 	"github.com/graphql-go/graphql/language/source"
 )
@@ -220,6 +221,21 @@ func (service *Service) Do(ctx context.Context, p QueryParams) *Result {
 	})
 }
 
+// IsMutation returns true if the query holds a mutation. The queries that
+// can't be parsed are not mutations, their errors are returned by Do.
+func IsMutation(query string) bool {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return false
+	}
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok && op.Operation == ast.OperationTypeMutation {
+			return true
+		}
+	}
+	return false
+}
+
 type typeRegister struct {
 	types      map[Kind]map[string]registerTypeFn
 	extensions map[string][]interface{}
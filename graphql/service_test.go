@@ -0,0 +1,21 @@
+package graphql
+
+import "testing"
+
+func TestIsMutation(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: `{ viewer { username } }`, want: false},
+		{query: `query { viewer { username } }`, want: false},
+		{query: `mutation { deleteEntity(input: {id: "foo"}) { deletedId } }`, want: true},
+		{query: `query a { viewer { username } } mutation b { deleteEntity(input: {id: "foo"}) { deletedId } }`, want: true},
+		{query: `mutation {`, want: false},
+	}
+	for _, tt := range tests {
+		if got := IsMutation(tt.query); got != tt.want {
+			t.Errorf("IsMutation(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package mockresource
+
+import "sync"
+
+// BackendEvent is an event of the backend entity.
+type BackendEvent struct {
+	Status uint32
+	Output string
+}
+
+// EventReceiver is a resource.EventReceiver that records the events of each
+// component.
+type EventReceiver struct {
+	mu     sync.Mutex
+	events map[string][]BackendEvent
+}
+
+// GenerateBackendEvent records the event of component.
+func (r *EventReceiver) GenerateBackendEvent(component string, status uint32, output string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.events == nil {
+		r.events = map[string][]BackendEvent{}
+	}
+	r.events[component] = append(r.events[component], BackendEvent{Status: status, Output: output})
+	return nil
+}
+
+// Reset forgets the recorded events.
+func (r *EventReceiver) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = nil
+}
+
+// Event returns the last event of component, and false if it has none.
+func (r *EventReceiver) Event(component string) (BackendEvent, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events[component]
+	if len(events) == 0 {
+		return BackendEvent{}, false
+	}
+	return events[len(events)-1], true
+}
+
+// Events returns the events of component, in the order they were recorded.
+func (r *EventReceiver) Events(component string) []BackendEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]BackendEvent(nil), r.events[component]...)
+}
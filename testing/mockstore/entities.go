@@ -25,6 +25,18 @@ func (s *MockStore) GetEntities(ctx context.Context, pred *store.SelectionPredic
 	return args.Get(0).([]*v2.Entity), args.Error(1)
 }
 
+// CountEntities ...
+func (s *MockStore) CountEntities(ctx context.Context) (int, error) {
+	args := s.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+// EntityStoreSupportsFiltering ...
+func (s *MockStore) EntityStoreSupportsFiltering(ctx context.Context) bool {
+	args := s.Called(ctx)
+	return args.Bool(0)
+}
+
 // GetEntityByName ...
 func (s *MockStore) GetEntityByName(ctx context.Context, id string) (*v2.Entity, error) {
 	args := s.Called(ctx, id)
@@ -36,3 +48,19 @@ func (s *MockStore) UpdateEntity(ctx context.Context, e *v2.Entity) error {
 	args := s.Called(ctx, e)
 	return args.Error(0)
 }
+
+// UpdateEntities calls update with the entities of the mocked GetEntities
+// call, and records the changes it returns with the mocked UpdateEntities
+// call, unless there are none.
+func (s *MockStore) UpdateEntities(ctx context.Context, update func([]*v2.Entity) ([]*v2.Entity, []string, error)) error {
+	existing, err := s.GetEntities(ctx, &store.SelectionPredicate{})
+	if err != nil {
+		return err
+	}
+	entities, deletes, err := update(existing)
+	if err != nil || len(entities) == 0 && len(deletes) == 0 {
+		return err
+	}
+	args := s.Called(ctx, entities, deletes)
+	return args.Error(0)
+}
@@ -7,6 +7,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -15,6 +17,37 @@ import (
 
 var ErrTooManyRequests = errors.New("too many requests")
 
+// TooManyRequestsError is returned when the backend refuses the connection
+// because it is overloaded, or because it has too many agent sessions. It
+// matches ErrTooManyRequests.
+type TooManyRequestsError struct {
+	// RetryAfter is the delay after which the backend asks to connect again,
+	// or zero if it doesn't.
+	RetryAfter time.Duration
+}
+
+func (e TooManyRequestsError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s, retry after %s", ErrTooManyRequests, e.RetryAfter)
+	}
+	return ErrTooManyRequests.Error()
+}
+
+// Is returns true if target is ErrTooManyRequests.
+func (e TooManyRequestsError) Is(target error) bool {
+	return target == ErrTooManyRequests
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds. The HTTP dates
+// are not supported, since the backend doesn't send them.
+func parseRetryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 // connect establish the connection to a given websocket backend and returns it
 // along with any error encountered
 func connect(wsServerURL string, tlsOpts *v2.TLSOptions, requestHeader http.Header, handshakeTimeout int) (*websocket.Conn, http.Header, error) {
@@ -42,6 +75,11 @@ func connect(wsServerURL string, tlsOpts *v2.TLSOptions, requestHeader http.Head
 	conn, resp, err := dialer.Dial(u.String(), requestHeader)
 	if err != nil {
 		if resp != nil {
+			// Refused handshakes are bad handshakes, so the 429 status is
+			// checked first
+			if resp.StatusCode == http.StatusTooManyRequests {
+				return nil, nil, TooManyRequestsError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+			}
 			if err == websocket.ErrBadHandshake {
 				err := fmt.Errorf("handshake failed with status %d", resp.StatusCode)
 				body, berr := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
@@ -50,9 +88,6 @@ func connect(wsServerURL string, tlsOpts *v2.TLSOptions, requestHeader http.Head
 				}
 				return nil, resp.Header, err
 			}
-			if resp.StatusCode == http.StatusTooManyRequests {
-				return nil, nil, ErrTooManyRequests
-			}
 			return nil, resp.Header, fmt.Errorf("connection failed with status %d", resp.StatusCode)
 		}
 		return nil, nil, err
@@ -63,12 +98,25 @@ func connect(wsServerURL string, tlsOpts *v2.TLSOptions, requestHeader http.Head
 
 // Connect causes the transport Client to connect to a given websocket server.
 // Transport is a thin wrapper around a websocket connection that makes the
-// connection safe for concurrent use by multiple goroutines.
+// connection safe for concurrent use by multiple goroutines. Its messages are
+// compressed with the compression algorithm picked by the server, if any. The
+// URLs with the grpc:// or grpcs:// scheme are connected to with a gRPC
+// stream instead.
 func Connect(wsServerURL string, tlsOpts *v2.TLSOptions, requestHeader http.Header, handshakeTimeout int) (Transport, http.Header, error) {
+	if u, err := url.Parse(wsServerURL); err == nil && IsGRPCURL(u) {
+		return connectGRPC(u, tlsOpts, requestHeader, handshakeTimeout)
+	}
+
 	conn, resp, err := connect(wsServerURL, tlsOpts, requestHeader, handshakeTimeout)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return NewTransport(conn), resp, nil
+	t, err := NewCompressedTransport(conn, resp.Get(HeaderKeyCompression))
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+
+	return t, resp, nil
 }
@@ -0,0 +1,244 @@
+package transport
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// CompressionDeflate compresses each message with DEFLATE.
+	CompressionDeflate = "deflate"
+
+	// CompressionZstd compresses each message with Zstandard.
+	CompressionZstd = "zstd"
+
+	// CompressionThreshold is the size of the messages, in bytes, from which
+	// they are compressed. Smaller messages are sent as they are.
+	CompressionThreshold = 1024
+
+	// MaxDecompressedSize is the maximum size of the payloads of the
+	// compressed messages once decompressed, in bytes, unless the transport
+	// has a lower maximum message size.
+	MaxDecompressedSize = 64 * 1024 * 1024
+
+	// UncompressedBytesCounterName is the name of the prometheus counter of
+	// the bytes of the messages of the compressed transports, before their
+	// compression.
+	UncompressedBytesCounterName = "sensu_go_transport_uncompressed_bytes_total"
+
+	// CompressedBytesCounterName is the name of the prometheus counter of the
+	// bytes of the messages of the compressed transports, as sent on the wire.
+	CompressedBytesCounterName = "sensu_go_transport_compressed_bytes_total"
+
+	// compressionSep separates the type of the compressed messages from their
+	// compression algorithm.
+	compressionSep = ";"
+)
+
+// Compressions are the supported compression algorithms, in order of
+// preference.
+var Compressions = []string{CompressionZstd, CompressionDeflate}
+
+var (
+	uncompressedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: UncompressedBytesCounterName,
+			Help: "The total number of bytes of the messages of the compressed transports, before their compression",
+		},
+		[]string{"compression", "direction"},
+	)
+
+	compressedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: CompressedBytesCounterName,
+			Help: "The total number of bytes of the messages of the compressed transports, as sent on the wire",
+		},
+		[]string{"compression", "direction"},
+	)
+)
+
+func init() {
+	_ = prometheus.Register(uncompressedBytes)
+	_ = prometheus.Register(compressedBytes)
+}
+
+// ValidateCompressions returns an error if one of the compression algorithms
+// is not supported.
+func ValidateCompressions(compressions []string) error {
+	for _, compression := range compressions {
+		if _, err := newCodec(compression); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NegotiateCompression returns the first compression algorithm of offered, a
+// comma-delimited list of algorithms in order of preference, that is in
+// accepted, or an empty string if there is none.
+func NegotiateCompression(offered string, accepted []string) string {
+	for _, compression := range strings.Split(offered, ",") {
+		compression = strings.TrimSpace(compression)
+		for _, a := range accepted {
+			if compression != "" && compression == a {
+				return compression
+			}
+		}
+	}
+	return ""
+}
+
+// errDecompressedSize is the error of decompressing a payload beyond the
+// maximum message size.
+var errDecompressedSize = errors.New("decompressed payload exceeds the maximum message size")
+
+// codec compresses and decompresses messages with a compression algorithm.
+// decompress fails with errDecompressedSize if the decompressed payload is
+// larger than max bytes.
+type codec interface {
+	compress(payload []byte) ([]byte, error)
+	decompress(payload []byte, max int) ([]byte, error)
+}
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+func newCodec(compression string) (codec, error) {
+	switch compression {
+	case CompressionDeflate:
+		return deflateCodec{}, nil
+	case CompressionZstd:
+		// The zstd encoder and decoder are safe for concurrent use by their
+		// EncodeAll and DecodeAll methods, and are shared by the transports.
+		zstdOnce.Do(func() {
+			zstdEncoder, zstdErr = zstd.NewWriter(nil)
+			if zstdErr != nil {
+				return
+			}
+			zstdDecoder, zstdErr = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxDecompressedSize))
+		})
+		if zstdErr != nil {
+			return nil, zstdErr
+		}
+		return zstdCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression: %q", compression)
+	}
+}
+
+type deflateCodec struct{}
+
+func (deflateCodec) compress(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (deflateCodec) decompress(payload []byte, max int) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(payload))
+	defer r.Close()
+	// Read one byte past the maximum size to tell a payload of exactly max
+	// bytes from a larger one
+	p, err := ioutil.ReadAll(io.LimitReader(r, int64(max)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(p) > max {
+		return nil, errDecompressedSize
+	}
+	return p, nil
+}
+
+type zstdCodec struct{}
+
+func (zstdCodec) compress(payload []byte) ([]byte, error) {
+	return zstdEncoder.EncodeAll(payload, nil), nil
+}
+
+func (zstdCodec) decompress(payload []byte, max int) ([]byte, error) {
+	// The decoder doesn't decode more than MaxDecompressedSize bytes
+	p, err := zstdDecoder.DecodeAll(payload, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, errDecompressedSize
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(p) > max {
+		return nil, errDecompressedSize
+	}
+	return p, nil
+}
+
+// encodeCompressed encodes a message to be sent over a websocket channel,
+// with its payload compressed if it is large enough and the compression
+// makes it smaller. The type of the compressed messages is followed by their
+// compression algorithm, so that they are decompressed whatever the
+// compression of the receiving transport.
+func encodeCompressed(compression string, c codec, msgType string, payload []byte) ([]byte, error) {
+	if len(payload) < CompressionThreshold {
+		return Encode(msgType, payload), nil
+	}
+	compressed, err := c.compress(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed) >= len(payload) {
+		return Encode(msgType, payload), nil
+	}
+	return Encode(msgType+compressionSep+compression, compressed), nil
+}
+
+// decodeCompressed decodes a message received from a websocket channel,
+// decompressing its payload if it was compressed. It returns the compression
+// algorithm of the message, if any. The payload is not decompressed beyond
+// max bytes, or MaxDecompressedSize if max is zero, and a
+// MessageTooLargeError is returned instead.
+func decodeCompressed(p []byte, max int) (string, []byte, string, error) {
+	msgType, payload, err := Decode(p)
+	if err != nil {
+		return "", nil, "", err
+	}
+	i := strings.Index(msgType, compressionSep)
+	if i < 0 {
+		return msgType, payload, "", nil
+	}
+	compression := msgType[i+len(compressionSep):]
+	c, err := newCodec(compression)
+	if err != nil {
+		return "", nil, "", err
+	}
+	if max <= 0 || max > MaxDecompressedSize {
+		max = MaxDecompressedSize
+	}
+	payload, err = c.decompress(payload, max)
+	if err == errDecompressedSize {
+		return "", nil, "", MessageTooLargeError{Type: msgType[:i], MaxSize: max}
+	}
+	if err != nil {
+		return "", nil, "", fmt.Errorf("could not decompress %s message: %s", compression, err)
+	}
+	return msgType[:i], payload, compression, nil
+}
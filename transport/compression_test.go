@@ -0,0 +1,172 @@
+package transport
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateCompression(t *testing.T) {
+	tests := []struct {
+		name     string
+		offered  string
+		accepted []string
+		want     string
+	}{
+		{
+			name:     "nothing offered",
+			offered:  "",
+			accepted: Compressions,
+			want:     "",
+		},
+		{
+			name:     "nothing accepted",
+			offered:  "zstd,deflate",
+			accepted: nil,
+			want:     "",
+		},
+		{
+			name:     "preference of the offer",
+			offered:  "deflate, zstd",
+			accepted: Compressions,
+			want:     CompressionDeflate,
+		},
+		{
+			name:     "unknown algorithms are skipped",
+			offered:  "brotli,zstd",
+			accepted: Compressions,
+			want:     CompressionZstd,
+		},
+		{
+			name:     "only accepted algorithms",
+			offered:  "zstd,deflate",
+			accepted: []string{CompressionDeflate},
+			want:     CompressionDeflate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NegotiateCompression(tt.offered, tt.accepted))
+		})
+	}
+}
+
+func TestValidateCompressions(t *testing.T) {
+	assert.NoError(t, ValidateCompressions(Compressions))
+	assert.Error(t, ValidateCompressions([]string{"brotli"}))
+}
+
+func TestEncodeDecodeCompressed(t *testing.T) {
+	small := []byte("small payload")
+	large := bytes.Repeat([]byte("check output with metrics "), 1000)
+
+	for _, compression := range Compressions {
+		t.Run(compression, func(t *testing.T) {
+			c, err := newCodec(compression)
+			require.NoError(t, err)
+
+			msg, err := encodeCompressed(compression, c, MessageTypeEvent, small)
+			require.NoError(t, err)
+			assert.Equal(t, Encode(MessageTypeEvent, small), msg)
+
+			msg, err = encodeCompressed(compression, c, MessageTypeEvent, large)
+			require.NoError(t, err)
+			assert.Less(t, len(msg), len(large))
+			msgType, payload, got, err := decodeCompressed(msg, 0)
+			require.NoError(t, err)
+			assert.Equal(t, MessageTypeEvent, msgType)
+			assert.Equal(t, large, payload)
+			assert.Equal(t, compression, got)
+		})
+	}
+}
+
+func TestDecodeCompressedTooLarge(t *testing.T) {
+	large := bytes.Repeat([]byte("check output with metrics "), 1000)
+
+	for _, compression := range Compressions {
+		t.Run(compression, func(t *testing.T) {
+			c, err := newCodec(compression)
+			require.NoError(t, err)
+			msg, err := encodeCompressed(compression, c, MessageTypeEvent, large)
+			require.NoError(t, err)
+
+			_, _, _, err = decodeCompressed(msg, len(large)-1)
+			assert.Equal(t, MessageTooLargeError{Type: MessageTypeEvent, MaxSize: len(large) - 1}, err)
+
+			_, payload, _, err := decodeCompressed(msg, len(large))
+			require.NoError(t, err)
+			assert.Equal(t, large, payload)
+		})
+	}
+}
+
+func TestDecodeMessageTooLargeOnTheWire(t *testing.T) {
+	large := bytes.Repeat([]byte("check output with metrics "), 1000)
+
+	// The uncompressed messages are checked as received
+	_, err := decodeMessage("", nil, Encode(MessageTypeEvent, large), len(large)-1)
+	assert.Equal(t, MessageTooLargeError{Type: MessageTypeEvent, Size: len(large), MaxSize: len(large) - 1}, err)
+
+	for _, compression := range Compressions {
+		t.Run(compression, func(t *testing.T) {
+			c, err := newCodec(compression)
+			require.NoError(t, err)
+			msg, err := encodeCompressed(compression, c, MessageTypeEvent, large)
+			require.NoError(t, err)
+			_, compressed, err := Decode(msg)
+			require.NoError(t, err)
+
+			// The compressed messages are dropped before they are
+			// decompressed
+			_, err = decodeMessage(compression, c, msg, len(compressed)-1)
+			assert.Equal(t, MessageTooLargeError{Type: MessageTypeEvent, Size: len(compressed), MaxSize: len(compressed) - 1}, err)
+
+			m, err := decodeMessage(compression, c, msg, len(large))
+			require.NoError(t, err)
+			assert.Equal(t, large, m.Payload)
+		})
+	}
+}
+
+func TestDecodeCompressedUnsupported(t *testing.T) {
+	_, _, _, err := decodeCompressed(Encode(MessageTypeEvent+compressionSep+"brotli", []byte("payload")), 0)
+	assert.Error(t, err)
+}
+
+func TestCompressedTransportSendReceive(t *testing.T) {
+	payload := bytes.Repeat([]byte("check output with metrics "), 1000)
+
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compression := NegotiateCompression(r.Header.Get(HeaderKeyCompression), Compressions)
+		header := make(http.Header)
+		header.Set(HeaderKeyCompression, compression)
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, header)
+		require.NoError(t, err)
+		transport, err := NewCompressedTransport(conn, compression)
+		require.NoError(t, err)
+
+		msg, err := transport.Receive()
+		assert.NoError(t, err)
+		assert.Equal(t, MessageTypeEvent, msg.Type)
+		assert.Equal(t, payload, msg.Payload)
+		done <- struct{}{}
+	}))
+	defer ts.Close()
+
+	header := make(http.Header)
+	header.Set(HeaderKeyCompression, "zstd,deflate")
+	clientTransport, respHeader, err := Connect(strings.Replace(ts.URL, "http", "ws", 1), nil, header, 5)
+	require.NoError(t, err)
+	assert.Equal(t, CompressionZstd, respHeader.Get(HeaderKeyCompression))
+	assert.NoError(t, clientTransport.Send(NewMessage(MessageTypeEvent, payload)))
+
+	<-done
+}
@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// MaxEventBatchSize is the maximum number of events of an event batch.
+	MaxEventBatchSize = 100
+
+	// MaxEventBatchBytes is the maximum size of the events of an event
+	// batch, in bytes.
+	MaxEventBatchBytes = 1 << 20
+
+	// MaxBatchedEventSize is the maximum size of the events packed into
+	// event batches, in bytes. Larger events gain nothing from being batched
+	// and are sent one by one.
+	MaxBatchedEventSize = 16 << 10
+)
+
+var errEventBatchTruncated = errors.New("event batch is truncated")
+
+// EncodeEventBatch encodes the payloads of events into the payload of an
+// event batch message: each payload is preceded by its size, as a varint.
+func EncodeEventBatch(payloads [][]byte) []byte {
+	size := 0
+	for _, payload := range payloads {
+		size += binary.MaxVarintLen64 + len(payload)
+	}
+	buf := make([]byte, size)
+	n := 0
+	for _, payload := range payloads {
+		n += binary.PutUvarint(buf[n:], uint64(len(payload)))
+		n += copy(buf[n:], payload)
+	}
+	return buf[:n]
+}
+
+// DecodeEventBatch decodes the payloads of the events of an event batch
+// message. The payloads share the memory of the message payload. It returns
+// an error if the batch has more than MaxEventBatchSize events.
+func DecodeEventBatch(payload []byte) ([][]byte, error) {
+	var payloads [][]byte
+	for len(payload) > 0 {
+		if len(payloads) == MaxEventBatchSize {
+			return nil, fmt.Errorf("event batch has more than %d events", MaxEventBatchSize)
+		}
+		size, n := binary.Uvarint(payload)
+		if n <= 0 {
+			return nil, errEventBatchTruncated
+		}
+		payload = payload[n:]
+		if size > uint64(len(payload)) {
+			return nil, errEventBatchTruncated
+		}
+		payloads = append(payloads, payload[:size])
+		payload = payload[size:]
+	}
+	return payloads, nil
+}
@@ -0,0 +1,41 @@
+package transport
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBatchRoundTrip(t *testing.T) {
+	payloads := [][]byte{
+		[]byte(`{"check":"check1"}`),
+		{},
+		bytes.Repeat([]byte("x"), 300),
+	}
+	got, err := DecodeEventBatch(EncodeEventBatch(payloads))
+	require.NoError(t, err)
+	require.Len(t, got, len(payloads))
+	for i := range payloads {
+		assert.Equal(t, string(payloads[i]), string(got[i]))
+	}
+
+	got, err = DecodeEventBatch(EncodeEventBatch(nil))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestDecodeEventBatchInvalid(t *testing.T) {
+	batch := EncodeEventBatch([][]byte{[]byte("event")})
+
+	_, err := DecodeEventBatch(batch[:len(batch)-1])
+	assert.Error(t, err, "truncated payload")
+
+	_, err = DecodeEventBatch([]byte{0x80})
+	assert.Error(t, err, "truncated size")
+
+	payloads := make([][]byte, MaxEventBatchSize+1)
+	_, err = DecodeEventBatch(EncodeEventBatch(payloads))
+	assert.Error(t, err, "too many events")
+}
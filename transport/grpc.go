@@ -0,0 +1,211 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// GRPCMethod is the method of the gRPC bidirectional stream of the agent
+	// sessions, whose messages are the messages of the transport.
+	GRPCMethod = "/" + grpcServiceName + "/Connect"
+
+	grpcServiceName = "sensu.transport.Agent"
+
+	// grpcAcceptedKey is the key of the header of the accepted streams, to
+	// tell them from the refused streams, whose response only has trailers.
+	grpcAcceptedKey = "sensu-stream-accepted"
+
+	// grpcCodecName is the content subtype of the gRPC streams of the agent
+	// sessions, whose messages are encoded like the WebSocket messages.
+	grpcCodecName = "sensu"
+)
+
+// grpcStreamDesc describes the gRPC stream of the agent sessions. Its messages
+// are not protobuf messages, so it has no generated code.
+var grpcStreamDesc = grpc.StreamDesc{
+	StreamName:    "Connect",
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+func init() {
+	encoding.RegisterCodec(grpcCodec{})
+}
+
+// grpcFrame is a message of the gRPC stream, encoded like a WebSocket message.
+type grpcFrame struct {
+	data []byte
+}
+
+// grpcCodec marshals the frames of the gRPC stream as they are.
+type grpcCodec struct{}
+
+func (grpcCodec) Marshal(v interface{}) ([]byte, error) {
+	frame, ok := v.(*grpcFrame)
+	if !ok {
+		return nil, fmt.Errorf("unexpected gRPC message of type %T", v)
+	}
+	return frame.data, nil
+}
+
+func (grpcCodec) Unmarshal(data []byte, v interface{}) error {
+	frame, ok := v.(*grpcFrame)
+	if !ok {
+		return fmt.Errorf("unexpected gRPC message of type %T", v)
+	}
+	// The data is owned by gRPC, and can be reused once Unmarshal returns
+	frame.data = append([]byte(nil), data...)
+	return nil
+}
+
+func (grpcCodec) Name() string {
+	return grpcCodecName
+}
+
+// grpcStream is implemented by the client and server gRPC streams.
+type grpcStream interface {
+	Context() context.Context
+	SendMsg(m interface{}) error
+	RecvMsg(m interface{}) error
+}
+
+// A GRPCTransport is a connection between sensu Agents and Backends over a
+// gRPC bidirectional stream, for the networks that don't allow WebSocket. Its
+// connection is kept alive by the keepalive of gRPC.
+type GRPCTransport struct {
+	byteCounts
+	stream      grpcStream
+	closed      atomic.Value
+	readMu      sync.Mutex
+	writeMu     sync.Mutex
+	compression string
+	codec       codec
+
+	// maxMessageSize is the maximum size of the payloads of the messages,
+	// as in WebSocketTransport.
+	maxMessageSize int
+
+	// closeStream ends the stream, once.
+	closeOnce   sync.Once
+	closeStream func() error
+}
+
+// newGRPCTransport returns a GRPCTransport of stream, whose messages are
+// compressed with the compression algorithm, or not compressed if it is
+// empty. closeStream ends the stream.
+func newGRPCTransport(stream grpcStream, compression string, closeStream func() error) (*GRPCTransport, error) {
+	t := &GRPCTransport{
+		stream:      stream,
+		compression: compression,
+		closeStream: closeStream,
+	}
+	if compression != "" {
+		c, err := newCodec(compression)
+		if err != nil {
+			return nil, err
+		}
+		t.codec = c
+	}
+	return t, nil
+}
+
+// SetMaxMessageSize sets the maximum size of the payloads of the messages
+// received by the transport, as received and once decompressed, in bytes.
+// If size is zero, the payloads are only limited to MaxDecompressedSize once
+// decompressed. It must be called before Receive.
+func (t *GRPCTransport) SetMaxMessageSize(size int) {
+	t.maxMessageSize = size
+}
+
+// Close ends the gRPC stream.
+func (t *GRPCTransport) Close() error {
+	t.closed.Store(true)
+	var err error
+	t.closeOnce.Do(func() {
+		err = t.closeStream()
+	})
+	return err
+}
+
+// Closed returns true if the gRPC stream has ended.
+func (t *GRPCTransport) Closed() bool {
+	val := t.closed.Load()
+	if val == nil {
+		return false
+	}
+	return val.(bool)
+}
+
+// Heartbeat does nothing, since the connection of the gRPC stream is kept
+// alive by the keepalive of gRPC.
+func (t *GRPCTransport) Heartbeat(ctx context.Context, interval, timeout int) {
+}
+
+// Receive a message from the gRPC stream. Like Send, returns either a
+// ClosedError or a ConnectionError if unable to receive a message.
+func (t *GRPCTransport) Receive() (*Message, error) {
+	t.readMu.Lock()
+	defer t.readMu.Unlock()
+
+	if t.Closed() {
+		return nil, ClosedError{"the gRPC stream is no longer open"}
+	}
+
+	var frame grpcFrame
+	if err := t.stream.RecvMsg(&frame); err != nil {
+		// The stream is over after any error
+		t.closed.Store(true)
+		if errors.Is(err, io.EOF) {
+			return nil, ClosedError{err.Error()}
+		}
+		return nil, ConnectionError{err.Error()}
+	}
+	atomic.AddInt64(&t.received, int64(len(frame.data)))
+
+	return decodeMessage(t.compression, t.codec, frame.data, t.maxMessageSize)
+}
+
+// Send a message over the gRPC stream. If the stream has ended, returns a
+// ClosedError. Returns a ConnectionError if the stream returns an error.
+func (t *GRPCTransport) Send(m *Message) (err error) {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if t.Closed() {
+		return ClosedError{"the gRPC stream is no longer open"}
+	}
+
+	defer func() {
+		if m.SendCallback != nil {
+			m.SendCallback(err)
+		}
+	}()
+
+	msg, err := encodeMessage(t.compression, t.codec, m)
+	if err != nil {
+		return err
+	}
+	if err := t.stream.SendMsg(&grpcFrame{data: msg}); err != nil {
+		t.closed.Store(true)
+		if errors.Is(err, io.EOF) {
+			return ClosedError{err.Error()}
+		}
+		return ConnectionError{err.Error()}
+	}
+	atomic.AddInt64(&t.sent, int64(len(msg)))
+
+	return nil
+}
+
+// SendCloseMessage ends the gRPC stream, which the peer receives as the end of
+// its messages.
+func (t *GRPCTransport) SendCloseMessage() error {
+	return t.Close()
+}
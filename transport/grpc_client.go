@@ -0,0 +1,133 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	v2 "github.com/sensu/core/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// GRPCKeepaliveTime is the inactivity of the gRPC connections after
+	// which the agents ping the backend.
+	GRPCKeepaliveTime = 30 * time.Second
+
+	// GRPCKeepaliveTimeout is how long the agents wait for the backend to
+	// acknowledge their pings, before they close the gRPC connection.
+	GRPCKeepaliveTimeout = 15 * time.Second
+)
+
+// IsGRPCURL returns true if u is the URL of the gRPC listener of a backend,
+// with the grpc:// scheme, or grpcs:// with TLS.
+func IsGRPCURL(u *url.URL) bool {
+	return u.Scheme == "grpc" || u.Scheme == "grpcs"
+}
+
+// connectGRPC establishes a gRPC stream with the backend of u, and returns it
+// as a Transport, along with the header of its response.
+func connectGRPC(u *url.URL, tlsOpts *v2.TLSOptions, requestHeader http.Header, handshakeTimeout int) (Transport, http.Header, error) {
+	if handshakeTimeout < 1 {
+		handshakeTimeout = 15
+	}
+
+	creds := grpc.WithInsecure()
+	if u.Scheme == "grpcs" {
+		tlsConfig := &tls.Config{}
+		if tlsOpts != nil {
+			var err error
+			if tlsConfig, err = tlsOpts.ToClientTLSConfig(); err != nil {
+				return nil, nil, err
+			}
+		}
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+
+	dialCtx, cancelDial := context.WithTimeout(context.Background(), time.Duration(handshakeTimeout)*time.Second)
+	defer cancelDial()
+	conn, err := grpc.DialContext(dialCtx, u.Host,
+		creds,
+		grpc.WithBlock(),
+		grpc.FailOnNonTempDialError(true),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                GRPCKeepaliveTime,
+			Timeout:             GRPCKeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(grpcMaxMessageSize),
+			grpc.MaxCallSendMsgSize(grpcMaxMessageSize),
+		),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	md := metadata.MD{}
+	for key, values := range requestHeader {
+		md.Append(key, values...)
+	}
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(context.Background(), md))
+	closeConn := func() error {
+		cancel()
+		return conn.Close()
+	}
+	stream, err := conn.NewStream(ctx, &grpcStreamDesc, GRPCMethod, grpc.CallContentSubtype(grpcCodecName))
+	if err != nil {
+		_ = closeConn()
+		return nil, nil, err
+	}
+
+	// The header of the stream is its handshake, which fails if the backend
+	// refuses the agent session
+	header, err := stream.Header()
+	if err == nil && len(header.Get(grpcAcceptedKey)) == 0 {
+		// The response only has trailers, its error is received
+		err = stream.RecvMsg(&grpcFrame{})
+	}
+	if err != nil {
+		trailer := stream.Trailer()
+		_ = closeConn()
+		return nil, nil, grpcHandshakeError(err, trailer)
+	}
+	delete(header, grpcAcceptedKey)
+	respHeader := make(http.Header)
+	for key, values := range header {
+		for _, value := range values {
+			respHeader.Add(key, value)
+		}
+	}
+
+	t, err := newGRPCTransport(stream, respHeader.Get(HeaderKeyCompression), func() error {
+		_ = stream.CloseSend()
+		return closeConn()
+	})
+	if err != nil {
+		_ = closeConn()
+		return nil, nil, err
+	}
+	return t, respHeader, nil
+}
+
+// grpcHandshakeError returns the error of a refused gRPC stream, with the
+// trailer of its response.
+func grpcHandshakeError(err error, trailer metadata.MD) error {
+	st := status.Convert(err)
+	if st.Code() == codes.ResourceExhausted {
+		var retryAfter time.Duration
+		if values := trailer.Get("Retry-After"); len(values) > 0 {
+			retryAfter = parseRetryAfter(values[0])
+		}
+		return TooManyRequestsError{RetryAfter: retryAfter}
+	}
+	return fmt.Errorf("handshake failed with status %s: %s", st.Code(), st.Message())
+}
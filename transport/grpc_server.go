@@ -0,0 +1,220 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcMaxMessageSize is the maximum size of the messages of the gRPC streams.
+// The size of the events is limited by the backend, like for WebSocket.
+const grpcMaxMessageSize = math.MaxInt32
+
+// grpcHandlerKey is the context key of the grpcHandler of a request.
+type grpcHandlerKey struct{}
+
+// grpcHandler starts the session of the gRPC stream of a request.
+type grpcHandler struct {
+	header      http.Header
+	compression string
+	handle      func(Transport)
+	handled     bool
+}
+
+// GRPCServer serves the gRPC streams of the agent sessions, in the HTTP/2
+// requests of an HTTP server, so that they go through the same handlers and
+// middlewares as the WebSocket connections.
+type GRPCServer struct {
+	server *grpc.Server
+}
+
+// NewGRPCServer is used to initialize a new GRPCServer and return a pointer to
+// it.
+func NewGRPCServer() *GRPCServer {
+	s := &GRPCServer{
+		server: grpc.NewServer(grpc.MaxRecvMsgSize(grpcMaxMessageSize), grpc.MaxSendMsgSize(grpcMaxMessageSize)),
+	}
+	stream := grpcStreamDesc
+	stream.Handler = s.connect
+	s.server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: grpcServiceName,
+		HandlerType: (*interface{})(nil),
+		Streams:     []grpc.StreamDesc{stream},
+	}, s)
+	return s
+}
+
+// IsGRPCRequest returns true if r is a gRPC request.
+func IsGRPCRequest(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// Serve serves the gRPC stream of r, an HTTP/2 request of GRPCMethod, as a
+// Transport whose messages are compressed with the compression algorithm, or
+// not compressed if it is empty. The stream starts with header, and handle is
+// called with its Transport. Serve blocks until the stream ends, since the
+// stream is the request, and returns an error if r is not a stream of the
+// agent sessions.
+func (s *GRPCServer) Serve(w http.ResponseWriter, r *http.Request, header http.Header, compression string, handle func(Transport)) error {
+	h := &grpcHandler{
+		header:      header,
+		compression: compression,
+		handle:      handle,
+	}
+	s.server.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), grpcHandlerKey{}, h)))
+	if !h.handled {
+		return errors.New("not a gRPC stream of the agent sessions")
+	}
+	return nil
+}
+
+// connect handles the gRPC streams of the agent sessions, until their
+// transport is closed or the agent ends them.
+func (s *GRPCServer) connect(srv interface{}, stream grpc.ServerStream) error {
+	h, ok := stream.Context().Value(grpcHandlerKey{}).(*grpcHandler)
+	if !ok {
+		return status.Error(codes.Unimplemented, "the agent sessions are served by the agentd handler")
+	}
+	h.handled = true
+
+	md := metadata.Pairs(grpcAcceptedKey, "true")
+	for key, values := range h.header {
+		md.Append(key, values...)
+	}
+	if err := stream.SendHeader(md); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	t, err := newGRPCTransport(stream, h.compression, func() error {
+		close(done)
+		return nil
+	})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	h.handle(t)
+
+	select {
+	case <-done:
+	case <-stream.Context().Done():
+		t.closed.Store(true)
+	}
+	return nil
+}
+
+// GRPCErrors returns a handler that sends the HTTP errors of next, such as
+// the refused agent connections, to the gRPC clients as the gRPC errors they
+// understand. The headers of the errors, such as Retry-After, are kept.
+func GRPCErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsGRPCRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ew := &grpcErrorWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r)
+		ew.finish()
+	})
+}
+
+// grpcErrorWriter passes the gRPC responses through, and buffers the other
+// responses, which are errors, to send them as gRPC errors.
+type grpcErrorWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// grpcResponse returns true if the response is a gRPC response.
+func (w *grpcErrorWriter) grpcResponse() bool {
+	return w.status == 0 && strings.HasPrefix(w.Header().Get("Content-Type"), "application/grpc")
+}
+
+func (w *grpcErrorWriter) WriteHeader(status int) {
+	if w.grpcResponse() {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *grpcErrorWriter) Write(p []byte) (int, error) {
+	if w.grpcResponse() {
+		return w.ResponseWriter.Write(p)
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(p)
+}
+
+// Flush implements http.Flusher, which gRPC requires.
+func (w *grpcErrorWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && w.grpcResponse() {
+		flusher.Flush()
+	}
+}
+
+// finish sends the buffered response as a gRPC error, in a response that
+// only has headers.
+func (w *grpcErrorWriter) finish() {
+	if w.status == 0 {
+		return
+	}
+	header := w.ResponseWriter.Header()
+	header.Del("Content-Length")
+	header.Del("X-Content-Type-Options")
+	header.Set("Content-Type", "application/grpc")
+	header.Set("Grpc-Status", strconv.Itoa(int(grpcCode(w.status))))
+	header.Set("Grpc-Message", encodeGRPCMessage(strings.TrimSpace(w.body.String())))
+	w.ResponseWriter.WriteHeader(http.StatusOK)
+}
+
+// grpcCode returns the gRPC status code of an HTTP status code.
+func grpcCode(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusUpgradeRequired:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// encodeGRPCMessage percent-encodes msg for the Grpc-Message header.
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
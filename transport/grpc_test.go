@@ -0,0 +1,129 @@
+package transport
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGRPCTestServer returns a TLS HTTP/2 server of the gRPC streams, which
+// echoes their messages. The agents named "refused" are refused.
+func newGRPCTestServer(t *testing.T, compression string) *httptest.Server {
+	t.Helper()
+	server := NewGRPCServer()
+	ts := httptest.NewUnstartedServer(GRPCErrors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderKeyAgentName) == "refused" {
+			w.Header().Set("Retry-After", "42")
+			http.Error(w, "the namespace agent session limit is reached", http.StatusTooManyRequests)
+			return
+		}
+		header := make(http.Header)
+		header.Set(HeaderKeyAgentName, r.Header.Get(HeaderKeyAgentName))
+		if compression != "" {
+			header.Set(HeaderKeyCompression, compression)
+		}
+		err := server.Serve(w, r, header, compression, func(conn Transport) {
+			go func() {
+				for {
+					msg, err := conn.Receive()
+					if err != nil {
+						return
+					}
+					if err := conn.Send(msg); err != nil {
+						return
+					}
+				}
+			}()
+		})
+		assert.NoError(t, err)
+	})))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func grpcTestURL(ts *httptest.Server) string {
+	return strings.Replace(ts.URL, "https", "grpcs", 1)
+}
+
+func TestGRPCTransportSendReceive(t *testing.T) {
+	for _, compression := range []string{"", CompressionZstd} {
+		t.Run("compression "+compression, func(t *testing.T) {
+			ts := newGRPCTestServer(t, compression)
+
+			header := make(http.Header)
+			header.Set(HeaderKeyAgentName, "agent1")
+			conn, respHeader, err := Connect(grpcTestURL(ts), &v2.TLSOptions{InsecureSkipVerify: true}, header, 5)
+			require.NoError(t, err)
+			defer conn.Close()
+			assert.Equal(t, "agent1", respHeader.Get(HeaderKeyAgentName))
+			assert.Equal(t, compression, respHeader.Get(HeaderKeyCompression))
+
+			var uncompressed int64
+			for _, payload := range [][]byte{[]byte("small"), bytes.Repeat([]byte("large"), CompressionThreshold)} {
+				require.NoError(t, conn.Send(NewMessage(MessageTypeEvent, payload)))
+				msg, err := conn.Receive()
+				require.NoError(t, err)
+				assert.Equal(t, MessageTypeEvent, msg.Type)
+				assert.Equal(t, payload, msg.Payload)
+				uncompressed += int64(len(Encode(MessageTypeEvent, payload)))
+			}
+
+			// The messages are echoed, and counted once compressed
+			counter := conn.(ByteCounter)
+			assert.Equal(t, counter.BytesSent(), counter.BytesReceived())
+			if compression == "" {
+				assert.Equal(t, uncompressed, counter.BytesSent())
+			} else {
+				assert.Less(t, counter.BytesSent(), uncompressed)
+			}
+
+			require.NoError(t, conn.Close())
+			assert.True(t, conn.Closed())
+			_, err = conn.Receive()
+			assert.IsType(t, ClosedError{}, err)
+		})
+	}
+}
+
+func TestGRPCTransportServerClose(t *testing.T) {
+	server := NewGRPCServer()
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = server.Serve(w, r, nil, "", func(conn Transport) {
+			_ = conn.Send(NewMessage(MessageTypeEvent, []byte("bye")))
+			_ = conn.Close()
+		})
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	conn, _, err := Connect(grpcTestURL(ts), &v2.TLSOptions{InsecureSkipVerify: true}, nil, 5)
+	require.NoError(t, err)
+	defer conn.Close()
+	msg, err := conn.Receive()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bye"), msg.Payload)
+	_, err = conn.Receive()
+	assert.IsType(t, ClosedError{}, err)
+}
+
+func TestConnectGRPCTooManyRequests(t *testing.T) {
+	ts := newGRPCTestServer(t, "")
+
+	header := make(http.Header)
+	header.Set(HeaderKeyAgentName, "refused")
+	_, _, err := Connect(grpcTestURL(ts), &v2.TLSOptions{InsecureSkipVerify: true}, header, 5)
+	require.ErrorIs(t, err, ErrTooManyRequests)
+	var tooManyRequests TooManyRequestsError
+	require.ErrorAs(t, err, &tooManyRequests)
+	assert.Equal(t, 42*time.Second, tooManyRequests.RetryAfter)
+}
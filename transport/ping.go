@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrPongTimeout is returned by Ping when the pong of the ping frame is not
+// received before the timeout.
+var ErrPongTimeout = errors.New("timed out waiting for the pong")
+
+// Pinger is implemented by the transports that measure their round-trip time
+// with ping frames.
+type Pinger interface {
+	// Ping sends a ping frame and waits for its pong, for up to timeout, and
+	// returns the round-trip time. The pong is only received while the
+	// transport is receiving messages.
+	Ping(ctx context.Context, timeout time.Duration) (time.Duration, error)
+}
+
+// Ping sends a ping frame with a unique payload, and waits for the pong that
+// echoes it.
+func (t *WebSocketTransport) Ping(ctx context.Context, timeout time.Duration) (time.Duration, error) {
+	t.pongOnce.Do(t.setPongHandler)
+
+	data := strconv.FormatUint(atomic.AddUint64(&t.pingSeq, 1), 10)
+	pong := make(chan struct{})
+	t.pongs.Store(data, pong)
+	defer t.pongs.Delete(data)
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	if err := t.Connection.WriteControl(websocket.PingMessage, []byte(data), deadline); err != nil {
+		return 0, ConnectionError{Message: err.Error()}
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-pong:
+		return time.Since(start), nil
+	case <-timer.C:
+		return 0, ErrPongTimeout
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// setPongHandler sets the pong handler of the connection, which notifies the
+// pings waiting for their pong and extends the read deadline of the
+// heartbeat, if any. It is set once, since the connection has a single pong
+// handler.
+func (t *WebSocketTransport) setPongHandler() {
+	t.Connection.SetPongHandler(func(data string) error {
+		if pong, ok := t.pongs.LoadAndDelete(data); ok {
+			close(pong.(chan struct{}))
+		}
+		if wait, ok := t.pongWait.Load().(time.Duration); ok {
+			logger.Debugf("pong received from the backend, setting the read deadline to %d", time.Now().Add(wait).Unix())
+			return t.Connection.SetReadDeadline(time.Now().Add(wait))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,70 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name string
+		// receive is true if the agent receives messages, and therefore
+		// answers the pings
+		receive bool
+		wantErr error
+	}{
+		{name: "pong", receive: true},
+		{name: "pong timeout", receive: false, wantErr: ErrPongTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan struct{})
+			server := NewServer()
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer close(done)
+				transport, err := server.Serve(w, r)
+				require.NoError(t, err)
+				defer transport.Close()
+				// The pongs are received while receiving messages
+				go func() {
+					for {
+						if _, err := transport.Receive(); err != nil {
+							return
+						}
+					}
+				}()
+				pinger, ok := transport.(Pinger)
+				require.True(t, ok)
+				for i := 0; i < 3; i++ {
+					rtt, err := pinger.Ping(context.Background(), 500*time.Millisecond)
+					assert.Equal(t, tt.wantErr, err)
+					if tt.wantErr == nil {
+						assert.Greater(t, rtt, time.Duration(0))
+					}
+				}
+			}))
+			defer ts.Close()
+
+			clientTransport, _, err := Connect(strings.Replace(ts.URL, "http", "ws", 1), nil, nil, 5)
+			require.NoError(t, err)
+			defer clientTransport.Close()
+			if tt.receive {
+				go func() {
+					for {
+						if _, err := clientTransport.Receive(); err != nil {
+							return
+						}
+					}
+				}()
+			}
+			<-done
+		})
+	}
+}
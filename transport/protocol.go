@@ -0,0 +1,85 @@
+package transport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev2 "github.com/sensu/core/v2"
+)
+
+const (
+	// HeaderKeyProtocolVersion is the HTTP header with which the Agent
+	// offers the latest protocol version it speaks, and the Backend tells the
+	// protocol version of the session.
+	HeaderKeyProtocolVersion = "Sensu-ProtocolVersion"
+
+	// ProtocolVersionLegacy is the protocol version of the agents and
+	// backends that don't send the protocol version header. Its messages are
+	// the ones that existed before the protocol was versioned, including the
+	// messages of the capabilities negotiated with their own header.
+	ProtocolVersionLegacy = 1
+
+	// ProtocolVersion2 is the first negotiated protocol version.
+	ProtocolVersion2 = 2
+
+	// ProtocolVersion3 introduces the event batch messages.
+	ProtocolVersion3 = 3
+
+	// ProtocolVersion is the latest protocol version, spoken by this build.
+	ProtocolVersion = ProtocolVersion3
+)
+
+// messageTypeVersions are the protocol versions in which the message types
+// were introduced. Message types that are not in the map are assumed to be
+// introduced in the latest protocol version, so that they are never sent to
+// peers that could not handle them.
+var messageTypeVersions = map[string]int{
+	MessageTypeKeepalive:     ProtocolVersionLegacy,
+	MessageTypeEvent:         ProtocolVersionLegacy,
+	MessageTypeEntityConfig:  ProtocolVersionLegacy,
+	MessageTypeBackoff:       ProtocolVersionLegacy,
+	MessageTypeEventAck:      ProtocolVersionLegacy,
+	MessageTypeThrottle:      ProtocolVersionLegacy,
+	MessageTypeEventRejected: ProtocolVersionLegacy,
+	corev2.CheckRequestType:  ProtocolVersionLegacy,
+	MessageTypeEventBatch:    ProtocolVersion3,
+}
+
+// ParseProtocolVersion parses the protocol version header. Peers that don't
+// send the header speak ProtocolVersionLegacy.
+func ParseProtocolVersion(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return ProtocolVersionLegacy, nil
+	}
+	version, err := strconv.Atoi(header)
+	if err != nil || version < ProtocolVersionLegacy {
+		return 0, fmt.Errorf("invalid protocol version: %q", header)
+	}
+	return version, nil
+}
+
+// NegotiateProtocolVersion returns the protocol version of a session with a
+// peer whose latest protocol version is offered: the latest version spoken by
+// both. It returns an error if offered is older than min, the oldest protocol
+// version that is served.
+func NegotiateProtocolVersion(offered, min int) (int, error) {
+	if offered < min {
+		return 0, fmt.Errorf("protocol version %d is not supported, the oldest supported protocol version is %d", offered, min)
+	}
+	if offered > ProtocolVersion {
+		return ProtocolVersion, nil
+	}
+	return offered, nil
+}
+
+// SupportsMessageType returns true if the messages of msgType can be sent in
+// the sessions of the protocol version.
+func SupportsMessageType(version int, msgType string) bool {
+	since, ok := messageTypeVersions[msgType]
+	if !ok {
+		since = ProtocolVersion
+	}
+	return version >= since
+}
@@ -0,0 +1,77 @@
+package transport
+
+import (
+	"testing"
+
+	corev2 "github.com/sensu/core/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{name: "missing header", header: "", want: ProtocolVersionLegacy},
+		{name: "version", header: "2", want: 2},
+		{name: "spaces", header: " 2 ", want: 2},
+		{name: "newer version", header: "42", want: 42},
+		{name: "not a number", header: "v2", wantErr: true},
+		{name: "zero", header: "0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProtocolVersion(tt.header)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		offered int
+		min     int
+		want    int
+		wantErr bool
+	}{
+		{name: "legacy agent", offered: ProtocolVersionLegacy, min: ProtocolVersionLegacy, want: ProtocolVersionLegacy},
+		{name: "same version", offered: ProtocolVersion, min: ProtocolVersionLegacy, want: ProtocolVersion},
+		{name: "newer agent", offered: ProtocolVersion + 1, min: ProtocolVersionLegacy, want: ProtocolVersion},
+		{name: "no minimum", offered: ProtocolVersionLegacy, min: 0, want: ProtocolVersionLegacy},
+		{name: "agent older than the minimum", offered: ProtocolVersionLegacy, min: ProtocolVersion2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NegotiateProtocolVersion(tt.offered, tt.min)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSupportsMessageType(t *testing.T) {
+	assert.True(t, SupportsMessageType(ProtocolVersionLegacy, MessageTypeEvent))
+	assert.True(t, SupportsMessageType(ProtocolVersionLegacy, corev2.CheckRequestType))
+	assert.True(t, SupportsMessageType(ProtocolVersion, MessageTypeEventAck))
+	assert.False(t, SupportsMessageType(ProtocolVersion2, MessageTypeEventBatch))
+	assert.True(t, SupportsMessageType(ProtocolVersion3, MessageTypeEventBatch))
+
+	// Unknown message types are only sent with the latest protocol version
+	assert.False(t, SupportsMessageType(ProtocolVersionLegacy, "unknown"))
+	assert.True(t, SupportsMessageType(ProtocolVersion, "unknown"))
+
+	// Sessions without a protocol version don't send anything
+	assert.False(t, SupportsMessageType(0, MessageTypeEvent))
+}
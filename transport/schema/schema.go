@@ -0,0 +1,245 @@
+// Package schema describes the protobuf schema of the messages exchanged
+// between agents and backends, so that their wire compatibility across Sensu
+// versions can be checked.
+//
+// The schema of the current version is compared to a snapshot recorded in
+// testdata by the tests of the package, which fail when a field of the
+// snapshot is removed, renumbered, renamed or retyped. Compatible changes,
+// like new fields, are recorded with go generate. The fingerprint of the
+// schema is sent by agents in their handshake, as a runtime marker of the
+// schema they were built with.
+//
+//go:generate go test -run TestSnapshot -update
+package schema
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	golangproto "github.com/golang/protobuf/proto"
+	corev2 "github.com/sensu/core/v2"
+	corev3 "github.com/sensu/core/v3"
+)
+
+// Message is a protobuf message that describes itself.
+type Message = descriptor.Message
+
+// WireMessages are the messages exchanged between agents and backends. The
+// messages they reference are part of the schema.
+var WireMessages = []Message{
+	&corev2.Event{},
+	&corev2.CheckRequest{},
+	&corev3.EntityConfig{},
+}
+
+// Field is a field of a message of the schema.
+type Field struct {
+	Number   int32  `json:"number"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Label    string `json:"label"`
+	TypeName string `json:"type_name,omitempty"`
+}
+
+// Schema are the fields of the messages of a schema, by full message name.
+// The fields are sorted by number.
+type Schema map[string][]Field
+
+var (
+	current     Schema
+	currentErr  error
+	currentOnce sync.Once
+)
+
+// Current returns the schema of the WireMessages of this build.
+func Current() (Schema, error) {
+	currentOnce.Do(func() {
+		current, currentErr = Of(WireMessages...)
+	})
+	return current, currentErr
+}
+
+// Fingerprint returns the fingerprint of the schema of this build, or an
+// empty string if the schema can't be described.
+func Fingerprint() string {
+	s, err := Current()
+	if err != nil {
+		return ""
+	}
+	return s.Fingerprint()
+}
+
+// Of returns the schema of msgs and of the messages they reference.
+func Of(msgs ...Message) (Schema, error) {
+	files := make(map[string]*descriptor.FileDescriptorProto)
+	types := make(map[string]*descriptor.DescriptorProto)
+	schema := make(Schema)
+	var queue []string
+	for _, msg := range msgs {
+		fd, md := descriptor.ForMessage(msg)
+		if err := indexFile(fd, files, types); err != nil {
+			return nil, err
+		}
+		queue = append(queue, fullName(fd.GetPackage(), md.GetName()))
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := schema[name]; ok {
+			continue
+		}
+		md, ok := types[name]
+		if !ok {
+			return nil, fmt.Errorf("message %s is not described", name)
+		}
+		fields := make([]Field, 0, len(md.GetField()))
+		for _, f := range md.GetField() {
+			field := Field{
+				Number:   f.GetNumber(),
+				Name:     f.GetName(),
+				Type:     f.GetType().String(),
+				Label:    f.GetLabel().String(),
+				TypeName: strings.TrimPrefix(f.GetTypeName(), "."),
+			}
+			fields = append(fields, field)
+			if f.GetType() == descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+				queue = append(queue, field.TypeName)
+			}
+		}
+		sort.Slice(fields, func(i, j int) bool {
+			return fields[i].Number < fields[j].Number
+		})
+		schema[name] = fields
+	}
+	return schema, nil
+}
+
+// indexFile indexes the messages of fd and of its dependencies by full name.
+func indexFile(fd *descriptor.FileDescriptorProto, files map[string]*descriptor.FileDescriptorProto, types map[string]*descriptor.DescriptorProto) error {
+	if _, ok := files[fd.GetName()]; ok {
+		return nil
+	}
+	files[fd.GetName()] = fd
+	for _, md := range fd.GetMessageType() {
+		indexMessage(fullName(fd.GetPackage(), ""), md, types)
+	}
+	for _, dep := range fd.GetDependency() {
+		depfd, err := loadFile(dep)
+		if err != nil {
+			// The dependencies that only declare options, like gogo.proto,
+			// may be registered under another name. A message of a missing
+			// dependency that is part of the schema fails Of.
+			continue
+		}
+		if err := indexFile(depfd, files, types); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func indexMessage(prefix string, md *descriptor.DescriptorProto, types map[string]*descriptor.DescriptorProto) {
+	name := fullName(prefix, md.GetName())
+	types[name] = md
+	for _, nested := range md.GetNestedType() {
+		indexMessage(name, nested, types)
+	}
+}
+
+// loadFile loads the descriptor of the registered protobuf file name. The
+// files are registered with the golang/protobuf registry by the generated
+// code of the Sensu types, and with the gogo/protobuf registry otherwise.
+func loadFile(name string) (*descriptor.FileDescriptorProto, error) {
+	gz := golangproto.FileDescriptor(name)
+	if gz == nil {
+		gz = proto.FileDescriptor(name)
+	}
+	if gz == nil {
+		return nil, fmt.Errorf("protobuf file %s is not registered", name)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return nil, fmt.Errorf("protobuf file %s: %s", name, err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf file %s: %s", name, err)
+	}
+	fd := new(descriptor.FileDescriptorProto)
+	if err := proto.Unmarshal(b, fd); err != nil {
+		return nil, fmt.Errorf("protobuf file %s: %s", name, err)
+	}
+	return fd, nil
+}
+
+func fullName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if name == "" {
+		return prefix
+	}
+	return prefix + "." + name
+}
+
+// Fingerprint returns a short hash of the schema, which is the same for the
+// same schema.
+func (s Schema) Fingerprint() string {
+	// The keys of the JSON objects are sorted, and the fields are sorted by
+	// number, so that the encoding of the schema is stable.
+	b, _ := json.Marshal(s)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// CheckCompatible returns an error describing every change of s that breaks
+// the wire compatibility with the older schema old: the messages of old that
+// are removed, and the fields of old that are removed, renumbered, renamed or
+// retyped. Fields are compared by name as well as by number, because the
+// messages are also exchanged as JSON.
+func (s Schema) CheckCompatible(old Schema) error {
+	var problems []string
+	names := make([]string, 0, len(old))
+	for name := range old {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields, ok := s[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("message %s was removed", name))
+			continue
+		}
+		byNumber := make(map[int32]Field, len(fields))
+		for _, f := range fields {
+			byNumber[f.Number] = f
+		}
+		for _, oldField := range old[name] {
+			f, ok := byNumber[oldField.Number]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("field %s.%s (%d) was removed", name, oldField.Name, oldField.Number))
+			case f.Name != oldField.Name:
+				problems = append(problems, fmt.Sprintf("field %s.%s (%d) was renamed to %s", name, oldField.Name, oldField.Number, f.Name))
+			case f.Type != oldField.Type || f.TypeName != oldField.TypeName:
+				problems = append(problems, fmt.Sprintf("field %s.%s (%d) changed type", name, oldField.Name, oldField.Number))
+			case f.Label != oldField.Label:
+				problems = append(problems, fmt.Sprintf("field %s.%s (%d) changed label", name, oldField.Name, oldField.Number))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("incompatible wire schema: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package schema
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "record the compatible changes of the wire schema in the snapshot")
+
+var snapshotPath = filepath.Join("testdata", "schema.json")
+
+func TestSnapshot(t *testing.T) {
+	current, err := Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(snapshotPath)
+	if os.IsNotExist(err) && !*update {
+		t.Skipf("no wire schema snapshot, record it with go generate ./transport/schema")
+	} else if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if b != nil {
+		var snapshot Schema
+		if err := json.Unmarshal(b, &snapshot); err != nil {
+			t.Fatal(err)
+		}
+		if err := current.CheckCompatible(snapshot); err != nil {
+			t.Fatal(err)
+		}
+		if !*update && current.Fingerprint() != snapshot.Fingerprint() {
+			t.Fatal("the wire schema has compatible changes, record them with go generate ./transport/schema")
+		}
+	}
+	if *update {
+		b, err := json.MarshalIndent(current, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(snapshotPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(snapshotPath, append(b, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	current, err := Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sensu.core.v2.Event", "sensu.core.v2.CheckRequest", "sensu.core.v2.Entity"} {
+		if _, ok := current[name]; !ok {
+			t.Errorf("message %s is not part of the wire schema", name)
+		}
+	}
+	if Fingerprint() == "" {
+		t.Error("expected a fingerprint")
+	}
+}
+
+func TestCheckCompatible(t *testing.T) {
+	old := Schema{
+		"sensu.Event": {
+			{Number: 1, Name: "timestamp", Type: "TYPE_INT64", Label: "LABEL_OPTIONAL"},
+			{Number: 2, Name: "entity", Type: "TYPE_MESSAGE", Label: "LABEL_OPTIONAL", TypeName: "sensu.Entity"},
+		},
+		"sensu.Entity": {
+			{Number: 1, Name: "subscriptions", Type: "TYPE_STRING", Label: "LABEL_REPEATED"},
+		},
+	}
+	tests := []struct {
+		name    string
+		schema  Schema
+		wantErr string
+	}{
+		{
+			name:   "same schema",
+			schema: old,
+		},
+		{
+			name: "new field",
+			schema: Schema{
+				"sensu.Event": {
+					{Number: 1, Name: "timestamp", Type: "TYPE_INT64", Label: "LABEL_OPTIONAL"},
+					{Number: 2, Name: "entity", Type: "TYPE_MESSAGE", Label: "LABEL_OPTIONAL", TypeName: "sensu.Entity"},
+					{Number: 3, Name: "id", Type: "TYPE_BYTES", Label: "LABEL_OPTIONAL"},
+				},
+				"sensu.Entity": old["sensu.Entity"],
+			},
+		},
+		{
+			name: "removed field",
+			schema: Schema{
+				"sensu.Event": {
+					{Number: 2, Name: "entity", Type: "TYPE_MESSAGE", Label: "LABEL_OPTIONAL", TypeName: "sensu.Entity"},
+				},
+				"sensu.Entity": old["sensu.Entity"],
+			},
+			wantErr: "field sensu.Event.timestamp (1) was removed",
+		},
+		{
+			name: "renamed field",
+			schema: Schema{
+				"sensu.Event": {
+					{Number: 1, Name: "time", Type: "TYPE_INT64", Label: "LABEL_OPTIONAL"},
+					{Number: 2, Name: "entity", Type: "TYPE_MESSAGE", Label: "LABEL_OPTIONAL", TypeName: "sensu.Entity"},
+				},
+				"sensu.Entity": old["sensu.Entity"],
+			},
+			wantErr: "field sensu.Event.timestamp (1) was renamed to time",
+		},
+		{
+			name: "retyped field",
+			schema: Schema{
+				"sensu.Event": {
+					{Number: 1, Name: "timestamp", Type: "TYPE_STRING", Label: "LABEL_OPTIONAL"},
+					{Number: 2, Name: "entity", Type: "TYPE_MESSAGE", Label: "LABEL_OPTIONAL", TypeName: "sensu.Entity"},
+				},
+				"sensu.Entity": old["sensu.Entity"],
+			},
+			wantErr: "field sensu.Event.timestamp (1) changed type",
+		},
+		{
+			name: "relabeled field",
+			schema: Schema{
+				"sensu.Event": old["sensu.Event"],
+				"sensu.Entity": {
+					{Number: 1, Name: "subscriptions", Type: "TYPE_STRING", Label: "LABEL_OPTIONAL"},
+				},
+			},
+			wantErr: "field sensu.Entity.subscriptions (1) changed label",
+		},
+		{
+			name: "removed message",
+			schema: Schema{
+				"sensu.Event": old["sensu.Event"],
+			},
+			wantErr: "message sensu.Entity was removed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.CheckCompatible(old)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := Schema{"sensu.Event": {{Number: 1, Name: "timestamp", Type: "TYPE_INT64", Label: "LABEL_OPTIONAL"}}}
+	b := Schema{"sensu.Event": {{Number: 1, Name: "timestamp", Type: "TYPE_INT64", Label: "LABEL_OPTIONAL"}}}
+	c := Schema{"sensu.Event": {{Number: 1, Name: "timestamp", Type: "TYPE_INT32", Label: "LABEL_OPTIONAL"}}}
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("expected the same fingerprint for the same schema")
+	}
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Error("expected different fingerprints for different schemas")
+	}
+}
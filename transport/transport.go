@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -27,6 +28,31 @@ const (
 	// MessageTypeEntityConfig is the message type sent for entity config updates
 	MessageTypeEntityConfig = "entity_config"
 
+	// MessageTypeBackoff is the message type sent to agents whose events are
+	// rejected by the rate limits of the backend. Its payload is the number of
+	// seconds for which the agent should back off.
+	MessageTypeBackoff = "backoff"
+
+	// MessageTypeEventAck is the message type sent to agents once one of
+	// their events is published by the backend. The payload is the UUID of
+	// the event.
+	MessageTypeEventAck = "event_ack"
+
+	// MessageTypeThrottle is the message type sent to agents while the
+	// backend is saturated, so that they slow down their events. Its payload
+	// is a JSON Throttle.
+	MessageTypeThrottle = "throttle"
+
+	// MessageTypeEventRejected is the message type sent to agents when one
+	// of their events is rejected by the backend. Its payload is a JSON
+	// EventRejection.
+	MessageTypeEventRejected = "event_rejected"
+
+	// MessageTypeEventBatch is the message type sent by agents to pack many
+	// small events into one message. Its payload is encoded with
+	// EncodeEventBatch.
+	MessageTypeEventBatch = "event_batch"
+
 	// HeaderKeyAgentName is the HTTP request header specifying the Agent name
 	HeaderKeyAgentName = "Sensu-AgentName"
 
@@ -38,8 +64,60 @@ const (
 
 	// HeaderKeySubscriptions is the HTTP request header specifying the Agent Subscriptions
 	HeaderKeySubscriptions = "Sensu-Subscriptions"
+
+	// HeaderKeyAgentVersion is the HTTP request header specifying the Agent version
+	HeaderKeyAgentVersion = "Sensu-AgentVersion"
+
+	// HeaderKeyWireSchema is the HTTP request header specifying the
+	// fingerprint of the wire schema the Agent was built with
+	HeaderKeyWireSchema = "Sensu-WireSchema"
+
+	// HeaderKeyEventAcks is the HTTP header with which the Agent asks for
+	// the acknowledgement of its events, and the Backend agrees to send them
+	HeaderKeyEventAcks = "Sensu-EventAcks"
+
+	// HeaderKeyThrottle is the HTTP header with which the Agent tells that it
+	// handles throttle messages, and the Backend agrees to send them
+	HeaderKeyThrottle = "Sensu-Throttle"
+
+	// HeaderKeyEventRejections is the HTTP header with which the Agent tells
+	// that it handles event rejection messages, and the Backend agrees to
+	// send them
+	HeaderKeyEventRejections = "Sensu-EventRejections"
+
+	// HeaderKeyCompression is the HTTP header with which the Agent offers
+	// the compression algorithms of its messages, in order of preference, and
+	// the Backend picks the one of the session
+	HeaderKeyCompression = "Sensu-Compression"
 )
 
+// Throttle is the payload of the throttle messages.
+type Throttle struct {
+	// Rate is the maximum number of events per second the agent sends.
+	Rate float64 `json:"rate"`
+
+	// Seconds is the number of seconds for which the rate applies.
+	Seconds int `json:"seconds"`
+}
+
+// EventRejectionTooLarge is the reason of the events rejected because they
+// are larger than the maximum event size of the backend.
+const EventRejectionTooLarge = "too_large"
+
+// EventRejection is the payload of the event rejection messages.
+type EventRejection struct {
+	// Reason is the reason why the event was rejected.
+	Reason string `json:"reason"`
+
+	// Size is the size of the event as received, in bytes, or zero if the
+	// event only exceeded the maximum size once decompressed.
+	Size int `json:"size"`
+
+	// MaxSize is the maximum size of the events accepted by the backend, in
+	// bytes.
+	MaxSize int `json:"max_size"`
+}
+
 // A ClosedError is returned when Receive or Send is called on a closed
 // Transport.
 type ClosedError struct {
@@ -60,6 +138,27 @@ func (e ConnectionError) Error() string {
 	return fmt.Sprintf("Connection error: %s", e.Message)
 }
 
+// A MessageTooLargeError is returned by Receive when the payload of a
+// message exceeds the maximum message size of the transport, either as
+// received or once decompressed. The message is dropped, but the transport
+// can still be used.
+type MessageTooLargeError struct {
+	// Type is the type of the message.
+	Type string
+
+	// Size is the size of the payload of the message as received, in bytes,
+	// or zero if it only exceeded the maximum message size once
+	// decompressed.
+	Size int
+
+	// MaxSize is the maximum message size, in bytes.
+	MaxSize int
+}
+
+func (e MessageTooLargeError) Error() string {
+	return fmt.Sprintf("%s message exceeds the maximum message size of %d bytes", e.Type, e.MaxSize)
+}
+
 // Encode a message to be sent over a websocket channel
 func Encode(msgType string, payload []byte) []byte {
 	buf := []byte(msgType + "\n")
@@ -79,6 +178,54 @@ func Decode(payload []byte) (string, []byte, error) {
 	return string(msgType), msg, nil
 }
 
+// encodeMessage encodes m to be sent by a transport whose messages are
+// compressed with c, or not compressed if c is nil.
+func encodeMessage(compression string, c codec, m *Message) ([]byte, error) {
+	if c == nil {
+		return Encode(m.Type, m.Payload), nil
+	}
+	msg, err := encodeCompressed(compression, c, m.Type, m.Payload)
+	if err != nil {
+		return nil, err
+	}
+	compressedBytes.WithLabelValues(compression, "sent").Add(float64(len(msg)))
+	uncompressedBytes.WithLabelValues(compression, "sent").Add(float64(len(m.Type) + len(sep) + len(m.Payload)))
+	return msg, nil
+}
+
+// decodeMessage decodes a message received by a transport whose messages are
+// compressed with c, or not compressed if c is nil. The messages whose
+// payload is larger than max bytes as received are dropped before their
+// payload is decompressed, and the compressed payloads are not decompressed
+// beyond max bytes.
+func decodeMessage(compression string, c codec, p []byte, max int) (*Message, error) {
+	if max > 0 {
+		if msgType, payload, err := Decode(p); err == nil && len(payload) > max {
+			if i := strings.Index(msgType, compressionSep); i >= 0 {
+				msgType = msgType[:i]
+			}
+			return nil, MessageTooLargeError{Type: msgType, Size: len(payload), MaxSize: max}
+		}
+	}
+	if c == nil {
+		msgType, payload, err := Decode(p)
+		if err != nil {
+			return nil, err
+		}
+		return NewMessage(msgType, payload), nil
+	}
+	msgType, payload, msgCompression, err := decodeCompressed(p, max)
+	if err != nil {
+		return nil, err
+	}
+	if msgCompression == "" {
+		msgCompression = compression
+	}
+	compressedBytes.WithLabelValues(msgCompression, "received").Add(float64(len(p)))
+	uncompressedBytes.WithLabelValues(msgCompression, "received").Add(float64(len(msgType) + len(sep) + len(payload)))
+	return NewMessage(msgType, payload), nil
+}
+
 // A Message is a tuple of a message type (i.e. channel) and a byte-array
 // payload to be sent across the transport.
 type Message struct {
@@ -122,13 +269,61 @@ type Transport interface {
 	SendCloseMessage() error
 }
 
+// A ByteCounter is a Transport that counts the bytes of the messages it sent
+// and received, as they were written to and read from its connection, once
+// compressed.
+type ByteCounter interface {
+	// BytesSent returns the number of bytes of the messages sent.
+	BytesSent() int64
+
+	// BytesReceived returns the number of bytes of the messages received.
+	BytesReceived() int64
+}
+
+// byteCounts implements ByteCounter for the transports. It must be at the
+// start of their struct, for the 64-bit alignment of its counts.
+type byteCounts struct {
+	sent     int64
+	received int64
+}
+
+// BytesSent implements ByteCounter.
+func (c *byteCounts) BytesSent() int64 {
+	return atomic.LoadInt64(&c.sent)
+}
+
+// BytesReceived implements ByteCounter.
+func (c *byteCounts) BytesReceived() int64 {
+	return atomic.LoadInt64(&c.received)
+}
+
 // A WebSocketTransport is a connection between sensu Agents and Backends over
 // WebSocket.
 type WebSocketTransport struct {
-	Connection *websocket.Conn
-	closed     atomic.Value
-	readMu     sync.Mutex
-	writeMu    sync.Mutex
+	// pingSeq numbers the pings. It is first, followed by the byte counts,
+	// for their 64-bit alignment.
+	pingSeq uint64
+	byteCounts
+
+	Connection  *websocket.Conn
+	closed      atomic.Value
+	readMu      sync.Mutex
+	writeMu     sync.Mutex
+	compression string
+	codec       codec
+
+	// maxMessageSize is the maximum size of the payloads of the messages,
+	// as received and once decompressed, or zero for no maximum size as
+	// received and MaxDecompressedSize once decompressed.
+	maxMessageSize int
+
+	// pongOnce sets the pong handler of the connection, shared by the
+	// heartbeat and the pings. pongWait is the read deadline extension of
+	// the heartbeat, and pongs are the pings waiting for their pong, by
+	// payload.
+	pongOnce sync.Once
+	pongWait atomic.Value
+	pongs    sync.Map
 }
 
 // NewTransport creates an initialized Transport and return its pointer.
@@ -138,6 +333,32 @@ func NewTransport(conn *websocket.Conn) Transport {
 	}
 }
 
+// NewCompressedTransport creates an initialized Transport whose messages are
+// compressed with the compression algorithm, or not compressed if it is
+// empty.
+func NewCompressedTransport(conn *websocket.Conn, compression string) (Transport, error) {
+	if compression == "" {
+		return NewTransport(conn), nil
+	}
+	c, err := newCodec(compression)
+	if err != nil {
+		return nil, err
+	}
+	return &WebSocketTransport{
+		Connection:  conn,
+		compression: compression,
+		codec:       c,
+	}, nil
+}
+
+// SetMaxMessageSize sets the maximum size of the payloads of the messages
+// received by the transport, as received and once decompressed, in bytes.
+// If size is zero, the payloads are only limited to MaxDecompressedSize once
+// decompressed. It must be called before Receive.
+func (t *WebSocketTransport) SetMaxMessageSize(size int) {
+	t.maxMessageSize = size
+}
+
 // NewMessage creates a new Message.
 func NewMessage(msgType string, payload []byte) *Message {
 	return &Message{
@@ -217,10 +438,8 @@ func (t *WebSocketTransport) Heartbeat(ctx context.Context, interval, timeout in
 	}()
 
 	_ = t.Connection.SetReadDeadline(time.Now().Add(pongWait))
-	t.Connection.SetPongHandler(func(string) error {
-		logger.Debugf("pong received from the backend, setting the read deadline to %d", time.Now().Add(pongWait).Unix())
-		return t.Connection.SetReadDeadline(time.Now().Add(pongWait))
-	})
+	t.pongWait.Store(pongWait)
+	t.pongOnce.Do(t.setPongHandler)
 }
 
 // Receive a message over the websocket connection. Like Send, returns either
@@ -242,14 +461,9 @@ func (t *WebSocketTransport) Receive() (*Message, error) {
 		}
 		return nil, ConnectionError{err.Error()}
 	}
+	atomic.AddInt64(&t.received, int64(len(p)))
 
-	msgType, payload, err := Decode(p)
-	if err != nil {
-		return nil, err
-	}
-
-	msg := NewMessage(msgType, payload)
-	return msg, nil
+	return decodeMessage(t.compression, t.codec, p, t.maxMessageSize)
 }
 
 // Send a message over the websocket connection. If the connection has been
@@ -268,7 +482,10 @@ func (t *WebSocketTransport) Send(m *Message) (err error) {
 		}
 	}()
 
-	msg := Encode(m.Type, m.Payload)
+	msg, err := encodeMessage(t.compression, t.codec, m)
+	if err != nil {
+		return err
+	}
 	if err := t.Connection.WriteMessage(websocket.BinaryMessage, msg); err != nil {
 		// If we get _any_ error, let's just considered the connection closed,
 		// because it's _really_ hard to figure out what errors from the
@@ -280,6 +497,7 @@ func (t *WebSocketTransport) Send(m *Message) (err error) {
 		}
 		return ConnectionError{err.Error()}
 	}
+	atomic.AddInt64(&t.sent, int64(len(msg)))
 
 	return nil
 }
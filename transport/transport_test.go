@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -44,6 +45,7 @@ func TestTransportSendReceive(t *testing.T) {
 		Payload: msgBytes,
 	})
 	assert.NoError(t, err)
+	assert.Equal(t, int64(len(Encode("testMessageType", msgBytes))), clientTransport.(ByteCounter).BytesSent())
 
 	<-done
 }
@@ -129,3 +131,17 @@ func BenchmarkEncode32k(b *testing.B) {
 func BenchmarkEncode128k(b *testing.B) {
 	benchmarkEncode(128*1024, b)
 }
+
+func TestConnectTooManyRequests(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "42")
+		http.Error(w, "the namespace agent session limit is reached", http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	_, _, err := Connect(strings.Replace(ts.URL, "http", "ws", 1), nil, nil, 5)
+	require.ErrorIs(t, err, ErrTooManyRequests)
+	var tooManyRequests TooManyRequestsError
+	require.ErrorAs(t, err, &tooManyRequests)
+	assert.Equal(t, 42*time.Second, tooManyRequests.RetryAfter)
+}
@@ -21,3 +21,58 @@ func (s *SyncBuffer) String() string {
 	defer s.mu.Unlock()
 	return s.buf.String()
 }
+
+// TailBuffer is a buffer safe for concurrent use that only retains the last
+// bytes written to it, so that the end of the output of a command, where its
+// errors usually are, is kept when the output is too large.
+type TailBuffer struct {
+	buf     []byte
+	max     int
+	written int64
+	mu      sync.Mutex
+}
+
+// NewTailBuffer returns a buffer that retains the last max bytes written to
+// it. The buffer retains everything if max is zero or lower.
+func NewTailBuffer(max int) *TailBuffer {
+	return &TailBuffer{max: max}
+}
+
+func (t *TailBuffer) Write(p []byte) (n int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.written += int64(len(p))
+	if t.max <= 0 {
+		t.buf = append(t.buf, p...)
+		return len(p), nil
+	}
+	n = len(p)
+	if len(p) > t.max {
+		p = p[len(p)-t.max:]
+	}
+	t.buf = append(t.buf, p...)
+	// The retained bytes are moved back to the start of the buffer once it
+	// holds twice as many, so that its size stays bounded
+	if len(t.buf) > 2*t.max {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-t.max:]...)
+	}
+	return n, nil
+}
+
+// Bytes returns the retained bytes, and the number of bytes written that were
+// not retained.
+func (t *TailBuffer) Bytes() ([]byte, int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tail := t.buf
+	if t.max > 0 && len(tail) > t.max {
+		tail = tail[len(tail)-t.max:]
+	}
+	return append([]byte(nil), tail...), t.written - int64(len(tail))
+}
+
+// String returns the retained bytes as a string.
+func (t *TailBuffer) String() string {
+	tail, _ := t.Bytes()
+	return string(tail)
+}
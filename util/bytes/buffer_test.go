@@ -0,0 +1,33 @@
+package bytes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailBuffer(t *testing.T) {
+	buf := NewTailBuffer(10)
+	for i := 0; i < 10; i++ {
+		_, _ = buf.Write([]byte("0123456"))
+	}
+	tail, truncated := buf.Bytes()
+	assert.Equal(t, "4560123456", string(tail))
+	assert.Equal(t, int64(60), truncated)
+
+	// Writes larger than the buffer only keep their end
+	n, err := buf.Write([]byte(strings.Repeat("x", 20) + "the error"))
+	assert.NoError(t, err)
+	assert.Equal(t, 29, n)
+	assert.Equal(t, "xthe error", buf.String())
+}
+
+func TestTailBufferUnlimited(t *testing.T) {
+	buf := NewTailBuffer(0)
+	_, _ = buf.Write([]byte("foo"))
+	_, _ = buf.Write([]byte("bar"))
+	tail, truncated := buf.Bytes()
+	assert.Equal(t, "foobar", string(tail))
+	assert.Equal(t, int64(0), truncated)
+}
@@ -0,0 +1,200 @@
+// Package deprecation is the registry of the deprecated endpoints and fields
+// of the API and of the deprecated flags of sensuctl. The API warns the
+// clients that use them with a Warning header, which sensuctl prints, so that
+// the operators are told before their behavior changes.
+package deprecation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind is the kind of a deprecated feature.
+type Kind string
+
+const (
+	// KindEndpoint is the kind of the deprecated API endpoints, named by
+	// their method and path, such as "GET /api/core/v2/silenced/checks/{check}".
+	// The segments of the path between braces match any segment.
+	KindEndpoint Kind = "endpoint"
+
+	// KindField is the kind of the deprecated fields of the API resources,
+	// named by the resource and the JSON field, such as "checks.subdue".
+	KindField Kind = "field"
+
+	// KindFlag is the kind of the deprecated flags of sensuctl, named by
+	// the command path and the flag, such as "sensuctl dump --types".
+	KindFlag Kind = "flag"
+)
+
+const (
+	// WarningHeader is the HTTP header of the deprecation warnings.
+	WarningHeader = "Warning"
+
+	// warningCode is the warn-code of the deprecation warnings, the code of
+	// the persistent warnings of RFC 7234.
+	warningCode = "299"
+)
+
+// Deprecation is a deprecated feature.
+type Deprecation struct {
+	Kind Kind
+	Name string
+
+	// Message tells what to use instead.
+	Message string
+
+	// Removal is the release from which the feature is removed, if known.
+	Removal string
+}
+
+// Warning returns the warning of d, for the clients that use it.
+func (d Deprecation) Warning() string {
+	warning := fmt.Sprintf("%s %s is deprecated", d.Kind, strings.TrimPrefix(d.Name, "sensuctl "))
+	if d.Removal != "" {
+		warning += " and will be removed in " + d.Removal
+	}
+	if d.Message != "" {
+		warning += ": " + d.Message
+	}
+	return warning
+}
+
+// registry holds the deprecated features. Every feature deprecated in a
+// release is added here, and removed once the feature is.
+var registry = []Deprecation{
+	{
+		Kind:    KindEndpoint,
+		Name:    "GET /api/core/v2/silenced/checks/{check}",
+		Message: "list the silences with the silenced.check field selector instead",
+	},
+	{
+		Kind:    KindField,
+		Name:    "checks.subdue",
+		Message: "use subdues instead",
+	},
+	{
+		Kind:    KindFlag,
+		Name:    "sensuctl dump --types",
+		Message: `use "sensuctl describe-type all" instead`,
+	},
+	{
+		Kind:    KindFlag,
+		Name:    "sensuctl cluster-role create --resource",
+		Message: "use --resources instead",
+	},
+	{
+		Kind:    KindFlag,
+		Name:    "sensuctl cluster-role create --verb",
+		Message: "use --verbs instead",
+	},
+}
+
+// List returns the deprecated features of kind, sorted by name.
+func List(kind Kind) []Deprecation {
+	var deprecations []Deprecation
+	for _, d := range registry {
+		if d.Kind == kind {
+			deprecations = append(deprecations, d)
+		}
+	}
+	sort.Slice(deprecations, func(i, j int) bool {
+		return deprecations[i].Name < deprecations[j].Name
+	})
+	return deprecations
+}
+
+// Endpoint returns the deprecated endpoint that matches the request of
+// method to path, if any.
+func Endpoint(method, path string) (Deprecation, bool) {
+	for _, d := range registry {
+		if d.Kind != KindEndpoint {
+			continue
+		}
+		if dMethod, dPath, ok := strings.Cut(d.Name, " "); ok && dMethod == method && matchPath(dPath, path) {
+			return d, true
+		}
+	}
+	return Deprecation{}, false
+}
+
+// Fields returns the deprecated fields of resource.
+func Fields(resource string) []Deprecation {
+	var deprecations []Deprecation
+	for _, d := range registry {
+		if d.Kind == KindField && strings.HasPrefix(d.Name, resource+".") {
+			deprecations = append(deprecations, d)
+		}
+	}
+	return deprecations
+}
+
+// Field returns the JSON field of the deprecated field d.
+func (d Deprecation) Field() string {
+	_, field, _ := strings.Cut(d.Name, ".")
+	return field
+}
+
+// Flag returns the command path and the name of the deprecated flag d.
+func (d Deprecation) Flag() (command, flag string) {
+	command, flag, _ = strings.Cut(d.Name, " --")
+	return command, flag
+}
+
+// matchPath returns true if path matches the path of an endpoint, whose
+// segments between braces match any segment.
+func matchPath(pattern, path string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatWarning returns the value of the Warning header of warning.
+func FormatWarning(warning string) string {
+	warning = strings.ReplaceAll(warning, `\`, `\\`)
+	warning = strings.ReplaceAll(warning, `"`, `\"`)
+	return fmt.Sprintf(`%s - "%s"`, warningCode, warning)
+}
+
+// ParseWarning returns the deprecation warning of the value of a Warning
+// header, and false if it is not a deprecation warning.
+func ParseWarning(value string) (string, bool) {
+	code, rest, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok || code != warningCode {
+		return "", false
+	}
+	_, text, ok := strings.Cut(rest, " ")
+	if !ok || len(text) < 2 || text[0] != '"' {
+		return "", false
+	}
+	var warning strings.Builder
+	for i := 1; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\\':
+			if i+1 < len(text) {
+				i++
+				warning.WriteByte(text[i])
+			}
+		case '"':
+			return warning.String(), true
+		default:
+			warning.WriteByte(c)
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,63 @@
+package deprecation
+
+import (
+	"testing"
+)
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{"GET", "/api/core/v2/silenced/checks/check-cpu", true},
+		{"GET", "/api/core/v2/silenced/checks/", false},
+		{"GET", "/api/core/v2/namespaces/default/silenced/checks/check-cpu", false},
+		{"DELETE", "/api/core/v2/silenced/checks/check-cpu", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			if _, got := Endpoint(tt.method, tt.path); got != tt.want {
+				t.Errorf("Endpoint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	fields := Fields("checks")
+	if len(fields) != 1 || fields[0].Field() != "subdue" {
+		t.Fatalf("bad deprecated fields: %v", fields)
+	}
+	if fields := Fields("check"); len(fields) != 0 {
+		t.Fatalf("bad deprecated fields: %v", fields)
+	}
+}
+
+func TestFlag(t *testing.T) {
+	for _, d := range List(KindFlag) {
+		if command, flag := d.Flag(); command == "" || flag == "" {
+			t.Errorf("bad deprecated flag %q", d.Name)
+		}
+	}
+}
+
+func TestWarning(t *testing.T) {
+	d := Deprecation{Kind: KindField, Name: "checks.subdue", Message: "use subdues instead", Removal: "7.1.0"}
+	if got, want := d.Warning(), "field checks.subdue is deprecated and will be removed in 7.1.0: use subdues instead"; got != want {
+		t.Errorf("Warning() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatParseWarning(t *testing.T) {
+	warning := `use "sensuctl describe-type all" instead of C:\sensu`
+	header := FormatWarning(warning)
+	if got, ok := ParseWarning(header); !ok || got != warning {
+		t.Errorf("ParseWarning(%q) = %q, %v", header, got, ok)
+	}
+	for _, header := range []string{`199 - "miscellaneous"`, `299 -`, `299 - "unterminated`, ""} {
+		if _, ok := ParseWarning(header); ok {
+			t.Errorf("ParseWarning(%q) should fail", header)
+		}
+	}
+}